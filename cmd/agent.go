@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/markusressel/fan2go/internal"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Poll the configured sensors and serve them to other fan2go instances",
+	Long: `Runs fan2go as a lightweight sensor agent, which only polls the configured sensors and serves
+their values using the gRPC api, and the REST api if it is enabled. Curves and fans of the configuration
+are ignored. Other fan2go instances read the sensors of the agent using "remote" sensors, f.ex. to drive
+the fans of a rack from the temperature of a diskless node.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		printHeader()
+
+		configPath := configuration.DetectAndReadConfigFile()
+		ui.Info("Using configuration file at: %s", configPath)
+		configuration.LoadConfig()
+		err := configuration.ValidateAgent(configPath)
+		if err != nil {
+			ui.ErrorAndNotify("Config Validation Error", err.Error())
+			return
+		}
+		setupLogging()
+
+		internal.RunAgent()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(agentCmd)
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/markusressel/fan2go/cmd/global"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tomlazar/table"
+)
+
+var (
+	calibrateFanId            string
+	calibrateFanResponseDelay int
+)
+
+// calibrationResult is the outcome of measuring the curve of a single fan
+type calibrationResult struct {
+	fan fans.Fan
+	err error
+}
+
+var calibrateCmd = &cobra.Command{
+	Use:   "calibrate",
+	Short: "Measure the PWM to RPM curve of fans",
+	Long: `Runs the initialization sequence for one or all configured fans, even if they have been
+analyzed before, stores the measured curves in the database and prints a summary.
+Make sure the fan2go daemon is stopped, since both would try to control the fans at the same time.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath := configuration.DetectAndReadConfigFile()
+		ui.Info("Using configuration file at: %s", configPath)
+		// the flag is not bound to the config key, since "fan init" already binds the same key
+		if cmd.Flags().Changed("fan-response-delay") {
+			viper.Set("FanResponseDelay", calibrateFanResponseDelay)
+		}
+		configuration.LoadConfig()
+		err := configuration.Validate(configPath)
+		if err != nil {
+			return err
+		}
+		if configuration.CurrentConfig.DryRun {
+			return errors.New("fans can't be calibrated in dry-run mode, since it requires changing their pwm")
+		}
+
+		fanList, err := getCalibrationFans(calibrateFanId)
+		if err != nil {
+			return err
+		}
+
+		dbPath := configuration.CurrentConfig.DbPath
+		ui.Info("Using persistence at: %s", dbPath)
+		p := persistence.NewPersistence(dbPath)
+
+		results := make([]calibrationResult, len(fanList))
+		var wg sync.WaitGroup
+		for idx, fan := range fanList {
+			if configuration.CurrentConfig.RunFanInitializationInParallel {
+				wg.Add(1)
+				go func(idx int, fan fans.Fan) {
+					defer wg.Done()
+					results[idx] = calibrateFan(p, fan)
+				}(idx, fan)
+			} else {
+				results[idx] = calibrateFan(p, fan)
+			}
+		}
+		wg.Wait()
+
+		err = printCalibrationResults(results)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			if result.err != nil {
+				return errors.New("calibration failed for some fans")
+			}
+		}
+		ui.Success("Done!")
+		return nil
+	},
+}
+
+// getCalibrationFans returns the fan with the given id, or all fans that can be calibrated if id is empty
+func getCalibrationFans(id string) ([]fans.Fan, error) {
+	controllers := hwmon.GetChips()
+
+	var result []fans.Fan
+	var availableFanIds []string
+	for _, config := range configuration.GetFans() {
+		availableFanIds = append(availableFanIds, config.ID)
+		if len(id) > 0 && config.ID != id {
+			continue
+		}
+
+		if config.HwMon != nil || config.DellSmm != nil || config.Group != nil {
+			err := hwmon.UpdateFanConfigFromHwMonControllers(controllers, &config)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't update fan config of '%s' from hwmon: %v", config.ID, err)
+			}
+		}
+		fan, err := fans.NewFan(config)
+		if err != nil {
+			return nil, fmt.Errorf("unable to process fan configuration of '%s': %v", config.ID, err)
+		}
+
+		// same as the daemon, only fans with a measurable curve are analyzed
+		switch fan.(type) {
+		case *fans.HwMonFan, *fans.DellSmmFan:
+		default:
+			if len(id) > 0 {
+				return nil, fmt.Errorf("fan %s can't be calibrated, only hwmon and dellSmm fans are supported", id)
+			}
+			ui.Info("Skipping fan %s, only hwmon and dellSmm fans can be calibrated", config.ID)
+			continue
+		}
+		if !fan.Supports(fans.FeatureRpmSensor) {
+			if len(id) > 0 {
+				return nil, fmt.Errorf("fan %s can't be calibrated, since it has no RPM sensor", id)
+			}
+			ui.Info("Skipping fan %s, since it has no RPM sensor", config.ID)
+			continue
+		}
+
+		result = append(result, fan)
+	}
+
+	if len(id) > 0 && len(result) <= 0 {
+		return nil, fmt.Errorf("no fan with id found: %s, options: %s", id, availableFanIds)
+	}
+	if len(result) <= 0 {
+		return nil, errors.New("no fans to calibrate")
+	}
+	return result, nil
+}
+
+// calibrateFan measures the curve of the given fan again, its existing data is only replaced on success
+func calibrateFan(p persistence.Persistence, fan fans.Fan) calibrationResult {
+	ui.Info("Calibrating fan %s, this may take several minutes...", fan.GetId())
+
+	err := controller.InitializeFan(p, fan)
+	if err != nil {
+		ui.Error("Calibration of fan %s failed: %v", fan.GetId(), err)
+	}
+	return calibrationResult{fan: fan, err: err}
+}
+
+// getMaxRpm returns the highest RPM of the measured curve of the given fan
+func getMaxRpm(fan fans.Fan) int {
+	curveData := fan.GetFanCurveData()
+	if curveData == nil {
+		return 0
+	}
+	return int(fans.GetMaxRpm(*curveData))
+}
+
+func printCalibrationResults(results []calibrationResult) error {
+	var rows [][]string
+	for _, result := range results {
+		fan := result.fan
+		if result.err != nil {
+			rows = append(rows, []string{fan.GetId(), "-", "-", "-", "-", result.err.Error()})
+			continue
+		}
+		rows = append(rows, []string{
+			fan.GetId(),
+			strconv.Itoa(fan.GetMinPwm()),
+			strconv.Itoa(fan.GetStartPwm()),
+			strconv.Itoa(fan.GetMaxPwm()),
+			strconv.Itoa(getMaxRpm(fan)),
+			"ok",
+		})
+	}
+
+	tab := table.Table{
+		Headers: []string{"Fan", "Min PWM", "Start PWM", "Max PWM", "Max RPM", "Result"},
+		Rows:    rows,
+	}
+	var buf bytes.Buffer
+	err := tab.WriteTable(&buf, &table.Config{
+		ShowIndex:       false,
+		Color:           !global.NoColor,
+		AlternateColors: true,
+		TitleColorCode:  ansi.ColorCode("white+buf"),
+		AltColorCodes: []string{
+			ansi.ColorCode("white"),
+			ansi.ColorCode("white:236"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	ui.Printfln(buf.String())
+	return nil
+}
+
+func init() {
+	calibrateCmd.Flags().StringVarP(
+		&calibrateFanId,
+		"id", "i",
+		"",
+		"Fan ID as specified in the config, all fans are calibrated if empty",
+	)
+	calibrateCmd.Flags().IntVarP(
+		&calibrateFanResponseDelay,
+		"fan-response-delay", "e",
+		2,
+		"Delay in seconds to wait before checking that a fan has responded to a control change",
+	)
+	rootCmd.AddCommand(calibrateCmd)
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// the range of the proposed linear curves, in degrees
+	defaultCurveMinTemp = 40
+	defaultCurveMaxTemp = 80
+)
+
+var initOutputPath string
+
+var nonIdCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Interactively create a configuration file",
+	Long: `Walks through the detected hwmon controllers, lets you pick the sensors and fans fan2go should use,
+proposes a linear curve for each selected sensor and writes a ready-to-use configuration file.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configuration.LoadConfig()
+
+		if _, err := os.Stat(initOutputPath); err == nil {
+			overwrite, err := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("%s already exists, overwrite it?", initOutputPath))
+			if err != nil {
+				return err
+			}
+			if !overwrite {
+				return nil
+			}
+		}
+
+		controllers := hwmon.GetChips()
+		sensorOptions, fanOptions := collectInitOptions(controllers)
+		if len(sensorOptions) <= 0 || len(fanOptions) <= 0 {
+			return errors.New("no hwmon sensors or fans detected, run 'fan2go detect' to see all detected devices")
+		}
+
+		selectedSensors, err := selectInitOptions("Select the sensors to monitor", sensorOptions)
+		if err != nil {
+			return err
+		}
+		if len(selectedSensors) <= 0 {
+			return errors.New("no sensors selected")
+		}
+		selectedFans, err := selectInitOptions("Select the fans to control", fanOptions)
+		if err != nil {
+			return err
+		}
+		if len(selectedFans) <= 0 {
+			return errors.New("no fans selected")
+		}
+
+		result := initConfig{}
+		curveIds := map[string]string{}
+		var sensorTexts []string
+		for _, option := range selectedSensors {
+			sensor := option.sensor
+			result.Sensors = append(result.Sensors, sensor)
+			curveId := sensor.ID + "_curve"
+			curveIds[option.text] = curveId
+			sensorTexts = append(sensorTexts, option.text)
+			result.Curves = append(result.Curves, initCurve{
+				ID: curveId,
+				Linear: &initLinearCurve{
+					Sensor: sensor.ID,
+					Min:    defaultCurveMinTemp,
+					Max:    defaultCurveMaxTemp,
+				},
+			})
+		}
+
+		for _, option := range selectedFans {
+			fan := option.fan
+			sensorText := sensorTexts[0]
+			if len(sensorTexts) > 1 {
+				sensorText, err = pterm.DefaultInteractiveSelect.WithOptions(sensorTexts).Show(fmt.Sprintf("Select the sensor controlling fan %s", fan.ID))
+				if err != nil {
+					return err
+				}
+			}
+			fan.Curve = curveIds[sensorText]
+
+			fan.NeverStop, err = pterm.DefaultInteractiveConfirm.WithDefaultValue(true).Show(fmt.Sprintf("Should fan %s never stop?", fan.ID))
+			if err != nil {
+				return err
+			}
+			result.Fans = append(result.Fans, fan)
+		}
+
+		var out bytes.Buffer
+		out.WriteString(fmt.Sprintf("# Generated by 'fan2go config init'. The curves map %d°C to %d°C linearly to the full\n# speed range of their fans, adjust them to your needs. See fan2go.yaml for all options.\n", defaultCurveMinTemp, defaultCurveMaxTemp))
+		encoder := yaml.NewEncoder(&out)
+		encoder.SetIndent(2)
+		err = encoder.Encode(result)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(initOutputPath, out.Bytes(), 0644)
+		if err != nil {
+			return err
+		}
+
+		ui.Success("Configuration written to %s, check it using: fan2go -c %s config validate", initOutputPath, initOutputPath)
+		return nil
+	},
+}
+
+// initConfig is the subset of the configuration written by the init command
+type initConfig struct {
+	Sensors []initSensor `yaml:"sensors"`
+	Curves  []initCurve  `yaml:"curves"`
+	Fans    []initFan    `yaml:"fans"`
+}
+
+type initSensor struct {
+	ID    string          `yaml:"id"`
+	HwMon initHwMonSensor `yaml:"hwmon"`
+}
+
+type initHwMonSensor struct {
+	Platform   string `yaml:"platform"`
+	PciAddress string `yaml:"pciAddress,omitempty"`
+	Index      int    `yaml:"index"`
+}
+
+type initCurve struct {
+	ID     string           `yaml:"id"`
+	Linear *initLinearCurve `yaml:"linear"`
+}
+
+type initLinearCurve struct {
+	Sensor string `yaml:"sensor"`
+	Min    int    `yaml:"min"`
+	Max    int    `yaml:"max"`
+}
+
+type initFan struct {
+	ID        string       `yaml:"id"`
+	HwMon     initHwMonFan `yaml:"hwmon"`
+	NeverStop bool         `yaml:"neverStop"`
+	Curve     string       `yaml:"curve"`
+}
+
+type initHwMonFan struct {
+	Platform   string `yaml:"platform"`
+	PciAddress string `yaml:"pciAddress,omitempty"`
+	RpmChannel int    `yaml:"rpmChannel"`
+}
+
+// initOption is a sensor or fan the user can select, text is displayed in the selection
+type initOption struct {
+	text   string
+	sensor initSensor
+	fan    initFan
+}
+
+// collectInitOptions returns all sensors and fans of the given controllers,
+// with unique ids derived from their labels
+func collectInitOptions(controllers []*hwmon.HwMonController) (sensorOptions []initOption, fanOptions []initOption) {
+	platformCount := map[string]int{}
+	for _, controller := range controllers {
+		platformCount[controller.Platform]++
+	}
+
+	sensorIds := map[string]bool{}
+	fanIds := map[string]bool{}
+	for _, controller := range controllers {
+		// identical devices share the same platform, so they are told apart by their PCI address
+		pciAddress := ""
+		if platformCount[controller.Platform] > 1 && len(controller.PciAddress) > 0 {
+			pciAddress = "pci-" + controller.PciAddress
+		}
+
+		sensorIndices := make([]int, 0, len(controller.Sensors))
+		for index := range controller.Sensors {
+			sensorIndices = append(sensorIndices, index)
+		}
+		sort.Ints(sensorIndices)
+		for _, index := range sensorIndices {
+			sensor := controller.Sensors[index]
+			sensorOptions = append(sensorOptions, initOption{
+				text: fmt.Sprintf("%s: %s (%s)", controller.Name, sensor.Label, formatSensorValue(sensor)),
+				sensor: initSensor{
+					ID: uniqueId(sensor.Label, sensorIds),
+					HwMon: initHwMonSensor{
+						Platform:   controller.Platform,
+						PciAddress: pciAddress,
+						Index:      sensor.Index,
+					},
+				},
+			})
+		}
+
+		for _, fan := range controller.Fans {
+			fanOptions = append(fanOptions, initOption{
+				text: fmt.Sprintf("%s: %s (%s)", controller.Name, fan.Label, formatFanRpm(fan)),
+				fan: initFan{
+					ID: uniqueId(fan.Label, fanIds),
+					HwMon: initHwMonFan{
+						Platform:   controller.Platform,
+						PciAddress: pciAddress,
+						RpmChannel: fan.Config.HwMon.RpmChannel,
+					},
+				},
+			})
+		}
+	}
+	return sensorOptions, fanOptions
+}
+
+func selectInitOptions(text string, options []initOption) ([]initOption, error) {
+	texts := make([]string, len(options))
+	for idx, option := range options {
+		texts[idx] = option.text
+	}
+	selected, err := pterm.DefaultInteractiveMultiselect.WithOptions(texts).WithMaxHeight(15).Show(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []initOption
+	for _, option := range options {
+		for _, s := range selected {
+			if s == option.text {
+				result = append(result, option)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// uniqueId derives an id from the given label, which is not yet contained in ids
+func uniqueId(label string, ids map[string]bool) string {
+	id := strings.Trim(nonIdCharacters.ReplaceAllString(strings.ToLower(label), "_"), "_")
+	if len(id) <= 0 {
+		id = "unnamed"
+	}
+	result := id
+	for i := 2; ids[result]; i++ {
+		result = fmt.Sprintf("%s_%d", id, i)
+	}
+	ids[result] = true
+	return result
+}
+
+func formatSensorValue(sensor *sensors.HwmonSensor) string {
+	value, err := sensor.GetValue()
+	if err != nil {
+		return "N/A"
+	}
+	return fmt.Sprintf("%.1f°C", value/1000)
+}
+
+func formatFanRpm(fan fans.HwMonFan) string {
+	if !fan.Supports(fans.FeatureRpmSensor) {
+		return "no RPM sensor"
+	}
+	rpm, err := fan.GetRpm()
+	if err != nil {
+		return "N/A"
+	}
+	return fmt.Sprintf("%d RPM", rpm)
+}
+
+func init() {
+	initCmd.Flags().StringVarP(
+		&initOutputPath,
+		"output", "o",
+		"fan2go.yaml",
+		"Path of the configuration file to write",
+	)
+	Command.AddCommand(initCmd)
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/markusressel/fan2go/internal/migration"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateFrom       string
+	migrateOutputPath string
+)
+
+// migrationSources maps from the name of a supported tool -> the function converting its configuration
+var migrationSources = map[string]func(r io.Reader) (migration.Config, []string, error){
+	"fancontrol": migration.FromFancontrol,
+	"thinkfan":   migration.FromThinkfan,
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate <path>",
+	Short: "Convert the configuration of another fan control tool",
+	Long: `Converts the configuration file of another fan control tool into an equivalent fan2go configuration file.
+Supported tools:
+  fancontrol: the lm-sensors fancontrol configuration written by pwmconfig, usually /etc/fancontrol
+  thinkfan:   the YAML configuration of thinkfan 1.0 and newer, usually /etc/thinkfan.yaml`,
+	Example: "fan2go config migrate --from fancontrol /etc/fancontrol -o fan2go.yaml",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		convert, ok := migrationSources[migrateFrom]
+		if !ok {
+			return fmt.Errorf("unsupported tool '%s', options: %s", migrateFrom, strings.Join(getMigrationSources(), ", "))
+		}
+
+		if _, err := os.Stat(migrateOutputPath); err == nil {
+			overwrite, err := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("%s already exists, overwrite it?", migrateOutputPath))
+			if err != nil {
+				return err
+			}
+			if !overwrite {
+				return nil
+			}
+		}
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		result, warnings, err := convert(file)
+		if err != nil {
+			return fmt.Errorf("unable to convert %s: %v", args[0], err)
+		}
+		for _, warning := range warnings {
+			ui.Warning("%s", warning)
+		}
+
+		header := fmt.Sprintf(`Converted from the %s configuration %s by 'fan2go config migrate'.
+Check the sensor indices and the curves using 'fan2go detect' and 'fan2go curve list'.`, migrateFrom, args[0])
+		data, err := migration.Marshal(result, header)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(migrateOutputPath, data, 0644)
+		if err != nil {
+			return err
+		}
+
+		ui.Success("Configuration written to %s, check it using: fan2go -c %s config validate", migrateOutputPath, migrateOutputPath)
+		return nil
+	},
+}
+
+func getMigrationSources() []string {
+	var result []string
+	for name := range migrationSources {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func init() {
+	migrateCmd.Flags().StringVarP(
+		&migrateFrom,
+		"from", "f",
+		"fancontrol",
+		"The tool the configuration file belongs to",
+	)
+	migrateCmd.Flags().StringVarP(
+		&migrateOutputPath,
+		"output", "o",
+		"fan2go.yaml",
+		"Path of the configuration file to write",
+	)
+	Command.AddCommand(migrateCmd)
+}
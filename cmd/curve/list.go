@@ -7,6 +7,7 @@ import (
 	"github.com/markusressel/fan2go/cmd/global"
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/markusressel/fan2go/internal/util"
 	"github.com/mgutz/ansi"
@@ -31,13 +32,13 @@ var curveCmd = &cobra.Command{
 
 		curveConfigsToPrint := []configuration.CurveConfig{}
 		if curveId != "" {
-			curveConf, err := getCurveConfig(curveId, configuration.CurrentConfig.Curves)
+			curveConf, err := getCurveConfig(curveId, configuration.GetCurves())
 			if err != nil {
 				return err
 			}
 			curveConfigsToPrint = append(curveConfigsToPrint, *curveConf)
 		} else {
-			curveConfigsToPrint = append(curveConfigsToPrint, configuration.CurrentConfig.Curves...)
+			curveConfigsToPrint = append(curveConfigsToPrint, configuration.GetCurves()...)
 		}
 
 		for idx, curveConfig := range curveConfigsToPrint {
@@ -58,6 +59,12 @@ var curveCmd = &cobra.Command{
 				printPidCurveInfo(curve, curveConfig.PID)
 			case *curves.FunctionSpeedCurve:
 				printFunctionCurveInfo(curve, curveConfig.Function)
+			case *curves.TargetSpeedCurve:
+				printTargetCurveInfo(curve, curveConfig.Target)
+			case *curves.ScheduleSpeedCurve:
+				printScheduleCurveInfo(curve, curveConfig.Schedule)
+			case *curves.ExpressionSpeedCurve:
+				printExpressionCurveInfo(curve, curveConfig.Expression)
 			}
 		}
 
@@ -68,69 +75,85 @@ var curveCmd = &cobra.Command{
 func printLinearCurveInfo(curve curves.SpeedCurve, config *configuration.LinearCurveConfig) {
 	curveType := "Linear"
 
-	sensorId := config.Sensor
+	sensorId := formatCurveInput(config.Sensor, config.Sensors)
+	if len(config.Curve) > 0 {
+		sensorId = "curve: " + config.Curve
+	}
 
+	var minTemp, maxTemp int
 	if config.Steps != nil {
-		stepMappings := map[int]float64{}
 		sortedStepKeys := util.SortedKeys(config.Steps)
-		for _, x := range sortedStepKeys {
-			y := config.Steps[x]
-			stepMappings[int(y)] = float64(x)
+		minTemp = sortedStepKeys[0]
+		maxTemp = sortedStepKeys[len(sortedStepKeys)-1]
+
+		interpolation := config.Interpolation
+		if len(interpolation) <= 0 {
+			interpolation = util.InterpolationTypeLinear
 		}
 
-		headers := []string{"ID", "Type", "Sensor"}
+		headers := []string{"ID", "Type", "Sensor", "Interpolation"}
 		rows := [][]string{
-			{curve.GetId(), curveType, sensorId},
+			{curve.GetId(), curveType, sensorId, interpolation},
 		}
 
 		printInfoTable(headers, rows)
-
-		interpolated := util.InterpolateLinearly(&stepMappings, 0, 255)
-		drawGraph(interpolated, "Temp / Curve Value")
 	} else {
+		minTemp = config.Min
+		maxTemp = config.Max
+
 		headers := []string{"ID", "Type", "Sensor", "Min", "Max"}
 		rows := [][]string{
 			{curve.GetId(), curveType, sensorId, fmt.Sprint(config.Min), fmt.Sprint(config.Max)},
 		}
 
 		printInfoTable(headers, rows)
+	}
 
-		/*
-			var keys map[int]float64
-			// TODO: to draw a graph of what the PWM curve would look like, we would need to
-			//  calculate the target pwm for each input pwm for a given curve.
-			//
-			//  with the current architecture this isn't possible, because the curve instance is hardwired
-			//  to a specific sensor.
-			//  So to calculate curve targets we would need to set fake sensor values
-
-			// creates a virtual sensor, to simulate inputs and calculate the output of the curve
-			sensor := sensors.VirtualSensor{
-				Name:  sensorId,
-				Value: 0,
-			}
-			sensors.SensorMap[sensorId] = &sensor
-
-			keys = map[int]float64{}
+	if len(config.Curve) > 0 {
+		printCurveComposition(curve.GetId(), "")
+		return
+	}
+	if len(config.Sensors) > 0 {
+		// the graph can only simulate a single sensor
+		return
+	}
 
-			for i := config.Min; i <= config.Max; i++ {
-				sensor.Value = float64(i * 1000)
-				v, _ := curve.Evaluate()
-				keys[i] = float64(v)
-			}
+	// add some margin to show the behaviour of the curve outside of its range
+	drawCurveGraph(curve, config.Sensor, minTemp-5, maxTemp+5)
+}
 
-			start := 0
-			stop := 100
+// formatCurveInput returns a description of the sensor, or the weighted sensors, a curve is driven by
+func formatCurveInput(sensorId string, weighted []configuration.WeightedSensorConfig) string {
+	if len(weighted) <= 0 {
+		return sensorId
+	}
+	var terms []string
+	for _, sensorConfig := range weighted {
+		terms = append(terms, fmt.Sprintf("%v*%s", sensorConfig.Weight, sensorConfig.ID))
+	}
+	return strings.Join(terms, " + ")
+}
 
-			var graphValues map[int]float64 = nil
-			graphValues = util.InterpolateLinearly(&keys, start, stop)
+// drawCurveGraph draws the value of the given curve for all temperatures in [minTemp;maxTemp],
+// by replacing its sensor with a virtual one simulating these temperatures
+func drawCurveGraph(curve curves.SpeedCurve, sensorId string, minTemp int, maxTemp int) {
+	sensor := sensors.VirtualSensor{
+		Name:  sensorId,
+		Value: 0,
+	}
+	sensors.SetSensor(sensorId, &sensor)
 
-			if graphValues != nil {
-				drawGraph(graphValues, "RPM / PWM")
-			}
-		*/
+	graphValues := map[int]float64{}
+	for temp := minTemp; temp <= maxTemp; temp++ {
+		sensor.Value = float64(temp * 1000)
+		value, err := curve.Evaluate()
+		if err != nil {
+			return
+		}
+		graphValues[temp] = float64(value)
 	}
 
+	drawGraph(graphValues, fmt.Sprintf("Curve Value / Temp (%d°C - %d°C)", minTemp, maxTemp))
 }
 
 func drawGraph(graphValues map[int]float64, caption string) {
@@ -162,6 +185,44 @@ func printFunctionCurveInfo(curve curves.SpeedCurve, config *configuration.Funct
 	}
 
 	printInfoTable(headers, rows)
+	printCurveComposition(curve.GetId(), "")
+}
+
+// printCurveComposition prints the tree of curves the given curve is composed of
+func printCurveComposition(curveId string, indent string) {
+	config, err := getCurveConfig(curveId, configuration.GetCurves())
+	if err != nil {
+		ui.Printfln("%s%s (not found)", indent, curveId)
+		return
+	}
+
+	var children []string
+	switch {
+	case config.Linear != nil && len(config.Linear.Curve) > 0:
+		ui.Printfln("%s%s (linear)", indent, curveId)
+		children = []string{config.Linear.Curve}
+	case config.Linear != nil:
+		ui.Printfln("%s%s (linear, sensor: %s)", indent, curveId, formatCurveInput(config.Linear.Sensor, config.Linear.Sensors))
+	case config.PID != nil:
+		ui.Printfln("%s%s (pid, sensor: %s)", indent, curveId, formatCurveInput(config.PID.Sensor, config.PID.Sensors))
+	case config.Target != nil:
+		ui.Printfln("%s%s (target, sensor: %s)", indent, curveId, formatCurveInput(config.Target.Sensor, config.Target.Sensors))
+	case config.Function != nil:
+		ui.Printfln("%s%s (%s)", indent, curveId, config.Function.Type)
+		children = config.Function.Curves
+	case config.Expression != nil:
+		ui.Printfln("%s%s (expression: %s)", indent, curveId, config.Expression.Formula)
+	case config.Schedule != nil:
+		ui.Printfln("%s%s (schedule)", indent, curveId)
+		children = append(children, config.Schedule.Default)
+		for _, entry := range config.Schedule.Entries {
+			children = append(children, entry.Curve)
+		}
+	}
+
+	for _, child := range children {
+		printCurveComposition(child, indent+"  ")
+	}
 }
 
 func printPidCurveInfo(curve curves.SpeedCurve, config *configuration.PidCurveConfig) {
@@ -175,6 +236,48 @@ func printPidCurveInfo(curve curves.SpeedCurve, config *configuration.PidCurveCo
 	printInfoTable(headers, rows)
 }
 
+func printTargetCurveInfo(curve curves.SpeedCurve, config *configuration.TargetCurveConfig) {
+	curveType := "Target"
+
+	headers := []string{"ID", "Type", "Sensor", "Target", "Min", "Max", "Gain", "Tolerance"}
+	rows := [][]string{
+		{curve.GetId(), curveType, formatCurveInput(config.Sensor, config.Sensors), fmt.Sprint(config.Target), fmt.Sprint(config.Min), fmt.Sprint(config.Max), fmt.Sprint(config.Gain), fmt.Sprint(config.Tolerance)},
+	}
+
+	printInfoTable(headers, rows)
+}
+
+func printScheduleCurveInfo(curve curves.SpeedCurve, config *configuration.ScheduleCurveConfig) {
+	curveType := "Schedule"
+
+	headers := []string{"ID", "Type", "From", "To", "Curve ID"}
+	rows := [][]string{}
+	for _, entry := range config.Entries {
+		rows = append(rows, []string{curve.GetId(), curveType, entry.From, entry.To, entry.Curve})
+	}
+	rows = append(rows, []string{curve.GetId(), curveType, "", "", config.Default})
+
+	printInfoTable(headers, rows)
+	printCurveComposition(curve.GetId(), "")
+}
+
+func printExpressionCurveInfo(curve curves.SpeedCurve, config *configuration.ExpressionCurveConfig) {
+	curveType := "Expression"
+
+	sensorIds := curves.GetSensorIds(curve.GetId())
+	headers := []string{"ID", "Type", "Formula", "Sensors"}
+	rows := [][]string{
+		{curve.GetId(), curveType, config.Formula, strings.Join(sensorIds, ", ")},
+	}
+
+	printInfoTable(headers, rows)
+
+	// the curve can only be drawn as a function of a single sensor
+	if len(sensorIds) == 1 {
+		drawCurveGraph(curve, sensorIds[0], 20, 100)
+	}
+}
+
 func printInfoTable(headers []string, rows [][]string) {
 	tab := table.Table{
 		Headers: headers,
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var fanIds []string
+
+var Command = &cobra.Command{
+	Use:              "db",
+	Short:            "Database related commands",
+	Long:             ``,
+	TraverseChildren: true,
+}
+
+func init() {
+	Command.PersistentFlags().StringSliceVarP(
+		&fanIds,
+		"id", "i",
+		[]string{},
+		"Fan IDs as specified in the config, all fans if empty",
+	)
+}
+
+// getDbPath returns the path of the database configured in the config file
+func getDbPath() string {
+	configuration.DetectAndReadConfigFile()
+	configuration.LoadConfig()
+	dbPath := configuration.CurrentConfig.DbPath
+	ui.Debug("Using persistence at: %s", dbPath)
+	return dbPath
+}
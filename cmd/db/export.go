@@ -0,0 +1,61 @@
+package db
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+var exportOutput string
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the calibration data of fans as JSON",
+	Long: `Exports the measured fan curves and pwm maps stored in the database as JSON,
+f.ex. to back them up or to use them on another machine with identical fans.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := persistence.ExportData(getDbPath())
+		if err != nil {
+			return err
+		}
+
+		if len(fanIds) > 0 {
+			for fanId := range data.Fans {
+				if !slices.Contains(fanIds, fanId) {
+					delete(data.Fans, fanId)
+				}
+			}
+		}
+
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = append(out, '\n')
+
+		if len(exportOutput) <= 0 {
+			_, err = os.Stdout.Write(out)
+			return err
+		}
+		err = os.WriteFile(exportOutput, out, 0644)
+		if err == nil {
+			ui.Success("Exported calibration data of %d fans to %s", len(data.Fans), exportOutput)
+		}
+		return err
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(
+		&exportOutput,
+		"output", "o",
+		"",
+		"File to write the exported data to, defaults to stdout",
+	)
+	Command.AddCommand(exportCmd)
+}
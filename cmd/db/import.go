@@ -0,0 +1,40 @@
+package db
+
+import (
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import the calibration data of fans from JSON",
+	Long: `Imports fan curves and pwm maps previously exported using "fan2go db export".
+Existing data of the imported fans is replaced, the daemon uses it on its next start.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := persistence.ReadExport(args[0])
+		if err != nil {
+			return err
+		}
+
+		if len(fanIds) > 0 {
+			for fanId := range data.Fans {
+				if !slices.Contains(fanIds, fanId) {
+					delete(data.Fans, fanId)
+				}
+			}
+		}
+
+		err = persistence.ImportData(getDbPath(), *data)
+		if err == nil {
+			ui.Success("Imported calibration data of %d fans", len(data.Fans))
+		}
+		return err
+	},
+}
+
+func init() {
+	Command.AddCommand(importCmd)
+}
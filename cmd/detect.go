@@ -2,30 +2,42 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/markusressel/fan2go/cmd/global"
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/fans"
 	"github.com/markusressel/fan2go/internal/hwmon"
 	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/usbhid"
 	"github.com/mgutz/ansi"
 	"github.com/spf13/cobra"
 	"github.com/tomlazar/table"
+	"gopkg.in/yaml.v3"
 )
 
+var detectOutputFormat string
+
 var detectCmd = &cobra.Command{
 	Use:   "detect",
 	Short: "Detect fans and sensors",
 	Long:  `Detect fans and sensors on your system and print them to console.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		configuration.LoadConfig()
 
 		controllers := hwmon.GetChips()
 
+		if len(detectOutputFormat) > 0 {
+			return printDetectedDevices(controllers, detectOutputFormat)
+		}
+
 		// === Print detected devices ===
 		tableConfig := &table.Config{
 			ShowIndex:       false,
@@ -68,12 +80,18 @@ var detectCmd = &cobra.Command{
 					}
 				}
 
+				modeText := "N/A"
+				mode, err := fan.GetPwmMode()
+				if err == nil {
+					modeText = fans.PwmModeName(mode)
+				}
+
 				isAuto, _ := fan.IsPwmAuto()
 				fanRows = append(fanRows, []string{
-					"", strconv.Itoa(fan.Index), strconv.Itoa(fan.Config.HwMon.RpmChannel), fan.Label, rpmText, pwmText, fmt.Sprintf("%v", isAuto),
+					"", strconv.Itoa(fan.Index), strconv.Itoa(fan.Config.HwMon.RpmChannel), fan.Label, rpmText, pwmText, modeText, fmt.Sprintf("%v", isAuto),
 				})
 			}
-			var fanHeaders = []string{"Fans   ", "Index", "Channel", "Label", "RPM", "PWM", "Auto"}
+			var fanHeaders = []string{"Fans   ", "Index", "Channel", "Label", "RPM", "PWM", "Mode", "Auto"}
 
 			fanTable := table.Table{
 				Headers: fanHeaders,
@@ -128,9 +146,179 @@ var detectCmd = &cobra.Command{
 				}
 			}
 		}
+
+		for _, controller := range usbhid.Detect() {
+			ui.Printfln("> %s (%s)", controller.GetName(), controller.GetPath())
+
+			var fanRows [][]string
+			for channel := 1; channel <= controller.GetChannelCount(); channel++ {
+				rpmText := "N/A"
+				rpm, err := controller.GetRpm(channel)
+				if err == nil {
+					rpmText = strconv.Itoa(rpm)
+				}
+				fanRows = append(fanRows, []string{
+					"", controller.GetModel(), strconv.Itoa(channel), rpmText,
+				})
+			}
+			var fanHeaders = []string{"Fans   ", "Model", "Channel", "RPM"}
+
+			fanTable := table.Table{
+				Headers: fanHeaders,
+				Rows:    fanRows,
+			}
+
+			var buf bytes.Buffer
+			tableErr := fanTable.WriteTable(&buf, tableConfig)
+			if tableErr != nil {
+				ui.Fatal("Error printing table: %v", tableErr)
+			}
+			ui.Printfln(buf.String())
+		}
+
+		return nil
 	},
 }
 
+// detectedController is the machine readable representation of a detected controller
+type detectedController struct {
+	Name       string           `json:"name" yaml:"name"`
+	Platform   string           `json:"platform,omitempty" yaml:"platform,omitempty"`
+	Modalias   string           `json:"modalias,omitempty" yaml:"modalias,omitempty"`
+	PciAddress string           `json:"pciAddress,omitempty" yaml:"pciAddress,omitempty"`
+	Model      string           `json:"model,omitempty" yaml:"model,omitempty"`
+	Path       string           `json:"path" yaml:"path"`
+	Fans       []detectedFan    `json:"fans" yaml:"fans"`
+	Sensors    []detectedSensor `json:"sensors,omitempty" yaml:"sensors,omitempty"`
+}
+
+type detectedFan struct {
+	Index   int    `json:"index,omitempty" yaml:"index,omitempty"`
+	Channel int    `json:"channel" yaml:"channel"`
+	Label   string `json:"label,omitempty" yaml:"label,omitempty"`
+	Rpm     *int   `json:"rpm" yaml:"rpm"`
+	Pwm     *int   `json:"pwm,omitempty" yaml:"pwm,omitempty"`
+	Mode    string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Auto    *bool  `json:"auto,omitempty" yaml:"auto,omitempty"`
+}
+
+type detectedSensor struct {
+	Index int    `json:"index" yaml:"index"`
+	Label string `json:"label" yaml:"label"`
+	Input string `json:"input" yaml:"input"`
+	Value *int   `json:"value" yaml:"value"`
+}
+
+// printDetectedDevices prints all detected devices in the given machine readable format
+func printDetectedDevices(controllers []*hwmon.HwMonController, format string) error {
+	result := []detectedController{}
+
+	for _, controller := range controllers {
+		if len(controller.Name) <= 0 || (len(controller.Fans) <= 0 && len(controller.Sensors) <= 0) {
+			continue
+		}
+
+		c := detectedController{
+			Name:     controller.Name,
+			Platform: controller.Platform,
+			Modalias: controller.Modalias,
+			Path:     controller.Path,
+			Fans:     []detectedFan{},
+		}
+		if len(controller.PciAddress) > 0 {
+			c.PciAddress = "pci-" + controller.PciAddress
+		}
+
+		for _, fan := range controller.Fans {
+			f := detectedFan{
+				Index:   fan.Index,
+				Channel: fan.Config.HwMon.RpmChannel,
+				Label:   fan.Label,
+			}
+			if pwm, err := fan.GetPwm(); err == nil {
+				f.Pwm = &pwm
+			}
+			if fan.Supports(fans.FeatureRpmSensor) {
+				if rpm, err := fan.GetRpm(); err == nil {
+					f.Rpm = &rpm
+				}
+			}
+			if mode, err := fan.GetPwmMode(); err == nil {
+				f.Mode = strings.ToLower(fans.PwmModeName(mode))
+			}
+			if isAuto, err := fan.IsPwmAuto(); err == nil {
+				f.Auto = &isAuto
+			}
+			c.Fans = append(c.Fans, f)
+		}
+
+		sensorIndices := make([]int, 0, len(controller.Sensors))
+		for index := range controller.Sensors {
+			sensorIndices = append(sensorIndices, index)
+		}
+		sort.Ints(sensorIndices)
+
+		for _, index := range sensorIndices {
+			sensor := controller.Sensors[index]
+			s := detectedSensor{
+				Index: sensor.Index,
+				Label: sensor.Label,
+				Input: sensor.Input,
+			}
+			if value, err := sensor.GetValue(); err == nil {
+				v := int(value)
+				s.Value = &v
+			}
+			c.Sensors = append(c.Sensors, s)
+		}
+
+		result = append(result, c)
+	}
+
+	for _, controller := range usbhid.Detect() {
+		c := detectedController{
+			Name:  controller.GetName(),
+			Model: controller.GetModel(),
+			Path:  controller.GetPath(),
+			Fans:  []detectedFan{},
+		}
+		for channel := 1; channel <= controller.GetChannelCount(); channel++ {
+			f := detectedFan{
+				Channel: channel,
+			}
+			if rpm, err := controller.GetRpm(channel); err == nil {
+				f.Rpm = &rpm
+			}
+			c.Fans = append(c.Fans, f)
+		}
+		result = append(result, c)
+	}
+
+	var out []byte
+	var err error
+	switch format {
+	case "json":
+		out, err = json.MarshalIndent(result, "", "  ")
+		out = append(out, '\n')
+	case "yaml":
+		out, err = yaml.Marshal(result)
+	default:
+		return fmt.Errorf("unsupported output format '%s', use one of: json | yaml", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
 func init() {
+	detectCmd.Flags().StringVarP(
+		&detectOutputFormat,
+		"output", "o",
+		"",
+		"Print the detected devices in a machine readable format, one of: json | yaml",
+	)
 	rootCmd.AddCommand(detectCmd)
 }
@@ -30,7 +30,7 @@ var curveCmd = &cobra.Command{
 		persistence := persistence.NewPersistence(configuration.CurrentConfig.DbPath)
 
 		var fanList []fans.Fan
-		for _, config := range configuration.CurrentConfig.Fans {
+		for _, config := range configuration.GetFans() {
 			fan, err := fans.NewFan(config)
 			if err != nil {
 				ui.Fatal("Unable to process fan configuration: %s", config.ID)
@@ -39,7 +39,7 @@ var curveCmd = &cobra.Command{
 		}
 
 		for idx, fan := range fanList {
-			if fan.GetId() != fanId {
+			if len(fanId) > 0 && fan.GetId() != fanId {
 				continue
 			}
 
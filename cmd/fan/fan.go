@@ -1,8 +1,10 @@
 package fan
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/markusressel/fan2go/internal/api"
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/fans"
 	"github.com/markusressel/fan2go/internal/hwmon"
@@ -12,6 +14,8 @@ import (
 
 var fanId string
 
+var configLoaded bool
+
 var Command = &cobra.Command{
 	Use:              "fan",
 	Short:            "Fan related commands",
@@ -26,10 +30,14 @@ func init() {
 		"",
 		"Fan ID as specified in the config",
 	)
-	_ = Command.MarkPersistentFlagRequired("id")
 }
 
-func getFan(id string) (fans.Fan, error) {
+func loadConfig() {
+	if configLoaded {
+		return
+	}
+	configLoaded = true
+
 	configPath := configuration.DetectAndReadConfigFile()
 	ui.Info("Using configuration file at: %s", configPath)
 	configuration.LoadConfig()
@@ -37,25 +45,41 @@ func getFan(id string) (fans.Fan, error) {
 	if err != nil {
 		ui.Fatal(err.Error())
 	}
+}
 
+// getDaemonClient returns a client for the control socket of a running daemon,
+// or nil if the socket is disabled or no daemon is listening on it
+func getDaemonClient() *api.Client {
+	loadConfig()
+	if !configuration.CurrentConfig.Socket.Enabled {
+		return nil
+	}
+	return api.NewSocketClient(configuration.CurrentConfig.Socket.Path)
+}
+
+func getFan(id string) (fans.Fan, error) {
+	if len(id) <= 0 {
+		return nil, errors.New("required flag \"id\" not set")
+	}
+
+	loadConfig()
 	controllers := hwmon.GetChips()
 
 	availableFanIds := []string{}
-	for _, config := range configuration.CurrentConfig.Fans {
+	for _, config := range configuration.GetFans() {
 		availableFanIds = append(availableFanIds, config.ID)
 		if config.ID == id {
-			if config.HwMon != nil {
-				_ = hwmon.UpdateFanConfigFromHwMonControllers(controllers, &config)
-			}
-
-			fan, err := fans.NewFan(config)
-			if err != nil {
-				return nil, err
-			}
-
-			return fan, nil
+			return createFan(config, controllers)
 		}
 	}
 
 	return nil, fmt.Errorf("no fan with id found: %s, options: %s", id, availableFanIds)
 }
+
+func createFan(config configuration.FanConfig, controllers []*hwmon.HwMonController) (fans.Fan, error) {
+	if config.HwMon != nil || config.DellSmm != nil || config.Group != nil {
+		_ = hwmon.UpdateFanConfigFromHwMonControllers(controllers, &config)
+	}
+
+	return fans.NewFan(config)
+}
@@ -5,7 +5,6 @@ import (
 	"github.com/markusressel/fan2go/internal/controller"
 	"github.com/markusressel/fan2go/internal/persistence"
 	"github.com/markusressel/fan2go/internal/ui"
-	"github.com/markusressel/fan2go/internal/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -28,27 +27,7 @@ var initCmd = &cobra.Command{
 
 		p := persistence.NewPersistence(dbPath)
 
-		fanController := controller.NewFanController(
-			p,
-			fan,
-			*util.NewPidLoop(
-				0.03,
-				0.002,
-				0.0005,
-			),
-			configuration.CurrentConfig.ControllerAdjustmentTickRate)
-
-		ui.Info("Deleting existing data for fan '%s'...", fan.GetId())
-
-		if err = p.DeleteFanPwmData(fan); err != nil {
-			return err
-		}
-		if err = p.DeleteFanPwmMap(fan.GetId()); err != nil {
-			return err
-		}
-
-		err = fanController.RunInitializationSequence()
-
+		err = controller.InitializeFan(p, fan)
 		if err == nil {
 			ui.Success("Done!")
 			// print measured fan curve
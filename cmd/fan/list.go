@@ -0,0 +1,91 @@
+package fan
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/markusressel/fan2go/cmd/global"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+	"github.com/tomlazar/table"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all configured fans with their current state",
+	Long:  ``,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loadConfig()
+		controllers := hwmon.GetChips()
+		p := persistence.NewPersistence(configuration.CurrentConfig.DbPath)
+
+		var rows [][]string
+		for _, config := range configuration.GetFans() {
+			fan, err := createFan(config, controllers)
+			if err != nil {
+				ui.Warning("Unable to process fan configuration %s: %v", config.ID, err)
+				continue
+			}
+
+			pwmText := "N/A"
+			if pwm, err := fan.GetPwm(); err == nil {
+				pwmText = strconv.Itoa(pwm)
+			}
+
+			rpmText := "N/A"
+			if fan.Supports(fans.FeatureRpmSensor) {
+				if rpm, err := fan.GetRpm(); err == nil {
+					rpmText = strconv.Itoa(rpm)
+				}
+			}
+
+			modeText := "N/A"
+			if fan.Supports(fans.FeatureControlMode) {
+				if pwmEnabled, err := fan.GetPwmEnabled(); err == nil {
+					modeText = strconv.Itoa(pwmEnabled)
+				}
+			}
+
+			calibratedText := "no"
+			if _, err := p.LoadFanPwmData(fan); err == nil {
+				calibratedText = "yes"
+			}
+
+			rows = append(rows, []string{
+				fan.GetId(), fan.GetCurveId(), pwmText, rpmText, modeText, calibratedText,
+			})
+		}
+
+		tab := table.Table{
+			Headers: []string{"ID", "Curve", "PWM", "RPM", "Mode", "Calibrated"},
+			Rows:    rows,
+		}
+		var buf bytes.Buffer
+		tableErr := tab.WriteTable(&buf, &table.Config{
+			ShowIndex:       false,
+			Color:           !global.NoColor,
+			AlternateColors: true,
+			TitleColorCode:  ansi.ColorCode("white+buf"),
+			AltColorCodes: []string{
+				ansi.ColorCode("white"),
+				ansi.ColorCode("white:236"),
+			},
+		})
+		if tableErr != nil {
+			return tableErr
+		}
+		ui.Printfln(buf.String())
+
+		return nil
+	},
+}
+
+func init() {
+	Command.AddCommand(listCmd)
+}
@@ -0,0 +1,69 @@
+package fan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var controlModes = map[string]fans.ControlMode{
+	"disabled": fans.ControlModeDisabled,
+	"pwm":      fans.ControlModePWM,
+	"auto":     fans.ControlModeAutomatic,
+}
+
+var modeCmd = &cobra.Command{
+	Use:   "mode",
+	Short: "Get/Set the current control mode (pwm_enable) of a fan, one of: disabled | pwm | auto (or [0..2])",
+	Long:  ``,
+	Args:  cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pterm.DisableOutput()
+
+		fan, err := getFan(fanId)
+		if err != nil {
+			return err
+		}
+
+		if !fan.Supports(fans.FeatureControlMode) {
+			return fmt.Errorf("fan %s does not support control modes", fan.GetId())
+		}
+
+		if len(args) > 0 {
+			var mode fans.ControlMode
+			mode, err = parseControlMode(args[0])
+			if err != nil {
+				return err
+			}
+			err = fan.SetPwmEnabled(mode)
+		} else {
+			var pwmEnabled int
+			if pwmEnabled, err = fan.GetPwmEnabled(); err == nil {
+				fmt.Printf("%d", pwmEnabled)
+			}
+		}
+
+		return err
+	},
+}
+
+// parseControlMode parses the given name or numeric value of a control mode
+func parseControlMode(text string) (fans.ControlMode, error) {
+	if mode, ok := controlModes[strings.ToLower(text)]; ok {
+		return mode, nil
+	}
+
+	value, err := strconv.Atoi(text)
+	if err != nil || value < int(fans.ControlModeDisabled) || value > int(fans.ControlModeAutomatic) {
+		return 0, fmt.Errorf("invalid control mode '%s', use one of: disabled | pwm | auto", text)
+	}
+	return fans.ControlMode(value), nil
+}
+
+func init() {
+	Command.AddCommand(modeCmd)
+}
@@ -0,0 +1,32 @@
+package fan
+
+import (
+	"errors"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Release a speed override of a fan, handing control back to its curve",
+	Long:  `Release a speed override set on a running daemon using "fan speed", handing control back to its curve.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pterm.DisableOutput()
+
+		if len(fanId) <= 0 {
+			return errors.New("required flag \"id\" not set")
+		}
+
+		client := getDaemonClient()
+		if client == nil {
+			return errors.New("no running fan2go daemon reachable via the control socket")
+		}
+		return client.ClearFanOverride(fanId)
+	},
+}
+
+func init() {
+	Command.AddCommand(releaseCmd)
+}
@@ -27,6 +27,10 @@ var resetCmd = &cobra.Command{
 			return err
 		}
 		err = p.DeleteFanPwmMap(fan.GetId())
+		if err != nil {
+			return err
+		}
+		err = p.DeleteFanPwmBoundaries(fan.GetId())
 
 		if err == nil {
 			ui.Success("Done!")
@@ -1,7 +1,9 @@
 package fan
 
 import (
+	"errors"
 	"fmt"
+
 	"github.com/markusressel/fan2go/internal/fans"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
@@ -15,6 +17,23 @@ var rpmCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		pterm.DisableOutput()
 
+		if len(fanId) <= 0 {
+			return errors.New("required flag \"id\" not set")
+		}
+
+		if client := getDaemonClient(); client != nil {
+			status, err := client.GetFanStatus(fanId)
+			if err != nil {
+				return err
+			}
+			if status.Rpm == nil {
+				fmt.Printf("N/A")
+				return nil
+			}
+			fmt.Printf("RPM: %d", *status.Rpm)
+			return nil
+		}
+
 		fan, err := getFan(fanId)
 		if err != nil {
 			return err
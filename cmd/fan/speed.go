@@ -1,31 +1,66 @@
 package fan
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/fans"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
-	"strconv"
 )
 
+var speedDuration time.Duration
+
 var speedCmd = &cobra.Command{
 	Use:   "speed",
 	Short: "Get/Set the current speed setting of a fan to the given PWM value ([0..255])",
-	Long:  ``,
-	Args:  cobra.RangeArgs(0, 1),
+	Long: `Get/Set the current speed setting of a fan to the given PWM value ([0..255]).
+
+If a fan2go daemon is reachable via its control socket, the speed is set as an override
+on the running daemon (see "fan release"), otherwise the fan is accessed directly.`,
+	Args: cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		pterm.DisableOutput()
 
-		fan, err := getFan(fanId)
-		if err != nil {
-			return err
+		if len(fanId) <= 0 {
+			return errors.New("required flag \"id\" not set")
 		}
 
+		var pwmValue int
+		var err error
 		if len(args) > 0 {
-			var pwmValue int
 			pwmValue, err = strconv.Atoi(args[0])
 			if err != nil {
 				return err
 			}
+			if pwmValue < fans.MinPwmValue || pwmValue > fans.MaxPwmValue {
+				return fmt.Errorf("invalid pwm value %d, must be in range [%d..%d]", pwmValue, fans.MinPwmValue, fans.MaxPwmValue)
+			}
+		}
+
+		if client := getDaemonClient(); client != nil {
+			if len(args) > 0 {
+				return client.SetFanOverride(fanId, pwmValue, speedDuration)
+			}
+			status, err := client.GetFanStatus(fanId)
+			if err != nil {
+				return err
+			}
+			if status.Pwm == nil {
+				return fmt.Errorf("unable to read pwm of fan %s", fanId)
+			}
+			fmt.Printf("%d", *status.Pwm)
+			return nil
+		}
+
+		fan, err := getFan(fanId)
+		if err != nil {
+			return err
+		}
+
+		if len(args) > 0 {
 			err = fan.SetPwm(pwmValue)
 		} else {
 			var pwm int
@@ -39,5 +74,11 @@ var speedCmd = &cobra.Command{
 }
 
 func init() {
+	speedCmd.Flags().DurationVarP(
+		&speedDuration,
+		"duration", "d",
+		0,
+		"Duration of the override when talking to a running daemon, forever if not set",
+	)
 	Command.AddCommand(speedCmd)
 }
@@ -0,0 +1,75 @@
+package fan
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/api"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the current PWM, RPM and override of a fan",
+	Long: `Print the current PWM, RPM and override of a fan.
+
+If a fan2go daemon is reachable via its control socket, the status is queried from the
+running daemon, otherwise the fan is accessed directly.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pterm.DisableOutput()
+
+		if len(fanId) <= 0 {
+			return errors.New("required flag \"id\" not set")
+		}
+
+		var status *api.FanStatus
+		if client := getDaemonClient(); client != nil {
+			var err error
+			status, err = client.GetFanStatus(fanId)
+			if err != nil {
+				return err
+			}
+		} else {
+			fan, err := getFan(fanId)
+			if err != nil {
+				return err
+			}
+			status = &api.FanStatus{}
+			if pwm, err := fan.GetPwm(); err == nil {
+				status.Pwm = &pwm
+			}
+			if fan.Supports(fans.FeatureRpmSensor) {
+				if rpm, err := fan.GetRpm(); err == nil {
+					status.Rpm = &rpm
+				}
+			}
+		}
+
+		pwmText := "N/A"
+		if status.Pwm != nil {
+			pwmText = fmt.Sprintf("%d", *status.Pwm)
+		}
+		rpmText := "N/A"
+		if status.Rpm != nil {
+			rpmText = fmt.Sprintf("%d", *status.Rpm)
+		}
+		overrideText := "none"
+		if status.Override != nil {
+			overrideText = fmt.Sprintf("%d", status.Override.Pwm)
+			if !status.Override.Until.IsZero() {
+				overrideText += fmt.Sprintf(" (until %s)", status.Override.Until.Format(time.RFC3339))
+			}
+		}
+
+		fmt.Printf("PWM: %s\nRPM: %s\nOverride: %s\n", pwmText, rpmText, overrideText)
+		return nil
+	},
+}
+
+func init() {
+	Command.AddCommand(statusCmd)
+}
@@ -7,4 +7,8 @@ var (
 	NoColor bool
 	NoStyle bool
 	Verbose bool
+
+	// LogLevel and LogFile override the logging configuration if set
+	LogLevel string
+	LogFile  string
 )
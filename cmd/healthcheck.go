@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/markusressel/fan2go/internal/api"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var healthcheckQuiet bool
+
+var healthcheckCmd = &cobra.Command{
+	Use:   "healthcheck",
+	Short: "Check whether the running daemon is healthy",
+	Long: `Checks whether all sensors, fans and fan controllers of a running fan2go daemon are operating,
+exiting with status 0 if it is healthy and 1 otherwise. The daemon is queried via the control socket
+if 'socket.enabled' is set in the config, otherwise via the REST api, which requires 'api.enabled'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pterm.DisableOutput()
+
+		configuration.DetectAndReadConfigFile()
+		configuration.LoadConfig()
+
+		var client *api.Client
+		switch {
+		case configuration.CurrentConfig.Socket.Enabled:
+			client = api.NewSocketClient(configuration.CurrentConfig.Socket.Path)
+			if client == nil {
+				return errors.New("no running fan2go daemon reachable via the control socket")
+			}
+		case configuration.CurrentConfig.Api.Enabled:
+			client = api.NewApiClient(configuration.CurrentConfig.Api.Host, configuration.CurrentConfig.Api.Port)
+		default:
+			return errors.New("neither the control socket nor the api is enabled, enable one of them using 'socket.enabled' or 'api.enabled' in the config")
+		}
+
+		health, err := client.GetHealth()
+		if err != nil {
+			return err
+		}
+
+		if health.Healthy {
+			if !healthcheckQuiet {
+				fmt.Println("healthy")
+			}
+			return nil
+		}
+		if !healthcheckQuiet {
+			fmt.Println("unhealthy")
+			for _, problem := range health.Problems {
+				fmt.Printf("%s %s: %s\n", problem.Kind, problem.ID, problem.Message)
+			}
+		}
+		os.Exit(1)
+		return nil
+	},
+}
+
+func init() {
+	healthcheckCmd.Flags().BoolVarP(&healthcheckQuiet, "quiet", "q", false, "Don't print anything, only set the exit status")
+	rootCmd.AddCommand(healthcheckCmd)
+}
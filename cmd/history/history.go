@@ -0,0 +1,93 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/api"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/history"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportSince  time.Duration
+	exportOutput string
+)
+
+var Command = &cobra.Command{
+	Use:   "history",
+	Short: "History related commands",
+	Long: `History related commands.
+
+The history is recorded by a running fan2go daemon, which requires 'history.enabled'
+and the control socket to be enabled.`,
+	TraverseChildren: true,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the recorded sensor and fan values",
+	Long:  ``,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pterm.DisableOutput()
+
+		client, err := getDaemonClient()
+		if err != nil {
+			return err
+		}
+		samples, err := client.GetHistory(exportSince)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if len(exportOutput) > 0 {
+			file, err := os.Create(exportOutput)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+
+		switch exportFormat {
+		case "csv":
+			return history.WriteCsv(out, samples)
+		case "json":
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(samples)
+		default:
+			return fmt.Errorf("unsupported format '%s', must be one of: csv | json", exportFormat)
+		}
+	},
+}
+
+func getDaemonClient() (*api.Client, error) {
+	configuration.DetectAndReadConfigFile()
+	configuration.LoadConfig()
+
+	if !configuration.CurrentConfig.Socket.Enabled {
+		return nil, errors.New("the control socket is disabled, enable it using 'socket.enabled' in the config")
+	}
+	client := api.NewSocketClient(configuration.CurrentConfig.Socket.Path)
+	if client == nil {
+		return nil, errors.New("no running fan2go daemon reachable via the control socket")
+	}
+	return client, nil
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "csv", "Output format, one of: csv | json")
+	exportCmd.Flags().DurationVarP(&exportSince, "since", "s", 0, "Only export samples of this duration, f.ex. 10m, all samples if 0")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "File to write the exported samples to, defaults to stdout")
+	Command.AddCommand(exportCmd)
+}
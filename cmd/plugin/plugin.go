@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/markusressel/fan2go/cmd/global"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/plugins"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+	"github.com/tomlazar/table"
+)
+
+var Command = &cobra.Command{
+	Use:   "plugin",
+	Short: "Plugin related commands",
+	Long: `Plugin related commands.
+
+Plugins are executables named fan2go-plugin-<name> in the directory configured by pluginDir,
+which provide sensors and/or fans.`,
+	TraverseChildren: true,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all plugins and the features they provide",
+	Long:  ``,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath := configuration.DetectAndReadConfigFile()
+		ui.Info("Using configuration file at: %s", configPath)
+		configuration.LoadConfig()
+
+		dir := configuration.GetPluginDir()
+		pluginList, err := plugins.List(dir)
+		if err != nil {
+			return err
+		}
+		if len(pluginList) <= 0 {
+			ui.Info("No plugins found in %s", dir)
+			return nil
+		}
+
+		var rows [][]string
+		for _, plugin := range pluginList {
+			description, err := plugin.Describe(plugins.DefaultTimeout)
+			if err != nil {
+				ui.Warning("%v", err)
+				rows = append(rows, []string{plugin.Name, "N/A", ""})
+				continue
+			}
+			var features []string
+			if description.Sensor {
+				features = append(features, "sensor")
+			}
+			if description.Fan {
+				features = append(features, "fan")
+			}
+			if description.Rpm {
+				features = append(features, "rpm")
+			}
+			if description.Pwm {
+				features = append(features, "pwm")
+			}
+			rows = append(rows, []string{plugin.Name, strings.Join(features, ", "), description.Description})
+		}
+
+		tab := table.Table{
+			Headers: []string{"Name", "Features", "Description"},
+			Rows:    rows,
+		}
+		var buf bytes.Buffer
+		tableErr := tab.WriteTable(&buf, &table.Config{
+			ShowIndex:       false,
+			Color:           !global.NoColor,
+			AlternateColors: true,
+			TitleColorCode:  ansi.ColorCode("white+buf"),
+			AltColorCodes: []string{
+				ansi.ColorCode("white"),
+				ansi.ColorCode("white:236"),
+			},
+		})
+		if tableErr != nil {
+			return tableErr
+		}
+		ui.Printfln(buf.String())
+
+		return nil
+	},
+}
+
+func init() {
+	Command.AddCommand(listCmd)
+}
@@ -0,0 +1,116 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/markusressel/fan2go/internal/api"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var Command = &cobra.Command{
+	Use:   "profile",
+	Short: "Profile related commands",
+	Long: `Profile related commands.
+
+Profiles are switched on a running fan2go daemon, which requires the control socket to be enabled.`,
+	TraverseChildren: true,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all configured profiles, the active one is marked with '*'",
+	Long:  ``,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pterm.DisableOutput()
+
+		client, err := getDaemonClient()
+		if err != nil {
+			return err
+		}
+		response, err := client.GetProfiles()
+		if err != nil {
+			return err
+		}
+
+		for _, profile := range response.Profiles {
+			marker := " "
+			if profile.ID == response.Active {
+				marker = "*"
+			}
+			var settings []string
+			for _, fan := range profile.Fans {
+				setting := fan.Fan + ":"
+				if len(fan.Curve) > 0 {
+					setting += " curve=" + fan.Curve
+				}
+				if fan.MaxPwm != nil {
+					setting += " maxPwm=" + strconv.Itoa(*fan.MaxPwm)
+				}
+				settings = append(settings, setting)
+			}
+			fmt.Printf("%s %s\t%s\n", marker, profile.ID, strings.Join(settings, ", "))
+		}
+		return nil
+	},
+}
+
+var setCmd = &cobra.Command{
+	Use:   "set <id>",
+	Short: "Activate the given profile",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pterm.DisableOutput()
+
+		client, err := getDaemonClient()
+		if err != nil {
+			return err
+		}
+		return client.SetActiveProfile(args[0])
+	},
+}
+
+var resetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Deactivate the active profile, using the regular fan settings",
+	Long:  ``,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pterm.DisableOutput()
+
+		client, err := getDaemonClient()
+		if err != nil {
+			return err
+		}
+		return client.SetActiveProfile("")
+	},
+}
+
+func init() {
+	Command.AddCommand(listCmd)
+	Command.AddCommand(setCmd)
+	Command.AddCommand(resetCmd)
+}
+
+// getDaemonClient returns a client for the control socket of the running daemon
+func getDaemonClient() (*api.Client, error) {
+	configPath := configuration.DetectAndReadConfigFile()
+	ui.Info("Using configuration file at: %s", configPath)
+	configuration.LoadConfig()
+
+	if !configuration.CurrentConfig.Socket.Enabled {
+		return nil, errors.New("the control socket is disabled, enable it using 'socket.enabled' in the config")
+	}
+	client := api.NewSocketClient(configuration.CurrentConfig.Socket.Path)
+	if client == nil {
+		return nil, errors.New("no running fan2go daemon reachable via the control socket")
+	}
+	return client, nil
+}
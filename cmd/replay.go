@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/history"
+	"github.com/markusressel/fan2go/internal/replay"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replaySpeed  float64
+	replayOutput string
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <trace>",
+	Short: "Replay recorded sensor values and print the resulting PWM decisions",
+	Long: `Feeds the sensor values of a trace written by "fan2go history export" (CSV or JSON) to the
+curves of the current configuration and prints the resulting PWM of every fan as CSV, next to
+the PWM recorded in the trace. No fans or sensors are accessed, so changes to curves can be
+evaluated against a real workload on any machine.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pterm.DisableOutput()
+
+		configPath := configuration.DetectAndReadConfigFile()
+		configuration.LoadConfig()
+		err := configuration.Validate(configPath)
+		if err != nil {
+			return err
+		}
+
+		samples, err := history.ReadTrace(args[0])
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if len(replayOutput) > 0 {
+			file, err := os.Create(replayOutput)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		config := &configuration.CurrentConfig
+		fanIds := replay.FanIds(config)
+		writer := csv.NewWriter(out)
+		err = writer.Write(replay.CsvHeader(fanIds))
+		if err != nil {
+			return err
+		}
+		err = replay.Run(ctx, config, samples, replaySpeed, func(step replay.Step) error {
+			if err := writer.Write(replay.CsvRow(fanIds, step)); err != nil {
+				return err
+			}
+			// flush every row, so the output can be followed while replaying
+			writer.Flush()
+			return writer.Error()
+		})
+		if err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+	},
+}
+
+func init() {
+	replayCmd.Flags().Float64VarP(&replaySpeed, "speed", "s", 10, "Speedup factor of the replay, 0 replays as fast as possible")
+	replayCmd.Flags().StringVarP(&replayOutput, "output", "o", "", "File to write the decisions to, defaults to stdout")
+	rootCmd.AddCommand(replayCmd)
+}
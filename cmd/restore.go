@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/markusressel/fan2go/internal"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore the original pwm and pwm_enable values of all fans after a crash",
+	Long: `fan2go stores the pwm and pwm_enable values of all fans in the database before taking control of them,
+and restores them when it exits. If the daemon didn't exit cleanly, f.ex. because it crashed, this command
+writes the stored values back, so the fans aren't stuck in manual mode. The daemon does the same on its next start.
+Make sure the fan2go daemon is stopped, since it would take control of the fans again.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath := configuration.DetectAndReadConfigFile()
+		ui.Info("Using configuration file at: %s", configPath)
+		configuration.LoadConfig()
+		err := configuration.Validate(configPath)
+		if err != nil {
+			return err
+		}
+		if configuration.CurrentConfig.NoDb {
+			return errors.New("fans can't be restored with noDb enabled, since their original state is only kept in memory")
+		}
+
+		dbPath := configuration.CurrentConfig.DbPath
+		ui.Info("Using persistence at: %s", dbPath)
+		restored, err := internal.RestoreFans(persistence.NewPersistence(dbPath))
+		if err != nil {
+			return err
+		}
+		if restored <= 0 {
+			ui.Info("No fans to restore, fan2go exited cleanly")
+			return nil
+		}
+		ui.Success("Restored %d fans", restored)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
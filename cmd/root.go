@@ -8,14 +8,19 @@ import (
 
 	"github.com/markusressel/fan2go/cmd/config"
 	"github.com/markusressel/fan2go/cmd/curve"
+	"github.com/markusressel/fan2go/cmd/db"
 	"github.com/markusressel/fan2go/cmd/fan"
 	"github.com/markusressel/fan2go/cmd/global"
+	"github.com/markusressel/fan2go/cmd/history"
+	"github.com/markusressel/fan2go/cmd/plugin"
+	"github.com/markusressel/fan2go/cmd/profile"
 	"github.com/markusressel/fan2go/cmd/sensor"
 	"github.com/markusressel/fan2go/internal"
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -36,6 +41,7 @@ on your computer based on temperature sensors.`,
 			ui.ErrorAndNotify("Config Validation Error", err.Error())
 			return
 		}
+		setupLogging()
 
 		internal.RunDaemon()
 	},
@@ -46,12 +52,22 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&global.NoColor, "no-color", "", false, "Disable all terminal output coloration")
 	rootCmd.PersistentFlags().BoolVarP(&global.NoStyle, "no-style", "", false, "Disable all terminal output styling")
 	rootCmd.PersistentFlags().BoolVarP(&global.Verbose, "verbose", "v", false, "More verbose output")
+	rootCmd.PersistentFlags().StringVarP(&global.LogLevel, "log-level", "", "", "Minimum level of logged messages, one of: debug | info | warn | error")
+	rootCmd.PersistentFlags().StringVarP(&global.LogFile, "log-file", "", "", "Additionally write log messages to this file")
+	rootCmd.Flags().Bool("no-db", false, "Keep calibration data in memory only, instead of writing it to the database")
+	_ = viper.BindPFlag("NoDb", rootCmd.Flags().Lookup("no-db"))
+	rootCmd.Flags().Bool("dry-run", false, "Log the PWM values that would be applied, without ever writing to any fan")
+	_ = viper.BindPFlag("DryRun", rootCmd.Flags().Lookup("dry-run"))
 
 	rootCmd.AddCommand(config.Command)
 
 	rootCmd.AddCommand(fan.Command)
 	rootCmd.AddCommand(curve.Command)
 	rootCmd.AddCommand(sensor.Command)
+	rootCmd.AddCommand(profile.Command)
+	rootCmd.AddCommand(db.Command)
+	rootCmd.AddCommand(history.Command)
+	rootCmd.AddCommand(plugin.Command)
 }
 
 func setupUi() {
@@ -65,6 +81,61 @@ func setupUi() {
 	}
 }
 
+// setupLogging applies the logging configuration, command line flags take precedence
+func setupLogging() {
+	config := configuration.CurrentConfig.Logging
+
+	levelName := config.Level
+	if len(global.LogLevel) > 0 {
+		levelName = global.LogLevel
+	}
+	level, err := ui.ParseLevel(levelName)
+	if err != nil {
+		ui.Warning("%v, using info instead", err)
+		level = ui.LevelInfo
+	}
+	if global.Verbose {
+		level = ui.LevelDebug
+	}
+	ui.SetLevel(level)
+	ui.SetDebugSubsystems(config.Debug)
+
+	var sinks []ui.Sink
+	var sink ui.Sink
+	switch config.Output {
+	case configuration.LogOutputJournald:
+		sink, err = ui.NewJournaldSink()
+	case configuration.LogOutputSyslog:
+		sink, err = ui.NewSyslogSink()
+	default:
+		sink = ui.NewConsoleSink()
+	}
+	if err != nil {
+		ui.Warning("Unable to log to %s, logging to the console instead: %v", config.Output, err)
+		sink = ui.NewConsoleSink()
+	}
+	sinks = append(sinks, sink)
+
+	filePath := config.File.Path
+	if len(global.LogFile) > 0 {
+		filePath = global.LogFile
+	}
+	if len(filePath) > 0 {
+		fileSink, err := ui.NewFileSink(filePath, config.File.MaxSize, config.File.MaxBackups)
+		if err != nil {
+			ui.Warning("Unable to open log file %s: %v", filePath, err)
+		} else {
+			sinks = append(sinks, fileSink)
+		}
+	}
+
+	if len(sinks) == 1 {
+		ui.SetSink(sinks[0])
+	} else {
+		ui.SetSink(ui.NewMultiSink(sinks...))
+	}
+}
+
 // Print a large text with the LetterStyle from the standard theme.
 func printHeader() {
 	err := pterm.DefaultBigText.WithLetters(
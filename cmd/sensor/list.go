@@ -0,0 +1,120 @@
+package sensor
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/markusressel/fan2go/cmd/global"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+	"github.com/tomlazar/table"
+)
+
+var showMovingAvg bool
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print the current values of all configured sensors",
+	Long:  ``,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		loadConfig()
+		controllers := hwmon.GetChips()
+
+		var sensorList []sensors.Sensor
+		for _, config := range configuration.GetSensors() {
+			sensor, err := createSensor(config.ID, controllers)
+			if err != nil {
+				ui.Warning("Unable to process sensor configuration %s: %v", config.ID, err)
+				continue
+			}
+			sensors.SetSensor(config.ID, sensor)
+			sensorList = append(sensorList, sensor)
+		}
+
+		headers := []string{"ID", "Value"}
+		if showMovingAvg {
+			headers = append(headers, "Avg")
+			measureMovingAvg(sensorList)
+		}
+
+		var rows [][]string
+		for _, sensor := range sensorList {
+			valueText := "N/A"
+			value, err := sensor.GetValue()
+			if err == nil {
+				valueText = fmt.Sprintf("%d", int(value))
+			} else {
+				ui.Warning("Error reading sensor %s: %v", sensor.GetId(), err)
+			}
+
+			row := []string{sensor.GetId(), valueText}
+			if showMovingAvg {
+				row = append(row, fmt.Sprintf("%d", int(sensor.GetMovingAvg())))
+			}
+			rows = append(rows, row)
+		}
+
+		tab := table.Table{
+			Headers: headers,
+			Rows:    rows,
+		}
+		var buf bytes.Buffer
+		tableErr := tab.WriteTable(&buf, &table.Config{
+			ShowIndex:       false,
+			Color:           !global.NoColor,
+			AlternateColors: true,
+			TitleColorCode:  ansi.ColorCode("white+buf"),
+			AltColorCodes: []string{
+				ansi.ColorCode("white"),
+				ansi.ColorCode("white:236"),
+			},
+		})
+		if tableErr != nil {
+			return tableErr
+		}
+		ui.Printfln(buf.String())
+
+		return nil
+	},
+}
+
+// measureMovingAvg samples all given sensors for a full rolling window,
+// the same way the daemon does
+func measureMovingAvg(sensorList []sensors.Sensor) {
+	pollingRate := configuration.CurrentConfig.TempSensorPollingRate
+	n := configuration.CurrentConfig.TempRollingWindowSize
+	ui.Info("Measuring moving averages over %v...", pollingRate*time.Duration(n))
+
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			time.Sleep(pollingRate)
+		}
+		for _, sensor := range sensorList {
+			value, err := sensor.GetValue()
+			if err != nil {
+				continue
+			}
+			if i == 0 {
+				sensor.SetMovingAvg(value)
+			} else {
+				sensors.UpdateMovingAvg(sensor, value)
+			}
+		}
+	}
+}
+
+func init() {
+	listCmd.Flags().BoolVarP(
+		&showMovingAvg,
+		"avg", "a",
+		false,
+		"Also print the moving average of each sensor",
+	)
+	Command.AddCommand(listCmd)
+}
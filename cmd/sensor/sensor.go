@@ -1,14 +1,15 @@
 package sensor
 
 import (
+	"errors"
 	"fmt"
 	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/expression"
 	"github.com/markusressel/fan2go/internal/hwmon"
 	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
-	"regexp"
 )
 
 var sensorId string
@@ -43,10 +44,9 @@ func init() {
 		"",
 		"Sensor ID as specified in the config",
 	)
-	_ = Command.MarkPersistentFlagRequired("id")
 }
 
-func getSensor(id string) (sensors.Sensor, error) {
+func loadConfig() {
 	configPath := configuration.DetectAndReadConfigFile()
 	ui.Info("Using configuration file at: %s", configPath)
 	configuration.LoadConfig()
@@ -54,18 +54,29 @@ func getSensor(id string) (sensors.Sensor, error) {
 	if err != nil {
 		ui.Fatal(err.Error())
 	}
+}
+
+func getSensor(id string) (sensors.Sensor, error) {
+	if len(id) <= 0 {
+		return nil, errors.New("required flag \"id\" not set")
+	}
 
+	loadConfig()
 	controllers := hwmon.GetChips()
+	return createSensor(id, controllers)
+}
 
+func createSensor(id string, controllers []*hwmon.HwMonController) (sensors.Sensor, error) {
 	availableSensorIds := []string{}
-	for _, config := range configuration.CurrentConfig.Sensors {
+	for _, config := range configuration.GetSensors() {
 		availableSensorIds = append(availableSensorIds, config.ID)
 		if config.ID == id {
 			if config.HwMon != nil {
+				match := hwmon.DeviceMatch{Platform: config.HwMon.Platform, Name: config.HwMon.Name, Modalias: config.HwMon.Modalias, PciAddress: config.HwMon.PciAddress}
 				for _, controller := range controllers {
-					matched, err := regexp.MatchString("(?i)"+config.HwMon.Platform, controller.Platform)
+					matched, err := match.Matches(controller)
 					if err != nil {
-						return nil, fmt.Errorf("Failed to match platform regex of %s (%s) against controller platform %s", config.ID, config.HwMon.Platform, controller.Platform)
+						return nil, fmt.Errorf("sensor %s: %v", config.ID, err)
 					}
 					if matched {
 						sensor, exists := controller.Sensors[config.HwMon.Index]
@@ -80,6 +91,39 @@ func getSensor(id string) (sensors.Sensor, error) {
 				}
 			}
 
+			if config.AmdGpu != nil {
+				err := hwmon.UpdateAmdGpuSensorConfigFromHwMonControllers(controllers, &config)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if config.Virtual != nil {
+				// virtual sensors need all sensors they reference
+				for _, sensorId := range config.Virtual.Sensors {
+					sensor, err := createSensor(sensorId, controllers)
+					if err != nil {
+						return nil, err
+					}
+					sensors.SetSensor(sensorId, sensor)
+				}
+			}
+
+			if config.Expression != nil {
+				// expression sensors need all sensors they reference
+				parsed, err := expression.Parse(config.Expression.Formula)
+				if err != nil {
+					return nil, fmt.Errorf("sensor %s: invalid formula: %v", config.ID, err)
+				}
+				for _, sensorId := range parsed.Variables() {
+					sensor, err := createSensor(sensorId, controllers)
+					if err != nil {
+						return nil, err
+					}
+					sensors.SetSensor(sensorId, sensor)
+				}
+			}
+
 			sensor, err := sensors.NewSensor(config)
 			if err != nil {
 				return nil, err
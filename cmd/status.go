@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/api"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusOutputFormat string
+	statusErrorCount   int
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the status of the running daemon",
+	Long: `Prints a consolidated status of a running fan2go daemon: the target and actual PWM, RPM,
+curve and state of each fan, the last errors and the uptime. The status is queried via the
+control socket, which requires 'socket.enabled' in the config.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configuration.DetectAndReadConfigFile()
+		configuration.LoadConfig()
+
+		if !configuration.CurrentConfig.Socket.Enabled {
+			return errors.New("the control socket is disabled, enable it using 'socket.enabled' in the config")
+		}
+		client := api.NewSocketClient(configuration.CurrentConfig.Socket.Path)
+		if client == nil {
+			return errors.New("no running fan2go daemon reachable via the control socket")
+		}
+
+		status, err := client.GetStatus()
+		if err != nil {
+			return err
+		}
+
+		switch statusOutputFormat {
+		case "":
+			text, err := renderStatus(status, statusErrorCount)
+			if err != nil {
+				return err
+			}
+			fmt.Print(text)
+			return nil
+		case "json":
+			out, err := json.MarshalIndent(status, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(append(out, '\n'))
+			return err
+		default:
+			return fmt.Errorf("unsupported output format '%s', use one of: json", statusOutputFormat)
+		}
+	},
+}
+
+// renderStatus formats the given status as a header line, a table of fans, the quarantined
+// sensors and fans and a list of the last errorCount errors
+func renderStatus(status *api.DaemonStatus, errorCount int) (string, error) {
+	var result strings.Builder
+
+	profile := status.Profile
+	if len(profile) <= 0 {
+		profile = "none"
+	}
+	uptime := (time.Duration(status.Uptime) * time.Second).String()
+	result.WriteString(fmt.Sprintf("fan2go - up %s (since %s) - profile: %s", uptime, status.StartTime.Format(time.RFC3339), profile))
+	if status.DryRun {
+		result.WriteString(" - dry run")
+	}
+	result.WriteString("\n\n")
+
+	var fanRows [][]string
+	fanIds := make([]string, 0, len(status.Fans))
+	for id := range status.Fans {
+		fanIds = append(fanIds, id)
+	}
+	sort.Strings(fanIds)
+	for _, id := range fanIds {
+		fan := status.Fans[id]
+		stateText := string(fan.State)
+		if len(stateText) <= 0 {
+			stateText = "N/A"
+		}
+		if fan.Override != nil {
+			stateText = fmt.Sprintf("%s (%d)", stateText, fan.Override.Pwm)
+		}
+		lastErrorText := ""
+		if fan.LastError != nil {
+			lastErrorText = fmt.Sprintf("%s %s", fan.LastError.Time.Format("15:04:05"), fan.LastError.Message)
+		}
+		fanRows = append(fanRows, []string{
+			id,
+			fan.Curve,
+			formatOptionalInt(fan.CurveValue),
+			formatOptionalInt(fan.TargetPwm),
+			formatOptionalInt(fan.Pwm),
+			formatOptionalInt(fan.Rpm),
+			stateText,
+			lastErrorText,
+		})
+	}
+	err := writeTopTable(&result, []string{"Fan", "Curve", "Curve Value", "Target PWM", "PWM", "RPM", "State", "Last Error"}, fanRows)
+	if err != nil {
+		return "", err
+	}
+
+	if len(status.Quarantine) > 0 {
+		result.WriteString("Quarantined:\n")
+		for _, entry := range status.Quarantine {
+			result.WriteString(fmt.Sprintf("%s %s since %s (%d retries): %s\n", entry.Kind, entry.ID, entry.Since.Format(time.RFC3339), entry.Retries, entry.Error))
+		}
+		result.WriteString("\n")
+	}
+
+	errorEntries := status.Errors
+	if errorCount < 0 {
+		errorCount = 0
+	}
+	if len(errorEntries) > errorCount {
+		errorEntries = errorEntries[len(errorEntries)-errorCount:]
+	}
+	if len(errorEntries) <= 0 {
+		result.WriteString("No errors\n")
+		return result.String(), nil
+	}
+	result.WriteString("Last errors:\n")
+	for _, entry := range errorEntries {
+		result.WriteString(fmt.Sprintf("%s %s: %s\n", entry.Time.Format(time.RFC3339), entry.Level, entry.Message))
+	}
+	return result.String(), nil
+}
+
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return "N/A"
+	}
+	return fmt.Sprintf("%d", *value)
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(
+		&statusOutputFormat,
+		"output", "o",
+		"",
+		"Print the status in a machine readable format, one of: json",
+	)
+	statusCmd.Flags().IntVarP(
+		&statusErrorCount,
+		"errors", "e",
+		10,
+		"Number of recent errors to print",
+	)
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/markusressel/fan2go/cmd/global"
+	"github.com/markusressel/fan2go/internal/api"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/mgutz/ansi"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/tomlazar/table"
+)
+
+var topInterval time.Duration
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show live sensor, fan and curve values of the running daemon",
+	Long: `Shows the current values of all sensors, fans and curves of a running fan2go daemon,
+refreshing them in place until interrupted. The values are queried via the control socket,
+which requires 'socket.enabled' in the config.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configuration.DetectAndReadConfigFile()
+		configuration.LoadConfig()
+
+		if !configuration.CurrentConfig.Socket.Enabled {
+			return errors.New("the control socket is disabled, enable it using 'socket.enabled' in the config")
+		}
+		socketPath := configuration.CurrentConfig.Socket.Path
+		client := api.NewSocketClient(socketPath)
+		if client == nil {
+			return errors.New("no running fan2go daemon reachable via the control socket")
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		area, err := pterm.DefaultArea.WithRemoveWhenDone(false).Start()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = area.Stop()
+		}()
+
+		tick := time.NewTicker(topInterval)
+		defer tick.Stop()
+		for {
+			metrics, err := client.GetMetrics()
+			if err != nil {
+				area.Update(fmt.Sprintf("Unable to query the daemon at %s: %v\n", socketPath, err))
+			} else {
+				text, err := renderTop(metrics)
+				if err != nil {
+					return err
+				}
+				area.Update(text)
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-tick.C:
+			}
+		}
+	},
+}
+
+// renderTop formats the given metrics as a header line followed by a table of fans, sensors and curves
+func renderTop(metrics *api.MetricsUpdate) (string, error) {
+	var result strings.Builder
+
+	profile := metrics.Profile
+	if len(profile) <= 0 {
+		profile = "none"
+	}
+	result.WriteString(fmt.Sprintf("fan2go - %s - profile: %s\n\n", metrics.Timestamp.Format("15:04:05"), profile))
+
+	var fanRows [][]string
+	fanIds := make([]string, 0, len(metrics.Fans))
+	for id := range metrics.Fans {
+		fanIds = append(fanIds, id)
+	}
+	sort.Strings(fanIds)
+	for _, id := range fanIds {
+		fan := metrics.Fans[id]
+		pwmText := "N/A"
+		if fan.Pwm != nil {
+			pwmText = fmt.Sprintf("%d", *fan.Pwm)
+		}
+		curveValueText := "N/A"
+		if curve, ok := metrics.Curves[fan.Curve]; ok {
+			curveValueText = fmt.Sprintf("%d", curve.Value)
+		}
+		stateText := string(fan.State)
+		if fan.Override != nil {
+			stateText = fmt.Sprintf("%s (%d)", stateText, fan.Override.Pwm)
+		}
+		fanRows = append(fanRows, []string{id, fan.Curve, curveValueText, pwmText, fmt.Sprintf("%d", int(fan.RpmAvg)), stateText})
+	}
+	err := writeTopTable(&result, []string{"Fan", "Curve", "Curve Value", "PWM", "RPM", "State"}, fanRows)
+	if err != nil {
+		return "", err
+	}
+
+	var sensorRows [][]string
+	sensorIds := make([]string, 0, len(metrics.Sensors))
+	for id := range metrics.Sensors {
+		sensorIds = append(sensorIds, id)
+	}
+	sort.Strings(sensorIds)
+	for _, id := range sensorIds {
+		sensorRows = append(sensorRows, []string{id, fmt.Sprintf("%d", int(metrics.Sensors[id].MovingAvg))})
+	}
+	err = writeTopTable(&result, []string{"Sensor", "Value"}, sensorRows)
+	if err != nil {
+		return "", err
+	}
+
+	var curveRows [][]string
+	curveIds := make([]string, 0, len(metrics.Curves))
+	for id := range metrics.Curves {
+		curveIds = append(curveIds, id)
+	}
+	sort.Strings(curveIds)
+	for _, id := range curveIds {
+		curveRows = append(curveRows, []string{id, fmt.Sprintf("%d", metrics.Curves[id].Value)})
+	}
+	err = writeTopTable(&result, []string{"Curve", "Value"}, curveRows)
+	if err != nil {
+		return "", err
+	}
+
+	return result.String(), nil
+}
+
+func writeTopTable(out *strings.Builder, headers []string, rows [][]string) error {
+	tab := table.Table{
+		Headers: headers,
+		Rows:    rows,
+	}
+	var buf bytes.Buffer
+	err := tab.WriteTable(&buf, &table.Config{
+		ShowIndex:       false,
+		Color:           !global.NoColor,
+		AlternateColors: true,
+		TitleColorCode:  ansi.ColorCode("white+buf"),
+		AltColorCodes: []string{
+			ansi.ColorCode("white"),
+			ansi.ColorCode("white:236"),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	out.WriteString(buf.String())
+	out.WriteString("\n")
+	return nil
+}
+
+func init() {
+	topCmd.Flags().DurationVarP(
+		&topInterval,
+		"interval", "n",
+		1*time.Second,
+		"Time between two refreshes",
+	)
+	rootCmd.AddCommand(topCmd)
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/grpc"
+	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/oklog/run"
+)
+
+// RunAgent polls all configured sensors and serves their values using the gRPC api, and the REST api
+// if it is enabled, without controlling any fans. Other fan2go instances read them using `remote` sensors.
+func RunAgent() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sensorList, err := createAgentSensors(configuration.GetSensors())
+	if err != nil {
+		ui.Fatal("%v, exiting.", err)
+	}
+	ui.Info("Serving %d sensors", len(sensorList))
+
+	poller := newSensorPoller(configuration.CurrentConfig.TempSensorPollingRate)
+	poller.SetSensors(sensorList)
+
+	var g run.Group
+	{
+		// === sensor monitors
+		g.Add(func() error {
+			poller.Run(ctx)
+			return nil
+		}, func(err error) {
+			cancel()
+		})
+	}
+	{
+		// === gRPC api, which is always served by the agent, read-only since it doesn't control any fans
+		grpcConfig := configuration.CurrentConfig.Grpc
+		if grpcConfig.Tls == nil || len(grpcConfig.Token) <= 0 {
+			ui.Warning("The gRPC api of the agent is not protected, configure grpc.tls and grpc.token if it is reachable from untrusted networks")
+		}
+		g.Add(func() error {
+			ui.Info("Starting gRPC api...")
+			return grpc.Run(ctx, grpcConfig, true)
+		}, func(err error) {
+			cancel()
+		})
+	}
+	if configuration.CurrentConfig.Api.Enabled {
+		g.Add(func() error {
+			restServer := startRestServer()
+			<-ctx.Done()
+			timeoutCtx, timeoutCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer timeoutCancel()
+			return restServer.Shutdown(timeoutCtx)
+		}, func(err error) {
+			cancel()
+		})
+	}
+	{
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+
+		g.Add(func() error {
+			select {
+			case <-sig:
+				ui.Info("Received SIGTERM signal, exiting...")
+			case <-ctx.Done():
+			}
+			return nil
+		}, func(err error) {
+			cancel()
+		})
+	}
+
+	if err := g.Run(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ui.Info("Done.")
+}
+
+// createAgentSensors creates the given sensors, publishes them in the sensor map and reads their initial values
+func createAgentSensors(sensorConfigs []configuration.SensorConfig) ([]sensors.Sensor, error) {
+	controllers := hwmon.GetChips()
+
+	sensorMap := map[string]sensors.Sensor{}
+	var result []sensors.Sensor
+	for _, sensorConfig := range sensorConfigs {
+		err := resolveSensorConfig(&sensorConfig, controllers)
+		if err != nil {
+			return nil, err
+		}
+		sensor, err := sensors.NewSensor(sensorConfig)
+		if err != nil {
+			return nil, fmt.Errorf("unable to process sensor configuration %s: %v", sensorConfig.ID, err)
+		}
+		sensorMap[sensorConfig.ID] = sensor
+		result = append(result, sensor)
+	}
+	sensors.SetSensorMap(sensorMap)
+
+	// virtual sensors depend on other sensors, so they are read after all sensors have been published
+	for _, sensor := range result {
+		value, err := sensor.GetValue()
+		if err != nil {
+			ui.Warning("Error reading sensor %s: %v", sensor.GetId(), err)
+		}
+		sensor.SetMovingAvg(value)
+	}
+	return result, nil
+}
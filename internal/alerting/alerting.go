@@ -0,0 +1,173 @@
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+const (
+	// EventFanFailure is fired when a fan cannot be controlled or stopped rotating
+	EventFanFailure = "fanFailure"
+	// EventSensorFailure is fired when a sensor cannot be read
+	EventSensorFailure = "sensorFailure"
+	// EventFailsafe is fired when a fan controller enters failsafe mode
+	EventFailsafe = "failsafe"
+	// EventCriticalTemperature is fired when a sensor reaches its critical temperature
+	EventCriticalTemperature = "criticalTemperature"
+
+	defaultEmailPort = 25
+	sendTimeout      = 10 * time.Second
+)
+
+// Alert is the payload sent to all alert targets
+type Alert struct {
+	Event string `json:"event"`
+	// Id is the id of the fan or sensor the alert is about
+	Id      string    `json:"id"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+var (
+	// lastAlerts maps from event and id -> time the last alert was sent
+	lastAlerts     = map[string]time.Time{}
+	lastAlertsLock sync.Mutex
+)
+
+// Fire sends an alert about the given fan or sensor to all configured targets in the background.
+// Repeated alerts for the same event and id are suppressed during the configured cooldown.
+func Fire(event string, id string, format string, a ...interface{}) {
+	config := configuration.CurrentConfig.Alerting
+	if !config.Enabled {
+		return
+	}
+
+	alert := Alert{
+		Event:   event,
+		Id:      id,
+		Message: fmt.Sprintf(format, a...),
+		Time:    time.Now(),
+	}
+	if !shouldSend(alert, config.Cooldown) {
+		ui.Debug("Suppressing %s alert for %s during cooldown", event, id)
+		return
+	}
+
+	go send(config, alert)
+}
+
+// shouldSend indicates whether the given alert is sent, or suppressed since
+// the last alert for the same event and id was sent less than cooldown ago
+func shouldSend(alert Alert, cooldown time.Duration) bool {
+	lastAlertsLock.Lock()
+	defer lastAlertsLock.Unlock()
+
+	key := alert.Event + "/" + alert.Id
+	if last, ok := lastAlerts[key]; ok && alert.Time.Sub(last) < cooldown {
+		return false
+	}
+	lastAlerts[key] = alert.Time
+	return true
+}
+
+func send(config configuration.AlertingConfig, alert Alert) {
+	if config.Webhook != nil {
+		if err := sendWebhook(*config.Webhook, alert); err != nil {
+			ui.Warning("Error sending alert to webhook: %v", err)
+		}
+	}
+	if config.Email != nil {
+		if err := sendEmail(*config.Email, alert); err != nil {
+			ui.Warning("Error sending alert email: %v", err)
+		}
+	}
+	if config.Exec != nil {
+		if err := runExec(*config.Exec, alert); err != nil {
+			ui.Warning("Error executing alert command: %v", err)
+		}
+	}
+}
+
+// sendWebhook posts the alert as a JSON object to the configured url
+func sendWebhook(config configuration.WebhookAlertConfig, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, config.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range config.Headers {
+		request.Header.Set(key, value)
+	}
+
+	client := http.Client{Timeout: sendTimeout}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from %s", response.StatusCode, config.Url)
+	}
+	return nil
+}
+
+// sendEmail sends the alert as a plain text email to all configured recipients
+func sendEmail(config configuration.EmailAlertConfig, alert Alert) error {
+	port := config.Port
+	if port <= 0 {
+		port = defaultEmailPort
+	}
+
+	var auth smtp.Auth
+	if len(config.Username) > 0 {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	address := fmt.Sprintf("%s:%d", config.Host, port)
+	return smtp.SendMail(address, auth, config.From, config.To, formatEmail(config, alert))
+}
+
+func formatEmail(config configuration.EmailAlertConfig, alert Alert) []byte {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("From: %s\r\n", config.From))
+	message.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(config.To, ", ")))
+	message.WriteString(fmt.Sprintf("Subject: fan2go: %s of %s\r\n", alert.Event, alert.Id))
+	message.WriteString(fmt.Sprintf("Date: %s\r\n", alert.Time.Format(time.RFC1123Z)))
+	message.WriteString("\r\n")
+	message.WriteString(alert.Message)
+	message.WriteString("\r\n")
+	return []byte(message.String())
+}
+
+// runExec executes the configured command, replacing the placeholders in its arguments
+func runExec(config configuration.ExecConfig, alert Alert) error {
+	replacer := strings.NewReplacer(
+		"%event%", alert.Event,
+		"%id%", alert.Id,
+		"%message%", alert.Message,
+	)
+
+	var args []string
+	for _, arg := range config.Args {
+		args = append(args, replacer.Replace(arg))
+	}
+
+	_, err := util.SafeCmdExecution(config.Exec, args, sendTimeout)
+	return err
+}
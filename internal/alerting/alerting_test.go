@@ -0,0 +1,99 @@
+package alerting
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendWebhook(t *testing.T) {
+	// GIVEN
+	var received Alert
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	config := configuration.WebhookAlertConfig{
+		Url:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}
+	alert := Alert{
+		Event:   EventFanFailure,
+		Id:      "cpu",
+		Message: "Fan cpu stalled",
+	}
+
+	// WHEN
+	err := sendWebhook(config, alert)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token", authorization)
+	assert.Equal(t, alert.Event, received.Event)
+	assert.Equal(t, alert.Id, received.Id)
+	assert.Equal(t, alert.Message, received.Message)
+}
+
+func TestSendWebhookErrorStatus(t *testing.T) {
+	// GIVEN
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := configuration.WebhookAlertConfig{
+		Url: server.URL,
+	}
+
+	// WHEN
+	err := sendWebhook(config, Alert{Event: EventSensorFailure, Id: "cpu"})
+
+	// THEN
+	assert.Error(t, err)
+}
+
+func TestShouldSendSuppressesDuringCooldown(t *testing.T) {
+	// GIVEN
+	now := time.Now()
+	first := Alert{Event: EventSensorFailure, Id: "cooldown_sensor", Time: now}
+	repeated := Alert{Event: EventSensorFailure, Id: "cooldown_sensor", Time: now.Add(time.Minute)}
+	other := Alert{Event: EventFanFailure, Id: "cooldown_sensor", Time: now.Add(time.Minute)}
+	expired := Alert{Event: EventSensorFailure, Id: "cooldown_sensor", Time: now.Add(10 * time.Minute)}
+
+	// THEN
+	assert.True(t, shouldSend(first, 5*time.Minute))
+	assert.False(t, shouldSend(repeated, 5*time.Minute))
+	assert.True(t, shouldSend(other, 5*time.Minute))
+	assert.True(t, shouldSend(expired, 5*time.Minute))
+}
+
+func TestFormatEmail(t *testing.T) {
+	// GIVEN
+	config := configuration.EmailAlertConfig{
+		From: "fan2go@example.com",
+		To:   []string{"admin@example.com", "ops@example.com"},
+	}
+	alert := Alert{
+		Event:   EventFailsafe,
+		Id:      "cpu",
+		Message: "Fan cpu entered failsafe mode",
+		Time:    time.Now(),
+	}
+
+	// WHEN
+	message := string(formatEmail(config, alert))
+
+	// THEN
+	assert.True(t, strings.Contains(message, "To: admin@example.com, ops@example.com\r\n"))
+	assert.True(t, strings.Contains(message, "Subject: fan2go: failsafe of cpu\r\n"))
+	assert.True(t, strings.HasSuffix(message, "\r\nFan cpu entered failsafe mode\r\n"))
+}
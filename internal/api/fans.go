@@ -2,9 +2,12 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"github.com/labstack/echo/v4"
+	"github.com/markusressel/fan2go/internal/controller"
 	"github.com/markusressel/fan2go/internal/fans"
 	"net/http"
+	"time"
 )
 
 func registerFanEndpoints(rest *echo.Echo) {
@@ -14,17 +17,36 @@ func registerFanEndpoints(rest *echo.Echo) {
 	group.GET("/:"+urlParamId+"/", getFan)
 	group.POST("/", createFan)
 	group.DELETE("/:"+urlParamId+"/", deleteFan)
+
+	group.GET("/:"+urlParamId+"/status/", getFanStatus)
+	group.GET("/:"+urlParamId+"/override/", getFanOverride)
+	group.POST("/:"+urlParamId+"/override/", setFanOverride)
+	group.DELETE("/:"+urlParamId+"/override/", clearFanOverride)
+}
+
+// FanStatus is the current state of a fan
+type FanStatus struct {
+	Pwm      *int                    `json:"pwm"`
+	Rpm      *int                    `json:"rpm"`
+	Override *controller.PwmOverride `json:"override"`
+}
+
+type fanOverrideRequest struct {
+	// Pwm is the pwm value [0..255] to use instead of the curve value
+	Pwm *int `json:"pwm"`
+	// Duration is the duration of the override, f.ex. "10m", forever if empty
+	Duration string `json:"duration"`
 }
 
 // returns a list of all currently configured fans
 func getFans(c echo.Context) error {
-	data := fans.FanMap
+	data := fans.GetFanMap()
 	return c.JSONPretty(http.StatusOK, data, indentationChar)
 }
 
 func getFan(c echo.Context) error {
 	id := c.Param(urlParamId)
-	data, exists := fans.FanMap[id]
+	data, exists := fans.GetFan(id)
 	if !exists {
 		return returnNotFound(c, id)
 	} else {
@@ -39,3 +61,75 @@ func deleteFan(c echo.Context) error {
 func createFan(c echo.Context) error {
 	return returnError(c, errors.New("not yet supported"))
 }
+
+// returns the current pwm, rpm and override of a fan
+func getFanStatus(c echo.Context) error {
+	id := c.Param(urlParamId)
+	if _, exists := fans.GetFan(id); !exists {
+		return returnNotFound(c, id)
+	}
+
+	// fans are only accessed by their controllers, so the values cached by them are used
+	status := FanStatus{}
+	if fanController, exists := controller.GetFanController(id); exists {
+		snapshot := fanController.GetFanSnapshot()
+		status.Pwm = snapshot.Pwm
+		status.Rpm = snapshot.Rpm
+		status.Override = fanController.GetOverride()
+	}
+
+	return c.JSONPretty(http.StatusOK, status, indentationChar)
+}
+
+// returns the currently active override of a fan, if any
+func getFanOverride(c echo.Context) error {
+	id := c.Param(urlParamId)
+	fanController, exists := controller.GetFanController(id)
+	if !exists {
+		return returnNotFound(c, id)
+	}
+	override := fanController.GetOverride()
+	if override == nil {
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.JSONPretty(http.StatusOK, override, indentationChar)
+}
+
+// temporarily overrides the curve of a fan with a fixed pwm value
+func setFanOverride(c echo.Context) error {
+	id := c.Param(urlParamId)
+	fanController, exists := controller.GetFanController(id)
+	if !exists {
+		return returnNotFound(c, id)
+	}
+
+	request := fanOverrideRequest{}
+	if err := c.Bind(&request); err != nil {
+		return returnBadRequest(c, err)
+	}
+	if request.Pwm == nil || *request.Pwm < fans.MinPwmValue || *request.Pwm > fans.MaxPwmValue {
+		return returnBadRequest(c, fmt.Errorf("pwm must be in range [%d..%d]", fans.MinPwmValue, fans.MaxPwmValue))
+	}
+	var duration time.Duration
+	if len(request.Duration) > 0 {
+		var err error
+		duration, err = time.ParseDuration(request.Duration)
+		if err != nil || duration <= 0 {
+			return returnBadRequest(c, fmt.Errorf("invalid duration '%s'", request.Duration))
+		}
+	}
+
+	fanController.SetOverride(*request.Pwm, duration)
+	return c.JSONPretty(http.StatusOK, fanController.GetOverride(), indentationChar)
+}
+
+// removes the override of a fan, returning control to its curve
+func clearFanOverride(c echo.Context) error {
+	id := c.Param(urlParamId)
+	fanController, exists := controller.GetFanController(id)
+	if !exists {
+		return returnNotFound(c, id)
+	}
+	fanController.ClearOverride()
+	return c.NoContent(http.StatusOK)
+}
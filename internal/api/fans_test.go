@@ -0,0 +1,32 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFanStatus_UsesControllerSnapshot(t *testing.T) {
+	// GIVEN
+	createHealthTestObjects(t)
+
+	server := httptest.NewServer(CreateRestService())
+	defer server.Close()
+
+	// WHEN
+	response, err := http.Get(server.URL + "/fan/cpu/status/")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	status := FanStatus{}
+	err = json.NewDecoder(response.Body).Decode(&status)
+
+	// THEN
+	// the controller didn't update the fan yet, the fan itself is not read
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Nil(t, status.Pwm)
+	assert.Nil(t, status.Rpm)
+}
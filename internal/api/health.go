@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/quarantine"
+	"github.com/markusressel/fan2go/internal/sensors"
+)
+
+const (
+	HealthProblemSensor     = "sensor"
+	HealthProblemFan        = "fan"
+	HealthProblemController = "controller"
+)
+
+// HealthStatus is the result of the health check of the daemon
+type HealthStatus struct {
+	Healthy bool `json:"healthy"`
+	// Problems are all sensors, fans and controllers which are not operating, empty if healthy
+	Problems []HealthProblem `json:"problems"`
+}
+
+// HealthProblem describes a sensor, fan or controller which is not operating
+type HealthProblem struct {
+	// Kind of the object, one of: sensor | fan | controller
+	Kind    string `json:"kind"`
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+func registerHealthEndpoint(rest *echo.Echo) {
+	rest.GET("/healthz/", getHealth)
+}
+
+// returns the health status of the daemon, with status code 503 if it is unhealthy
+func getHealth(c echo.Context) error {
+	health := checkHealth()
+	code := http.StatusOK
+	if !health.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+	return c.JSONPretty(code, health, indentationChar)
+}
+
+// checkHealth checks whether all sensors can be read, all fans can be accessed, all fan
+// controllers are running without a failsafe or stalled fan and nothing is quarantined
+func checkHealth() *HealthStatus {
+	problems := []HealthProblem{}
+
+	for id := range sensors.GetSensorMap() {
+		if since, failing := sensors.GetFailingSince(id); failing {
+			problems = append(problems, HealthProblem{
+				Kind:    HealthProblemSensor,
+				ID:      id,
+				Message: fmt.Sprintf("unreadable since %s", since.Format(time.RFC3339)),
+			})
+		}
+	}
+
+	for id := range fans.GetFanMap() {
+		fanController, exists := controller.GetFanController(id)
+		if !exists {
+			problems = append(problems, HealthProblem{
+				Kind:    HealthProblemController,
+				ID:      id,
+				Message: "no controller",
+			})
+			continue
+		}
+
+		// fans are only accessed by their controllers, so the values cached by them are used
+		if err := fanController.GetFanSnapshot().PwmErr; err != nil {
+			problems = append(problems, HealthProblem{
+				Kind:    HealthProblemFan,
+				ID:      id,
+				Message: fmt.Sprintf("unable to read pwm: %v", err),
+			})
+		}
+		message := ""
+		switch fanController.GetState() {
+		case controller.ControllerStateStopped:
+			message = "stopped"
+		case controller.ControllerStateFailsafe:
+			message = "in failsafe mode, since a sensor of its curve is unreadable"
+		case controller.ControllerStateStallKick:
+			message = "fan is stalled"
+		}
+		if len(message) > 0 {
+			problems = append(problems, HealthProblem{
+				Kind:    HealthProblemController,
+				ID:      id,
+				Message: message,
+			})
+		}
+	}
+
+	for _, entry := range quarantine.Get() {
+		kind := HealthProblemSensor
+		if entry.Kind == quarantine.KindFan {
+			kind = HealthProblemFan
+		}
+		problems = append(problems, HealthProblem{
+			Kind:    kind,
+			ID:      entry.ID,
+			Message: fmt.Sprintf("quarantined since %s: %s", entry.Since.Format(time.RFC3339), entry.Error),
+		})
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].Kind != problems[j].Kind {
+			return problems[i].Kind < problems[j].Kind
+		}
+		return problems[i].ID < problems[j].ID
+	})
+
+	return &HealthStatus{
+		Healthy:  len(problems) <= 0,
+		Problems: problems,
+	}
+}
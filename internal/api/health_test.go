@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func createHealthTestObjects(t *testing.T) {
+	pwmPath := path.Join(t.TempDir(), "pwm")
+	err := os.WriteFile(pwmPath, []byte("100"), 0644)
+	assert.NoError(t, err)
+	fan := &fans.FileFan{
+		Config: configuration.FanConfig{
+			ID:   "cpu",
+			File: &configuration.FileFanConfig{Path: pwmPath},
+		},
+	}
+	fans.SetFanMap(map[string]fans.Fan{fan.GetId(): fan})
+	controller.SetFanControllerMap(map[string]controller.FanController{
+		fan.GetId(): controller.NewFanController(nil, fan, *util.NewPidLoop(0.03, 0.002, 0.0005), time.Second),
+	})
+
+	sensor := &sensors.FileSensor{
+		Config: configuration.SensorConfig{
+			ID: "cpu_temp",
+		},
+	}
+	sensors.SetSensorMap(map[string]sensors.Sensor{sensor.GetId(): sensor})
+
+	t.Cleanup(func() {
+		fans.SetFanMap(map[string]fans.Fan{})
+		controller.SetFanControllerMap(map[string]controller.FanController{})
+		sensors.SetSensorMap(map[string]sensors.Sensor{})
+		sensors.ReportReadResult("cpu_temp", nil)
+	})
+}
+
+func TestGetHealth(t *testing.T) {
+	// GIVEN
+	createHealthTestObjects(t)
+
+	server := httptest.NewServer(CreateRestService())
+	defer server.Close()
+
+	// WHEN
+	response, err := http.Get(server.URL + "/healthz")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	health := HealthStatus{}
+	err = json.NewDecoder(response.Body).Decode(&health)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, health.Healthy)
+	assert.Empty(t, health.Problems)
+}
+
+func TestGetHealth_Unhealthy(t *testing.T) {
+	// GIVEN
+	createHealthTestObjects(t)
+	sensors.ReportReadResult("cpu_temp", errors.New("read error"))
+	brokenFan := &fans.FileFan{
+		Config: configuration.FanConfig{
+			ID:   "broken",
+			File: &configuration.FileFanConfig{Path: path.Join(t.TempDir(), "missing")},
+		},
+	}
+	fans.SetFan(brokenFan.GetId(), brokenFan)
+	brokenController := controller.NewFanController(nil, brokenFan, *util.NewPidLoop(0.03, 0.002, 0.0005), time.Second)
+	controller.SetFanController(brokenFan.GetId(), brokenController)
+	// the pwm read error is cached by the controller
+	err := brokenController.UpdateFanSpeed()
+	assert.Error(t, err)
+	fans.SetFan("unmanaged", &fans.FileFan{
+		Config: configuration.FanConfig{
+			ID:   "unmanaged",
+			File: &configuration.FileFanConfig{Path: path.Join(t.TempDir(), "missing")},
+		},
+	})
+
+	server := httptest.NewServer(CreateRestService())
+	defer server.Close()
+
+	// WHEN
+	health, err := NewApiClient("127.0.0.1", server.Listener.Addr().(*net.TCPAddr).Port).GetHealth()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.False(t, health.Healthy)
+	assert.Len(t, health.Problems, 3)
+	assert.Equal(t, HealthProblem{Kind: HealthProblemController, ID: "unmanaged", Message: "no controller"}, health.Problems[0])
+	assert.Equal(t, HealthProblemFan, health.Problems[1].Kind)
+	assert.Equal(t, "broken", health.Problems[1].ID)
+	assert.Equal(t, HealthProblemSensor, health.Problems[2].Kind)
+	assert.Equal(t, "cpu_temp", health.Problems[2].ID)
+}
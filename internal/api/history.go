@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/markusressel/fan2go/internal/history"
+)
+
+func registerHistoryEndpoints(rest *echo.Echo) {
+	group := rest.Group("/history")
+
+	group.GET("/", getHistory)
+}
+
+// returns all recorded samples, optionally only those of the duration given by the "since" query parameter
+func getHistory(c echo.Context) error {
+	if !history.IsEnabled() {
+		return returnBadRequest(c, errors.New("history recording is disabled, enable it using 'history.enabled' in the config"))
+	}
+
+	since := time.Time{}
+	if param := c.QueryParam("since"); len(param) > 0 {
+		duration, err := time.ParseDuration(param)
+		if err != nil {
+			return returnBadRequest(c, fmt.Errorf("invalid duration '%s'", param))
+		}
+		since = time.Now().Add(-duration)
+	}
+
+	return c.JSONPretty(http.StatusOK, history.GetSamples(since), indentationChar)
+}
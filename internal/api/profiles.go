@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/profiles"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+// ProfilesResponse lists all configured profiles and the currently active one
+type ProfilesResponse struct {
+	// Active is the id of the active profile, empty if none is active
+	Active   string                        `json:"active"`
+	Profiles []configuration.ProfileConfig `json:"profiles"`
+}
+
+type activeProfileRequest struct {
+	// ID is the id of the profile to activate, an empty id deactivates the current profile
+	ID string `json:"id"`
+}
+
+func registerProfileEndpoints(rest *echo.Echo) {
+	group := rest.Group("/profile")
+
+	group.GET("/", getProfiles)
+	group.GET("/active/", getProfiles)
+	group.POST("/active/", setActiveProfile)
+}
+
+func getProfiles(c echo.Context) error {
+	return c.JSONPretty(http.StatusOK, ProfilesResponse{
+		Active:   profiles.GetActive(),
+		Profiles: configuration.GetProfiles(),
+	}, indentationChar)
+}
+
+// activates the profile with the given id
+func setActiveProfile(c echo.Context) error {
+	request := activeProfileRequest{}
+	if err := c.Bind(&request); err != nil {
+		return returnBadRequest(c, err)
+	}
+
+	if err := profiles.SetActive(request.ID); err != nil {
+		return returnNotFound(c, request.ID)
+	}
+	if len(request.ID) > 0 {
+		ui.Info("Activated profile %s", request.ID)
+	} else {
+		ui.Info("Deactivated profile")
+	}
+
+	return getProfiles(c)
+}
@@ -21,13 +21,17 @@ func CreateRestService() *echo.Echo {
 	echoRest := CreateWebserver()
 
 	echoRest.GET("/alive/", isAlive)
+	registerHealthEndpoint(echoRest)
 
 	// Authentication
 	// Group level middleware
 	registerFanEndpoints(echoRest)
 	registerSensorEndpoints(echoRest)
 	registerCurveEndpoints(echoRest)
-	//registerWebsocketEndpoint(echoRest)
+	registerProfileEndpoints(echoRest)
+	registerHistoryEndpoints(echoRest)
+	registerStatusEndpoint(echoRest)
+	registerWebsocketEndpoint(echoRest)
 
 	return echoRest
 }
@@ -52,3 +56,11 @@ func returnError(c echo.Context, e error) (err error) {
 		Message: e.Error(),
 	}, indentationChar)
 }
+
+// return a "bad request" message
+func returnBadRequest(c echo.Context, e error) (err error) {
+	return c.JSONPretty(http.StatusBadRequest, &Result{
+		Name:    "Bad Request",
+		Message: e.Error(),
+	}, indentationChar)
+}
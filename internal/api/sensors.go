@@ -17,14 +17,14 @@ func registerSensorEndpoints(rest *echo.Echo) {
 }
 
 func getSensors(c echo.Context) error {
-	data := sensors.SensorMap
+	data := sensors.GetSensorMap()
 	return c.JSONPretty(http.StatusOK, data, indentationChar)
 }
 
 func getSensor(c echo.Context) error {
 	id := c.Param(urlParamId)
 
-	data, exists := sensors.SensorMap[id]
+	data, exists := sensors.GetSensor(id)
 	if !exists {
 		return returnNotFound(c, id)
 	} else {
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/history"
+)
+
+// ListenUnixSocket creates the unix domain socket of the given configuration,
+// replacing a stale socket left behind by a previous run
+func ListenUnixSocket(config configuration.SocketConfig) (net.Listener, error) {
+	if info, err := os.Stat(config.Path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", config.Path)
+		}
+		if err := os.Remove(config.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", config.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := strconv.ParseUint(config.Permissions, 8, 32)
+	if err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("invalid socket permissions '%s'", config.Permissions)
+	}
+	if err := os.Chmod(config.Path, os.FileMode(permissions)); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	if len(config.Group) > 0 {
+		group, err := user.LookupGroup(config.Group)
+		if err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+		gid, _ := strconv.Atoi(group.Gid)
+		if err := os.Chown(config.Path, -1, gid); err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+	}
+
+	return listener, nil
+}
+
+// Client talks to the api of a running daemon via its unix domain socket or the network
+type Client struct {
+	http *http.Client
+	// baseUrl is prepended to the path of all requests
+	baseUrl string
+}
+
+// NewSocketClient returns a client for the daemon listening on the given socket,
+// or nil if there is no daemon listening on it
+func NewSocketClient(path string) *Client {
+	if info, err := os.Stat(path); err != nil || info.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+
+	client := &Client{
+		http: &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+		baseUrl: "http://fan2go",
+	}
+
+	if _, err := client.request(http.MethodGet, "/alive/", nil, nil); err != nil {
+		return nil
+	}
+	return client
+}
+
+// NewApiClient returns a client for the REST api of the daemon listening on the given host and port
+func NewApiClient(host string, port int) *Client {
+	return &Client{
+		http:    &http.Client{Timeout: 5 * time.Second},
+		baseUrl: fmt.Sprintf("http://%s", net.JoinHostPort(host, strconv.Itoa(port))),
+	}
+}
+
+// GetFanStatus returns the current status of the given fan
+func (c *Client) GetFanStatus(id string) (*FanStatus, error) {
+	result := &FanStatus{}
+	_, err := c.request(http.MethodGet, "/fan/"+id+"/status/", nil, result)
+	return result, err
+}
+
+// SetFanOverride overrides the curve of the given fan with a fixed pwm value,
+// forever if duration is 0
+func (c *Client) SetFanOverride(id string, pwm int, duration time.Duration) error {
+	request := fanOverrideRequest{
+		Pwm: &pwm,
+	}
+	if duration > 0 {
+		request.Duration = duration.String()
+	}
+	_, err := c.request(http.MethodPost, "/fan/"+id+"/override/", request, nil)
+	return err
+}
+
+// ClearFanOverride removes the override of the given fan
+func (c *Client) ClearFanOverride(id string) error {
+	_, err := c.request(http.MethodDelete, "/fan/"+id+"/override/", nil, nil)
+	return err
+}
+
+// GetProfiles returns all configured profiles and the currently active one
+func (c *Client) GetProfiles() (*ProfilesResponse, error) {
+	result := &ProfilesResponse{}
+	_, err := c.request(http.MethodGet, "/profile/", nil, result)
+	return result, err
+}
+
+// SetActiveProfile activates the profile with the given id, an empty id deactivates the current profile
+func (c *Client) SetActiveProfile(id string) error {
+	_, err := c.request(http.MethodPost, "/profile/active/", activeProfileRequest{ID: id}, nil)
+	return err
+}
+
+// GetMetrics returns the current values of all sensors, fans and curves
+func (c *Client) GetMetrics() (*MetricsUpdate, error) {
+	result := &MetricsUpdate{}
+	_, err := c.request(http.MethodGet, "/metrics/", nil, result)
+	return result, err
+}
+
+// GetStatus returns the consolidated status of the daemon
+func (c *Client) GetStatus() (*DaemonStatus, error) {
+	result := &DaemonStatus{}
+	_, err := c.request(http.MethodGet, "/status/", nil, result)
+	return result, err
+}
+
+// GetHealth returns the health status of the daemon, which is returned even if it is unhealthy
+func (c *Client) GetHealth() (*HealthStatus, error) {
+	response, err := c.http.Get(c.baseUrl + "/healthz/")
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusServiceUnavailable {
+		return nil, fmt.Errorf("request failed with status %d", response.StatusCode)
+	}
+	result := &HealthStatus{}
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetHistory returns the recorded samples of the given duration, all samples if duration is 0
+func (c *Client) GetHistory(duration time.Duration) ([]history.Sample, error) {
+	path := "/history/"
+	if duration > 0 {
+		path += "?since=" + duration.String()
+	}
+	var result []history.Sample
+	_, err := c.request(http.MethodGet, path, nil, &result)
+	return result, err
+}
+
+func (c *Client) request(method string, path string, body interface{}, result interface{}) (int, error) {
+	var requestBody *strings.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		requestBody = strings.NewReader(string(data))
+	} else {
+		requestBody = strings.NewReader("")
+	}
+
+	request, err := http.NewRequest(method, c.baseUrl+path, requestBody)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		message := &Result{}
+		if err := json.NewDecoder(response.Body).Decode(message); err == nil && len(message.Message) > 0 {
+			return response.StatusCode, errors.New(message.Message)
+		}
+		return response.StatusCode, fmt.Errorf("request failed with status %d", response.StatusCode)
+	}
+
+	if result != nil && response.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+			return response.StatusCode, err
+		}
+	}
+	return response.StatusCode, nil
+}
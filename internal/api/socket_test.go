@@ -0,0 +1,101 @@
+package api
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenUnixSocket(t *testing.T) {
+	// GIVEN
+	config := configuration.SocketConfig{
+		Enabled:     true,
+		Path:        path.Join(t.TempDir(), "fan2go.sock"),
+		Permissions: "0600",
+	}
+
+	// WHEN
+	listener, err := ListenUnixSocket(config)
+
+	// THEN
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	info, err := os.Stat(config.Path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.ModeSocket, info.Mode()&os.ModeSocket)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestListenUnixSocket_NoSocket(t *testing.T) {
+	// GIVEN
+	config := configuration.SocketConfig{
+		Enabled:     true,
+		Path:        path.Join(t.TempDir(), "fan2go.sock"),
+		Permissions: "0660",
+	}
+	err := os.WriteFile(config.Path, []byte("data"), 0644)
+	assert.NoError(t, err)
+
+	// WHEN
+	_, err = ListenUnixSocket(config)
+
+	// THEN
+	assert.Error(t, err)
+}
+
+func TestListenUnixSocket_InvalidPermissions(t *testing.T) {
+	// GIVEN
+	config := configuration.SocketConfig{
+		Enabled:     true,
+		Path:        path.Join(t.TempDir(), "fan2go.sock"),
+		Permissions: "rw-rw----",
+	}
+
+	// WHEN
+	_, err := ListenUnixSocket(config)
+
+	// THEN
+	assert.Error(t, err)
+}
+
+func TestNewSocketClient(t *testing.T) {
+	// GIVEN
+	config := configuration.SocketConfig{
+		Enabled:     true,
+		Path:        path.Join(t.TempDir(), "fan2go.sock"),
+		Permissions: "0660",
+	}
+	listener, err := ListenUnixSocket(config)
+	assert.NoError(t, err)
+
+	server := CreateRestService()
+	server.Listener = listener
+	go func() {
+		_ = server.Start("")
+	}()
+	defer server.Close()
+
+	// WHEN
+	client := NewSocketClient(config.Path)
+
+	// THEN
+	assert.NotNil(t, client)
+
+	_, err = client.GetFanStatus("unknown")
+	assert.EqualError(t, err, "No item with id 'unknown' found")
+}
+
+func TestNewSocketClient_NoDaemon(t *testing.T) {
+	// GIVEN
+	socketPath := path.Join(t.TempDir(), "fan2go.sock")
+
+	// WHEN
+	client := NewSocketClient(socketPath)
+
+	// THEN
+	assert.Nil(t, client)
+}
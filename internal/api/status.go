@@ -0,0 +1,106 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/profiles"
+	"github.com/markusressel/fan2go/internal/quarantine"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+// startTime is the time the process was started at
+var startTime = time.Now()
+
+// DaemonStatus is a consolidated status of the running daemon
+type DaemonStatus struct {
+	StartTime time.Time `json:"startTime"`
+	// Uptime of the daemon in seconds
+	Uptime  float64                    `json:"uptime"`
+	Profile string                     `json:"profile"`
+	DryRun  bool                       `json:"dryRun"`
+	Fans    map[string]FanDaemonStatus `json:"fans"`
+	// Quarantine are all sensors and fans which failed and are retried periodically
+	Quarantine []quarantine.Entry `json:"quarantine"`
+	// Errors are the last warnings and errors logged by the daemon, oldest first
+	Errors []ui.LogEntry `json:"errors"`
+}
+
+// FanDaemonStatus is the state of a single fan, as seen by its controller
+type FanDaemonStatus struct {
+	Curve string `json:"curve"`
+	// CurveValue is the result of the last evaluation of the curve of the fan
+	CurveValue *int `json:"curveValue"`
+	// TargetPwm is the pwm value set by the controller, nil if it didn't set any yet
+	TargetPwm *int `json:"targetPwm"`
+	// Pwm is the pwm value read back from the fan
+	Pwm    *int    `json:"pwm"`
+	Rpm    *int    `json:"rpm"`
+	RpmAvg float64 `json:"rpmAvg"`
+	// State describes what determined the current pwm value, empty if the fan has no controller
+	State    controller.ControllerState `json:"state,omitempty"`
+	Override *controller.PwmOverride    `json:"override"`
+	// LastError is the last warning or error logged about the fan, if any
+	LastError *ui.LogEntry `json:"lastError"`
+}
+
+func registerStatusEndpoint(rest *echo.Echo) {
+	rest.GET("/status/", getStatus)
+}
+
+// returns the consolidated status of the daemon
+func getStatus(c echo.Context) error {
+	return c.JSONPretty(http.StatusOK, collectStatus(), indentationChar)
+}
+
+// collectStatus returns the current state of all fans and the last errors of the daemon
+func collectStatus() *DaemonStatus {
+	now := time.Now()
+	status := &DaemonStatus{
+		StartTime:  startTime,
+		Uptime:     now.Sub(startTime).Seconds(),
+		Profile:    profiles.GetActive(),
+		DryRun:     configuration.CurrentConfig.DryRun,
+		Fans:       map[string]FanDaemonStatus{},
+		Quarantine: quarantine.Get(),
+		Errors:     ui.RecentErrors(),
+	}
+
+	lastErrors := map[string]ui.LogEntry{}
+	for _, entry := range status.Errors {
+		if fanId, ok := entry.Fields[ui.FieldFan]; ok {
+			lastErrors[fanId] = entry
+		}
+	}
+
+	// fans are only accessed by their controllers, so the values cached by them are used
+	for id, fan := range fans.GetFanMap() {
+		fanStatus := FanDaemonStatus{
+			Curve: profiles.GetCurveId(id, fan.GetCurveId()),
+		}
+		if curve, ok := curves.GetSpeedCurve(fanStatus.Curve); ok {
+			value := curve.CurrentValue()
+			fanStatus.CurveValue = &value
+		}
+		if fanController, exists := controller.GetFanController(id); exists {
+			snapshot := fanController.GetFanSnapshot()
+			fanStatus.Pwm = snapshot.Pwm
+			fanStatus.Rpm = snapshot.Rpm
+			fanStatus.RpmAvg = snapshot.RpmAvg
+			fanStatus.TargetPwm = fanController.GetTargetPwm()
+			fanStatus.State = fanController.GetState()
+			fanStatus.Override = fanController.GetOverride()
+		}
+		if entry, ok := lastErrors[id]; ok {
+			fanStatus.LastError = &entry
+		}
+		status.Fans[id] = fanStatus
+	}
+
+	return status
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStatus(t *testing.T) {
+	// GIVEN
+	pwmPath := path.Join(t.TempDir(), "pwm")
+	err := os.WriteFile(pwmPath, []byte("100"), 0644)
+	assert.NoError(t, err)
+	fan := &fans.FileFan{
+		Config: configuration.FanConfig{
+			ID:    "cpu",
+			Curve: "cpu_curve",
+			File:  &configuration.FileFanConfig{Path: pwmPath},
+		},
+	}
+	fans.SetFanMap(map[string]fans.Fan{fan.GetId(): fan})
+	defer func() { fans.SetFanMap(map[string]fans.Fan{}) }()
+	controller.SetFanControllerMap(map[string]controller.FanController{
+		fan.GetId(): controller.NewFanController(nil, fan, *util.NewPidLoop(0.03, 0.002, 0.0005), time.Second),
+	})
+	defer func() { controller.SetFanControllerMap(map[string]controller.FanController{}) }()
+	curve := &curves.LinearSpeedCurve{
+		Config: configuration.CurveConfig{
+			ID: "cpu_curve",
+		},
+		Value: 128,
+	}
+	curves.SetSpeedCurveMap(map[string]curves.SpeedCurve{curve.GetId(): curve})
+	defer func() { curves.SetSpeedCurveMap(map[string]curves.SpeedCurve{}) }()
+
+	ui.WithFan("cpu").Warning("fan cpu is slow")
+
+	config := configuration.SocketConfig{
+		Enabled:     true,
+		Path:        path.Join(t.TempDir(), "fan2go.sock"),
+		Permissions: "0600",
+	}
+	listener, err := ListenUnixSocket(config)
+	assert.NoError(t, err)
+	server := CreateRestService()
+	server.Listener = listener
+	go func() {
+		_ = server.Start("")
+	}()
+	defer server.Close()
+	client := NewSocketClient(config.Path)
+	assert.NotNil(t, client)
+
+	// WHEN
+	status, err := client.GetStatus()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.True(t, status.Uptime >= 0)
+	assert.NotEmpty(t, status.Errors)
+
+	fanStatus, ok := status.Fans["cpu"]
+	assert.True(t, ok)
+	assert.Equal(t, "cpu_curve", fanStatus.Curve)
+	assert.Equal(t, 128, *fanStatus.CurveValue)
+	// the controller didn't update the fan yet, the fan itself is not read
+	assert.Nil(t, fanStatus.Pwm)
+	assert.Nil(t, fanStatus.TargetPwm)
+	assert.Nil(t, fanStatus.Rpm)
+	assert.NotNil(t, fanStatus.LastError)
+	assert.Equal(t, "fan cpu is slow", fanStatus.LastError.Message)
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/profiles"
+	"github.com/markusressel/fan2go/internal/sensors"
+)
+
+const (
+	defaultWebsocketInterval = 1 * time.Second
+	minWebsocketInterval     = 100 * time.Millisecond
+	websocketWriteTimeout    = 5 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	// the api is meant to be used by third party tools, which may be served from any origin
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// MetricsUpdate is a snapshot of all sensor and fan values, sent to websocket clients
+type MetricsUpdate struct {
+	Timestamp time.Time                `json:"timestamp"`
+	Profile   string                   `json:"profile"`
+	Sensors   map[string]SensorMetrics `json:"sensors"`
+	Fans      map[string]FanMetrics    `json:"fans"`
+	Curves    map[string]CurveMetrics  `json:"curves"`
+}
+
+type SensorMetrics struct {
+	MovingAvg float64 `json:"movingAvg"`
+}
+
+type FanMetrics struct {
+	Curve    string                  `json:"curve"`
+	Pwm      *int                    `json:"pwm"`
+	RpmAvg   float64                 `json:"rpmAvg"`
+	Override *controller.PwmOverride `json:"override"`
+	// State describes what determined the current pwm value, empty if the fan has no controller
+	State controller.ControllerState `json:"state,omitempty"`
+}
+
+type CurveMetrics struct {
+	// Value is the result of the last evaluation of the curve
+	Value int `json:"value"`
+}
+
+func registerWebsocketEndpoint(rest *echo.Echo) {
+	rest.GET("/ws/", streamMetrics)
+	rest.GET("/metrics/", getMetrics)
+}
+
+// returns a single MetricsUpdate with the current values
+func getMetrics(c echo.Context) error {
+	return c.JSONPretty(http.StatusOK, collectMetrics(), indentationChar)
+}
+
+// streams a MetricsUpdate to the client whenever a value changes,
+// checking for changes at the interval given by the "interval" query parameter
+func streamMetrics(c echo.Context) error {
+	interval := defaultWebsocketInterval
+	if param := c.QueryParam("interval"); len(param) > 0 {
+		parsed, err := time.ParseDuration(param)
+		if err != nil {
+			return returnBadRequest(c, fmt.Errorf("invalid interval '%s'", param))
+		}
+		if parsed < minWebsocketInterval {
+			parsed = minWebsocketInterval
+		}
+		interval = parsed
+	}
+
+	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// the client isn't expected to send anything, but reading is required to notice a closed connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *MetricsUpdate
+	for {
+		update := collectMetrics()
+		if last == nil || last.Profile != update.Profile || !reflect.DeepEqual(last.Sensors, update.Sensors) || !reflect.DeepEqual(last.Fans, update.Fans) || !reflect.DeepEqual(last.Curves, update.Curves) {
+			_ = conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout))
+			if err := conn.WriteJSON(update); err != nil {
+				return nil
+			}
+			last = update
+		}
+
+		select {
+		case <-closed:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// collectMetrics returns the current (cached) values of all sensors, fans and curves
+func collectMetrics() *MetricsUpdate {
+	update := &MetricsUpdate{
+		Timestamp: time.Now(),
+		Profile:   profiles.GetActive(),
+		Sensors:   map[string]SensorMetrics{},
+		Fans:      map[string]FanMetrics{},
+		Curves:    map[string]CurveMetrics{},
+	}
+
+	for id, sensor := range sensors.GetSensorMap() {
+		update.Sensors[id] = SensorMetrics{
+			MovingAvg: sensor.GetMovingAvg(),
+		}
+	}
+
+	// fans are only accessed by their controllers, so the values cached by them are used
+	curveIds := map[string]string{}
+	for _, fanConfig := range configuration.GetFans() {
+		curveIds[fanConfig.ID] = fanConfig.Curve
+	}
+	for id := range fans.GetFanMap() {
+		metrics := FanMetrics{
+			Curve: profiles.GetCurveId(id, curveIds[id]),
+		}
+		if fanController, exists := controller.GetFanController(id); exists {
+			snapshot := fanController.GetFanSnapshot()
+			metrics.Pwm = snapshot.Pwm
+			metrics.RpmAvg = snapshot.RpmAvg
+			metrics.Override = fanController.GetOverride()
+			metrics.State = fanController.GetState()
+		}
+		update.Fans[id] = metrics
+	}
+
+	for id, curve := range curves.GetSpeedCurveMap() {
+		update.Curves[id] = CurveMetrics{
+			Value: curve.CurrentValue(),
+		}
+	}
+
+	return update
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamMetrics(t *testing.T) {
+	// GIVEN
+	sensor := &sensors.FileSensor{
+		Config: configuration.SensorConfig{
+			ID: "cpu",
+		},
+		MovingAvg: 40000,
+	}
+	sensors.SetSensorMap(map[string]sensors.Sensor{sensor.GetId(): sensor})
+	defer func() { sensors.SetSensorMap(map[string]sensors.Sensor{}) }()
+
+	server := httptest.NewServer(CreateRestService())
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/?interval=100ms"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// WHEN
+	first := MetricsUpdate{}
+	err = conn.ReadJSON(&first)
+	assert.NoError(t, err)
+
+	sensor.SetMovingAvg(50000)
+
+	second := MetricsUpdate{}
+	err = conn.ReadJSON(&second)
+	assert.NoError(t, err)
+
+	// THEN
+	assert.Equal(t, 40000.0, first.Sensors["cpu"].MovingAvg)
+	assert.Equal(t, 50000.0, second.Sensors["cpu"].MovingAvg)
+}
+
+func TestStreamMetrics_InvalidInterval(t *testing.T) {
+	// GIVEN
+	server := httptest.NewServer(CreateRestService())
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/?interval=often"
+
+	// WHEN
+	_, response, err := websocket.DefaultDialer.Dial(url, nil)
+
+	// THEN
+	assert.Error(t, err)
+	assert.Equal(t, 400, response.StatusCode)
+}
+
+func TestGetMetrics(t *testing.T) {
+	// GIVEN
+	curve := &curves.LinearSpeedCurve{
+		Config: configuration.CurveConfig{
+			ID: "cpu_curve",
+		},
+		Value: 128,
+	}
+	curves.SetSpeedCurveMap(map[string]curves.SpeedCurve{curve.GetId(): curve})
+	defer func() { curves.SetSpeedCurveMap(map[string]curves.SpeedCurve{}) }()
+
+	server := httptest.NewServer(CreateRestService())
+	defer server.Close()
+
+	// WHEN
+	response, err := http.Get(server.URL + "/metrics/")
+	assert.NoError(t, err)
+	defer response.Body.Close()
+	update := MetricsUpdate{}
+	err = json.NewDecoder(response.Body).Decode(&update)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 128, update.Curves["cpu_curve"].Value)
+}
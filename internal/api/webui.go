@@ -0,0 +1,19 @@
+package api
+
+import (
+	"embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed webui
+var webUiAssets embed.FS
+
+// RegisterWebUi serves the embedded web ui dashboard at /ui
+func RegisterWebUi(rest *echo.Echo) {
+	rest.GET("/", func(c echo.Context) error {
+		return c.Redirect(http.StatusFound, "/ui/")
+	})
+	rest.StaticFS("/ui/", echo.MustSubFS(webUiAssets, "webui"))
+}
@@ -0,0 +1,236 @@
+"use strict";
+
+// number of updates kept for the history charts
+const historySize = 300;
+const colors = ["#42a5f5", "#ef5350", "#66bb6a", "#ffa726", "#ab47bc", "#26c6da", "#d4e157", "#8d6e63"];
+
+const sensorHistory = {};
+const fanHistory = {};
+let curves = {};
+let lastUpdate = null;
+
+function colorOf(index) {
+  return colors[index % colors.length];
+}
+
+function push(history, id, value) {
+  if (!history[id]) {
+    history[id] = [];
+  }
+  history[id].push(value);
+  if (history[id].length > historySize) {
+    history[id].shift();
+  }
+}
+
+function drawHistory(canvas, history, maxValue, unit) {
+  const ctx = canvas.getContext("2d");
+  const width = canvas.width;
+  const height = canvas.height;
+  ctx.clearRect(0, 0, width, height);
+
+  ctx.fillStyle = "#888";
+  ctx.font = "11px sans-serif";
+  ctx.fillText(maxValue + unit, 4, 12);
+  ctx.fillText("0" + unit, 4, height - 4);
+
+  Object.keys(history).sort().forEach((id, index) => {
+    const values = history[id];
+    ctx.strokeStyle = colorOf(index);
+    ctx.beginPath();
+    values.forEach((value, i) => {
+      const x = width - (values.length - 1 - i) * (width / historySize);
+      const y = height - (value / maxValue) * height;
+      if (i === 0) {
+        ctx.moveTo(x, y);
+      } else {
+        ctx.lineTo(x, y);
+      }
+    });
+    ctx.stroke();
+    ctx.fillStyle = colorOf(index);
+    ctx.fillText(id, 40 + index * 90, 12);
+  });
+}
+
+function drawCurve(canvas, curve, temperature) {
+  const ctx = canvas.getContext("2d");
+  const width = canvas.width;
+  const height = canvas.height;
+  const steps = curve.config.linear.steps;
+  let points;
+  if (steps && Object.keys(steps).length > 0) {
+    points = Object.keys(steps).map(Number).sort((a, b) => a - b).map(t => [t, steps[t]]);
+  } else {
+    points = [[curve.config.linear.min, 0], [curve.config.linear.max, 255]];
+  }
+  const minTemp = Math.min(points[0][0], temperature !== undefined ? temperature : points[0][0]) - 5;
+  const maxTemp = Math.max(points[points.length - 1][0], temperature !== undefined ? temperature : 0) + 5;
+  const toX = t => ((t - minTemp) / (maxTemp - minTemp)) * width;
+  const toY = v => height - (v / 255) * height;
+
+  ctx.clearRect(0, 0, width, height);
+  ctx.fillStyle = "#888";
+  ctx.font = "11px sans-serif";
+  ctx.fillText(minTemp + "°C", 2, height - 4);
+  ctx.fillText(maxTemp + "°C", width - 40, height - 4);
+
+  ctx.strokeStyle = colors[0];
+  ctx.beginPath();
+  ctx.moveTo(0, toY(points[0][1]));
+  points.forEach(([t, v]) => ctx.lineTo(toX(t), toY(v)));
+  ctx.lineTo(width, toY(points[points.length - 1][1]));
+  ctx.stroke();
+
+  if (temperature !== undefined) {
+    ctx.fillStyle = colors[1];
+    ctx.beginPath();
+    ctx.arc(toX(temperature), toY(curve.value), 4, 0, 2 * Math.PI);
+    ctx.fill();
+  }
+}
+
+function renderSensors(update) {
+  const body = document.querySelector("#sensor-table tbody");
+  body.innerHTML = "";
+  Object.keys(update.sensors).sort().forEach(id => {
+    const row = body.insertRow();
+    row.insertCell().textContent = id;
+    row.insertCell().textContent = (update.sensors[id].movingAvg / 1000).toFixed(1) + " °C";
+  });
+}
+
+function renderFans(update) {
+  const body = document.querySelector("#fan-table tbody");
+  Object.keys(update.fans).sort().forEach(id => {
+    const fan = update.fans[id];
+    let row = body.querySelector(`tr[data-id="${id}"]`);
+    if (!row) {
+      row = body.insertRow();
+      row.dataset.id = id;
+      for (let i = 0; i < 5; i++) {
+        row.insertCell();
+      }
+      const controls = row.insertCell();
+      const input = document.createElement("input");
+      input.type = "number";
+      input.min = 0;
+      input.max = 255;
+      const set = document.createElement("button");
+      set.textContent = "Override";
+      set.onclick = () => setOverride(id, parseInt(input.value, 10));
+      const release = document.createElement("button");
+      release.textContent = "Release";
+      release.onclick = () => clearOverride(id);
+      controls.append(input, set, release);
+    }
+    const cells = row.cells;
+    cells[0].textContent = id;
+    cells[1].textContent = fan.curve;
+    cells[2].textContent = fan.pwm !== null ? fan.pwm : "N/A";
+    cells[3].textContent = Math.round(fan.rpmAvg);
+    cells[4].textContent = fan.override ? fan.override.pwm : "none";
+  });
+}
+
+function renderCurves(update) {
+  const container = document.getElementById("curves");
+  Object.keys(curves).sort().forEach(id => {
+    const curve = curves[id];
+    if (!curve.config.linear) {
+      return;
+    }
+    let canvas = document.getElementById("curve-" + id);
+    if (!canvas) {
+      const div = document.createElement("div");
+      div.className = "curve";
+      const legend = document.createElement("div");
+      legend.className = "legend";
+      legend.textContent = id + " (" + curve.config.linear.sensor + ")";
+      canvas = document.createElement("canvas");
+      canvas.id = "curve-" + id;
+      canvas.width = 280;
+      canvas.height = 180;
+      div.append(legend, canvas);
+      container.append(div);
+    }
+    const sensor = update && update.sensors[curve.config.linear.sensor];
+    drawCurve(canvas, curve, sensor ? sensor.movingAvg / 1000 : undefined);
+  });
+}
+
+function onUpdate(update) {
+  lastUpdate = update;
+  document.getElementById("profile").value = update.profile;
+  Object.keys(update.sensors).forEach(id => push(sensorHistory, id, update.sensors[id].movingAvg / 1000));
+  Object.keys(update.fans).forEach(id => push(fanHistory, id, update.fans[id].pwm || 0));
+
+  drawHistory(document.getElementById("sensor-chart"), sensorHistory, 100, "°C");
+  drawHistory(document.getElementById("fan-chart"), fanHistory, 255, "");
+  renderSensors(update);
+  renderFans(update);
+  renderCurves(update);
+}
+
+function setOverride(id, pwm) {
+  if (isNaN(pwm)) {
+    return;
+  }
+  fetch(`../fan/${encodeURIComponent(id)}/override/`, {
+    method: "POST",
+    headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({pwm: pwm}),
+  });
+}
+
+function clearOverride(id) {
+  fetch(`../fan/${encodeURIComponent(id)}/override/`, {method: "DELETE"});
+}
+
+function loadProfiles() {
+  fetch("../profile/").then(response => response.json()).then(data => {
+    const select = document.getElementById("profile");
+    select.innerHTML = "";
+    select.append(new Option("none", ""));
+    (data.profiles || []).forEach(profile => select.append(new Option(profile.id, profile.id)));
+    select.value = data.active;
+    select.onchange = () => setProfile(select.value);
+  });
+}
+
+function setProfile(id) {
+  fetch("../profile/active/", {
+    method: "POST",
+    headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({id: id}),
+  });
+}
+
+function loadCurves() {
+  fetch("../curve/").then(response => response.json()).then(data => {
+    curves = data;
+    renderCurves(lastUpdate);
+  });
+}
+
+function connect() {
+  const protocol = location.protocol === "https:" ? "wss:" : "ws:";
+  const path = location.pathname.replace(/ui\/?$/, "ws/");
+  const socket = new WebSocket(`${protocol}//${location.host}${path}`);
+  const status = document.getElementById("status");
+
+  socket.onopen = () => {
+    status.textContent = "connected";
+    status.className = "status connected";
+  };
+  socket.onmessage = event => onUpdate(JSON.parse(event.data));
+  socket.onclose = () => {
+    status.textContent = "disconnected";
+    status.className = "status disconnected";
+    setTimeout(connect, 2000);
+  };
+}
+
+loadProfiles();
+loadCurves();
+connect();
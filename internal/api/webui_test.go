@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebUi(t *testing.T) {
+	// GIVEN
+	server := CreateRestService()
+	RegisterWebUi(server)
+
+	for _, path := range []string{"/ui/", "/ui/app.js", "/ui/style.css"} {
+		// WHEN
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request)
+
+		// THEN
+		assert.Equal(t, http.StatusOK, recorder.Code, path)
+		assert.NotEmpty(t, recorder.Body.String(), path)
+	}
+}
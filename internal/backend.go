@@ -8,22 +8,19 @@ import (
 	"os"
 	"os/signal"
 	"os/user"
-	"regexp"
 	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/markusressel/fan2go/internal/api"
 	"github.com/markusressel/fan2go/internal/configuration"
-	"github.com/markusressel/fan2go/internal/controller"
-	"github.com/markusressel/fan2go/internal/curves"
-	"github.com/markusressel/fan2go/internal/fans"
-	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/dbus"
+	"github.com/markusressel/fan2go/internal/grpc"
+	"github.com/markusressel/fan2go/internal/history"
 	"github.com/markusressel/fan2go/internal/persistence"
-	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/profiles"
 	"github.com/markusressel/fan2go/internal/statistics"
 	"github.com/markusressel/fan2go/internal/ui"
-	"github.com/markusressel/fan2go/internal/util"
 	"github.com/oklog/run"
 )
 
@@ -31,17 +28,43 @@ func RunDaemon() {
 	owner, err := getProcessOwner()
 	if err != nil {
 		ui.Warning("Unable to verify process owner: %v", err)
-	} else if owner != "root" {
-		ui.Info("fan2go is running as a non-root user '%s'. If you encounter errors, make sure to give this user the required permissions.", owner)
+	} else if os.Geteuid() != 0 {
+		ui.Info("fan2go is running as a non-root user '%s', write access to all fans is verified before controlling them.", owner)
 	}
 
-	pers := persistence.NewPersistence(configuration.CurrentConfig.DbPath)
+	if configuration.CurrentConfig.DryRun {
+		ui.Warning("Dry-run mode is enabled, PWM values are only logged and never written to any fan")
+	}
 
-	fanControllers := initializeObjects(pers)
+	pers, err := createPersistence()
+	if err != nil {
+		ui.Fatal("Unable to create persistence: %v, exiting.", err)
+	}
+
+	// fan states left over in the database mean that the last run didn't exit cleanly
+	if !configuration.CurrentConfig.DryRun {
+		restored, err := RestoreFans(pers)
+		if err != nil {
+			ui.Warning("Unable to restore fans after an unclean exit: %v", err)
+		} else if restored > 0 {
+			ui.Warning("fan2go didn't exit cleanly, restored the original state of %d fans", restored)
+		}
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	err = profiles.SetActive(configuration.CurrentConfig.Profile)
+	if err != nil {
+		ui.Fatal("%v, exiting.", err)
+	}
+
+	objects := newDaemonObjects(ctx, pers)
+	err = objects.apply(&configuration.CurrentConfig)
+	if err != nil {
+		ui.Fatal("%v, exiting.", err)
+	}
+
 	var g run.Group
 	{
 		if configuration.CurrentConfig.Profiling.Enabled {
@@ -74,7 +97,7 @@ func RunDaemon() {
 	}
 	{
 		// === Global Webserver
-		if configuration.CurrentConfig.Api.Enabled || configuration.CurrentConfig.Statistics.Enabled {
+		if configuration.CurrentConfig.Api.Enabled || configuration.CurrentConfig.Socket.Enabled || configuration.CurrentConfig.Statistics.Enabled {
 			g.Add(func() error {
 				ui.Info("Starting Webserver...")
 
@@ -102,49 +125,136 @@ func RunDaemon() {
 		}
 	}
 	{
-		// === sensor monitoring
-		for _, sensor := range sensors.SensorMap {
-			s := sensor
-			pollingRate := configuration.CurrentConfig.TempSensorPollingRate
-			mon := NewSensorMonitor(s, pollingRate)
-
+		// === D-Bus service
+		if configuration.CurrentConfig.Dbus.Enabled {
 			g.Add(func() error {
-				err := mon.Run(ctx)
-				ui.Info("Sensor Monitor for sensor %s stopped.", s.GetId())
-				if err != nil {
-					panic(err)
-				}
-				return err
+				ui.Info("Starting D-Bus service...")
+				return dbus.Run(ctx, configuration.CurrentConfig.Dbus)
 			}, func(err error) {
 				if err != nil {
-					ui.Warning("Error monitoring sensor: %v", err)
+					ui.Warning("Error stopping D-Bus service: " + err.Error())
+				} else {
+					ui.Debug("D-Bus service stopped.")
 				}
 			})
 		}
 	}
 	{
-		// === fan controllers
-		for f, c := range fanControllers {
-			fan := f
-			fanController := c
+		// === gRPC api
+		if configuration.CurrentConfig.Grpc.Enabled {
 			g.Add(func() error {
-				err := fanController.Run(ctx)
-				ui.Info("Fan controller for fan %s stopped.", fan.GetId())
-				if err != nil {
-					ui.NotifyError(fmt.Sprintf("Fan Controller: %s", fan.GetId()), err.Error())
-					panic(err)
-				}
-				return err
+				ui.Info("Starting gRPC api...")
+				return grpc.Run(ctx, configuration.CurrentConfig.Grpc, false)
 			}, func(err error) {
 				if err != nil {
-					ui.WarningAndNotify(fmt.Sprintf("Fan Controller: %s", fan.GetId()), "Something went wrong: %v", err)
+					ui.Warning("Error stopping gRPC api: " + err.Error())
+				} else {
+					ui.Debug("gRPC api stopped.")
 				}
 			})
 		}
-
-		if len(fans.FanMap) == 0 {
-			ui.Fatal("No valid fan configurations, exiting.")
-		}
+	}
+	{
+		// === sensor monitors and fan controllers
+		g.Add(func() error {
+			<-ctx.Done()
+			objects.wait()
+			return nil
+		}, func(err error) {
+			if err != nil {
+				ui.Warning("Error stopping fan controllers: %v", err)
+			}
+		})
+	}
+	{
+		// === configuration reload
+		g.Add(func() error {
+			return watchForReload(ctx, objects, configuration.CurrentConfig.AutoReload)
+		}, func(err error) {
+			if err != nil {
+				ui.Warning("Error watching for configuration changes: %v", err)
+			}
+		})
+	}
+	if configuration.CurrentConfig.History.Enabled {
+		g.Add(func() error {
+			ui.Info("Recording history...")
+			return history.Run(ctx, configuration.CurrentConfig.History)
+		}, func(err error) {
+			if err != nil {
+				ui.Warning("Error recording history: %v", err)
+			}
+		})
+	}
+	if configuration.CurrentConfig.Statistics.InfluxDb.Enabled {
+		g.Add(func() error {
+			ui.Info("Writing metrics to InfluxDB...")
+			return statistics.RunInfluxDb(ctx, configuration.CurrentConfig.Statistics.InfluxDb)
+		}, func(err error) {
+			if err != nil {
+				ui.Warning("Error writing metrics to InfluxDB: %v", err)
+			}
+		})
+	}
+	if configuration.CurrentConfig.Statistics.Graphite.Enabled {
+		g.Add(func() error {
+			ui.Info("Sending metrics to Graphite...")
+			return statistics.RunGraphite(ctx, configuration.CurrentConfig.Statistics.Graphite)
+		}, func(err error) {
+			if err != nil {
+				ui.Warning("Error sending metrics to Graphite: %v", err)
+			}
+		})
+	}
+	if configuration.CurrentConfig.Statistics.Statsd.Enabled {
+		g.Add(func() error {
+			ui.Info("Sending metrics to StatsD...")
+			return statistics.RunStatsd(ctx, configuration.CurrentConfig.Statistics.Statsd)
+		}, func(err error) {
+			if err != nil {
+				ui.Warning("Error sending metrics to StatsD: %v", err)
+			}
+		})
+	}
+	{
+		// === device hotplug
+		g.Add(func() error {
+			return watchForHotplug(ctx, objects)
+		}, func(err error) {
+			if err != nil {
+				ui.Warning("Error watching for device changes: %v", err)
+			}
+		})
+	}
+	{
+		// === retry quarantined sensors and fans
+		g.Add(func() error {
+			return watchQuarantine(ctx, objects)
+		}, func(err error) {
+			if err != nil {
+				ui.Warning("Error retrying quarantined sensors and fans: %v", err)
+			}
+		})
+	}
+	{
+		// === state dump
+		g.Add(func() error {
+			return watchForDump(ctx)
+		}, func(err error) {
+			if err != nil {
+				ui.Warning("Error watching for state dump requests: %v", err)
+			}
+		})
+	}
+	{
+		// === resume from suspend
+		g.Add(func() error {
+			return watchForResume(ctx)
+		}, func(err error) {
+			if err != nil {
+				ui.Warning("Error watching for resume from suspend: %v", err)
+			}
+		})
 	}
 	{
 		sig := make(chan os.Signal, 1)
@@ -176,6 +286,12 @@ func createWebServer() []*echo.Echo {
 		result = append(result, startRestServer())
 	}
 
+	if configuration.CurrentConfig.Socket.Enabled {
+		if server := startSocketServer(); server != nil {
+			result = append(result, server)
+		}
+	}
+
 	if configuration.CurrentConfig.Statistics.Enabled {
 		result = append(result, startStatisticsServer())
 	}
@@ -187,6 +303,9 @@ func startRestServer() *echo.Echo {
 	ui.Info("Starting REST api server...")
 
 	restServer := api.CreateRestService()
+	if configuration.CurrentConfig.Api.WebUi {
+		api.RegisterWebUi(restServer)
+	}
 
 	go func() {
 		apiConfig := configuration.CurrentConfig.Api
@@ -200,6 +319,28 @@ func startRestServer() *echo.Echo {
 	return restServer
 }
 
+func startSocketServer() *echo.Echo {
+	socketConfig := configuration.CurrentConfig.Socket
+	ui.Info("Starting control socket at %s...", socketConfig.Path)
+
+	listener, err := api.ListenUnixSocket(socketConfig)
+	if err != nil {
+		ui.ErrorAndNotify("Socket Error", "Cannot create control socket (%s)", err.Error())
+		return nil
+	}
+
+	socketServer := api.CreateRestService()
+	socketServer.Listener = listener
+
+	go func() {
+		if err := socketServer.Start(""); err != nil && err != http.ErrServerClosed {
+			ui.ErrorAndNotify("Socket Error", "Cannot start control socket (%s)", err.Error())
+		}
+	}()
+
+	return socketServer
+}
+
 func startStatisticsServer() *echo.Echo {
 	ui.Info("Starting statistics server...")
 
@@ -217,126 +358,24 @@ func startStatisticsServer() *echo.Echo {
 	return echoPrometheus
 }
 
-func initializeObjects(pers persistence.Persistence) map[fans.Fan]controller.FanController {
-	controllers := hwmon.GetChips()
-
-	initializeSensors(controllers)
-	initializeCurves()
-
-	var result = map[fans.Fan]controller.FanController{}
-
-	for config, fan := range initializeFans(controllers) {
-		updateRate := configuration.CurrentConfig.ControllerAdjustmentTickRate
-
-		var pidLoop util.PidLoop
-		if config.ControlLoop != nil {
-			pidLoop = *util.NewPidLoop(
-				config.ControlLoop.P,
-				config.ControlLoop.I,
-				config.ControlLoop.D,
-			)
-		} else {
-			pidLoop = *util.NewPidLoop(
-				0.03,
-				0.002,
-				0.0005,
-			)
-		}
-		fanController := controller.NewFanController(pers, fan, pidLoop, updateRate)
-		result[fan] = fanController
-	}
-
-	var fanControllers = []controller.FanController{}
-	for _, c := range result {
-		fanControllers = append(fanControllers, c)
+// createPersistence returns the database at the configured path, or an in-memory persistence if noDb is enabled
+func createPersistence() (persistence.Persistence, error) {
+	config := configuration.CurrentConfig
+	if !config.NoDb {
+		return persistence.NewPersistence(config.DbPath), nil
 	}
-	controllerCollector := statistics.NewControllerCollector(fanControllers)
-	statistics.Register(controllerCollector)
 
-	return result
-}
-
-func initializeSensors(controllers []*hwmon.HwMonController) {
-	var sensorList []sensors.Sensor
-	for _, config := range configuration.CurrentConfig.Sensors {
-		if config.HwMon != nil {
-			found := false
-			for _, c := range controllers {
-				matched, err := regexp.MatchString("(?i)"+config.HwMon.Platform, c.Platform)
-				if err != nil {
-					ui.Fatal("Failed to match platform regex of %s (%s) against controller platform %s", config.ID, config.HwMon.Platform, c.Platform)
-				}
-				if matched {
-					found = true
-					config.HwMon.TempInput = c.Sensors[config.HwMon.Index].Input
-				}
-			}
-			if !found {
-				ui.Fatal("Couldn't find hwmon device with platform '%s' for sensor: %s. Run 'fan2go detect' again and correct any mistake.", config.HwMon.Platform, config.ID)
-			}
-		}
-
-		sensor, err := sensors.NewSensor(config)
+	var initial *persistence.Export
+	if len(config.CalibrationFile) > 0 {
+		data, err := persistence.ReadExport(config.CalibrationFile)
 		if err != nil {
-			ui.Fatal("Unable to process sensor configuration: %s", config.ID)
+			return nil, err
 		}
-		sensorList = append(sensorList, sensor)
-
-		currentValue, err := sensor.GetValue()
-		if err != nil {
-			ui.Warning("Error reading sensor %s: %v", config.ID, err)
-		}
-		sensor.SetMovingAvg(currentValue)
-
-		sensors.SensorMap[config.ID] = sensor
+		initial = data
+		ui.Info("Using calibration data of %d fans from: %s", len(data.Fans), config.CalibrationFile)
 	}
-
-	sensorCollector := statistics.NewSensorCollector(sensorList)
-	statistics.Register(sensorCollector)
-}
-
-func initializeCurves() {
-	var curveList []curves.SpeedCurve
-	for _, config := range configuration.CurrentConfig.Curves {
-		curve, err := curves.NewSpeedCurve(config)
-		if err != nil {
-			ui.Fatal("Unable to process curve configuration: %s", config.ID)
-		}
-		curveList = append(curveList, curve)
-		curves.SpeedCurveMap[config.ID] = curve
-	}
-
-	curveCollector := statistics.NewCurveCollector(curveList)
-	statistics.Register(curveCollector)
-}
-
-func initializeFans(controllers []*hwmon.HwMonController) map[configuration.FanConfig]fans.Fan {
-	var result = map[configuration.FanConfig]fans.Fan{}
-
-	var fanList []fans.Fan
-
-	for _, config := range configuration.CurrentConfig.Fans {
-		if config.HwMon != nil {
-			err := hwmon.UpdateFanConfigFromHwMonControllers(controllers, &config)
-			if err != nil {
-				ui.Fatal("Couldn't update fan config from hwmon: %s", err)
-			}
-		}
-
-		fan, err := fans.NewFan(config)
-		if err != nil {
-			ui.Fatal("Unable to process fan configuration of '%s': %v", config.ID, err)
-		}
-		fans.FanMap[config.ID] = fan
-		result[config] = fan
-
-		fanList = append(fanList, fan)
-	}
-
-	fanCollector := statistics.NewFanCollector(fanList)
-	statistics.Register(fanCollector)
-
-	return result
+	ui.Info("Keeping calibration data in memory only, fans without calibration data are analyzed on every start")
+	return persistence.NewMemoryPersistence(initial)
 }
 
 func getProcessOwner() (string, error) {
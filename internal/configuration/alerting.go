@@ -0,0 +1,35 @@
+package configuration
+
+import "time"
+
+type AlertingConfig struct {
+	Enabled bool `json:"enabled"`
+	// Cooldown is the minimum amount of time between two alerts for the same
+	// event and device, defaults to 5m
+	Cooldown time.Duration       `json:"cooldown"`
+	Webhook  *WebhookAlertConfig `json:"webhook,omitempty"`
+	Email    *EmailAlertConfig   `json:"email,omitempty"`
+	// Exec is a command that is executed for each alert, "%event%", "%id%"
+	// and "%message%" are replaced with their respective value in the arguments
+	Exec *ExecConfig `json:"exec,omitempty"`
+}
+
+type WebhookAlertConfig struct {
+	// Url the alert is posted to as a JSON object
+	Url string `json:"url"`
+	// Headers are additional http headers sent with each request, f.ex. for authorization
+	Headers map[string]string `json:"headers"`
+}
+
+type EmailAlertConfig struct {
+	// Host of the smtp server
+	Host string `json:"host"`
+	// Port of the smtp server, defaults to 25
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// From is the sender address of alert emails
+	From string `json:"from"`
+	// To is the list of recipient addresses of alert emails
+	To []string `json:"to"`
+}
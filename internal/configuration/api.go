@@ -4,4 +4,16 @@ type ApiConfig struct {
 	Enabled bool   `json:"enabled"`
 	Host    string `json:"host"`
 	Port    int    `json:"port"`
+	// WebUi enables the embedded web ui dashboard at /ui, defaults to false
+	WebUi bool `json:"webUi"`
+}
+
+type SocketConfig struct {
+	Enabled bool `json:"enabled"`
+	// Path is the path of the unix domain socket, defaults to /run/fan2go.sock
+	Path string `json:"path"`
+	// Permissions are the (octal) file permissions of the socket, defaults to "0660"
+	Permissions string `json:"permissions"`
+	// Group is the name of the group owning the socket, defaults to the group of the daemon
+	Group string `json:"group"`
 }
@@ -2,6 +2,7 @@ package configuration
 
 import (
 	"os"
+	"sync"
 	"time"
 
 	"github.com/markusressel/fan2go/internal/ui"
@@ -11,6 +12,30 @@ import (
 
 type Configuration struct {
 	DbPath string `json:"dbPath"`
+	// NoDb keeps calibration data in memory only instead of writing it to dbPath,
+	// f.ex. for read-only file systems
+	NoDb bool `json:"noDb"`
+	// CalibrationFile is a file written by "fan2go db export", whose data is used
+	// as initial calibration data if noDb is enabled
+	CalibrationFile string `json:"calibrationFile"`
+
+	// DryRun runs the full control pipeline and logs the pwm values that would be applied,
+	// but never writes pwm or pwm_enable of any fan
+	DryRun bool `json:"dryRun"`
+
+	// HwMonBackend selects how hwmon devices are detected, one of: libsensors | sysfs, defaults to libsensors
+	HwMonBackend string `json:"hwMonBackend"`
+	// SysfsRoot is the root of the sysfs tree used by the sysfs backend and all sysfs based sensors
+	// and devices, f.ex. a remapped /sys in a container or a directory with a fake sysfs tree,
+	// can also be set using the FAN2GO_SYSFS_ROOT environment variable, defaults to /sys
+	SysfsRoot string `json:"sysfsRoot"`
+
+	// Include is a list of files, directories or glob patterns, relative to the config file,
+	// whose fans, sensors, curves and profiles are merged into this configuration
+	Include []string `json:"include"`
+
+	// PluginDir is the directory containing the executables of plugins, defaults to /usr/lib/fan2go/plugins
+	PluginDir string `json:"pluginDir"`
 
 	RunFanInitializationInParallel bool    `json:"runFanInitializationInParallel"`
 	MaxRpmDiffForSettledFan        float64 `json:"maxRpmDiffForSettledFan"`
@@ -24,17 +49,118 @@ type Configuration struct {
 
 	ControllerAdjustmentTickRate time.Duration `json:"controllerAdjustmentTickRate"`
 
+	// AdaptivePolling lengthens the polling and controller intervals while temperatures are stable
+	AdaptivePolling AdaptivePollingConfig `json:"adaptivePolling"`
+
+	// StallDetection restarts fans that stopped rotating although they should be spinning
+	StallDetection StallDetectionConfig `json:"stallDetection"`
+
+	// StuckPwmDetection diagnoses fans whose pwm differs from the written value, f.ex. due to an active BIOS fan control
+	StuckPwmDetection StuckPwmDetectionConfig `json:"stuckPwmDetection"`
+
+	// PwmLimit is the highest pwm value ([0..255]) written to any fan without a pwmLimit of its own,
+	// regardless of the value of its curve
+	PwmLimit *int `json:"pwmLimit,omitempty"`
+
+	// NightMode caps the pwm of fans during the configured hours
+	NightMode NightModeConfig `json:"nightMode"`
+
+	// Failsafe drives fans at a fixed speed while the sensors of their curve are unreadable
+	Failsafe FailsafeConfig `json:"failsafe"`
+
+	// Alerting sends notifications when fans or sensors fail
+	Alerting AlertingConfig `json:"alerting"`
+
+	// History records the values of all sensors and fans for later analysis
+	History HistoryConfig `json:"history"`
+
+	// Logging configures where log messages are written to
+	Logging LoggingConfig `json:"logging"`
+
+	// AutoReload reloads sensors, curves and fans whenever the config file changes
+	AutoReload bool `json:"autoReload"`
+
 	Fans    []FanConfig    `json:"fans"`
 	Sensors []SensorConfig `json:"sensors"`
 	Curves  []CurveConfig  `json:"curves"`
 
+	Profiles []ProfileConfig `json:"profiles"`
+	// Profile is the id of the profile that is active on startup, none if empty
+	Profile string `json:"profile"`
+
 	Api        ApiConfig        `json:"api"`
+	Socket     SocketConfig     `json:"socket"`
 	Statistics StatisticsConfig `json:"statistics"`
 	Profiling  ProfilingConfig  `json:"profiling"`
+	Dbus       DbusConfig       `json:"dbus"`
+	Grpc       GrpcConfig       `json:"grpc"`
 }
 
 var CurrentConfig Configuration
 
+// objectsLock guards the sensors, curves, fans and profiles of CurrentConfig,
+// which are replaced at runtime when the configuration is reloaded
+var objectsLock sync.RWMutex
+
+// GetSensors returns the sensor configurations of CurrentConfig
+func GetSensors() []SensorConfig {
+	objectsLock.RLock()
+	defer objectsLock.RUnlock()
+	return CurrentConfig.Sensors
+}
+
+// GetCurves returns the curve configurations of CurrentConfig
+func GetCurves() []CurveConfig {
+	objectsLock.RLock()
+	defer objectsLock.RUnlock()
+	return CurrentConfig.Curves
+}
+
+// GetFans returns the fan configurations of CurrentConfig
+func GetFans() []FanConfig {
+	objectsLock.RLock()
+	defer objectsLock.RUnlock()
+	return CurrentConfig.Fans
+}
+
+// GetProfiles returns the profile configurations of CurrentConfig
+func GetProfiles() []ProfileConfig {
+	objectsLock.RLock()
+	defer objectsLock.RUnlock()
+	return CurrentConfig.Profiles
+}
+
+// SetObjects replaces the sensors, curves, fans and profiles of CurrentConfig
+// with the ones of the given configuration
+func SetObjects(config *Configuration) {
+	objectsLock.Lock()
+	defer objectsLock.Unlock()
+	CurrentConfig.Sensors = config.Sensors
+	CurrentConfig.Curves = config.Curves
+	CurrentConfig.Fans = config.Fans
+	CurrentConfig.Profiles = config.Profiles
+	CurrentConfig.Profile = config.Profile
+}
+
+// CopyObjects returns a configuration containing copies of the sensors, curves, fans and profiles
+// of CurrentConfig, which can be applied again without modifying the configs of running objects
+func CopyObjects() *Configuration {
+	objectsLock.RLock()
+	defer objectsLock.RUnlock()
+	config := &Configuration{
+		Curves:   append([]CurveConfig(nil), CurrentConfig.Curves...),
+		Profiles: append([]ProfileConfig(nil), CurrentConfig.Profiles...),
+		Profile:  CurrentConfig.Profile,
+	}
+	for _, sensorConfig := range CurrentConfig.Sensors {
+		config.Sensors = append(config.Sensors, sensorConfig.Copy())
+	}
+	for _, fanConfig := range CurrentConfig.Fans {
+		config.Fans = append(config.Fans, fanConfig.Copy())
+	}
+	return config
+}
+
 // InitConfig reads in config file and ENV variables if set.
 func InitConfig(cfgFile string) {
 	viper.SetConfigName("fan2go")
@@ -56,12 +182,19 @@ func InitConfig(cfgFile string) {
 	}
 
 	viper.AutomaticEnv() // read in environment variables that match
+	// f.ex. for containers with a remapped /sys
+	_ = viper.BindEnv("SysfsRoot", "FAN2GO_SYSFS_ROOT")
 
 	setDefaultValues()
 }
 
 func setDefaultValues() {
 	viper.SetDefault("dbpath", "/etc/fan2go/fan2go.db")
+	viper.SetDefault("NoDb", false)
+	viper.SetDefault("DryRun", false)
+	viper.SetDefault("HwMonBackend", HwMonBackendLibsensors)
+	viper.SetDefault("SysfsRoot", DefaultSysfsRoot)
+	viper.SetDefault("PluginDir", DefaultPluginDir)
 	viper.SetDefault("RunFanInitializationInParallel", true)
 	viper.SetDefault("MaxRpmDiffForSettledFan", 10.0)
 	viper.SetDefault("FanResponseDelay", 2)
@@ -73,17 +206,49 @@ func setDefaultValues() {
 	viper.SetDefault("Statistics", StatisticsConfig{
 		Enabled: false,
 		Port:    9000,
+		InfluxDb: InfluxDbConfig{
+			Enabled:  false,
+			Interval: 10 * time.Second,
+		},
+		Graphite: PushConfig{
+			Enabled:  false,
+			Address:  "localhost:2003",
+			Prefix:   "fan2go",
+			Interval: 10 * time.Second,
+		},
+		Statsd: PushConfig{
+			Enabled:  false,
+			Address:  "localhost:8125",
+			Prefix:   "fan2go",
+			Interval: 10 * time.Second,
+		},
 	})
 	viper.SetDefault("Statistics.Port", 9000)
+	viper.SetDefault("Statistics.InfluxDb.Interval", 10*time.Second)
+	viper.SetDefault("Statistics.Graphite.Address", "localhost:2003")
+	viper.SetDefault("Statistics.Graphite.Prefix", "fan2go")
+	viper.SetDefault("Statistics.Graphite.Interval", 10*time.Second)
+	viper.SetDefault("Statistics.Statsd.Address", "localhost:8125")
+	viper.SetDefault("Statistics.Statsd.Prefix", "fan2go")
+	viper.SetDefault("Statistics.Statsd.Interval", 10*time.Second)
 
 	viper.SetDefault("Api", ApiConfig{
 		Enabled: false,
 		Host:    "localhost",
 		Port:    9001,
+		WebUi:   false,
 	})
 	viper.SetDefault("Api.Host", "localhost")
 	viper.SetDefault("Api.Port", 9001)
 
+	viper.SetDefault("Socket", SocketConfig{
+		Enabled:     false,
+		Path:        "/run/fan2go.sock",
+		Permissions: "0660",
+	})
+	viper.SetDefault("Socket.Path", "/run/fan2go.sock")
+	viper.SetDefault("Socket.Permissions", "0660")
+
 	viper.SetDefault("Profiling", ProfilingConfig{
 		Enabled: false,
 		Host:    "localhost",
@@ -92,10 +257,89 @@ func setDefaultValues() {
 	viper.SetDefault("Profiling.Host", "localhost")
 	viper.SetDefault("Profiling.Port", 6060)
 
+	viper.SetDefault("Dbus", DbusConfig{
+		Enabled: false,
+	})
+
+	viper.SetDefault("Grpc", GrpcConfig{
+		Enabled: false,
+		Host:    "localhost",
+		Port:    9002,
+	})
+	viper.SetDefault("Grpc.Host", "localhost")
+	viper.SetDefault("Grpc.Port", 9002)
+
+	viper.SetDefault("StallDetection", StallDetectionConfig{
+		Enabled:      false,
+		Cycles:       5,
+		KickPwm:      255,
+		KickDuration: 5 * time.Second,
+	})
+	viper.SetDefault("StallDetection.Cycles", 5)
+	viper.SetDefault("StallDetection.KickPwm", 255)
+	viper.SetDefault("StallDetection.KickDuration", 5*time.Second)
+
+	viper.SetDefault("StuckPwmDetection", StuckPwmDetectionConfig{
+		Enabled:           true,
+		Cycles:            3,
+		ReassertPwmEnable: false,
+	})
+	viper.SetDefault("StuckPwmDetection.Enabled", true)
+	viper.SetDefault("StuckPwmDetection.Cycles", 3)
+	viper.SetDefault("StuckPwmDetection.ReassertPwmEnable", false)
+
+	viper.SetDefault("Failsafe", FailsafeConfig{
+		SensorTimeout: 30 * time.Second,
+		Pwm:           255,
+	})
+	viper.SetDefault("Failsafe.SensorTimeout", 30*time.Second)
+	viper.SetDefault("Failsafe.Pwm", 255)
+
+	viper.SetDefault("Alerting", AlertingConfig{
+		Enabled:  false,
+		Cooldown: 5 * time.Minute,
+	})
+	viper.SetDefault("Alerting.Cooldown", 5*time.Minute)
+
+	viper.SetDefault("History", HistoryConfig{
+		Enabled:   false,
+		Interval:  1 * time.Second,
+		Retention: 1 * time.Hour,
+	})
+	viper.SetDefault("History.Interval", 1*time.Second)
+	viper.SetDefault("History.Retention", 1*time.Hour)
+
+	viper.SetDefault("Logging", LoggingConfig{
+		Output: LogOutputConsole,
+		Level:  "info",
+		File: LogFileConfig{
+			MaxSize:    10,
+			MaxBackups: 3,
+		},
+	})
+	viper.SetDefault("Logging.Output", LogOutputConsole)
+	viper.SetDefault("Logging.Level", "info")
+	viper.SetDefault("Logging.File.MaxSize", 10)
+	viper.SetDefault("Logging.File.MaxBackups", 3)
+
 	viper.SetDefault("ControllerAdjustmentTickRate", 200*time.Millisecond)
 
+	viper.SetDefault("AdaptivePolling", AdaptivePollingConfig{
+		Enabled:     false,
+		MaxInterval: 2 * time.Second,
+		RampUp:      1 * time.Minute,
+		StableDelta: 0.5,
+		RapidDelta:  2,
+	})
+	viper.SetDefault("AdaptivePolling.MaxInterval", 2*time.Second)
+	viper.SetDefault("AdaptivePolling.RampUp", 1*time.Minute)
+	viper.SetDefault("AdaptivePolling.StableDelta", 0.5)
+	viper.SetDefault("AdaptivePolling.RapidDelta", 2)
+	viper.SetDefault("AutoReload", false)
+
 	viper.SetDefault("sensors", []SensorConfig{})
 	viper.SetDefault("fans", []FanConfig{})
+	viper.SetDefault("profiles", []ProfileConfig{})
 }
 
 // DetectAndReadConfigFile detects the path of the first existing config file
@@ -110,6 +354,12 @@ func ReadInConfig() {
 		// config file is required, so we fail here
 		ui.Fatal("Error reading config file, %s", err)
 	}
+	if err := checkSchemaFile(GetFilePath()); err != nil {
+		ui.Fatal("Invalid config file:\n%s", err)
+	}
+	if err := mergeIncludes(); err != nil {
+		ui.Fatal("Error reading included config file, %s", err)
+	}
 }
 
 // GetFilePath this is only populated _after_ ReadInConfig()
@@ -117,6 +367,28 @@ func GetFilePath() string {
 	return viper.ConfigFileUsed()
 }
 
+// ReloadConfig reads the config file again and returns the validated result,
+// CurrentConfig is left untouched
+func ReloadConfig() (*Configuration, error) {
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	if err := checkSchemaFile(GetFilePath()); err != nil {
+		return nil, err
+	}
+	if err := mergeIncludes(); err != nil {
+		return nil, err
+	}
+	config := Configuration{}
+	if err := viper.Unmarshal(&config); err != nil {
+		return nil, err
+	}
+	if err := validateConfig(&config, GetFilePath()); err != nil {
+		return nil, locateValidationError(err, getConfigFiles())
+	}
+	return &config, nil
+}
+
 func LoadConfig() {
 	// load default configuration values
 	err := viper.Unmarshal(&CurrentConfig)
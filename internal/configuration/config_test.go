@@ -0,0 +1,39 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyObjects(t *testing.T) {
+	// GIVEN
+	CurrentConfig = Configuration{
+		Sensors: []SensorConfig{
+			{ID: "cpu", HwMon: &HwMonSensorConfig{Platform: "coretemp", Index: 1}},
+		},
+		Fans: []FanConfig{
+			{ID: "cpu_fan", HwMon: &HwMonFanConfig{Platform: "nct6798", RpmChannel: 1}},
+			{ID: "group", Group: &GroupFanConfig{Members: []FanConfig{
+				{ID: "member", HwMon: &HwMonFanConfig{Platform: "nct6798", RpmChannel: 2}},
+			}}},
+		},
+		Profile: "silent",
+	}
+	defer func() {
+		CurrentConfig = Configuration{}
+	}()
+
+	// WHEN
+	config := CopyObjects()
+	config.Sensors[0].HwMon.TempInput = "/sys/class/hwmon/hwmon1/temp1_input"
+	config.Fans[0].HwMon.PwmPath = "/sys/class/hwmon/hwmon2/pwm1"
+	config.Fans[1].Group.Members[0].HwMon.PwmPath = "/sys/class/hwmon/hwmon2/pwm2"
+
+	// THEN
+	assert.Equal(t, "silent", config.Profile)
+	assert.Equal(t, "coretemp", config.Sensors[0].HwMon.Platform)
+	assert.Empty(t, CurrentConfig.Sensors[0].HwMon.TempInput)
+	assert.Empty(t, CurrentConfig.Fans[0].HwMon.PwmPath)
+	assert.Empty(t, CurrentConfig.Fans[1].Group.Members[0].HwMon.PwmPath)
+}
@@ -1,10 +1,23 @@
 package configuration
 
+import "github.com/markusressel/fan2go/internal/expression"
+
 type CurveConfig struct {
 	ID       string               `json:"id"`
 	Linear   *LinearCurveConfig   `json:"linear,omitempty"`
 	PID      *PidCurveConfig      `json:"pid,omitempty"`
 	Function *FunctionCurveConfig `json:"function,omitempty"`
+	Target   *TargetCurveConfig   `json:"target,omitempty"`
+	Schedule *ScheduleCurveConfig `json:"schedule,omitempty"`
+	// Expression computes the curve value using a formula of sensor values
+	Expression *ExpressionCurveConfig `json:"expression,omitempty"`
+}
+
+// WeightedSensorConfig references a sensor which contributes to the input of a curve
+type WeightedSensorConfig struct {
+	ID string `json:"id"`
+	// Weight is the factor the value of the sensor is multiplied with
+	Weight float64 `json:"weight"`
 }
 
 type LinearCurveConfig struct {
@@ -12,6 +25,16 @@ type LinearCurveConfig struct {
 	Min    int             `json:"min"`
 	Max    int             `json:"max"`
 	Steps  map[int]float64 `json:"steps"`
+	// Interpolation is used to calculate values in between steps, one of: linear | step | monotone
+	Interpolation string `json:"interpolation"`
+	// Hysteresis is the amount of degrees the sensor value has to drop below a
+	// threshold before the curve ramps down again, defaults to 0
+	Hysteresis float64 `json:"hysteresis"`
+	// Sensors can be used instead of Sensor to drive the curve by the weighted sum of multiple sensors
+	Sensors []WeightedSensorConfig `json:"sensors"`
+	// Curve can be used instead of Sensor to drive the curve by the value (0..255) of another curve,
+	// f.ex. to clamp or rescale it
+	Curve string `json:"curve"`
 }
 
 type PidCurveConfig struct {
@@ -20,6 +43,66 @@ type PidCurveConfig struct {
 	P        float64 `json:"p"`
 	I        float64 `json:"i"`
 	D        float64 `json:"d"`
+	// Sensors can be used instead of Sensor to drive the curve by the weighted sum of multiple sensors
+	Sensors []WeightedSensorConfig `json:"sensors"`
+}
+
+type TargetCurveConfig struct {
+	Sensor string `json:"sensor"`
+	// Sensors can be used instead of Sensor to drive the curve by the weighted sum of multiple sensors
+	Sensors []WeightedSensorConfig `json:"sensors"`
+	// Target is the sensor value (in degrees) the curve tries to hold
+	Target float64 `json:"target"`
+	// Min is the minimum curve value, defaults to 0
+	Min int `json:"min"`
+	// Max is the maximum curve value, defaults to 255
+	Max int `json:"max"`
+	// Gain is the amount the curve value is changed by per degree of deviation
+	// from the target on each evaluation, defaults to 1
+	Gain float64 `json:"gain"`
+	// Tolerance is the deviation (in degrees) from the target that is accepted
+	// without changing the curve value, defaults to 0
+	Tolerance float64 `json:"tolerance"`
+}
+
+type ExpressionCurveConfig struct {
+	// Formula computes the curve value from the values (in degrees) of the sensors it references
+	// by their id, f.ex. "max(cpu, gpu * 0.8) > 70 ? 255 : scale(cpu, 40, 70, 50, 200)",
+	// the result is limited to [0..255]
+	Formula string `json:"formula"`
+}
+
+// GetSensorIds returns the ids of all sensors the curve references directly,
+// without the sensors of the curves it references
+func (c CurveConfig) GetSensorIds() []string {
+	switch {
+	case c.Linear != nil:
+		return getInputSensorIds(c.Linear.Sensor, c.Linear.Sensors)
+	case c.PID != nil:
+		return getInputSensorIds(c.PID.Sensor, c.PID.Sensors)
+	case c.Target != nil:
+		return getInputSensorIds(c.Target.Sensor, c.Target.Sensors)
+	case c.Expression != nil:
+		parsed, err := expression.Parse(c.Expression.Formula)
+		if err == nil {
+			return parsed.Variables()
+		}
+	}
+	return nil
+}
+
+func getInputSensorIds(sensor string, weighted []WeightedSensorConfig) []string {
+	if len(weighted) <= 0 {
+		if len(sensor) <= 0 {
+			return nil
+		}
+		return []string{sensor}
+	}
+	var result []string
+	for _, sensorConfig := range weighted {
+		result = append(result, sensorConfig.ID)
+	}
+	return result
 }
 
 const (
@@ -43,3 +126,21 @@ type FunctionCurveConfig struct {
 	Type   string   `json:"type"`
 	Curves []string `json:"curves"`
 }
+
+type ScheduleCurveConfig struct {
+	// Default is the id of the curve used while no schedule entry is active
+	Default string `json:"default"`
+	// Entries is a list of curves to use during specific times of day,
+	// the first matching entry is used
+	Entries []ScheduleEntryConfig `json:"entries"`
+}
+
+type ScheduleEntryConfig struct {
+	// From is the time of day (HH:MM) at which the entry becomes active
+	From string `json:"from"`
+	// To is the time of day (HH:MM) at which the entry becomes inactive,
+	// may be before From to span midnight
+	To string `json:"to"`
+	// Curve is the id of the curve to use while the entry is active
+	Curve string `json:"curve"`
+}
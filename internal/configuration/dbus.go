@@ -0,0 +1,11 @@
+package configuration
+
+type DbusConfig struct {
+	Enabled bool `json:"enabled"`
+	// MaxTemperature is the temperature (in degrees) above which an "OverTemperature"
+	// signal is emitted for a sensor, 0 disables the signal
+	MaxTemperature float64 `json:"maxTemperature"`
+	// Group whose members may call the methods changing the state of fan2go (f.ex. SetOverride),
+	// in addition to root. Only root may call them if empty.
+	Group string `json:"group"`
+}
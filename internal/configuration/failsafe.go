@@ -0,0 +1,11 @@
+package configuration
+
+import "time"
+
+type FailsafeConfig struct {
+	// SensorTimeout is the amount of time a sensor may be unreadable, before all fans
+	// whose curve depends on it enter failsafe mode, 0 disables failsafe mode
+	SensorTimeout time.Duration `json:"sensorTimeout"`
+	// Pwm is the PWM value applied to fans in failsafe mode, defaults to 255
+	Pwm int `json:"pwm"`
+}
@@ -1,5 +1,7 @@
 package configuration
 
+import "time"
+
 type FanConfig struct {
 	ID        string `json:"id"`
 	NeverStop bool   `json:"neverStop"`
@@ -8,24 +10,113 @@ type FanConfig struct {
 	// StartPwm defines the lowest PWM value where the fans are able to start spinning from a standstill
 	StartPwm *int `json:"startPwm,omitempty"`
 	// MaxPwm defines the highest PWM value that yields an RPM increase
-	PwmMap      *map[int]int       `json:"pwmMap,omitempty"`
-	MaxPwm      *int               `json:"maxPwm,omitempty"`
-	Curve       string             `json:"curve"`
-	HwMon       *HwMonFanConfig    `json:"hwMon,omitempty"`
-	File        *FileFanConfig     `json:"file,omitempty"`
-	Cmd         *CmdFanConfig      `json:"cmd,omitempty"`
-	ControlLoop *ControlLoopConfig `json:"controlLoop,omitempty"`
+	PwmMap      *map[int]int        `json:"pwmMap,omitempty"`
+	MaxPwm      *int                `json:"maxPwm,omitempty"`
+	Curve       string              `json:"curve"`
+	HwMon       *HwMonFanConfig     `json:"hwMon,omitempty"`
+	File        *FileFanConfig      `json:"file,omitempty"`
+	Cmd         *CmdFanConfig       `json:"cmd,omitempty"`
+	Ipmi        *IpmiFanConfig      `json:"ipmi,omitempty"`
+	Redfish     *RedfishFanConfig   `json:"redfish,omitempty"`
+	Mqtt        *MqttFanConfig      `json:"mqtt,omitempty"`
+	DellSmm     *DellSmmFanConfig   `json:"dellSmm,omitempty"`
+	Liquidctl   *LiquidctlFanConfig `json:"liquidctl,omitempty"`
+	UsbHid      *UsbHidFanConfig    `json:"usbHid,omitempty"`
+	Plugin      *PluginConfig       `json:"plugin,omitempty"`
+	Group       *GroupFanConfig     `json:"group,omitempty"`
+	ControlLoop *ControlLoopConfig  `json:"controlLoop,omitempty"`
+	ZeroRpm     *ZeroRpmConfig      `json:"zeroRpm,omitempty"`
+	// RpmTarget interprets the curve value as a target RPM instead of a pwm value
+	RpmTarget *RpmTargetConfig `json:"rpmTarget,omitempty"`
+	// UpdateRate overrides controllerAdjustmentTickRate for this fan
+	UpdateRate time.Duration `json:"updateRate,omitempty"`
+	// CurveFactor is multiplied with the value of the curve, f.ex. to run a fan slower than
+	// other fans sharing the same curve, defaults to 1
+	CurveFactor float64 `json:"curveFactor,omitempty"`
+	// CurveOffset is added to the value of the curve after applying CurveFactor, defaults to 0
+	CurveOffset int `json:"curveOffset,omitempty"`
+	// PwmEnable overrides the control modes (pwm_enable values) written to the fan
+	PwmEnable *PwmEnableConfig `json:"pwmEnable,omitempty"`
+	// MinRpm is the lowest RPM the fan should spin at while it is driven with a pwm value above 0,
+	// the pwm value is raised until the measured RPM reaches it
+	MinRpm int `json:"minRpm,omitempty"`
+	// PwmLimit is the highest pwm value ([0..255]) written to the fan, regardless of the value of its curve,
+	// unlike MaxPwm the curve is not scaled to it, overrides the global pwmLimit
+	PwmLimit *int `json:"pwmLimit,omitempty"`
+}
+
+// Copy returns a copy of the fan config whose hwmon configs can be resolved against the detected devices
+// without modifying the ones of this config, which may be used by a running fan
+func (c FanConfig) Copy() FanConfig {
+	if c.HwMon != nil {
+		hwMon := *c.HwMon
+		c.HwMon = &hwMon
+	}
+	if c.DellSmm != nil {
+		dellSmm := *c.DellSmm
+		c.DellSmm = &dellSmm
+	}
+	if c.Group != nil {
+		members := make([]FanConfig, len(c.Group.Members))
+		for idx, member := range c.Group.Members {
+			members[idx] = member.Copy()
+		}
+		c.Group = &GroupFanConfig{Members: members}
+	}
+	return c
+}
+
+type PwmEnableConfig struct {
+	// Manual is the value written to control the fan manually, since some chips require
+	// f.ex. 2 or 5 instead of 1, defaults to 1
+	Manual *int `json:"manual,omitempty"`
+	// Restore is the value written when fan2go stops controlling the fan,
+	// defaults to the value the fan had when fan2go started
+	Restore *int `json:"restore,omitempty"`
 }
 
 type HwMonFanConfig struct {
-	Platform      string `json:"platform"`
-	Index         int    `json:"index"`
-	RpmChannel    int    `json:"rpmChannel"`
-	PwmChannel    int    `json:"pwmChannel"`
+	Platform string `json:"platform"`
+	// Name is a regex matching the name of the hwmon device, f.ex. "nct6798", for devices without a stable platform
+	Name string `json:"name,omitempty"`
+	// Modalias is a regex matching the modalias of the hwmon device, f.ex. "pci:v00001002d0000731F"
+	Modalias string `json:"modalias,omitempty"`
+	// PciAddress is the address of the PCI device of the hwmon device, f.ex. "pci-0000:2f:00.0",
+	// to distinguish identical devices
+	PciAddress string `json:"pciAddress,omitempty"`
+	Index      int    `json:"index"`
+	RpmChannel int    `json:"rpmChannel"`
+	PwmChannel int    `json:"pwmChannel"`
+	// PwmMode is the mode (pwmN_mode) used to drive the fan, one of: dc | pwm,
+	// the mode of the chip is left untouched if none is set
+	PwmMode       string `json:"pwmMode,omitempty"`
 	SysfsPath     string
 	RpmInputPath  string
 	PwmPath       string
 	PwmEnablePath string
+	PwmModePath   string
+}
+
+const (
+	// PwmModeDC drives the fan by varying its voltage, required by some 3-pin fans
+	PwmModeDC = "dc"
+	// PwmModePWM drives the fan using a pwm signal
+	PwmModePWM = "pwm"
+)
+
+type GroupFanConfig struct {
+	// Members are the fans controlled as a single fan, they use the same sub-configurations
+	// as any other fan, but have no curve of their own
+	Members []FanConfig `json:"members"`
+}
+
+type DellSmmFanConfig struct {
+	// HwMonFanConfig selects the fan of the dell_smm_hwmon driver,
+	// the platform defaults to "dell_smm"
+	HwMonFanConfig `mapstructure:",squash"`
+	// ReassertInterval is the interval at which the last PWM value is written again,
+	// since the BIOS may change the fan speed on its own, defaults to 10s
+	ReassertInterval time.Duration `json:"reassertInterval"`
 }
 
 type FileFanConfig struct {
@@ -38,11 +129,159 @@ type CmdFanConfig struct {
 	GetRpm *ExecConfig `json:"getRpm,omitempty"`
 }
 
+const (
+	// IpmiProfileSupermicro is an alias of IpmiProfileSupermicroX11
+	IpmiProfileSupermicro    = "supermicro"
+	IpmiProfileSupermicroX9  = "supermicro-x9"
+	IpmiProfileSupermicroX10 = "supermicro-x10"
+	IpmiProfileSupermicroX11 = "supermicro-x11"
+	IpmiProfileSupermicroX12 = "supermicro-x12"
+	IpmiProfileSupermicroX13 = "supermicro-x13"
+	// IpmiProfileDell is an alias of IpmiProfileDellPowerEdge
+	IpmiProfileDell          = "dell"
+	IpmiProfileDellPowerEdge = "dell-poweredge"
+	IpmiProfileAsRockRack    = "asrockrack"
+	IpmiProfileGeneric       = "generic"
+)
+
+// IpmiProfiles lists all supported ipmi profiles
+var IpmiProfiles = []string{
+	IpmiProfileSupermicro,
+	IpmiProfileSupermicroX9,
+	IpmiProfileSupermicroX10,
+	IpmiProfileSupermicroX11,
+	IpmiProfileSupermicroX12,
+	IpmiProfileSupermicroX13,
+	IpmiProfileDell,
+	IpmiProfileDellPowerEdge,
+	IpmiProfileAsRockRack,
+	IpmiProfileGeneric,
+}
+
+type IpmiFanConfig struct {
+	// Profile selects the set of raw commands understood by the BMC, see IpmiProfiles
+	Profile string `json:"profile"`
+	// Zone is the fan zone (supermicro, 0: CPU/system fans, 1: peripheral fans) or the fan number
+	// (dell, asrockrack) to control, 0 controls all fans on dell and asrockrack
+	Zone int `json:"zone"`
+	// Ipmitool is the path to the ipmitool executable, defaults to /usr/bin/ipmitool
+	Ipmitool string `json:"ipmitool"`
+	// Host of a remote BMC, the local BMC is used if empty
+	Host     string `json:"host"`
+	Username string `json:"username"`
+	// Password of the remote BMC, never serialized to keep it out of the api
+	Password string `json:"-"`
+	// RpmSensor is the name of the SDR sensor used to read the RPM of this fan
+	RpmSensor string `json:"rpmSensor"`
+	// Raw contains the raw command templates used by the generic profile
+	Raw *IpmiRawConfig `json:"raw,omitempty"`
+}
+
+type IpmiRawConfig struct {
+	// SetPwm are the raw bytes to set the fan duty, "%pwm%" (0-255), "%duty%" (0-100)
+	// and "%zone%" are replaced with their respective value
+	SetPwm []string `json:"setPwm"`
+	// Manual are the raw bytes to put the BMC into manual fan control mode
+	Manual []string `json:"manual"`
+	// Auto are the raw bytes to return fan control to the BMC
+	Auto []string `json:"auto"`
+}
+
+type MqttFanConfig struct {
+	// Broker is the url of the mqtt broker, f.ex. tcp://localhost:1883
+	Broker   string `json:"broker"`
+	ClientId string `json:"clientId"`
+	Username string `json:"username"`
+	// Password of the broker, never serialized to keep it out of the api
+	Password string `json:"-"`
+	// PwmTopic is the topic the target PWM value (0-255) is published to
+	PwmTopic string `json:"pwmTopic"`
+	// RpmTopic is the topic the current RPM value is received from
+	RpmTopic string `json:"rpmTopic"`
+	Qos      byte   `json:"qos"`
+	// Retain indicates whether the broker should retain published PWM values
+	Retain bool `json:"retain"`
+}
+
+type LiquidctlFanConfig struct {
+	// Match is passed to "liquidctl --match" to select the device
+	Match string `json:"match"`
+	// Channel of the device to control, f.ex. "fan1" or "pump"
+	Channel string `json:"channel"`
+	// RpmKey is the key of the status value used as the RPM of this fan, f.ex. "Fan 1 speed"
+	RpmKey string `json:"rpmKey"`
+	// Liquidctl is the path to the liquidctl executable, defaults to /usr/bin/liquidctl
+	Liquidctl string `json:"liquidctl"`
+}
+
+const (
+	UsbHidModelNzxtSmartDevice     = "nzxt-smart-device"
+	UsbHidModelNzxtSmartDeviceV2   = "nzxt-smart-device-v2"
+	UsbHidModelCorsairCommanderPro = "corsair-commander-pro"
+	UsbHidModelAquacomputerD5Next  = "aquacomputer-d5next"
+	UsbHidModelAquacomputerOcto    = "aquacomputer-octo"
+	UsbHidModelAquacomputerQuadro  = "aquacomputer-quadro"
+)
+
+var (
+	// UsbHidFanModels are the usb hid controllers which can control fans
+	UsbHidFanModels = []string{
+		UsbHidModelNzxtSmartDevice,
+		UsbHidModelNzxtSmartDeviceV2,
+		UsbHidModelCorsairCommanderPro,
+		UsbHidModelAquacomputerD5Next,
+		UsbHidModelAquacomputerOcto,
+		UsbHidModelAquacomputerQuadro,
+	}
+	// UsbHidSensorModels are the usb hid controllers which report sensor values
+	UsbHidSensorModels = []string{
+		UsbHidModelAquacomputerD5Next,
+		UsbHidModelAquacomputerOcto,
+		UsbHidModelAquacomputerQuadro,
+	}
+)
+
+type UsbHidFanConfig struct {
+	// Model of the controller, one of: nzxt-smart-device | nzxt-smart-device-v2 | corsair-commander-pro |
+	// aquacomputer-d5next | aquacomputer-octo | aquacomputer-quadro
+	Model string `json:"model"`
+	// Index of the controller, if there are multiple controllers of the same model
+	Index int `json:"index"`
+	// Path of the hidraw device of the controller, takes precedence over Model and Index if set
+	Path string `json:"path"`
+	// Channel of the fan on the controller, starting at 1
+	Channel int `json:"channel"`
+}
+
 type ExecConfig struct {
 	Exec string   `json:"exec"`
 	Args []string `json:"args"`
 }
 
+type ZeroRpmConfig struct {
+	// Sensor is the id of the sensor that decides whether the fan is stopped
+	Sensor string `json:"sensor"`
+	// StopTemp is the temperature (in degrees) at or below which the fan is stopped
+	StopTemp float64 `json:"stopTemp"`
+	// StartTemp is the temperature (in degrees) at or above which a stopped fan
+	// is started again, must be greater than StopTemp
+	StartTemp float64 `json:"startTemp"`
+	// KickPwm is the PWM value applied when starting a stopped fan,
+	// defaults to the startPwm of the fan
+	KickPwm *int `json:"kickPwm,omitempty"`
+	// KickDuration is the amount of time KickPwm is applied for, defaults to 2s
+	KickDuration time.Duration `json:"kickDuration"`
+}
+
+type RpmTargetConfig struct {
+	// MaxRpm is the target RPM of the highest curve value (255), lower values are scaled linearly.
+	// Defaults to the highest RPM measured during the initialization of the fan.
+	MaxRpm int `json:"maxRpm,omitempty"`
+	// Tolerance is the difference (in RPM) between the target and the measured RPM
+	// which is not corrected, defaults to 50
+	Tolerance int `json:"tolerance,omitempty"`
+}
+
 type ControlLoopConfig struct {
 	P float64 `json:"p"`
 	I float64 `json:"i"`
@@ -0,0 +1,20 @@
+package configuration
+
+type GrpcConfig struct {
+	Enabled bool `json:"enabled"`
+	// Host is the host to listen for connections, defaults to localhost
+	Host string `json:"host"`
+	// Port is the port to listen for connections, defaults to 9002
+	Port int `json:"port"`
+	// Tls encrypts all connections using the given certificate, recommended if the api is reachable from other hosts
+	Tls *GrpcTlsConfig `json:"tls,omitempty"`
+	// Token is required from clients as "authorization: Bearer <token>" metadata of each call, if set.
+	// It requires Tls, since it would be sent in clear text otherwise.
+	Token string `json:"token"`
+}
+
+type GrpcTlsConfig struct {
+	// CertFile and KeyFile are the PEM encoded certificate (chain) and private key of the server
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+}
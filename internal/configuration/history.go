@@ -0,0 +1,11 @@
+package configuration
+
+import "time"
+
+type HistoryConfig struct {
+	Enabled bool `json:"enabled"`
+	// Interval is the time between two recorded samples, defaults to 1s
+	Interval time.Duration `json:"interval"`
+	// Retention is the amount of time samples are kept for, defaults to 1h
+	Retention time.Duration `json:"retention"`
+}
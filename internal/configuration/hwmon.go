@@ -0,0 +1,41 @@
+package configuration
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// HwMonBackendLibsensors detects hwmon devices using libsensors
+	HwMonBackendLibsensors = "libsensors"
+	// HwMonBackendSysfs detects hwmon devices by scanning the sysfs tree at SysfsRoot
+	HwMonBackendSysfs = "sysfs"
+)
+
+var pciAddressPattern = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+
+// DefaultSysfsRoot is the root of the sysfs tree used if SysfsRoot is not configured
+const DefaultSysfsRoot = "/sys"
+
+// GetSysfsPath joins the given path elements to the configured root of the sysfs tree,
+// f.ex. GetSysfsPath("class", "thermal") returns /sys/class/thermal by default
+func GetSysfsPath(elem ...string) string {
+	root := CurrentConfig.SysfsRoot
+	if len(root) <= 0 {
+		root = DefaultSysfsRoot
+	}
+	return path.Join(append([]string{root}, elem...)...)
+}
+
+// NormalizePciAddress returns the given PCI address in the format used by the sysfs tree,
+// f.ex. "0000:2f:00.0" for "pci-0000:2F:00.0"
+func NormalizePciAddress(address string) string {
+	return strings.TrimPrefix(strings.ToLower(address), "pci-")
+}
+
+// IsValidPciAddress returns true if the given address is a PCI address in the format
+// "pci-0000:2f:00.0", the "pci-" prefix is optional
+func IsValidPciAddress(address string) bool {
+	return pciAddressPattern.MatchString(NormalizePciAddress(address))
+}
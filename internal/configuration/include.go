@@ -0,0 +1,132 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// includableKeys are the keys that can be defined in included files,
+// their lists are appended to the ones of the main config file
+var includableKeys = []string{"fans", "sensors", "curves", "profiles"}
+
+// mergeIncludes reads all files matched by the "include" patterns of the config file
+// and merges their fans, sensors, curves and profiles into the configuration
+func mergeIncludes() error {
+	files, err := findIncludedFiles()
+	if err != nil || len(files) <= 0 {
+		return err
+	}
+
+	merged := map[string]interface{}{}
+	for _, key := range includableKeys {
+		list, _ := viper.Get(key).([]interface{})
+		merged[key] = list
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		content := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &content); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		if err := checkSchema(file, data); err != nil {
+			return err
+		}
+
+		for key, value := range content {
+			key = strings.ToLower(key)
+			if !slices.Contains(includableKeys, key) {
+				return fmt.Errorf("%s: unsupported key '%s', included files may only contain: %s", file, key, strings.Join(includableKeys, " | "))
+			}
+			list, ok := value.([]interface{})
+			if !ok {
+				return fmt.Errorf("%s: %s must be a list", file, key)
+			}
+			merged[key] = append(merged[key].([]interface{}), list...)
+		}
+	}
+
+	return viper.MergeConfigMap(merged)
+}
+
+// findIncludedFiles returns all files matched by the "include" patterns of the config file.
+// Patterns are relative to the directory of the config file, a directory includes all yaml files within it.
+func findIncludedFiles() ([]string, error) {
+	var result []string
+	for _, pattern := range getIncludePatterns() {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern '%s': %v", pattern, err)
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				for _, extension := range []string{"*.yaml", "*.yml"} {
+					files, _ := filepath.Glob(filepath.Join(match, extension))
+					result = append(result, files...)
+				}
+			} else {
+				result = append(result, match)
+			}
+		}
+	}
+	sort.Strings(result)
+	return slices.Compact(result), nil
+}
+
+// getIncludePatterns returns the absolute "include" patterns of the config file
+func getIncludePatterns() []string {
+	var result []string
+	for _, pattern := range viper.GetStringSlice("include") {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(GetFilePath()), pattern)
+		}
+		result = append(result, pattern)
+	}
+	return result
+}
+
+// GetIncludeDirs returns the directories containing files that may be included by the config file
+func GetIncludeDirs() []string {
+	var result []string
+	for _, pattern := range getIncludePatterns() {
+		dir := pattern
+		if info, err := os.Stat(pattern); err != nil || !info.IsDir() {
+			dir = filepath.Dir(pattern)
+		}
+		if !slices.Contains(result, dir) {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// IsIncludedFile returns true if the given file is (or would be) included by the config file
+func IsIncludedFile(path string) bool {
+	for _, pattern := range getIncludePatterns() {
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			extension := filepath.Ext(path)
+			if filepath.Dir(path) == filepath.Clean(pattern) && (extension == ".yaml" || extension == ".yml") {
+				return true
+			}
+			continue
+		}
+		if matched, _ := filepath.Match(pattern, path); matched {
+			return true
+		}
+	}
+	return false
+}
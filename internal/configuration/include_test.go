@@ -0,0 +1,160 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, path string, content string) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(path, []byte(content), 0644)
+	assert.NoError(t, err)
+}
+
+func readTestConfig(t *testing.T, configPath string) (Configuration, error) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigFile(configPath)
+
+	config := Configuration{}
+	if err := viper.ReadInConfig(); err != nil {
+		return config, err
+	}
+	if err := mergeIncludes(); err != nil {
+		return config, err
+	}
+	err := viper.Unmarshal(&config)
+	return config, err
+}
+
+func TestIncludeMergesLists(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "fan2go.yaml")
+	writeConfigFile(t, configPath, `
+include:
+  - fan2go.d
+fans:
+  - id: main_fan
+    file:
+      path: /tmp/fan
+`)
+	writeConfigFile(t, filepath.Join(dir, "fan2go.d", "10-cpu.yaml"), `
+sensors:
+  - id: cpu
+    file:
+      path: /tmp/cpu
+curves:
+  - id: cpu_curve
+    linear:
+      sensor: cpu
+      min: 40
+      max: 80
+`)
+	writeConfigFile(t, filepath.Join(dir, "fan2go.d", "20-case.yml"), `
+fans:
+  - id: case_fan
+    file:
+      path: /tmp/case
+`)
+	writeConfigFile(t, filepath.Join(dir, "fan2go.d", "ignored.txt"), `fans: []`)
+
+	// WHEN
+	config, err := readTestConfig(t, configPath)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, config.Fans, 2)
+	assert.Equal(t, "main_fan", config.Fans[0].ID)
+	assert.Equal(t, "case_fan", config.Fans[1].ID)
+	assert.Len(t, config.Sensors, 1)
+	assert.Equal(t, "cpu", config.Sensors[0].ID)
+	assert.Len(t, config.Curves, 1)
+	assert.Equal(t, "cpu", config.Curves[0].Linear.Sensor)
+}
+
+func TestIncludeGlobPattern(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "fan2go.yaml")
+	writeConfigFile(t, configPath, `
+include:
+  - devices/*.yaml
+`)
+	writeConfigFile(t, filepath.Join(dir, "devices", "b.yaml"), `
+fans:
+  - id: b
+`)
+	writeConfigFile(t, filepath.Join(dir, "devices", "a.yaml"), `
+fans:
+  - id: a
+`)
+
+	// WHEN
+	config, err := readTestConfig(t, configPath)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, config.Fans, 2)
+	assert.Equal(t, "a", config.Fans[0].ID)
+	assert.Equal(t, "b", config.Fans[1].ID)
+	assert.True(t, IsIncludedFile(filepath.Join(dir, "devices", "c.yaml")))
+	assert.False(t, IsIncludedFile(filepath.Join(dir, "c.yaml")))
+	assert.Equal(t, []string{filepath.Join(dir, "devices")}, GetIncludeDirs())
+}
+
+func TestIncludeUnsupportedKey(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "fan2go.yaml")
+	includePath := filepath.Join(dir, "fan2go.d", "api.yaml")
+	writeConfigFile(t, configPath, `
+include:
+  - fan2go.d
+`)
+	writeConfigFile(t, includePath, `
+dbPath: /tmp/fan2go.db
+`)
+
+	// WHEN
+	_, err := readTestConfig(t, configPath)
+
+	// THEN
+	assert.EqualError(t, err, includePath+": unsupported key 'dbpath', included files may only contain: fans | sensors | curves | profiles")
+}
+
+func TestIncludedFilePermissionsAreChecked(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("Skipping tests which require root")
+	}
+
+	// GIVEN
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "fan2go.yaml")
+	includePath := filepath.Join(dir, "fan2go.d", "cmd.yaml")
+	writeConfigFile(t, configPath, `
+include:
+  - fan2go.d
+`)
+	writeConfigFile(t, includePath, `
+sensors:
+  - id: cmd
+    cmd:
+      exec: /usr/bin/true
+`)
+	err := os.Chmod(includePath, 0666)
+	assert.NoError(t, err)
+	_, err = readTestConfig(t, configPath)
+	assert.NoError(t, err)
+
+	// WHEN
+	err = checkConfigFilePermissions(configPath)
+
+	// THEN
+	assert.EqualError(t, err, "config file '"+includePath+"' has invalid permissions: others have write permission")
+}
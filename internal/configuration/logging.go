@@ -0,0 +1,31 @@
+package configuration
+
+const (
+	LogOutputConsole  = "console"
+	LogOutputJournald = "journald"
+	LogOutputSyslog   = "syslog"
+)
+
+type LoggingConfig struct {
+	// Output is where log messages are written to, one of: console | journald | syslog,
+	// defaults to console
+	Output string `json:"output"`
+	// Level is the minimum level of logged messages, one of: debug | info | warn | error,
+	// defaults to info
+	Level string `json:"level"`
+	// Debug is a list of subsystems whose debug messages are logged regardless of the level,
+	// any of: controller | hwmon | persistence
+	Debug []string `json:"debug"`
+	// File additionally writes all log messages to a file
+	File LogFileConfig `json:"file"`
+}
+
+type LogFileConfig struct {
+	// Path of the log file, no log file is written if empty
+	Path string `json:"path"`
+	// MaxSize is the size in megabytes after which the file is rotated, 0 disables rotation,
+	// defaults to 10
+	MaxSize int `json:"maxSize"`
+	// MaxBackups is the number of rotated files that are kept, defaults to 3
+	MaxBackups int `json:"maxBackups"`
+}
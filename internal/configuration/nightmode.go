@@ -0,0 +1,22 @@
+package configuration
+
+// NightModeConfig caps the pwm of fans during the configured hours, f.ex. to keep the
+// machine quiet at night even under load, at the cost of higher temperatures
+type NightModeConfig struct {
+	Enabled bool `json:"enabled"`
+	// From is the time of day (HH:MM) at which night mode starts
+	From string `json:"from"`
+	// To is the time of day (HH:MM) at which night mode ends, may be before From to span midnight
+	To string `json:"to"`
+	// MaxPwm caps the pwm value ([0..255]) set by the curves of all fans, if set
+	MaxPwm *int `json:"maxPwm,omitempty"`
+	// Fans overrides the cap of individual fans
+	Fans []NightModeFanConfig `json:"fans"`
+}
+
+type NightModeFanConfig struct {
+	// Fan is the id of the fan the cap applies to
+	Fan string `json:"fan"`
+	// MaxPwm caps the pwm value ([0..255]) set by the curve of the fan
+	MaxPwm int `json:"maxPwm"`
+}
@@ -0,0 +1,24 @@
+package configuration
+
+import "time"
+
+// DefaultPluginDir is the directory plugins are loaded from if PluginDir is not configured
+const DefaultPluginDir = "/usr/lib/fan2go/plugins"
+
+type PluginConfig struct {
+	// Name of the plugin, the executable fan2go-plugin-<name> in pluginDir is used
+	Name string `json:"name"`
+	// Options are passed to the plugin as is, f.ex. to select a device or channel.
+	// Note that option names are passed in lower case.
+	Options map[string]interface{} `json:"options,omitempty"`
+	// Timeout is the maximum duration a single call of the plugin is allowed to run, defaults to 5s
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// GetPluginDir returns the configured directory containing plugins
+func GetPluginDir() string {
+	if len(CurrentConfig.PluginDir) <= 0 {
+		return DefaultPluginDir
+	}
+	return CurrentConfig.PluginDir
+}
@@ -0,0 +1,19 @@
+package configuration
+
+import "time"
+
+type AdaptivePollingConfig struct {
+	Enabled bool `json:"enabled"`
+	// MaxInterval is the longest interval sensors are polled and fans are updated at, while
+	// temperatures are stable and fans idle, defaults to 2s
+	MaxInterval time.Duration `json:"maxInterval"`
+	// RampUp is the amount of time it takes to lengthen the intervals from their configured
+	// rate to MaxInterval, defaults to 1m
+	RampUp time.Duration `json:"rampUp"`
+	// StableDelta is the max change of a sensor value (in degrees) between two polls,
+	// for which the sensor is still considered stable, defaults to 0.5
+	StableDelta float64 `json:"stableDelta"`
+	// RapidDelta is the change of a sensor value (in degrees) between two polls, which
+	// immediately resets all intervals to their configured rate, defaults to 2
+	RapidDelta float64 `json:"rapidDelta"`
+}
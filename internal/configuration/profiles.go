@@ -0,0 +1,16 @@
+package configuration
+
+type ProfileConfig struct {
+	ID string `json:"id"`
+	// Fans are the fan settings that differ from the regular fan configuration while this profile is active
+	Fans []ProfileFanConfig `json:"fans"`
+}
+
+type ProfileFanConfig struct {
+	// Fan is the id of the fan these settings apply to
+	Fan string `json:"fan"`
+	// Curve replaces the curve of the fan, if set
+	Curve string `json:"curve,omitempty"`
+	// MaxPwm caps the pwm value ([0..255]) set by the curve of the fan, if set
+	MaxPwm *int `json:"maxPwm,omitempty"`
+}
@@ -0,0 +1,37 @@
+package configuration
+
+import "time"
+
+// RedfishConfig contains the connection details of a BMC with a Redfish API
+type RedfishConfig struct {
+	// Url is the base url of the BMC, f.ex. https://192.168.1.10
+	Url string `json:"url"`
+	// Username and Password are used for basic authentication, if no Token is set.
+	// Credentials are never serialized, since fans and sensors are exposed by the api.
+	Username string `json:"username"`
+	Password string `json:"-"`
+	// Token is a Redfish session token, sent in the X-Auth-Token header
+	Token string `json:"-"`
+	// Insecure skips the verification of the TLS certificate of the BMC, which is often self-signed
+	Insecure bool `json:"insecure"`
+	// Chassis is the id of the chassis containing the sensor or fan, defaults to the first chassis
+	Chassis string `json:"chassis"`
+	// Timeout of a single request, defaults to 5s
+	Timeout time.Duration `json:"timeout"`
+}
+
+type RedfishSensorConfig struct {
+	RedfishConfig `mapstructure:",squash"`
+	// Sensor is the name or member id of the temperature in the Thermal resource of the chassis
+	Sensor string `json:"sensor"`
+}
+
+type RedfishFanConfig struct {
+	RedfishConfig `mapstructure:",squash"`
+	// Control is the path of the Control resource used to set the speed of the fan,
+	// f.ex. /redfish/v1/Chassis/1/Controls/Fan1
+	Control string `json:"control"`
+	// Fan is the name or member id of the fan in the Thermal resource of the chassis,
+	// used to read its RPM
+	Fan string `json:"fan"`
+}
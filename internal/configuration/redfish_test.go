@@ -0,0 +1,54 @@
+package configuration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentialsAreNotSerialized(t *testing.T) {
+	// GIVEN
+	config := FanConfig{
+		ID: "fan",
+		Redfish: &RedfishFanConfig{
+			RedfishConfig: RedfishConfig{
+				Url:      "https://bmc",
+				Username: "admin",
+				Password: "redfish-secret",
+				Token:    "redfish-token",
+			},
+		},
+		Ipmi: &IpmiFanConfig{Username: "admin", Password: "ipmi-secret"},
+		Mqtt: &MqttFanConfig{Username: "admin", Password: "mqtt-secret"},
+	}
+
+	// WHEN
+	data, err := json.Marshal(config)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "admin")
+	assert.NotContains(t, string(data), "secret")
+	assert.NotContains(t, string(data), "token")
+}
+
+func TestCheckSchemaAcceptsCredentials(t *testing.T) {
+	// GIVEN
+	content := `
+fans:
+  - id: bmc_fan
+    redfish:
+      url: https://bmc
+      username: admin
+      password: secret
+      token: token
+      control: /redfish/v1/Chassis/1/Controls/Fan1
+`
+
+	// WHEN
+	err := checkSchema("fan2go.yaml", []byte(content))
+
+	// THEN
+	assert.NoError(t, err)
+}
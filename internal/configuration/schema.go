@@ -0,0 +1,344 @@
+package configuration
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// validationErrorPattern matches the errors of validateConfig which refer to a single list entry
+var validationErrorPattern = regexp.MustCompile(`^(fan|sensor|curve|profile) ([^:]+): `)
+
+// SchemaError is a problem found in a config file, at the given position
+type SchemaError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// SchemaErrors are all problems found in a config file
+type SchemaErrors []SchemaError
+
+func (e SchemaErrors) Error() string {
+	var lines []string
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkSchemaFile checks the given config file against the structure of Configuration,
+// files in formats other than yaml and json are not checked
+func checkSchemaFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return checkSchema(path, data)
+}
+
+// checkSchema reports unknown keys and values of the wrong type in the given yaml document,
+// which viper would otherwise silently ignore or fail to decode without any hint where
+func checkSchema(file string, data []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	if len(root.Content) <= 0 {
+		return nil
+	}
+
+	checker := schemaChecker{file: file}
+	checker.check(root.Content[0], reflect.TypeOf(Configuration{}), "")
+	if len(checker.errors) > 0 {
+		return checker.errors
+	}
+	return nil
+}
+
+type schemaChecker struct {
+	file   string
+	errors SchemaErrors
+}
+
+func (c *schemaChecker) fail(node *yaml.Node, format string, a ...interface{}) {
+	c.errors = append(c.errors, SchemaError{
+		File:    c.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+func (c *schemaChecker) check(node *yaml.Node, t reflect.Type, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Tag == "!!null" {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == durationType {
+		if node.Kind != yaml.ScalarNode {
+			c.fail(node, "%s: expected a duration, f.ex. 200ms", path)
+		} else if _, err := time.ParseDuration(node.Value); err != nil && node.Tag != "!!int" {
+			c.fail(node, "%s: expected a duration, f.ex. 200ms, got '%s'", path, node.Value)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		c.checkStruct(node, t, path)
+	case reflect.Map:
+		if node.Kind == yaml.SequenceNode {
+			// a list of maps is merged into a single map
+			for idx, item := range node.Content {
+				c.check(item, t, fmt.Sprintf("%s[%d]", path, idx))
+			}
+			return
+		}
+		if node.Kind != yaml.MappingNode {
+			c.fail(node, "%s: expected a map", path)
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			c.check(key, t.Key(), path)
+			c.check(value, t.Elem(), fmt.Sprintf("%s.%s", path, key.Value))
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			// a single value is decoded as a list with one element
+			c.check(node, t.Elem(), path)
+			return
+		}
+		for idx, item := range node.Content {
+			c.check(item, t.Elem(), fmt.Sprintf("%s[%d]", path, idx))
+		}
+	case reflect.Interface:
+	default:
+		c.checkScalar(node, t.Kind(), path)
+	}
+}
+
+func (c *schemaChecker) checkScalar(node *yaml.Node, kind reflect.Kind, path string) {
+	if node.Kind != yaml.ScalarNode {
+		c.fail(node, "%s: expected a single value, got a %s", path, describeNodeKind(node))
+		return
+	}
+
+	switch kind {
+	case reflect.Bool:
+		if _, err := strconv.ParseBool(node.Value); err != nil {
+			c.fail(node, "%s: expected true or false, got '%s'", path, node.Value)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseFloat(node.Value, 64)
+		if _, intErr := strconv.ParseInt(node.Value, 0, 64); intErr != nil && (err != nil || value != math.Trunc(value)) {
+			c.fail(node, "%s: expected an integer, got '%s'", path, node.Value)
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(node.Value, 64); err != nil {
+			c.fail(node, "%s: expected a number, got '%s'", path, node.Value)
+		}
+	}
+}
+
+func (c *schemaChecker) checkStruct(node *yaml.Node, t reflect.Type, path string) {
+	if node.Kind != yaml.MappingNode {
+		c.fail(node, "%s: expected a map of options, got a %s", path, describeNodeKind(node))
+		return
+	}
+
+	fields := map[string]reflect.StructField{}
+	collectFields(t, fields)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		// merge keys ("<<: *anchor") add the keys of another map
+		if key.Tag == "!!merge" {
+			c.check(value, t, path)
+			continue
+		}
+
+		keyPath := key.Value
+		if len(path) > 0 {
+			keyPath = path + "." + key.Value
+		}
+		field, ok := fields[strings.ToLower(key.Value)]
+		if !ok {
+			message := fmt.Sprintf("unknown key '%s'", keyPath)
+			if suggestion := suggestKey(key.Value, fields); len(suggestion) > 0 {
+				message += fmt.Sprintf(", did you mean '%s'?", suggestion)
+			}
+			c.fail(key, "%s", message)
+			continue
+		}
+		c.check(value, field.Type, keyPath)
+	}
+}
+
+// collectFields returns all fields of the given struct type by their lowercase name, which is how viper
+// matches keys. Embedded structs are squashed into their parent.
+func collectFields(t reflect.Type, result map[string]reflect.StructField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectFields(field.Type, result)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		result[strings.ToLower(field.Name)] = field
+	}
+}
+
+// suggestKey returns the key closest to the given unknown key, if it is close enough to likely be a typo
+func suggestKey(key string, fields map[string]reflect.StructField) string {
+	best := ""
+	bestDistance := len(key)/3 + 1
+	for name, field := range fields {
+		distance := levenshtein(strings.ToLower(key), name)
+		if distance <= bestDistance && (len(best) <= 0 || distance < bestDistance) {
+			best = getKeyName(field)
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// getKeyName returns the name of the given field as used in the config file
+func getKeyName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if len(name) > 0 && name != "-" {
+		return name
+	}
+	// fields that are not serialized, f.ex. passwords, are still read from the config file
+	return strings.ToLower(field.Name[:1]) + field.Name[1:]
+}
+
+func levenshtein(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
+
+func describeNodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "map"
+	case yaml.SequenceNode:
+		return "list"
+	default:
+		return fmt.Sprintf("value '%s'", node.Value)
+	}
+}
+
+// locateValidationError prefixes the given error of validateConfig with the position of the fan, sensor,
+// curve or profile it refers to, if it can be found in one of the given files
+func locateValidationError(err error, files []string) error {
+	match := validationErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	listKey := match[1] + "s"
+	id := match[2]
+
+	for _, file := range files {
+		data, readErr := os.ReadFile(file)
+		if readErr != nil {
+			continue
+		}
+		var root yaml.Node
+		if yaml.Unmarshal(data, &root) != nil || len(root.Content) <= 0 {
+			continue
+		}
+		if node := findListEntry(root.Content[0], listKey, id); node != nil {
+			return SchemaError{File: file, Line: node.Line, Column: node.Column, Message: err.Error()}
+		}
+	}
+	return err
+}
+
+// findListEntry returns the entry with the given id of the list with the given key
+func findListEntry(node *yaml.Node, listKey string, id string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !strings.EqualFold(node.Content[i].Value, listKey) || node.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, entry := range node.Content[i+1].Content {
+			if entry.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(entry.Content); j += 2 {
+				if strings.EqualFold(entry.Content[j].Value, "id") && entry.Content[j+1].Value == id {
+					return entry
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getConfigFiles returns the config file and all included files
+func getConfigFiles() []string {
+	files := []string{GetFilePath()}
+	included, err := findIncludedFiles()
+	if err == nil {
+		files = append(files, included...)
+	}
+	return files
+}
@@ -0,0 +1,139 @@
+package configuration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSchemaExampleConfig(t *testing.T) {
+	// GIVEN
+	configPath := filepath.Join("..", "..", "fan2go.yaml")
+
+	// WHEN
+	err := checkSchemaFile(configPath)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func TestCheckSchemaValid(t *testing.T) {
+	// GIVEN
+	content := `
+dbPath: /tmp/fan2go.db
+tempSensorPollingRate: 200ms
+fans:
+  - id: cpu_fan
+    hwmon:
+      platform: nct6798
+      rpmChannel: 1
+    neverStop: true
+    curve: cpu_curve
+    pwmMap:
+      0: 0
+      255: 255
+sensors:
+  - id: cpu
+    file:
+      path: /tmp/cpu
+curves:
+  - id: cpu_curve
+    linear:
+      sensor: cpu
+      steps:
+        - 40: 0
+        - 80: 255
+`
+
+	// WHEN
+	err := checkSchema("fan2go.yaml", []byte(content))
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func TestCheckSchemaUnknownKey(t *testing.T) {
+	// GIVEN
+	content := `
+sensors:
+  - id: cpu
+    hwmon:
+      platform: coretemp
+      index: 1
+    temerature: 1
+`
+
+	// WHEN
+	err := checkSchema("fan2go.yaml", []byte(content))
+
+	// THEN
+	assert.EqualError(t, err, "fan2go.yaml:7:5: unknown key 'sensors[0].temerature'")
+}
+
+func TestCheckSchemaSuggestsKey(t *testing.T) {
+	// GIVEN
+	content := `
+fans:
+  - id: cpu_fan
+    hwmon:
+      platfrom: nct6798
+      rpmChannel: 1
+    curve: cpu_curve
+`
+
+	// WHEN
+	err := checkSchema("fan2go.yaml", []byte(content))
+
+	// THEN
+	assert.EqualError(t, err, "fan2go.yaml:5:7: unknown key 'fans[0].hwmon.platfrom', did you mean 'platform'?")
+}
+
+func TestCheckSchemaWrongTypes(t *testing.T) {
+	// GIVEN
+	content := `
+tempSensorPollingRate: fast
+fans:
+  - id: cpu_fan
+    neverStop: sometimes
+    minPwm: 30.5
+    curve:
+      - cpu_curve
+`
+
+	// WHEN
+	err := checkSchema("fan2go.yaml", []byte(content))
+
+	// THEN
+	assert.EqualError(t, err, `fan2go.yaml:2:24: tempSensorPollingRate: expected a duration, f.ex. 200ms, got 'fast'
+fan2go.yaml:5:16: fans[0].neverStop: expected true or false, got 'sometimes'
+fan2go.yaml:6:13: fans[0].minPwm: expected an integer, got '30.5'
+fan2go.yaml:8:7: fans[0].curve: expected a single value, got a list`)
+}
+
+func TestLocateValidationError(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "fan2go.yaml")
+	writeConfigFile(t, configPath, `
+fans:
+  - id: other_fan
+    curve: curve
+  - id: cpu_fan
+    curve: missing
+`)
+	_, err := readTestConfig(t, configPath)
+	assert.NoError(t, err)
+
+	// WHEN
+	result := locateValidationError(assertError("fan cpu_fan: no curve definition with id 'missing' found"), []string{configPath})
+
+	// THEN
+	assert.EqualError(t, result, configPath+":5:5: fan cpu_fan: no curve definition with id 'missing' found")
+}
+
+type assertError string
+
+func (e assertError) Error() string {
+	return string(e)
+}
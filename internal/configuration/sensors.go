@@ -1,23 +1,314 @@
 package configuration
 
+import "time"
+
 type SensorConfig struct {
-	ID    string             `json:"id"`
-	HwMon *HwMonSensorConfig `json:"hwMon,omitempty"`
-	File  *FileSensorConfig  `json:"file,omitempty"`
-	Cmd   *CmdSensorConfig   `json:"cmd,omitempty"`
+	ID        string                 `json:"id"`
+	HwMon     *HwMonSensorConfig     `json:"hwMon,omitempty"`
+	File      *FileSensorConfig      `json:"file,omitempty"`
+	Cmd       *CmdSensorConfig       `json:"cmd,omitempty"`
+	Nvme      *NvmeSensorConfig      `json:"nvme,omitempty"`
+	Nvidia    *NvidiaSensorConfig    `json:"nvidia,omitempty"`
+	AmdGpu    *AmdGpuSensorConfig    `json:"amdgpu,omitempty"`
+	Http      *HttpSensorConfig      `json:"http,omitempty"`
+	Snmp      *SnmpSensorConfig      `json:"snmp,omitempty"`
+	Redfish   *RedfishSensorConfig   `json:"redfish,omitempty"`
+	Remote    *RemoteSensorConfig    `json:"remote,omitempty"`
+	Smart     *SmartSensorConfig     `json:"smart,omitempty"`
+	Thermal   *ThermalSensorConfig   `json:"thermal,omitempty"`
+	Rapl      *RaplSensorConfig      `json:"rapl,omitempty"`
+	Liquidctl *LiquidctlSensorConfig `json:"liquidctl,omitempty"`
+	UsbHid    *UsbHidSensorConfig    `json:"usbHid,omitempty"`
+	Virtual   *VirtualSensorConfig   `json:"virtual,omitempty"`
+	Plugin    *PluginConfig          `json:"plugin,omitempty"`
+	// Expression computes the sensor value using a formula of other sensor values
+	Expression *ExpressionSensorConfig `json:"expression,omitempty"`
+	Critical   *CriticalConfig         `json:"critical,omitempty"`
+	// PollingRate overrides tempSensorPollingRate for this sensor, f.ex. to poll slow sensors less often
+	PollingRate time.Duration `json:"pollingRate,omitempty"`
+	// Smoothing configures how the values of this sensor are averaged
+	Smoothing *SmoothingConfig `json:"smoothing,omitempty"`
+	// Factor is multiplied with the raw values of this sensor, f.ex. 100 for a sensor
+	// reporting tenths of a degree instead of milli-degrees, defaults to 1
+	Factor float64 `json:"factor,omitempty"`
+	// Offset (in degrees) is added to the values of this sensor after applying Factor,
+	// f.ex. to correct a sensor that reads consistently high or low, defaults to 0
+	Offset float64 `json:"offset,omitempty"`
+}
+
+const (
+	// SmoothingTypeMovingAvg averages the values over a window of fixed size
+	SmoothingTypeMovingAvg = "movingAvg"
+	// SmoothingTypeEwma computes an exponentially weighted moving average
+	SmoothingTypeEwma = "ewma"
+)
+
+type SmoothingConfig struct {
+	// Type is the smoothing algorithm, one of: movingAvg | ewma, defaults to movingAvg
+	Type string `json:"type"`
+	// WindowSize is the number of values the moving average is computed over, defaults to tempRollingWindowSize
+	WindowSize int `json:"windowSize"`
+	// Window is the time span the moving average is computed over, it is converted to a number
+	// of values using the polling rate of the sensor and takes precedence over WindowSize
+	Window time.Duration `json:"window"`
+	// Alpha is the weight (0..1] of a new value in the ewma, higher values react faster
+	Alpha float64 `json:"alpha"`
+	// AlphaRising is used instead of Alpha if a new value is higher than the current average,
+	// to react faster to rising temperatures, defaults to Alpha
+	AlphaRising float64 `json:"alphaRising"`
+}
+
+const (
+	// CriticalActionMaxFans drives all fans at full speed
+	CriticalActionMaxFans = "maxFans"
+	// CriticalActionExec runs a user defined command
+	CriticalActionExec = "exec"
+	// CriticalActionShutdown powers off the system
+	CriticalActionShutdown = "shutdown"
+)
+
+type CriticalConfig struct {
+	// Temp is the temperature (in degrees) at or above which the emergency actions are taken
+	Temp float64 `json:"temp"`
+	// Hysteresis is the amount of degrees the sensor has to drop below Temp,
+	// before the emergency is over, defaults to 0
+	Hysteresis float64 `json:"hysteresis"`
+	// Actions taken when Temp is reached, any of: maxFans | exec | shutdown, defaults to maxFans
+	Actions []string `json:"actions"`
+	// Exec is the command run by the exec action, "%sensor%" and "%temp%"
+	// are replaced with their respective value in the arguments
+	Exec *ExecConfig `json:"exec,omitempty"`
+}
+
+// Copy returns a copy of the sensor config whose hwmon configs can be resolved against the detected devices
+// without modifying the ones of this config, which may be used by a running sensor
+func (c SensorConfig) Copy() SensorConfig {
+	if c.HwMon != nil {
+		hwMon := *c.HwMon
+		c.HwMon = &hwMon
+	}
+	if c.AmdGpu != nil {
+		amdGpu := *c.AmdGpu
+		amdGpu.Temps = append([]string(nil), c.AmdGpu.Temps...)
+		amdGpu.TempInputs = append([]string(nil), c.AmdGpu.TempInputs...)
+		c.AmdGpu = &amdGpu
+	}
+	return c
 }
 
 type HwMonSensorConfig struct {
-	Platform  string `json:"platform"`
-	Index     int    `json:"index"`
-	TempInput string
+	Platform string `json:"platform"`
+	// Name is a regex matching the name of the hwmon device, f.ex. "nct6798", for devices without a stable platform
+	Name string `json:"name,omitempty"`
+	// Modalias is a regex matching the modalias of the hwmon device, f.ex. "pci:v00001002d0000731F"
+	Modalias string `json:"modalias,omitempty"`
+	// PciAddress is the address of the PCI device of the hwmon device, f.ex. "pci-0000:2f:00.0",
+	// to distinguish identical devices
+	PciAddress string `json:"pciAddress,omitempty"`
+	Index      int    `json:"index"`
+	TempInput  string
 }
 
 type FileSensorConfig struct {
 	Path string `json:"path"`
+	// Scale is the factor the value in the file is multiplied with to convert it
+	// to milli-units, f.ex. 1000 for a file containing degrees, defaults to 1
+	Scale float64 `json:"scale"`
 }
 
 type CmdSensorConfig struct {
 	Exec string   `json:"exec"`
 	Args []string `json:"args"`
+	// Timeout is the maximum duration the command is allowed to run, defaults to 2s
+	Timeout time.Duration `json:"timeout"`
+}
+
+type HttpSensorConfig struct {
+	// Url is the HTTP(S) endpoint to poll
+	Url string `json:"url"`
+	// Headers are additional headers to send with each request, f.ex. for authentication,
+	// they are not serialized since they may contain credentials
+	Headers map[string]string `json:"-"`
+	// JsonPath extracts the value from a json response, f.ex. "$.sensors[0].value"
+	JsonPath string `json:"jsonPath"`
+	// Regex extracts the value from the response (or the result of JsonPath),
+	// using the first capture group if there is one
+	Regex string `json:"regex"`
+	// Scale is the factor the extracted value is multiplied with to convert it to milli-units, defaults to 1
+	Scale float64 `json:"scale"`
+	// Timeout of a single request, defaults to 2s
+	Timeout time.Duration `json:"timeout"`
+}
+
+type RemoteSensorConfig struct {
+	// Address is the host and port of the gRPC api of the fan2go instance (f.ex. a `fan2go agent`)
+	// providing the sensor, f.ex. "storage-node:9002"
+	Address string `json:"address"`
+	// Sensor is the id of the sensor on the remote instance
+	Sensor string `json:"sensor"`
+	// Timeout of a single request, defaults to 2s
+	Timeout time.Duration `json:"timeout"`
+	// Tls connects to the remote instance using TLS, required if it has `grpc.tls` configured
+	Tls *RemoteTlsConfig `json:"tls,omitempty"`
+	// Token is sent to the remote instance with each request, required if it has `grpc.token` configured.
+	// It is never serialized, since sensors are exposed by the api.
+	Token string `json:"-"`
+}
+
+type RemoteTlsConfig struct {
+	// CaFile is the PEM encoded certificate authority used to verify the remote instance,
+	// f.ex. its self-signed certificate, the system certificate authorities are used if empty
+	CaFile string `json:"caFile"`
+	// ServerName is the name the certificate of the remote instance is verified for, defaults to the host of Address
+	ServerName string `json:"serverName"`
+}
+
+const (
+	SnmpVersion1  = "1"
+	SnmpVersion2c = "2c"
+	SnmpVersion3  = "3"
+)
+
+type SnmpSensorConfig struct {
+	Host string `json:"host"`
+	// Port of the snmp agent, defaults to 161
+	Port int `json:"port"`
+	// Version of the protocol, one of: 1 | 2c | 3, defaults to 2c
+	Version string `json:"version"`
+	// Community used for version 1 and 2c, defaults to "public", it acts as a password
+	// and isn't serialized
+	Community string `json:"-"`
+	// Oid of the value to read
+	Oid string `json:"oid"`
+	// Scale is the factor the value is multiplied with to convert it to milli-units, defaults to 1
+	Scale float64 `json:"scale"`
+	// Timeout of a single request, defaults to 2s
+	Timeout time.Duration `json:"timeout"`
+
+	// Username, AuthProtocol (MD5 | SHA | SHA224 | SHA256 | SHA384 | SHA512), AuthPassword,
+	// PrivProtocol (DES | AES | AES192 | AES256) and PrivPassword are used for version 3,
+	// the passwords are not serialized
+	Username     string `json:"username"`
+	AuthProtocol string `json:"authProtocol"`
+	AuthPassword string `json:"-"`
+	PrivProtocol string `json:"privProtocol"`
+	PrivPassword string `json:"-"`
+}
+
+type SmartSensorConfig struct {
+	// Device is the device path of the drive, f.ex. /dev/sda
+	Device string `json:"device"`
+	// Serial selects the drive by its serial number instead of its device path
+	Serial string `json:"serial"`
+	// Smartctl is the path to the smartctl executable, defaults to /usr/sbin/smartctl
+	Smartctl string `json:"smartctl"`
+	// Interval at which the drive is queried, defaults to 1m
+	Interval time.Duration `json:"interval"`
+}
+
+type RaplSensorConfig struct {
+	// Zone is the name (f.ex. "package-0", "core" or "dram") or the directory (f.ex. "intel-rapl:0")
+	// of the powercap zone, or the label of an amd_energy counter (f.ex. "Esocket0"), defaults to package-0
+	Zone string `json:"zone"`
+}
+
+type ThermalSensorConfig struct {
+	// Zone is the index of the thermal zone, f.ex. 0 for /sys/class/thermal/thermal_zone0
+	Zone int `json:"zone"`
+	// Type selects the thermal zone by its type instead of its index, f.ex. "x86_pkg_temp"
+	Type string `json:"type"`
+}
+
+type LiquidctlSensorConfig struct {
+	// Match is passed to "liquidctl --match" to select the device
+	Match string `json:"match"`
+	// Key of the status value to read, f.ex. "Liquid temperature"
+	Key string `json:"key"`
+	// Liquidctl is the path to the liquidctl executable, defaults to /usr/bin/liquidctl
+	Liquidctl string `json:"liquidctl"`
+}
+
+type UsbHidSensorConfig struct {
+	// Model of the controller, one of: aquacomputer-d5next | aquacomputer-octo | aquacomputer-quadro
+	Model string `json:"model"`
+	// Index of the controller, if there are multiple controllers of the same model
+	Index int `json:"index"`
+	// Path of the hidraw device of the controller, takes precedence over Model and Index if set
+	Path string `json:"path"`
+	// Sensor of the controller to read, f.ex. "temp1", "virtual1" or "flow"
+	Sensor string `json:"sensor"`
+}
+
+type NvmeSensorConfig struct {
+	// Device is the name (nvme0) or device path (/dev/nvme0) of the NVMe controller
+	Device string `json:"device"`
+	// Serial selects the NVMe controller by its serial number instead of its device name
+	Serial string `json:"serial"`
+	// Index of the temperature sensor, 1 is the "Composite" temperature of the drive
+	Index int `json:"index"`
+}
+
+const (
+	// NvidiaSensorTypeCore is the temperature of the GPU core
+	NvidiaSensorTypeCore = "core"
+	// NvidiaSensorTypeMemory is the temperature of the GPU memory
+	NvidiaSensorTypeMemory = "memory"
+	// NvidiaSensorTypeUtilization is the percentage of time the GPU was busy
+	NvidiaSensorTypeUtilization = "utilization"
+)
+
+type NvidiaSensorConfig struct {
+	// Index of the GPU as enumerated by NVML
+	Index int `json:"index"`
+	// UUID of the GPU, takes precedence over Index if set
+	UUID string `json:"uuid"`
+	// Type of the value to read, one of: core | memory | utilization
+	Type string `json:"type"`
+}
+
+const (
+	AmdGpuSensorTempEdge     = "edge"
+	AmdGpuSensorTempJunction = "junction"
+	AmdGpuSensorTempMem      = "mem"
+
+	// AmdGpuSensorTypeTemperature reads the temperature inputs of the GPU
+	AmdGpuSensorTypeTemperature = "temperature"
+	// AmdGpuSensorTypeUtilization reads the percentage of time the GPU was busy
+	AmdGpuSensorTypeUtilization = "utilization"
+
+	AggregationMax     = "max"
+	AggregationMin     = "min"
+	AggregationAverage = "avg"
+	// AggregationWeighted is the weighted sum of all values
+	AggregationWeighted = "weighted"
+	// AggregationDifference is the value of the first minus the value of the second sensor
+	AggregationDifference = "difference"
+)
+
+type AmdGpuSensorConfig struct {
+	// Platform is a regex matching the platform of the amdgpu hwmon controller, defaults to "amdgpu"
+	Platform string `json:"platform"`
+	// Type of the value to read, one of: temperature | utilization, defaults to temperature
+	Type string `json:"type"`
+	// Temps is a list of the temperature inputs to read, any of: edge | junction | mem
+	Temps []string `json:"temps"`
+	// Aggregation is used to combine the values of multiple temps, one of: max | min | avg
+	Aggregation string `json:"aggregation"`
+	TempInputs  []string
+	BusyInput   string
+}
+
+type VirtualSensorConfig struct {
+	// Function is used to combine the values of the sensors, one of: max | min | avg | weighted | difference
+	Function string `json:"function"`
+	// Sensors is a list of the ids of the sensors to combine
+	Sensors []string `json:"sensors"`
+	// Weights is a list of factors the value of each sensor is multiplied with, used by "weighted"
+	Weights []float64 `json:"weights"`
+}
+
+type ExpressionSensorConfig struct {
+	// Formula computes the sensor value (in degrees) from the values (in degrees) of the sensors
+	// it references by their id, f.ex. "(cpu + gpu) / 2" or "max(nvme0, nvme1)"
+	Formula string `json:"formula"`
 }
@@ -0,0 +1,14 @@
+package configuration
+
+import "time"
+
+type StallDetectionConfig struct {
+	Enabled bool `json:"enabled"`
+	// Cycles is the number of consecutive rpm measurements reporting 0 RPM at a
+	// PWM value the fan should be spinning at, before the fan is considered stalled
+	Cycles int `json:"cycles"`
+	// KickPwm is the PWM value applied to restart a stalled fan, defaults to 255
+	KickPwm int `json:"kickPwm"`
+	// KickDuration is the amount of time KickPwm is applied for
+	KickDuration time.Duration `json:"kickDuration"`
+}
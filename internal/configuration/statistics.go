@@ -1,6 +1,37 @@
 package configuration
 
+import "time"
+
 type StatisticsConfig struct {
 	Enabled bool `json:"enabled"`
 	Port    int  `json:"port,omitempty"`
+	// InfluxDb periodically writes all metrics to an InfluxDB v2 server
+	InfluxDb InfluxDbConfig `json:"influxDb,omitempty"`
+	// Graphite periodically sends all metrics to a Graphite server using the plaintext protocol
+	Graphite PushConfig `json:"graphite,omitempty"`
+	// Statsd periodically sends all metrics as gauges to a StatsD server
+	Statsd PushConfig `json:"statsd,omitempty"`
+}
+
+type InfluxDbConfig struct {
+	Enabled bool `json:"enabled"`
+	// Url is the base url of the InfluxDB server, f.ex. "http://localhost:8086"
+	Url    string `json:"url"`
+	Org    string `json:"org"`
+	Bucket string `json:"bucket"`
+	// Token is the API token used to authenticate with the server
+	Token string `json:"token,omitempty"`
+	// Interval is the time between two writes, defaults to 10s
+	Interval time.Duration `json:"interval,omitempty"`
+}
+
+// PushConfig configures a server that metrics are periodically pushed to
+type PushConfig struct {
+	Enabled bool `json:"enabled"`
+	// Address is the "host:port" of the server
+	Address string `json:"address,omitempty"`
+	// Prefix is prepended to the name of all metrics, defaults to "fan2go"
+	Prefix string `json:"prefix,omitempty"`
+	// Interval is the time between two pushes, defaults to 10s
+	Interval time.Duration `json:"interval,omitempty"`
 }
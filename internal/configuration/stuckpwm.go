@@ -0,0 +1,11 @@
+package configuration
+
+type StuckPwmDetectionConfig struct {
+	Enabled bool `json:"enabled"`
+	// Cycles is the number of consecutive controller updates in which the pwm read back from a fan
+	// differs from the last written value, before the pwm of the fan is considered stuck
+	Cycles int `json:"cycles"`
+	// ReassertPwmEnable applies the manual control mode (pwm_enable) and the last pwm value
+	// of a fan again, once its pwm is considered stuck
+	ReassertPwmEnable bool `json:"reassertPwmEnable"`
+}
@@ -2,16 +2,53 @@ package configuration
 
 import (
 	"fmt"
+	"net"
+	"path"
+	"regexp"
 	"strings"
 
 	"github.com/looplab/tarjan"
+	"github.com/markusressel/fan2go/internal/expression"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/markusressel/fan2go/internal/util"
 	"golang.org/x/exp/slices"
 )
 
 func Validate(configPath string) error {
-	return validateConfig(&CurrentConfig, configPath)
+	err := validateConfig(&CurrentConfig, configPath)
+	if err != nil {
+		return locateValidationError(err, getConfigFiles())
+	}
+	return nil
+}
+
+// ValidateAgent validates the parts of the current configuration used by `fan2go agent`,
+// which only polls sensors, curves and fans are ignored
+func ValidateAgent(configPath string) error {
+	err := validateAgentConfig(&CurrentConfig, configPath)
+	if err != nil {
+		return locateValidationError(err, getConfigFiles())
+	}
+	return nil
+}
+
+func validateAgentConfig(config *Configuration, path string) error {
+	err := validateSensors(config)
+	if err != nil {
+		return err
+	}
+	err = validateLogging(config)
+	if err != nil {
+		return err
+	}
+	err = validateGrpc(config.Grpc)
+	if err != nil {
+		return err
+	}
+	if containsCmdSensors(config) {
+		return checkConfigFilePermissions(path)
+	}
+	return nil
 }
 
 func validateConfig(config *Configuration, path string) error {
@@ -19,24 +56,101 @@ func validateConfig(config *Configuration, path string) error {
 	if err != nil {
 		return err
 	}
+	warnUnusedSensors(config)
 	err = validateCurves(config)
 	if err != nil {
 		return err
 	}
 	err = validateFans(config)
+	if err != nil {
+		return err
+	}
+	err = validateProfiles(config)
+	if err != nil {
+		return err
+	}
+	err = validateStallDetection(config)
+	if err != nil {
+		return err
+	}
+	err = validateStuckPwmDetection(config)
+	if err != nil {
+		return err
+	}
+	err = validateFailsafe(config)
+	if err != nil {
+		return err
+	}
+	err = validateNightMode(config)
+	if err != nil {
+		return err
+	}
+	if config.PwmLimit != nil && (*config.PwmLimit < 0 || *config.PwmLimit > 255) {
+		return fmt.Errorf("invalid pwmLimit %d, must be in range [0..255]", *config.PwmLimit)
+	}
+	err = validateAdaptivePolling(config)
+	if err != nil {
+		return err
+	}
+	err = validateHistory(config)
+	if err != nil {
+		return err
+	}
+	err = validateInfluxDb(config)
+	if err != nil {
+		return err
+	}
+	err = validatePush("graphite", config.Statistics.Graphite)
+	if err != nil {
+		return err
+	}
+	err = validatePush("statsd", config.Statistics.Statsd)
+	if err != nil {
+		return err
+	}
+	err = validateLogging(config)
+	if err != nil {
+		return err
+	}
+	err = validateGrpc(config.Grpc)
+	if err != nil {
+		return err
+	}
+	if len(config.HwMonBackend) > 0 && config.HwMonBackend != HwMonBackendLibsensors && config.HwMonBackend != HwMonBackendSysfs {
+		return fmt.Errorf("invalid hwMonBackend '%s', must be one of: %s | %s", config.HwMonBackend, HwMonBackendLibsensors, HwMonBackendSysfs)
+	}
+	if len(config.CalibrationFile) > 0 && !config.NoDb {
+		return fmt.Errorf("calibrationFile can only be used together with noDb")
+	}
+	err = validateAlerting(config)
 
-	if containsCmdSensors() || containsCmdFan() {
-		if _, err := util.CheckFilePermissionsForExecution(path); err != nil {
-			return fmt.Errorf("config file '%s' has invalid permissions: %s", path, err)
+	if containsCmdSensors(config) || containsCmdFan(config) || containsCmdAlert(config) {
+		if err := checkConfigFilePermissions(path); err != nil {
+			return err
 		}
 	}
 
 	return err
 }
 
-func containsCmdFan() bool {
-	for _, fanConfig := range CurrentConfig.Fans {
-		if fanConfig.Cmd != nil {
+// checkConfigFilePermissions checks whether the given config file and all files included by it
+// are safe to configure commands executed by fan2go, since any of them may contribute such commands
+func checkConfigFilePermissions(path string) error {
+	included, err := findIncludedFiles()
+	if err != nil {
+		return err
+	}
+	for _, file := range append([]string{path}, included...) {
+		if _, err := util.CheckFilePermissionsForExecution(file); err != nil {
+			return fmt.Errorf("config file '%s' has invalid permissions: %s", file, err)
+		}
+	}
+	return nil
+}
+
+func containsCmdFan(config *Configuration) bool {
+	for _, fanConfig := range config.Fans {
+		if isCmdFan(fanConfig) {
 			return true
 		}
 	}
@@ -44,9 +158,31 @@ func containsCmdFan() bool {
 	return false
 }
 
-func containsCmdSensors() bool {
-	for _, sensorConfig := range CurrentConfig.Sensors {
-		if sensorConfig.Cmd != nil {
+// isCmdFan returns true if the given fan, or any member of it if it is a group, runs external commands
+func isCmdFan(fanConfig FanConfig) bool {
+	if fanConfig.Cmd != nil || fanConfig.Ipmi != nil || fanConfig.Liquidctl != nil || fanConfig.Plugin != nil {
+		return true
+	}
+	if fanConfig.Group != nil {
+		for _, member := range fanConfig.Group.Members {
+			if isCmdFan(member) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsCmdAlert(config *Configuration) bool {
+	return config.Alerting.Enabled && config.Alerting.Exec != nil
+}
+
+func containsCmdSensors(config *Configuration) bool {
+	for _, sensorConfig := range config.Sensors {
+		if sensorConfig.Cmd != nil || sensorConfig.Smart != nil || sensorConfig.Liquidctl != nil || sensorConfig.Plugin != nil {
+			return true
+		}
+		if sensorConfig.Critical != nil && sensorConfig.Critical.Exec != nil {
 			return true
 		}
 	}
@@ -54,7 +190,16 @@ func containsCmdSensors() bool {
 	return false
 }
 
+func warnUnusedSensors(config *Configuration) {
+	for _, sensorConfig := range config.Sensors {
+		if !isSensorConfigInUse(sensorConfig, config.Sensors, config.Curves, config.Fans) {
+			ui.Warning("Unused sensor configuration: %s", sensorConfig.ID)
+		}
+	}
+}
+
 func validateSensors(config *Configuration) error {
+	graph := make(map[interface{}][]interface{})
 	sensorIds := []string{}
 
 	for _, sensorConfig := range config.Sensors {
@@ -73,37 +218,346 @@ func validateSensors(config *Configuration) error {
 		if sensorConfig.Cmd != nil {
 			subConfigs++
 		}
+		if sensorConfig.Nvme != nil {
+			subConfigs++
+		}
+		if sensorConfig.Nvidia != nil {
+			subConfigs++
+		}
+		if sensorConfig.AmdGpu != nil {
+			subConfigs++
+		}
+		if sensorConfig.Http != nil {
+			subConfigs++
+		}
+		if sensorConfig.Snmp != nil {
+			subConfigs++
+		}
+		if sensorConfig.Redfish != nil {
+			subConfigs++
+		}
+		if sensorConfig.Remote != nil {
+			subConfigs++
+		}
+		if sensorConfig.Smart != nil {
+			subConfigs++
+		}
+		if sensorConfig.Thermal != nil {
+			subConfigs++
+		}
+		if sensorConfig.Rapl != nil {
+			subConfigs++
+		}
+		if sensorConfig.Liquidctl != nil {
+			subConfigs++
+		}
+		if sensorConfig.UsbHid != nil {
+			subConfigs++
+		}
+		if sensorConfig.Virtual != nil {
+			subConfigs++
+		}
+		if sensorConfig.Plugin != nil {
+			subConfigs++
+		}
+		if sensorConfig.Expression != nil {
+			subConfigs++
+		}
 		if subConfigs > 1 {
 			return fmt.Errorf("sensor %s: only one sensor type can be used per sensor definition block", sensorConfig.ID)
 		}
 		if subConfigs <= 0 {
-			return fmt.Errorf("sensor %s: sub-configuration for sensor is missing, use one of: hwmon | file | cmd", sensorConfig.ID)
+			return fmt.Errorf("sensor %s: sub-configuration for sensor is missing, use one of: hwmon | file | cmd | nvme | nvidia | amdgpu | http | snmp | redfish | remote | smart | thermal | rapl | liquidctl | usbHid | virtual | plugin | expression", sensorConfig.ID)
 		}
 
-		if !isSensorConfigInUse(sensorConfig, config.Curves) {
-			ui.Warning("Unused sensor configuration: %s", sensorConfig.ID)
+		if sensorConfig.PollingRate < 0 {
+			return fmt.Errorf("sensor %s: invalid pollingRate, must be > 0", sensorConfig.ID)
+		}
+
+		if sensorConfig.Factor < 0 {
+			return fmt.Errorf("sensor %s: invalid factor, must be > 0", sensorConfig.ID)
+		}
+
+		if sensorConfig.Smoothing != nil {
+			if err := validateSmoothingConfig(sensorConfig.ID, *sensorConfig.Smoothing); err != nil {
+				return err
+			}
+		}
+
+		if sensorConfig.Critical != nil {
+			if err := validateCriticalConfig(sensorConfig.ID, *sensorConfig.Critical); err != nil {
+				return err
+			}
 		}
 
 		if sensorConfig.HwMon != nil {
 			if sensorConfig.HwMon.Index <= 0 {
 				return fmt.Errorf("sensor %s: invalid index, must be >= 1", sensorConfig.ID)
 			}
+			if err := validateDeviceMatch(sensorConfig.HwMon.Platform, sensorConfig.HwMon.Name, sensorConfig.HwMon.Modalias, sensorConfig.HwMon.PciAddress); err != nil {
+				return fmt.Errorf("sensor %s: %v", sensorConfig.ID, err)
+			}
+		}
+
+		if sensorConfig.File != nil {
+			if sensorConfig.File.Scale < 0 {
+				return fmt.Errorf("sensor %s: invalid scale, must be > 0", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Cmd != nil {
+			if len(sensorConfig.Cmd.Exec) <= 0 {
+				return fmt.Errorf("sensor %s: executable is missing", sensorConfig.ID)
+			}
+			if sensorConfig.Cmd.Timeout < 0 {
+				return fmt.Errorf("sensor %s: invalid timeout, must be > 0", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Http != nil {
+			if len(sensorConfig.Http.Url) <= 0 {
+				return fmt.Errorf("sensor %s: no url provided", sensorConfig.ID)
+			}
+			if len(sensorConfig.Http.Regex) > 0 {
+				if _, err := regexp.Compile(sensorConfig.Http.Regex); err != nil {
+					return fmt.Errorf("sensor %s: invalid regex: %v", sensorConfig.ID, err)
+				}
+			}
+			if sensorConfig.Http.Scale < 0 {
+				return fmt.Errorf("sensor %s: invalid scale, must be > 0", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Redfish != nil {
+			if err := validateRedfishConfig(sensorConfig.Redfish.RedfishConfig); err != nil {
+				return fmt.Errorf("sensor %s: %v", sensorConfig.ID, err)
+			}
+			if len(sensorConfig.Redfish.Sensor) <= 0 {
+				return fmt.Errorf("sensor %s: no redfish sensor provided", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Remote != nil {
+			if len(sensorConfig.Remote.Address) <= 0 {
+				return fmt.Errorf("sensor %s: no remote address provided", sensorConfig.ID)
+			}
+			if len(sensorConfig.Remote.Sensor) <= 0 {
+				return fmt.Errorf("sensor %s: no remote sensor provided", sensorConfig.ID)
+			}
+			if sensorConfig.Remote.Timeout < 0 {
+				return fmt.Errorf("sensor %s: invalid timeout, must be > 0", sensorConfig.ID)
+			}
+			if len(sensorConfig.Remote.Token) > 0 && sensorConfig.Remote.Tls == nil {
+				return fmt.Errorf("sensor %s: a remote token requires tls, since it would be sent in clear text otherwise", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Snmp != nil {
+			if len(sensorConfig.Snmp.Host) <= 0 {
+				return fmt.Errorf("sensor %s: no host provided", sensorConfig.ID)
+			}
+			if len(sensorConfig.Snmp.Oid) <= 0 {
+				return fmt.Errorf("sensor %s: no oid provided", sensorConfig.ID)
+			}
+			supportedVersions := []string{SnmpVersion1, SnmpVersion2c, SnmpVersion3}
+			if len(sensorConfig.Snmp.Version) > 0 && !slices.Contains(supportedVersions, sensorConfig.Snmp.Version) {
+				return fmt.Errorf("sensor %s: unsupported snmp version '%s', use one of: %s", sensorConfig.ID, sensorConfig.Snmp.Version, strings.Join(supportedVersions, " | "))
+			}
+			if sensorConfig.Snmp.Version == SnmpVersion3 && len(sensorConfig.Snmp.Username) <= 0 {
+				return fmt.Errorf("sensor %s: snmp version 3 requires a username", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Smart != nil {
+			if (len(sensorConfig.Smart.Device) > 0) == (len(sensorConfig.Smart.Serial) > 0) {
+				return fmt.Errorf("sensor %s: must have one of device or serial", sensorConfig.ID)
+			}
+			if sensorConfig.Smart.Interval < 0 {
+				return fmt.Errorf("sensor %s: invalid interval, must be > 0", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Thermal != nil {
+			if sensorConfig.Thermal.Zone < 0 {
+				return fmt.Errorf("sensor %s: invalid zone, must be >= 0", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Liquidctl != nil {
+			if len(sensorConfig.Liquidctl.Key) <= 0 {
+				return fmt.Errorf("sensor %s: no key provided", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.UsbHid != nil {
+			if len(sensorConfig.UsbHid.Path) <= 0 && !slices.Contains(UsbHidSensorModels, sensorConfig.UsbHid.Model) {
+				return fmt.Errorf("sensor %s: unsupported usb hid model '%s', use one of: %s", sensorConfig.ID, sensorConfig.UsbHid.Model, strings.Join(UsbHidSensorModels, " | "))
+			}
+			if len(sensorConfig.UsbHid.Sensor) <= 0 {
+				return fmt.Errorf("sensor %s: no usb hid sensor provided", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Plugin != nil {
+			if err := validatePluginConfig(*sensorConfig.Plugin); err != nil {
+				return fmt.Errorf("sensor %s: %v", sensorConfig.ID, err)
+			}
+		}
+
+		if sensorConfig.Virtual != nil {
+			supportedFunctions := []string{AggregationMax, AggregationMin, AggregationAverage, AggregationWeighted, AggregationDifference}
+			if !slices.Contains(supportedFunctions, sensorConfig.Virtual.Function) {
+				return fmt.Errorf("sensor %s: unsupported function '%s', use one of: %s", sensorConfig.ID, sensorConfig.Virtual.Function, strings.Join(supportedFunctions, " | "))
+			}
+			if len(sensorConfig.Virtual.Sensors) <= 0 {
+				return fmt.Errorf("sensor %s: no sensors provided", sensorConfig.ID)
+			}
+			if sensorConfig.Virtual.Function == AggregationWeighted && len(sensorConfig.Virtual.Weights) != len(sensorConfig.Virtual.Sensors) {
+				return fmt.Errorf("sensor %s: number of weights must match the number of sensors", sensorConfig.ID)
+			}
+			if sensorConfig.Virtual.Function == AggregationDifference && len(sensorConfig.Virtual.Sensors) != 2 {
+				return fmt.Errorf("sensor %s: difference requires exactly two sensors", sensorConfig.ID)
+			}
+
+			var connections []interface{}
+			for _, sensorId := range sensorConfig.Virtual.Sensors {
+				if sensorId == sensorConfig.ID {
+					return fmt.Errorf("sensor %s: a sensor cannot reference itself", sensorConfig.ID)
+				}
+				if !sensorIdExists(sensorId, config) {
+					return fmt.Errorf("sensor %s: no sensor definition with id '%s' found", sensorConfig.ID, sensorId)
+				}
+				connections = append(connections, sensorId)
+			}
+			graph[sensorConfig.ID] = connections
+		}
+
+		if sensorConfig.Expression != nil {
+			if len(strings.TrimSpace(sensorConfig.Expression.Formula)) <= 0 {
+				return fmt.Errorf("sensor %s: missing formula", sensorConfig.ID)
+			}
+			parsed, err := expression.Parse(sensorConfig.Expression.Formula)
+			if err != nil {
+				return fmt.Errorf("sensor %s: invalid formula: %v", sensorConfig.ID, err)
+			}
+
+			var connections []interface{}
+			for _, sensorId := range parsed.Variables() {
+				if sensorId == sensorConfig.ID {
+					return fmt.Errorf("sensor %s: a sensor cannot reference itself", sensorConfig.ID)
+				}
+				if !sensorIdExists(sensorId, config) {
+					return fmt.Errorf("sensor %s: no sensor definition with id '%s' found", sensorConfig.ID, sensorId)
+				}
+				connections = append(connections, sensorId)
+			}
+			graph[sensorConfig.ID] = connections
+		}
+
+		if sensorConfig.Nvme != nil {
+			if (len(sensorConfig.Nvme.Device) > 0) == (len(sensorConfig.Nvme.Serial) > 0) {
+				return fmt.Errorf("sensor %s: must have one of device or serial", sensorConfig.ID)
+			}
+			if sensorConfig.Nvme.Index < 0 {
+				return fmt.Errorf("sensor %s: invalid index, must be >= 1", sensorConfig.ID)
+			}
+		}
+
+		if sensorConfig.Nvidia != nil {
+			if sensorConfig.Nvidia.Index < 0 {
+				return fmt.Errorf("sensor %s: invalid index, must be >= 0", sensorConfig.ID)
+			}
+			supportedTypes := []string{NvidiaSensorTypeCore, NvidiaSensorTypeMemory, NvidiaSensorTypeUtilization}
+			if len(sensorConfig.Nvidia.Type) > 0 && !slices.Contains(supportedTypes, sensorConfig.Nvidia.Type) {
+				return fmt.Errorf("sensor %s: unsupported type '%s', use one of: %s", sensorConfig.ID, sensorConfig.Nvidia.Type, strings.Join(supportedTypes, " | "))
+			}
+		}
+
+		if sensorConfig.AmdGpu != nil {
+			supportedTypes := []string{AmdGpuSensorTypeTemperature, AmdGpuSensorTypeUtilization}
+			if len(sensorConfig.AmdGpu.Type) > 0 && !slices.Contains(supportedTypes, sensorConfig.AmdGpu.Type) {
+				return fmt.Errorf("sensor %s: unsupported type '%s', use one of: %s", sensorConfig.ID, sensorConfig.AmdGpu.Type, strings.Join(supportedTypes, " | "))
+			}
+			supportedTemps := []string{AmdGpuSensorTempEdge, AmdGpuSensorTempJunction, AmdGpuSensorTempMem}
+			for _, temp := range sensorConfig.AmdGpu.Temps {
+				if !slices.Contains(supportedTemps, temp) {
+					return fmt.Errorf("sensor %s: unsupported temp '%s', use any of: %s", sensorConfig.ID, temp, strings.Join(supportedTemps, " | "))
+				}
+			}
+			supportedAggregations := []string{AggregationMax, AggregationMin, AggregationAverage}
+			if len(sensorConfig.AmdGpu.Aggregation) > 0 && !slices.Contains(supportedAggregations, sensorConfig.AmdGpu.Aggregation) {
+				return fmt.Errorf("sensor %s: unsupported aggregation '%s', use one of: %s", sensorConfig.ID, sensorConfig.AmdGpu.Aggregation, strings.Join(supportedAggregations, " | "))
+			}
+		}
+	}
+
+	return validateNoLoops("sensor", graph)
+}
+
+// validateSmoothingConfig checks the smoothing settings of the given sensor
+func validateSmoothingConfig(sensorId string, smoothing SmoothingConfig) error {
+	switch smoothing.Type {
+	case "", SmoothingTypeMovingAvg:
+	case SmoothingTypeEwma:
+		if smoothing.Alpha <= 0 || smoothing.Alpha > 1 {
+			return fmt.Errorf("sensor %s: smoothing: invalid alpha %v, must be in range (0..1]", sensorId, smoothing.Alpha)
+		}
+		if smoothing.AlphaRising < 0 || smoothing.AlphaRising > 1 {
+			return fmt.Errorf("sensor %s: smoothing: invalid alphaRising %v, must be in range (0..1]", sensorId, smoothing.AlphaRising)
 		}
+	default:
+		return fmt.Errorf("sensor %s: smoothing: invalid type '%s', must be one of: %s | %s", sensorId, smoothing.Type, SmoothingTypeMovingAvg, SmoothingTypeEwma)
 	}
+	if smoothing.WindowSize < 0 {
+		return fmt.Errorf("sensor %s: smoothing: invalid windowSize, must be >= 1", sensorId)
+	}
+	if smoothing.Window < 0 {
+		return fmt.Errorf("sensor %s: smoothing: invalid window, must be > 0", sensorId)
+	}
+	return nil
+}
 
+func validateCriticalConfig(sensorId string, config CriticalConfig) error {
+	if config.Hysteresis < 0 {
+		return fmt.Errorf("sensor %s: critical: invalid hysteresis, must be >= 0", sensorId)
+	}
+	supportedActions := []string{CriticalActionMaxFans, CriticalActionExec, CriticalActionShutdown}
+	for _, action := range config.Actions {
+		if !slices.Contains(supportedActions, action) {
+			return fmt.Errorf("sensor %s: critical: unsupported action '%s', use any of: %s", sensorId, action, strings.Join(supportedActions, " | "))
+		}
+	}
+	if slices.Contains(config.Actions, CriticalActionExec) && (config.Exec == nil || len(config.Exec.Exec) <= 0) {
+		return fmt.Errorf("sensor %s: critical: the exec action requires an executable", sensorId)
+	}
 	return nil
 }
 
-func isSensorConfigInUse(config SensorConfig, curves []CurveConfig) bool {
+func isSensorConfigInUse(config SensorConfig, sensors []SensorConfig, curves []CurveConfig, fans []FanConfig) bool {
+	for _, sensorConfig := range sensors {
+		if sensorConfig.Virtual != nil && util.ContainsString(sensorConfig.Virtual.Sensors, config.ID) {
+			return true
+		}
+		if sensorConfig.Expression != nil {
+			parsed, err := expression.Parse(sensorConfig.Expression.Formula)
+			if err == nil && slices.Contains(parsed.Variables(), config.ID) {
+				return true
+			}
+		}
+	}
+
 	for _, curveConfig := range curves {
 		if curveConfig.Function != nil {
 			// function curves cannot reference sensors
 			continue
 		}
-		if curveConfig.Linear != nil && curveConfig.Linear.Sensor == config.ID {
+		if slices.Contains(curveConfig.GetSensorIds(), config.ID) {
 			return true
 		}
-		if curveConfig.PID != nil && curveConfig.PID.Sensor == config.ID {
+	}
+
+	for _, fanConfig := range fans {
+		if fanConfig.ZeroRpm != nil && fanConfig.ZeroRpm.Sensor == config.ID {
 			return true
 		}
 	}
@@ -131,14 +585,23 @@ func validateCurves(config *Configuration) error {
 		if curveConfig.Function != nil {
 			subConfigs++
 		}
+		if curveConfig.Target != nil {
+			subConfigs++
+		}
+		if curveConfig.Schedule != nil {
+			subConfigs++
+		}
+		if curveConfig.Expression != nil {
+			subConfigs++
+		}
 		if subConfigs > 1 {
 			return fmt.Errorf("curve %s: only one curve type can be used per curve definition block", curveConfig.ID)
 		}
 		if subConfigs <= 0 {
-			return fmt.Errorf("curve %s: sub-configuration for curve is missing, use one of: linear | pid | function", curveConfig.ID)
+			return fmt.Errorf("curve %s: sub-configuration for curve is missing, use one of: linear | pid | function | target | schedule | expression", curveConfig.ID)
 		}
 
-		if !isCurveConfigInUse(curveConfig, config.Curves, config.Fans) {
+		if !isCurveConfigInUse(curveConfig, config.Curves, config.Fans, config.Profiles) {
 			ui.Warning("Unused curve configuration: %s", curveConfig.ID)
 		}
 
@@ -147,6 +610,9 @@ func validateCurves(config *Configuration) error {
 			if !slices.Contains(supportedTypes, curveConfig.Function.Type) {
 				return fmt.Errorf("curve %s: unsupported function type '%s', use one of: %s", curveConfig.ID, curveConfig.Function.Type, strings.Join(supportedTypes, " | "))
 			}
+			if len(curveConfig.Function.Curves) <= 0 {
+				return fmt.Errorf("curve %s: no curves provided", curveConfig.ID)
+			}
 
 			var connections []interface{}
 			for _, curve := range curveConfig.Function.Curves {
@@ -161,23 +627,66 @@ func validateCurves(config *Configuration) error {
 			graph[curveConfig.ID] = connections
 		}
 
-		if curveConfig.Linear != nil {
-			if len(curveConfig.Linear.Sensor) <= 0 {
-				return fmt.Errorf("curve %s: missing sensorId", curveConfig.ID)
-			}
-
-			if !sensorIdExists(curveConfig.Linear.Sensor, config) {
-				return fmt.Errorf("curve %s: no sensor definition with id '%s' found", curveConfig.ID, curveConfig.Linear.Sensor)
+		if curveConfig.Schedule != nil {
+			scheduleConfig := curveConfig.Schedule
+			if len(scheduleConfig.Default) <= 0 {
+				return fmt.Errorf("curve %s: missing default curve", curveConfig.ID)
 			}
-		}
 
-		if curveConfig.PID != nil {
-			if len(curveConfig.PID.Sensor) <= 0 {
-				return fmt.Errorf("curve %s: missing sensorId", curveConfig.ID)
+			curveRefs := []string{scheduleConfig.Default}
+			for _, entry := range scheduleConfig.Entries {
+				if _, err := util.ParseTimeOfDay(entry.From); err != nil {
+					return fmt.Errorf("curve %s: %v", curveConfig.ID, err)
+				}
+				if _, err := util.ParseTimeOfDay(entry.To); err != nil {
+					return fmt.Errorf("curve %s: %v", curveConfig.ID, err)
+				}
+				curveRefs = append(curveRefs, entry.Curve)
 			}
 
-			if !sensorIdExists(curveConfig.PID.Sensor, config) {
-				return fmt.Errorf("curve %s: no sensor definition with id '%s' found", curveConfig.ID, curveConfig.PID.Sensor)
+			var connections []interface{}
+			for _, curve := range curveRefs {
+				if curve == curveConfig.ID {
+					return fmt.Errorf("curve %s: a curve cannot reference itself", curveConfig.ID)
+				}
+				if !curveIdExists(curve, config) {
+					return fmt.Errorf("curve %s: no curve definition with id '%s' found", curveConfig.ID, curve)
+				}
+				connections = append(connections, curve)
+			}
+			graph[curveConfig.ID] = connections
+		}
+
+		if curveConfig.Linear != nil {
+			if len(curveConfig.Linear.Curve) > 0 {
+				curve := curveConfig.Linear.Curve
+				if len(curveConfig.Linear.Sensor) > 0 || len(curveConfig.Linear.Sensors) > 0 {
+					return fmt.Errorf("curve %s: only one of sensor, sensors or curve can be used", curveConfig.ID)
+				}
+				if curve == curveConfig.ID {
+					return fmt.Errorf("curve %s: a curve cannot reference itself", curveConfig.ID)
+				}
+				if !curveIdExists(curve, config) {
+					return fmt.Errorf("curve %s: no curve definition with id '%s' found", curveConfig.ID, curve)
+				}
+				graph[curveConfig.ID] = []interface{}{curve}
+			} else if err := validateCurveInput(curveConfig.ID, curveConfig.Linear.Sensor, curveConfig.Linear.Sensors, config); err != nil {
+				return err
+			}
+
+			supportedInterpolations := []string{util.InterpolationTypeLinear, util.InterpolationTypeStep, util.InterpolationTypeMonotone}
+			if len(curveConfig.Linear.Interpolation) > 0 && !slices.Contains(supportedInterpolations, curveConfig.Linear.Interpolation) {
+				return fmt.Errorf("curve %s: unsupported interpolation '%s', use one of: %s", curveConfig.ID, curveConfig.Linear.Interpolation, strings.Join(supportedInterpolations, " | "))
+			}
+
+			if curveConfig.Linear.Hysteresis < 0 {
+				return fmt.Errorf("curve %s: invalid hysteresis, must be >= 0", curveConfig.ID)
+			}
+		}
+
+		if curveConfig.PID != nil {
+			if err := validateCurveInput(curveConfig.ID, curveConfig.PID.Sensor, curveConfig.PID.Sensors, config); err != nil {
+				return err
 			}
 
 			pidConfig := curveConfig.PID
@@ -186,12 +695,78 @@ func validateCurves(config *Configuration) error {
 			}
 		}
 
+		if curveConfig.Target != nil {
+			targetConfig := curveConfig.Target
+			if err := validateCurveInput(curveConfig.ID, targetConfig.Sensor, targetConfig.Sensors, config); err != nil {
+				return err
+			}
+
+			maxValue := targetConfig.Max
+			if maxValue <= 0 {
+				maxValue = 255
+			}
+			if targetConfig.Min < 0 || maxValue > 255 || targetConfig.Min > maxValue {
+				return fmt.Errorf("curve %s: invalid min/max, must be 0 <= min <= max <= 255", curveConfig.ID)
+			}
+			if targetConfig.Gain < 0 {
+				return fmt.Errorf("curve %s: invalid gain, must be > 0", curveConfig.ID)
+			}
+			if targetConfig.Tolerance < 0 {
+				return fmt.Errorf("curve %s: invalid tolerance, must be >= 0", curveConfig.ID)
+			}
+		}
+
+		if curveConfig.Expression != nil {
+			if len(strings.TrimSpace(curveConfig.Expression.Formula)) <= 0 {
+				return fmt.Errorf("curve %s: missing formula", curveConfig.ID)
+			}
+			parsed, err := expression.Parse(curveConfig.Expression.Formula)
+			if err != nil {
+				return fmt.Errorf("curve %s: invalid formula: %v", curveConfig.ID, err)
+			}
+			for _, sensorId := range parsed.Variables() {
+				if !sensorIdExists(sensorId, config) {
+					return fmt.Errorf("curve %s: no sensor definition with id '%s' found", curveConfig.ID, sensorId)
+				}
+			}
+		}
+
 	}
 
-	err := validateNoLoops(graph)
+	err := validateNoLoops("curve", graph)
 	return err
 }
 
+// validateCurveInput checks the sensor, or the weighted sensors, a curve is driven by
+func validateCurveInput(curveId string, sensor string, weighted []WeightedSensorConfig, config *Configuration) error {
+	if len(sensor) > 0 && len(weighted) > 0 {
+		return fmt.Errorf("curve %s: only one of sensor or sensors can be used", curveId)
+	}
+
+	if len(weighted) <= 0 {
+		if len(sensor) <= 0 {
+			return fmt.Errorf("curve %s: missing sensorId", curveId)
+		}
+		if !sensorIdExists(sensor, config) {
+			return fmt.Errorf("curve %s: no sensor definition with id '%s' found", curveId, sensor)
+		}
+		return nil
+	}
+
+	for _, sensorConfig := range weighted {
+		if len(sensorConfig.ID) <= 0 {
+			return fmt.Errorf("curve %s: missing sensorId", curveId)
+		}
+		if !sensorIdExists(sensorConfig.ID, config) {
+			return fmt.Errorf("curve %s: no sensor definition with id '%s' found", curveId, sensorConfig.ID)
+		}
+		if sensorConfig.Weight == 0 {
+			return fmt.Errorf("curve %s: missing weight of sensor '%s'", curveId, sensorConfig.ID)
+		}
+	}
+	return nil
+}
+
 func sensorIdExists(sensorId string, config *Configuration) bool {
 	for _, sensor := range config.Sensors {
 		if sensor.ID == sensorId {
@@ -202,23 +777,36 @@ func sensorIdExists(sensorId string, config *Configuration) bool {
 	return false
 }
 
-func validateNoLoops(graph map[interface{}][]interface{}) error {
+func validateNoLoops(kind string, graph map[interface{}][]interface{}) error {
 	output := tarjan.Connections(graph)
 	for _, items := range output {
 		if len(items) > 1 {
-			return fmt.Errorf("you have created a curve dependency cycle: %v", items)
+			return fmt.Errorf("you have created a %s dependency cycle: %v", kind, items)
 		}
 	}
 	return nil
 }
 
-func isCurveConfigInUse(config CurveConfig, curves []CurveConfig, fans []FanConfig) bool {
+func isCurveConfigInUse(config CurveConfig, curves []CurveConfig, fans []FanConfig, profiles []ProfileConfig) bool {
 	for _, curveConfig := range curves {
+		if curveConfig.Linear != nil && curveConfig.Linear.Curve == config.ID {
+			return true
+		}
 		if curveConfig.Function != nil {
 			if util.ContainsString(curveConfig.Function.Curves, config.ID) {
 				return true
 			}
 		}
+		if curveConfig.Schedule != nil {
+			if curveConfig.Schedule.Default == config.ID {
+				return true
+			}
+			for _, entry := range curveConfig.Schedule.Entries {
+				if entry.Curve == config.ID {
+					return true
+				}
+			}
+		}
 	}
 
 	for _, fanConfig := range fans {
@@ -227,6 +815,14 @@ func isCurveConfigInUse(config CurveConfig, curves []CurveConfig, fans []FanConf
 		}
 	}
 
+	for _, profileConfig := range profiles {
+		for _, fanConfig := range profileConfig.Fans {
+			if fanConfig.Curve == config.ID {
+				return true
+			}
+		}
+	}
+
 	return false
 }
 
@@ -249,12 +845,42 @@ func validateFans(config *Configuration) error {
 		if fanConfig.Cmd != nil {
 			subConfigs++
 		}
+		if fanConfig.Ipmi != nil {
+			subConfigs++
+		}
+		if fanConfig.Redfish != nil {
+			subConfigs++
+		}
+		if fanConfig.Mqtt != nil {
+			subConfigs++
+		}
+		if fanConfig.DellSmm != nil {
+			subConfigs++
+		}
+		if fanConfig.Liquidctl != nil {
+			subConfigs++
+		}
+		if fanConfig.UsbHid != nil {
+			subConfigs++
+		}
+		if fanConfig.Plugin != nil {
+			subConfigs++
+		}
+		if fanConfig.Group != nil {
+			subConfigs++
+		}
 
 		if subConfigs > 1 {
 			return fmt.Errorf("fan %s: only one fan type can be used per fan definition block", fanConfig.ID)
 		}
 		if subConfigs <= 0 {
-			return fmt.Errorf("fan %s: sub-configuration for fan is missing, use one of: hwmon | file | cmd", fanConfig.ID)
+			return fmt.Errorf("fan %s: sub-configuration for fan is missing, use one of: hwmon | file | cmd | ipmi | redfish | mqtt | dellSmm | liquidctl | usbHid | plugin | group", fanConfig.ID)
+		}
+
+		if fanConfig.Group != nil {
+			if err := validateGroupFanConfig(fanConfig); err != nil {
+				return err
+			}
 		}
 
 		if len(fanConfig.Curve) <= 0 {
@@ -265,18 +891,60 @@ func validateFans(config *Configuration) error {
 			return fmt.Errorf("fan %s: no curve definition with id '%s' found", fanConfig.ID, fanConfig.Curve)
 		}
 
-		if fanConfig.HwMon != nil {
-			if (fanConfig.HwMon.Index != 0 && fanConfig.HwMon.RpmChannel != 0) || (fanConfig.HwMon.Index == 0 && fanConfig.HwMon.RpmChannel == 0) {
-				return fmt.Errorf("fan %s: must have one of index or rpmChannel, must be >= 1", fanConfig.ID)
+		if err := validateFanPwmLimits(fanConfig); err != nil {
+			return err
+		}
+
+		if fanConfig.UpdateRate < 0 {
+			return fmt.Errorf("fan %s: invalid updateRate, must be > 0", fanConfig.ID)
+		}
+
+		if fanConfig.CurveFactor < 0 {
+			return fmt.Errorf("fan %s: invalid curveFactor, must be > 0", fanConfig.ID)
+		}
+		if fanConfig.CurveOffset < -255 || fanConfig.CurveOffset > 255 {
+			return fmt.Errorf("fan %s: invalid curveOffset %d, must be in range [-255..255]", fanConfig.ID, fanConfig.CurveOffset)
+		}
+
+		if fanConfig.PwmEnable != nil {
+			if fanConfig.PwmEnable.Manual != nil && *fanConfig.PwmEnable.Manual < 0 {
+				return fmt.Errorf("fan %s: pwmEnable: invalid manual value %d, must be >= 0", fanConfig.ID, *fanConfig.PwmEnable.Manual)
+			}
+			if fanConfig.PwmEnable.Restore != nil && *fanConfig.PwmEnable.Restore < 0 {
+				return fmt.Errorf("fan %s: pwmEnable: invalid restore value %d, must be >= 0", fanConfig.ID, *fanConfig.PwmEnable.Restore)
+			}
+		}
+
+		if fanConfig.ZeroRpm != nil {
+			if err := validateZeroRpmConfig(fanConfig, config); err != nil {
+				return err
+			}
+		}
+
+		if fanConfig.MinRpm < 0 {
+			return fmt.Errorf("fan %s: invalid minRpm, must be >= 0", fanConfig.ID)
+		}
+
+		if fanConfig.RpmTarget != nil {
+			if err := validateRpmTargetConfig(fanConfig); err != nil {
+				return err
 			}
-			if fanConfig.HwMon.Index < 0 {
-				return fmt.Errorf("fan %s: invalid index, must be >= 1", fanConfig.ID)
+		}
+
+		if fanConfig.HwMon != nil {
+			err := validateHwMonFanConfig(fanConfig.ID, *fanConfig.HwMon)
+			if err != nil {
+				return err
 			}
-			if fanConfig.HwMon.RpmChannel < 0 {
-				return fmt.Errorf("fan %s: invalid rpmChannel, must be >= 1", fanConfig.ID)
+		}
+
+		if fanConfig.DellSmm != nil {
+			err := validateHwMonFanConfig(fanConfig.ID, fanConfig.DellSmm.HwMonFanConfig)
+			if err != nil {
+				return err
 			}
-			if fanConfig.HwMon.PwmChannel < 0 {
-				return fmt.Errorf("fan %s: invalid pwmChannel, must be >= 1", fanConfig.ID)
+			if fanConfig.DellSmm.ReassertInterval < 0 {
+				return fmt.Errorf("fan %s: invalid reassertInterval, must be > 0", fanConfig.ID)
 			}
 		}
 
@@ -286,6 +954,60 @@ func validateFans(config *Configuration) error {
 			}
 		}
 
+		if fanConfig.Ipmi != nil {
+			if !slices.Contains(IpmiProfiles, fanConfig.Ipmi.Profile) {
+				return fmt.Errorf("fan %s: unsupported ipmi profile '%s', use one of: %s", fanConfig.ID, fanConfig.Ipmi.Profile, strings.Join(IpmiProfiles, " | "))
+			}
+			if fanConfig.Ipmi.Zone < 0 {
+				return fmt.Errorf("fan %s: invalid zone, must be >= 0", fanConfig.ID)
+			}
+			if fanConfig.Ipmi.Profile == IpmiProfileGeneric && (fanConfig.Ipmi.Raw == nil || len(fanConfig.Ipmi.Raw.SetPwm) <= 0) {
+				return fmt.Errorf("fan %s: the generic ipmi profile requires a raw setPwm command", fanConfig.ID)
+			}
+		}
+
+		if fanConfig.Redfish != nil {
+			if err := validateRedfishConfig(fanConfig.Redfish.RedfishConfig); err != nil {
+				return fmt.Errorf("fan %s: %v", fanConfig.ID, err)
+			}
+			if len(fanConfig.Redfish.Control) <= 0 {
+				return fmt.Errorf("fan %s: no redfish control provided", fanConfig.ID)
+			}
+		}
+
+		if fanConfig.Mqtt != nil {
+			if len(fanConfig.Mqtt.Broker) <= 0 {
+				return fmt.Errorf("fan %s: no mqtt broker provided", fanConfig.ID)
+			}
+			if len(fanConfig.Mqtt.PwmTopic) <= 0 {
+				return fmt.Errorf("fan %s: no pwmTopic provided", fanConfig.ID)
+			}
+			if fanConfig.Mqtt.Qos > 2 {
+				return fmt.Errorf("fan %s: invalid qos, must be one of: 0 | 1 | 2", fanConfig.ID)
+			}
+		}
+
+		if fanConfig.Liquidctl != nil {
+			if len(fanConfig.Liquidctl.Channel) <= 0 {
+				return fmt.Errorf("fan %s: no channel provided", fanConfig.ID)
+			}
+		}
+
+		if fanConfig.Plugin != nil {
+			if err := validatePluginConfig(*fanConfig.Plugin); err != nil {
+				return fmt.Errorf("fan %s: %v", fanConfig.ID, err)
+			}
+		}
+
+		if fanConfig.UsbHid != nil {
+			if len(fanConfig.UsbHid.Path) <= 0 && !slices.Contains(UsbHidFanModels, fanConfig.UsbHid.Model) {
+				return fmt.Errorf("fan %s: unsupported usb hid model '%s', use one of: %s", fanConfig.ID, fanConfig.UsbHid.Model, strings.Join(UsbHidFanModels, " | "))
+			}
+			if fanConfig.UsbHid.Channel <= 0 {
+				return fmt.Errorf("fan %s: invalid channel, must be >= 1", fanConfig.ID)
+			}
+		}
+
 		if fanConfig.Cmd != nil {
 			cmdConfig := fanConfig.Cmd
 			if cmdConfig.SetPwm == nil {
@@ -295,18 +1017,450 @@ func validateFans(config *Configuration) error {
 				return fmt.Errorf("fan %s: setPwm executable is missing", fanConfig.ID)
 			}
 
-			if cmdConfig.GetPwm == nil {
-				return fmt.Errorf("fan %s: missing getPwm configuration", fanConfig.ID)
-			}
-			if len(cmdConfig.GetPwm.Exec) <= 0 {
+			if cmdConfig.GetPwm != nil && len(cmdConfig.GetPwm.Exec) <= 0 {
 				return fmt.Errorf("fan %s: getPwm executable is missing", fanConfig.ID)
 			}
+			if cmdConfig.GetRpm != nil && len(cmdConfig.GetRpm.Exec) <= 0 {
+				return fmt.Errorf("fan %s: getRpm executable is missing", fanConfig.ID)
+			}
 		}
 	}
 
 	return nil
 }
 
+func validateProfiles(config *Configuration) error {
+	profileIds := []string{}
+
+	for _, profileConfig := range config.Profiles {
+		if len(profileConfig.ID) <= 0 {
+			return fmt.Errorf("profile: missing id")
+		}
+		if slices.Contains(profileIds, profileConfig.ID) {
+			return fmt.Errorf("duplicate profile id detected: %s", profileConfig.ID)
+		}
+		profileIds = append(profileIds, profileConfig.ID)
+
+		fanIds := []string{}
+		for _, fanConfig := range profileConfig.Fans {
+			if !fanIdExists(fanConfig.Fan, config) {
+				return fmt.Errorf("profile %s: no fan definition with id '%s' found", profileConfig.ID, fanConfig.Fan)
+			}
+			if slices.Contains(fanIds, fanConfig.Fan) {
+				return fmt.Errorf("profile %s: duplicate settings for fan %s", profileConfig.ID, fanConfig.Fan)
+			}
+			fanIds = append(fanIds, fanConfig.Fan)
+
+			if len(fanConfig.Curve) > 0 && !curveIdExists(fanConfig.Curve, config) {
+				return fmt.Errorf("profile %s: no curve definition with id '%s' found", profileConfig.ID, fanConfig.Curve)
+			}
+			if fanConfig.MaxPwm != nil && (*fanConfig.MaxPwm < 0 || *fanConfig.MaxPwm > 255) {
+				return fmt.Errorf("profile %s: invalid maxPwm for fan %s, must be in range [0..255]", profileConfig.ID, fanConfig.Fan)
+			}
+		}
+	}
+
+	if len(config.Profile) > 0 && !slices.Contains(profileIds, config.Profile) {
+		return fmt.Errorf("no profile definition with id '%s' found", config.Profile)
+	}
+
+	return nil
+}
+
+func validateStallDetection(config *Configuration) error {
+	stallDetection := config.StallDetection
+	if !stallDetection.Enabled {
+		return nil
+	}
+	if stallDetection.Cycles <= 0 {
+		return fmt.Errorf("stallDetection: invalid cycles, must be >= 1")
+	}
+	if stallDetection.KickPwm <= 0 || stallDetection.KickPwm > 255 {
+		return fmt.Errorf("stallDetection: invalid kickPwm %d, must be in range [1..255]", stallDetection.KickPwm)
+	}
+	if stallDetection.KickDuration <= 0 {
+		return fmt.Errorf("stallDetection: invalid kickDuration, must be > 0")
+	}
+	return nil
+}
+
+func validateRedfishConfig(config RedfishConfig) error {
+	if len(config.Url) <= 0 {
+		return fmt.Errorf("no redfish url provided")
+	}
+	if !strings.HasPrefix(config.Url, "https://") {
+		return fmt.Errorf("invalid redfish url '%s', must start with https://", config.Url)
+	}
+	if config.Timeout < 0 {
+		return fmt.Errorf("invalid redfish timeout, must be > 0")
+	}
+	return nil
+}
+
+func validateStuckPwmDetection(config *Configuration) error {
+	stuckPwmDetection := config.StuckPwmDetection
+	if stuckPwmDetection.Enabled && stuckPwmDetection.Cycles <= 0 {
+		return fmt.Errorf("stuckPwmDetection: invalid cycles, must be >= 1")
+	}
+	return nil
+}
+
+func validateFailsafe(config *Configuration) error {
+	failsafe := config.Failsafe
+	if failsafe.SensorTimeout < 0 {
+		return fmt.Errorf("failsafe: invalid sensorTimeout, must be >= 0")
+	}
+	if failsafe.SensorTimeout > 0 && (failsafe.Pwm <= 0 || failsafe.Pwm > 255) {
+		return fmt.Errorf("failsafe: invalid pwm %d, must be in range [1..255]", failsafe.Pwm)
+	}
+	return nil
+}
+
+func validateNightMode(config *Configuration) error {
+	nightMode := config.NightMode
+	if !nightMode.Enabled {
+		return nil
+	}
+	if _, err := util.ParseTimeOfDay(nightMode.From); err != nil {
+		return fmt.Errorf("nightMode: %v", err)
+	}
+	if _, err := util.ParseTimeOfDay(nightMode.To); err != nil {
+		return fmt.Errorf("nightMode: %v", err)
+	}
+	if nightMode.MaxPwm != nil && (*nightMode.MaxPwm < 0 || *nightMode.MaxPwm > 255) {
+		return fmt.Errorf("nightMode: invalid maxPwm %d, must be in range [0..255]", *nightMode.MaxPwm)
+	}
+
+	fanIds := []string{}
+	for _, fanConfig := range nightMode.Fans {
+		if !fanIdExists(fanConfig.Fan, config) {
+			return fmt.Errorf("nightMode: no fan definition with id '%s' found", fanConfig.Fan)
+		}
+		if slices.Contains(fanIds, fanConfig.Fan) {
+			return fmt.Errorf("nightMode: duplicate settings for fan %s", fanConfig.Fan)
+		}
+		fanIds = append(fanIds, fanConfig.Fan)
+
+		if fanConfig.MaxPwm < 0 || fanConfig.MaxPwm > 255 {
+			return fmt.Errorf("nightMode: invalid maxPwm for fan %s, must be in range [0..255]", fanConfig.Fan)
+		}
+	}
+	return nil
+}
+
+func validateAdaptivePolling(config *Configuration) error {
+	adaptivePolling := config.AdaptivePolling
+	if !adaptivePolling.Enabled {
+		return nil
+	}
+	if adaptivePolling.MaxInterval <= 0 {
+		return fmt.Errorf("adaptivePolling: invalid maxInterval, must be > 0")
+	}
+	if adaptivePolling.RampUp <= 0 {
+		return fmt.Errorf("adaptivePolling: invalid rampUp, must be > 0")
+	}
+	if adaptivePolling.StableDelta < 0 {
+		return fmt.Errorf("adaptivePolling: invalid stableDelta, must be >= 0")
+	}
+	if adaptivePolling.RapidDelta < adaptivePolling.StableDelta {
+		return fmt.Errorf("adaptivePolling: invalid rapidDelta, must be >= stableDelta")
+	}
+	return nil
+}
+
+func validateHistory(config *Configuration) error {
+	history := config.History
+	if !history.Enabled {
+		return nil
+	}
+	if history.Interval <= 0 {
+		return fmt.Errorf("history: invalid interval, must be > 0")
+	}
+	if history.Retention < history.Interval {
+		return fmt.Errorf("history: invalid retention, must be >= interval")
+	}
+	return nil
+}
+
+func validateInfluxDb(config *Configuration) error {
+	influxDb := config.Statistics.InfluxDb
+	if !influxDb.Enabled {
+		return nil
+	}
+	if len(influxDb.Url) <= 0 {
+		return fmt.Errorf("statistics: influxDb: missing url")
+	}
+	if len(influxDb.Bucket) <= 0 {
+		return fmt.Errorf("statistics: influxDb: missing bucket")
+	}
+	if influxDb.Interval <= 0 {
+		return fmt.Errorf("statistics: influxDb: invalid interval, must be > 0")
+	}
+	return nil
+}
+
+func validatePush(name string, config PushConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(config.Address); err != nil {
+		return fmt.Errorf("statistics: %s: invalid address '%s', must be host:port", name, config.Address)
+	}
+	if config.Interval <= 0 {
+		return fmt.Errorf("statistics: %s: invalid interval, must be > 0", name)
+	}
+	return nil
+}
+
+func validateLogging(config *Configuration) error {
+	logging := config.Logging
+	output := logging.Output
+	if len(output) > 0 && output != LogOutputConsole && output != LogOutputJournald && output != LogOutputSyslog {
+		return fmt.Errorf("logging: invalid output '%s', must be one of: %s | %s | %s", output, LogOutputConsole, LogOutputJournald, LogOutputSyslog)
+	}
+	if len(logging.Level) > 0 {
+		if _, err := ui.ParseLevel(logging.Level); err != nil {
+			return fmt.Errorf("logging: invalid level '%s', must be one of: debug | info | warn | error", logging.Level)
+		}
+	}
+	for _, subsystem := range logging.Debug {
+		if !slices.Contains(ui.Subsystems, subsystem) {
+			return fmt.Errorf("logging: unknown debug subsystem '%s', must be any of: %s", subsystem, strings.Join(ui.Subsystems, " | "))
+		}
+	}
+	if logging.File.MaxSize < 0 {
+		return fmt.Errorf("logging: file: invalid maxSize, must be >= 0")
+	}
+	if logging.File.MaxBackups < 0 {
+		return fmt.Errorf("logging: file: invalid maxBackups, must be >= 0")
+	}
+	return nil
+}
+
+func validateGrpc(config GrpcConfig) error {
+	if config.Tls != nil && (len(config.Tls.CertFile) <= 0 || len(config.Tls.KeyFile) <= 0) {
+		return fmt.Errorf("grpc: tls: certFile and keyFile are required")
+	}
+	if len(config.Token) > 0 && config.Tls == nil {
+		return fmt.Errorf("grpc: token requires tls, since it would be sent in clear text otherwise")
+	}
+	return nil
+}
+
+func validateAlerting(config *Configuration) error {
+	alerting := config.Alerting
+	if !alerting.Enabled {
+		return nil
+	}
+	if alerting.Webhook == nil && alerting.Email == nil && alerting.Exec == nil {
+		return fmt.Errorf("alerting: no alert target configured, use any of: webhook | email | exec")
+	}
+	if alerting.Cooldown < 0 {
+		return fmt.Errorf("alerting: invalid cooldown, must be >= 0")
+	}
+	if alerting.Webhook != nil && len(alerting.Webhook.Url) <= 0 {
+		return fmt.Errorf("alerting: webhook: missing url")
+	}
+	if alerting.Email != nil {
+		if len(alerting.Email.Host) <= 0 {
+			return fmt.Errorf("alerting: email: missing host")
+		}
+		if len(alerting.Email.From) <= 0 {
+			return fmt.Errorf("alerting: email: missing from address")
+		}
+		if len(alerting.Email.To) <= 0 {
+			return fmt.Errorf("alerting: email: missing recipient address")
+		}
+	}
+	if alerting.Exec != nil && len(alerting.Exec.Exec) <= 0 {
+		return fmt.Errorf("alerting: exec: executable is missing")
+	}
+	return nil
+}
+
+// validateFanPwmLimits checks the minPwm, startPwm, maxPwm and pwmLimit overrides of the given fan
+func validateFanPwmLimits(fanConfig FanConfig) error {
+	limits := []struct {
+		name  string
+		value *int
+	}{
+		{"minPwm", fanConfig.MinPwm},
+		{"startPwm", fanConfig.StartPwm},
+		{"maxPwm", fanConfig.MaxPwm},
+		{"pwmLimit", fanConfig.PwmLimit},
+	}
+	for _, limit := range limits {
+		if limit.value != nil && (*limit.value < 0 || *limit.value > 255) {
+			return fmt.Errorf("fan %s: invalid %s %d, must be in range [0..255]", fanConfig.ID, limit.name, *limit.value)
+		}
+	}
+
+	if fanConfig.MaxPwm != nil {
+		if fanConfig.MinPwm != nil && *fanConfig.MinPwm > *fanConfig.MaxPwm {
+			return fmt.Errorf("fan %s: minPwm (%d) must not be greater than maxPwm (%d)", fanConfig.ID, *fanConfig.MinPwm, *fanConfig.MaxPwm)
+		}
+		if fanConfig.StartPwm != nil && *fanConfig.StartPwm > *fanConfig.MaxPwm {
+			return fmt.Errorf("fan %s: startPwm (%d) must not be greater than maxPwm (%d)", fanConfig.ID, *fanConfig.StartPwm, *fanConfig.MaxPwm)
+		}
+	}
+
+	return nil
+}
+
+// validateZeroRpmConfig checks the zero rpm mode settings of the given fan
+func validateZeroRpmConfig(fanConfig FanConfig, config *Configuration) error {
+	zeroRpm := fanConfig.ZeroRpm
+	if fanConfig.NeverStop {
+		return fmt.Errorf("fan %s: zeroRpm cannot be used together with neverStop", fanConfig.ID)
+	}
+	if len(zeroRpm.Sensor) <= 0 {
+		return fmt.Errorf("fan %s: zeroRpm: missing sensor", fanConfig.ID)
+	}
+	if !sensorIdExists(zeroRpm.Sensor, config) {
+		return fmt.Errorf("fan %s: zeroRpm: no sensor definition with id '%s' found", fanConfig.ID, zeroRpm.Sensor)
+	}
+	if zeroRpm.StartTemp <= zeroRpm.StopTemp {
+		return fmt.Errorf("fan %s: zeroRpm: startTemp (%v) must be greater than stopTemp (%v)", fanConfig.ID, zeroRpm.StartTemp, zeroRpm.StopTemp)
+	}
+	if zeroRpm.KickPwm != nil && (*zeroRpm.KickPwm <= 0 || *zeroRpm.KickPwm > 255) {
+		return fmt.Errorf("fan %s: zeroRpm: invalid kickPwm %d, must be in range [1..255]", fanConfig.ID, *zeroRpm.KickPwm)
+	}
+	if zeroRpm.KickDuration < 0 {
+		return fmt.Errorf("fan %s: zeroRpm: invalid kickDuration, must be >= 0", fanConfig.ID)
+	}
+	return nil
+}
+
+// validateRpmTargetConfig checks the RPM target settings of the given fan
+func validateRpmTargetConfig(fanConfig FanConfig) error {
+	rpmTarget := fanConfig.RpmTarget
+	if rpmTarget.MaxRpm < 0 {
+		return fmt.Errorf("fan %s: rpmTarget: invalid maxRpm, must be >= 0", fanConfig.ID)
+	}
+	if rpmTarget.Tolerance < 0 {
+		return fmt.Errorf("fan %s: rpmTarget: invalid tolerance, must be >= 0", fanConfig.ID)
+	}
+	return nil
+}
+
+// validateGroupFanConfig checks the members of the given group fan
+func validateGroupFanConfig(fanConfig FanConfig) error {
+	members := fanConfig.Group.Members
+	if len(members) <= 0 {
+		return fmt.Errorf("fan %s: group: no members provided", fanConfig.ID)
+	}
+
+	memberIds := []string{}
+	for _, member := range members {
+		if len(member.ID) <= 0 {
+			return fmt.Errorf("fan %s: group: member id is missing", fanConfig.ID)
+		}
+		if slices.Contains(memberIds, member.ID) {
+			return fmt.Errorf("fan %s: group: duplicate member id detected: %s", fanConfig.ID, member.ID)
+		}
+		memberIds = append(memberIds, member.ID)
+
+		if member.Group != nil {
+			return fmt.Errorf("fan %s: group: member %s can't be a group itself", fanConfig.ID, member.ID)
+		}
+		subConfigs := 0
+		for _, set := range []bool{member.HwMon != nil, member.File != nil, member.Cmd != nil, member.Ipmi != nil, member.Redfish != nil, member.Mqtt != nil, member.DellSmm != nil, member.Liquidctl != nil, member.UsbHid != nil, member.Plugin != nil} {
+			if set {
+				subConfigs++
+			}
+		}
+		if subConfigs != 1 {
+			return fmt.Errorf("fan %s: group: member %s must use exactly one of: hwmon | file | cmd | ipmi | redfish | mqtt | dellSmm | liquidctl | usbHid | plugin", fanConfig.ID, member.ID)
+		}
+
+		if member.HwMon != nil {
+			if err := validateHwMonFanConfig(member.ID, *member.HwMon); err != nil {
+				return err
+			}
+		}
+		if member.DellSmm != nil {
+			if err := validateHwMonFanConfig(member.ID, member.DellSmm.HwMonFanConfig); err != nil {
+				return err
+			}
+		}
+		if member.Plugin != nil {
+			if err := validatePluginConfig(*member.Plugin); err != nil {
+				return fmt.Errorf("fan %s: group: member %s: %v", fanConfig.ID, member.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validatePluginConfig(config PluginConfig) error {
+	if len(config.Name) <= 0 {
+		return fmt.Errorf("plugin: no name provided")
+	}
+	if strings.Contains(config.Name, "/") {
+		return fmt.Errorf("plugin: invalid name '%s', must not contain '/'", config.Name)
+	}
+	if config.Timeout < 0 {
+		return fmt.Errorf("plugin: invalid timeout, must be >= 0")
+	}
+	return nil
+}
+
+func validateHwMonFanConfig(fanId string, config HwMonFanConfig) error {
+	if (config.Index != 0 && config.RpmChannel != 0) || (config.Index == 0 && config.RpmChannel == 0) {
+		return fmt.Errorf("fan %s: must have one of index or rpmChannel, must be >= 1", fanId)
+	}
+	if config.Index < 0 {
+		return fmt.Errorf("fan %s: invalid index, must be >= 1", fanId)
+	}
+	if config.RpmChannel < 0 {
+		return fmt.Errorf("fan %s: invalid rpmChannel, must be >= 1", fanId)
+	}
+	if config.PwmChannel < 0 {
+		return fmt.Errorf("fan %s: invalid pwmChannel, must be >= 1", fanId)
+	}
+	if len(config.PwmMode) > 0 && config.PwmMode != PwmModeDC && config.PwmMode != PwmModePWM {
+		return fmt.Errorf("fan %s: invalid pwmMode '%s', must be one of: %s | %s", fanId, config.PwmMode, PwmModeDC, PwmModePWM)
+	}
+	if err := validateDeviceMatch(config.Platform, config.Name, config.Modalias, config.PciAddress); err != nil {
+		return fmt.Errorf("fan %s: %v", fanId, err)
+	}
+	return nil
+}
+
+// validateDeviceMatch checks the patterns and the PCI address used to select a hwmon device,
+// patterns may be either a glob or a regex
+func validateDeviceMatch(platform string, name string, modalias string, pciAddress string) error {
+	patterns := []struct {
+		field   string
+		pattern string
+	}{
+		{"platform", platform},
+		{"name", name},
+		{"modalias", modalias},
+	}
+	for _, p := range patterns {
+		if _, err := regexp.Compile(p.pattern); err != nil {
+			if _, globErr := path.Match(p.pattern, ""); globErr != nil {
+				return fmt.Errorf("invalid %s pattern, neither a valid glob nor regex: %v", p.field, err)
+			}
+		}
+	}
+	if len(pciAddress) > 0 && !IsValidPciAddress(pciAddress) {
+		return fmt.Errorf("invalid pciAddress '%s', must be in the format pci-0000:2f:00.0", pciAddress)
+	}
+	return nil
+}
+
+func fanIdExists(fanId string, config *Configuration) bool {
+	for _, fan := range config.Fans {
+		if fan.ID == fanId {
+			return true
+		}
+	}
+
+	return false
+}
+
 func curveIdExists(curveId string, config *Configuration) bool {
 	for _, curve := range config.Curves {
 		if curve.ID == curveId {
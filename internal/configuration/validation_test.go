@@ -3,6 +3,7 @@ package configuration
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -74,7 +75,7 @@ func TestValidateFanSubConfigIsMissing(t *testing.T) {
 	err := validateConfig(&config, "")
 
 	// THEN
-	assert.EqualError(t, err, "fan fan: sub-configuration for fan is missing, use one of: hwmon | file | cmd")
+	assert.EqualError(t, err, "fan fan: sub-configuration for fan is missing, use one of: hwmon | file | cmd | ipmi | redfish | mqtt | dellSmm | liquidctl | usbHid | plugin | group")
 }
 
 func TestValidateFanCurveWithIdIsNotDefined(t *testing.T) {
@@ -115,7 +116,7 @@ func TestValidateCurveSubConfigSensorIdIsMissing(t *testing.T) {
 	err := validateConfig(&config, "")
 
 	// THEN
-	assert.EqualError(t, err, "curve curve: sub-configuration for curve is missing, use one of: linear | pid | function")
+	assert.EqualError(t, err, "curve curve: sub-configuration for curve is missing, use one of: linear | pid | function | target | schedule | expression")
 }
 
 func TestValidateCurveSensorIdIsMissing(t *testing.T) {
@@ -162,6 +163,27 @@ func TestValidateCurveSensorWithIdIsNotDefined(t *testing.T) {
 	assert.EqualError(t, err, "curve curve: no sensor definition with id 'sensor' found")
 }
 
+func TestValidateFunctionCurveWithoutCurves(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Curves: []CurveConfig{
+			{
+				ID: "curve",
+				Function: &FunctionCurveConfig{
+					Type:   FunctionMaximum,
+					Curves: []string{},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: no curves provided")
+}
+
 func TestValidateCurveDependencyToSelf(t *testing.T) {
 	// GIVEN
 	config := Configuration{
@@ -369,7 +391,7 @@ func TestValidateSensorSubConfigSensorIdIsMissing(t *testing.T) {
 	err := validateConfig(&config, "")
 
 	// THEN
-	assert.EqualError(t, err, "sensor sensor: sub-configuration for sensor is missing, use one of: hwmon | file | cmd")
+	assert.EqualError(t, err, "sensor sensor: sub-configuration for sensor is missing, use one of: hwmon | file | cmd | nvme | nvidia | amdgpu | http | snmp | redfish | remote | smart | thermal | rapl | liquidctl | usbHid | virtual | plugin | expression")
 }
 
 func TestValidateSensor(t *testing.T) {
@@ -392,6 +414,90 @@ func TestValidateSensor(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestValidateSensorNegativePollingRate(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				File: &FileSensorConfig{
+					Path: "",
+				},
+				PollingRate: -1 * time.Second,
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: invalid pollingRate, must be > 0")
+}
+
+func TestValidateSensorSmoothingNegativeWindow(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				File: &FileSensorConfig{
+					Path: "",
+				},
+				Smoothing: &SmoothingConfig{Window: -1 * time.Second},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: smoothing: invalid window, must be > 0")
+}
+
+func TestValidateSensorSmoothingEwmaInvalidAlpha(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				File: &FileSensorConfig{
+					Path: "",
+				},
+				Smoothing: &SmoothingConfig{Type: SmoothingTypeEwma, Alpha: 1.5},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: smoothing: invalid alpha 1.5, must be in range (0..1]")
+}
+
+func TestValidateSensorNegativeFactor(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				File: &FileSensorConfig{
+					Path: "",
+				},
+				Factor: -1,
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: invalid factor, must be > 0")
+}
+
 func TestValidateDuplicateSensorId(t *testing.T) {
 	// GIVEN
 	sensorId := "sensor"
@@ -419,6 +525,232 @@ func TestValidateDuplicateSensorId(t *testing.T) {
 	assert.EqualError(t, err, fmt.Sprintf("duplicate sensor id detected: %s", sensorId))
 }
 
+func TestValidateVirtualSensorReferencesMissingSensor(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "virtual",
+				Virtual: &VirtualSensorConfig{
+					Function: AggregationMax,
+					Sensors:  []string{"missing"},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor virtual: no sensor definition with id 'missing' found")
+}
+
+func TestValidateVirtualSensorWeights(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "sensor",
+				File: &FileSensorConfig{},
+			},
+			{
+				ID: "virtual",
+				Virtual: &VirtualSensorConfig{
+					Function: AggregationWeighted,
+					Sensors:  []string{"sensor"},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor virtual: number of weights must match the number of sensors")
+}
+
+func TestValidateVirtualSensorDifferenceRequiresTwoSensors(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "sensor",
+				File: &FileSensorConfig{},
+			},
+			{
+				ID: "virtual",
+				Virtual: &VirtualSensorConfig{
+					Function: AggregationDifference,
+					Sensors:  []string{"sensor"},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor virtual: difference requires exactly two sensors")
+}
+
+func TestValidateVirtualSensorDependencyCycle(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "virtual1",
+				Virtual: &VirtualSensorConfig{
+					Function: AggregationMax,
+					Sensors:  []string{"virtual2"},
+				},
+			},
+			{
+				ID: "virtual2",
+				Virtual: &VirtualSensorConfig{
+					Function: AggregationMax,
+					Sensors:  []string{"virtual1"},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "you have created a sensor dependency cycle")
+}
+
+func TestValidateExpressionSensorReferencesMissingSensor(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "sensor",
+				File: &FileSensorConfig{},
+			},
+			{
+				ID: "expression",
+				Expression: &ExpressionSensorConfig{
+					Formula: "(sensor + missing) / 2",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor expression: no sensor definition with id 'missing' found")
+}
+
+func TestValidateExpressionSensorInvalidFormula(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "expression",
+				Expression: &ExpressionSensorConfig{
+					Formula: "max(",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor expression: invalid formula: unexpected end of expression")
+}
+
+func TestValidateExpressionSensorDependencyCycle(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "expression",
+				Expression: &ExpressionSensorConfig{
+					Formula: "virtual * 2",
+				},
+			},
+			{
+				ID: "virtual",
+				Virtual: &VirtualSensorConfig{
+					Function: AggregationMax,
+					Sensors:  []string{"expression"},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "you have created a sensor dependency cycle")
+}
+
+func TestValidateFanNegativeUpdateRate(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].UpdateRate = -1 * time.Second
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: invalid updateRate, must be > 0")
+}
+
+func TestValidateFanCurveOffsetOutOfRange(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].CurveOffset = 300
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: invalid curveOffset 300, must be in range [-255..255]")
+}
+
+func TestValidateFanGroupNestedGroup(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].File = nil
+	config.Fans[0].Group = &GroupFanConfig{
+		Members: []FanConfig{
+			{ID: "member", Group: &GroupFanConfig{}},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: group: member member can't be a group itself")
+}
+
+func TestValidateFanNegativePwmEnableValue(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	manual := -1
+	config.Fans[0].PwmEnable = &PwmEnableConfig{Manual: &manual}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: pwmEnable: invalid manual value -1, must be >= 0")
+}
+
 func TestValidateFanHasIndexOrChannel(t *testing.T) {
 	// GIVEN
 	config := Configuration{
@@ -577,3 +909,1172 @@ func TestValidateFanPwmChannel(t *testing.T) {
 	// THEN
 	assert.EqualError(t, err, "fan fan: invalid pwmChannel, must be >= 1")
 }
+
+func TestValidateNvmeSensorHasDeviceOrSerial(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "sensor",
+				Nvme: &NvmeSensorConfig{},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: must have one of device or serial")
+}
+
+func TestValidateNvidiaSensorTypeUnsupported(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				Nvidia: &NvidiaSensorConfig{
+					Type: "hotspot",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: unsupported type 'hotspot', use one of: core | memory | utilization")
+}
+
+func TestValidateAmdGpuSensorTempUnsupported(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				AmdGpu: &AmdGpuSensorConfig{
+					Temps: []string{"edge", "hotspot"},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: unsupported temp 'hotspot', use any of: edge | junction | mem")
+}
+
+func TestValidateAmdGpuSensorTypeUnsupported(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				AmdGpu: &AmdGpuSensorConfig{
+					Type: "power",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: unsupported type 'power', use one of: temperature | utilization")
+}
+
+func TestValidateIpmiFanGenericProfileNeedsRawCommand(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				File: &FileSensorConfig{
+					Path: "abc",
+				},
+			},
+		},
+		Curves: []CurveConfig{
+			{
+				ID: "curve",
+				Linear: &LinearCurveConfig{
+					Sensor: "sensor",
+					Min:    0,
+					Max:    100,
+				},
+			},
+		},
+		Fans: []FanConfig{
+			{
+				ID:    "fan",
+				Curve: "curve",
+				Ipmi: &IpmiFanConfig{
+					Profile: IpmiProfileGeneric,
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: the generic ipmi profile requires a raw setPwm command")
+}
+
+func TestValidateCmdSensorExecIsMissing(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:  "sensor",
+				Cmd: &CmdSensorConfig{},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: executable is missing")
+}
+
+func TestValidateCmdFanGetRpmExecIsMissing(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				File: &FileSensorConfig{
+					Path: "abc",
+				},
+			},
+		},
+		Curves: []CurveConfig{
+			{
+				ID: "curve",
+				Linear: &LinearCurveConfig{
+					Sensor: "sensor",
+					Min:    0,
+					Max:    100,
+				},
+			},
+		},
+		Fans: []FanConfig{
+			{
+				ID:    "fan",
+				Curve: "curve",
+				Cmd: &CmdFanConfig{
+					SetPwm: &ExecConfig{Exec: "/usr/bin/true"},
+					GetRpm: &ExecConfig{},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: getRpm executable is missing")
+}
+
+func TestValidateHttpSensorUrlIsMissing(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID:   "sensor",
+				Http: &HttpSensorConfig{},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: no url provided")
+}
+
+func TestValidateMqttFanPwmTopicIsMissing(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				File: &FileSensorConfig{
+					Path: "abc",
+				},
+			},
+		},
+		Curves: []CurveConfig{
+			{
+				ID: "curve",
+				Linear: &LinearCurveConfig{
+					Sensor: "sensor",
+					Min:    0,
+					Max:    100,
+				},
+			},
+		},
+		Fans: []FanConfig{
+			{
+				ID:    "fan",
+				Curve: "curve",
+				Mqtt: &MqttFanConfig{
+					Broker: "tcp://localhost:1883",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: no pwmTopic provided")
+}
+
+func TestValidateSnmpSensorVersionUnsupported(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				Snmp: &SnmpSensorConfig{
+					Host:    "ups.local",
+					Oid:     ".1.3.6.1.2.1.33.1.2.7.0",
+					Version: "4",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: unsupported snmp version '4', use one of: 1 | 2c | 3")
+}
+
+func TestValidateSmartSensorHasDeviceOrSerial(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				Smart: &SmartSensorConfig{
+					Device: "/dev/sda",
+					Serial: "WD-123456",
+				},
+			},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: must have one of device or serial")
+}
+
+func createProfileTestConfig(profiles []ProfileConfig) Configuration {
+	return Configuration{
+		Curves: []CurveConfig{
+			{
+				ID: "curve",
+				Linear: &LinearCurveConfig{
+					Sensor: "sensor",
+					Min:    40,
+					Max:    80,
+				},
+			},
+		},
+		Sensors: []SensorConfig{
+			{
+				ID: "sensor",
+				File: &FileSensorConfig{
+					Path: "/tmp/temp",
+				},
+			},
+		},
+		Fans: []FanConfig{
+			{
+				ID:    "fan",
+				Curve: "curve",
+				File: &FileFanConfig{
+					Path: "/tmp/pwm",
+				},
+			},
+		},
+		Profiles: profiles,
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	// GIVEN
+	maxPwm := 100
+	config := createProfileTestConfig([]ProfileConfig{
+		{
+			ID: "silent",
+			Fans: []ProfileFanConfig{
+				{Fan: "fan", Curve: "curve", MaxPwm: &maxPwm},
+			},
+		},
+	})
+	config.Profile = "silent"
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func TestValidateProfileFanDoesNotExist(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig([]ProfileConfig{
+		{
+			ID: "silent",
+			Fans: []ProfileFanConfig{
+				{Fan: "unknown", Curve: "curve"},
+			},
+		},
+	})
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "profile silent: no fan definition with id 'unknown' found")
+}
+
+func TestValidateProfileCurveDoesNotExist(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig([]ProfileConfig{
+		{
+			ID: "silent",
+			Fans: []ProfileFanConfig{
+				{Fan: "fan", Curve: "unknown"},
+			},
+		},
+	})
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "profile silent: no curve definition with id 'unknown' found")
+}
+
+func TestValidateProfileMaxPwmOutOfRange(t *testing.T) {
+	// GIVEN
+	maxPwm := 300
+	config := createProfileTestConfig([]ProfileConfig{
+		{
+			ID: "silent",
+			Fans: []ProfileFanConfig{
+				{Fan: "fan", MaxPwm: &maxPwm},
+			},
+		},
+	})
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "profile silent: invalid maxPwm for fan fan, must be in range [0..255]")
+}
+
+func TestValidateDefaultProfileDoesNotExist(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig([]ProfileConfig{
+		{ID: "silent"},
+	})
+	config.Profile = "performance"
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "no profile definition with id 'performance' found")
+}
+
+func TestValidateFanPwmLimitOutOfRange(t *testing.T) {
+	// GIVEN
+	startPwm := 256
+	config := createProfileTestConfig(nil)
+	config.Fans[0].StartPwm = &startPwm
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: invalid startPwm 256, must be in range [0..255]")
+}
+
+func TestValidateFanMinPwmGreaterThanMaxPwm(t *testing.T) {
+	// GIVEN
+	minPwm := 100
+	maxPwm := 50
+	config := createProfileTestConfig(nil)
+	config.Fans[0].MinPwm = &minPwm
+	config.Fans[0].MaxPwm = &maxPwm
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: minPwm (100) must not be greater than maxPwm (50)")
+}
+
+func TestValidateFanZeroRpmThresholds(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].ZeroRpm = &ZeroRpmConfig{
+		Sensor:    "sensor",
+		StopTemp:  50,
+		StartTemp: 40,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: zeroRpm: startTemp (40) must be greater than stopTemp (50)")
+}
+
+func TestValidateFanZeroRpmWithNeverStop(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].NeverStop = true
+	config.Fans[0].ZeroRpm = &ZeroRpmConfig{
+		Sensor:    "sensor",
+		StopTemp:  40,
+		StartTemp: 50,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: zeroRpm cannot be used together with neverStop")
+}
+
+func TestValidateFanRpmTargetNegativeMaxRpm(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].RpmTarget = &RpmTargetConfig{
+		MaxRpm: -1,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: rpmTarget: invalid maxRpm, must be >= 0")
+}
+
+func TestValidateLoggingOutputUnknown(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Logging.Output = "stdout"
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "logging: invalid output 'stdout', must be one of: console | journald | syslog")
+}
+
+func TestValidateLoggingDebugSubsystemUnknown(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Logging.Debug = []string{"controller", "gpu"}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "logging: unknown debug subsystem 'gpu', must be any of: controller | hwmon | persistence")
+}
+
+func TestValidateCalibrationFileWithoutNoDb(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.CalibrationFile = "/etc/fan2go/calibration.json"
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "calibrationFile can only be used together with noDb")
+}
+
+func TestValidateHistoryRetentionShorterThanInterval(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.History = HistoryConfig{
+		Enabled:   true,
+		Interval:  10 * time.Second,
+		Retention: 5 * time.Second,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "history: invalid retention, must be >= interval")
+}
+
+func TestValidateAdaptivePollingRapidDeltaBelowStableDelta(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.AdaptivePolling = AdaptivePollingConfig{
+		Enabled:     true,
+		MaxInterval: 2 * time.Second,
+		RampUp:      1 * time.Minute,
+		StableDelta: 1,
+		RapidDelta:  0.5,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "adaptivePolling: invalid rapidDelta, must be >= stableDelta")
+}
+
+func TestValidateInvalidHwMonBackend(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.HwMonBackend = "procfs"
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "invalid hwMonBackend 'procfs', must be one of: libsensors | sysfs")
+}
+
+func TestValidateInfluxDbMissingBucket(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Statistics.InfluxDb = InfluxDbConfig{
+		Enabled:  true,
+		Url:      "http://localhost:8086",
+		Interval: 10 * time.Second,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "statistics: influxDb: missing bucket")
+}
+
+func TestValidateStatsdInvalidAddress(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Statistics.Statsd = PushConfig{
+		Enabled:  true,
+		Address:  "localhost",
+		Interval: 10 * time.Second,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "statistics: statsd: invalid address 'localhost', must be host:port")
+}
+
+func TestValidateHwMonInvalidModaliasPattern(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].File = nil
+	config.Fans[0].HwMon = &HwMonFanConfig{
+		Modalias: "pci:[",
+		Index:    1,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.ErrorContains(t, err, "fan fan: invalid modalias pattern")
+}
+
+func TestValidateHwMonSensorInvalidNamePattern(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Sensors[0].File = nil
+	config.Sensors[0].HwMon = &HwMonSensorConfig{
+		Name:  "nct[",
+		Index: 1,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.ErrorContains(t, err, "sensor sensor: invalid name pattern")
+}
+
+func TestValidateHwMonInvalidPciAddress(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].File = nil
+	config.Fans[0].HwMon = &HwMonFanConfig{
+		PciAddress: "pci-2f:00.0",
+		Index:      1,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.ErrorContains(t, err, "fan fan: invalid pciAddress")
+}
+
+func TestValidateHwMonGlobPlatform(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].File = nil
+	config.Fans[0].HwMon = &HwMonFanConfig{
+		Platform: "*-isa-*",
+		Index:    1,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func TestValidatePluginInvalidName(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].File = nil
+	config.Fans[0].Plugin = &PluginConfig{
+		Name: "../corsair",
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: plugin: invalid name '../corsair', must not contain '/'")
+}
+
+func TestValidatePluginMissingName(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Sensors[0].File = nil
+	config.Sensors[0].Plugin = &PluginConfig{}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: plugin: no name provided")
+}
+
+func TestValidateCurveExpressionInvalidFormula(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves[0].Linear = nil
+	config.Curves[0].Expression = &ExpressionCurveConfig{
+		Formula: "sensor >",
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: invalid formula: unexpected end of expression")
+}
+
+func TestValidateCurveExpressionUnknownSensor(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves[0].Linear = nil
+	config.Curves[0].Expression = &ExpressionCurveConfig{
+		Formula: "max(sensor, gpu)",
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: no sensor definition with id 'gpu' found")
+}
+
+func TestValidateCurveWeightedSensors(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves[0].Linear.Sensor = ""
+	config.Curves[0].Linear.Sensors = []WeightedSensorConfig{
+		{ID: "sensor", Weight: 0.7},
+		{ID: "sensor", Weight: 0.3},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func TestValidateCurveSensorAndWeightedSensors(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves[0].Linear.Sensors = []WeightedSensorConfig{
+		{ID: "sensor", Weight: 1},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: only one of sensor or sensors can be used")
+}
+
+func TestValidateCurveWeightedSensorUnknown(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves[0].Linear.Sensor = ""
+	config.Curves[0].Linear.Sensors = []WeightedSensorConfig{
+		{ID: "sensor", Weight: 0.7},
+		{ID: "vrm", Weight: 0.3},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: no sensor definition with id 'vrm' found")
+}
+
+func TestValidateCurveWeightedSensorMissingWeight(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves[0].Linear = nil
+	config.Curves[0].PID = &PidCurveConfig{
+		Sensors:  []WeightedSensorConfig{{ID: "sensor"}},
+		SetPoint: 60,
+		P:        -0.05,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: missing weight of sensor 'sensor'")
+}
+
+func TestValidateCurveChained(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves = append(config.Curves, CurveConfig{
+		ID: "psu_curve",
+		Linear: &LinearCurveConfig{
+			Curve: "curve",
+			Min:   0,
+			Max:   255,
+		},
+	})
+	config.Fans[0].Curve = "psu_curve"
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func TestValidateCurveChainedToSelf(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves[0].Linear.Sensor = ""
+	config.Curves[0].Linear.Curve = "curve"
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: a curve cannot reference itself")
+}
+
+func TestValidateCurveChainedWithSensor(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves = append(config.Curves, CurveConfig{
+		ID: "psu_curve",
+		Linear: &LinearCurveConfig{
+			Sensor: "sensor",
+			Curve:  "curve",
+		},
+	})
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "curve psu_curve: only one of sensor, sensors or curve can be used")
+}
+
+func TestValidateCurveChainedCurveNotDefined(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves[0].Linear.Sensor = ""
+	config.Curves[0].Linear.Curve = "cpu_curve"
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: no curve definition with id 'cpu_curve' found")
+}
+
+func TestValidateCurveChainedCycle(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves[0].Linear.Sensor = ""
+	config.Curves[0].Linear.Curve = "psu_curve"
+	config.Curves = append(config.Curves, CurveConfig{
+		ID: "psu_curve",
+		Linear: &LinearCurveConfig{
+			Curve: "curve",
+		},
+	})
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.ErrorContains(t, err, "you have created a curve dependency cycle")
+}
+
+func TestValidateNightMode(t *testing.T) {
+	// GIVEN
+	maxPwm := 150
+	config := createProfileTestConfig(nil)
+	config.NightMode = NightModeConfig{
+		Enabled: true,
+		From:    "22:00",
+		To:      "07:00",
+		MaxPwm:  &maxPwm,
+		Fans: []NightModeFanConfig{
+			{Fan: "fan", MaxPwm: 100},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func TestValidateNightModeInvalidTime(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.NightMode = NightModeConfig{
+		Enabled: true,
+		From:    "22",
+		To:      "07:00",
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "nightMode: invalid time of day '22', expected HH:MM")
+}
+
+func TestValidateNightModeFanNotDefined(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.NightMode = NightModeConfig{
+		Enabled: true,
+		From:    "22:00",
+		To:      "07:00",
+		Fans: []NightModeFanConfig{
+			{Fan: "gpu", MaxPwm: 100},
+		},
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "nightMode: no fan definition with id 'gpu' found")
+}
+
+func TestValidateNightModeInvalidMaxPwm(t *testing.T) {
+	// GIVEN
+	maxPwm := 300
+	config := createProfileTestConfig(nil)
+	config.NightMode = NightModeConfig{
+		Enabled: true,
+		From:    "22:00",
+		To:      "07:00",
+		MaxPwm:  &maxPwm,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "nightMode: invalid maxPwm 300, must be in range [0..255]")
+}
+
+func TestValidatePwmLimitOutOfRange(t *testing.T) {
+	// GIVEN
+	pwmLimit := 256
+	config := createProfileTestConfig(nil)
+	config.PwmLimit = &pwmLimit
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "invalid pwmLimit 256, must be in range [0..255]")
+}
+
+func TestValidateFanPwmLimitOverrideOutOfRange(t *testing.T) {
+	// GIVEN
+	pwmLimit := -1
+	config := createProfileTestConfig(nil)
+	config.Fans[0].PwmLimit = &pwmLimit
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: invalid pwmLimit -1, must be in range [0..255]")
+}
+
+func TestValidateFanMinRpmNegative(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].MinRpm = -100
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: invalid minRpm, must be >= 0")
+}
+
+func TestValidateStuckPwmDetectionInvalidCycles(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.StuckPwmDetection = StuckPwmDetectionConfig{
+		Enabled: true,
+		Cycles:  0,
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "stuckPwmDetection: invalid cycles, must be >= 1")
+}
+
+func TestValidateRedfishSensorInsecureUrl(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Sensors[0].File = nil
+	config.Sensors[0].Redfish = &RedfishSensorConfig{
+		RedfishConfig: RedfishConfig{Url: "http://192.168.1.10"},
+		Sensor:        "CPU1 Temp",
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: invalid redfish url 'http://192.168.1.10', must start with https://")
+}
+
+func TestValidateRedfishFanMissingControl(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].File = nil
+	config.Fans[0].Redfish = &RedfishFanConfig{
+		RedfishConfig: RedfishConfig{Url: "https://192.168.1.10"},
+		Fan:           "FAN1",
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: no redfish control provided")
+}
+
+func TestValidateFanInvalidPwmMode(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Fans[0].File = nil
+	config.Fans[0].HwMon = &HwMonFanConfig{
+		Platform: "nct6798",
+		Index:    1,
+		PwmMode:  "voltage",
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "fan fan: invalid pwmMode 'voltage', must be one of: dc | pwm")
+}
+
+func TestValidateUsbHidSensorUnsupportedModel(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Sensors[0].File = nil
+	config.Sensors[0].UsbHid = &UsbHidSensorConfig{
+		Model:  UsbHidModelCorsairCommanderPro,
+		Sensor: "temp1",
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: unsupported usb hid model 'corsair-commander-pro', use one of: aquacomputer-d5next | aquacomputer-octo | aquacomputer-quadro")
+}
+
+func TestValidateRemoteSensorMissingAddress(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Sensors[0].File = nil
+	config.Sensors[0].Remote = &RemoteSensorConfig{
+		Sensor: "cpu",
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: no remote address provided")
+}
+
+func TestValidateRemoteSensorTokenWithoutTls(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Sensors[0].File = nil
+	config.Sensors[0].Remote = &RemoteSensorConfig{
+		Address: "node1:9002",
+		Sensor:  "cpu",
+		Token:   "secret",
+	}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "sensor sensor: a remote token requires tls, since it would be sent in clear text otherwise")
+}
+
+func TestValidateGrpcTokenWithoutTls(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Grpc = GrpcConfig{Token: "secret"}
+
+	// WHEN
+	err := validateAgentConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "grpc: token requires tls, since it would be sent in clear text otherwise")
+}
+
+func TestValidateGrpcTlsMissingKeyFile(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Grpc = GrpcConfig{Tls: &GrpcTlsConfig{CertFile: "/etc/fan2go/fan2go.crt"}}
+
+	// WHEN
+	err := validateConfig(&config, "")
+
+	// THEN
+	assert.EqualError(t, err, "grpc: tls: certFile and keyFile are required")
+}
+
+func TestValidateAgentIgnoresFans(t *testing.T) {
+	// GIVEN
+	config := createProfileTestConfig(nil)
+	config.Curves = nil
+	config.Fans = nil
+
+	// WHEN
+	err := validateAgentConfig(&config, "")
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func TestContainsCmdFanInGroup(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Fans: []FanConfig{
+			{
+				ID: "group",
+				Group: &GroupFanConfig{
+					Members: []FanConfig{
+						{
+							ID:   "file",
+							File: &FileFanConfig{Path: "abc"},
+						},
+						{
+							ID:  "cmd",
+							Cmd: &CmdFanConfig{SetPwm: &ExecConfig{Exec: "/usr/bin/true"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	result := containsCmdFan(&config)
+
+	// THEN
+	assert.True(t, result)
+}
+
+func TestContainsCmdFanWithoutCmd(t *testing.T) {
+	// GIVEN
+	config := Configuration{
+		Fans: []FanConfig{
+			{
+				ID: "group",
+				Group: &GroupFanConfig{
+					Members: []FanConfig{
+						{
+							ID:   "file",
+							File: &FileFanConfig{Path: "abc"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// WHEN
+	result := containsCmdFan(&config)
+
+	// THEN
+	assert.False(t, result)
+}
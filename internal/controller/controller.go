@@ -2,16 +2,25 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"os"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/markusressel/fan2go/internal/alerting"
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/emergency"
 	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/nightmode"
 	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/polling"
+	"github.com/markusressel/fan2go/internal/profiles"
+	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/markusressel/fan2go/internal/util"
 	"github.com/oklog/run"
@@ -20,12 +29,88 @@ import (
 // Amount of time to wait between a set-pwm and get-pwm. Used during fan initial calibration.
 const pwmSetGetDelay time.Duration = 5 * time.Millisecond
 
+// Interval at which the rpm of a fan is measured while waiting for it to settle, a variable to allow shorter waits in tests
+var fanSettleInterval = 1 * time.Second
+
+// Amount of time the kick pwm is applied for when a fan in zero rpm mode is started again
+const defaultZeroRpmKickDuration = 2 * time.Second
+
+// Tolerance (in RPM) of fans with an RPM target, if not configured
+const defaultRpmTargetTolerance = 50
+
+// Share of the RPM difference of a fan with an RPM target which is corrected per update
+const rpmTargetCorrectionGain = 0.1
+
+// Maximum correction (in pwm) of the measured pwm of a target RPM
+const maxRpmTargetCorrection = 64
+
+// Share of the minRpm of a fan the measured RPM has to exceed it by, before the pwm floor is lowered again
+const minRpmHysteresis = 0.1
+
+// logger is used for all messages of fan controllers
+var logger = ui.ForSubsystem(ui.SubsystemController)
+
 var InitializationSequenceMutex sync.Mutex
 
+var (
+	// fanControllerMap maps from fan id -> controller of the fan. It is read concurrently,
+	// so it must only be accessed using the functions below.
+	fanControllerMap     = map[string]FanController{}
+	fanControllerMapLock sync.RWMutex
+)
+
+// GetFanControllerMap returns all fan controllers by id, the result must not be modified
+func GetFanControllerMap() map[string]FanController {
+	fanControllerMapLock.RLock()
+	defer fanControllerMapLock.RUnlock()
+	return fanControllerMap
+}
+
+// GetFanController returns the fan controller with the given id
+func GetFanController(id string) (FanController, bool) {
+	fanControllerMapLock.RLock()
+	defer fanControllerMapLock.RUnlock()
+	value, ok := fanControllerMap[id]
+	return value, ok
+}
+
+// SetFanControllerMap replaces all fan controllers, the given map must not be modified afterwards
+func SetFanControllerMap(values map[string]FanController) {
+	fanControllerMapLock.Lock()
+	defer fanControllerMapLock.Unlock()
+	fanControllerMap = values
+}
+
+// SetFanController adds or replaces the fan controller with the given id
+func SetFanController(id string, value FanController) {
+	fanControllerMapLock.Lock()
+	defer fanControllerMapLock.Unlock()
+	values := make(map[string]FanController, len(fanControllerMap)+1)
+	for key, v := range fanControllerMap {
+		values[key] = v
+	}
+	values[id] = value
+	fanControllerMap = values
+}
+
+// RemoveFanController removes the fan controller with the given id
+func RemoveFanController(id string) {
+	fanControllerMapLock.Lock()
+	defer fanControllerMapLock.Unlock()
+	values := make(map[string]FanController, len(fanControllerMap))
+	for key, v := range fanControllerMap {
+		if key != id {
+			values[key] = v
+		}
+	}
+	fanControllerMap = values
+}
+
 type FanControllerStatistics struct {
-	UnexpectedPwmValueCount int
-	IncreasedMinPwmCount    int
-	MinPwmOffset            int
+	UnexpectedPwmValueCount int `json:"unexpectedPwmValueCount"`
+	IncreasedMinPwmCount    int `json:"increasedMinPwmCount"`
+	MinPwmOffset            int `json:"minPwmOffset"`
+	StallCount              int `json:"stallCount"`
 }
 
 type FanController interface {
@@ -40,6 +125,75 @@ type FanController interface {
 	RunInitializationSequence() (err error)
 
 	UpdateFanSpeed() error
+
+	// SetOverride overrides the curve of the fan with a fixed pwm value ([0..255]) for the given
+	// duration, a duration of 0 overrides it until ClearOverride is called
+	SetOverride(pwm int, duration time.Duration)
+	// GetOverride returns the currently active override, if any
+	GetOverride() *PwmOverride
+	// ClearOverride removes the current override, if any
+	ClearOverride()
+
+	// Reinitialize applies the control mode and the last pwm value to the fan again on the next
+	// update, f.ex. after a resume from suspend, since the firmware may have reset them
+	Reinitialize()
+
+	// GetState returns what determined the pwm value of the last update
+	GetState() ControllerState
+
+	// GetTargetPwm returns the pwm value set by the last update, before applying the pwmMap to it,
+	// nil if no pwm value was set yet
+	GetTargetPwm() *int
+
+	// GetFanSnapshot returns the values of the fan as of the last update and rpm measurement,
+	// without accessing the fan, which is only accessed by the control loop
+	GetFanSnapshot() FanSnapshot
+}
+
+// FanSnapshot contains the values of a fan cached by its controller
+type FanSnapshot struct {
+	// Pwm is the pwm value of the fan after the last update, nil if it wasn't read yet
+	Pwm *int
+	// PwmErr is the error of the last pwm read, nil if it succeeded
+	PwmErr error
+	// Rpm is the last measured rpm value, nil if it wasn't measured yet
+	Rpm    *int
+	RpmAvg float64
+
+	MinPwm   int
+	StartPwm int
+	MaxPwm   int
+	// CurvePoints is the number of measured pwm -> rpm points, 0 if the fan wasn't measured
+	CurvePoints int
+	// MaxRpm is the highest measured rpm
+	MaxRpm float64
+}
+
+// ControllerState describes what determined the pwm value of a fan
+type ControllerState string
+
+const (
+	// ControllerStateCurve indicates that the pwm follows the curve of the fan
+	ControllerStateCurve ControllerState = "curve"
+	// ControllerStateOverride indicates that the curve is overridden with a fixed pwm value
+	ControllerStateOverride ControllerState = "override"
+	// ControllerStateMaxFans indicates that all fans run at full speed due to a critical temperature
+	ControllerStateMaxFans ControllerState = "maxFans"
+	// ControllerStateStallKick indicates that a stalled fan is kicked by the stall detection
+	ControllerStateStallKick ControllerState = "stallKick"
+	// ControllerStateFailsafe indicates that a sensor of the curve is unreadable
+	ControllerStateFailsafe ControllerState = "failsafe"
+	// ControllerStateZeroRpm indicates that the fan is stopped or restarted by its zero rpm mode
+	ControllerStateZeroRpm ControllerState = "zeroRpm"
+	// ControllerStateStopped indicates that the control loop has stopped, f.ex. due to an error
+	ControllerStateStopped ControllerState = "stopped"
+)
+
+// PwmOverride is a fixed pwm value that is used instead of the curve value of a fan
+type PwmOverride struct {
+	Pwm int `json:"pwm"`
+	// Until is the time the override expires at, zero if it never expires
+	Until time.Time `json:"until,omitempty"`
 }
 
 type PidFanController struct {
@@ -49,14 +203,14 @@ type PidFanController struct {
 	persistence persistence.Persistence
 	// the fan to control
 	fan fans.Fan
-	// the curve used to control the fan
-	curve curves.SpeedCurve
 	// rate to update the target fan speed
 	updateRate time.Duration
 	// the original pwm_enabled flag state of the fan before starting the controller
 	originalPwmEnabled fans.ControlMode
 	// the original pwm value of the fan before starting the controller
 	originalPwmValue int
+	// the original pwmN_mode value of a hwmon fan with a configured pwm mode, nil if it isn't changed
+	originalPwmMode *int
 	// the last pwm value that was set to the fan, **before** applying the pwmMap to it
 	lastSetPwm *int
 	// a list of all pre-pwmMap pwm values where setPwm(x) != setPwm(y) for the controlled fan
@@ -68,6 +222,63 @@ type PidFanController struct {
 
 	// offset applied to the actual minPwm of the fan to ensure "neverStops" constraint
 	minPwmOffset int
+
+	// startPwm and minPwm measured during the initialization sequence, nil if they weren't measured
+	pwmBoundaries *persistence.FanPwmBoundaries
+
+	// override of the curve value, if any
+	override     *PwmOverride
+	overrideLock sync.Mutex
+
+	// whether the fan is currently stopped by its zero rpm mode
+	zeroRpmStopped bool
+	// time until which the kick pwm is applied after a zero rpm stop
+	zeroRpmKickUntil time.Time
+
+	// whether the fan is in failsafe mode, since a sensor of its curve is unreadable
+	failsafe bool
+	// whether an error evaluating the curve of the fan was logged already
+	curveErrorLogged bool
+
+	// correction (in pwm) of the measured pwm of the target RPM, based on the difference
+	// between the target and the measured RPM
+	rpmTargetCorrection float64
+	// whether the fan can't reach an RPM target was logged already
+	rpmTargetWarned bool
+
+	// lowest pwm value keeping the fan at its minRpm, nil until it is first needed
+	minRpmPwm *int
+	// whether the fan can't reach its minRpm was logged already
+	minRpmWarned bool
+	// whether the fan lacks the RPM sensor or measured curve minRpm requires was logged already
+	minRpmUnsupportedWarned bool
+
+	// number of consecutive updates in which the pwm read back from the fan differed from the written value
+	stuckPwmCycles int
+	// the pwm value read back in the first of these updates
+	stuckPwmValue int
+	// whether the read back pwm value changed during these updates
+	stuckPwmChanging bool
+
+	// number of consecutive rpm measurements that reported a stalled fan
+	stallCycles int
+	// time until which the stall detection kick pwm is applied
+	stallKickUntil time.Time
+	stallLock      sync.Mutex
+
+	// set to 1 if the fan should be re-initialized on the next update
+	reinitializeRequested int32
+
+	// what determined the pwm value of the last update
+	state ControllerState
+	// copy of lastSetPwm, which can be read while the control loop is running
+	targetPwm *int
+	// values of the fan, which can be read while the control loop is running
+	fanSnapshot FanSnapshot
+	stateLock   sync.Mutex
+
+	// the last pwm value that was logged instead of being set in dry-run mode
+	dryRunPwm *int
 }
 
 func NewFanController(
@@ -79,7 +290,6 @@ func NewFanController(
 	return &PidFanController{
 		persistence:                 persistence,
 		fan:                         fan,
-		curve:                       curves.SpeedCurveMap[fan.GetCurveId()],
 		updateRate:                  updateRate,
 		pwmValuesWithDistinctTarget: []int{},
 		pwmMap:                      map[int]int{},
@@ -93,20 +303,136 @@ func (f *PidFanController) GetFanId() string {
 }
 
 func (f *PidFanController) GetStatistics() FanControllerStatistics {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
 	return f.stats
 }
 
+func (f *PidFanController) SetOverride(pwm int, duration time.Duration) {
+	f.overrideLock.Lock()
+	defer f.overrideLock.Unlock()
+	override := &PwmOverride{
+		Pwm: int(util.Coerce(float64(pwm), fans.MinPwmValue, fans.MaxPwmValue)),
+	}
+	if duration > 0 {
+		override.Until = time.Now().Add(duration)
+	}
+	f.override = override
+}
+
+func (f *PidFanController) GetOverride() *PwmOverride {
+	f.overrideLock.Lock()
+	defer f.overrideLock.Unlock()
+	if f.override != nil && !f.override.Until.IsZero() && time.Now().After(f.override.Until) {
+		logger.WithFan(f.fan.GetId()).Info("Override of fan %s expired", f.fan.GetId())
+		f.override = nil
+	}
+	if f.override == nil {
+		return nil
+	}
+	override := *f.override
+	return &override
+}
+
+func (f *PidFanController) ClearOverride() {
+	f.overrideLock.Lock()
+	defer f.overrideLock.Unlock()
+	f.override = nil
+}
+
+func (f *PidFanController) Reinitialize() {
+	atomic.StoreInt32(&f.reinitializeRequested, 1)
+}
+
+func (f *PidFanController) GetState() ControllerState {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+	return f.state
+}
+
+func (f *PidFanController) GetTargetPwm() *int {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+	if f.targetPwm == nil {
+		return nil
+	}
+	target := *f.targetPwm
+	return &target
+}
+
+func (f *PidFanController) GetFanSnapshot() FanSnapshot {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+	return f.fanSnapshot
+}
+
+// recordPwm caches the given pwm value and the pwm settings of the fan for GetFanSnapshot
+func (f *PidFanController) recordPwm(pwm int) {
+	minPwm, startPwm, maxPwm := f.fan.GetMinPwm(), f.fan.GetStartPwm(), f.fan.GetMaxPwm()
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+	f.fanSnapshot.Pwm = &pwm
+	f.fanSnapshot.PwmErr = nil
+	f.fanSnapshot.MinPwm = minPwm
+	f.fanSnapshot.StartPwm = startPwm
+	f.fanSnapshot.MaxPwm = maxPwm
+}
+
+// recordPwmError caches the given error of a pwm read for GetFanSnapshot
+func (f *PidFanController) recordPwmError(err error) {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+	f.fanSnapshot.PwmErr = err
+}
+
+// recordCurveData caches the pwm settings and a summary of the curve data of the fan for GetFanSnapshot
+func (f *PidFanController) recordCurveData() {
+	curvePoints, maxRpm := 0, 0.0
+	if curveData := f.fan.GetFanCurveData(); curveData != nil {
+		curvePoints = len(*curveData)
+		for _, rpm := range *curveData {
+			maxRpm = math.Max(maxRpm, rpm)
+		}
+	}
+	minPwm, startPwm, maxPwm := f.fan.GetMinPwm(), f.fan.GetStartPwm(), f.fan.GetMaxPwm()
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+	f.fanSnapshot.MinPwm = minPwm
+	f.fanSnapshot.StartPwm = startPwm
+	f.fanSnapshot.MaxPwm = maxPwm
+	f.fanSnapshot.CurvePoints = curvePoints
+	f.fanSnapshot.MaxRpm = maxRpm
+}
+
+// recordRpm caches the given rpm measurement for GetFanSnapshot
+func (f *PidFanController) recordRpm(rpm int, rpmAvg float64) {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+	f.fanSnapshot.Rpm = &rpm
+	f.fanSnapshot.RpmAvg = rpmAvg
+}
+
+func (f *PidFanController) setState(state ControllerState) {
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
+	f.state = state
+}
+
 func (f *PidFanController) Run(ctx context.Context) error {
 	fan := f.fan
+	defer f.setState(ControllerStateStopped)
 
 	if fan.ShouldNeverStop() && !fan.Supports(fans.FeatureRpmSensor) {
-		ui.Warning("WARN: cannot guarantee neverStop option on fan %s, since it has no RPM input.", fan.GetId())
+		logger.WithFan(fan.GetId()).Warning("WARN: cannot guarantee neverStop option on fan %s, since it has no RPM input.", fan.GetId())
 	}
 
 	// store original pwm value
 	pwm, err := fan.GetPwm()
 	if err != nil {
-		ui.Warning("Cannot read pwm value of %s", fan.GetId())
+		logger.WithFan(fan.GetId()).Warning("Cannot read pwm value of %s", fan.GetId())
+		f.recordPwmError(err)
+	} else {
+		f.recordPwm(pwm)
 	}
 	f.originalPwmValue = pwm
 
@@ -114,28 +440,49 @@ func (f *PidFanController) Run(ctx context.Context) error {
 	if f.fan.Supports(fans.FeatureControlMode) {
 		pwmEnabled, err := fan.GetPwmEnabled()
 		if err != nil {
-			ui.Warning("Cannot read pwm_enable value of %s", fan.GetId())
+			logger.WithFan(fan.GetId()).Warning("Cannot read pwm_enable value of %s", fan.GetId())
 		}
 		f.originalPwmEnabled = fans.ControlMode(pwmEnabled)
 	}
 
-	ui.Info("Gathering sensor data for %s...", fan.GetId())
+	// store the original pwm mode and apply the configured one
+	f.applyPwmMode()
+
+	// store the original values in the database too, so they can be restored after a crash
+	if !configuration.CurrentConfig.DryRun {
+		state := persistence.FanState{Pwm: f.originalPwmValue}
+		if f.fan.Supports(fans.FeatureControlMode) {
+			pwmEnabled := int(f.originalPwmEnabled)
+			state.PwmEnabled = &pwmEnabled
+		}
+		state.PwmMode = f.originalPwmMode
+		err = f.persistence.SaveFanState(fan.GetId(), state)
+		if err != nil {
+			logger.WithFan(fan.GetId()).Warning("Cannot save original state of %s: %v", fan.GetId(), err)
+		}
+	}
+
+	logger.WithFan(fan.GetId()).Info("Gathering sensor data for %s...", fan.GetId())
 	// wait a bit to gather monitoring data
 	time.Sleep(2*time.Second + configuration.CurrentConfig.TempSensorPollingRate*2)
 
 	// check if we have data for this fan in persistence,
 	// if not we need to run the initialization sequence
-	ui.Info("Loading fan curve data for fan '%s'...", fan.GetId())
+	logger.WithFan(fan.GetId()).Info("Loading fan curve data for fan '%s'...", fan.GetId())
 	fanPwmData, err := f.persistence.LoadFanPwmData(fan)
 	if err != nil {
-		_, ok := fan.(*fans.HwMonFan)
-		if ok {
-			ui.Warning("Fan '%s' has not yet been analyzed, starting initialization sequence...", fan.GetId())
+		switch fan.(type) {
+		case *fans.HwMonFan, *fans.DellSmmFan:
+			if configuration.CurrentConfig.DryRun {
+				logger.WithFan(fan.GetId()).Warning("Fan '%s' has not yet been analyzed, assuming a linear curve in dry-run mode", fan.GetId())
+				return f.runWithCurveData(ctx, util.InterpolateLinearly(&map[int]float64{0: 0, 255: 255}, 0, 255))
+			}
+			logger.WithFan(fan.GetId()).Warning("Fan '%s' has not yet been analyzed, starting initialization sequence...", fan.GetId())
 			err = f.RunInitializationSequence()
 			if err != nil {
 				return err
 			}
-		} else {
+		default:
 			err = f.persistence.SaveFanPwmData(fan)
 			if err != nil {
 				return err
@@ -148,26 +495,39 @@ func (f *PidFanController) Run(ctx context.Context) error {
 		return err
 	}
 
-	err = fan.AttachFanCurveData(&fanPwmData)
+	return f.runWithCurveData(ctx, fanPwmData)
+}
+
+// runWithCurveData attaches the given curve data to the fan and runs the control loop
+func (f *PidFanController) runWithCurveData(ctx context.Context, fanPwmData map[int]float64) error {
+	fan := f.fan
+
+	err := fan.AttachFanCurveData(&fanPwmData)
 	if err != nil {
 		return err
 	}
+	f.loadPwmBoundaries()
+	f.recordCurveData()
 
 	err1 := f.computePwmMap()
 	if err1 != nil {
-		ui.Warning("Error computing PWM map: %v", err1)
+		logger.WithFan(fan.GetId()).Warning("Error computing PWM map: %v", err1)
 	}
 
 	f.updateDistinctPwmValues()
 
-	ui.Debug("PWM map of fan '%s': %v", fan.GetId(), f.pwmMap)
-	ui.Info("PWM settings of fan '%s': Min %d, Start %d, Max %d", fan.GetId(), fan.GetMinPwm(), fan.GetStartPwm(), fan.GetMaxPwm())
-	ui.Info("Starting controller loop for fan '%s'", fan.GetId())
+	logger.WithFan(fan.GetId()).Debug("PWM map of fan '%s': %v", fan.GetId(), f.pwmMap)
+	logger.WithFan(fan.GetId()).Info("PWM settings of fan '%s': Min %d, Start %d, Max %d", fan.GetId(), fan.GetMinPwm(), fan.GetStartPwm(), fan.GetMaxPwm())
+	logger.WithFan(fan.GetId()).Info("Starting controller loop for fan '%s'", fan.GetId())
 
 	if fan.GetMinPwm() > fan.GetStartPwm() {
-		ui.Warning("Suspicious pwm config of fan '%s': MinPwm (%d) > StartPwm (%d)", fan.GetId(), fan.GetMinPwm(), fan.GetStartPwm())
+		logger.WithFan(fan.GetId()).Warning("Suspicious pwm config of fan '%s': MinPwm (%d) > StartPwm (%d)", fan.GetId(), fan.GetMinPwm(), fan.GetStartPwm())
 	}
 
+	// the rpm monitor is stopped as well if the control loop stops due to an error
+	loopCtx, cancelLoop := context.WithCancel(ctx)
+	defer cancelLoop()
+
 	var g run.Group
 
 	if fan.Supports(fans.FeatureRpmSensor) {
@@ -175,46 +535,37 @@ func (f *PidFanController) Run(ctx context.Context) error {
 		pollingRate := configuration.CurrentConfig.RpmPollingRate
 
 		g.Add(func() error {
-			tick := time.NewTicker(pollingRate)
-			for {
-				select {
-				case <-ctx.Done():
-					ui.Info("Stopping RPM monitor of fan controller for fan %s...", fan.GetId())
-					return nil
-				case <-tick.C:
-					measureRpm(fan)
+			for polling.Wait(loopCtx, pollingRate) {
+				pwm, rpm, err := measureRpm(fan)
+				if err == nil {
+					f.recordRpm(rpm, fan.GetRpmAvg())
+					f.detectStall(pwm, rpm)
 				}
 			}
+			logger.WithFan(fan.GetId()).Info("Stopping RPM monitor of fan controller for fan %s...", fan.GetId())
+			return nil
 		}, func(err error) {
-			if err != nil {
-				ui.Warning("Error monitoring fan rpm: %v", err)
-			}
+			cancelLoop()
 		})
 	}
 
 	{
 		g.Add(func() error {
 			time.Sleep(1 * time.Second)
-			tick := time.NewTicker(f.updateRate)
-			for {
-				select {
-				case <-ctx.Done():
-					ui.Info("Stopping fan controller for fan %s...", fan.GetId())
+			for polling.Wait(loopCtx, f.updateRate) {
+				err := f.UpdateFanSpeed()
+				if err != nil {
+					logger.WithFan(fan.GetId()).ErrorAndNotify("Fan Control Error", "Fan %s: %v", fan.GetId(), err)
+					alerting.Fire(alerting.EventFanFailure, fan.GetId(), "Unable to control fan %s: %v", fan.GetId(), err)
 					f.restorePwmEnabled()
-					return nil
-				case <-tick.C:
-					err = f.UpdateFanSpeed()
-					if err != nil {
-						ui.ErrorAndNotify("Fan Control Error", "Fan %s: %v", fan.GetId(), err)
-						f.restorePwmEnabled()
-						return nil
-					}
+					return err
 				}
 			}
+			logger.WithFan(fan.GetId()).Info("Stopping fan controller for fan %s...", fan.GetId())
+			f.restorePwmEnabled()
+			return nil
 		}, func(err error) {
-			if err != nil {
-				ui.Fatal("Error monitoring fan rpm: %v", err)
-			}
+			cancelLoop()
 		})
 	}
 
@@ -225,19 +576,66 @@ func (f *PidFanController) Run(ctx context.Context) error {
 func (f *PidFanController) UpdateFanSpeed() error {
 	fan := f.fan
 
+	if atomic.CompareAndSwapInt32(&f.reinitializeRequested, 1, 0) {
+		f.reinitialize()
+	}
+	if !configuration.CurrentConfig.DryRun {
+		if err := fans.ReassertPwm(fan); err != nil {
+			logger.WithFan(fan.GetId()).Warning("Unable to reassert the pwm of fan %s: %v", fan.GetId(), err)
+		}
+	}
+	f.detectStuckPwm()
+
 	lastSetPwm := 0
 	if f.lastSetPwm != nil {
 		lastSetPwm = *(f.lastSetPwm)
 	} else {
 		pwm, err := f.fan.GetPwm()
 		if err != nil {
+			f.recordPwmError(err)
 			return err
 		}
 		lastSetPwm = pwm
 	}
 
+	// critical temperatures, kicking a stalled fan, as well as stopping and restarting
+	// a fan in zero rpm mode bypass the pid loop, since a slow ramp would defeat them
+	if emergency.IsMaxFansActive() {
+		f.setState(ControllerStateMaxFans)
+		f.setPwmDirectly(fans.MaxPwmValue)
+		return nil
+	}
+	if f.isStallKickActive() {
+		f.setState(ControllerStateStallKick)
+		f.setPwmDirectly(configuration.CurrentConfig.StallDetection.KickPwm)
+		return nil
+	}
+	if f.updateFailsafe() {
+		f.setState(ControllerStateFailsafe)
+		f.setPwmDirectly(configuration.CurrentConfig.Failsafe.Pwm)
+		return nil
+	}
+	if f.GetOverride() == nil {
+		if pwm, active := f.calculateZeroRpmPwm(); active {
+			f.setState(ControllerStateZeroRpm)
+			f.setPwmDirectly(f.applyPwmLimit(pwm))
+			return nil
+		}
+		f.setState(ControllerStateCurve)
+	} else {
+		f.setState(ControllerStateOverride)
+	}
+
 	// calculate the direct optimal target speed
 	target := f.calculateTargetPwm()
+	if target < 0 {
+		// the curve can't be evaluated, f.ex. since a sensor is quarantined, the fan keeps its
+		// current pwm and the pid loop starts over once the curve can be evaluated again,
+		// instead of accumulating an error in the meantime
+		f.pidLoop.Reset()
+		return nil
+	}
+	target = f.applyPwmLimit(target)
 
 	// ask the PID controller how to proceed
 	pidChange := math.Ceil(f.pidLoop.Loop(float64(target), float64(lastSetPwm)))
@@ -248,14 +646,12 @@ func (f *PidFanController) UpdateFanSpeed() error {
 
 	// ensure we are within sane bounds
 	coerced := util.Coerce(float64(lastSetPwm)+pidControllerTarget, 0, 255)
-	roundedTarget := int(math.Round(coerced))
+	roundedTarget := f.applyPwmLimit(int(math.Round(coerced)))
 
-	if target >= 0 {
-		_ = trySetManualPwm(f.fan)
-		err := f.setPwm(roundedTarget)
-		if err != nil {
-			ui.Error("Error setting %s: %v", fan.GetId(), err)
-		}
+	_ = trySetManualPwm(f.fan)
+	err := f.setPwm(roundedTarget)
+	if err != nil {
+		logger.WithFan(fan.GetId()).Error("Error setting %s: %v", fan.GetId(), err)
 	}
 
 	return nil
@@ -264,26 +660,30 @@ func (f *PidFanController) UpdateFanSpeed() error {
 func (f *PidFanController) RunInitializationSequence() (err error) {
 	fan := f.fan
 
+	if configuration.CurrentConfig.DryRun {
+		return errors.New("the initialization sequence can't run in dry-run mode, since it requires changing the pwm of the fan")
+	}
+
 	err1 := f.computePwmMap()
 	if err1 != nil {
-		ui.Warning("Error computing PWM map: %v", err1)
+		logger.WithFan(fan.GetId()).Warning("Error computing PWM map: %v", err1)
 	}
 
 	err = f.persistence.SaveFanPwmMap(fan.GetId(), f.pwmMap)
 	if err != nil {
-		ui.Error("Unable to persist pwmMap for fan %s", fan.GetId())
+		logger.WithFan(fan.GetId()).Error("Unable to persist pwmMap for fan %s", fan.GetId())
 	}
 	f.updateDistinctPwmValues()
 
 	if !fan.Supports(fans.FeatureRpmSensor) {
-		ui.Info("Fan '%s' doesn't support RPM sensor, skipping fan curve measurement", fan.GetId())
+		logger.WithFan(fan.GetId()).Info("Fan '%s' doesn't support RPM sensor, skipping fan curve measurement", fan.GetId())
 		return nil
 	}
-	ui.Info("Measuring RPM curve...")
+	logger.WithFan(fan.GetId()).Info("Measuring RPM curve...")
 
 	err = trySetManualPwm(fan)
 	if err != nil {
-		ui.Warning("Could not enable manual fan mode on %s, trying to continue anyway...", fan.GetId())
+		logger.WithFan(fan.GetId()).Warning("Could not enable manual fan mode on %s, trying to continue anyway...", fan.GetId())
 	}
 
 	curveData := map[int]float64{}
@@ -293,18 +693,18 @@ func (f *PidFanController) RunInitializationSequence() (err error) {
 		// set a pwm
 		err = f.setPwm(pwm)
 		if err != nil {
-			ui.Error("Unable to run initialization sequence on %s: %v", fan.GetId(), err)
+			logger.WithFan(fan.GetId()).Error("Unable to run initialization sequence on %s: %v", fan.GetId(), err)
 			return err
 		}
 		expectedPwm := f.pwmMap[pwm]
 		time.Sleep(pwmSetGetDelay)
 		actualPwm, err := fan.GetPwm()
 		if err != nil {
-			ui.Error("Fan %s: Unable to measure current PWM", fan.GetId())
+			logger.WithFan(fan.GetId()).Error("Fan %s: Unable to measure current PWM", fan.GetId())
 			return err
 		}
 		if actualPwm != expectedPwm {
-			ui.Debug("Fan %s: Actual PWM value differs from requested one, skipping: requested: %d, expected: %d, actual: %d", fan.GetId(), pwm, expectedPwm, actualPwm)
+			logger.WithFan(fan.GetId()).Debug("Fan %s: Actual PWM value differs from requested one, skipping: requested: %d, expected: %d, actual: %d", fan.GetId(), pwm, expectedPwm, actualPwm)
 			continue
 		}
 
@@ -318,77 +718,354 @@ func (f *PidFanController) RunInitializationSequence() (err error) {
 
 		rpm, err := fan.GetRpm()
 		if err != nil {
-			ui.Error("Unable to measure RPM of fan %s", fan.GetId())
+			logger.WithFan(fan.GetId()).Error("Unable to measure RPM of fan %s", fan.GetId())
 			return err
 		}
-		ui.Debug("Measuring RPM of %s at PWM %d: %d", fan.GetId(), pwm, rpm)
+		logger.WithFan(fan.GetId()).Debug("Measuring RPM of %s at PWM %d: %d", fan.GetId(), pwm, rpm)
 
 		// update rpm curve
 		fan.SetRpmAvg(float64(rpm))
 		curveData[pwm] = float64(rpm)
 
-		ui.Debug("Measured RPM of %d at PWM %d for fan %s", int(fan.GetRpmAvg()), pwm, fan.GetId())
+		logger.WithFan(fan.GetId()).Debug("Measured RPM of %d at PWM %d for fan %s", int(fan.GetRpmAvg()), pwm, fan.GetId())
 	}
 
 	err = fan.AttachFanCurveData(&curveData)
 	if err != nil {
-		ui.Error("Failed to attach fan curve data to fan %s: %v", fan.GetId(), err)
+		logger.WithFan(fan.GetId()).Error("Failed to attach fan curve data to fan %s: %v", fan.GetId(), err)
 		return err
 	}
 
 	// save to database to restore it on restarts
 	err = f.persistence.SaveFanPwmData(fan)
 	if err != nil {
-		ui.Error("Failed to save fan PWM data for %s: %v", fan.GetId(), err)
+		logger.WithFan(fan.GetId()).Error("Failed to save fan PWM data for %s: %v", fan.GetId(), err)
+		return err
+	}
+
+	boundaries, err := f.measurePwmBoundaries(curveData)
+	if err != nil {
+		logger.WithFan(fan.GetId()).Warning("Unable to measure startPwm and minPwm of fan %s: %v", fan.GetId(), err)
+		return nil
+	}
+	logger.WithFan(fan.GetId()).Info("Measured startPwm %d and minPwm %d of fan %s", boundaries.StartPwm, boundaries.MinPwm, fan.GetId())
+	f.applyPwmBoundaries(boundaries)
+
+	err = f.persistence.SaveFanPwmBoundaries(fan.GetId(), boundaries)
+	if err != nil {
+		logger.WithFan(fan.GetId()).Error("Failed to save pwm boundaries of fan %s: %v", fan.GetId(), err)
 	}
 	return err
 }
 
-// read the current value of a fan RPM sensor and append it to the moving window
-func measureRpm(fan fans.Fan) {
-	pwm, err := fan.GetPwm()
+// measurePwmBoundaries determines the lowest pwm value which reliably starts the fan from standstill,
+// and the lowest pwm value at which the fan keeps spinning once it is running, using the given
+// curve data of the rising pwm sweep as a starting point
+func (f *PidFanController) measurePwmBoundaries(curveData map[int]float64) (persistence.FanPwmBoundaries, error) {
+	fan := f.fan
+	responseDelay := time.Duration(configuration.CurrentConfig.FanResponseDelay) * time.Second
+
+	spinsAt := func(pwm int) (bool, error) {
+		err := f.setPwm(pwm)
+		if err != nil {
+			return false, err
+		}
+		time.Sleep(responseDelay)
+		rpm, err := fan.GetRpm()
+		logger.WithFan(fan.GetId()).Debug("Measuring RPM of %s at PWM %d: %d", fan.GetId(), pwm, rpm)
+		return rpm > 0, err
+	}
+
+	// the sweep started at standstill, so the fan started spinning at the lowest pwm with a measured rpm,
+	// each candidate is verified by starting the fan from standstill again
+	candidate, _ := fans.ComputeCurveBoundaries(curveData)
+	startPwm, err := findStartPwm(f.pwmValuesWithDistinctTarget, candidate, func(pwm int) (bool, error) {
+		err := f.setPwm(fans.MinPwmValue)
+		if err != nil {
+			return false, err
+		}
+		f.waitForFanToSettle(fan)
+		return spinsAt(pwm)
+	})
 	if err != nil {
-		ui.Warning("Error reading PWM value of fan %s: %v", fan.GetId(), err)
+		return persistence.FanPwmBoundaries{}, err
 	}
-	rpm, err := fan.GetRpm()
+
+	// lower the pwm of the running fan step by step, until it stops
+	f.waitForFanToSettle(fan)
+	minPwm, err := findMinPwm(f.pwmValuesWithDistinctTarget, startPwm, spinsAt)
 	if err != nil {
-		ui.Warning("Error reading RPM value of fan %s: %v", fan.GetId(), err)
+		return persistence.FanPwmBoundaries{}, err
+	}
+
+	return persistence.FanPwmBoundaries{StartPwm: startPwm, MinPwm: minPwm}, nil
+}
+
+// findStartPwm returns the first of the given ascending pwm values, starting at from, which starts the fan
+func findStartPwm(pwmValues []int, from int, startsFan func(pwm int) (bool, error)) (int, error) {
+	for _, pwm := range pwmValues {
+		if pwm < from {
+			continue
+		}
+		started, err := startsFan(pwm)
+		if err != nil {
+			return 0, err
+		}
+		if started {
+			return pwm, nil
+		}
+	}
+	return 0, errors.New("fan didn't start spinning at any pwm value")
+}
+
+// findMinPwm lowers the pwm of a fan running at startPwm step by step, using the given ascending pwm values,
+// and returns the lowest one at which the fan kept spinning
+func findMinPwm(pwmValues []int, startPwm int, keepsSpinning func(pwm int) (bool, error)) (int, error) {
+	minPwm := startPwm
+	for i := len(pwmValues) - 1; i >= 0; i-- {
+		pwm := pwmValues[i]
+		if pwm >= startPwm {
+			continue
+		}
+		spinning, err := keepsSpinning(pwm)
+		if err != nil {
+			return 0, err
+		}
+		if !spinning {
+			break
+		}
+		minPwm = pwm
+	}
+	return minPwm, nil
+}
+
+// loadPwmBoundaries applies the startPwm and minPwm measured during the initialization sequence, if any
+func (f *PidFanController) loadPwmBoundaries() {
+	boundaries, err := f.persistence.LoadFanPwmBoundaries(f.fan.GetId())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.WithFan(f.fan.GetId()).Warning("Unable to load pwm boundaries of fan %s: %v", f.fan.GetId(), err)
+		}
+		return
+	}
+	f.applyPwmBoundaries(boundaries)
+}
+
+// applyPwmBoundaries uses the given measured startPwm and minPwm for the fan,
+// unless they are configured explicitly
+func (f *PidFanController) applyPwmBoundaries(boundaries persistence.FanPwmBoundaries) {
+	f.pwmBoundaries = &boundaries
+	f.fan.SetStartPwm(boundaries.StartPwm, false)
+	f.fan.SetMinPwm(boundaries.MinPwm, false)
+}
+
+// reinitialize applies the control mode and the last set pwm value to the fan again,
+// regardless of the current state of the fan
+func (f *PidFanController) reinitialize() {
+	fan := f.fan
+	if configuration.CurrentConfig.DryRun {
+		logger.WithFan(fan.GetId()).Info("Dry run: would re-initialize fan %s", fan.GetId())
+		return
+	}
+	logger.WithFan(fan.GetId()).Info("Re-initializing fan %s...", fan.GetId())
+
+	err := trySetManualPwm(fan)
+	if err != nil {
+		logger.WithFan(fan.GetId()).Warning("Could not enable manual fan mode on %s: %v", fan.GetId(), err)
+	}
+	if f.lastSetPwm != nil {
+		err = fan.SetPwm(f.findClosestDistinctTarget(*f.lastSetPwm))
+		if err != nil {
+			logger.WithFan(fan.GetId()).Warning("Could not restore the pwm value of %s: %v", fan.GetId(), err)
+		}
+	}
+	f.zeroRpmStopped = false
+}
+
+// setPwmDirectly sets the given pwm value without going through the pid loop
+func (f *PidFanController) setPwmDirectly(pwm int) {
+	_ = trySetManualPwm(f.fan)
+	err := f.setPwm(pwm)
+	if err != nil {
+		logger.WithFan(f.fan.GetId()).Error("Error setting %s: %v", f.fan.GetId(), err)
+	}
+}
+
+// read the current value of a fan RPM sensor and append it to the moving window,
+// returns the measured pwm and rpm values and the last error that occurred while reading them
+func measureRpm(fan fans.Fan) (pwm int, rpm int, err error) {
+	pwm, pwmErr := fan.GetPwm()
+	if pwmErr != nil {
+		logger.WithFan(fan.GetId()).Warning("Error reading PWM value of fan %s: %v", fan.GetId(), pwmErr)
+		err = pwmErr
+	}
+	rpm, rpmErr := fan.GetRpm()
+	if rpmErr != nil {
+		logger.WithFan(fan.GetId()).Warning("Error reading RPM value of fan %s: %v", fan.GetId(), rpmErr)
+		err = rpmErr
 	}
 
 	updatedRpmAvg := util.UpdateSimpleMovingAvg(fan.GetRpmAvg(), configuration.CurrentConfig.RpmRollingWindowSize, float64(rpm))
 	fan.SetRpmAvg(updatedRpmAvg)
 
-	pwmRpmMap := fan.GetFanCurveData()
-	(*pwmRpmMap)[pwm] = float64(rpm)
+	// fans without a measured curve share a linear one, which must not be modified
+	if fan.Supports(fans.FeatureMeasuredCurve) {
+		pwmRpmMap := fan.GetFanCurveData()
+		(*pwmRpmMap)[pwm] = float64(rpm)
+	}
+
+	return pwm, rpm, err
+}
+
+// detectStall counts the consecutive rpm measurements of a fan that doesn't rotate, although it
+// is driven with a pwm value it should be spinning at. Once the configured amount of cycles is
+// reached, the fan is kicked with a fixed pwm value to get it spinning again.
+func (f *PidFanController) detectStall(pwm int, rpm int) {
+	config := configuration.CurrentConfig.StallDetection
+	if !config.Enabled {
+		return
+	}
+
+	f.stallLock.Lock()
+	defer f.stallLock.Unlock()
+
+	now := time.Now()
+	if rpm > 0 || pwm <= 0 || pwm < f.fan.GetStartPwm() || now.Before(f.stallKickUntil) {
+		f.stallCycles = 0
+		return
+	}
+
+	f.stallCycles++
+	if f.stallCycles < config.Cycles {
+		return
+	}
+
+	f.stallCycles = 0
+	f.stateLock.Lock()
+	f.stats.StallCount += 1
+	f.stateLock.Unlock()
+	f.stallKickUntil = now.Add(config.KickDuration)
+	logger.WithFan(f.fan.GetId()).ErrorAndNotify("Fan Stalled", "Fan %s reports 0 RPM at PWM %d, restarting it with PWM %d", f.fan.GetId(), pwm, config.KickPwm)
+	alerting.Fire(alerting.EventFanFailure, f.fan.GetId(), "Fan %s stalled at PWM %d", f.fan.GetId(), pwm)
+}
+
+// detectStuckPwm compares the pwm of the fan to the value written in the previous update. If they differ
+// for the configured amount of consecutive updates, f.ex. because the BIOS fan control is still active
+// or the driver limits the pwm values, the likely cause is logged and the manual control mode of the
+// fan is applied again, if configured.
+func (f *PidFanController) detectStuckPwm() {
+	fan := f.fan
+	// in dry-run mode the pwm is never written, so it always differs from the last "set" value
+	if f.lastSetPwm == nil || f.pwmMap == nil || configuration.CurrentConfig.DryRun {
+		return
+	}
+	expected := f.pwmMap[f.findClosestDistinctTarget(*f.lastSetPwm)]
+	actual, err := fan.GetPwm()
+	if err != nil {
+		return
+	}
+
+	config := configuration.CurrentConfig.StuckPwmDetection
+	if actual == expected {
+		if config.Enabled && f.stuckPwmCycles >= config.Cycles {
+			logger.WithFan(fan.GetId()).Info("PWM of fan %s follows the written value again", fan.GetId())
+		}
+		f.stuckPwmCycles = 0
+		return
+	}
+
+	f.stateLock.Lock()
+	f.stats.UnexpectedPwmValueCount += 1
+	f.stateLock.Unlock()
+	if !config.Enabled {
+		logger.WithFan(fan.GetId()).Warning("PWM of %s was changed by third party! Last set PWM value was: %d but is now: %d",
+			fan.GetId(), expected, actual)
+		return
+	}
+
+	if f.stuckPwmCycles == 0 {
+		f.stuckPwmValue = actual
+		f.stuckPwmChanging = false
+	} else if actual != f.stuckPwmValue {
+		f.stuckPwmChanging = true
+	}
+	f.stuckPwmCycles++
+	if f.stuckPwmCycles != config.Cycles {
+		logger.WithFan(fan.GetId()).Debug("PWM of fan %s is %d instead of the written value %d", fan.GetId(), actual, expected)
+		return
+	}
+
+	logger.WithFan(fan.GetId()).Warning("PWM of fan %s is stuck, %s", fan.GetId(), f.diagnoseStuckPwm(expected, actual))
+	if config.ReassertPwmEnable {
+		f.reinitialize()
+	}
+}
+
+// diagnoseStuckPwm describes the likely cause of a pwm value that differs from the written one
+func (f *PidFanController) diagnoseStuckPwm(expected int, actual int) string {
+	fan := f.fan
+	if fan.Supports(fans.FeatureControlMode) {
+		manualMode := fans.GetManualControlMode(fan)
+		if pwmEnabled, err := fan.GetPwmEnabled(); err == nil && fans.ControlMode(pwmEnabled) != manualMode {
+			return fmt.Sprintf("its control mode (pwm_enable) is %d instead of %d, the BIOS or another program took over control of the fan",
+				pwmEnabled, manualMode)
+		}
+	}
+	if !f.stuckPwmChanging {
+		return fmt.Sprintf("the written value %d is ignored and the pwm stays at %d, f.ex. because the BIOS fan control is still active",
+			expected, actual)
+	}
+	return fmt.Sprintf("the written value %d is changed to %d, f.ex. because the driver limits the pwm values", expected, actual)
+}
+
+// isStallKickActive indicates whether the fan is currently kicked by the stall detection
+func (f *PidFanController) isStallKickActive() bool {
+	f.stallLock.Lock()
+	defer f.stallLock.Unlock()
+	return time.Now().Before(f.stallKickUntil)
 }
 
 func trySetManualPwm(fan fans.Fan) error {
-	if !fan.Supports(fans.FeatureControlMode) {
+	if !fan.Supports(fans.FeatureControlMode) || configuration.CurrentConfig.DryRun {
 		return nil
 	}
 
-	err := fan.SetPwmEnabled(fans.ControlModePWM)
+	manualMode := fans.GetManualControlMode(fan)
+	err := fan.SetPwmEnabled(manualMode)
 	if err != nil {
-		ui.Error("Unable to set Fan Mode of '%s' to \"%d\": %v", fan.GetId(), fans.ControlModePWM, err)
+		logger.WithFan(fan.GetId()).Error("Unable to set Fan Mode of '%s' to \"%d\": %v", fan.GetId(), manualMode, err)
 		err = fan.SetPwmEnabled(fans.ControlModeDisabled)
 		if err != nil {
-			ui.Error("Unable to set Fan Mode of '%s' to \"%d\": %v", fan.GetId(), fans.ControlModeDisabled, err)
+			logger.WithFan(fan.GetId()).Error("Unable to set Fan Mode of '%s' to \"%d\": %v", fan.GetId(), fans.ControlModeDisabled, err)
 		}
 	}
 	return err
 }
 
 func (f *PidFanController) restorePwmEnabled() {
-	ui.Info("Trying to restore fan settings for %s...", f.fan.GetId())
+	logger.WithFan(f.fan.GetId()).Info("Trying to restore fan settings for %s...", f.fan.GetId())
 
 	err := f.setPwm(f.originalPwmValue)
 	if err != nil {
-		ui.Warning("Error restoring original PWM value for fan %s: %v", f.fan.GetId(), err)
+		logger.WithFan(f.fan.GetId()).Warning("Error restoring original PWM value for fan %s: %v", f.fan.GetId(), err)
+	}
+
+	if configuration.CurrentConfig.DryRun {
+		return
 	}
+	defer func() {
+		err := f.persistence.DeleteFanState(f.fan.GetId())
+		if err != nil {
+			logger.WithFan(f.fan.GetId()).Warning("Cannot delete original state of %s: %v", f.fan.GetId(), err)
+		}
+	}()
+
+	f.restorePwmMode()
 
 	// try to reset the pwm_enable value
-	if f.fan.Supports(fans.FeatureControlMode) && f.originalPwmEnabled != fans.ControlModePWM {
-		err := f.fan.SetPwmEnabled(f.originalPwmEnabled)
+	restoreMode := fans.GetRestoreControlMode(f.fan, f.originalPwmEnabled)
+	if f.fan.Supports(fans.FeatureControlMode) && restoreMode != fans.GetManualControlMode(f.fan) {
+		err := f.fan.SetPwmEnabled(restoreMode)
 		if err == nil {
 			return
 		}
@@ -396,47 +1073,134 @@ func (f *PidFanController) restorePwmEnabled() {
 	// if this fails, try to set it to max speed instead
 	err = f.setPwm(fans.MaxPwmValue)
 	if err != nil {
-		ui.Warning("Unable to restore fan %s, make sure it is running!", f.fan.GetId())
+		logger.WithFan(f.fan.GetId()).Warning("Unable to restore fan %s, make sure it is running!", f.fan.GetId())
 	}
 }
 
+// applyPwmMode switches a hwmon fan to its configured pwm mode (DC or PWM),
+// the original mode is stored so it can be restored when fan2go stops
+func (f *PidFanController) applyPwmMode() {
+	fan, ok := f.fan.(*fans.HwMonFan)
+	if !ok {
+		return
+	}
+	mode, ok := fans.GetConfiguredPwmMode(*fan.Config.HwMon)
+	if !ok {
+		return
+	}
+
+	original, err := fan.GetPwmMode()
+	if err != nil {
+		logger.WithFan(fan.GetId()).Warning("Cannot read pwm mode of %s: %v", fan.GetId(), err)
+		return
+	}
+	if original == mode {
+		return
+	}
+	f.originalPwmMode = &original
+
+	if configuration.CurrentConfig.DryRun {
+		logger.WithFan(fan.GetId()).Info("Dry run: would switch fan %s to %s mode", fan.GetId(), fans.PwmModeName(mode))
+		return
+	}
+	logger.WithFan(fan.GetId()).Info("Switching fan %s from %s to %s mode", fan.GetId(), fans.PwmModeName(original), fans.PwmModeName(mode))
+	err = fan.SetPwmMode(mode)
+	if err != nil {
+		logger.WithFan(fan.GetId()).Warning("Cannot set pwm mode of %s: %v", fan.GetId(), err)
+	}
+}
+
+// restorePwmMode restores the pwm mode a hwmon fan had before applyPwmMode changed it
+func (f *PidFanController) restorePwmMode() {
+	fan, ok := f.fan.(*fans.HwMonFan)
+	if !ok || f.originalPwmMode == nil {
+		return
+	}
+	err := fan.SetPwmMode(*f.originalPwmMode)
+	if err != nil {
+		logger.WithFan(fan.GetId()).Warning("Error restoring original pwm mode for fan %s: %v", fan.GetId(), err)
+	}
+}
+
+// applyPwmLimit caps the given pwm value at the pwm limit of the fan, the cap of the active profile
+// and the cap of night mode, if any.
+// Critical temperatures, stall kicks and failsafe mode are not limited, since they protect the hardware.
+func (f *PidFanController) applyPwmLimit(pwm int) int {
+	if limit, ok := fans.GetPwmLimit(f.fan.GetConfig()); ok && pwm > limit {
+		pwm = limit
+	}
+	if limit, ok := profiles.GetMaxPwm(f.fan.GetId()); ok && pwm > limit {
+		pwm = limit
+	}
+	if limit, ok := nightmode.GetMaxPwm(f.fan.GetId(), time.Now()); ok && pwm > limit {
+		pwm = limit
+	}
+	return pwm
+}
+
 // calculates the optimal pwm for a fan with the given target level.
 // returns -1 if no rpm is detected even at fan.maxPwm
 func (f *PidFanController) calculateTargetPwm() int {
 	fan := f.fan
-	target, err := f.curve.Evaluate()
+
+	if override := f.GetOverride(); override != nil {
+		return override.Pwm
+	}
+
+	// the curve is looked up on each evaluation, since it may be replaced
+	// by a config reload or the active profile
+	curveId := profiles.GetCurveId(fan.GetId(), fan.GetCurveId())
+	curve, ok := curves.GetSpeedCurve(curveId)
+	if !ok {
+		f.reportCurveError(fmt.Errorf("curve %s doesn't exist", curveId))
+		return -1
+	}
+	target, err := curve.Evaluate()
 	if err != nil {
-		ui.Fatal("Unable to calculate optimal PWM value for %s: %v", fan.GetId(), err)
+		// f.ex. a sensor of the curve is quarantined, the fan keeps its pwm until
+		// the sensor is available again, or the failsafe takes over
+		f.reportCurveError(err)
+		return -1
+	}
+	if f.curveErrorLogged {
+		logger.WithFan(fan.GetId()).Info("Curve of fan %s can be evaluated again", fan.GetId())
+		f.curveErrorLogged = false
 	}
 
 	// ensure target value is within bounds of possible values
 	if target > fans.MaxPwmValue {
-		ui.Warning("Tried to set out-of-bounds PWM value %d on fan %s", target, fan.GetId())
+		logger.WithFan(fan.GetId()).Warning("Tried to set out-of-bounds PWM value %d on fan %s", target, fan.GetId())
 		target = fans.MaxPwmValue
 	} else if target < fans.MinPwmValue {
-		ui.Warning("Tried to set out-of-bounds PWM value %d on fan %s", target, fan.GetId())
+		logger.WithFan(fan.GetId()).Warning("Tried to set out-of-bounds PWM value %d on fan %s", target, fan.GetId())
 		target = fans.MinPwmValue
 	}
 
+	// adjust the curve value for this fan, since multiple fans may share the same curve
+	target = fans.ApplyCurveAdjustment(fan.GetConfig(), target)
+
 	// map the target value to the possible range of this fan
 	maxPwm := fan.GetMaxPwm()
 	minPwm := fan.GetMinPwm() + f.minPwmOffset
+	measuredMinPwm, stoppable := f.getStoppableMinPwm()
+	if stoppable {
+		minPwm = measuredMinPwm
+	}
 
-	// TODO: this assumes a linear curve, but it might be something else
-	target = minPwm + int((float64(target)/fans.MaxPwmValue)*(float64(maxPwm)-float64(minPwm)))
-
-	if f.lastSetPwm != nil && f.pwmMap != nil {
-		lastSetPwm := *(f.lastSetPwm)
-		expected := f.pwmMap[f.findClosestDistinctTarget(lastSetPwm)]
-		if currentPwm, err := fan.GetPwm(); err == nil {
-			if currentPwm != expected {
-				f.stats.UnexpectedPwmValueCount += 1
-				ui.Warning("PWM of %s was changed by third party! Last set PWM value was: %d but is now: %d",
-					fan.GetId(), expected, currentPwm)
-			}
-		}
+	if stoppable && target <= 0 {
+		// fans which are allowed to stop only stop at a curve value of 0,
+		// all other values are mapped to the range in which the fan keeps spinning
+		target = fans.MinPwmValue
+	} else if pwm, ok := f.calculateRpmTargetPwm(target); ok {
+		target = int(util.Coerce(float64(pwm), float64(minPwm), float64(maxPwm)))
+	} else {
+		// TODO: this assumes a linear curve, but it might be something else
+		target = minPwm + int((float64(target)/fans.MaxPwmValue)*(float64(maxPwm)-float64(minPwm)))
 	}
 
+	target = f.applyStartPwm(target, minPwm)
+	target = f.applyMinRpm(target, maxPwm)
+
 	if fan.Supports(fans.FeatureRpmSensor) {
 		// make sure fans never stop by validating the current RPM
 		// and adjusting the target PWM value upwards if necessary
@@ -445,11 +1209,12 @@ func (f *PidFanController) calculateTargetPwm() int {
 			avgRpm := fan.GetRpmAvg()
 			if avgRpm <= 0 {
 				if target >= maxPwm {
-					ui.Error("CRITICAL: Fan %s avg. RPM is %d, even at PWM value %d", fan.GetId(), int(avgRpm), target)
+					logger.WithFan(fan.GetId()).Error("CRITICAL: Fan %s avg. RPM is %d, even at PWM value %d", fan.GetId(), int(avgRpm), target)
+					alerting.Fire(alerting.EventFanFailure, fan.GetId(), "Fan %s avg. RPM is %d, even at PWM value %d", fan.GetId(), int(avgRpm), target)
 					return -1
 				}
 				oldOffset := f.minPwmOffset
-				ui.Warning("WARNING: Increasing minPWM of %s from %d to %d, which is supposed to never stop, but RPM is %d",
+				logger.WithFan(fan.GetId()).Warning("WARNING: Increasing minPWM of %s from %d to %d, which is supposed to never stop, but RPM is %d",
 					fan.GetId(), oldOffset, oldOffset+1, int(avgRpm))
 				f.increaseMinPwmOffset()
 				fan.SetMinPwm(f.minPwmOffset, true)
@@ -465,21 +1230,265 @@ func (f *PidFanController) calculateTargetPwm() int {
 	return target
 }
 
+// reportCurveError logs that the curve of the fan can't be evaluated, once until it can be evaluated again
+func (f *PidFanController) reportCurveError(err error) {
+	if f.curveErrorLogged {
+		return
+	}
+	logger.WithFan(f.fan.GetId()).Error("Unable to calculate optimal PWM value for %s, keeping the current one: %v", f.fan.GetId(), err)
+	f.curveErrorLogged = true
+}
+
+// getStoppableMinPwm returns the minPwm of a fan which is allowed to stop, if it was measured
+// during the initialization sequence. A configured minPwm takes precedence over the measured one.
+func (f *PidFanController) getStoppableMinPwm() (int, bool) {
+	fan := f.fan
+	if f.pwmBoundaries == nil || fan.ShouldNeverStop() {
+		return 0, false
+	}
+	if minPwm := fan.GetConfig().MinPwm; minPwm != nil {
+		return *minPwm, true
+	}
+	return f.pwmBoundaries.MinPwm, true
+}
+
+// applyStartPwm raises the given pwm value of a stopped fan to its startPwm, since its minPwm
+// keeps the fan spinning once it is running, but might not be enough to start it
+func (f *PidFanController) applyStartPwm(pwm int, minPwm int) int {
+	if f.pwmBoundaries == nil || pwm <= 0 || !f.fan.Supports(fans.FeatureRpmSensor) {
+		return pwm
+	}
+	startPwm := f.fan.GetStartPwm()
+	if pwm >= startPwm {
+		return pwm
+	}
+	stopped := f.lastSetPwm == nil || *f.lastSetPwm < minPwm || f.fan.GetRpmAvg() <= 0
+	if stopped {
+		return startPwm
+	}
+	return pwm
+}
+
+// applyMinRpm raises the given pwm value, so the fan keeps spinning at its minRpm while it should be spinning.
+// The measured curve of the fan is used as a starting point for the pwm floor, which is raised while the
+// measured RPM is too low, f.ex. due to an aging fan or voltage droop, and lowered again once the RPM
+// is well above minRpm.
+func (f *PidFanController) applyMinRpm(pwm int, maxPwm int) int {
+	fan := f.fan
+	minRpm := float64(fan.GetConfig().MinRpm)
+	if minRpm <= 0 || pwm <= 0 {
+		return pwm
+	}
+	if !fan.Supports(fans.FeatureRpmSensor) || !fan.Supports(fans.FeatureMeasuredCurve) {
+		if !f.minRpmUnsupportedWarned {
+			logger.WithFan(fan.GetId()).Warning("Fan %s has no RPM sensor or measured curve, its minRpm is ignored", fan.GetId())
+			f.minRpmUnsupportedWarned = true
+		}
+		return pwm
+	}
+
+	if f.minRpmPwm == nil {
+		floor := 0
+		if curveData := fan.GetFanCurveData(); curveData != nil && len(*curveData) > 0 {
+			floor = fans.FindPwmForRpm(*curveData, minRpm)
+		}
+		f.minRpmPwm = &floor
+	}
+
+	// the floor is only corrected while it actually determines the pwm of the fan
+	if f.lastSetPwm != nil && *f.lastSetPwm <= *f.minRpmPwm {
+		avgRpm := fan.GetRpmAvg()
+		if avgRpm < minRpm {
+			if *f.minRpmPwm >= maxPwm {
+				if !f.minRpmWarned {
+					logger.WithFan(fan.GetId()).Warning("Fan %s can't reach its minRpm of %d, its avg. RPM is %d at PWM %d",
+						fan.GetId(), int(minRpm), int(avgRpm), *f.minRpmPwm)
+					f.minRpmWarned = true
+				}
+			} else {
+				*f.minRpmPwm++
+			}
+		} else if avgRpm > minRpm*(1+minRpmHysteresis) && *f.minRpmPwm > 0 {
+			*f.minRpmPwm--
+			f.minRpmWarned = false
+		}
+	}
+
+	if pwm < *f.minRpmPwm {
+		return *f.minRpmPwm
+	}
+	return pwm
+}
+
+// calculateRpmTargetPwm returns the pwm value for the target RPM the given curve value stands for,
+// if the fan uses an RPM target. The pwm value is looked up in the measured curve of the fan,
+// and corrected step by step if the measured RPM differs from the target.
+func (f *PidFanController) calculateRpmTargetPwm(value int) (pwm int, ok bool) {
+	fan := f.fan
+	config := fan.GetConfig().RpmTarget
+	if config == nil {
+		return 0, false
+	}
+	curveData := fan.GetFanCurveData()
+	if !fan.Supports(fans.FeatureRpmSensor) || !fan.Supports(fans.FeatureMeasuredCurve) || curveData == nil || len(*curveData) <= 0 {
+		if !f.rpmTargetWarned {
+			logger.WithFan(fan.GetId()).Warning("Fan %s has no RPM sensor or measured curve, its curve value is used as pwm instead of as target RPM", fan.GetId())
+			f.rpmTargetWarned = true
+		}
+		return 0, false
+	}
+
+	maxRpm := float64(config.MaxRpm)
+	if maxRpm <= 0 {
+		maxRpm = fans.GetMaxRpm(*curveData)
+	}
+	tolerance := float64(config.Tolerance)
+	if tolerance <= 0 {
+		tolerance = defaultRpmTargetTolerance
+	}
+	targetRpm := float64(value) / fans.MaxPwmValue * maxRpm
+
+	pwm = fans.FindPwmForRpm(*curveData, targetRpm)
+	if targetRpm <= 0 || maxRpm <= 0 {
+		return pwm, true
+	}
+
+	// the measured curve may be outdated, f.ex. due to dust, so the rpm is checked as well
+	if f.lastSetPwm != nil {
+		diff := targetRpm - fan.GetRpmAvg()
+		if math.Abs(diff) > tolerance {
+			f.rpmTargetCorrection += rpmTargetCorrectionGain * diff / maxRpm * fans.MaxPwmValue
+			f.rpmTargetCorrection = util.Coerce(f.rpmTargetCorrection, -maxRpmTargetCorrection, maxRpmTargetCorrection)
+		}
+	}
+	return pwm + int(math.Round(f.rpmTargetCorrection)), true
+}
+
+// updateFailsafe checks whether any sensor the curve of the fan depends on has been
+// unreadable for longer than the configured timeout, and returns whether the fan is
+// in failsafe mode
+func (f *PidFanController) updateFailsafe() bool {
+	fan := f.fan
+	config := configuration.CurrentConfig.Failsafe
+
+	failingSensor := ""
+	var failingSince time.Time
+	if config.SensorTimeout > 0 {
+		curveId := profiles.GetCurveId(fan.GetId(), fan.GetCurveId())
+		sensorIds := curves.GetSensorIds(curveId)
+		// a fan held stopped by its zero rpm mode would otherwise never be started again
+		if zeroRpm := fan.GetConfig().ZeroRpm; zeroRpm != nil {
+			sensorIds = append(sensorIds, zeroRpm.Sensor)
+		}
+		for _, sensorId := range sensorIds {
+			since, failing := sensors.GetFailingSince(sensorId)
+			if failing && time.Since(since) >= config.SensorTimeout {
+				failingSensor = sensorId
+				failingSince = since
+				break
+			}
+		}
+	}
+
+	active := len(failingSensor) > 0
+	if active && !f.failsafe {
+		logger.WithFan(fan.GetId()).ErrorAndNotify("Fan Failsafe", "Fan %s enters failsafe mode, since sensor %s is unreadable since %s",
+			fan.GetId(), failingSensor, failingSince.Format(time.RFC3339))
+		alerting.Fire(alerting.EventFailsafe, fan.GetId(), "Fan %s entered failsafe mode, since sensor %s is unreadable since %s",
+			fan.GetId(), failingSensor, failingSince.Format(time.RFC3339))
+	} else if !active && f.failsafe {
+		logger.WithFan(fan.GetId()).Info("Fan %s leaves failsafe mode", fan.GetId())
+	}
+	f.failsafe = active
+	return active
+}
+
+// calculateZeroRpmPwm returns the pwm value enforced by the zero rpm mode of the fan, if any.
+// The fan is stopped once its sensor drops to stopTemp and is only started again when
+// the sensor reaches startTemp, using a short kick to get it spinning reliably.
+func (f *PidFanController) calculateZeroRpmPwm() (pwm int, active bool) {
+	fan := f.fan
+	config := fan.GetConfig().ZeroRpm
+	if config == nil {
+		return 0, false
+	}
+
+	sensor, ok := sensors.GetSensor(config.Sensor)
+	if !ok {
+		logger.WithFan(fan.GetId()).Warning("Zero rpm sensor %s of fan %s doesn't exist", config.Sensor, fan.GetId())
+		return 0, false
+	}
+	temp := sensor.GetMovingAvg() / 1000 // milli-degree to degree
+
+	now := time.Now()
+	if f.zeroRpmStopped {
+		if temp < config.StartTemp {
+			return 0, true
+		}
+		logger.WithFan(fan.GetId()).Info("Starting fan %s, since %s reached %.1f°C", fan.GetId(), config.Sensor, temp)
+		f.zeroRpmStopped = false
+		kickDuration := config.KickDuration
+		if kickDuration <= 0 {
+			kickDuration = defaultZeroRpmKickDuration
+		}
+		f.zeroRpmKickUntil = now.Add(kickDuration)
+	} else if temp <= config.StopTemp && !now.Before(f.zeroRpmKickUntil) {
+		logger.WithFan(fan.GetId()).Info("Stopping fan %s, since %s dropped to %.1f°C", fan.GetId(), config.Sensor, temp)
+		f.zeroRpmStopped = true
+		return 0, true
+	}
+
+	if now.Before(f.zeroRpmKickUntil) {
+		if config.KickPwm != nil {
+			return *config.KickPwm, true
+		}
+		return fan.GetStartPwm(), true
+	}
+
+	return 0, false
+}
+
 // set the pwm speed of a fan to the specified value (0..255)
 func (f *PidFanController) setPwm(target int) (err error) {
-	current, err := f.fan.GetPwm()
+	current, readErr := f.fan.GetPwm()
 
 	closestTarget := f.findClosestDistinctTarget(target)
 	closestExpected := f.pwmMap[closestTarget]
 
+	if f.lastSetPwm != nil && f.findClosestDistinctTarget(*f.lastSetPwm) != closestTarget {
+		polling.ReportFanActivity()
+	}
 	f.lastSetPwm = &target
-	if err == nil {
+	f.stateLock.Lock()
+	f.targetPwm = &target
+	f.stateLock.Unlock()
+	if configuration.CurrentConfig.DryRun {
+		if f.dryRunPwm == nil || *f.dryRunPwm != closestTarget {
+			logger.WithFan(f.fan.GetId()).Info("Dry run: would set pwm of fan %s to %d", f.fan.GetId(), closestTarget)
+			f.dryRunPwm = &closestTarget
+		}
+		if readErr == nil {
+			f.recordPwm(current)
+		} else {
+			f.recordPwmError(readErr)
+		}
+		return nil
+	}
+	if readErr == nil {
 		if closestExpected == current {
 			// nothing to do
+			f.recordPwm(current)
 			return nil
 		}
 	}
-	return f.fan.SetPwm(closestTarget)
+	err = f.fan.SetPwm(closestTarget)
+	if err == nil {
+		f.recordPwm(closestExpected)
+	}
+	if readErr != nil {
+		f.recordPwmError(readErr)
+	}
+	return err
 }
 
 func (f *PidFanController) waitForFanToSettle(fan fans.Fan) {
@@ -491,19 +1500,19 @@ func (f *PidFanController) waitForFanToSettle(fan fans.Fan) {
 	measuredRpmDiffMax := 2 * diffThreshold
 	oldRpm := 0
 	for !(measuredRpmDiffMax < diffThreshold) {
-		ui.Debug("Waiting for fan %s to settle (current RPM max diff: %f)...", fan.GetId(), measuredRpmDiffMax)
-		time.Sleep(1 * time.Second)
+		logger.WithFan(fan.GetId()).Debug("Waiting for fan %s to settle (current RPM max diff: %f)...", fan.GetId(), measuredRpmDiffMax)
+		time.Sleep(fanSettleInterval)
 
 		currentRpm, err := fan.GetRpm()
 		if err != nil {
-			ui.Warning("Cannot read RPM value of fan %s: %v", fan.GetId(), err)
+			logger.WithFan(fan.GetId()).Warning("Cannot read RPM value of fan %s: %v", fan.GetId(), err)
 			continue
 		}
 		measuredRpmDiffWindow.Append(math.Abs(float64(currentRpm - oldRpm)))
 		oldRpm = currentRpm
 		measuredRpmDiffMax = math.Ceil(util.GetWindowMax(measuredRpmDiffWindow))
 	}
-	ui.Debug("Fan %s has settled (current RPM max diff: %f)", fan.GetId(), measuredRpmDiffMax)
+	logger.WithFan(fan.GetId()).Debug("Fan %s has settled (current RPM max diff: %f)", fan.GetId(), measuredRpmDiffMax)
 }
 
 func (f *PidFanController) findClosestDistinctTarget(target int) int {
@@ -541,21 +1550,30 @@ func (f *PidFanController) computePwmMap() (err error) {
 	}
 
 	if configOverride != nil {
-		ui.Info("Using pwm map override from config...")
+		logger.WithFan(f.fan.GetId()).Info("Using pwm map override from config...")
 		f.pwmMap = *configOverride
 		return nil
 	}
 
 	f.pwmMap, err = f.persistence.LoadFanPwmMap(f.fan.GetId())
 	if err == nil && f.pwmMap != nil {
-		ui.Info("FanController: Using saved value for pwm map of Fan '%s'", f.fan.GetId())
+		logger.WithFan(f.fan.GetId()).Info("FanController: Using saved value for pwm map of Fan '%s'", f.fan.GetId())
+		return nil
+	}
+
+	if configuration.CurrentConfig.DryRun {
+		logger.WithFan(f.fan.GetId()).Info("Assuming a 1:1 pwm map for fan '%s' in dry-run mode", f.fan.GetId())
+		f.pwmMap = map[int]int{}
+		for i := fans.MinPwmValue; i <= fans.MaxPwmValue; i++ {
+			f.pwmMap[i] = i
+		}
 		return nil
 	}
 
-	ui.Info("Computing pwm map...")
+	logger.WithFan(f.fan.GetId()).Info("Computing pwm map...")
 	f.computePwmMapAutomatically()
 
-	ui.Debug("Saving pwm map to fan...")
+	logger.WithFan(f.fan.GetId()).Debug("Saving pwm map to fan...")
 	return f.persistence.SaveFanPwmMap(f.fan.GetId(), f.pwmMap)
 }
 
@@ -570,7 +1588,7 @@ func (f *PidFanController) computePwmMapAutomatically() {
 		time.Sleep(pwmSetGetDelay)
 		pwm, err := fan.GetPwm()
 		if err != nil {
-			ui.Warning("Error reading PWM value of fan %s: %v", fan.GetId(), err)
+			logger.WithFan(fan.GetId()).Warning("Error reading PWM value of fan %s: %v", fan.GetId(), err)
 		}
 		pwmMap[i] = pwm
 	}
@@ -584,11 +1602,13 @@ func (f *PidFanController) updateDistinctPwmValues() {
 	sort.Ints(keys)
 	f.pwmValuesWithDistinctTarget = keys
 
-	ui.Debug("Distinct PWM value targets of fan %s: %v", f.fan.GetId(), keys)
+	logger.WithFan(f.fan.GetId()).Debug("Distinct PWM value targets of fan %s: %v", f.fan.GetId(), keys)
 }
 
 func (f *PidFanController) increaseMinPwmOffset() {
 	f.minPwmOffset += 1
+	f.stateLock.Lock()
+	defer f.stateLock.Unlock()
 	f.stats.MinPwmOffset = f.minPwmOffset
 	f.stats.IncreasedMinPwmCount += 1
 }
@@ -1,6 +1,9 @@
 package controller
 
 import (
+	"errors"
+	"os"
+	"path"
 	"sort"
 	"testing"
 	"time"
@@ -8,6 +11,9 @@ import (
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/curves"
 	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/profiles"
 	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/util"
 	"github.com/stretchr/testify/assert"
@@ -56,22 +62,31 @@ func (c MockCurve) Evaluate() (value int, err error) {
 	return c.Value, nil
 }
 
+func (c MockCurve) CurrentValue() int {
+	return c.Value
+}
+
 type MockFan struct {
 	ID              string
 	PWM             int
 	MinPWM          int
+	StartPWM        int
 	RPM             int
 	curveId         string
 	shouldNeverStop bool
 	speedCurve      *map[int]float64
+	config          configuration.FanConfig
+	pwmEnabled      fans.ControlMode
+	// unmeasuredCurve indicates that speedCurve is an assumed curve, instead of a measured one
+	unmeasuredCurve bool
 }
 
 func (fan MockFan) GetStartPwm() int {
-	return 0
+	return fan.StartPWM
 }
 
 func (fan *MockFan) SetStartPwm(pwm int, force bool) {
-	panic("not supported")
+	fan.StartPWM = pwm
 }
 
 func (fan MockFan) GetMinPwm() int {
@@ -121,11 +136,12 @@ func (fan *MockFan) AttachFanCurveData(curveData *map[int]float64) (err error) {
 }
 
 func (fan MockFan) GetPwmEnabled() (int, error) {
-	panic("implement me")
+	return int(fan.pwmEnabled), nil
 }
 
 func (fan *MockFan) SetPwmEnabled(value fans.ControlMode) (err error) {
-	panic("implement me")
+	fan.pwmEnabled = value
+	return nil
 }
 
 func (fan MockFan) IsPwmAuto() (bool, error) {
@@ -136,6 +152,10 @@ func (fan MockFan) GetId() string {
 	return fan.ID
 }
 
+func (fan MockFan) GetConfig() configuration.FanConfig {
+	return fan.config
+}
+
 func (fan MockFan) GetName() string {
 	return fan.ID
 }
@@ -149,6 +169,9 @@ func (fan MockFan) ShouldNeverStop() bool {
 }
 
 func (fan MockFan) Supports(feature fans.FeatureFlag) bool {
+	if feature == fans.FeatureMeasuredCurve {
+		return !fan.unmeasuredCurve
+	}
 	return true
 }
 
@@ -217,6 +240,22 @@ func (p mockPersistence) LoadFanPwmMap(fanId string) (map[int]int, error) {
 func (p mockPersistence) SaveFanPwmMap(fanId string, pwmMap map[int]int) (err error) { return nil }
 func (p mockPersistence) DeleteFanPwmMap(fanId string) (err error)                   { return nil }
 
+func (p mockPersistence) LoadFanPwmBoundaries(fanId string) (persistence.FanPwmBoundaries, error) {
+	return persistence.FanPwmBoundaries{}, os.ErrNotExist
+}
+func (p mockPersistence) SaveFanPwmBoundaries(fanId string, boundaries persistence.FanPwmBoundaries) (err error) {
+	return nil
+}
+func (p mockPersistence) DeleteFanPwmBoundaries(fanId string) (err error) { return nil }
+
+func (p mockPersistence) LoadFanStates() (map[string]persistence.FanState, error) {
+	return map[string]persistence.FanState{}, nil
+}
+func (p mockPersistence) SaveFanState(fanId string, state persistence.FanState) (err error) {
+	return nil
+}
+func (p mockPersistence) DeleteFanState(fanId string) (err error) { return nil }
+
 func createOneToOnePwmMap() map[int]int {
 	var pwmMap = map[int]int{}
 	for i := fans.MinPwmValue; i <= fans.MaxPwmValue; i++ {
@@ -241,7 +280,7 @@ func CreateFan(neverStop bool, curveData map[int]float64, startPwm *int) (fan fa
 		},
 		StartPwm: startPwm,
 	}
-	fans.FanMap[fan.GetId()] = fan
+	fans.SetFan(fan.GetId(), fan)
 
 	err = fan.AttachFanCurveData(&curveData)
 
@@ -304,14 +343,14 @@ func TestCalculateTargetSpeedLinear(t *testing.T) {
 		Name:      "sensor",
 		MovingAvg: avgTmp,
 	}
-	sensors.SensorMap[s.GetId()] = &s
+	sensors.SetSensor(s.GetId(), &s)
 
 	curveValue := 127
 	curve := MockCurve{
 		ID:    "curve",
 		Value: curveValue,
 	}
-	curves.SpeedCurveMap[curve.GetId()] = &curve
+	curves.SetSpeedCurve(curve.GetId(), &curve)
 
 	fan := &MockFan{
 		ID:              "fan",
@@ -320,12 +359,11 @@ func TestCalculateTargetSpeedLinear(t *testing.T) {
 		curveId:         curve.GetId(),
 		speedCurve:      &LinearFan,
 	}
-	fans.FanMap[fan.GetId()] = fan
+	fans.SetFan(fan.GetId(), fan)
 
 	controller := PidFanController{
 		persistence: mockPersistence{},
 		fan:         fan,
-		curve:       curve,
 		updateRate:  time.Duration(100),
 		pwmMap:      createOneToOnePwmMap(),
 	}
@@ -347,14 +385,14 @@ func TestCalculateTargetSpeedNeverStop(t *testing.T) {
 		Name:      "sensor",
 		MovingAvg: avgTmp,
 	}
-	sensors.SensorMap[s.GetId()] = &s
+	sensors.SetSensor(s.GetId(), &s)
 
 	curveValue := 0
 	curve := &MockCurve{
 		ID:    "curve",
 		Value: curveValue,
 	}
-	curves.SpeedCurveMap[curve.GetId()] = curve
+	curves.SetSpeedCurve(curve.GetId(), curve)
 
 	fan := &MockFan{
 		ID:              "fan",
@@ -365,11 +403,10 @@ func TestCalculateTargetSpeedNeverStop(t *testing.T) {
 		shouldNeverStop: true,
 		speedCurve:      &NeverStoppingFan,
 	}
-	fans.FanMap[fan.GetId()] = fan
+	fans.SetFan(fan.GetId(), fan)
 
 	controller := PidFanController{
 		persistence: mockPersistence{}, fan: fan,
-		curve:      curve,
 		updateRate: time.Duration(100),
 		pwmMap:     createOneToOnePwmMap(),
 	}
@@ -417,14 +454,14 @@ func TestFanController_UpdateFanSpeed_FanCurveGaps(t *testing.T) {
 		Name:      "sensor",
 		MovingAvg: avgTmp,
 	}
-	sensors.SensorMap[s.GetId()] = &s
+	sensors.SetSensor(s.GetId(), &s)
 
 	curveValue := 5
 	curve := &MockCurve{
 		ID:    "curve",
 		Value: curveValue,
 	}
-	curves.SpeedCurveMap[curve.GetId()] = curve
+	curves.SetSpeedCurve(curve.GetId(), curve)
 
 	fan := &MockFan{
 		ID:              "fan",
@@ -435,7 +472,7 @@ func TestFanController_UpdateFanSpeed_FanCurveGaps(t *testing.T) {
 		shouldNeverStop: true,
 		speedCurve:      &DutyCycleFan,
 	}
-	fans.FanMap[fan.GetId()] = fan
+	fans.SetFan(fan.GetId(), fan)
 
 	var keys []int
 	for pwm := range DutyCycleFan {
@@ -455,7 +492,6 @@ func TestFanController_UpdateFanSpeed_FanCurveGaps(t *testing.T) {
 
 	controller := PidFanController{
 		persistence: mockPersistence{}, fan: fan,
-		curve:      curve,
 		updateRate: time.Duration(100),
 		pwmMap:     pwmMap,
 	}
@@ -470,3 +506,1399 @@ func TestFanController_UpdateFanSpeed_FanCurveGaps(t *testing.T) {
 	closestTarget := controller.findClosestDistinctTarget(targetPwm)
 	assert.Equal(t, 58, closestTarget)
 }
+
+func TestOverrideReplacesCurveValue(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 127,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	fan := &MockFan{
+		ID:              "fan",
+		PWM:             0,
+		shouldNeverStop: false,
+		curveId:         curve.GetId(),
+		speedCurve:      &LinearFan,
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		pwmMap:      createOneToOnePwmMap(),
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	controller.SetOverride(200, 0)
+	overridden := controller.calculateTargetPwm()
+	controller.ClearOverride()
+	cleared := controller.calculateTargetPwm()
+
+	// THEN
+	assert.Equal(t, 200, overridden)
+	assert.Equal(t, 127, cleared)
+}
+
+func TestOverrideExpires(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{
+		ID: "fan",
+	}
+	controller := PidFanController{
+		fan: fan,
+	}
+
+	// WHEN
+	controller.SetOverride(200, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// THEN
+	assert.Nil(t, controller.GetOverride())
+}
+
+func TestProfileReplacesCurveAndCapsPwm(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 127,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+	silentCurve := MockCurve{
+		ID:    "silent_curve",
+		Value: 200,
+	}
+	curves.SetSpeedCurve(silentCurve.GetId(), &silentCurve)
+
+	fan := &MockFan{
+		ID:              "fan",
+		PWM:             0,
+		shouldNeverStop: false,
+		curveId:         curve.GetId(),
+		speedCurve:      &LinearFan,
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	maxPwm := 150
+	configuration.CurrentConfig.Profiles = []configuration.ProfileConfig{
+		{
+			ID: "silent",
+			Fans: []configuration.ProfileFanConfig{
+				{Fan: fan.GetId(), Curve: silentCurve.GetId(), MaxPwm: &maxPwm},
+			},
+		},
+	}
+	defer func() {
+		_ = profiles.SetActive("")
+		configuration.CurrentConfig.Profiles = nil
+	}()
+
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		pwmMap:      createOneToOnePwmMap(),
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	regular := controller.applyPwmLimit(controller.calculateTargetPwm())
+	_ = profiles.SetActive("silent")
+	silent := controller.applyPwmLimit(controller.calculateTargetPwm())
+
+	// THEN
+	assert.Equal(t, 127, regular)
+	assert.Equal(t, 150, silent)
+}
+
+func TestProfileCapsMappedPwm(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 255,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	fan := &MockFan{
+		ID:              "fan",
+		PWM:             0,
+		MinPWM:          100,
+		shouldNeverStop: false,
+		curveId:         curve.GetId(),
+		speedCurve:      &LinearFan,
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	maxPwm := 150
+	configuration.CurrentConfig.Profiles = []configuration.ProfileConfig{
+		{
+			ID: "silent",
+			Fans: []configuration.ProfileFanConfig{
+				{Fan: fan.GetId(), MaxPwm: &maxPwm},
+			},
+		},
+	}
+	_ = profiles.SetActive("silent")
+	defer func() {
+		_ = profiles.SetActive("")
+		configuration.CurrentConfig.Profiles = nil
+	}()
+
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		pwmMap:      createOneToOnePwmMap(),
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	target := controller.calculateTargetPwm()
+	limited := controller.applyPwmLimit(target)
+
+	// THEN
+	// the cap applies to the pwm value mapped to [minPwm..maxPwm], not to the curve value
+	assert.Equal(t, 255, target)
+	assert.Equal(t, 150, limited)
+}
+
+func TestPwmLimit(t *testing.T) {
+	// GIVEN
+	globalLimit := 200
+	configuration.CurrentConfig.PwmLimit = &globalLimit
+	defer func() {
+		configuration.CurrentConfig.PwmLimit = nil
+	}()
+
+	fanLimit := 120
+	limited := PidFanController{
+		fan: &MockFan{
+			ID:     "limited",
+			config: configuration.FanConfig{PwmLimit: &fanLimit},
+		},
+	}
+	regular := PidFanController{
+		fan: &MockFan{
+			ID: "regular",
+		},
+	}
+
+	// WHEN
+	limitedPwm := limited.applyPwmLimit(255)
+	regularPwm := regular.applyPwmLimit(255)
+	lowPwm := limited.applyPwmLimit(50)
+
+	// THEN
+	assert.Equal(t, 120, limitedPwm)
+	assert.Equal(t, 200, regularPwm)
+	assert.Equal(t, 50, lowPwm)
+}
+
+func TestPwmLimitAppliesToUpdateFanSpeed(t *testing.T) {
+	// GIVEN
+	fanLimit := 120
+	fan := &MockFan{
+		ID:     "fan",
+		PWM:    250,
+		config: configuration.FanConfig{PwmLimit: &fanLimit},
+	}
+	controller := PidFanController{
+		fan:     fan,
+		pwmMap:  createOneToOnePwmMap(),
+		pidLoop: util.NewPidLoop(0.03, 0.002, 0.0005),
+	}
+	controller.updateDistinctPwmValues()
+	controller.SetOverride(255, 0)
+
+	// WHEN
+	err := controller.UpdateFanSpeed()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 120, fan.PWM)
+}
+
+func TestNightModeCapsMappedPwm(t *testing.T) {
+	// GIVEN
+	now := time.Now()
+	configuration.CurrentConfig.NightMode = configuration.NightModeConfig{
+		Enabled: true,
+		From:    now.Add(-time.Hour).Format("15:04"),
+		To:      now.Add(time.Hour).Format("15:04"),
+		Fans: []configuration.NightModeFanConfig{
+			{Fan: "fan", MaxPwm: 100},
+		},
+	}
+	defer func() {
+		configuration.CurrentConfig.NightMode = configuration.NightModeConfig{}
+	}()
+
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 255,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	curveData := util.InterpolateLinearly(&map[int]float64{0: 0, 255: 2000}, 0, 255)
+	fan := &MockFan{
+		ID:         "fan",
+		RPM:        1000,
+		curveId:    curve.GetId(),
+		speedCurve: &curveData,
+		config: configuration.FanConfig{
+			RpmTarget: &configuration.RpmTargetConfig{MaxRpm: 1000},
+		},
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		pwmMap:      createOneToOnePwmMap(),
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	target := controller.calculateTargetPwm()
+	limited := controller.applyPwmLimit(target)
+
+	// THEN
+	// the cap applies to the mapped pwm value, not to the target rpm of the curve
+	assert.Equal(t, 128, target)
+	assert.Equal(t, 100, limited)
+}
+
+func TestRpmTargetUsesMeasuredCurve(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 255,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	curveData := util.InterpolateLinearly(&map[int]float64{0: 0, 255: 2000}, 0, 255)
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        0,
+		RPM:        1000,
+		curveId:    curve.GetId(),
+		speedCurve: &curveData,
+		config: configuration.FanConfig{
+			RpmTarget: &configuration.RpmTargetConfig{MaxRpm: 1000},
+		},
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		pwmMap:      createOneToOnePwmMap(),
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	full := controller.calculateTargetPwm()
+	curve.Value = 0
+	stopped := controller.calculateTargetPwm()
+
+	// THEN
+	assert.Equal(t, 128, full)
+	assert.Equal(t, 0, stopped)
+}
+
+func TestRpmTargetWithoutMeasuredCurve(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 128,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	curveData := util.InterpolateLinearly(&map[int]float64{0: 0, 255: 255}, 0, 255)
+	fan := &MockFan{
+		ID:              "fan",
+		RPM:             1000,
+		curveId:         curve.GetId(),
+		speedCurve:      &curveData,
+		unmeasuredCurve: true,
+		config: configuration.FanConfig{
+			RpmTarget: &configuration.RpmTargetConfig{MaxRpm: 2000},
+		},
+	}
+	controller := PidFanController{
+		fan: fan,
+	}
+
+	// WHEN
+	_, ok := controller.calculateRpmTargetPwm(curve.Value)
+
+	// THEN
+	assert.False(t, ok)
+	assert.True(t, controller.rpmTargetWarned)
+}
+
+func TestRpmTargetCorrectsSlowFan(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 255,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	curveData := util.InterpolateLinearly(&map[int]float64{0: 0, 255: 2000}, 0, 255)
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        128,
+		RPM:        800,
+		curveId:    curve.GetId(),
+		speedCurve: &curveData,
+		config: configuration.FanConfig{
+			RpmTarget: &configuration.RpmTargetConfig{MaxRpm: 1000},
+		},
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	lastSetPwm := 128
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		pwmMap:      createOneToOnePwmMap(),
+		lastSetPwm:  &lastSetPwm,
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	first := controller.calculateTargetPwm()
+	second := controller.calculateTargetPwm()
+	fan.RPM = 1020
+	settled := controller.calculateTargetPwm()
+
+	// THEN
+	assert.Equal(t, 133, first)
+	assert.Equal(t, 138, second)
+	assert.Equal(t, 138, settled)
+}
+
+func TestMinRpmUsesMeasuredCurve(t *testing.T) {
+	// GIVEN
+	curveData := util.InterpolateLinearly(&map[int]float64{0: 0, 255: 2000}, 0, 255)
+	fan := &MockFan{
+		ID:         "fan",
+		RPM:        600,
+		speedCurve: &curveData,
+		config:     configuration.FanConfig{MinRpm: 500},
+	}
+	controller := PidFanController{
+		fan: fan,
+	}
+
+	// WHEN
+	raised := controller.applyMinRpm(20, fans.MaxPwmValue)
+	unchanged := controller.applyMinRpm(100, fans.MaxPwmValue)
+	stopped := controller.applyMinRpm(0, fans.MaxPwmValue)
+
+	// THEN
+	assert.Equal(t, 64, raised)
+	assert.Equal(t, 100, unchanged)
+	assert.Equal(t, 0, stopped)
+}
+
+func TestMinRpmWithoutMeasuredCurve(t *testing.T) {
+	// GIVEN
+	curveData := util.InterpolateLinearly(&map[int]float64{0: 0, 255: 255}, 0, 255)
+	fan := &MockFan{
+		ID:              "fan",
+		RPM:             100,
+		speedCurve:      &curveData,
+		unmeasuredCurve: true,
+		config:          configuration.FanConfig{MinRpm: 500},
+	}
+	controller := PidFanController{
+		fan: fan,
+	}
+
+	// WHEN
+	result := controller.applyMinRpm(20, fans.MaxPwmValue)
+
+	// THEN
+	assert.Equal(t, 20, result)
+	assert.True(t, controller.minRpmUnsupportedWarned)
+}
+
+func TestMeasureRpmKeepsUnmeasuredCurve(t *testing.T) {
+	// GIVEN
+	pwmPath := path.Join(t.TempDir(), "pwm")
+	_ = os.WriteFile(pwmPath, []byte("100"), 0644)
+	fan := &fans.FileFan{
+		Config: configuration.FanConfig{
+			ID:   "file_fan",
+			File: &configuration.FileFanConfig{Path: pwmPath},
+		},
+	}
+
+	// WHEN
+	pwm, rpm, err := measureRpm(fan)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 100, pwm)
+	assert.Equal(t, 0, rpm)
+	assert.Equal(t, 100.0, (*fan.GetFanCurveData())[100])
+}
+
+func TestMinRpmRaisesPwmOfSlowFan(t *testing.T) {
+	// GIVEN
+	curveData := util.InterpolateLinearly(&map[int]float64{0: 0, 255: 2000}, 0, 255)
+	fan := &MockFan{
+		ID:         "fan",
+		RPM:        400,
+		speedCurve: &curveData,
+		config:     configuration.FanConfig{MinRpm: 500},
+	}
+	lastSetPwm := 64
+	controller := PidFanController{
+		fan:        fan,
+		lastSetPwm: &lastSetPwm,
+	}
+
+	// WHEN
+	first := controller.applyMinRpm(20, fans.MaxPwmValue)
+	second := controller.applyMinRpm(20, fans.MaxPwmValue)
+	fan.RPM = 520
+	settled := controller.applyMinRpm(20, fans.MaxPwmValue)
+	fan.RPM = 600
+	lowered := controller.applyMinRpm(20, fans.MaxPwmValue)
+
+	// THEN
+	assert.Equal(t, 65, first)
+	assert.Equal(t, 66, second)
+	assert.Equal(t, 66, settled)
+	assert.Equal(t, 65, lowered)
+}
+
+func TestMinRpmAtMaxPwm(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{
+		ID:     "fan",
+		RPM:    0,
+		config: configuration.FanConfig{MinRpm: 500},
+	}
+	lastSetPwm := 0
+	controller := PidFanController{
+		fan:        fan,
+		lastSetPwm: &lastSetPwm,
+	}
+
+	// WHEN
+	var pwm int
+	for i := 0; i < 300; i++ {
+		pwm = controller.applyMinRpm(20, fans.MaxPwmValue)
+	}
+
+	// THEN
+	assert.Equal(t, fans.MaxPwmValue, pwm)
+	assert.True(t, controller.minRpmWarned)
+}
+
+func TestFindStartAndMinPwm(t *testing.T) {
+	// GIVEN
+	var pwmValues []int
+	for pwm := fans.MinPwmValue; pwm <= fans.MaxPwmValue; pwm++ {
+		pwmValues = append(pwmValues, pwm)
+	}
+	// the fan starts at a pwm of 40 and keeps spinning down to 25
+	var startAttempts []int
+	startsFan := func(pwm int) (bool, error) {
+		startAttempts = append(startAttempts, pwm)
+		return pwm >= 40, nil
+	}
+	keepsSpinning := func(pwm int) (bool, error) {
+		return pwm >= 25, nil
+	}
+
+	// WHEN
+	startPwm, err := findStartPwm(pwmValues, 37, startsFan)
+	assert.NoError(t, err)
+	minPwm, err := findMinPwm(pwmValues, startPwm, keepsSpinning)
+	assert.NoError(t, err)
+
+	// THEN
+	assert.Equal(t, []int{37, 38, 39, 40}, startAttempts)
+	assert.Equal(t, 40, startPwm)
+	assert.Equal(t, 25, minPwm)
+}
+
+func TestFindStartPwmOfBrokenFan(t *testing.T) {
+	// GIVEN
+	pwmValues := []int{0, 100, 200, 255}
+
+	// WHEN
+	_, err := findStartPwm(pwmValues, 100, func(pwm int) (bool, error) {
+		return false, nil
+	})
+
+	// THEN
+	assert.EqualError(t, err, "fan didn't start spinning at any pwm value")
+}
+
+func TestMeasuredPwmBoundariesMapCurveValues(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 0,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	fan := &MockFan{
+		ID:      "fan",
+		PWM:     100,
+		RPM:     800,
+		curveId: curve.GetId(),
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	lastSetPwm := 100
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		pwmMap:      createOneToOnePwmMap(),
+		lastSetPwm:  &lastSetPwm,
+	}
+	controller.updateDistinctPwmValues()
+	controller.applyPwmBoundaries(persistence.FanPwmBoundaries{StartPwm: 60, MinPwm: 40})
+
+	// WHEN
+	stopped := controller.calculateTargetPwm()
+	curve.Value = 1
+	lowest := controller.calculateTargetPwm()
+	curve.Value = 255
+	highest := controller.calculateTargetPwm()
+
+	// THEN
+	assert.Equal(t, 60, fan.GetStartPwm())
+	assert.Equal(t, 0, stopped)
+	assert.Equal(t, 40, lowest)
+	assert.Equal(t, 255, highest)
+}
+
+func TestMeasuredStartPwmStartsStoppedFan(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 1,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	fan := &MockFan{
+		ID:      "fan",
+		PWM:     0,
+		RPM:     0,
+		curveId: curve.GetId(),
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	lastSetPwm := 0
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		pwmMap:      createOneToOnePwmMap(),
+		lastSetPwm:  &lastSetPwm,
+	}
+	controller.updateDistinctPwmValues()
+	controller.applyPwmBoundaries(persistence.FanPwmBoundaries{StartPwm: 60, MinPwm: 40})
+
+	// WHEN
+	starting := controller.calculateTargetPwm()
+	lastSetPwm = 60
+	fan.RPM = 500
+	running := controller.calculateTargetPwm()
+
+	// THEN
+	assert.Equal(t, 60, starting)
+	assert.Equal(t, 40, running)
+}
+
+func TestZeroRpmStopsAndKicksFan(t *testing.T) {
+	// GIVEN
+	s := MockSensor{
+		ID:        "zero_rpm_sensor",
+		Name:      "zero_rpm_sensor",
+		MovingAvg: 35000,
+	}
+	sensors.SetSensor(s.GetId(), &s)
+
+	kickPwm := 180
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        100,
+		speedCurve: &LinearFan,
+		config: configuration.FanConfig{
+			ID: "fan",
+			ZeroRpm: &configuration.ZeroRpmConfig{
+				Sensor:       s.GetId(),
+				StopTemp:     40,
+				StartTemp:    50,
+				KickPwm:      &kickPwm,
+				KickDuration: time.Hour,
+			},
+		},
+	}
+
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		pwmMap:      createOneToOnePwmMap(),
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	stopPwm, stopActive := controller.calculateZeroRpmPwm()
+	s.SetMovingAvg(45000)
+	hysteresisPwm, hysteresisActive := controller.calculateZeroRpmPwm()
+	s.SetMovingAvg(50000)
+	kickPwmValue, kickActive := controller.calculateZeroRpmPwm()
+	s.SetMovingAvg(35000)
+	kickedPwmValue, kickedActive := controller.calculateZeroRpmPwm()
+
+	// THEN
+	assert.True(t, stopActive)
+	assert.Equal(t, 0, stopPwm)
+	assert.True(t, hysteresisActive)
+	assert.Equal(t, 0, hysteresisPwm)
+	assert.True(t, kickActive)
+	assert.Equal(t, kickPwm, kickPwmValue)
+	// the fan is not stopped again while the kick is applied
+	assert.True(t, kickedActive)
+	assert.Equal(t, kickPwm, kickedPwmValue)
+}
+
+func TestZeroRpmInactiveWhenWarm(t *testing.T) {
+	// GIVEN
+	s := MockSensor{
+		ID:        "zero_rpm_sensor",
+		Name:      "zero_rpm_sensor",
+		MovingAvg: 45000,
+	}
+	sensors.SetSensor(s.GetId(), &s)
+
+	fan := &MockFan{
+		ID: "fan",
+		config: configuration.FanConfig{
+			ID: "fan",
+			ZeroRpm: &configuration.ZeroRpmConfig{
+				Sensor:    s.GetId(),
+				StopTemp:  40,
+				StartTemp: 50,
+			},
+		},
+	}
+	controller := PidFanController{
+		fan: fan,
+	}
+
+	// WHEN
+	_, active := controller.calculateZeroRpmPwm()
+
+	// THEN
+	assert.False(t, active)
+}
+
+func TestStallDetectionKicksFan(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.StallDetection = configuration.StallDetectionConfig{
+		Enabled:      true,
+		Cycles:       3,
+		KickPwm:      255,
+		KickDuration: time.Hour,
+	}
+	defer func() {
+		configuration.CurrentConfig.StallDetection = configuration.StallDetectionConfig{}
+	}()
+
+	fan := &MockFan{
+		ID: "fan",
+	}
+	controller := PidFanController{
+		fan: fan,
+	}
+
+	// WHEN
+	controller.detectStall(100, 0)
+	controller.detectStall(100, 0)
+	kickedEarly := controller.isStallKickActive()
+	controller.detectStall(100, 0)
+
+	// THEN
+	assert.False(t, kickedEarly)
+	assert.True(t, controller.isStallKickActive())
+	assert.Equal(t, 1, controller.GetStatistics().StallCount)
+}
+
+func TestStallDetectionIgnoresSpinningFan(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.StallDetection = configuration.StallDetectionConfig{
+		Enabled:      true,
+		Cycles:       2,
+		KickPwm:      255,
+		KickDuration: time.Hour,
+	}
+	defer func() {
+		configuration.CurrentConfig.StallDetection = configuration.StallDetectionConfig{}
+	}()
+
+	fan := &MockFan{
+		ID: "fan",
+	}
+	controller := PidFanController{
+		fan: fan,
+	}
+
+	// WHEN
+	controller.detectStall(100, 0)
+	controller.detectStall(100, 800)
+	controller.detectStall(100, 0)
+	controller.detectStall(0, 0)
+
+	// THEN
+	assert.False(t, controller.isStallKickActive())
+	assert.Equal(t, 0, controller.GetStatistics().StallCount)
+}
+
+func TestUpdateFanSpeedReportsState(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{
+		ID:  "fan",
+		PWM: 100,
+	}
+	controller := PidFanController{
+		fan:            fan,
+		pwmMap:         createOneToOnePwmMap(),
+		pidLoop:        util.NewPidLoop(0.03, 0.002, 0.0005),
+		stallKickUntil: time.Now().Add(time.Hour),
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	err := controller.UpdateFanSpeed()
+	kicked := controller.GetState()
+	controller.stallKickUntil = time.Time{}
+	controller.SetOverride(200, 0)
+	err2 := controller.UpdateFanSpeed()
+	overridden := controller.GetState()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Equal(t, ControllerStateStallKick, kicked)
+	assert.Equal(t, ControllerStateOverride, overridden)
+}
+
+func TestUpdateFanSpeedKeepsPwmWithoutCurve(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{
+		ID:      "fan",
+		PWM:     100,
+		curveId: "missing_curve",
+	}
+	controller := PidFanController{
+		fan:     fan,
+		pwmMap:  createOneToOnePwmMap(),
+		pidLoop: util.NewPidLoop(0.03, 0.002, 0.0005),
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	var errs []error
+	for i := 0; i < 3; i++ {
+		errs = append(errs, controller.UpdateFanSpeed())
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// THEN
+	assert.Equal(t, []error{nil, nil, nil}, errs)
+	assert.Equal(t, 100, fan.PWM)
+	assert.Nil(t, controller.GetTargetPwm())
+}
+
+func TestGetTargetPwm(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{
+		ID:  "fan",
+		PWM: 100,
+	}
+	controller := PidFanController{
+		fan:    fan,
+		pwmMap: createOneToOnePwmMap(),
+	}
+	controller.updateDistinctPwmValues()
+	initial := controller.GetTargetPwm()
+
+	// WHEN
+	controller.setPwmDirectly(150)
+	target := controller.GetTargetPwm()
+
+	// THEN
+	assert.Nil(t, initial)
+	assert.NotNil(t, target)
+	assert.Equal(t, 150, *target)
+}
+
+func TestFailsafeOnUnreadableSensor(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.Failsafe = configuration.FailsafeConfig{
+		SensorTimeout: time.Millisecond,
+		Pwm:           255,
+	}
+	configuration.CurrentConfig.Curves = []configuration.CurveConfig{
+		{ID: "failsafe_curve", Linear: &configuration.LinearCurveConfig{Sensor: "failsafe_sensor"}},
+	}
+	defer func() {
+		configuration.CurrentConfig.Failsafe = configuration.FailsafeConfig{}
+		configuration.CurrentConfig.Curves = nil
+		sensors.ReportReadResult("failsafe_sensor", nil)
+	}()
+
+	fan := &MockFan{
+		ID:      "fan",
+		curveId: "failsafe_curve",
+	}
+	controller := PidFanController{
+		fan: fan,
+	}
+
+	// WHEN
+	beforeFailure := controller.updateFailsafe()
+	sensors.ReportReadResult("failsafe_sensor", errors.New("unreadable"))
+	time.Sleep(5 * time.Millisecond)
+	afterTimeout := controller.updateFailsafe()
+	sensors.ReportReadResult("failsafe_sensor", nil)
+	afterRecovery := controller.updateFailsafe()
+
+	// THEN
+	assert.False(t, beforeFailure)
+	assert.True(t, afterTimeout)
+	assert.False(t, afterRecovery)
+}
+
+func TestFailsafeOnUnreadableZeroRpmSensor(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.Failsafe = configuration.FailsafeConfig{
+		SensorTimeout: time.Millisecond,
+		Pwm:           255,
+	}
+	configuration.CurrentConfig.Curves = []configuration.CurveConfig{
+		{ID: "failsafe_curve", Linear: &configuration.LinearCurveConfig{Sensor: "failsafe_sensor"}},
+	}
+	defer func() {
+		configuration.CurrentConfig.Failsafe = configuration.FailsafeConfig{}
+		configuration.CurrentConfig.Curves = nil
+		sensors.ReportReadResult("zero_rpm_sensor", nil)
+	}()
+
+	fan := &MockFan{
+		ID:      "fan",
+		curveId: "failsafe_curve",
+		config: configuration.FanConfig{
+			ZeroRpm: &configuration.ZeroRpmConfig{Sensor: "zero_rpm_sensor"},
+		},
+	}
+	controller := PidFanController{
+		fan: fan,
+	}
+
+	// WHEN
+	beforeFailure := controller.updateFailsafe()
+	sensors.ReportReadResult("zero_rpm_sensor", errors.New("unreadable"))
+	time.Sleep(5 * time.Millisecond)
+	afterTimeout := controller.updateFailsafe()
+
+	// THEN
+	assert.False(t, beforeFailure)
+	assert.True(t, afterTimeout)
+}
+
+func TestReinitializeRestoresLastPwm(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 100,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        0,
+		curveId:    curve.GetId(),
+		speedCurve: &LinearFan,
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+		updateRate:  time.Duration(100),
+		pwmMap:      createOneToOnePwmMap(),
+		pidLoop:     util.NewPidLoop(0.03, 0.002, 0.0005),
+	}
+	controller.updateDistinctPwmValues()
+	lastSetPwm := 120
+	controller.lastSetPwm = &lastSetPwm
+
+	// WHEN
+	controller.Reinitialize()
+	controller.reinitialize()
+
+	// THEN
+	assert.Equal(t, 120, fan.PWM)
+	assert.Equal(t, fans.ControlModePWM, fan.pwmEnabled)
+}
+
+func TestStuckPwmReassertsPwmEnable(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.StuckPwmDetection = configuration.StuckPwmDetectionConfig{
+		Enabled:           true,
+		Cycles:            2,
+		ReassertPwmEnable: true,
+	}
+	defer func() {
+		configuration.CurrentConfig.StuckPwmDetection = configuration.StuckPwmDetectionConfig{}
+	}()
+
+	// the BIOS took over control of the fan
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        200,
+		pwmEnabled: fans.ControlModeAutomatic,
+	}
+	lastSetPwm := 100
+	controller := PidFanController{
+		fan:        fan,
+		pwmMap:     createOneToOnePwmMap(),
+		lastSetPwm: &lastSetPwm,
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	controller.detectStuckPwm()
+	diagnosis := controller.diagnoseStuckPwm(100, 200)
+
+	// THEN
+	assert.Equal(t, 200, fan.PWM)
+	assert.Equal(t, "its control mode (pwm_enable) is 2 instead of 1, the BIOS or another program took over control of the fan", diagnosis)
+
+	// WHEN
+	controller.detectStuckPwm()
+
+	// THEN
+	assert.Equal(t, 100, fan.PWM)
+	assert.Equal(t, fans.ControlModePWM, fan.pwmEnabled)
+	assert.Equal(t, 2, controller.GetStatistics().UnexpectedPwmValueCount)
+
+	// WHEN
+	controller.detectStuckPwm()
+
+	// THEN
+	assert.Equal(t, 0, controller.stuckPwmCycles)
+}
+
+func TestStuckPwmDiagnosis(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.StuckPwmDetection = configuration.StuckPwmDetectionConfig{
+		Enabled: true,
+		Cycles:  3,
+	}
+	defer func() {
+		configuration.CurrentConfig.StuckPwmDetection = configuration.StuckPwmDetectionConfig{}
+	}()
+
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        255,
+		pwmEnabled: fans.ControlModePWM,
+	}
+	lastSetPwm := 100
+	controller := PidFanController{
+		fan:        fan,
+		pwmMap:     createOneToOnePwmMap(),
+		lastSetPwm: &lastSetPwm,
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	controller.detectStuckPwm()
+	controller.detectStuckPwm()
+	ignored := controller.diagnoseStuckPwm(100, 255)
+	fan.PWM = 96
+	controller.detectStuckPwm()
+	changed := controller.diagnoseStuckPwm(100, 96)
+
+	// THEN
+	assert.Equal(t, "the written value 100 is ignored and the pwm stays at 255, f.ex. because the BIOS fan control is still active", ignored)
+	assert.Equal(t, "the written value 100 is changed to 96, f.ex. because the driver limits the pwm values", changed)
+	// without reassertPwmEnable the pwm isn't written again
+	assert.Equal(t, 96, fan.PWM)
+}
+
+func TestConfiguredPwmEnableModes(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 100,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	manual := 5
+	restore := 2
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        0,
+		curveId:    curve.GetId(),
+		speedCurve: &LinearFan,
+		pwmEnabled: fans.ControlModePWM,
+		config: configuration.FanConfig{
+			PwmEnable: &configuration.PwmEnableConfig{
+				Manual:  &manual,
+				Restore: &restore,
+			},
+		},
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	controller := PidFanController{
+		persistence:        mockPersistence{},
+		fan:                fan,
+		updateRate:         time.Duration(100),
+		pwmMap:             createOneToOnePwmMap(),
+		pidLoop:            util.NewPidLoop(0.03, 0.002, 0.0005),
+		originalPwmEnabled: fans.ControlModePWM,
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	err := controller.UpdateFanSpeed()
+	manualMode := fan.pwmEnabled
+	controller.restorePwmEnabled()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, fans.ControlMode(5), manualMode)
+	assert.Equal(t, fans.ControlModeAutomatic, fan.pwmEnabled)
+}
+
+func TestDryRunNeverWritesPwm(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.DryRun = true
+	defer func() {
+		configuration.CurrentConfig.DryRun = false
+	}()
+
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 200,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        50,
+		curveId:    curve.GetId(),
+		speedCurve: &LinearFan,
+		pwmEnabled: fans.ControlModeAutomatic,
+	}
+	fans.SetFan(fan.GetId(), fan)
+
+	controller := PidFanController{
+		persistence:        mockPersistence{},
+		fan:                fan,
+		updateRate:         time.Duration(100),
+		pwmMap:             createOneToOnePwmMap(),
+		pidLoop:            util.NewPidLoop(0.03, 0.002, 0.0005),
+		originalPwmValue:   50,
+		originalPwmEnabled: fans.ControlModeAutomatic,
+	}
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	for i := 0; i < 5; i++ {
+		err := controller.UpdateFanSpeed()
+		assert.NoError(t, err)
+	}
+	controller.SetOverride(255, 0)
+	err := controller.UpdateFanSpeed()
+	assert.NoError(t, err)
+	controller.reinitialize()
+	controller.restorePwmEnabled()
+	initErr := controller.RunInitializationSequence()
+
+	// THEN
+	assert.Equal(t, 50, fan.PWM)
+	assert.Equal(t, fans.ControlModeAutomatic, fan.pwmEnabled)
+	assert.NotNil(t, controller.lastSetPwm)
+	assert.Error(t, initErr)
+}
+
+func TestRestoreFanState(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        255,
+		speedCurve: &LinearFan,
+		pwmEnabled: fans.ControlModePWM,
+	}
+	pwmEnabled := int(fans.ControlModeAutomatic)
+
+	// WHEN
+	err := RestoreFanState(fan, persistence.FanState{Pwm: 80, PwmEnabled: &pwmEnabled})
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 80, fan.PWM)
+	assert.Equal(t, fans.ControlModeAutomatic, fan.pwmEnabled)
+}
+
+func TestRestoreFanState_ManualModeFallsBackToMaxPwm(t *testing.T) {
+	// GIVEN
+	fan := &MockFan{
+		ID:         "fan",
+		PWM:        100,
+		speedCurve: &LinearFan,
+		pwmEnabled: fans.ControlModePWM,
+	}
+	pwmEnabled := int(fans.ControlModePWM)
+
+	// WHEN
+	err := RestoreFanState(fan, persistence.FanState{Pwm: 80, PwmEnabled: &pwmEnabled})
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, fans.MaxPwmValue, fan.PWM)
+}
+
+// createPwmModeFan creates a hwmon fan with the given configured pwm mode, whose pwm_mode file contains the given value
+func createPwmModeFan(t *testing.T, pwmMode string, value string) *fans.HwMonFan {
+	dir := t.TempDir()
+	fan := &fans.HwMonFan{
+		Config: configuration.FanConfig{
+			ID: "fan",
+			HwMon: &configuration.HwMonFanConfig{
+				PwmMode:     pwmMode,
+				PwmPath:     path.Join(dir, "pwm1"),
+				PwmModePath: path.Join(dir, "pwm1_mode"),
+			},
+		},
+	}
+	_ = os.WriteFile(fan.Config.HwMon.PwmPath, []byte("255"), 0644)
+	_ = os.WriteFile(fan.Config.HwMon.PwmModePath, []byte(value), 0644)
+	return fan
+}
+
+func TestApplyPwmMode(t *testing.T) {
+	// GIVEN
+	fan := createPwmModeFan(t, configuration.PwmModeDC, "1")
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+	}
+
+	// WHEN
+	controller.applyPwmMode()
+
+	// THEN
+	mode, err := fan.GetPwmMode()
+	assert.NoError(t, err)
+	assert.Equal(t, fans.PwmModeDC, mode)
+	assert.Equal(t, fans.PwmModePWM, *controller.originalPwmMode)
+
+	// WHEN
+	controller.restorePwmMode()
+
+	// THEN
+	mode, err = fan.GetPwmMode()
+	assert.NoError(t, err)
+	assert.Equal(t, fans.PwmModePWM, mode)
+}
+
+func TestApplyPwmMode_NotConfigured(t *testing.T) {
+	// GIVEN
+	fan := createPwmModeFan(t, "", "1")
+	controller := PidFanController{
+		persistence: mockPersistence{},
+		fan:         fan,
+	}
+
+	// WHEN
+	controller.applyPwmMode()
+
+	// THEN
+	mode, err := fan.GetPwmMode()
+	assert.NoError(t, err)
+	assert.Equal(t, fans.PwmModePWM, mode)
+	assert.Nil(t, controller.originalPwmMode)
+}
+
+func TestRestoreFanState_PwmMode(t *testing.T) {
+	// GIVEN
+	fan := createPwmModeFan(t, configuration.PwmModeDC, "0")
+	pwmMode := fans.PwmModePWM
+
+	// WHEN
+	err := RestoreFanState(fan, persistence.FanState{Pwm: 80, PwmMode: &pwmMode})
+
+	// THEN
+	assert.NoError(t, err)
+	mode, err := fan.GetPwmMode()
+	assert.NoError(t, err)
+	assert.Equal(t, fans.PwmModePWM, mode)
+}
+
+func TestInitializeFan_FailureKeepsPreviousData(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.DryRun = true
+	defer func() {
+		configuration.CurrentConfig.DryRun = false
+	}()
+
+	fan := &MockFan{
+		ID:         "fan",
+		speedCurve: &LinearFan,
+	}
+	previousPwmMap := createOneToOnePwmMap()
+	p, err := persistence.NewMemoryPersistence(&persistence.Export{
+		Version: persistence.ExportVersion,
+		Fans: map[string]persistence.FanExport{
+			"fan": {
+				CurveData:     LinearFan,
+				PwmMap:        previousPwmMap,
+				PwmBoundaries: &persistence.FanPwmBoundaries{StartPwm: 40, MinPwm: 30},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	// WHEN
+	err = InitializeFan(p, fan)
+
+	// THEN
+	assert.Error(t, err)
+	pwmMap, err := p.LoadFanPwmMap("fan")
+	assert.NoError(t, err)
+	assert.Equal(t, previousPwmMap, pwmMap)
+	curveData, err := p.LoadFanPwmData(fan)
+	assert.NoError(t, err)
+	assert.Equal(t, LinearFan, curveData)
+	boundaries, err := p.LoadFanPwmBoundaries("fan")
+	assert.NoError(t, err)
+	assert.Equal(t, 40, boundaries.StartPwm)
+}
+
+// createFixtureFan returns a fan of a fake sysfs tree detected using the sysfs hwmon backend,
+// and the path of its pwm attribute
+func createFixtureFan(t *testing.T, rpm int, pwm int) (fans.Fan, string) {
+	root := t.TempDir()
+	err := hwmon.WriteFixture(root, []hwmon.FixtureDevice{
+		{
+			Name: "nct6798",
+			Fans: map[int]int{1: rpm},
+			Pwms: map[int]int{1: pwm},
+		},
+	})
+	assert.NoError(t, err)
+	configuration.CurrentConfig.HwMonBackend = configuration.HwMonBackendSysfs
+	configuration.CurrentConfig.SysfsRoot = root
+	t.Cleanup(func() {
+		configuration.CurrentConfig.HwMonBackend = ""
+		configuration.CurrentConfig.SysfsRoot = ""
+	})
+
+	config := configuration.FanConfig{
+		ID:    "fixture_fan",
+		Curve: "curve",
+		HwMon: &configuration.HwMonFanConfig{
+			Platform:   "nct6798",
+			RpmChannel: 1,
+		},
+	}
+	err = hwmon.UpdateFanConfigFromHwMonControllers(hwmon.GetChips(), &config)
+	assert.NoError(t, err)
+	fan, err := fans.NewFan(config)
+	assert.NoError(t, err)
+	fans.SetFan(fan.GetId(), fan)
+	return fan, config.HwMon.PwmPath
+}
+
+func TestFixtureFanFollowsCurve(t *testing.T) {
+	// GIVEN
+	curve := MockCurve{
+		ID:    "curve",
+		Value: 255,
+	}
+	curves.SetSpeedCurve(curve.GetId(), &curve)
+
+	fan, pwmPath := createFixtureFan(t, 1200, 100)
+	err := fan.AttachFanCurveData(&LinearFan)
+	assert.NoError(t, err)
+
+	controller := NewFanController(mockPersistence{}, fan, *NewPidLoop(fan.GetConfig()), time.Duration(100)).(*PidFanController)
+	controller.pwmMap = createOneToOnePwmMap()
+	controller.updateDistinctPwmValues()
+
+	// WHEN
+	for i := 0; i < 50; i++ {
+		// the pid loop depends on the time between updates, like the actual control loop
+		time.Sleep(10 * time.Millisecond)
+		err = controller.UpdateFanSpeed()
+		assert.NoError(t, err)
+	}
+
+	// THEN
+	pwm, err := util.ReadIntFromFile(pwmPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 255, pwm)
+	pwmEnabled, err := util.ReadIntFromFile(pwmPath + "_enable")
+	assert.NoError(t, err)
+	assert.Equal(t, int(fans.ControlModePWM), pwmEnabled)
+}
+
+func TestInitializeFixtureFan(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.MaxRpmDiffForSettledFan = 10
+	fanSettleInterval = time.Millisecond
+	defer func() {
+		configuration.CurrentConfig.MaxRpmDiffForSettledFan = 0
+		fanSettleInterval = 1 * time.Second
+	}()
+
+	fan, pwmPath := createFixtureFan(t, 1200, 100)
+	p, err := persistence.NewMemoryPersistence(nil)
+	assert.NoError(t, err)
+
+	// WHEN
+	err = InitializeFan(p, fan)
+
+	// THEN
+	assert.NoError(t, err)
+	pwmMap, err := p.LoadFanPwmMap(fan.GetId())
+	assert.NoError(t, err)
+	assert.Equal(t, createOneToOnePwmMap(), pwmMap)
+	curveData, err := p.LoadFanPwmData(fan)
+	assert.NoError(t, err)
+	assert.Len(t, curveData, fans.MaxPwmValue+1)
+	assert.Equal(t, 1200.0, curveData[128])
+	// the fixture fan spins at any pwm
+	boundaries, err := p.LoadFanPwmBoundaries(fan.GetId())
+	assert.NoError(t, err)
+	assert.Equal(t, fans.MinPwmValue, boundaries.StartPwm)
+	assert.Equal(t, fans.MinPwmValue, boundaries.MinPwm)
+	pwmEnabled, err := util.ReadIntFromFile(pwmPath + "_enable")
+	assert.NoError(t, err)
+	assert.Equal(t, int(fans.ControlModePWM), pwmEnabled)
+}
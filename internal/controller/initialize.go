@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// NewPidLoop returns the control loop configured for the given fan, or the default one
+func NewPidLoop(config configuration.FanConfig) *util.PidLoop {
+	if config.ControlLoop != nil {
+		return util.NewPidLoop(
+			config.ControlLoop.P,
+			config.ControlLoop.I,
+			config.ControlLoop.D,
+		)
+	}
+	return util.NewPidLoop(
+		0.03,
+		0.002,
+		0.0005,
+	)
+}
+
+// InitializeFan runs the initialization sequence for the given fan, even if it has been analyzed before,
+// and replaces its data in the given persistence with the measured one. The sequence stores its results
+// in memory first, so the previous data is kept if it fails or is aborted.
+func InitializeFan(p persistence.Persistence, fan fans.Fan) error {
+	measured, err := persistence.NewMemoryPersistence(nil)
+	if err != nil {
+		return err
+	}
+
+	fanController := NewFanController(
+		measured,
+		fan,
+		*NewPidLoop(fan.GetConfig()),
+		configuration.CurrentConfig.ControllerAdjustmentTickRate,
+	)
+	err = fanController.RunInitializationSequence()
+	if err != nil {
+		return err
+	}
+
+	logger.WithFan(fan.GetId()).Info("Replacing existing data for fan '%s'...", fan.GetId())
+	pwmMap, loadErr := measured.LoadFanPwmMap(fan.GetId())
+	if loadErr == nil && pwmMap != nil {
+		err = p.SaveFanPwmMap(fan.GetId(), pwmMap)
+	} else {
+		err = p.DeleteFanPwmMap(fan.GetId())
+	}
+	if err != nil {
+		return err
+	}
+
+	_, loadErr = measured.LoadFanPwmData(fan)
+	if loadErr == nil {
+		err = p.SaveFanPwmData(fan)
+	} else {
+		err = p.DeleteFanPwmData(fan)
+	}
+	if err != nil {
+		return err
+	}
+
+	boundaries, loadErr := measured.LoadFanPwmBoundaries(fan.GetId())
+	if loadErr == nil {
+		return p.SaveFanPwmBoundaries(fan.GetId(), boundaries)
+	}
+	return p.DeleteFanPwmBoundaries(fan.GetId())
+}
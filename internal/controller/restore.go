@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/persistence"
+)
+
+// RestoreFanState writes the given original state back to the fan. If its original pwm_enable value
+// can't be restored, or would leave the fan in manual mode, the fan is set to max speed instead.
+func RestoreFanState(fan fans.Fan, state persistence.FanState) error {
+	if hwMonFan, ok := fan.(*fans.HwMonFan); ok && state.PwmMode != nil {
+		err := hwMonFan.SetPwmMode(*state.PwmMode)
+		if err != nil {
+			logger.WithFan(fan.GetId()).Warning("Error restoring original pwm mode for fan %s: %v", fan.GetId(), err)
+		}
+	}
+
+	err := fan.SetPwm(state.Pwm)
+	if err != nil {
+		logger.WithFan(fan.GetId()).Warning("Error restoring original PWM value for fan %s: %v", fan.GetId(), err)
+	}
+
+	if fan.Supports(fans.FeatureControlMode) && state.PwmEnabled != nil {
+		restoreMode := fans.GetRestoreControlMode(fan, fans.ControlMode(*state.PwmEnabled))
+		if restoreMode != fans.GetManualControlMode(fan) && fan.SetPwmEnabled(restoreMode) == nil {
+			return nil
+		}
+	}
+	return fan.SetPwm(fans.MaxPwmValue)
+}
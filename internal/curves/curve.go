@@ -1,6 +1,8 @@
 package curves
 
 import (
+	"sync"
+
 	"fmt"
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/util"
@@ -11,12 +13,75 @@ type SpeedCurve interface {
 	// Evaluate calculates the current value of the given curve,
 	// returns a value in [0..255]
 	Evaluate() (value int, err error)
+	// CurrentValue returns the result of the last evaluation, without evaluating the curve again
+	CurrentValue() int
 }
 
 var (
-	SpeedCurveMap = map[string]SpeedCurve{}
+	// speedCurveMap contains all active curves. It is read concurrently,
+	// so it must only be accessed using the functions below.
+	speedCurveMap     = map[string]SpeedCurve{}
+	speedCurveMapLock sync.RWMutex
 )
 
+// GetSpeedCurveMap returns all curves by id, the result must not be modified
+func GetSpeedCurveMap() map[string]SpeedCurve {
+	speedCurveMapLock.RLock()
+	defer speedCurveMapLock.RUnlock()
+	return speedCurveMap
+}
+
+// GetSpeedCurve returns the curve with the given id
+func GetSpeedCurve(id string) (SpeedCurve, bool) {
+	speedCurveMapLock.RLock()
+	defer speedCurveMapLock.RUnlock()
+	value, ok := speedCurveMap[id]
+	return value, ok
+}
+
+// SetSpeedCurveMap replaces all curves, the given map must not be modified afterwards
+func SetSpeedCurveMap(values map[string]SpeedCurve) {
+	speedCurveMapLock.Lock()
+	defer speedCurveMapLock.Unlock()
+	speedCurveMap = values
+}
+
+// SetSpeedCurve adds or replaces the curve with the given id
+func SetSpeedCurve(id string, value SpeedCurve) {
+	speedCurveMapLock.Lock()
+	defer speedCurveMapLock.Unlock()
+	values := make(map[string]SpeedCurve, len(speedCurveMap)+1)
+	for key, v := range speedCurveMap {
+		values[key] = v
+	}
+	values[id] = value
+	speedCurveMap = values
+}
+
+// RemoveSpeedCurve removes the curve with the given id
+func RemoveSpeedCurve(id string) {
+	speedCurveMapLock.Lock()
+	defer speedCurveMapLock.Unlock()
+	values := make(map[string]SpeedCurve, len(speedCurveMap))
+	for key, v := range speedCurveMap {
+		if key != id {
+			values[key] = v
+		}
+	}
+	speedCurveMap = values
+}
+
+// valueLock guards the values of all curves, which are updated by the fan controllers evaluating them,
+// while the apis read them
+var valueLock sync.RWMutex
+
+// setValue stores the result of an evaluation as the current value of a curve
+func setValue(target *int, value int) {
+	valueLock.Lock()
+	defer valueLock.Unlock()
+	*target = value
+}
+
 func NewSpeedCurve(config configuration.CurveConfig) (SpeedCurve, error) {
 	if config.Linear != nil {
 		return &LinearSpeedCurve{
@@ -30,6 +95,7 @@ func NewSpeedCurve(config configuration.CurveConfig) (SpeedCurve, error) {
 			config.PID.I,
 			config.PID.D,
 		)
+		pidLoop.SetOutputLimits(0, 1)
 		return &PidSpeedCurve{
 			Config:  config,
 			pidLoop: pidLoop,
@@ -42,5 +108,56 @@ func NewSpeedCurve(config configuration.CurveConfig) (SpeedCurve, error) {
 		}, nil
 	}
 
+	if config.Target != nil {
+		return &TargetSpeedCurve{
+			Config: config,
+		}, nil
+	}
+
+	if config.Schedule != nil {
+		return &ScheduleSpeedCurve{
+			Config: config,
+		}, nil
+	}
+
+	if config.Expression != nil {
+		return NewExpressionSpeedCurve(config)
+	}
+
 	return nil, fmt.Errorf("no matching curve type for curve: %s", config.ID)
 }
+
+// GetSensorIds returns the ids of all sensors the value of the given curve depends on,
+// including the sensors of all curves it references
+func GetSensorIds(curveId string) []string {
+	var sensorIds []string
+	collectSensorIds(curveId, map[string]bool{}, &sensorIds)
+	return sensorIds
+}
+
+func collectSensorIds(curveId string, visited map[string]bool, sensorIds *[]string) {
+	if visited[curveId] {
+		return
+	}
+	visited[curveId] = true
+
+	for _, config := range configuration.GetCurves() {
+		if config.ID != curveId {
+			continue
+		}
+		*sensorIds = append(*sensorIds, config.GetSensorIds()...)
+		switch {
+		case config.Linear != nil && len(config.Linear.Curve) > 0:
+			collectSensorIds(config.Linear.Curve, visited, sensorIds)
+		case config.Function != nil:
+			for _, id := range config.Function.Curves {
+				collectSensorIds(id, visited, sensorIds)
+			}
+		case config.Schedule != nil:
+			collectSensorIds(config.Schedule.Default, visited, sensorIds)
+			for _, entry := range config.Schedule.Entries {
+				collectSensorIds(entry.Curve, visited, sensorIds)
+			}
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package curves
 
 import (
+	"testing"
+
 	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
 )
 
 type MockSensor struct {
@@ -33,3 +36,71 @@ func (sensor MockSensor) GetMovingAvg() (avg float64) {
 func (sensor *MockSensor) SetMovingAvg(avg float64) {
 	sensor.MovingAvg = avg
 }
+
+func TestGetSensorIdsOfReferencedCurves(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.Curves = []configuration.CurveConfig{
+		{ID: "cpu_curve", Linear: &configuration.LinearCurveConfig{Sensor: "cpu"}},
+		{ID: "gpu_curve", Target: &configuration.TargetCurveConfig{Sensor: "gpu"}},
+		{ID: "night_curve", PID: &configuration.PidCurveConfig{Sensor: "case"}},
+		{ID: "max_curve", Function: &configuration.FunctionCurveConfig{
+			Type:   configuration.FunctionMaximum,
+			Curves: []string{"cpu_curve", "gpu_curve"},
+		}},
+		{ID: "schedule_curve", Schedule: &configuration.ScheduleCurveConfig{
+			Default: "max_curve",
+			Entries: []configuration.ScheduleEntryConfig{
+				{From: "22:00", To: "06:00", Curve: "night_curve"},
+				{From: "12:00", To: "13:00", Curve: "expression_curve"},
+			},
+		}},
+		{ID: "expression_curve", Expression: &configuration.ExpressionCurveConfig{Formula: "max(cpu, nvme) * 2"}},
+	}
+	defer func() {
+		configuration.CurrentConfig.Curves = nil
+	}()
+
+	// WHEN
+	sensorIds := GetSensorIds("schedule_curve")
+
+	// THEN
+	assert.ElementsMatch(t, []string{"cpu", "gpu", "case", "cpu", "nvme"}, sensorIds)
+}
+
+func TestGetSensorIdsOfWeightedSensors(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.Curves = []configuration.CurveConfig{
+		{ID: "cpu_curve", Linear: &configuration.LinearCurveConfig{
+			Sensors: []configuration.WeightedSensorConfig{
+				{ID: "cpu", Weight: 0.7},
+				{ID: "vrm", Weight: 0.3},
+			},
+		}},
+	}
+	defer func() {
+		configuration.CurrentConfig.Curves = nil
+	}()
+
+	// WHEN
+	sensorIds := GetSensorIds("cpu_curve")
+
+	// THEN
+	assert.Equal(t, []string{"cpu", "vrm"}, sensorIds)
+}
+
+func TestGetSensorIdsOfChainedCurves(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.Curves = []configuration.CurveConfig{
+		{ID: "cpu_curve", Linear: &configuration.LinearCurveConfig{Sensor: "cpu"}},
+		{ID: "psu_curve", Linear: &configuration.LinearCurveConfig{Curve: "cpu_curve"}},
+	}
+	defer func() {
+		configuration.CurrentConfig.Curves = nil
+	}()
+
+	// WHEN
+	sensorIds := GetSensorIds("psu_curve")
+
+	// THEN
+	assert.Equal(t, []string{"cpu"}, sensorIds)
+}
@@ -0,0 +1,61 @@
+package curves
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/expression"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// ExpressionSpeedCurve computes its value using a formula of sensor values
+type ExpressionSpeedCurve struct {
+	Config configuration.CurveConfig `json:"config"`
+	Value  int                       `json:"value"`
+
+	expression *expression.Expression
+}
+
+func NewExpressionSpeedCurve(config configuration.CurveConfig) (*ExpressionSpeedCurve, error) {
+	parsed, err := expression.Parse(config.Expression.Formula)
+	if err != nil {
+		return nil, fmt.Errorf("curve %s: invalid formula: %v", config.ID, err)
+	}
+	return &ExpressionSpeedCurve{
+		Config:     config,
+		expression: parsed,
+	}, nil
+}
+
+func (c *ExpressionSpeedCurve) GetId() string {
+	return c.Config.ID
+}
+
+func (c *ExpressionSpeedCurve) CurrentValue() int {
+	valueLock.RLock()
+	defer valueLock.RUnlock()
+	return c.Value
+}
+
+func (c *ExpressionSpeedCurve) Evaluate() (value int, err error) {
+	variables := map[string]float64{}
+	for _, sensorId := range c.expression.Variables() {
+		sensor, ok := sensors.GetSensor(sensorId)
+		if !ok {
+			return c.CurrentValue(), fmt.Errorf("curve %s: sensor %s not found", c.GetId(), sensorId)
+		}
+		// milli-degree to degree
+		variables[sensorId] = sensor.GetMovingAvg() / 1000
+	}
+
+	result, err := c.expression.Evaluate(variables)
+	if err != nil {
+		return c.CurrentValue(), fmt.Errorf("curve %s: %v", c.GetId(), err)
+	}
+
+	value = int(math.Round(util.Coerce(result, 0, 255)))
+	setValue(&c.Value, value)
+	return value, nil
+}
@@ -0,0 +1,90 @@
+package curves
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+)
+
+func createExpressionCurveConfig(id string, formula string) configuration.CurveConfig {
+	return configuration.CurveConfig{
+		ID: id,
+		Expression: &configuration.ExpressionCurveConfig{
+			Formula: formula,
+		},
+	}
+}
+
+func TestExpressionCurve(t *testing.T) {
+	// GIVEN
+	cpu := MockSensor{ID: "cpu", MovingAvg: 55000.0}
+	gpu := MockSensor{ID: "gpu", MovingAvg: 80000.0}
+	sensors.SetSensor(cpu.GetId(), &cpu)
+	sensors.SetSensor(gpu.GetId(), &gpu)
+
+	curveConfig := createExpressionCurveConfig("curve", "max(cpu, gpu * 0.8) > 70 ? 255 : scale(max(cpu, gpu * 0.8), 40, 70, 50, 200)")
+	curve, err := NewSpeedCurve(curveConfig)
+	assert.NoError(t, err)
+
+	// WHEN
+	result, err := curve.Evaluate()
+
+	// THEN
+	assert.NoError(t, err)
+	// max(55, 64) = 64 -> 50 + 150 * 24/30
+	assert.Equal(t, 170, result)
+
+	// WHEN
+	gpu.MovingAvg = 90000.0
+	result, err = curve.Evaluate()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 255, result)
+}
+
+func TestExpressionCurveIsLimited(t *testing.T) {
+	// GIVEN
+	cpu := MockSensor{ID: "cpu", MovingAvg: 90000.0}
+	sensors.SetSensor(cpu.GetId(), &cpu)
+
+	curve, err := NewSpeedCurve(createExpressionCurveConfig("curve", "(cpu - 40) * 10"))
+	assert.NoError(t, err)
+
+	// WHEN
+	result, err := curve.Evaluate()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 255, result)
+
+	// WHEN
+	cpu.MovingAvg = 20000.0
+	result, err = curve.Evaluate()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result)
+}
+
+func TestExpressionCurveMissingSensor(t *testing.T) {
+	// GIVEN
+	curve, err := NewSpeedCurve(createExpressionCurveConfig("curve", "missing_sensor * 2"))
+	assert.NoError(t, err)
+
+	// WHEN
+	_, err = curve.Evaluate()
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: sensor missing_sensor not found")
+}
+
+func TestExpressionCurveInvalidFormula(t *testing.T) {
+	// WHEN
+	_, err := NewSpeedCurve(createExpressionCurveConfig("curve", "max(cpu"))
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: invalid formula: expected ')' at end of expression")
+}
@@ -15,10 +15,16 @@ func (c *FunctionSpeedCurve) GetId() string {
 	return c.Config.ID
 }
 
+func (c *FunctionSpeedCurve) CurrentValue() int {
+	valueLock.RLock()
+	defer valueLock.RUnlock()
+	return c.Value
+}
+
 func (c *FunctionSpeedCurve) Evaluate() (value int, err error) {
 	var curves []SpeedCurve
 	for _, curveId := range c.Config.Function.Curves {
-		curves = append(curves, SpeedCurveMap[curveId])
+		curves = append(curves, GetSpeedCurveMap()[curveId])
 	}
 
 	var values []int
@@ -79,6 +85,6 @@ func (c *FunctionSpeedCurve) Evaluate() (value int, err error) {
 		ui.Fatal("Unknown curve function: %s", c.Config.Function.Type)
 	}
 
-	c.Value = value
+	setValue(&c.Value, value)
 	return value, err
 }
@@ -0,0 +1,57 @@
+package curves
+
+import (
+	"fmt"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/sensors"
+)
+
+// getInput returns the value (in milli-units) a curve is driven by, which is the value of its sensor,
+// or the weighted sum of the values of its weighted sensors if any are configured
+func getInput(sensorId string, weighted []configuration.WeightedSensorConfig, read func(sensor sensors.Sensor) (float64, error)) (float64, error) {
+	if len(weighted) <= 0 {
+		return readSensor(sensorId, read)
+	}
+
+	sum := 0.0
+	for _, sensorConfig := range weighted {
+		value, err := readSensor(sensorConfig.ID, read)
+		if err != nil {
+			return 0, err
+		}
+		sum += value * sensorConfig.Weight
+	}
+	return sum, nil
+}
+
+// readSensor reads the sensor with the given id, which may not exist f.ex. if it is quarantined
+func readSensor(sensorId string, read func(sensor sensors.Sensor) (float64, error)) (float64, error) {
+	sensor, ok := sensors.GetSensor(sensorId)
+	if !ok {
+		return 0, fmt.Errorf("sensor '%s' not found", sensorId)
+	}
+	return read(sensor)
+}
+
+func readMovingAvg(sensor sensors.Sensor) (float64, error) {
+	return sensor.GetMovingAvg(), nil
+}
+
+func readValue(sensor sensors.Sensor) (float64, error) {
+	return sensor.GetValue()
+}
+
+// getCurveInput evaluates the curve another curve is driven by and returns its value,
+// scaled to milli-units like sensor values
+func getCurveInput(curveId string) (float64, error) {
+	curve, ok := GetSpeedCurve(curveId)
+	if !ok {
+		return 0, fmt.Errorf("curve '%s' not found", curveId)
+	}
+	value, err := curve.Evaluate()
+	if err != nil {
+		return 0, err
+	}
+	return float64(value) * 1000, nil
+}
@@ -0,0 +1,150 @@
+package curves
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearCurveWithWeightedSensors(t *testing.T) {
+	// GIVEN
+	cpu := MockSensor{ID: "cpu", MovingAvg: 70000}
+	vrm := MockSensor{ID: "vrm", MovingAvg: 40000}
+	sensors.SetSensor(cpu.GetId(), &cpu)
+	sensors.SetSensor(vrm.GetId(), &vrm)
+
+	curve, _ := NewSpeedCurve(configuration.CurveConfig{
+		ID: "curve",
+		Linear: &configuration.LinearCurveConfig{
+			Sensors: []configuration.WeightedSensorConfig{
+				{ID: "cpu", Weight: 0.7},
+				{ID: "vrm", Weight: 0.3},
+			},
+			Min: 41,
+			Max: 81,
+		},
+	})
+
+	// WHEN
+	result, err := curve.Evaluate()
+
+	// THEN
+	// 0.7 * 70 + 0.3 * 40 = 61
+	assert.NoError(t, err)
+	assert.Equal(t, 127, result)
+}
+
+func TestTargetCurveWithWeightedSensors(t *testing.T) {
+	// GIVEN
+	cpu := MockSensor{ID: "cpu", MovingAvg: 80000}
+	vrm := MockSensor{ID: "vrm", MovingAvg: 60000}
+	sensors.SetSensor(cpu.GetId(), &cpu)
+	sensors.SetSensor(vrm.GetId(), &vrm)
+
+	curve, _ := NewSpeedCurve(configuration.CurveConfig{
+		ID: "curve",
+		Target: &configuration.TargetCurveConfig{
+			Sensors: []configuration.WeightedSensorConfig{
+				{ID: "cpu", Weight: 0.5},
+				{ID: "vrm", Weight: 0.5},
+			},
+			Target: 60,
+			Max:    200,
+		},
+	})
+
+	// WHEN
+	result, err := curve.Evaluate()
+
+	// THEN
+	// the weighted input of 70 is 10 above the target
+	assert.NoError(t, err)
+	assert.Equal(t, 200, result)
+	result, _ = curve.Evaluate()
+	assert.Equal(t, 200, result)
+
+	// WHEN
+	cpu.MovingAvg = 50000
+	result, err = curve.Evaluate()
+
+	// THEN
+	// the weighted input of 55 is 5 below the target
+	assert.NoError(t, err)
+	assert.Equal(t, 195, result)
+}
+
+func TestLinearCurveWithCurveInput(t *testing.T) {
+	// GIVEN
+	cpu := MockSensor{ID: "cpu", MovingAvg: 60000}
+	sensors.SetSensor(cpu.GetId(), &cpu)
+
+	cpuCurve, _ := NewSpeedCurve(createLinearCurveConfig("cpu_curve", "cpu", 40, 80))
+	SetSpeedCurve(cpuCurve.GetId(), cpuCurve)
+	defer RemoveSpeedCurve(cpuCurve.GetId())
+
+	curve, _ := NewSpeedCurve(configuration.CurveConfig{
+		ID: "psu_curve",
+		Linear: &configuration.LinearCurveConfig{
+			Curve: "cpu_curve",
+			// rescale to 100..200
+			Steps: map[int]float64{
+				0:   100,
+				255: 200,
+			},
+		},
+	})
+
+	// WHEN
+	result, err := curve.Evaluate()
+
+	// THEN
+	// the cpu curve is at 127
+	assert.NoError(t, err)
+	assert.Equal(t, 127, cpuCurve.CurrentValue())
+	assert.Equal(t, 150, result)
+}
+
+func TestLinearCurveWithMissingCurveInput(t *testing.T) {
+	// GIVEN
+	curve, _ := NewSpeedCurve(configuration.CurveConfig{
+		ID: "psu_curve",
+		Linear: &configuration.LinearCurveConfig{
+			Curve: "cpu_curve",
+			Min:   0,
+			Max:   255,
+		},
+	})
+
+	// WHEN
+	_, err := curve.Evaluate()
+
+	// THEN
+	assert.EqualError(t, err, "curve psu_curve: curve 'cpu_curve' not found")
+}
+
+func TestLinearCurveWithMissingSensor(t *testing.T) {
+	// GIVEN
+	cpu := MockSensor{ID: "cpu", MovingAvg: 70000}
+	sensors.SetSensor(cpu.GetId(), &cpu)
+	sensors.RemoveSensor("missing")
+
+	curve, _ := NewSpeedCurve(configuration.CurveConfig{
+		ID: "curve",
+		Linear: &configuration.LinearCurveConfig{
+			Sensors: []configuration.WeightedSensorConfig{
+				{ID: "cpu", Weight: 0.5},
+				{ID: "missing", Weight: 0.5},
+			},
+			Min: 40,
+			Max: 80,
+		},
+	})
+
+	// WHEN
+	_, err := curve.Evaluate()
+
+	// THEN
+	assert.EqualError(t, err, "curve curve: sensor 'missing' not found")
+}
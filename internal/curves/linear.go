@@ -1,8 +1,8 @@
 package curves
 
 import (
+	"fmt"
 	"github.com/markusressel/fan2go/internal/configuration"
-	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/util"
 	"math"
 )
@@ -10,19 +10,44 @@ import (
 type LinearSpeedCurve struct {
 	Config configuration.CurveConfig `json:"config"`
 	Value  int                       `json:"value"`
+
+	// effectiveTemp is the (milli-degree) temperature the curve was last evaluated at,
+	// taking hysteresis into account
+	effectiveTemp *float64
 }
 
 func (c *LinearSpeedCurve) GetId() string {
 	return c.Config.ID
 }
 
+func (c *LinearSpeedCurve) CurrentValue() int {
+	valueLock.RLock()
+	defer valueLock.RUnlock()
+	return c.Value
+}
+
 func (c *LinearSpeedCurve) Evaluate() (value int, err error) {
-	sensor := sensors.SensorMap[c.Config.Linear.Sensor]
-	var avgTemp = sensor.GetMovingAvg()
+	var input float64
+	if len(c.Config.Linear.Curve) > 0 {
+		input, err = getCurveInput(c.Config.Linear.Curve)
+		if err != nil {
+			return c.CurrentValue(), fmt.Errorf("curve %s: %v", c.GetId(), err)
+		}
+	} else {
+		input, err = getInput(c.Config.Linear.Sensor, c.Config.Linear.Sensors, readMovingAvg)
+		if err != nil {
+			return c.CurrentValue(), fmt.Errorf("curve %s: %v", c.GetId(), err)
+		}
+	}
+	var avgTemp = c.applyHysteresis(input)
 
 	steps := c.Config.Linear.Steps
 	if steps != nil {
-		value = int(math.Round(util.CalculateInterpolatedCurveValue(steps, util.InterpolationTypeLinear, avgTemp/1000)))
+		interpolationType := c.Config.Linear.Interpolation
+		if len(interpolationType) <= 0 {
+			interpolationType = util.InterpolationTypeLinear
+		}
+		value = int(math.Round(util.CalculateInterpolatedCurveValue(steps, interpolationType, avgTemp/1000)))
 	} else {
 		minTemp := float64(c.Config.Linear.Min) * 1000 // degree to milli-degree
 		maxTemp := float64(c.Config.Linear.Max) * 1000
@@ -39,6 +64,25 @@ func (c *LinearSpeedCurve) Evaluate() (value int, err error) {
 		}
 	}
 
-	c.Value = value
+	setValue(&c.Value, value)
 	return value, nil
 }
+
+// applyHysteresis returns the temperature to evaluate the curve at. Rising temperatures
+// are followed immediately, while falling temperatures are only followed once they
+// dropped by more than the configured hysteresis.
+func (c *LinearSpeedCurve) applyHysteresis(temp float64) float64 {
+	hysteresis := c.Config.Linear.Hysteresis * 1000 // degree to milli-degree
+	if hysteresis <= 0 {
+		return temp
+	}
+
+	if c.effectiveTemp == nil || temp > *c.effectiveTemp {
+		c.effectiveTemp = &temp
+	} else if temp < *c.effectiveTemp-hysteresis {
+		effectiveTemp := temp + hysteresis
+		c.effectiveTemp = &effectiveTemp
+	}
+
+	return *c.effectiveTemp
+}
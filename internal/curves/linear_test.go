@@ -49,7 +49,7 @@ func TestLinearCurveWithMinMax(t *testing.T) {
 		Name:      "sensor",
 		MovingAvg: avgTmp,
 	}
-	sensors.SensorMap[s.GetId()] = &s
+	sensors.SetSensor(s.GetId(), &s)
 
 	curveConfig := createLinearCurveConfig(
 		"curve",
@@ -76,7 +76,7 @@ func TestLinearCurveWithSteps(t *testing.T) {
 		Name:      "sensor",
 		MovingAvg: avgTmp,
 	}
-	sensors.SensorMap[s.GetId()] = &s
+	sensors.SetSensor(s.GetId(), &s)
 
 	curveConfig := createLinearCurveConfigWithSteps(
 		"curve",
@@ -99,3 +99,50 @@ func TestLinearCurveWithSteps(t *testing.T) {
 	// THEN
 	assert.Equal(t, 100, result)
 }
+
+func TestLinearCurveWithHysteresis(t *testing.T) {
+	// GIVEN
+	s := MockSensor{
+		Name:      "sensor",
+		MovingAvg: 60000.0,
+	}
+	sensors.SetSensor(s.GetId(), &s)
+
+	curveConfig := createLinearCurveConfigWithSteps(
+		"curve",
+		s.GetId(),
+		map[int]float64{
+			40: 0,
+			50: 0,
+			60: 100,
+		},
+	)
+	curveConfig.Linear.Hysteresis = 5
+	curve, _ := NewSpeedCurve(curveConfig)
+
+	// temperature rises are followed immediately,
+	// drops only once they exceed the hysteresis
+	for _, step := range []struct {
+		temp     float64
+		expected int
+	}{
+		{temp: 60000, expected: 100},
+		{temp: 57000, expected: 100},
+		{temp: 55000, expected: 100},
+		{temp: 54000, expected: 90},
+		{temp: 56000, expected: 90},
+		{temp: 58000, expected: 90},
+		{temp: 59500, expected: 95},
+	} {
+		s.MovingAvg = step.temp
+
+		// WHEN
+		result, err := curve.Evaluate()
+		if err != nil {
+			assert.Fail(t, err.Error())
+		}
+
+		// THEN
+		assert.Equal(t, step.expected, result, "temp %v", step.temp)
+	}
+}
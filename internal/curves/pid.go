@@ -2,7 +2,6 @@ package curves
 
 import (
 	"github.com/markusressel/fan2go/internal/configuration"
-	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/util"
 )
 
@@ -17,12 +16,17 @@ func (c *PidSpeedCurve) GetId() string {
 	return c.Config.ID
 }
 
+func (c *PidSpeedCurve) CurrentValue() int {
+	valueLock.RLock()
+	defer valueLock.RUnlock()
+	return c.Value
+}
+
 func (c *PidSpeedCurve) Evaluate() (value int, err error) {
-	sensor := sensors.SensorMap[c.Config.PID.Sensor]
 	var measured float64
-	measured, err = sensor.GetValue()
+	measured, err = getInput(c.Config.PID.Sensor, c.Config.PID.Sensors, readValue)
 	if err != nil {
-		return c.Value, err
+		return c.CurrentValue(), err
 	}
 	pidTarget := c.Config.PID.SetPoint
 
@@ -38,6 +42,6 @@ func (c *PidSpeedCurve) Evaluate() (value int, err error) {
 	// map to expected output range
 	curveValue := int(loopValue * 255)
 
-	c.Value = curveValue
+	setValue(&c.Value, curveValue)
 	return curveValue, nil
 }
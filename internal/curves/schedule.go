@@ -0,0 +1,64 @@
+package curves
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// ScheduleSpeedCurve uses the value of different curves depending on the time of day
+type ScheduleSpeedCurve struct {
+	Config configuration.CurveConfig `json:"config"`
+	Value  int                       `json:"value"`
+}
+
+func (c *ScheduleSpeedCurve) GetId() string {
+	return c.Config.ID
+}
+
+func (c *ScheduleSpeedCurve) CurrentValue() int {
+	valueLock.RLock()
+	defer valueLock.RUnlock()
+	return c.Value
+}
+
+func (c *ScheduleSpeedCurve) Evaluate() (value int, err error) {
+	curveId, err := c.getActiveCurveId(time.Now())
+	if err != nil {
+		return c.CurrentValue(), err
+	}
+
+	curve, ok := GetSpeedCurve(curveId)
+	if !ok {
+		return c.CurrentValue(), fmt.Errorf("curve %s: curve '%s' not found", c.GetId(), curveId)
+	}
+
+	value, err = curve.Evaluate()
+	if err != nil {
+		return c.CurrentValue(), err
+	}
+
+	setValue(&c.Value, value)
+	return value, nil
+}
+
+// getActiveCurveId returns the id of the curve that is active at the given time
+func (c *ScheduleSpeedCurve) getActiveCurveId(now time.Time) (string, error) {
+	config := c.Config.Schedule
+	for _, entry := range config.Entries {
+		from, err := util.ParseTimeOfDay(entry.From)
+		if err != nil {
+			return "", err
+		}
+		to, err := util.ParseTimeOfDay(entry.To)
+		if err != nil {
+			return "", err
+		}
+		if util.IsTimeOfDayInRange(now, from, to) {
+			return entry.Curve, nil
+		}
+	}
+	return config.Default, nil
+}
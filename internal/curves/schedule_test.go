@@ -0,0 +1,71 @@
+package curves
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleCurveActiveCurve(t *testing.T) {
+	// GIVEN
+	curve := ScheduleSpeedCurve{
+		Config: configuration.CurveConfig{
+			ID: "schedule",
+			Schedule: &configuration.ScheduleCurveConfig{
+				Default: "day_curve",
+				Entries: []configuration.ScheduleEntryConfig{
+					{From: "22:00", To: "07:00", Curve: "night_curve"},
+					{From: "12:00", To: "13:00", Curve: "lunch_curve"},
+				},
+			},
+		},
+	}
+
+	expected := map[int]string{
+		9:  "day_curve",
+		12: "lunch_curve",
+		21: "day_curve",
+		23: "night_curve",
+		3:  "night_curve",
+	}
+
+	for hour, curveId := range expected {
+		now := time.Date(2022, 1, 1, hour, 30, 0, 0, time.Local)
+
+		// WHEN
+		result, err := curve.getActiveCurveId(now)
+
+		// THEN
+		assert.NoError(t, err)
+		assert.Equal(t, curveId, result, "hour %d", hour)
+	}
+}
+
+func TestScheduleCurveEvaluate(t *testing.T) {
+	// GIVEN
+	s := MockSensor{
+		Name:      "sensor",
+		MovingAvg: 60000.0,
+	}
+	sensors.SetSensor(s.GetId(), &s)
+
+	c1, _ := NewSpeedCurve(createLinearCurveConfig("default_curve", s.GetId(), 40, 80))
+	SetSpeedCurve(c1.GetId(), c1)
+
+	curve, _ := NewSpeedCurve(configuration.CurveConfig{
+		ID: "schedule",
+		Schedule: &configuration.ScheduleCurveConfig{
+			Default: c1.GetId(),
+		},
+	})
+
+	// WHEN
+	result, err := curve.Evaluate()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 127, result)
+}
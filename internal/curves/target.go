@@ -0,0 +1,67 @@
+package curves
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// TargetSpeedCurve continuously adjusts its value to hold a sensor at a target temperature
+type TargetSpeedCurve struct {
+	Config configuration.CurveConfig `json:"config"`
+	Value  int                       `json:"value"`
+
+	// value is the unrounded curve value, so small adjustments can accumulate
+	value *float64
+}
+
+func (c *TargetSpeedCurve) GetId() string {
+	return c.Config.ID
+}
+
+func (c *TargetSpeedCurve) CurrentValue() int {
+	valueLock.RLock()
+	defer valueLock.RUnlock()
+	return c.Value
+}
+
+func (c *TargetSpeedCurve) Evaluate() (value int, err error) {
+	config := c.Config.Target
+	avgTemp, err := getInput(config.Sensor, config.Sensors, readMovingAvg)
+	if err != nil {
+		return c.CurrentValue(), fmt.Errorf("curve %s: %v", c.GetId(), err)
+	}
+
+	minValue, maxValue := c.getRange()
+	if c.value == nil {
+		// start at full speed and slow down until the target is reached
+		c.value = &maxValue
+	}
+
+	gain := config.Gain
+	if gain <= 0 {
+		gain = 1
+	}
+
+	deviation := avgTemp/1000 - config.Target
+	if math.Abs(deviation) > config.Tolerance {
+		newValue := util.Coerce(*c.value+gain*deviation, minValue, maxValue)
+		c.value = &newValue
+	}
+
+	value = int(math.Round(*c.value))
+	setValue(&c.Value, value)
+	return value, nil
+}
+
+// getRange returns the configured range of the curve value
+func (c *TargetSpeedCurve) getRange() (float64, float64) {
+	config := c.Config.Target
+	maxValue := config.Max
+	if maxValue <= 0 {
+		maxValue = 255
+	}
+	return float64(config.Min), float64(maxValue)
+}
@@ -0,0 +1,101 @@
+package curves
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+)
+
+// helper function to create a target curve configuration
+func createTargetCurveConfig(
+	id string,
+	sensorId string,
+	target float64,
+	min int,
+	max int,
+) (curve configuration.CurveConfig) {
+	curve = configuration.CurveConfig{
+		ID: id,
+		Target: &configuration.TargetCurveConfig{
+			Sensor: sensorId,
+			Target: target,
+			Min:    min,
+			Max:    max,
+			Gain:   10,
+		},
+	}
+	return curve
+}
+
+func TestTargetCurveBelowTarget(t *testing.T) {
+	// GIVEN
+	s := MockSensor{
+		Name:      "sensor",
+		MovingAvg: 55000.0,
+	}
+	sensors.SetSensor(s.GetId(), &s)
+
+	curveConfig := createTargetCurveConfig("curve", s.GetId(), 60, 50, 200)
+	curve, _ := NewSpeedCurve(curveConfig)
+
+	for _, expected := range []int{150, 100, 50, 50} {
+		// WHEN
+		result, err := curve.Evaluate()
+		if err != nil {
+			assert.Fail(t, err.Error())
+		}
+
+		// THEN
+		assert.Equal(t, expected, result)
+	}
+}
+
+func TestTargetCurveAboveTarget(t *testing.T) {
+	// GIVEN
+	s := MockSensor{
+		Name:      "sensor",
+		MovingAvg: 55000.0,
+	}
+	sensors.SetSensor(s.GetId(), &s)
+
+	curveConfig := createTargetCurveConfig("curve", s.GetId(), 60, 0, 0)
+	curve, _ := NewSpeedCurve(curveConfig)
+	_, _ = curve.Evaluate()
+	_, _ = curve.Evaluate()
+	s.MovingAvg = 62000.0
+
+	for _, expected := range []int{175, 195, 215} {
+		// WHEN
+		result, err := curve.Evaluate()
+		if err != nil {
+			assert.Fail(t, err.Error())
+		}
+
+		// THEN
+		assert.Equal(t, expected, result)
+	}
+}
+
+func TestTargetCurveWithinTolerance(t *testing.T) {
+	// GIVEN
+	s := MockSensor{
+		Name:      "sensor",
+		MovingAvg: 59000.0,
+	}
+	sensors.SetSensor(s.GetId(), &s)
+
+	curveConfig := createTargetCurveConfig("curve", s.GetId(), 60, 0, 0)
+	curveConfig.Target.Tolerance = 1
+	curve, _ := NewSpeedCurve(curveConfig)
+
+	// WHEN
+	result, err := curve.Evaluate()
+	if err != nil {
+		assert.Fail(t, err.Error())
+	}
+
+	// THEN
+	assert.Equal(t, 255, result)
+}
@@ -0,0 +1,314 @@
+package dbus
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+	"sort"
+	"strconv"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/profiles"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+const (
+	ServiceName   = "com.markusressel.Fan2go"
+	ObjectPath    = godbus.ObjectPath("/com/markusressel/Fan2go")
+	InterfaceName = ServiceName
+
+	signalFanStalled      = InterfaceName + ".FanStalled"
+	signalOverTemperature = InterfaceName + ".OverTemperature"
+
+	// eventCheckRate is the rate at which fans and sensors are checked for events to signal
+	eventCheckRate = time.Second
+)
+
+// service is the object exported on the bus, all of its exported methods are available via D-Bus
+type service struct {
+	config configuration.DbusConfig
+	// senderUid returns the uid of the process which sent a method call
+	senderUid func(sender godbus.Sender) (uint32, error)
+
+	// the ids of all fans which are currently stalled
+	stalledFans map[string]bool
+	// the ids of all sensors which are currently above the maximum temperature
+	hotSensors map[string]bool
+}
+
+// ListFans returns the ids of all configured fans
+func (s *service) ListFans() ([]string, *godbus.Error) {
+	return sortedKeys(fans.GetFanMap()), nil
+}
+
+// GetFan returns the current pwm, rpm and curve of the given fan
+func (s *service) GetFan(id string) (int32, int32, string, *godbus.Error) {
+	if _, ok := fans.GetFan(id); !ok {
+		return 0, 0, "", notFound(id)
+	}
+	// fans are only accessed by their controllers, so the values cached by them are used
+	fanController, ok := controller.GetFanController(id)
+	if !ok {
+		return 0, 0, "", notFound(id)
+	}
+	snapshot := fanController.GetFanSnapshot()
+	if snapshot.Pwm == nil {
+		return 0, 0, "", godbus.MakeFailedError(fmt.Errorf("fan %s has not been updated yet", id))
+	}
+	curveId := ""
+	for _, fanConfig := range configuration.GetFans() {
+		if fanConfig.ID == id {
+			curveId = fanConfig.Curve
+		}
+	}
+	return int32(*snapshot.Pwm), int32(snapshot.RpmAvg), curveId, nil
+}
+
+// ListSensors returns the ids of all configured sensors
+func (s *service) ListSensors() ([]string, *godbus.Error) {
+	return sortedKeys(sensors.GetSensorMap()), nil
+}
+
+// GetSensor returns the moving average of the given sensor
+func (s *service) GetSensor(id string) (float64, *godbus.Error) {
+	sensor, ok := sensors.GetSensor(id)
+	if !ok {
+		return 0, notFound(id)
+	}
+	return sensor.GetMovingAvg(), nil
+}
+
+// ListProfiles returns the ids of all configured profiles
+func (s *service) ListProfiles() ([]string, *godbus.Error) {
+	ids := []string{}
+	for _, profile := range configuration.GetProfiles() {
+		ids = append(ids, profile.ID)
+	}
+	return ids, nil
+}
+
+// GetActiveProfile returns the id of the active profile, empty if none is active
+func (s *service) GetActiveProfile() (string, *godbus.Error) {
+	return profiles.GetActive(), nil
+}
+
+// SetActiveProfile activates the given profile, an empty id deactivates the current profile
+func (s *service) SetActiveProfile(sender godbus.Sender, id string) *godbus.Error {
+	if err := s.authorize(sender); err != nil {
+		return err
+	}
+	if err := profiles.SetActive(id); err != nil {
+		return notFound(id)
+	}
+	if len(id) > 0 {
+		ui.Info("Activated profile %s", id)
+	} else {
+		ui.Info("Deactivated profile")
+	}
+	return nil
+}
+
+// SetOverride overrides the curve of the given fan with a fixed pwm value for the given amount
+// of seconds, or until ClearOverride is called if seconds is 0
+func (s *service) SetOverride(sender godbus.Sender, id string, pwm int32, seconds uint32) *godbus.Error {
+	if err := s.authorize(sender); err != nil {
+		return err
+	}
+	fanController, ok := controller.GetFanController(id)
+	if !ok {
+		return notFound(id)
+	}
+	if pwm < fans.MinPwmValue || pwm > fans.MaxPwmValue {
+		return godbus.MakeFailedError(fmt.Errorf("pwm must be in range [%d..%d]", fans.MinPwmValue, fans.MaxPwmValue))
+	}
+	fanController.SetOverride(int(pwm), time.Duration(seconds)*time.Second)
+	return nil
+}
+
+// ClearOverride removes the override of the given fan, returning control to its curve
+func (s *service) ClearOverride(sender godbus.Sender, id string) *godbus.Error {
+	if err := s.authorize(sender); err != nil {
+		return err
+	}
+	fanController, ok := controller.GetFanController(id)
+	if !ok {
+		return notFound(id)
+	}
+	fanController.ClearOverride()
+	return nil
+}
+
+// Run exports the service on the system bus until the given context is done
+func Run(ctx context.Context, config configuration.DbusConfig) error {
+	conn, err := godbus.ConnectSystemBus()
+	if err != nil {
+		return fmt.Errorf("unable to connect to system bus: %v", err)
+	}
+	defer conn.Close()
+
+	s := &service{
+		config: config,
+		senderUid: func(sender godbus.Sender) (uint32, error) {
+			var uid uint32
+			err := conn.BusObject().Call("org.freedesktop.DBus.GetConnectionUnixUser", 0, string(sender)).Store(&uid)
+			return uid, err
+		},
+		stalledFans: map[string]bool{},
+		hotSensors:  map[string]bool{},
+	}
+
+	err = conn.Export(s, ObjectPath, InterfaceName)
+	if err != nil {
+		return err
+	}
+	err = conn.Export(introspect.NewIntrospectable(s.introspection()), ObjectPath, "org.freedesktop.DBus.Introspectable")
+	if err != nil {
+		return err
+	}
+
+	reply, err := conn.RequestName(ServiceName, godbus.NameFlagDoNotQueue)
+	if err != nil {
+		return fmt.Errorf("unable to request name %s: %v", ServiceName, err)
+	}
+	if reply != godbus.RequestNameReplyPrimaryOwner {
+		return fmt.Errorf("name %s is already taken", ServiceName)
+	}
+	ui.Info("D-Bus service %s started", ServiceName)
+
+	tick := time.NewTicker(eventCheckRate)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+			s.emitEvents(conn)
+		}
+	}
+}
+
+// emitEvents emits a signal for each fan that started stalling and each sensor
+// that exceeded the maximum temperature since the last check
+func (s *service) emitEvents(conn *godbus.Conn) {
+	for id := range fans.GetFanMap() {
+		fanController, ok := controller.GetFanController(id)
+		if !ok {
+			continue
+		}
+		stalled := isStalled(fanController.GetFanSnapshot())
+		if stalled && !s.stalledFans[id] {
+			ui.Warning("Fan %s is stalled", id)
+			_ = conn.Emit(ObjectPath, signalFanStalled, id)
+		}
+		s.stalledFans[id] = stalled
+	}
+
+	if s.config.MaxTemperature <= 0 {
+		return
+	}
+	for id, sensor := range sensors.GetSensorMap() {
+		value := sensor.GetMovingAvg()
+		hot := value > s.config.MaxTemperature*1000
+		if hot && !s.hotSensors[id] {
+			_ = conn.Emit(ObjectPath, signalOverTemperature, id, value)
+		}
+		s.hotSensors[id] = hot
+	}
+}
+
+// isStalled returns true if the fan of the given snapshot doesn't spin, even though it should.
+// The rpm is only measured for fans with an rpm sensor.
+func isStalled(snapshot controller.FanSnapshot) bool {
+	if snapshot.Pwm == nil || snapshot.Rpm == nil {
+		return false
+	}
+	return *snapshot.Pwm > snapshot.MinPwm && snapshot.RpmAvg <= 0
+}
+
+func (s *service) introspection() *introspect.Node {
+	node := &introspect.Node{
+		Name: string(ObjectPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			{
+				Name:    InterfaceName,
+				Methods: introspect.Methods(s),
+				Signals: []introspect.Signal{
+					{
+						Name: "FanStalled",
+						Args: []introspect.Arg{{Name: "id", Type: "s"}},
+					},
+					{
+						Name: "OverTemperature",
+						Args: []introspect.Arg{{Name: "id", Type: "s"}, {Name: "value", Type: "d"}},
+					},
+				},
+			},
+		},
+	}
+	return node
+}
+
+// authorize returns an error, unless the sender of a method call changing the state of fan2go
+// is root or a member of the configured group
+func (s *service) authorize(sender godbus.Sender) *godbus.Error {
+	uid, err := s.senderUid(sender)
+	if err != nil {
+		return godbus.MakeFailedError(fmt.Errorf("unable to determine the user of %s: %v", sender, err))
+	}
+	authorized, err := isAuthorized(uid, s.config.Group)
+	if err != nil {
+		return godbus.MakeFailedError(err)
+	}
+	if !authorized {
+		return godbus.NewError("org.freedesktop.DBus.Error.AccessDenied", []interface{}{fmt.Sprintf("uid %d is neither root nor a member of the configured group", uid)})
+	}
+	return nil
+}
+
+// isAuthorized returns true if the given user is root or a member of the given group
+func isAuthorized(uid uint32, group string) (bool, error) {
+	if uid == 0 {
+		return true, nil
+	}
+	if len(group) <= 0 {
+		return false, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return false, err
+	}
+	u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
+	if err != nil {
+		return false, err
+	}
+	groupIds, err := u.GroupIds()
+	if err != nil {
+		return false, err
+	}
+	for _, id := range groupIds {
+		if id == g.Gid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func notFound(id string) *godbus.Error {
+	return godbus.NewError(InterfaceName+".Error.NotFound", []interface{}{fmt.Sprintf("No item with id '%s' found", id)})
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,119 @@
+package dbus
+
+import (
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/profiles"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Sensors(t *testing.T) {
+	// GIVEN
+	sensors.SetSensor("b", &sensors.VirtualSensor{Name: "b", Value: 42000})
+	sensors.SetSensor("a", &sensors.VirtualSensor{Name: "a", Value: 21000})
+	s := &service{}
+
+	// WHEN
+	ids, err := s.ListSensors()
+	value, valueErr := s.GetSensor("b")
+	_, missingErr := s.GetSensor("missing")
+
+	// THEN
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, ids)
+	assert.Nil(t, valueErr)
+	assert.Equal(t, 42000.0, value)
+	assert.Equal(t, InterfaceName+".Error.NotFound", missingErr.Name)
+}
+
+// senderUid returns a function returning the given uid for any sender
+func senderUid(uid uint32) func(sender godbus.Sender) (uint32, error) {
+	return func(sender godbus.Sender) (uint32, error) {
+		return uid, nil
+	}
+}
+
+func TestService_SetOverrideOfUnknownFan(t *testing.T) {
+	// GIVEN
+	s := &service{senderUid: senderUid(0)}
+
+	// WHEN
+	err := s.SetOverride(":1.1", "missing", 100, 0)
+
+	// THEN
+	assert.Equal(t, InterfaceName+".Error.NotFound", err.Name)
+}
+
+func TestService_SetOverrideRequiresRoot(t *testing.T) {
+	// GIVEN
+	s := &service{senderUid: senderUid(1000)}
+
+	// WHEN
+	setErr := s.SetOverride(":1.1", "missing", 0, 0)
+	clearErr := s.ClearOverride(":1.1", "missing")
+	profileErr := s.SetActiveProfile(":1.1", "")
+
+	// THEN
+	assert.Equal(t, "org.freedesktop.DBus.Error.AccessDenied", setErr.Name)
+	assert.Equal(t, "org.freedesktop.DBus.Error.AccessDenied", clearErr.Name)
+	assert.Equal(t, "org.freedesktop.DBus.Error.AccessDenied", profileErr.Name)
+}
+
+func TestIsAuthorized(t *testing.T) {
+	// GIVEN
+	// WHEN
+	root, rootErr := isAuthorized(0, "")
+	noGroup, noGroupErr := isAuthorized(1000, "")
+	_, unknownGroupErr := isAuthorized(1000, "fan2go-missing-group")
+
+	// THEN
+	assert.NoError(t, rootErr)
+	assert.True(t, root)
+	assert.NoError(t, noGroupErr)
+	assert.False(t, noGroup)
+	assert.Error(t, unknownGroupErr)
+}
+
+func TestService_Profiles(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.Profiles = []configuration.ProfileConfig{{ID: "silent"}, {ID: "performance"}}
+	defer func() {
+		_ = profiles.SetActive("")
+		configuration.CurrentConfig.Profiles = nil
+	}()
+	s := &service{senderUid: senderUid(0)}
+
+	// WHEN
+	ids, listErr := s.ListProfiles()
+	setErr := s.SetActiveProfile(":1.1", "silent")
+	active, getErr := s.GetActiveProfile()
+	missingErr := s.SetActiveProfile(":1.1", "missing")
+
+	// THEN
+	assert.Nil(t, listErr)
+	assert.Equal(t, []string{"silent", "performance"}, ids)
+	assert.Nil(t, setErr)
+	assert.Nil(t, getErr)
+	assert.Equal(t, "silent", active)
+	assert.Equal(t, InterfaceName+".Error.NotFound", missingErr.Name)
+}
+
+func TestIsStalled(t *testing.T) {
+	// GIVEN
+	pwm, rpm, stopped := 150, 800, 0
+	spinning := controller.FanSnapshot{Pwm: &pwm, Rpm: &rpm, RpmAvg: 800, MinPwm: 30}
+	stalled := controller.FanSnapshot{Pwm: &pwm, Rpm: &stopped, RpmAvg: 0, MinPwm: 30}
+	withoutRpmSensor := controller.FanSnapshot{Pwm: &pwm, MinPwm: 30}
+	notUpdated := controller.FanSnapshot{Rpm: &stopped}
+
+	// WHEN
+	// THEN
+	assert.False(t, isStalled(spinning))
+	assert.True(t, isStalled(stalled))
+	assert.False(t, isStalled(withoutRpmSensor))
+	assert.False(t, isStalled(notUpdated))
+}
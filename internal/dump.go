@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/emergency"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/profiles"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// stateDump is a snapshot of the internal state of the daemon, logged on SIGUSR1
+type stateDump struct {
+	Profile string `json:"profile"`
+	DryRun  bool   `json:"dryRun"`
+	// MaxFans indicates whether all fans run at full speed due to a critical temperature
+	MaxFans bool                  `json:"maxFans"`
+	Sensors map[string]sensorDump `json:"sensors"`
+	Curves  map[string]curveDump  `json:"curves"`
+	Fans    map[string]fanDump    `json:"fans"`
+}
+
+type sensorDump struct {
+	// Last is the last value read by the sensor monitor, nil if it wasn't read yet
+	Last      *sensors.LastValue `json:"last"`
+	MovingAvg float64            `json:"movingAvg"`
+	// FailingSince is the time of the first failed read since the last successful one, if any
+	FailingSince *time.Time `json:"failingSince,omitempty"`
+}
+
+type curveDump struct {
+	// Value is the result of the last evaluation of the curve
+	Value int `json:"value"`
+	// Sensors are the ids of all sensors the curve depends on
+	Sensors []string `json:"sensors"`
+}
+
+type fanDump struct {
+	Curve  string  `json:"curve"`
+	Pwm    *int    `json:"pwm"`
+	Rpm    *int    `json:"rpm"`
+	RpmAvg float64 `json:"rpmAvg"`
+
+	// controller state, empty if the fan has no controller
+	State      controller.ControllerState          `json:"state,omitempty"`
+	TargetPwm  *int                                `json:"targetPwm"`
+	Override   *controller.PwmOverride             `json:"override"`
+	Statistics *controller.FanControllerStatistics `json:"statistics,omitempty"`
+
+	Calibration calibrationDump `json:"calibration"`
+}
+
+// calibrationDump summarizes the measured characteristics of a fan
+type calibrationDump struct {
+	MinPwm   int `json:"minPwm"`
+	StartPwm int `json:"startPwm"`
+	MaxPwm   int `json:"maxPwm"`
+	// CurvePoints is the number of measured pwm -> rpm points, 0 if the fan wasn't measured
+	CurvePoints int `json:"curvePoints"`
+	// MaxRpm is the highest measured rpm
+	MaxRpm float64 `json:"maxRpm"`
+}
+
+// watchForDump logs the internal state of the daemon on SIGUSR1, until the given context is done
+func watchForDump(ctx context.Context) error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGUSR1)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sig:
+			ui.Info("Received SIGUSR1 signal, dumping state...")
+			logStateDump(collectStateDump())
+		}
+	}
+}
+
+// collectStateDump returns the current state of all sensors, curves, fans and fan controllers
+func collectStateDump() stateDump {
+	dump := stateDump{
+		Profile: profiles.GetActive(),
+		DryRun:  configuration.CurrentConfig.DryRun,
+		MaxFans: emergency.IsMaxFansActive(),
+		Sensors: map[string]sensorDump{},
+		Curves:  map[string]curveDump{},
+		Fans:    map[string]fanDump{},
+	}
+
+	for id, sensor := range sensors.GetSensorMap() {
+		s := sensorDump{
+			MovingAvg: sensor.GetMovingAvg(),
+		}
+		if last, ok := sensors.GetLastValue(id); ok {
+			s.Last = &last
+		}
+		if since, failing := sensors.GetFailingSince(id); failing {
+			s.FailingSince = &since
+		}
+		dump.Sensors[id] = s
+	}
+
+	for id, curve := range curves.GetSpeedCurveMap() {
+		dump.Curves[id] = curveDump{
+			Value:   curve.CurrentValue(),
+			Sensors: curves.GetSensorIds(id),
+		}
+	}
+
+	// fans are only accessed by their controllers, so the values cached by them are used
+	curveIds := map[string]string{}
+	for _, fanConfig := range configuration.GetFans() {
+		curveIds[fanConfig.ID] = fanConfig.Curve
+	}
+	for id := range fans.GetFanMap() {
+		f := fanDump{
+			Curve: profiles.GetCurveId(id, curveIds[id]),
+		}
+		if fanController, exists := controller.GetFanController(id); exists {
+			statistics := fanController.GetStatistics()
+			snapshot := fanController.GetFanSnapshot()
+			f.Pwm = snapshot.Pwm
+			f.Rpm = snapshot.Rpm
+			f.RpmAvg = snapshot.RpmAvg
+			f.Calibration = calibrationDump{
+				MinPwm:      snapshot.MinPwm,
+				StartPwm:    snapshot.StartPwm,
+				MaxPwm:      snapshot.MaxPwm,
+				CurvePoints: snapshot.CurvePoints,
+				MaxRpm:      snapshot.MaxRpm,
+			}
+			f.State = fanController.GetState()
+			f.TargetPwm = fanController.GetTargetPwm()
+			f.Override = fanController.GetOverride()
+			f.Statistics = &statistics
+		}
+		dump.Fans[id] = f
+	}
+
+	return dump
+}
+
+// logStateDump logs the given state as one json message per object, with the id of
+// the object attached as a structured field
+func logStateDump(dump stateDump) {
+	ui.Info("State dump: profile=%s dryRun=%t maxFans=%t", dump.Profile, dump.DryRun, dump.MaxFans)
+
+	for _, id := range util.SortedKeys(dump.Sensors) {
+		ui.WithSensor(id).Info("State dump of sensor %s: %s", id, toJson(dump.Sensors[id]))
+	}
+	for _, id := range util.SortedKeys(dump.Curves) {
+		ui.WithCurve(id).Info("State dump of curve %s: %s", id, toJson(dump.Curves[id]))
+	}
+	for _, id := range util.SortedKeys(dump.Fans) {
+		ui.WithFan(id).Info("State dump of fan %s: %s", id, toJson(dump.Fans[id]))
+	}
+}
+
+func toJson(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
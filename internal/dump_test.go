@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectStateDump(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	_ = os.WriteFile(path.Join(dir, "temp"), []byte("50000"), 0644)
+	_ = os.WriteFile(path.Join(dir, "pwm"), []byte("100"), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	objects := newDaemonObjects(ctx, persistence.NewPersistence(path.Join(dir, "fan2go.db")))
+	defer stopObjects(cancel, objects)
+
+	err := objects.apply(createTestConfig(dir, 80))
+	assert.NoError(t, err)
+	sensors.ReportValue("cpu", 50000)
+	// the values of the fan are cached by its controller once it started
+	fanController, _ := controller.GetFanController("fan")
+	assert.Eventually(t, func() bool {
+		return fanController.GetFanSnapshot().Pwm != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// WHEN
+	dump := collectStateDump()
+
+	// THEN
+	assert.Len(t, dump.Sensors, 1)
+	assert.NotNil(t, dump.Sensors["cpu"].Last)
+	assert.Equal(t, 50000.0, dump.Sensors["cpu"].Last.Value)
+	assert.Equal(t, 50000.0, dump.Sensors["cpu"].MovingAvg)
+
+	assert.Equal(t, []string{"cpu"}, dump.Curves["curve"].Sensors)
+
+	fan, ok := dump.Fans["fan"]
+	assert.True(t, ok)
+	assert.Equal(t, "curve", fan.Curve)
+	assert.Equal(t, 100, *fan.Pwm)
+	assert.Nil(t, fan.Rpm)
+	assert.NotNil(t, fan.Statistics)
+	assert.Equal(t, 255, fan.Calibration.MaxPwm)
+}
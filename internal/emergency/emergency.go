@@ -0,0 +1,101 @@
+package emergency
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/alerting"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/markusressel/fan2go/internal/util"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	shutdownExecutable = "/sbin/shutdown"
+	execTimeout        = 30 * time.Second
+)
+
+var (
+	// criticalSensors maps from sensor id -> critical config of all sensors
+	// which are currently at or above their critical temperature
+	criticalSensors     = map[string]configuration.CriticalConfig{}
+	criticalSensorsLock sync.RWMutex
+
+	// runCommand executes emergency commands, replaced in tests
+	runCommand = func(executable string, args []string) error {
+		_, err := util.SafeCmdExecution(executable, args, execTimeout)
+		return err
+	}
+)
+
+// Check compares the given value (in milli-degrees) of a sensor with its critical temperature,
+// and takes the configured emergency actions once it is reached
+func Check(sensorId string, config configuration.CriticalConfig, value float64) {
+	temp := value / 1000
+
+	criticalSensorsLock.Lock()
+	_, active := criticalSensors[sensorId]
+	entered := !active && temp >= config.Temp
+	left := active && temp < config.Temp-config.Hysteresis
+	if entered {
+		criticalSensors[sensorId] = config
+	} else if left {
+		delete(criticalSensors, sensorId)
+	}
+	criticalSensorsLock.Unlock()
+
+	if entered {
+		ui.WithSensor(sensorId).ErrorAndNotify("Critical Temperature", "Sensor %s reached critical temperature: %.1f°C", sensorId, temp)
+		alerting.Fire(alerting.EventCriticalTemperature, sensorId, "Sensor %s reached critical temperature: %.1f°C", sensorId, temp)
+		go runActions(sensorId, config, temp)
+	} else if left {
+		ui.WithSensor(sensorId).Info("Sensor %s is below its critical temperature again: %.1f°C", sensorId, temp)
+	}
+}
+
+// IsMaxFansActive indicates whether any sensor, which requires all fans to
+// run at full speed, is currently at or above its critical temperature
+func IsMaxFansActive() bool {
+	criticalSensorsLock.RLock()
+	defer criticalSensorsLock.RUnlock()
+	for _, config := range criticalSensors {
+		if len(config.Actions) <= 0 || slices.Contains(config.Actions, configuration.CriticalActionMaxFans) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear resets the state of the given sensor, f.ex. when it is removed
+func Clear(sensorId string) {
+	criticalSensorsLock.Lock()
+	defer criticalSensorsLock.Unlock()
+	delete(criticalSensors, sensorId)
+}
+
+func runActions(sensorId string, config configuration.CriticalConfig, temp float64) {
+	if slices.Contains(config.Actions, configuration.CriticalActionExec) && config.Exec != nil {
+		replacer := strings.NewReplacer(
+			"%sensor%", sensorId,
+			"%temp%", fmt.Sprintf("%.1f", temp),
+		)
+		var args []string
+		for _, arg := range config.Exec.Args {
+			args = append(args, replacer.Replace(arg))
+		}
+		if err := runCommand(config.Exec.Exec, args); err != nil {
+			ui.WithSensor(sensorId).Error("Error running critical temperature command of sensor %s: %v", sensorId, err)
+		}
+	}
+
+	if slices.Contains(config.Actions, configuration.CriticalActionShutdown) {
+		ui.WithSensor(sensorId).Error("Shutting down, since sensor %s reached critical temperature", sensorId)
+		message := fmt.Sprintf("fan2go: sensor %s reached critical temperature", sensorId)
+		if err := runCommand(shutdownExecutable, []string{"-h", "now", message}); err != nil {
+			ui.Error("Error shutting down: %v", err)
+		}
+	}
+}
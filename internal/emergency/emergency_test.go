@@ -0,0 +1,66 @@
+package emergency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckWithHysteresis(t *testing.T) {
+	// GIVEN
+	id := "hysteresis_sensor"
+	config := configuration.CriticalConfig{
+		Temp:       90,
+		Hysteresis: 5,
+	}
+	defer Clear(id)
+
+	// WHEN
+	Check(id, config, 85000)
+	belowCritical := IsMaxFansActive()
+	Check(id, config, 90000)
+	atCritical := IsMaxFansActive()
+	Check(id, config, 86000)
+	withinHysteresis := IsMaxFansActive()
+	Check(id, config, 84000)
+	belowHysteresis := IsMaxFansActive()
+
+	// THEN
+	assert.False(t, belowCritical)
+	assert.True(t, atCritical)
+	assert.True(t, withinHysteresis)
+	assert.False(t, belowHysteresis)
+}
+
+func TestCheckWithoutMaxFansAction(t *testing.T) {
+	// GIVEN
+	id := "exec_sensor"
+	executed := make(chan []string, 1)
+	runCommand = func(executable string, args []string) error {
+		executed <- append([]string{executable}, args...)
+		return nil
+	}
+	config := configuration.CriticalConfig{
+		Temp:    90,
+		Actions: []string{configuration.CriticalActionExec},
+		Exec: &configuration.ExecConfig{
+			Exec: "/usr/local/bin/hook",
+			Args: []string{"%sensor%", "%temp%"},
+		},
+	}
+	defer Clear(id)
+
+	// WHEN
+	Check(id, config, 95000)
+
+	// THEN
+	assert.False(t, IsMaxFansActive())
+	select {
+	case command := <-executed:
+		assert.Equal(t, []string{"/usr/local/bin/hook", id, "95.0"}, command)
+	case <-time.After(time.Second):
+		assert.Fail(t, "critical temperature command was not executed")
+	}
+}
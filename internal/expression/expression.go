@@ -0,0 +1,76 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Expression is a parsed arithmetic expression, f.ex. "max(cpu, gpu * 0.8) > 70 ? 255 : 128".
+//
+// Supported are numbers, variables, the operators + - * / % < <= > >= == != && || ! ?:,
+// parentheses and the functions listed in Functions. Comparisons and logical operators
+// return 1 for true and 0 for false, any value other than 0 is considered true.
+type Expression struct {
+	source string
+	root   node
+}
+
+// Parse parses the given expression
+func Parse(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEnd {
+		return nil, p.unexpected()
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Variables returns the names of all variables used in the expression, sorted by name
+func (e *Expression) Variables() []string {
+	seen := map[string]bool{}
+	e.root.collectVariables(seen)
+
+	var result []string
+	for name := range seen {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Evaluate calculates the value of the expression using the given values of its variables
+func (e *Expression) Evaluate(variables map[string]float64) (float64, error) {
+	result, err := e.root.evaluate(variables)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, fmt.Errorf("expression '%s' has no finite result", e.source)
+	}
+	return result, nil
+}
+
+func toBool(value float64) bool {
+	return value != 0
+}
+
+func fromBool(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	variables := map[string]float64{"cpu": 60, "gpu": 80, "nvme_0": 40}
+
+	for _, tc := range []struct {
+		expression string
+		expected   float64
+	}{
+		{"42", 42},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"-cpu + 100", 40},
+		{"7 % 4", 3},
+		{"10 - 4 - 3", 3},
+		{"cpu < gpu", 1},
+		{"cpu >= gpu", 0},
+		{"cpu == 60 && !(gpu != 80)", 1},
+		{"cpu > 70 || nvme_0 > 30", 1},
+		{"max(cpu, gpu * 0.8) > 70 ? 255 : 128", 128},
+		{"cpu > 50 ? gpu > 90 ? 255 : 200 : 100", 200},
+		{"min(cpu, gpu, nvme_0)", 40},
+		{"avg(cpu, gpu)", 70},
+		{"abs(-2.5)", 2.5},
+		{"round(2.5) + floor(2.7) + ceil(2.1)", 8},
+		{"pow(2, 3) + sqrt(16)", 12},
+		{"clamp(300, 0, 255)", 255},
+		{"lerp(100, 200, 0.25)", 125},
+		{"scale(cpu, 40, 80, 0, 255)", 127.5},
+		{"scale(gpu, 40, 70, 50, 255)", 255},
+		{"scale(20, 40, 70, 50, 255)", 50},
+		{"true && !false", 1},
+	} {
+		// GIVEN
+		expression, err := Parse(tc.expression)
+		assert.NoError(t, err, tc.expression)
+
+		// WHEN
+		result, err := expression.Evaluate(variables)
+
+		// THEN
+		assert.NoError(t, err, tc.expression)
+		assert.Equal(t, tc.expected, result, tc.expression)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	// GIVEN
+	expression, err := Parse("max(gpu, cpu) > 70 ? cpu : scale(gpu, 0, 100, 0, 255)")
+	assert.NoError(t, err)
+
+	// WHEN
+	result := expression.Variables()
+
+	// THEN
+	assert.Equal(t, []string{"cpu", "gpu"}, result)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		expression string
+		expected   string
+	}{
+		{"", "unexpected end of expression"},
+		{"1 +", "unexpected end of expression"},
+		{"(1 + 2", "expected ')' at end of expression"},
+		{"1 2", "unexpected '2' at position 3"},
+		{"cpu > 70 ? 255", "expected ':' at end of expression"},
+		{"cpu # 2", "unexpected character '#' at position 5"},
+		{"foo(1)", "unknown function 'foo' at position 1"},
+		{"clamp(1, 2)", "function 'clamp' at position 1: expected 3 argument(s)"},
+		{"max()", "function 'max' at position 1: expected at least 1 argument(s)"},
+		{"1.2.3", "invalid number '1.2.3' at position 1"},
+	} {
+		// WHEN
+		_, err := Parse(tc.expression)
+
+		// THEN
+		assert.EqualError(t, err, tc.expected, tc.expression)
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	for _, tc := range []struct {
+		expression string
+		expected   string
+	}{
+		{"cpu + 1", "unknown variable 'cpu'"},
+		{"1 / 0", "division by zero"},
+		{"1 % 0", "division by zero"},
+		{"sqrt(-1)", "expression 'sqrt(-1)' has no finite result"},
+	} {
+		// GIVEN
+		expression, err := Parse(tc.expression)
+		assert.NoError(t, err, tc.expression)
+
+		// WHEN
+		_, err = expression.Evaluate(map[string]float64{})
+
+		// THEN
+		assert.EqualError(t, err, tc.expected, tc.expression)
+	}
+}
+
+func TestEvaluateShortCircuit(t *testing.T) {
+	// GIVEN
+	expression, err := Parse("cpu > 0 && 1 / cpu > 0.5")
+	assert.NoError(t, err)
+
+	// WHEN
+	result, err := expression.Evaluate(map[string]float64{"cpu": 0})
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, result)
+}
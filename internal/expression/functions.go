@@ -0,0 +1,78 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+)
+
+// Function is a function that can be called in an expression
+type Function struct {
+	// MinArgs is the minimum number of arguments
+	MinArgs int
+	// MaxArgs is the maximum number of arguments, -1 if unlimited
+	MaxArgs int
+	Call    func(args []float64) float64
+}
+
+func (f Function) describeArgs() string {
+	switch {
+	case f.MaxArgs < 0:
+		return fmt.Sprintf("expected at least %d argument(s)", f.MinArgs)
+	case f.MinArgs == f.MaxArgs:
+		return fmt.Sprintf("expected %d argument(s)", f.MinArgs)
+	default:
+		return fmt.Sprintf("expected %d to %d arguments", f.MinArgs, f.MaxArgs)
+	}
+}
+
+// Functions contains all functions that can be called in an expression
+var Functions = map[string]Function{
+	"min": {MinArgs: 1, MaxArgs: -1, Call: func(args []float64) float64 {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = math.Min(result, arg)
+		}
+		return result
+	}},
+	"max": {MinArgs: 1, MaxArgs: -1, Call: func(args []float64) float64 {
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = math.Max(result, arg)
+		}
+		return result
+	}},
+	"avg": {MinArgs: 1, MaxArgs: -1, Call: func(args []float64) float64 {
+		sum := 0.0
+		for _, arg := range args {
+			sum += arg
+		}
+		return sum / float64(len(args))
+	}},
+	"abs":   {MinArgs: 1, MaxArgs: 1, Call: func(args []float64) float64 { return math.Abs(args[0]) }},
+	"round": {MinArgs: 1, MaxArgs: 1, Call: func(args []float64) float64 { return math.Round(args[0]) }},
+	"floor": {MinArgs: 1, MaxArgs: 1, Call: func(args []float64) float64 { return math.Floor(args[0]) }},
+	"ceil":  {MinArgs: 1, MaxArgs: 1, Call: func(args []float64) float64 { return math.Ceil(args[0]) }},
+	"sqrt":  {MinArgs: 1, MaxArgs: 1, Call: func(args []float64) float64 { return math.Sqrt(args[0]) }},
+	"pow":   {MinArgs: 2, MaxArgs: 2, Call: func(args []float64) float64 { return math.Pow(args[0], args[1]) }},
+	// clamp(x, min, max) limits x to [min..max]
+	"clamp": {MinArgs: 3, MaxArgs: 3, Call: func(args []float64) float64 {
+		return math.Max(args[1], math.Min(args[2], args[0]))
+	}},
+	// lerp(a, b, t) interpolates linearly between a (t = 0) and b (t = 1)
+	"lerp": {MinArgs: 3, MaxArgs: 3, Call: func(args []float64) float64 {
+		return args[0] + (args[1]-args[0])*args[2]
+	}},
+	// scale(x, inMin, inMax, outMin, outMax) maps x from [inMin..inMax] to [outMin..outMax],
+	// values outside of the input range are mapped to outMin or outMax
+	"scale": {MinArgs: 5, MaxArgs: 5, Call: func(args []float64) float64 {
+		x, inMin, inMax, outMin, outMax := args[0], args[1], args[2], args[3], args[4]
+		if inMax == inMin {
+			if x < inMin {
+				return outMin
+			}
+			return outMax
+		}
+		t := math.Max(0, math.Min(1, (x-inMin)/(inMax-inMin)))
+		return outMin + (outMax-outMin)*t
+	}},
+}
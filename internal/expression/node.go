@@ -0,0 +1,169 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+)
+
+type node interface {
+	evaluate(variables map[string]float64) (float64, error)
+	collectVariables(seen map[string]bool)
+}
+
+type numberNode float64
+
+func (n numberNode) evaluate(variables map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+func (n numberNode) collectVariables(seen map[string]bool) {
+}
+
+type variableNode string
+
+func (n variableNode) evaluate(variables map[string]float64) (float64, error) {
+	value, ok := variables[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("unknown variable '%s'", string(n))
+	}
+	return value, nil
+}
+
+func (n variableNode) collectVariables(seen map[string]bool) {
+	seen[string(n)] = true
+}
+
+type unaryNode struct {
+	operator string
+	operand  node
+}
+
+func (n unaryNode) evaluate(variables map[string]float64) (float64, error) {
+	value, err := n.operand.evaluate(variables)
+	if err != nil {
+		return 0, err
+	}
+	if n.operator == "!" {
+		return fromBool(!toBool(value)), nil
+	}
+	return -value, nil
+}
+
+func (n unaryNode) collectVariables(seen map[string]bool) {
+	n.operand.collectVariables(seen)
+}
+
+type binaryNode struct {
+	operator string
+	left     node
+	right    node
+}
+
+func (n binaryNode) evaluate(variables map[string]float64) (float64, error) {
+	left, err := n.left.evaluate(variables)
+	if err != nil {
+		return 0, err
+	}
+
+	// short-circuit logical operators
+	switch n.operator {
+	case "&&":
+		if !toBool(left) {
+			return 0, nil
+		}
+	case "||":
+		if toBool(left) {
+			return 1, nil
+		}
+	}
+
+	right, err := n.right.evaluate(variables)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.operator {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return left / right, nil
+	case "%":
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return math.Mod(left, right), nil
+	case "<":
+		return fromBool(left < right), nil
+	case "<=":
+		return fromBool(left <= right), nil
+	case ">":
+		return fromBool(left > right), nil
+	case ">=":
+		return fromBool(left >= right), nil
+	case "==":
+		return fromBool(left == right), nil
+	case "!=":
+		return fromBool(left != right), nil
+	case "&&", "||":
+		return fromBool(toBool(right)), nil
+	}
+	return 0, fmt.Errorf("unsupported operator '%s'", n.operator)
+}
+
+func (n binaryNode) collectVariables(seen map[string]bool) {
+	n.left.collectVariables(seen)
+	n.right.collectVariables(seen)
+}
+
+type ternaryNode struct {
+	condition node
+	ifTrue    node
+	ifFalse   node
+}
+
+func (n ternaryNode) evaluate(variables map[string]float64) (float64, error) {
+	condition, err := n.condition.evaluate(variables)
+	if err != nil {
+		return 0, err
+	}
+	if toBool(condition) {
+		return n.ifTrue.evaluate(variables)
+	}
+	return n.ifFalse.evaluate(variables)
+}
+
+func (n ternaryNode) collectVariables(seen map[string]bool) {
+	n.condition.collectVariables(seen)
+	n.ifTrue.collectVariables(seen)
+	n.ifFalse.collectVariables(seen)
+}
+
+type callNode struct {
+	function Function
+	args     []node
+}
+
+func (n callNode) evaluate(variables map[string]float64) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.evaluate(variables)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = value
+	}
+	return n.function.Call(args), nil
+}
+
+func (n callNode) collectVariables(seen map[string]bool) {
+	for _, arg := range n.args {
+		arg.collectVariables(seen)
+	}
+}
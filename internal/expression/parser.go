@@ -0,0 +1,252 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenNumber
+	tokenIdentifier
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// position is the offset of the token in the source, starting at 1
+	position int
+}
+
+// operators contains all operators, longer ones first so they take precedence
+var operators = []string{"<=", ">=", "==", "!=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")", ","}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), position: start + 1})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: string(runes[start:i]), position: start + 1})
+		default:
+			matched := false
+			for _, operator := range operators {
+				if strings.HasPrefix(string(runes[i:]), operator) {
+					tokens = append(tokens, token{kind: tokenOperator, text: operator, position: i + 1})
+					i += len([]rune(operator))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character '%c' at position %d", r, i+1)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEnd, position: len(runes) + 1}), nil
+}
+
+// parser is a recursive descent parser, each parse function handles one level of operator precedence
+type parser struct {
+	tokens []token
+	index  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.index]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.index]
+	if t.kind != tokenEnd {
+		p.index++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of the given operators
+func (p *parser) accept(operators ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return "", false
+	}
+	for _, operator := range operators {
+		if t.text == operator {
+			p.index++
+			return operator, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(operator string) error {
+	if _, ok := p.accept(operator); !ok {
+		t := p.peek()
+		if t.kind == tokenEnd {
+			return fmt.Errorf("expected '%s' at end of expression", operator)
+		}
+		return fmt.Errorf("expected '%s' at position %d, got '%s'", operator, t.position, t.text)
+	}
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokenEnd {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected '%s' at position %d", t.text, t.position)
+}
+
+func (p *parser) parseTernary() (node, error) {
+	condition, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return condition, nil
+	}
+	ifTrue, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	ifFalse, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	return ternaryNode{condition: condition, ifTrue: ifTrue, ifFalse: ifFalse}, nil
+}
+
+// binaryPrecedence lists the binary operators from the lowest to the highest precedence
+var binaryPrecedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level >= len(binaryPrecedence) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator, ok := p.accept(binaryPrecedence[level]...)
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if operator, ok := p.accept("-", "!", "+"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if operator == "+" {
+			return operand, nil
+		}
+		return unaryNode{operator: operator, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenNumber:
+		p.next()
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s' at position %d", t.text, t.position)
+		}
+		return numberNode(value), nil
+	case tokenIdentifier:
+		p.next()
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(t)
+		}
+		switch t.text {
+		case "true":
+			return numberNode(1), nil
+		case "false":
+			return numberNode(0), nil
+		}
+		return variableNode(t.text), nil
+	case tokenOperator:
+		if t.text == "(" {
+			p.next()
+			inner, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	function, ok := Functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function '%s' at position %d", name.text, name.position)
+	}
+
+	var args []node
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(","); ok {
+				continue
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	if len(args) < function.MinArgs || (function.MaxArgs >= 0 && len(args) > function.MaxArgs) {
+		return nil, fmt.Errorf("function '%s' at position %d: %s", name.text, name.position, function.describeArgs())
+	}
+	return callNode{function: function, args: args}, nil
+}
@@ -22,15 +22,19 @@ func (fan CmdFan) GetId() string {
 	return fan.Config.ID
 }
 
+func (fan CmdFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
 func (fan CmdFan) GetStartPwm() int {
-	return 1
+	return getConfiguredStartPwm(fan.Config)
 }
 
 func (fan *CmdFan) SetStartPwm(pwm int, force bool) {
 }
 
 func (fan CmdFan) GetMinPwm() int {
-	return MinPwmValue
+	return getConfiguredMinPwm(fan.Config)
 }
 
 func (fan *CmdFan) SetMinPwm(pwm int, force bool) {
@@ -38,7 +42,7 @@ func (fan *CmdFan) SetMinPwm(pwm int, force bool) {
 }
 
 func (fan CmdFan) GetMaxPwm() int {
-	return MaxPwmValue
+	return getConfiguredMaxPwm(fan.Config)
 }
 
 func (fan *CmdFan) SetMaxPwm(pwm int, force bool) {
@@ -58,7 +62,7 @@ func (fan *CmdFan) GetRpm() (int, error) {
 		return 0, err
 	}
 
-	rpm, err := strconv.ParseFloat(result, 64)
+	rpm, err := strconv.ParseFloat(strings.TrimSpace(result), 64)
 	if err != nil {
 		ui.Warning("Unable to read int from command output: %s", conf.Exec)
 		return 0, err
@@ -70,15 +74,19 @@ func (fan *CmdFan) GetRpm() (int, error) {
 }
 
 func (fan CmdFan) GetRpmAvg() float64 {
-	return 0
+	return fan.MovingAvg
 }
 
 func (fan *CmdFan) SetRpmAvg(rpm float64) {
-	// not supported
+	fan.MovingAvg = rpm
 }
 
 func (fan *CmdFan) GetPwm() (result int, err error) {
 	conf := fan.Config.Cmd.GetPwm
+	if conf == nil {
+		// no way to read the current value, return the last one we have set instead
+		return fan.Pwm, nil
+	}
 
 	timeout := 2 * time.Second
 	output, err := util.SafeCmdExecution(conf.Exec, conf.Args, timeout)
@@ -86,7 +94,7 @@ func (fan *CmdFan) GetPwm() (result int, err error) {
 		return 0, err
 	}
 
-	pwm, err := strconv.ParseFloat(output, 64)
+	pwm, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
 	if err != nil {
 		ui.Warning("Unable to read int from command output: %s", conf.Exec)
 		return 0, err
@@ -112,6 +120,8 @@ func (fan *CmdFan) SetPwm(pwm int) (err error) {
 		return fmt.Errorf("%s", err.Error())
 	}
 
+	fan.Pwm = pwm
+
 	return nil
 }
 
@@ -1,9 +1,14 @@
 package fans
 
 import (
+	"sync"
+
 	"fmt"
-	"github.com/markusressel/fan2go/internal/configuration"
+	"math"
 	"sort"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
 )
 
 const (
@@ -16,6 +21,9 @@ type FeatureFlag int
 const (
 	FeatureRpmSensor   FeatureFlag = 0
 	FeatureControlMode FeatureFlag = 1
+	// FeatureMeasuredCurve indicates that the fan curve data of the fan is measured by its initialization
+	// sequence, fans without it share a linear curve which doesn't reflect their actual RPM
+	FeatureMeasuredCurve FeatureFlag = 2
 )
 
 type ControlMode int
@@ -29,13 +37,73 @@ const (
 	ControlModeAutomatic ControlMode = 2
 )
 
+const (
+	// PwmModeDC is the pwmX_mode value of a fan driven by varying its voltage
+	PwmModeDC = 0
+	// PwmModePWM is the pwmX_mode value of a fan driven using a pwm signal
+	PwmModePWM = 1
+)
+
 var (
-	FanMap = map[string]Fan{}
+	// fanMap contains all active fans. It is read concurrently,
+	// so it must only be accessed using the functions below.
+	fanMap     = map[string]Fan{}
+	fanMapLock sync.RWMutex
 )
 
+// GetFanMap returns all fans by id, the result must not be modified
+func GetFanMap() map[string]Fan {
+	fanMapLock.RLock()
+	defer fanMapLock.RUnlock()
+	return fanMap
+}
+
+// GetFan returns the fan with the given id
+func GetFan(id string) (Fan, bool) {
+	fanMapLock.RLock()
+	defer fanMapLock.RUnlock()
+	value, ok := fanMap[id]
+	return value, ok
+}
+
+// SetFanMap replaces all fans, the given map must not be modified afterwards
+func SetFanMap(values map[string]Fan) {
+	fanMapLock.Lock()
+	defer fanMapLock.Unlock()
+	fanMap = values
+}
+
+// SetFan adds or replaces the fan with the given id
+func SetFan(id string, value Fan) {
+	fanMapLock.Lock()
+	defer fanMapLock.Unlock()
+	values := make(map[string]Fan, len(fanMap)+1)
+	for key, v := range fanMap {
+		values[key] = v
+	}
+	values[id] = value
+	fanMap = values
+}
+
+// RemoveFan removes the fan with the given id
+func RemoveFan(id string) {
+	fanMapLock.Lock()
+	defer fanMapLock.Unlock()
+	values := make(map[string]Fan, len(fanMap))
+	for key, v := range fanMap {
+		if key != id {
+			values[key] = v
+		}
+	}
+	fanMap = values
+}
+
 type Fan interface {
 	GetId() string
 
+	// GetConfig returns the configuration of this fan
+	GetConfig() configuration.FanConfig
+
 	// GetMinPwm returns the lowest PWM value where the fans are still spinning, when spinning previously
 	GetMinPwm() int
 	SetMinPwm(pwm int, force bool)
@@ -77,6 +145,25 @@ type Fan interface {
 }
 
 func NewFan(config configuration.FanConfig) (Fan, error) {
+	if config.Group != nil {
+		return NewGroupFan(config)
+	}
+
+	if config.DellSmm != nil {
+		hwMonConfig := config
+		hwMonConfig.HwMon = &config.DellSmm.HwMonFanConfig
+		return &DellSmmFan{
+			HwMonFan: HwMonFan{
+				Label:    config.ID,
+				Index:    config.DellSmm.Index,
+				MinPwm:   config.MinPwm,
+				StartPwm: config.StartPwm,
+				MaxPwm:   config.MaxPwm,
+				Config:   hwMonConfig,
+			},
+		}, nil
+	}
+
 	if config.HwMon != nil {
 		return &HwMonFan{
 			Label:    config.ID,
@@ -97,28 +184,154 @@ func NewFan(config configuration.FanConfig) (Fan, error) {
 	if config.Cmd != nil {
 		return &CmdFan{
 			Config: config,
+			// the current value might not be readable, assume full speed
+			Pwm: MaxPwmValue,
 		}, nil
 	}
 
+	if config.Ipmi != nil {
+		return &IpmiFan{
+			Config: config,
+			// the current duty might not be readable, assume full speed
+			Pwm:  MaxPwmValue,
+			Mode: ControlModeAutomatic,
+		}, nil
+	}
+
+	if config.Redfish != nil {
+		return NewRedfishFan(config), nil
+	}
+
+	if config.Mqtt != nil {
+		return NewMqttFan(config), nil
+	}
+
+	if config.UsbHid != nil {
+		return NewUsbHidFan(config)
+	}
+
+	if config.Liquidctl != nil {
+		return &LiquidctlFan{
+			Config: config,
+			// the current value is not known until we have set it, assume full speed
+			Pwm: MaxPwmValue,
+		}, nil
+	}
+
+	if config.Plugin != nil {
+		return NewPluginFan(config)
+	}
+
 	return nil, fmt.Errorf("no matching fan type for fan: %s", config.ID)
 }
 
+// GetManualControlMode returns the control mode written to control the given fan manually
+func GetManualControlMode(fan Fan) ControlMode {
+	config := fan.GetConfig().PwmEnable
+	if config != nil && config.Manual != nil {
+		return ControlMode(*config.Manual)
+	}
+	return ControlModePWM
+}
+
+// ReassertPwm writes the last set pwm value of the given fan (or its members) again, if the fan requires it,
+// since its firmware may change its speed on its own. It is called by the fan controller on every update.
+func ReassertPwm(fan Fan) error {
+	switch f := fan.(type) {
+	case *DellSmmFan:
+		return f.reassertPwm()
+	case *GroupFan:
+		var err error
+		for _, member := range f.Members {
+			if memberErr := ReassertPwm(member); memberErr != nil && err == nil {
+				err = memberErr
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// GetRestoreControlMode returns the control mode written when fan2go stops controlling the given fan,
+// original is the control mode the fan had when fan2go started
+func GetRestoreControlMode(fan Fan, original ControlMode) ControlMode {
+	config := fan.GetConfig().PwmEnable
+	if config != nil && config.Restore != nil {
+		return ControlMode(*config.Restore)
+	}
+	return original
+}
+
+// GetConfiguredPwmMode returns the pwmX_mode value for the pwm mode configured for the given hwmon fan,
+// false if the mode of the chip should be left untouched
+func GetConfiguredPwmMode(config configuration.HwMonFanConfig) (int, bool) {
+	switch config.PwmMode {
+	case configuration.PwmModeDC:
+		return PwmModeDC, true
+	case configuration.PwmModePWM:
+		return PwmModePWM, true
+	}
+	return 0, false
+}
+
+// PwmModeName returns the name of the given pwmX_mode value
+func PwmModeName(value int) string {
+	if value == PwmModeDC {
+		return "DC"
+	}
+	return "PWM"
+}
+
+// ApplyCurveAdjustment applies the curveFactor and curveOffset of the given config to a curve value,
+// the result is coerced to [0..255]
+func ApplyCurveAdjustment(config configuration.FanConfig, value int) int {
+	result := float64(value)
+	if config.CurveFactor != 0 {
+		result *= config.CurveFactor
+	}
+	result = math.Round(result) + float64(config.CurveOffset)
+	return int(util.Coerce(result, MinPwmValue, MaxPwmValue))
+}
+
+// GetPwmLimit returns the highest pwm value that may be written to the fan with the given config,
+// which is its pwmLimit or the global pwmLimit, if any
+func GetPwmLimit(config configuration.FanConfig) (int, bool) {
+	if config.PwmLimit != nil {
+		return *config.PwmLimit, true
+	}
+	if configuration.CurrentConfig.PwmLimit != nil {
+		return *configuration.CurrentConfig.PwmLimit, true
+	}
+	return 0, false
+}
+
 // ComputePwmBoundaries calculates the startPwm and maxPwm values for a fan based on its fan curve data
 func ComputePwmBoundaries(fan Fan) (startPwm int, maxPwm int) {
 	userStartPwm := fan.GetStartPwm()
+	startPwm, maxPwm = ComputeCurveBoundaries(*fan.GetFanCurveData())
+
+	if userStartPwm < 255 {
+		startPwm = userStartPwm
+	}
+
+	return startPwm, maxPwm
+}
+
+// ComputeCurveBoundaries calculates the lowest PWM value at which the fan is spinning, and the PWM value
+// yielding the highest RPM, based on the given fan curve data (pwm -> rpm)
+func ComputeCurveBoundaries(pwmRpmMap map[int]float64) (startPwm int, maxPwm int) {
 	startPwm = 255
 	maxPwm = 255
-	pwmRpmMap := fan.GetFanCurveData()
 
 	var keys []int
-	for pwm := range *pwmRpmMap {
+	for pwm := range pwmRpmMap {
 		keys = append(keys, pwm)
 	}
 	sort.Ints(keys)
 
 	maxRpm := 0
 	for _, pwm := range keys {
-		avgRpm := int((*pwmRpmMap)[pwm])
+		avgRpm := int(pwmRpmMap[pwm])
 		if avgRpm > maxRpm {
 			maxRpm = avgRpm
 			maxPwm = pwm
@@ -129,9 +342,72 @@ func ComputePwmBoundaries(fan Fan) (startPwm int, maxPwm int) {
 		}
 	}
 
-	if userStartPwm < 255 {
-		startPwm = userStartPwm
+	return startPwm, maxPwm
+}
+
+// GetMaxRpm returns the highest RPM of the given fan curve data (pwm -> rpm)
+func GetMaxRpm(pwmRpmMap map[int]float64) float64 {
+	maxRpm := 0.0
+	for _, rpm := range pwmRpmMap {
+		maxRpm = math.Max(maxRpm, rpm)
 	}
+	return maxRpm
+}
 
-	return startPwm, maxPwm
+// FindPwmForRpm returns the lowest PWM value reaching the given RPM, based on the given fan curve data
+// (pwm -> rpm). Values in between two measurements are interpolated linearly, the PWM value yielding
+// the highest RPM is returned if the given RPM is never reached.
+func FindPwmForRpm(pwmRpmMap map[int]float64, rpm float64) int {
+	var keys []int
+	for pwm := range pwmRpmMap {
+		keys = append(keys, pwm)
+	}
+	sort.Ints(keys)
+	if len(keys) <= 0 {
+		return MaxPwmValue
+	}
+
+	for idx, pwm := range keys {
+		current := pwmRpmMap[pwm]
+		if current < rpm {
+			continue
+		}
+		if idx == 0 {
+			return pwm
+		}
+		previousPwm := keys[idx-1]
+		previous := pwmRpmMap[previousPwm]
+		ratio := (rpm - previous) / (current - previous)
+		return previousPwm + int(math.Ceil(ratio*float64(pwm-previousPwm)))
+	}
+
+	_, maxPwm := ComputeCurveBoundaries(pwmRpmMap)
+	return maxPwm
+}
+
+// getConfiguredMinPwm returns the minPwm override of the given config for fans
+// that are not analyzed by fan2go, which only applies if the fan should never stop
+func getConfiguredMinPwm(config configuration.FanConfig) int {
+	if config.NeverStop && config.MinPwm != nil {
+		return *config.MinPwm
+	}
+	return MinPwmValue
+}
+
+// getConfiguredStartPwm returns the startPwm override of the given config for fans
+// that are not analyzed by fan2go
+func getConfiguredStartPwm(config configuration.FanConfig) int {
+	if config.StartPwm != nil {
+		return *config.StartPwm
+	}
+	return 1
+}
+
+// getConfiguredMaxPwm returns the maxPwm override of the given config for fans
+// that are not analyzed by fan2go
+func getConfiguredMaxPwm(config configuration.FanConfig) int {
+	if config.MaxPwm != nil {
+		return *config.MaxPwm
+	}
+	return MaxPwmValue
 }
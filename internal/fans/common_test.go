@@ -0,0 +1,54 @@
+package fans
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyCurveAdjustment(t *testing.T) {
+	// GIVEN
+	unchanged := configuration.FanConfig{}
+	slower := configuration.FanConfig{CurveFactor: 0.9}
+	offset := configuration.FanConfig{CurveFactor: 0.5, CurveOffset: 20}
+	negative := configuration.FanConfig{CurveOffset: -50}
+
+	// WHEN
+	unchangedResult := ApplyCurveAdjustment(unchanged, 200)
+	slowerResult := ApplyCurveAdjustment(slower, 200)
+	offsetResult := ApplyCurveAdjustment(offset, 100)
+	negativeResult := ApplyCurveAdjustment(negative, 30)
+	cappedResult := ApplyCurveAdjustment(configuration.FanConfig{CurveFactor: 1.5}, 200)
+
+	// THEN
+	assert.Equal(t, 200, unchangedResult)
+	assert.Equal(t, 180, slowerResult)
+	assert.Equal(t, 70, offsetResult)
+	assert.Equal(t, 0, negativeResult)
+	assert.Equal(t, 255, cappedResult)
+}
+
+func TestFindPwmForRpm(t *testing.T) {
+	// GIVEN
+	curveData := map[int]float64{
+		0:   0,
+		50:  0,
+		100: 800,
+		200: 1800,
+		255: 1750,
+	}
+
+	// WHEN
+	stopped := FindPwmForRpm(curveData, 0)
+	exact := FindPwmForRpm(curveData, 800)
+	interpolated := FindPwmForRpm(curveData, 400)
+	unreachable := FindPwmForRpm(curveData, 2500)
+
+	// THEN
+	assert.Equal(t, 0, stopped)
+	assert.Equal(t, 100, exact)
+	assert.Equal(t, 75, interpolated)
+	assert.Equal(t, 200, unreachable)
+	assert.Equal(t, 1800.0, GetMaxRpm(curveData))
+}
@@ -0,0 +1,49 @@
+package fans
+
+import (
+	"time"
+
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+const (
+	defaultDellSmmReassertInterval = 10 * time.Second
+)
+
+// DellSmmFan is a fan controlled by the dell_smm_hwmon driver.
+// These fans only support a few discrete levels, which is handled by the pwm map
+// of the controller, and the BIOS may change their speed on its own at any time,
+// which is why the last set value is written again periodically, see ReassertPwm.
+type DellSmmFan struct {
+	HwMonFan
+
+	// LastSetPwm is the last value written to the fan, if any
+	LastSetPwm *int `json:"lastSetPwm"`
+	lastWrite  time.Time
+}
+
+// reassertPwm writes the last set value again, if the reassert interval has passed
+func (fan *DellSmmFan) reassertPwm() error {
+	interval := fan.Config.DellSmm.ReassertInterval
+	if interval <= 0 {
+		interval = defaultDellSmmReassertInterval
+	}
+
+	if fan.LastSetPwm == nil || time.Since(fan.lastWrite) < interval {
+		return nil
+	}
+	ui.Debug("Reasserting PWM of '%s' to %d ...", fan.GetId(), *fan.LastSetPwm)
+	return fan.SetPwm(*fan.LastSetPwm)
+}
+
+func (fan *DellSmmFan) SetPwm(pwm int) (err error) {
+	err = fan.HwMonFan.SetPwm(pwm)
+	if err != nil {
+		return err
+	}
+
+	fan.LastSetPwm = &pwm
+	fan.lastWrite = time.Now()
+
+	return nil
+}
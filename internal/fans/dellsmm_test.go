@@ -0,0 +1,64 @@
+package fans
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDellSmmFan_ReassertPwm(t *testing.T) {
+	// GIVEN
+	pwmPath := path.Join(t.TempDir(), "pwm1")
+	config := configuration.FanConfig{
+		ID: "fan",
+		DellSmm: &configuration.DellSmmFanConfig{
+			HwMonFanConfig: configuration.HwMonFanConfig{
+				PwmPath: pwmPath,
+			},
+			ReassertInterval: time.Millisecond,
+		},
+	}
+	fan, _ := NewFan(config)
+	_ = fan.SetPwm(128)
+
+	// the BIOS changes the fan speed on its own
+	_ = os.WriteFile(pwmPath, []byte("255"), 0644)
+	time.Sleep(2 * time.Millisecond)
+
+	// WHEN
+	err := ReassertPwm(fan)
+
+	// THEN
+	assert.NoError(t, err)
+	pwm, _ := fan.GetPwm()
+	assert.Equal(t, 128, pwm)
+}
+
+func TestDellSmmFan_GetPwmDoesNotWrite(t *testing.T) {
+	// GIVEN
+	pwmPath := path.Join(t.TempDir(), "pwm1")
+	config := configuration.FanConfig{
+		ID: "fan",
+		DellSmm: &configuration.DellSmmFanConfig{
+			HwMonFanConfig: configuration.HwMonFanConfig{
+				PwmPath: pwmPath,
+			},
+			ReassertInterval: time.Millisecond,
+		},
+	}
+	fan, _ := NewFan(config)
+	_ = fan.SetPwm(128)
+	_ = os.WriteFile(pwmPath, []byte("255"), 0644)
+	time.Sleep(2 * time.Millisecond)
+
+	// WHEN
+	pwm, err := fan.GetPwm()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 255, pwm)
+}
@@ -20,15 +20,19 @@ func (fan FileFan) GetId() string {
 	return fan.Config.ID
 }
 
+func (fan FileFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
 func (fan FileFan) GetStartPwm() int {
-	return 1
+	return getConfiguredStartPwm(fan.Config)
 }
 
 func (fan *FileFan) SetStartPwm(pwm int, force bool) {
 }
 
 func (fan FileFan) GetMinPwm() int {
-	return MinPwmValue
+	return getConfiguredMinPwm(fan.Config)
 }
 
 func (fan *FileFan) SetMinPwm(pwm int, force bool) {
@@ -36,7 +40,7 @@ func (fan *FileFan) SetMinPwm(pwm int, force bool) {
 }
 
 func (fan FileFan) GetMaxPwm() int {
-	return MaxPwmValue
+	return getConfiguredMaxPwm(fan.Config)
 }
 
 func (fan *FileFan) SetMaxPwm(pwm int, force bool) {
@@ -0,0 +1,66 @@
+package fans
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileFan_PwmLimitsDefault(t *testing.T) {
+	// GIVEN
+	fan := FileFan{
+		Config: configuration.FanConfig{
+			File: &configuration.FileFanConfig{},
+		},
+	}
+
+	// WHEN
+	minPwm, startPwm, maxPwm := fan.GetMinPwm(), fan.GetStartPwm(), fan.GetMaxPwm()
+
+	// THEN
+	assert.Equal(t, MinPwmValue, minPwm)
+	assert.Equal(t, 1, startPwm)
+	assert.Equal(t, MaxPwmValue, maxPwm)
+}
+
+func TestFileFan_PwmLimitsOverride(t *testing.T) {
+	// GIVEN
+	minPwm := 40
+	startPwm := 60
+	maxPwm := 200
+	fan := FileFan{
+		Config: configuration.FanConfig{
+			NeverStop: true,
+			MinPwm:    &minPwm,
+			StartPwm:  &startPwm,
+			MaxPwm:    &maxPwm,
+			File:      &configuration.FileFanConfig{},
+		},
+	}
+
+	// WHEN
+	resultMin, resultStart, resultMax := fan.GetMinPwm(), fan.GetStartPwm(), fan.GetMaxPwm()
+
+	// THEN
+	assert.Equal(t, minPwm, resultMin)
+	assert.Equal(t, startPwm, resultStart)
+	assert.Equal(t, maxPwm, resultMax)
+}
+
+func TestFileFan_MinPwmRequiresNeverStop(t *testing.T) {
+	// GIVEN
+	minPwm := 40
+	fan := FileFan{
+		Config: configuration.FanConfig{
+			MinPwm: &minPwm,
+			File:   &configuration.FileFanConfig{},
+		},
+	}
+
+	// WHEN
+	result := fan.GetMinPwm()
+
+	// THEN
+	assert.Equal(t, MinPwmValue, result)
+}
@@ -0,0 +1,171 @@
+package fans
+
+import (
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+// GroupFan controls multiple fans as a single fan, pwm values are written to all members,
+// while the rpm of the group is the lowest rpm of all members
+type GroupFan struct {
+	Config  configuration.FanConfig `json:"config"`
+	Members []Fan                   `json:"members"`
+	RpmAvg  float64                 `json:"rpmAvg"`
+}
+
+func NewGroupFan(config configuration.FanConfig) (*GroupFan, error) {
+	var members []Fan
+	for _, memberConfig := range config.Group.Members {
+		member, err := NewFan(memberConfig)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return &GroupFan{
+		Config:  config,
+		Members: members,
+	}, nil
+}
+
+func (fan GroupFan) GetId() string {
+	return fan.Config.ID
+}
+
+func (fan GroupFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
+func (fan GroupFan) GetStartPwm() int {
+	return getConfiguredStartPwm(fan.Config)
+}
+
+func (fan *GroupFan) SetStartPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan GroupFan) GetMinPwm() int {
+	return getConfiguredMinPwm(fan.Config)
+}
+
+func (fan *GroupFan) SetMinPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan GroupFan) GetMaxPwm() int {
+	return getConfiguredMaxPwm(fan.Config)
+}
+
+func (fan *GroupFan) SetMaxPwm(pwm int, force bool) {
+	// not supported
+}
+
+// GetRpm returns the lowest rpm of all members, so a single stalled member is noticed
+func (fan GroupFan) GetRpm() (int, error) {
+	if !fan.Supports(FeatureRpmSensor) {
+		return 0, nil
+	}
+	result := -1
+	for _, member := range fan.Members {
+		rpm, err := member.GetRpm()
+		if err != nil {
+			return 0, err
+		}
+		if result < 0 || rpm < result {
+			result = rpm
+		}
+	}
+	return result, nil
+}
+
+func (fan GroupFan) GetRpmAvg() float64 {
+	return fan.RpmAvg
+}
+
+func (fan *GroupFan) SetRpmAvg(rpm float64) {
+	fan.RpmAvg = rpm
+}
+
+// GetPwm returns the pwm of the first member
+func (fan *GroupFan) GetPwm() (int, error) {
+	return fan.Members[0].GetPwm()
+}
+
+// SetPwm writes the given pwm to all members, even if writing it to one of them fails
+func (fan *GroupFan) SetPwm(pwm int) (err error) {
+	for _, member := range fan.Members {
+		if memberErr := member.SetPwm(pwm); memberErr != nil && err == nil {
+			err = memberErr
+		}
+	}
+	return err
+}
+
+func (fan GroupFan) GetFanCurveData() *map[int]float64 {
+	return &interpolated
+}
+
+func (fan *GroupFan) AttachFanCurveData(curveData *map[int]float64) (err error) {
+	// not supported
+	return
+}
+
+func (fan GroupFan) GetCurveId() string {
+	return fan.Config.Curve
+}
+
+func (fan GroupFan) ShouldNeverStop() bool {
+	return fan.Config.NeverStop
+}
+
+// GetPwmEnabled returns the control mode of the first member that supports it
+func (fan GroupFan) GetPwmEnabled() (int, error) {
+	for _, member := range fan.Members {
+		if member.Supports(FeatureControlMode) {
+			return member.GetPwmEnabled()
+		}
+	}
+	return 1, nil
+}
+
+// SetPwmEnabled sets the given control mode on all members that support it
+func (fan *GroupFan) SetPwmEnabled(value ControlMode) (err error) {
+	for _, member := range fan.Members {
+		if !member.Supports(FeatureControlMode) {
+			continue
+		}
+		if memberErr := member.SetPwmEnabled(value); memberErr != nil && err == nil {
+			err = memberErr
+		}
+	}
+	return err
+}
+
+func (fan GroupFan) IsPwmAuto() (bool, error) {
+	value, err := fan.GetPwmEnabled()
+	if err != nil {
+		return false, err
+	}
+	return value > 1, nil
+}
+
+func (fan GroupFan) Supports(feature FeatureFlag) bool {
+	switch feature {
+	case FeatureControlMode:
+		// the control mode is only set on members that support it
+		for _, member := range fan.Members {
+			if member.Supports(FeatureControlMode) {
+				return true
+			}
+		}
+		return false
+	case FeatureRpmSensor:
+		// the rpm of the group is only meaningful, if all members report it
+		for _, member := range fan.Members {
+			if !member.Supports(FeatureRpmSensor) {
+				return false
+			}
+		}
+		return len(fan.Members) > 0
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package fans
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// createGroupMemberConfig returns the config of a hwmon fan whose files are located in the given directory
+func createGroupMemberConfig(t *testing.T, dir string, id string, rpm string) configuration.FanConfig {
+	config := configuration.FanConfig{
+		ID: id,
+		HwMon: &configuration.HwMonFanConfig{
+			RpmInputPath:  path.Join(dir, id+"_input"),
+			PwmPath:       path.Join(dir, id+"_pwm"),
+			PwmEnablePath: path.Join(dir, id+"_pwm_enable"),
+		},
+	}
+	assert.NoError(t, os.WriteFile(config.HwMon.RpmInputPath, []byte(rpm), 0644))
+	assert.NoError(t, os.WriteFile(config.HwMon.PwmPath, []byte("255"), 0644))
+	assert.NoError(t, os.WriteFile(config.HwMon.PwmEnablePath, []byte("2"), 0644))
+	return config
+}
+
+func TestGroupFanMirrorsPwm(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	fan, err := NewFan(configuration.FanConfig{
+		ID: "radiator",
+		Group: &configuration.GroupFanConfig{
+			Members: []configuration.FanConfig{
+				createGroupMemberConfig(t, dir, "left", "1200"),
+				createGroupMemberConfig(t, dir, "right", "1100"),
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	// WHEN
+	err = fan.SetPwm(100)
+
+	// THEN
+	assert.NoError(t, err)
+	left, _ := util.ReadIntFromFile(path.Join(dir, "left_pwm"))
+	right, _ := util.ReadIntFromFile(path.Join(dir, "right_pwm"))
+	assert.Equal(t, 100, left)
+	assert.Equal(t, 100, right)
+}
+
+func TestGroupFanReportsLowestRpm(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	fan, err := NewFan(configuration.FanConfig{
+		ID: "radiator",
+		Group: &configuration.GroupFanConfig{
+			Members: []configuration.FanConfig{
+				createGroupMemberConfig(t, dir, "left", "1200"),
+				createGroupMemberConfig(t, dir, "right", "0"),
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	// WHEN
+	rpm, err := fan.GetRpm()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rpm)
+	assert.True(t, fan.Supports(FeatureRpmSensor))
+	assert.True(t, fan.Supports(FeatureControlMode))
+}
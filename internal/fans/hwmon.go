@@ -3,12 +3,17 @@ package fans
 import (
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/markusressel/fan2go/internal/configuration"
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/markusressel/fan2go/internal/util"
 )
 
+// hwMonFanLock guards the last measured values of all hwmon fans, which are updated by the rpm monitor
+// of their fan controller, while the control loop and the apis read them
+var hwMonFanLock sync.RWMutex
+
 type HwMonFan struct {
 	Label        string                  `json:"label"`
 	Index        int                     `json:"index"`
@@ -22,11 +27,15 @@ type HwMonFan struct {
 	Pwm          int                     `json:"pwm"`
 }
 
-func (fan HwMonFan) GetId() string {
+func (fan *HwMonFan) GetId() string {
 	return fan.Config.ID
 }
 
-func (fan HwMonFan) GetMinPwm() int {
+func (fan *HwMonFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
+func (fan *HwMonFan) GetMinPwm() int {
 	// if the fan is never supposed to stop,
 	// use the lowest pwm value where the fan is still spinning
 	if fan.ShouldNeverStop() {
@@ -46,7 +55,7 @@ func (fan *HwMonFan) SetMinPwm(pwm int, force bool) {
 	}
 }
 
-func (fan HwMonFan) GetStartPwm() int {
+func (fan *HwMonFan) GetStartPwm() int {
 	if fan.StartPwm != nil {
 		return *fan.StartPwm
 	} else {
@@ -60,7 +69,7 @@ func (fan *HwMonFan) SetStartPwm(pwm int, force bool) {
 	}
 }
 
-func (fan HwMonFan) GetMaxPwm() int {
+func (fan *HwMonFan) GetMaxPwm() int {
 	if fan.MaxPwm != nil {
 		return *fan.MaxPwm
 	} else {
@@ -75,38 +84,46 @@ func (fan *HwMonFan) SetMaxPwm(pwm int, force bool) {
 }
 
 func (fan *HwMonFan) GetRpm() (int, error) {
-	if value, err := util.ReadIntFromFile(fan.Config.HwMon.RpmInputPath); err != nil {
+	if value, err := util.ReadIntFromSysfs(fan.Config.HwMon.RpmInputPath); err != nil {
 		return 0, err
 	} else {
+		hwMonFanLock.Lock()
 		fan.Rpm = value
+		hwMonFanLock.Unlock()
 		return value, nil
 	}
 }
 
-func (fan HwMonFan) GetRpmAvg() float64 {
+func (fan *HwMonFan) GetRpmAvg() float64 {
+	hwMonFanLock.RLock()
+	defer hwMonFanLock.RUnlock()
 	return fan.RpmMovingAvg
 }
 
 func (fan *HwMonFan) SetRpmAvg(rpm float64) {
+	hwMonFanLock.Lock()
+	defer hwMonFanLock.Unlock()
 	fan.RpmMovingAvg = rpm
 }
 
 func (fan *HwMonFan) GetPwm() (int, error) {
-	value, err := util.ReadIntFromFile(fan.Config.HwMon.PwmPath)
+	value, err := util.ReadIntFromSysfs(fan.Config.HwMon.PwmPath)
 	if err != nil {
 		return MinPwmValue, err
 	}
+	hwMonFanLock.Lock()
 	fan.Pwm = value
+	hwMonFanLock.Unlock()
 	return value, nil
 }
 
 func (fan *HwMonFan) SetPwm(pwm int) (err error) {
-	ui.Debug("Setting Fan PWM of '%s' to %d ...", fan.GetId(), pwm)
-	err = util.WriteIntToFile(pwm, fan.Config.HwMon.PwmPath)
+	ui.ForSubsystem(ui.SubsystemHwmon).WithFan(fan.GetId()).Debug("Setting Fan PWM of '%s' to %d ...", fan.GetId(), pwm)
+	err = util.WriteIntToSysfs(pwm, fan.Config.HwMon.PwmPath)
 	return err
 }
 
-func (fan HwMonFan) GetFanCurveData() *map[int]float64 {
+func (fan *HwMonFan) GetFanCurveData() *map[int]float64 {
 	return fan.FanCurveData
 }
 
@@ -132,19 +149,19 @@ func (fan *HwMonFan) AttachFanCurveData(curveData *map[int]float64) (err error)
 	return err
 }
 
-func (fan HwMonFan) GetCurveId() string {
+func (fan *HwMonFan) GetCurveId() string {
 	return fan.Config.Curve
 }
 
-func (fan HwMonFan) ShouldNeverStop() bool {
+func (fan *HwMonFan) ShouldNeverStop() bool {
 	return fan.Config.NeverStop
 }
 
-func (fan HwMonFan) GetPwmEnabled() (int, error) {
-	return util.ReadIntFromFile(fan.Config.HwMon.PwmEnablePath)
+func (fan *HwMonFan) GetPwmEnabled() (int, error) {
+	return util.ReadIntFromSysfs(fan.Config.HwMon.PwmEnablePath)
 }
 
-func (fan HwMonFan) IsPwmAuto() (bool, error) {
+func (fan *HwMonFan) IsPwmAuto() (bool, error) {
 	value, err := fan.GetPwmEnabled()
 	if err != nil {
 		return false, err
@@ -158,9 +175,9 @@ func (fan HwMonFan) IsPwmAuto() (bool, error) {
 // 1 - manual pwm control
 // 2 - motherboard pwm control
 func (fan *HwMonFan) SetPwmEnabled(value ControlMode) (err error) {
-	err = util.WriteIntToFile(int(value), fan.Config.HwMon.PwmEnablePath)
+	err = util.WriteIntToSysfs(int(value), fan.Config.HwMon.PwmEnablePath)
 	if err == nil {
-		currentValue, err := util.ReadIntFromFile(fan.Config.HwMon.PwmEnablePath)
+		currentValue, err := util.ReadIntFromSysfs(fan.Config.HwMon.PwmEnablePath)
 		if err != nil || ControlMode(currentValue) != value {
 			return fmt.Errorf("PWM mode stuck to %d", currentValue)
 		}
@@ -168,7 +185,24 @@ func (fan *HwMonFan) SetPwmEnabled(value ControlMode) (err error) {
 	return err
 }
 
-func (fan HwMonFan) Supports(feature FeatureFlag) bool {
+// GetPwmMode reads pwmX_mode, 0 if the fan is driven in DC mode, 1 if it is driven in PWM mode
+func (fan *HwMonFan) GetPwmMode() (int, error) {
+	return util.ReadIntFromSysfs(fan.Config.HwMon.PwmModePath)
+}
+
+// SetPwmMode writes the given value to pwmX_mode, see GetPwmMode
+func (fan *HwMonFan) SetPwmMode(value int) (err error) {
+	err = util.WriteIntToSysfs(value, fan.Config.HwMon.PwmModePath)
+	if err == nil {
+		currentValue, err := util.ReadIntFromSysfs(fan.Config.HwMon.PwmModePath)
+		if err != nil || currentValue != value {
+			return fmt.Errorf("PWM mode stuck to %d", currentValue)
+		}
+	}
+	return err
+}
+
+func (fan *HwMonFan) Supports(feature FeatureFlag) bool {
 	switch feature {
 	case FeatureControlMode:
 		_, err := os.Stat(fan.Config.HwMon.PwmEnablePath)
@@ -176,6 +210,8 @@ func (fan HwMonFan) Supports(feature FeatureFlag) bool {
 	case FeatureRpmSensor:
 		_, err := os.Stat(fan.Config.HwMon.RpmInputPath)
 		return err == nil
+	case FeatureMeasuredCurve:
+		return true
 	}
 	return false
 }
@@ -0,0 +1,368 @@
+package fans
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+const (
+	defaultIpmitoolPath = "/usr/bin/ipmitool"
+	// asRockRackFanCount is the number of fan duty bytes expected by the asrockrack "set fan duty" command
+	asRockRackFanCount = 8
+)
+
+// ipmiProfile describes the raw commands used to control the fans of a specific BMC vendor
+type ipmiProfile struct {
+	// manual are the raw bytes to enable manual fan control, if necessary
+	manual []string
+	// auto are the raw bytes to return fan control to the BMC
+	auto []string
+	// setDuty returns the raw bytes to set the given duty (0-100) for the given zone
+	setDuty func(host string, zone int, duty int) []string
+	// getDuty returns the raw bytes to read the current duty (0-100) of the given zone, if supported
+	getDuty func(zone int) []string
+}
+
+var (
+	// supermicroProfile controls the BMC of Supermicro X10, X11, X12 and X13 boards,
+	// which set the duty (0-100) of a zone directly
+	supermicroProfile = ipmiProfile{
+		// fan mode "full", which doesn't interfere with manually set duties
+		manual: []string{"0x30", "0x45", "0x01", "0x01"},
+		// fan mode "standard"
+		auto: []string{"0x30", "0x45", "0x01", "0x00"},
+		setDuty: func(host string, zone int, duty int) []string {
+			return []string{"0x30", "0x70", "0x66", "0x01", toHexByte(zone), toHexByte(duty)}
+		},
+		getDuty: func(zone int) []string {
+			return []string{"0x30", "0x70", "0x66", "0x00", toHexByte(zone)}
+		},
+	}
+
+	// supermicroX9Profile controls the BMC of Supermicro X9 boards, which write the duty (0-255)
+	// to the fan controller register of a zone (0x10: CPU/system fans, 0x11: peripheral fans)
+	supermicroX9Profile = ipmiProfile{
+		manual: supermicroProfile.manual,
+		auto:   supermicroProfile.auto,
+		setDuty: func(host string, zone int, duty int) []string {
+			return []string{"0x30", "0x91", "0x5a", "0x03", toHexByte(0x10 + zone), toHexByte(dutyToPwm(duty))}
+		},
+	}
+
+	// dellPowerEdgeProfile controls the iDRAC of Dell PowerEdge servers, which sets the duty (0-100)
+	// of a single fan or all fans
+	dellPowerEdgeProfile = ipmiProfile{
+		manual: []string{"0x30", "0x30", "0x01", "0x00"},
+		auto:   []string{"0x30", "0x30", "0x01", "0x01"},
+		setDuty: func(host string, zone int, duty int) []string {
+			fan := "0xff"
+			if zone > 0 {
+				fan = toHexByte(zone - 1)
+			}
+			return []string{"0x30", "0x30", "0x02", fan, toHexByte(duty)}
+		},
+	}
+
+	ipmiProfiles = map[string]ipmiProfile{
+		configuration.IpmiProfileSupermicro:    supermicroProfile,
+		configuration.IpmiProfileSupermicroX9:  supermicroX9Profile,
+		configuration.IpmiProfileSupermicroX10: supermicroProfile,
+		configuration.IpmiProfileSupermicroX11: supermicroProfile,
+		configuration.IpmiProfileSupermicroX12: supermicroProfile,
+		configuration.IpmiProfileSupermicroX13: supermicroProfile,
+		configuration.IpmiProfileDell:          dellPowerEdgeProfile,
+		configuration.IpmiProfileDellPowerEdge: dellPowerEdgeProfile,
+		configuration.IpmiProfileAsRockRack: {
+			// a duty of 0 returns the fan to "smart fan" mode
+			auto:    append([]string{"0x3a", "0x01"}, asRockRackDutyBytes(make([]int, asRockRackFanCount))...),
+			setDuty: setAsRockRackDuty,
+		},
+	}
+
+	// asRockRackDuties holds the last known duties of all fans of a BMC (by host),
+	// since the asrockrack command always sets the duties of all fans at once
+	asRockRackDuties = map[string][]int{}
+	asRockRackLock   sync.Mutex
+)
+
+type IpmiFan struct {
+	Config    configuration.FanConfig `json:"configuration"`
+	MovingAvg float64                 `json:"movingAvg"`
+
+	Rpm  int         `json:"rpm"`
+	Pwm  int         `json:"pwm"`
+	Mode ControlMode `json:"mode"`
+}
+
+func (fan IpmiFan) GetId() string {
+	return fan.Config.ID
+}
+
+func (fan IpmiFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
+func (fan IpmiFan) GetStartPwm() int {
+	return getConfiguredStartPwm(fan.Config)
+}
+
+func (fan *IpmiFan) SetStartPwm(pwm int, force bool) {
+}
+
+func (fan IpmiFan) GetMinPwm() int {
+	return getConfiguredMinPwm(fan.Config)
+}
+
+func (fan *IpmiFan) SetMinPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan IpmiFan) GetMaxPwm() int {
+	return getConfiguredMaxPwm(fan.Config)
+}
+
+func (fan *IpmiFan) SetMaxPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan *IpmiFan) GetRpm() (int, error) {
+	if !fan.Supports(FeatureRpmSensor) {
+		return 0, nil
+	}
+
+	output, err := fan.execute("sensor", "reading", fan.Config.Ipmi.RpmSensor)
+	if err != nil {
+		return 0, err
+	}
+
+	// the output has the format "<sensor name> | <value>"
+	parts := strings.Split(output, "|")
+	rpm, err := strconv.ParseFloat(strings.TrimSpace(parts[len(parts)-1]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse rpm of sensor '%s': %s", fan.Config.Ipmi.RpmSensor, output)
+	}
+
+	fan.Rpm = int(rpm)
+
+	return fan.Rpm, nil
+}
+
+func (fan IpmiFan) GetRpmAvg() float64 {
+	return fan.MovingAvg
+}
+
+func (fan *IpmiFan) SetRpmAvg(rpm float64) {
+	fan.MovingAvg = rpm
+}
+
+func (fan *IpmiFan) GetPwm() (result int, err error) {
+	profile := fan.getProfile()
+	if profile.getDuty == nil {
+		// the BMC doesn't support reading the duty, return the last known value instead
+		return fan.Pwm, nil
+	}
+
+	output, err := fan.executeRaw(profile.getDuty(fan.Config.Ipmi.Zone))
+	if err != nil {
+		return 0, err
+	}
+
+	duty, err := strconv.ParseInt(strings.TrimSpace(output), 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse fan duty: %s", output)
+	}
+
+	fan.Pwm = dutyToPwm(int(duty))
+
+	return fan.Pwm, nil
+}
+
+func (fan *IpmiFan) SetPwm(pwm int) (err error) {
+	config := fan.Config.Ipmi
+	duty := pwmToDuty(pwm)
+
+	var raw []string
+	if config.Profile == configuration.IpmiProfileGeneric {
+		for _, arg := range config.Raw.SetPwm {
+			arg = strings.ReplaceAll(arg, "%pwm%", strconv.Itoa(pwm))
+			arg = strings.ReplaceAll(arg, "%duty%", strconv.Itoa(duty))
+			arg = strings.ReplaceAll(arg, "%zone%", strconv.Itoa(config.Zone))
+			raw = append(raw, arg)
+		}
+	} else {
+		raw = fan.getProfile().setDuty(config.Host, config.Zone, duty)
+	}
+
+	_, err = fan.executeRaw(raw)
+	if err != nil {
+		return err
+	}
+
+	fan.Pwm = pwm
+
+	return nil
+}
+
+func (fan IpmiFan) GetFanCurveData() *map[int]float64 {
+	return &interpolated
+}
+
+func (fan *IpmiFan) AttachFanCurveData(curveData *map[int]float64) (err error) {
+	// not supported
+	return
+}
+
+func (fan IpmiFan) GetCurveId() string {
+	return fan.Config.Curve
+}
+
+func (fan IpmiFan) ShouldNeverStop() bool {
+	return fan.Config.NeverStop
+}
+
+func (fan IpmiFan) GetPwmEnabled() (int, error) {
+	return int(fan.Mode), nil
+}
+
+// SetPwmEnabled switches the BMC between manual and automatic fan control
+func (fan *IpmiFan) SetPwmEnabled(value ControlMode) (err error) {
+	profile := fan.getProfile()
+
+	switch value {
+	case ControlModePWM:
+		if len(profile.manual) > 0 {
+			_, err = fan.executeRaw(profile.manual)
+		}
+	case ControlModeAutomatic:
+		if len(profile.auto) > 0 {
+			_, err = fan.executeRaw(profile.auto)
+		}
+	case ControlModeDisabled:
+		// there is no way to "disable" control, run the fans at full speed instead
+		err = fan.SetPwm(MaxPwmValue)
+	}
+	if err != nil {
+		return err
+	}
+
+	fan.Mode = value
+
+	return nil
+}
+
+func (fan IpmiFan) IsPwmAuto() (bool, error) {
+	return fan.Mode == ControlModeAutomatic, nil
+}
+
+func (fan IpmiFan) Supports(feature FeatureFlag) bool {
+	switch feature {
+	case FeatureControlMode:
+		profile := fan.getProfile()
+		return len(profile.manual) > 0 || len(profile.auto) > 0
+	case FeatureRpmSensor:
+		return len(fan.Config.Ipmi.RpmSensor) > 0
+	}
+	return false
+}
+
+// getProfile returns the profile of this fan, the generic profile is built from the raw config
+func (fan IpmiFan) getProfile() ipmiProfile {
+	config := fan.Config.Ipmi
+	if config.Profile == configuration.IpmiProfileGeneric {
+		if config.Raw == nil {
+			return ipmiProfile{}
+		}
+		return ipmiProfile{
+			manual: config.Raw.Manual,
+			auto:   config.Raw.Auto,
+		}
+	}
+	return ipmiProfiles[config.Profile]
+}
+
+func (fan IpmiFan) executeRaw(raw []string) (string, error) {
+	return fan.execute(append([]string{"raw"}, raw...)...)
+}
+
+func (fan IpmiFan) execute(args ...string) (string, error) {
+	executable := fan.Config.Ipmi.Ipmitool
+	if len(executable) <= 0 {
+		executable = defaultIpmitoolPath
+	}
+
+	fullArgs, env := ipmitoolArgs(*fan.Config.Ipmi, args)
+	timeout := 5 * time.Second
+	return util.SafeCmdExecutionWithEnv(executable, fullArgs, env, timeout)
+}
+
+// ipmitoolArgs returns the arguments and additional environment of an ipmitool call.
+// The password is passed using the IPMI_PASSWORD environment variable (-E),
+// since the arguments of a process can be read by every local user.
+func ipmitoolArgs(config configuration.IpmiFanConfig, args []string) (fullArgs []string, env []string) {
+	if len(config.Host) > 0 {
+		fullArgs = append(fullArgs, "-I", "lanplus", "-H", config.Host)
+		if len(config.Username) > 0 {
+			fullArgs = append(fullArgs, "-U", config.Username)
+		}
+		if len(config.Password) > 0 {
+			fullArgs = append(fullArgs, "-E")
+			env = append(env, "IPMI_PASSWORD="+config.Password)
+		}
+	}
+	return append(fullArgs, args...), env
+}
+
+func setAsRockRackDuty(host string, zone int, duty int) []string {
+	asRockRackLock.Lock()
+	defer asRockRackLock.Unlock()
+
+	duties, ok := asRockRackDuties[host]
+	if !ok {
+		duties = make([]int, asRockRackFanCount)
+		asRockRackDuties[host] = duties
+	}
+
+	// a duty of 0 means "smart fan", so use the lowest possible manual duty instead
+	if duty <= 0 {
+		duty = 1
+	}
+
+	if zone <= 0 {
+		for i := range duties {
+			duties[i] = duty
+		}
+	} else if zone <= len(duties) {
+		duties[zone-1] = duty
+	}
+
+	return append([]string{"0x3a", "0x01"}, asRockRackDutyBytes(duties)...)
+}
+
+func asRockRackDutyBytes(duties []int) []string {
+	var result []string
+	for _, duty := range duties {
+		result = append(result, toHexByte(duty))
+	}
+	return result
+}
+
+// pwmToDuty converts a pwm value (0-255) to a duty percentage (0-100)
+func pwmToDuty(pwm int) int {
+	return int(util.Coerce(math.Round(float64(pwm)*100/MaxPwmValue), 0, 100))
+}
+
+// dutyToPwm converts a duty percentage (0-100) to a pwm value (0-255)
+func dutyToPwm(duty int) int {
+	return int(util.Coerce(math.Round(float64(duty)*MaxPwmValue/100), MinPwmValue, MaxPwmValue))
+}
+
+func toHexByte(value int) string {
+	return fmt.Sprintf("0x%02x", value&0xff)
+}
@@ -0,0 +1,105 @@
+package fans
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIpmiFan_PwmToDuty(t *testing.T) {
+	// GIVEN
+	pwmValues := []int{MinPwmValue, 128, MaxPwmValue}
+
+	// WHEN
+	var duties []int
+	for _, pwm := range pwmValues {
+		duties = append(duties, pwmToDuty(pwm))
+	}
+
+	// THEN
+	assert.Equal(t, []int{0, 50, 100}, duties)
+}
+
+func TestIpmiFan_SupermicroSetDuty(t *testing.T) {
+	// GIVEN
+	profile := ipmiProfiles[configuration.IpmiProfileSupermicro]
+
+	// WHEN
+	raw := profile.setDuty("", 1, 50)
+
+	// THEN
+	assert.Equal(t, []string{"0x30", "0x70", "0x66", "0x01", "0x01", "0x32"}, raw)
+}
+
+func TestIpmiFan_AsRockRackSetDutyKeepsOtherFans(t *testing.T) {
+	// GIVEN
+	profile := ipmiProfiles[configuration.IpmiProfileAsRockRack]
+	profile.setDuty("bmc", 1, 30)
+
+	// WHEN
+	raw := profile.setDuty("bmc", 3, 60)
+
+	// THEN
+	assert.Equal(t, []string{"0x3a", "0x01", "0x1e", "0x00", "0x3c", "0x00", "0x00", "0x00", "0x00", "0x00"}, raw)
+}
+
+func TestIpmiFan_SupermicroX9SetDuty(t *testing.T) {
+	// GIVEN
+	profile := ipmiProfiles[configuration.IpmiProfileSupermicroX9]
+
+	// WHEN
+	cpuZone := profile.setDuty("", 0, 50)
+	peripheralZone := profile.setDuty("", 1, 100)
+
+	// THEN
+	assert.Equal(t, []string{"0x30", "0x91", "0x5a", "0x03", "0x10", "0x80"}, cpuZone)
+	assert.Equal(t, []string{"0x30", "0x91", "0x5a", "0x03", "0x11", "0xff"}, peripheralZone)
+	assert.Equal(t, []string{"0x30", "0x45", "0x01", "0x01"}, profile.manual)
+}
+
+func TestIpmiFan_DellPowerEdgeSetDuty(t *testing.T) {
+	// GIVEN
+	profile := ipmiProfiles[configuration.IpmiProfileDellPowerEdge]
+
+	// WHEN
+	allFans := profile.setDuty("", 0, 30)
+	secondFan := profile.setDuty("", 2, 30)
+
+	// THEN
+	assert.Equal(t, []string{"0x30", "0x30", "0x02", "0xff", "0x1e"}, allFans)
+	assert.Equal(t, []string{"0x30", "0x30", "0x02", "0x01", "0x1e"}, secondFan)
+	assert.Equal(t, []string{"0x30", "0x30", "0x01", "0x00"}, profile.manual)
+	assert.Equal(t, []string{"0x30", "0x30", "0x01", "0x01"}, profile.auto)
+}
+
+func TestIpmiFan_AllProfilesSupported(t *testing.T) {
+	for _, name := range configuration.IpmiProfiles {
+		if name == configuration.IpmiProfileGeneric {
+			continue
+		}
+
+		// WHEN
+		profile, ok := ipmiProfiles[name]
+
+		// THEN
+		assert.True(t, ok, name)
+		assert.NotNil(t, profile.setDuty, name)
+	}
+}
+
+func TestIpmiFan_PasswordNotInArgs(t *testing.T) {
+	// GIVEN
+	config := configuration.IpmiFanConfig{
+		Host:     "bmc",
+		Username: "admin",
+		Password: "secret",
+	}
+
+	// WHEN
+	args, env := ipmitoolArgs(config, []string{"raw", "0x30"})
+
+	// THEN
+	assert.Equal(t, []string{"-I", "lanplus", "-H", "bmc", "-U", "admin", "-E", "raw", "0x30"}, args)
+	assert.Equal(t, []string{"IPMI_PASSWORD=secret"}, env)
+}
@@ -0,0 +1,130 @@
+package fans
+
+import (
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/liquidctl"
+)
+
+type LiquidctlFan struct {
+	Config    configuration.FanConfig `json:"configuration"`
+	MovingAvg float64                 `json:"movingAvg"`
+
+	Rpm int `json:"rpm"`
+	Pwm int `json:"pwm"`
+}
+
+func (fan LiquidctlFan) GetId() string {
+	return fan.Config.ID
+}
+
+func (fan LiquidctlFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
+func (fan LiquidctlFan) GetStartPwm() int {
+	return getConfiguredStartPwm(fan.Config)
+}
+
+func (fan *LiquidctlFan) SetStartPwm(pwm int, force bool) {
+}
+
+func (fan LiquidctlFan) GetMinPwm() int {
+	return getConfiguredMinPwm(fan.Config)
+}
+
+func (fan *LiquidctlFan) SetMinPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan LiquidctlFan) GetMaxPwm() int {
+	return getConfiguredMaxPwm(fan.Config)
+}
+
+func (fan *LiquidctlFan) SetMaxPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan *LiquidctlFan) GetRpm() (int, error) {
+	if !fan.Supports(FeatureRpmSensor) {
+		return 0, nil
+	}
+
+	config := fan.Config.Liquidctl
+	record, err := liquidctl.GetValue(config.Liquidctl, config.Match, config.RpmKey)
+	if err != nil {
+		return 0, err
+	}
+	rpm, err := record.Float()
+	if err != nil {
+		return 0, err
+	}
+
+	fan.Rpm = int(rpm)
+
+	return fan.Rpm, nil
+}
+
+func (fan LiquidctlFan) GetRpmAvg() float64 {
+	return fan.MovingAvg
+}
+
+func (fan *LiquidctlFan) SetRpmAvg(rpm float64) {
+	fan.MovingAvg = rpm
+}
+
+// GetPwm returns the last PWM value set by fan2go, since most devices don't report their duty
+func (fan *LiquidctlFan) GetPwm() (result int, err error) {
+	return fan.Pwm, nil
+}
+
+func (fan *LiquidctlFan) SetPwm(pwm int) (err error) {
+	config := fan.Config.Liquidctl
+	err = liquidctl.SetSpeed(config.Liquidctl, config.Match, config.Channel, pwmToDuty(pwm))
+	if err != nil {
+		return err
+	}
+
+	fan.Pwm = pwm
+
+	return nil
+}
+
+func (fan LiquidctlFan) GetFanCurveData() *map[int]float64 {
+	return &interpolated
+}
+
+func (fan *LiquidctlFan) AttachFanCurveData(curveData *map[int]float64) (err error) {
+	// not supported
+	return
+}
+
+func (fan LiquidctlFan) GetCurveId() string {
+	return fan.Config.Curve
+}
+
+func (fan LiquidctlFan) ShouldNeverStop() bool {
+	return fan.Config.NeverStop
+}
+
+func (fan LiquidctlFan) GetPwmEnabled() (int, error) {
+	return 1, nil
+}
+
+func (fan *LiquidctlFan) SetPwmEnabled(value ControlMode) (err error) {
+	// nothing to do
+	return nil
+}
+
+func (fan LiquidctlFan) IsPwmAuto() (bool, error) {
+	return true, nil
+}
+
+func (fan LiquidctlFan) Supports(feature FeatureFlag) bool {
+	switch feature {
+	case FeatureControlMode:
+		return false
+	case FeatureRpmSensor:
+		return len(fan.Config.Liquidctl.RpmKey) > 0
+	}
+	return false
+}
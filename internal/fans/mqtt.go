@@ -0,0 +1,224 @@
+package fans
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+const (
+	mqttTimeout = 5 * time.Second
+)
+
+var (
+	// newMqttClient creates the client of a fan, replaced in tests
+	newMqttClient = mqtt.NewClient
+)
+
+type MqttFan struct {
+	Config    configuration.FanConfig `json:"configuration"`
+	MovingAvg float64                 `json:"movingAvg"`
+
+	Rpm int `json:"rpm"`
+	Pwm int `json:"pwm"`
+
+	client mqtt.Client
+	lock   sync.Mutex
+}
+
+func NewMqttFan(config configuration.FanConfig) *MqttFan {
+	return &MqttFan{
+		Config: config,
+		// the current value is not known until we have set it, assume full speed
+		Pwm: MaxPwmValue,
+	}
+}
+
+func (fan *MqttFan) GetId() string {
+	return fan.Config.ID
+}
+
+func (fan *MqttFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
+func (fan *MqttFan) GetStartPwm() int {
+	return getConfiguredStartPwm(fan.Config)
+}
+
+func (fan *MqttFan) SetStartPwm(pwm int, force bool) {
+}
+
+func (fan *MqttFan) GetMinPwm() int {
+	return getConfiguredMinPwm(fan.Config)
+}
+
+func (fan *MqttFan) SetMinPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan *MqttFan) GetMaxPwm() int {
+	return getConfiguredMaxPwm(fan.Config)
+}
+
+func (fan *MqttFan) SetMaxPwm(pwm int, force bool) {
+	// not supported
+}
+
+// GetRpm returns the last RPM value received on the rpm topic
+func (fan *MqttFan) GetRpm() (int, error) {
+	if !fan.Supports(FeatureRpmSensor) {
+		return 0, nil
+	}
+
+	err := fan.connect()
+	if err != nil {
+		return 0, err
+	}
+
+	fan.lock.Lock()
+	defer fan.lock.Unlock()
+	return fan.Rpm, nil
+}
+
+func (fan *MqttFan) GetRpmAvg() float64 {
+	return fan.MovingAvg
+}
+
+func (fan *MqttFan) SetRpmAvg(rpm float64) {
+	fan.MovingAvg = rpm
+}
+
+// GetPwm returns the last PWM value published by fan2go
+func (fan *MqttFan) GetPwm() (result int, err error) {
+	return fan.Pwm, nil
+}
+
+func (fan *MqttFan) SetPwm(pwm int) (err error) {
+	err = fan.connect()
+	if err != nil {
+		return err
+	}
+
+	config := fan.Config.Mqtt
+	token := fan.client.Publish(config.PwmTopic, config.Qos, config.Retain, strconv.Itoa(pwm))
+	if !token.WaitTimeout(mqttTimeout) {
+		return fmt.Errorf("timeout while publishing to topic '%s'", config.PwmTopic)
+	}
+	if token.Error() != nil {
+		return token.Error()
+	}
+
+	fan.Pwm = pwm
+
+	return nil
+}
+
+func (fan *MqttFan) GetFanCurveData() *map[int]float64 {
+	return &interpolated
+}
+
+func (fan *MqttFan) AttachFanCurveData(curveData *map[int]float64) (err error) {
+	// not supported
+	return
+}
+
+func (fan *MqttFan) GetCurveId() string {
+	return fan.Config.Curve
+}
+
+func (fan *MqttFan) ShouldNeverStop() bool {
+	return fan.Config.NeverStop
+}
+
+func (fan *MqttFan) GetPwmEnabled() (int, error) {
+	return 1, nil
+}
+
+func (fan *MqttFan) SetPwmEnabled(value ControlMode) (err error) {
+	// nothing to do
+	return nil
+}
+
+func (fan *MqttFan) IsPwmAuto() (bool, error) {
+	return true, nil
+}
+
+func (fan *MqttFan) Supports(feature FeatureFlag) bool {
+	switch feature {
+	case FeatureControlMode:
+		return false
+	case FeatureRpmSensor:
+		return len(fan.Config.Mqtt.RpmTopic) > 0
+	}
+	return false
+}
+
+// connect lazily connects to the broker and subscribes to the rpm topic, if configured
+func (fan *MqttFan) connect() error {
+	fan.lock.Lock()
+	defer fan.lock.Unlock()
+
+	if fan.client != nil {
+		return nil
+	}
+
+	config := fan.Config.Mqtt
+
+	clientId := config.ClientId
+	if len(clientId) <= 0 {
+		clientId = "fan2go-" + fan.Config.ID
+	}
+
+	options := mqtt.NewClientOptions().
+		AddBroker(config.Broker).
+		SetClientID(clientId).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetAutoReconnect(true).
+		SetConnectTimeout(mqttTimeout)
+	if len(config.RpmTopic) > 0 {
+		// resubscribe after reconnecting
+		options.SetOnConnectHandler(func(client mqtt.Client) {
+			token := client.Subscribe(config.RpmTopic, config.Qos, fan.onRpmMessage)
+			if token.WaitTimeout(mqttTimeout) && token.Error() != nil {
+				ui.Warning("Unable to subscribe to topic '%s' of fan %s: %v", config.RpmTopic, fan.Config.ID, token.Error())
+			}
+		})
+	}
+
+	client := newMqttClient(options)
+	token := client.Connect()
+	// the client is discarded on failure, so it is disconnected to stop its connection attempts,
+	// a new one is created on the next call
+	if !token.WaitTimeout(mqttTimeout) {
+		client.Disconnect(0)
+		return fmt.Errorf("timeout while connecting to mqtt broker %s", config.Broker)
+	}
+	if token.Error() != nil {
+		client.Disconnect(0)
+		return fmt.Errorf("unable to connect to mqtt broker %s: %v", config.Broker, token.Error())
+	}
+
+	fan.client = client
+
+	return nil
+}
+
+func (fan *MqttFan) onRpmMessage(client mqtt.Client, message mqtt.Message) {
+	rpm, err := strconv.ParseFloat(strings.TrimSpace(string(message.Payload())), 64)
+	if err != nil {
+		ui.Warning("Unable to parse rpm of fan %s from message: %s", fan.Config.ID, string(message.Payload()))
+		return
+	}
+
+	fan.lock.Lock()
+	defer fan.lock.Unlock()
+	fan.Rpm = int(rpm)
+}
@@ -0,0 +1,255 @@
+package fans
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMqttToken is a token which either completed with the given error, or never completes
+type fakeMqttToken struct {
+	completed bool
+	err       error
+}
+
+func (token *fakeMqttToken) Wait() bool {
+	return token.completed
+}
+
+func (token *fakeMqttToken) WaitTimeout(time.Duration) bool {
+	return token.completed
+}
+
+func (token *fakeMqttToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	if token.completed {
+		close(done)
+	}
+	return done
+}
+
+func (token *fakeMqttToken) Error() error {
+	return token.err
+}
+
+// fakeMqttMessage is a message received on a subscribed topic
+type fakeMqttMessage struct {
+	topic   string
+	payload string
+}
+
+func (message fakeMqttMessage) Duplicate() bool {
+	return false
+}
+
+func (message fakeMqttMessage) Qos() byte {
+	return 0
+}
+
+func (message fakeMqttMessage) Retained() bool {
+	return false
+}
+
+func (message fakeMqttMessage) Topic() string {
+	return message.topic
+}
+
+func (message fakeMqttMessage) MessageID() uint16 {
+	return 0
+}
+
+func (message fakeMqttMessage) Payload() []byte {
+	return []byte(message.payload)
+}
+
+func (message fakeMqttMessage) Ack() {
+}
+
+// fakeMqttClient records published messages and subscriptions instead of connecting to a broker
+type fakeMqttClient struct {
+	options      *mqtt.ClientOptions
+	connectToken *fakeMqttToken
+
+	connected     bool
+	disconnected  bool
+	published     map[string]string
+	subscriptions map[string]mqtt.MessageHandler
+}
+
+func (client *fakeMqttClient) IsConnected() bool {
+	return client.connected
+}
+
+func (client *fakeMqttClient) IsConnectionOpen() bool {
+	return client.connected
+}
+
+func (client *fakeMqttClient) Connect() mqtt.Token {
+	if client.connectToken.completed && client.connectToken.err == nil {
+		client.connected = true
+		if client.options.OnConnect != nil {
+			client.options.OnConnect(client)
+		}
+	}
+	return client.connectToken
+}
+
+func (client *fakeMqttClient) Disconnect(quiesce uint) {
+	client.connected = false
+	client.disconnected = true
+}
+
+func (client *fakeMqttClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	client.published[topic] = fmt.Sprint(payload)
+	return &fakeMqttToken{completed: true}
+}
+
+func (client *fakeMqttClient) Subscribe(topic string, qos byte, callback mqtt.MessageHandler) mqtt.Token {
+	client.subscriptions[topic] = callback
+	return &fakeMqttToken{completed: true}
+}
+
+func (client *fakeMqttClient) SubscribeMultiple(filters map[string]byte, callback mqtt.MessageHandler) mqtt.Token {
+	for topic := range filters {
+		client.subscriptions[topic] = callback
+	}
+	return &fakeMqttToken{completed: true}
+}
+
+func (client *fakeMqttClient) Unsubscribe(topics ...string) mqtt.Token {
+	for _, topic := range topics {
+		delete(client.subscriptions, topic)
+	}
+	return &fakeMqttToken{completed: true}
+}
+
+func (client *fakeMqttClient) AddRoute(topic string, callback mqtt.MessageHandler) {
+	client.subscriptions[topic] = callback
+}
+
+func (client *fakeMqttClient) OptionsReader() mqtt.ClientOptionsReader {
+	panic("not supported")
+}
+
+// deliver passes a message with the given payload to the handler subscribed to the given topic
+func (client *fakeMqttClient) deliver(topic string, payload string) {
+	client.subscriptions[topic](client, fakeMqttMessage{topic: topic, payload: payload})
+}
+
+// useFakeMqttClient makes all mqtt fans use fake clients, whose connection attempts complete
+// with the given token. It returns the clients created so far.
+func useFakeMqttClient(t *testing.T, connectToken *fakeMqttToken) *[]*fakeMqttClient {
+	var clients []*fakeMqttClient
+	newMqttClient = func(options *mqtt.ClientOptions) mqtt.Client {
+		client := &fakeMqttClient{
+			options:       options,
+			connectToken:  connectToken,
+			published:     map[string]string{},
+			subscriptions: map[string]mqtt.MessageHandler{},
+		}
+		clients = append(clients, client)
+		return client
+	}
+	t.Cleanup(func() {
+		newMqttClient = mqtt.NewClient
+	})
+	return &clients
+}
+
+func createMqttFan() *MqttFan {
+	return NewMqttFan(configuration.FanConfig{
+		ID: "mqtt",
+		Mqtt: &configuration.MqttFanConfig{
+			Broker:   "tcp://localhost:1883",
+			PwmTopic: "fan/pwm",
+			RpmTopic: "fan/rpm",
+		},
+	})
+}
+
+func TestMqttFan_SetPwm(t *testing.T) {
+	// GIVEN
+	clients := useFakeMqttClient(t, &fakeMqttToken{completed: true})
+	fan := createMqttFan()
+
+	// WHEN
+	err := fan.SetPwm(100)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, *clients, 1)
+	assert.Equal(t, "100", (*clients)[0].published["fan/pwm"])
+	pwm, err := fan.GetPwm()
+	assert.NoError(t, err)
+	assert.Equal(t, 100, pwm)
+}
+
+func TestMqttFan_GetRpm(t *testing.T) {
+	// GIVEN
+	clients := useFakeMqttClient(t, &fakeMqttToken{completed: true})
+	fan := createMqttFan()
+	_, err := fan.GetRpm()
+	assert.NoError(t, err)
+
+	// WHEN
+	(*clients)[0].deliver("fan/rpm", "1200.0\n")
+	rpm, err := fan.GetRpm()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 1200, rpm)
+}
+
+func TestMqttFan_GetRpmIgnoresInvalidMessage(t *testing.T) {
+	// GIVEN
+	clients := useFakeMqttClient(t, &fakeMqttToken{completed: true})
+	fan := createMqttFan()
+	_, err := fan.GetRpm()
+	assert.NoError(t, err)
+	(*clients)[0].deliver("fan/rpm", "800")
+
+	// WHEN
+	(*clients)[0].deliver("fan/rpm", "fast")
+	rpm, err := fan.GetRpm()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 800, rpm)
+}
+
+func TestMqttFan_ConnectTimeoutDisconnectsClient(t *testing.T) {
+	// GIVEN
+	clients := useFakeMqttClient(t, &fakeMqttToken{completed: false})
+	fan := createMqttFan()
+
+	// WHEN
+	err := fan.SetPwm(100)
+
+	// THEN
+	assert.Error(t, err)
+	assert.Len(t, *clients, 1)
+	assert.True(t, (*clients)[0].disconnected)
+	assert.Nil(t, fan.client)
+}
+
+func TestMqttFan_ConnectErrorDisconnectsClient(t *testing.T) {
+	// GIVEN
+	clients := useFakeMqttClient(t, &fakeMqttToken{completed: true, err: errors.New("connection refused")})
+	fan := createMqttFan()
+
+	// WHEN
+	err := fan.SetPwm(100)
+	retryErr := fan.SetPwm(100)
+
+	// THEN
+	assert.Error(t, err)
+	assert.Error(t, retryErr)
+	assert.Len(t, *clients, 2)
+	assert.True(t, (*clients)[0].disconnected)
+	assert.True(t, (*clients)[1].disconnected)
+}
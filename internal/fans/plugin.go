@@ -0,0 +1,167 @@
+package fans
+
+import (
+	"fmt"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/plugins"
+)
+
+type PluginFan struct {
+	Config    configuration.FanConfig `json:"configuration"`
+	MovingAvg float64                 `json:"movingAvg"`
+
+	Plugin      plugins.Plugin      `json:"-"`
+	Description plugins.Description `json:"-"`
+
+	Rpm int `json:"rpm"`
+	Pwm int `json:"pwm"`
+}
+
+// NewPluginFan returns a fan controlled by the plugin of the given config,
+// the plugin is asked which features it supports
+func NewPluginFan(config configuration.FanConfig) (*PluginFan, error) {
+	plugin, err := plugins.Find(configuration.GetPluginDir(), config.Plugin.Name)
+	if err != nil {
+		return nil, fmt.Errorf("fan %s: %v", config.ID, err)
+	}
+	description, err := plugin.Describe(config.Plugin.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("fan %s: %v", config.ID, err)
+	}
+	if !description.Fan {
+		return nil, fmt.Errorf("fan %s: plugin %s doesn't provide fans", config.ID, plugin.Name)
+	}
+
+	return &PluginFan{
+		Config:      config,
+		Plugin:      plugin,
+		Description: description,
+		// the current value might not be readable, assume full speed
+		Pwm: MaxPwmValue,
+	}, nil
+}
+
+func (fan PluginFan) GetId() string {
+	return fan.Config.ID
+}
+
+func (fan PluginFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
+func (fan PluginFan) GetStartPwm() int {
+	return getConfiguredStartPwm(fan.Config)
+}
+
+func (fan *PluginFan) SetStartPwm(pwm int, force bool) {
+}
+
+func (fan PluginFan) GetMinPwm() int {
+	return getConfiguredMinPwm(fan.Config)
+}
+
+func (fan *PluginFan) SetMinPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan PluginFan) GetMaxPwm() int {
+	return getConfiguredMaxPwm(fan.Config)
+}
+
+func (fan *PluginFan) SetMaxPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan *PluginFan) GetRpm() (int, error) {
+	if !fan.Supports(FeatureRpmSensor) {
+		return 0, nil
+	}
+
+	config := fan.Config.Plugin
+	rpm, err := fan.Plugin.GetValue(plugins.ActionGetRpm, config.Options, config.Timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	fan.Rpm = int(rpm)
+
+	return fan.Rpm, nil
+}
+
+func (fan PluginFan) GetRpmAvg() float64 {
+	return fan.MovingAvg
+}
+
+func (fan *PluginFan) SetRpmAvg(rpm float64) {
+	fan.MovingAvg = rpm
+}
+
+func (fan *PluginFan) GetPwm() (result int, err error) {
+	if !fan.Description.Pwm {
+		// no way to read the current value, return the last one we have set instead
+		return fan.Pwm, nil
+	}
+
+	config := fan.Config.Plugin
+	pwm, err := fan.Plugin.GetValue(plugins.ActionGetPwm, config.Options, config.Timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	fan.Pwm = int(pwm)
+
+	return fan.Pwm, nil
+}
+
+func (fan *PluginFan) SetPwm(pwm int) (err error) {
+	config := fan.Config.Plugin
+	err = fan.Plugin.SetPwm(config.Options, pwm, config.Timeout)
+	if err != nil {
+		return err
+	}
+
+	fan.Pwm = pwm
+
+	return nil
+}
+
+func (fan PluginFan) GetFanCurveData() *map[int]float64 {
+	return &interpolated
+}
+
+func (fan *PluginFan) AttachFanCurveData(curveData *map[int]float64) (err error) {
+	// not supported
+	return
+}
+
+func (fan PluginFan) GetCurveId() string {
+	return fan.Config.Curve
+}
+
+func (fan PluginFan) ShouldNeverStop() bool {
+	return fan.Config.NeverStop
+}
+
+func (fan PluginFan) GetPwmEnabled() (int, error) {
+	return 1, nil
+}
+
+func (fan *PluginFan) SetPwmEnabled(value ControlMode) (err error) {
+	// nothing to do
+	return nil
+}
+
+func (fan PluginFan) IsPwmAuto() (bool, error) {
+	return true, nil
+}
+
+func (fan PluginFan) Supports(feature FeatureFlag) bool {
+	switch feature {
+	case FeatureControlMode:
+		return false
+	case FeatureRpmSensor:
+		return fan.Description.Rpm
+	}
+	return false
+}
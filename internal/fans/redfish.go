@@ -0,0 +1,173 @@
+package fans
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/redfish"
+)
+
+// RedfishFan sets the speed of a fan using a Control resource of the Redfish API of a BMC
+type RedfishFan struct {
+	Config    configuration.FanConfig `json:"configuration"`
+	MovingAvg float64                 `json:"movingAvg"`
+
+	Rpm  int         `json:"rpm"`
+	Pwm  int         `json:"pwm"`
+	Mode ControlMode `json:"mode"`
+
+	client *redfish.Client
+}
+
+func NewRedfishFan(config configuration.FanConfig) *RedfishFan {
+	return &RedfishFan{
+		Config: config,
+		// the current duty might not be readable, assume full speed
+		Pwm:    MaxPwmValue,
+		Mode:   ControlModeAutomatic,
+		client: redfish.NewClient(config.Redfish.RedfishConfig),
+	}
+}
+
+func (fan RedfishFan) GetId() string {
+	return fan.Config.ID
+}
+
+func (fan RedfishFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
+func (fan RedfishFan) GetStartPwm() int {
+	return getConfiguredStartPwm(fan.Config)
+}
+
+func (fan *RedfishFan) SetStartPwm(pwm int, force bool) {
+}
+
+func (fan RedfishFan) GetMinPwm() int {
+	return getConfiguredMinPwm(fan.Config)
+}
+
+func (fan *RedfishFan) SetMinPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan RedfishFan) GetMaxPwm() int {
+	return getConfiguredMaxPwm(fan.Config)
+}
+
+func (fan *RedfishFan) SetMaxPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan *RedfishFan) GetRpm() (int, error) {
+	if !fan.Supports(FeatureRpmSensor) {
+		return 0, nil
+	}
+
+	rpm, err := fan.client.GetFanRpm(fan.Config.Redfish.Fan)
+	if err != nil {
+		return 0, err
+	}
+
+	fan.Rpm = int(rpm)
+
+	return fan.Rpm, nil
+}
+
+func (fan RedfishFan) GetRpmAvg() float64 {
+	return fan.MovingAvg
+}
+
+func (fan *RedfishFan) SetRpmAvg(rpm float64) {
+	fan.MovingAvg = rpm
+}
+
+func (fan *RedfishFan) GetPwm() (result int, err error) {
+	control, err := fan.client.GetControl(fan.Config.Redfish.Control)
+	if err != nil {
+		return 0, err
+	}
+	if control.SetPoint == nil {
+		// the BMC doesn't report the set point, return the last known value instead
+		return fan.Pwm, nil
+	}
+
+	fan.Pwm = dutyToPwm(int(math.Round(*control.SetPoint)))
+
+	return fan.Pwm, nil
+}
+
+func (fan *RedfishFan) SetPwm(pwm int) (err error) {
+	duty := float64(pwmToDuty(pwm))
+	err = fan.client.SetControl(fan.Config.Redfish.Control, redfish.Control{
+		SetPoint: &duty,
+	})
+	if err != nil {
+		return err
+	}
+
+	fan.Pwm = pwm
+
+	return nil
+}
+
+func (fan RedfishFan) GetFanCurveData() *map[int]float64 {
+	return &interpolated
+}
+
+func (fan *RedfishFan) AttachFanCurveData(curveData *map[int]float64) (err error) {
+	// not supported
+	return
+}
+
+func (fan RedfishFan) GetCurveId() string {
+	return fan.Config.Curve
+}
+
+func (fan RedfishFan) ShouldNeverStop() bool {
+	return fan.Config.NeverStop
+}
+
+func (fan RedfishFan) GetPwmEnabled() (int, error) {
+	return int(fan.Mode), nil
+}
+
+// SetPwmEnabled switches the Control resource between manual and automatic control by the BMC
+func (fan *RedfishFan) SetPwmEnabled(value ControlMode) (err error) {
+	path := fan.Config.Redfish.Control
+
+	switch value {
+	case ControlModePWM:
+		err = fan.client.SetControl(path, redfish.Control{ControlMode: redfish.ControlModeManual})
+	case ControlModeAutomatic:
+		err = fan.client.SetControl(path, redfish.Control{ControlMode: redfish.ControlModeAutomatic})
+	case ControlModeDisabled:
+		// there is no way to "disable" control, run the fan at full speed instead
+		err = fan.SetPwm(MaxPwmValue)
+	default:
+		err = fmt.Errorf("unsupported control mode %d", value)
+	}
+	if err != nil {
+		return err
+	}
+
+	fan.Mode = value
+
+	return nil
+}
+
+func (fan RedfishFan) IsPwmAuto() (bool, error) {
+	return fan.Mode == ControlModeAutomatic, nil
+}
+
+func (fan RedfishFan) Supports(feature FeatureFlag) bool {
+	switch feature {
+	case FeatureControlMode:
+		return true
+	case FeatureRpmSensor:
+		return len(fan.Config.Redfish.Fan) > 0
+	}
+	return false
+}
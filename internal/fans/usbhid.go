@@ -0,0 +1,134 @@
+package fans
+
+import (
+	"fmt"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/usbhid"
+)
+
+type UsbHidFan struct {
+	Config    configuration.FanConfig `json:"configuration"`
+	MovingAvg float64                 `json:"movingAvg"`
+
+	Rpm int `json:"rpm"`
+	Pwm int `json:"pwm"`
+
+	Controller usbhid.Controller `json:"-"`
+}
+
+func NewUsbHidFan(config configuration.FanConfig) (*UsbHidFan, error) {
+	controller, err := usbhid.Find(config.UsbHid.Model, config.UsbHid.Index, config.UsbHid.Path)
+	if err != nil {
+		return nil, fmt.Errorf("fan %s: %v", config.ID, err)
+	}
+
+	return &UsbHidFan{
+		Config: config,
+		// the current value can't be read from the device, assume full speed
+		Pwm:        MaxPwmValue,
+		Controller: controller,
+	}, nil
+}
+
+func (fan UsbHidFan) GetId() string {
+	return fan.Config.ID
+}
+
+func (fan UsbHidFan) GetConfig() configuration.FanConfig {
+	return fan.Config
+}
+
+func (fan UsbHidFan) GetStartPwm() int {
+	return getConfiguredStartPwm(fan.Config)
+}
+
+func (fan *UsbHidFan) SetStartPwm(pwm int, force bool) {
+}
+
+func (fan UsbHidFan) GetMinPwm() int {
+	return getConfiguredMinPwm(fan.Config)
+}
+
+func (fan *UsbHidFan) SetMinPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan UsbHidFan) GetMaxPwm() int {
+	return getConfiguredMaxPwm(fan.Config)
+}
+
+func (fan *UsbHidFan) SetMaxPwm(pwm int, force bool) {
+	// not supported
+}
+
+func (fan *UsbHidFan) GetRpm() (int, error) {
+	rpm, err := fan.Controller.GetRpm(fan.Config.UsbHid.Channel)
+	if err != nil {
+		return 0, err
+	}
+	fan.Rpm = rpm
+	return rpm, nil
+}
+
+func (fan UsbHidFan) GetRpmAvg() float64 {
+	return fan.MovingAvg
+}
+
+func (fan *UsbHidFan) SetRpmAvg(rpm float64) {
+	fan.MovingAvg = rpm
+}
+
+// GetPwm returns the last PWM value set by fan2go
+func (fan *UsbHidFan) GetPwm() (result int, err error) {
+	return fan.Pwm, nil
+}
+
+func (fan *UsbHidFan) SetPwm(pwm int) (err error) {
+	err = fan.Controller.SetDuty(fan.Config.UsbHid.Channel, pwmToDuty(pwm))
+	if err != nil {
+		return err
+	}
+	fan.Pwm = pwm
+	return nil
+}
+
+func (fan UsbHidFan) GetFanCurveData() *map[int]float64 {
+	return &interpolated
+}
+
+func (fan *UsbHidFan) AttachFanCurveData(curveData *map[int]float64) (err error) {
+	// not supported
+	return
+}
+
+func (fan UsbHidFan) GetCurveId() string {
+	return fan.Config.Curve
+}
+
+func (fan UsbHidFan) ShouldNeverStop() bool {
+	return fan.Config.NeverStop
+}
+
+func (fan UsbHidFan) GetPwmEnabled() (int, error) {
+	return 1, nil
+}
+
+func (fan *UsbHidFan) SetPwmEnabled(value ControlMode) (err error) {
+	// nothing to do
+	return nil
+}
+
+func (fan UsbHidFan) IsPwmAuto() (bool, error) {
+	return false, nil
+}
+
+func (fan UsbHidFan) Supports(feature FeatureFlag) bool {
+	switch feature {
+	case FeatureControlMode:
+		return false
+	case FeatureRpmSensor:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tokenInterceptors returns server options rejecting all calls which don't carry the given token
+// as "authorization: Bearer <token>" metadata
+func tokenInterceptors(token string) []grpclib.ServerOption {
+	expected := []byte("Bearer " + token)
+	check := func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, value := range md.Get("authorization") {
+			if subtle.ConstantTimeCompare([]byte(value), expected) == 1 {
+				return nil
+			}
+		}
+		return status.Error(codes.Unauthenticated, "missing or invalid token")
+	}
+
+	return []grpclib.ServerOption{
+		grpclib.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (interface{}, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpclib.StreamInterceptor(func(srv interface{}, stream grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+			if err := check(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
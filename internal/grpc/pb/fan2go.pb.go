@@ -0,0 +1,1048 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v3.21.12
+// source: fan2go.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Fan struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id    string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Curve string `protobuf:"bytes,2,opt,name=curve,proto3" json:"curve,omitempty"`
+	// pwm is unset if it can't be read
+	Pwm    *int32  `protobuf:"varint,3,opt,name=pwm,proto3,oneof" json:"pwm,omitempty"`
+	RpmAvg float64 `protobuf:"fixed64,4,opt,name=rpm_avg,json=rpmAvg,proto3" json:"rpm_avg,omitempty"`
+	// override is unset if the fan follows its curve
+	Override *Override `protobuf:"bytes,5,opt,name=override,proto3" json:"override,omitempty"`
+}
+
+func (x *Fan) Reset() {
+	*x = Fan{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Fan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fan) ProtoMessage() {}
+
+func (x *Fan) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fan.ProtoReflect.Descriptor instead.
+func (*Fan) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Fan) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Fan) GetCurve() string {
+	if x != nil {
+		return x.Curve
+	}
+	return ""
+}
+
+func (x *Fan) GetPwm() int32 {
+	if x != nil && x.Pwm != nil {
+		return *x.Pwm
+	}
+	return 0
+}
+
+func (x *Fan) GetRpmAvg() float64 {
+	if x != nil {
+		return x.RpmAvg
+	}
+	return 0
+}
+
+func (x *Fan) GetOverride() *Override {
+	if x != nil {
+		return x.Override
+	}
+	return nil
+}
+
+type Override struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pwm int32 `protobuf:"varint,1,opt,name=pwm,proto3" json:"pwm,omitempty"`
+	// until is unset if the override never expires
+	Until *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=until,proto3" json:"until,omitempty"`
+}
+
+func (x *Override) Reset() {
+	*x = Override{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Override) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Override) ProtoMessage() {}
+
+func (x *Override) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Override.ProtoReflect.Descriptor instead.
+func (*Override) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Override) GetPwm() int32 {
+	if x != nil {
+		return x.Pwm
+	}
+	return 0
+}
+
+func (x *Override) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+type Sensor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// moving_avg is the moving average of the sensor value, in milli-degrees for temperature sensors
+	MovingAvg float64 `protobuf:"fixed64,2,opt,name=moving_avg,json=movingAvg,proto3" json:"moving_avg,omitempty"`
+}
+
+func (x *Sensor) Reset() {
+	*x = Sensor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Sensor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sensor) ProtoMessage() {}
+
+func (x *Sensor) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sensor.ProtoReflect.Descriptor instead.
+func (*Sensor) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Sensor) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Sensor) GetMovingAvg() float64 {
+	if x != nil {
+		return x.MovingAvg
+	}
+	return 0
+}
+
+type ListFansRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListFansRequest) Reset() {
+	*x = ListFansRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListFansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFansRequest) ProtoMessage() {}
+
+func (x *ListFansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFansRequest.ProtoReflect.Descriptor instead.
+func (*ListFansRequest) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{3}
+}
+
+type ListFansResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Fans []*Fan `protobuf:"bytes,1,rep,name=fans,proto3" json:"fans,omitempty"`
+}
+
+func (x *ListFansResponse) Reset() {
+	*x = ListFansResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListFansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFansResponse) ProtoMessage() {}
+
+func (x *ListFansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFansResponse.ProtoReflect.Descriptor instead.
+func (*ListFansResponse) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListFansResponse) GetFans() []*Fan {
+	if x != nil {
+		return x.Fans
+	}
+	return nil
+}
+
+type GetFanRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetFanRequest) Reset() {
+	*x = GetFanRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetFanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFanRequest) ProtoMessage() {}
+
+func (x *GetFanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFanRequest.ProtoReflect.Descriptor instead.
+func (*GetFanRequest) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetFanRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListSensorsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListSensorsRequest) Reset() {
+	*x = ListSensorsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListSensorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSensorsRequest) ProtoMessage() {}
+
+func (x *ListSensorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSensorsRequest.ProtoReflect.Descriptor instead.
+func (*ListSensorsRequest) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{6}
+}
+
+type ListSensorsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sensors []*Sensor `protobuf:"bytes,1,rep,name=sensors,proto3" json:"sensors,omitempty"`
+}
+
+func (x *ListSensorsResponse) Reset() {
+	*x = ListSensorsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListSensorsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSensorsResponse) ProtoMessage() {}
+
+func (x *ListSensorsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSensorsResponse.ProtoReflect.Descriptor instead.
+func (*ListSensorsResponse) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListSensorsResponse) GetSensors() []*Sensor {
+	if x != nil {
+		return x.Sensors
+	}
+	return nil
+}
+
+type GetSensorRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetSensorRequest) Reset() {
+	*x = GetSensorRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSensorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSensorRequest) ProtoMessage() {}
+
+func (x *GetSensorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSensorRequest.ProtoReflect.Descriptor instead.
+func (*GetSensorRequest) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetSensorRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type SetOverrideRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id  string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Pwm int32  `protobuf:"varint,2,opt,name=pwm,proto3" json:"pwm,omitempty"`
+	// duration of the override, forever if unset
+	Duration *durationpb.Duration `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
+}
+
+func (x *SetOverrideRequest) Reset() {
+	*x = SetOverrideRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetOverrideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOverrideRequest) ProtoMessage() {}
+
+func (x *SetOverrideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOverrideRequest.ProtoReflect.Descriptor instead.
+func (*SetOverrideRequest) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SetOverrideRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SetOverrideRequest) GetPwm() int32 {
+	if x != nil {
+		return x.Pwm
+	}
+	return 0
+}
+
+func (x *SetOverrideRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type ClearOverrideRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *ClearOverrideRequest) Reset() {
+	*x = ClearOverrideRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClearOverrideRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearOverrideRequest) ProtoMessage() {}
+
+func (x *ClearOverrideRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearOverrideRequest.ProtoReflect.Descriptor instead.
+func (*ClearOverrideRequest) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ClearOverrideRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type StreamTelemetryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// interval at which values are checked for changes, defaults to 1s
+	Interval *durationpb.Duration `protobuf:"bytes,1,opt,name=interval,proto3" json:"interval,omitempty"`
+}
+
+func (x *StreamTelemetryRequest) Reset() {
+	*x = StreamTelemetryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamTelemetryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTelemetryRequest) ProtoMessage() {}
+
+func (x *StreamTelemetryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTelemetryRequest.ProtoReflect.Descriptor instead.
+func (*StreamTelemetryRequest) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *StreamTelemetryRequest) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+type Telemetry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Fans      []*Fan                 `protobuf:"bytes,2,rep,name=fans,proto3" json:"fans,omitempty"`
+	Sensors   []*Sensor              `protobuf:"bytes,3,rep,name=sensors,proto3" json:"sensors,omitempty"`
+}
+
+func (x *Telemetry) Reset() {
+	*x = Telemetry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fan2go_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Telemetry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Telemetry) ProtoMessage() {}
+
+func (x *Telemetry) ProtoReflect() protoreflect.Message {
+	mi := &file_fan2go_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Telemetry.ProtoReflect.Descriptor instead.
+func (*Telemetry) Descriptor() ([]byte, []int) {
+	return file_fan2go_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Telemetry) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Telemetry) GetFans() []*Fan {
+	if x != nil {
+		return x.Fans
+	}
+	return nil
+}
+
+func (x *Telemetry) GetSensors() []*Sensor {
+	if x != nil {
+		return x.Sensors
+	}
+	return nil
+}
+
+var File_fan2go_proto protoreflect.FileDescriptor
+
+var file_fan2go_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09,
+	0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x94, 0x01, 0x0a, 0x03, 0x46,
+	0x61, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x75, 0x72, 0x76, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x63, 0x75, 0x72, 0x76, 0x65, 0x12, 0x15, 0x0a, 0x03, 0x70, 0x77, 0x6d, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x03, 0x70, 0x77, 0x6d, 0x88, 0x01, 0x01, 0x12,
+	0x17, 0x0a, 0x07, 0x72, 0x70, 0x6d, 0x5f, 0x61, 0x76, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x06, 0x72, 0x70, 0x6d, 0x41, 0x76, 0x67, 0x12, 0x2f, 0x0a, 0x08, 0x6f, 0x76, 0x65, 0x72,
+	0x72, 0x69, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x66, 0x61, 0x6e,
+	0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x52,
+	0x08, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x70, 0x77,
+	0x6d, 0x22, 0x4e, 0x0a, 0x08, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x12, 0x10, 0x0a,
+	0x03, 0x70, 0x77, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x70, 0x77, 0x6d, 0x12,
+	0x30, 0x0a, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x75, 0x6e, 0x74, 0x69,
+	0x6c, 0x22, 0x37, 0x0a, 0x06, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6d,
+	0x6f, 0x76, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x76, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x09, 0x6d, 0x6f, 0x76, 0x69, 0x6e, 0x67, 0x41, 0x76, 0x67, 0x22, 0x11, 0x0a, 0x0f, 0x4c, 0x69,
+	0x73, 0x74, 0x46, 0x61, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x36, 0x0a,
+	0x10, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x61, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x22, 0x0a, 0x04, 0x66, 0x61, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x6e, 0x52,
+	0x04, 0x66, 0x61, 0x6e, 0x73, 0x22, 0x1f, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x46, 0x61, 0x6e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x65,
+	0x6e, 0x73, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x42, 0x0a, 0x13,
+	0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x07, 0x73, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x52, 0x07, 0x73, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x73,
+	0x22, 0x22, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x22, 0x6d, 0x0a, 0x12, 0x53, 0x65, 0x74, 0x4f, 0x76, 0x65, 0x72, 0x72,
+	0x69, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x77,
+	0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x70, 0x77, 0x6d, 0x12, 0x35, 0x0a, 0x08,
+	0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x22, 0x26, 0x0a, 0x14, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f, 0x76, 0x65, 0x72,
+	0x72, 0x69, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x4f, 0x0a, 0x16, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x54, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x35, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x22, 0x96, 0x01, 0x0a,
+	0x09, 0x54, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x12, 0x22, 0x0a, 0x04, 0x66, 0x61, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x46,
+	0x61, 0x6e, 0x52, 0x04, 0x66, 0x61, 0x6e, 0x73, 0x12, 0x2b, 0x0a, 0x07, 0x73, 0x65, 0x6e, 0x73,
+	0x6f, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x66, 0x61, 0x6e, 0x32,
+	0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x52, 0x07, 0x73, 0x65,
+	0x6e, 0x73, 0x6f, 0x72, 0x73, 0x32, 0xda, 0x03, 0x0a, 0x06, 0x46, 0x61, 0x6e, 0x32, 0x67, 0x6f,
+	0x12, 0x43, 0x0a, 0x08, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x61, 0x6e, 0x73, 0x12, 0x1a, 0x2e, 0x66,
+	0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x61, 0x6e,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67,
+	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x61, 0x6e, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x46, 0x61, 0x6e, 0x12,
+	0x18, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x46,
+	0x61, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x66, 0x61, 0x6e, 0x32,
+	0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x6e, 0x12, 0x4c, 0x0a, 0x0b, 0x4c, 0x69, 0x73,
+	0x74, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x73, 0x12, 0x1d, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67,
+	0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x53, 0x65,
+	0x6e, 0x73, 0x6f, 0x72, 0x12, 0x1b, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x47, 0x65, 0x74, 0x53, 0x65, 0x6e, 0x73, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x11, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65,
+	0x6e, 0x73, 0x6f, 0x72, 0x12, 0x3c, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x4f, 0x76, 0x65, 0x72, 0x72,
+	0x69, 0x64, 0x65, 0x12, 0x1d, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x65, 0x74, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x46,
+	0x61, 0x6e, 0x12, 0x40, 0x0a, 0x0d, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f, 0x76, 0x65, 0x72, 0x72,
+	0x69, 0x64, 0x65, 0x12, 0x1f, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x61, 0x6e, 0x12, 0x4c, 0x0a, 0x0f, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x54, 0x65,
+	0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x12, 0x21, 0x2e, 0x66, 0x61, 0x6e, 0x32, 0x67, 0x6f,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x54, 0x65, 0x6c, 0x65, 0x6d, 0x65,
+	0x74, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x66, 0x61, 0x6e,
+	0x32, 0x67, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65, 0x6c, 0x65, 0x6d, 0x65, 0x74, 0x72, 0x79,
+	0x30, 0x01, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x6d, 0x61, 0x72, 0x6b, 0x75, 0x73, 0x72, 0x65, 0x73, 0x73, 0x65, 0x6c, 0x2f, 0x66, 0x61,
+	0x6e, 0x32, 0x67, 0x6f, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72,
+	0x70, 0x63, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_fan2go_proto_rawDescOnce sync.Once
+	file_fan2go_proto_rawDescData = file_fan2go_proto_rawDesc
+)
+
+func file_fan2go_proto_rawDescGZIP() []byte {
+	file_fan2go_proto_rawDescOnce.Do(func() {
+		file_fan2go_proto_rawDescData = protoimpl.X.CompressGZIP(file_fan2go_proto_rawDescData)
+	})
+	return file_fan2go_proto_rawDescData
+}
+
+var file_fan2go_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_fan2go_proto_goTypes = []interface{}{
+	(*Fan)(nil),                    // 0: fan2go.v1.Fan
+	(*Override)(nil),               // 1: fan2go.v1.Override
+	(*Sensor)(nil),                 // 2: fan2go.v1.Sensor
+	(*ListFansRequest)(nil),        // 3: fan2go.v1.ListFansRequest
+	(*ListFansResponse)(nil),       // 4: fan2go.v1.ListFansResponse
+	(*GetFanRequest)(nil),          // 5: fan2go.v1.GetFanRequest
+	(*ListSensorsRequest)(nil),     // 6: fan2go.v1.ListSensorsRequest
+	(*ListSensorsResponse)(nil),    // 7: fan2go.v1.ListSensorsResponse
+	(*GetSensorRequest)(nil),       // 8: fan2go.v1.GetSensorRequest
+	(*SetOverrideRequest)(nil),     // 9: fan2go.v1.SetOverrideRequest
+	(*ClearOverrideRequest)(nil),   // 10: fan2go.v1.ClearOverrideRequest
+	(*StreamTelemetryRequest)(nil), // 11: fan2go.v1.StreamTelemetryRequest
+	(*Telemetry)(nil),              // 12: fan2go.v1.Telemetry
+	(*timestamppb.Timestamp)(nil),  // 13: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),    // 14: google.protobuf.Duration
+}
+var file_fan2go_proto_depIdxs = []int32{
+	1,  // 0: fan2go.v1.Fan.override:type_name -> fan2go.v1.Override
+	13, // 1: fan2go.v1.Override.until:type_name -> google.protobuf.Timestamp
+	0,  // 2: fan2go.v1.ListFansResponse.fans:type_name -> fan2go.v1.Fan
+	2,  // 3: fan2go.v1.ListSensorsResponse.sensors:type_name -> fan2go.v1.Sensor
+	14, // 4: fan2go.v1.SetOverrideRequest.duration:type_name -> google.protobuf.Duration
+	14, // 5: fan2go.v1.StreamTelemetryRequest.interval:type_name -> google.protobuf.Duration
+	13, // 6: fan2go.v1.Telemetry.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 7: fan2go.v1.Telemetry.fans:type_name -> fan2go.v1.Fan
+	2,  // 8: fan2go.v1.Telemetry.sensors:type_name -> fan2go.v1.Sensor
+	3,  // 9: fan2go.v1.Fan2go.ListFans:input_type -> fan2go.v1.ListFansRequest
+	5,  // 10: fan2go.v1.Fan2go.GetFan:input_type -> fan2go.v1.GetFanRequest
+	6,  // 11: fan2go.v1.Fan2go.ListSensors:input_type -> fan2go.v1.ListSensorsRequest
+	8,  // 12: fan2go.v1.Fan2go.GetSensor:input_type -> fan2go.v1.GetSensorRequest
+	9,  // 13: fan2go.v1.Fan2go.SetOverride:input_type -> fan2go.v1.SetOverrideRequest
+	10, // 14: fan2go.v1.Fan2go.ClearOverride:input_type -> fan2go.v1.ClearOverrideRequest
+	11, // 15: fan2go.v1.Fan2go.StreamTelemetry:input_type -> fan2go.v1.StreamTelemetryRequest
+	4,  // 16: fan2go.v1.Fan2go.ListFans:output_type -> fan2go.v1.ListFansResponse
+	0,  // 17: fan2go.v1.Fan2go.GetFan:output_type -> fan2go.v1.Fan
+	7,  // 18: fan2go.v1.Fan2go.ListSensors:output_type -> fan2go.v1.ListSensorsResponse
+	2,  // 19: fan2go.v1.Fan2go.GetSensor:output_type -> fan2go.v1.Sensor
+	0,  // 20: fan2go.v1.Fan2go.SetOverride:output_type -> fan2go.v1.Fan
+	0,  // 21: fan2go.v1.Fan2go.ClearOverride:output_type -> fan2go.v1.Fan
+	12, // 22: fan2go.v1.Fan2go.StreamTelemetry:output_type -> fan2go.v1.Telemetry
+	16, // [16:23] is the sub-list for method output_type
+	9,  // [9:16] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_fan2go_proto_init() }
+func file_fan2go_proto_init() {
+	if File_fan2go_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_fan2go_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Fan); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Override); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Sensor); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListFansRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListFansResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetFanRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListSensorsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListSensorsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSensorRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetOverrideRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClearOverrideRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamTelemetryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fan2go_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Telemetry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_fan2go_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_fan2go_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_fan2go_proto_goTypes,
+		DependencyIndexes: file_fan2go_proto_depIdxs,
+		MessageInfos:      file_fan2go_proto_msgTypes,
+	}.Build()
+	File_fan2go_proto = out.File
+	file_fan2go_proto_rawDesc = nil
+	file_fan2go_proto_goTypes = nil
+	file_fan2go_proto_depIdxs = nil
+}
@@ -0,0 +1,373 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.21.12
+// source: fan2go.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Fan2Go_ListFans_FullMethodName        = "/fan2go.v1.Fan2go/ListFans"
+	Fan2Go_GetFan_FullMethodName          = "/fan2go.v1.Fan2go/GetFan"
+	Fan2Go_ListSensors_FullMethodName     = "/fan2go.v1.Fan2go/ListSensors"
+	Fan2Go_GetSensor_FullMethodName       = "/fan2go.v1.Fan2go/GetSensor"
+	Fan2Go_SetOverride_FullMethodName     = "/fan2go.v1.Fan2go/SetOverride"
+	Fan2Go_ClearOverride_FullMethodName   = "/fan2go.v1.Fan2go/ClearOverride"
+	Fan2Go_StreamTelemetry_FullMethodName = "/fan2go.v1.Fan2go/StreamTelemetry"
+)
+
+// Fan2GoClient is the client API for Fan2Go service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type Fan2GoClient interface {
+	// ListFans returns all configured fans
+	ListFans(ctx context.Context, in *ListFansRequest, opts ...grpc.CallOption) (*ListFansResponse, error)
+	// GetFan returns the fan with the given id
+	GetFan(ctx context.Context, in *GetFanRequest, opts ...grpc.CallOption) (*Fan, error)
+	// ListSensors returns all configured sensors
+	ListSensors(ctx context.Context, in *ListSensorsRequest, opts ...grpc.CallOption) (*ListSensorsResponse, error)
+	// GetSensor returns the sensor with the given id
+	GetSensor(ctx context.Context, in *GetSensorRequest, opts ...grpc.CallOption) (*Sensor, error)
+	// SetOverride overrides the curve of a fan with a fixed pwm value
+	SetOverride(ctx context.Context, in *SetOverrideRequest, opts ...grpc.CallOption) (*Fan, error)
+	// ClearOverride removes the override of a fan, returning control to its curve
+	ClearOverride(ctx context.Context, in *ClearOverrideRequest, opts ...grpc.CallOption) (*Fan, error)
+	// StreamTelemetry sends the state of all fans and sensors whenever a value changes
+	StreamTelemetry(ctx context.Context, in *StreamTelemetryRequest, opts ...grpc.CallOption) (Fan2Go_StreamTelemetryClient, error)
+}
+
+type fan2GoClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFan2GoClient(cc grpc.ClientConnInterface) Fan2GoClient {
+	return &fan2GoClient{cc}
+}
+
+func (c *fan2GoClient) ListFans(ctx context.Context, in *ListFansRequest, opts ...grpc.CallOption) (*ListFansResponse, error) {
+	out := new(ListFansResponse)
+	err := c.cc.Invoke(ctx, Fan2Go_ListFans_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fan2GoClient) GetFan(ctx context.Context, in *GetFanRequest, opts ...grpc.CallOption) (*Fan, error) {
+	out := new(Fan)
+	err := c.cc.Invoke(ctx, Fan2Go_GetFan_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fan2GoClient) ListSensors(ctx context.Context, in *ListSensorsRequest, opts ...grpc.CallOption) (*ListSensorsResponse, error) {
+	out := new(ListSensorsResponse)
+	err := c.cc.Invoke(ctx, Fan2Go_ListSensors_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fan2GoClient) GetSensor(ctx context.Context, in *GetSensorRequest, opts ...grpc.CallOption) (*Sensor, error) {
+	out := new(Sensor)
+	err := c.cc.Invoke(ctx, Fan2Go_GetSensor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fan2GoClient) SetOverride(ctx context.Context, in *SetOverrideRequest, opts ...grpc.CallOption) (*Fan, error) {
+	out := new(Fan)
+	err := c.cc.Invoke(ctx, Fan2Go_SetOverride_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fan2GoClient) ClearOverride(ctx context.Context, in *ClearOverrideRequest, opts ...grpc.CallOption) (*Fan, error) {
+	out := new(Fan)
+	err := c.cc.Invoke(ctx, Fan2Go_ClearOverride_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fan2GoClient) StreamTelemetry(ctx context.Context, in *StreamTelemetryRequest, opts ...grpc.CallOption) (Fan2Go_StreamTelemetryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Fan2Go_ServiceDesc.Streams[0], Fan2Go_StreamTelemetry_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fan2GoStreamTelemetryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Fan2Go_StreamTelemetryClient interface {
+	Recv() (*Telemetry, error)
+	grpc.ClientStream
+}
+
+type fan2GoStreamTelemetryClient struct {
+	grpc.ClientStream
+}
+
+func (x *fan2GoStreamTelemetryClient) Recv() (*Telemetry, error) {
+	m := new(Telemetry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Fan2GoServer is the server API for Fan2Go service.
+// All implementations must embed UnimplementedFan2GoServer
+// for forward compatibility
+type Fan2GoServer interface {
+	// ListFans returns all configured fans
+	ListFans(context.Context, *ListFansRequest) (*ListFansResponse, error)
+	// GetFan returns the fan with the given id
+	GetFan(context.Context, *GetFanRequest) (*Fan, error)
+	// ListSensors returns all configured sensors
+	ListSensors(context.Context, *ListSensorsRequest) (*ListSensorsResponse, error)
+	// GetSensor returns the sensor with the given id
+	GetSensor(context.Context, *GetSensorRequest) (*Sensor, error)
+	// SetOverride overrides the curve of a fan with a fixed pwm value
+	SetOverride(context.Context, *SetOverrideRequest) (*Fan, error)
+	// ClearOverride removes the override of a fan, returning control to its curve
+	ClearOverride(context.Context, *ClearOverrideRequest) (*Fan, error)
+	// StreamTelemetry sends the state of all fans and sensors whenever a value changes
+	StreamTelemetry(*StreamTelemetryRequest, Fan2Go_StreamTelemetryServer) error
+	mustEmbedUnimplementedFan2GoServer()
+}
+
+// UnimplementedFan2GoServer must be embedded to have forward compatible implementations.
+type UnimplementedFan2GoServer struct {
+}
+
+func (UnimplementedFan2GoServer) ListFans(context.Context, *ListFansRequest) (*ListFansResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFans not implemented")
+}
+func (UnimplementedFan2GoServer) GetFan(context.Context, *GetFanRequest) (*Fan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFan not implemented")
+}
+func (UnimplementedFan2GoServer) ListSensors(context.Context, *ListSensorsRequest) (*ListSensorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSensors not implemented")
+}
+func (UnimplementedFan2GoServer) GetSensor(context.Context, *GetSensorRequest) (*Sensor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSensor not implemented")
+}
+func (UnimplementedFan2GoServer) SetOverride(context.Context, *SetOverrideRequest) (*Fan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOverride not implemented")
+}
+func (UnimplementedFan2GoServer) ClearOverride(context.Context, *ClearOverrideRequest) (*Fan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearOverride not implemented")
+}
+func (UnimplementedFan2GoServer) StreamTelemetry(*StreamTelemetryRequest, Fan2Go_StreamTelemetryServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTelemetry not implemented")
+}
+func (UnimplementedFan2GoServer) mustEmbedUnimplementedFan2GoServer() {}
+
+// UnsafeFan2GoServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to Fan2GoServer will
+// result in compilation errors.
+type UnsafeFan2GoServer interface {
+	mustEmbedUnimplementedFan2GoServer()
+}
+
+func RegisterFan2GoServer(s grpc.ServiceRegistrar, srv Fan2GoServer) {
+	s.RegisterService(&Fan2Go_ServiceDesc, srv)
+}
+
+func _Fan2Go_ListFans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fan2GoServer).ListFans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fan2Go_ListFans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fan2GoServer).ListFans(ctx, req.(*ListFansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fan2Go_GetFan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fan2GoServer).GetFan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fan2Go_GetFan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fan2GoServer).GetFan(ctx, req.(*GetFanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fan2Go_ListSensors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSensorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fan2GoServer).ListSensors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fan2Go_ListSensors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fan2GoServer).ListSensors(ctx, req.(*ListSensorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fan2Go_GetSensor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSensorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fan2GoServer).GetSensor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fan2Go_GetSensor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fan2GoServer).GetSensor(ctx, req.(*GetSensorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fan2Go_SetOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fan2GoServer).SetOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fan2Go_SetOverride_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fan2GoServer).SetOverride(ctx, req.(*SetOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fan2Go_ClearOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Fan2GoServer).ClearOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fan2Go_ClearOverride_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Fan2GoServer).ClearOverride(ctx, req.(*ClearOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fan2Go_StreamTelemetry_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTelemetryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(Fan2GoServer).StreamTelemetry(m, &fan2GoStreamTelemetryServer{stream})
+}
+
+type Fan2Go_StreamTelemetryServer interface {
+	Send(*Telemetry) error
+	grpc.ServerStream
+}
+
+type fan2GoStreamTelemetryServer struct {
+	grpc.ServerStream
+}
+
+func (x *fan2GoStreamTelemetryServer) Send(m *Telemetry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Fan2Go_ServiceDesc is the grpc.ServiceDesc for Fan2Go service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Fan2Go_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fan2go.v1.Fan2go",
+	HandlerType: (*Fan2GoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListFans",
+			Handler:    _Fan2Go_ListFans_Handler,
+		},
+		{
+			MethodName: "GetFan",
+			Handler:    _Fan2Go_GetFan_Handler,
+		},
+		{
+			MethodName: "ListSensors",
+			Handler:    _Fan2Go_ListSensors_Handler,
+		},
+		{
+			MethodName: "GetSensor",
+			Handler:    _Fan2Go_GetSensor_Handler,
+		},
+		{
+			MethodName: "SetOverride",
+			Handler:    _Fan2Go_SetOverride_Handler,
+		},
+		{
+			MethodName: "ClearOverride",
+			Handler:    _Fan2Go_ClearOverride_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTelemetry",
+			Handler:       _Fan2Go_StreamTelemetry_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "fan2go.proto",
+}
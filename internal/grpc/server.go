@@ -0,0 +1,261 @@
+package grpc
+
+//go:generate protoc -I pb --go_out=pb --go_opt=paths=source_relative --go-grpc_out=pb --go-grpc_opt=paths=source_relative pb/fan2go.proto
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/grpc/pb"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/ui"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultTelemetryInterval = 1 * time.Second
+	minTelemetryInterval     = 100 * time.Millisecond
+)
+
+// server implements the Fan2go gRPC service on top of the live fan, sensor and controller maps
+type server struct {
+	pb.UnimplementedFan2GoServer
+
+	// readOnly rejects all calls changing the state of fan2go, f.ex. when serving the sensors of an agent
+	readOnly bool
+}
+
+// NewServer returns a gRPC server with the Fan2go service registered, using the tls and token of the
+// given config. A readOnly server rejects all calls changing the state of fan2go.
+func NewServer(config configuration.GrpcConfig, readOnly bool) (*grpclib.Server, error) {
+	var options []grpclib.ServerOption
+	if config.Tls != nil {
+		creds, err := credentials.NewServerTLSFromFile(config.Tls.CertFile, config.Tls.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load tls certificate: %v", err)
+		}
+		options = append(options, grpclib.Creds(creds))
+	}
+	if len(config.Token) > 0 {
+		options = append(options, tokenInterceptors(config.Token)...)
+	}
+
+	s := grpclib.NewServer(options...)
+	pb.RegisterFan2GoServer(s, &server{readOnly: readOnly})
+	return s, nil
+}
+
+// Run serves the gRPC api on the configured address until the given context is done
+func Run(ctx context.Context, config configuration.GrpcConfig, readOnly bool) error {
+	s, err := NewServer(config, readOnly)
+	if err != nil {
+		return err
+	}
+
+	address := fmt.Sprintf("%s:%d", config.Host, config.Port)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %v", address, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.GracefulStop()
+	}()
+
+	ui.Info("gRPC api listening on %s", address)
+	return s.Serve(listener)
+}
+
+func (s *server) ListFans(ctx context.Context, request *pb.ListFansRequest) (*pb.ListFansResponse, error) {
+	return &pb.ListFansResponse{Fans: collectFans()}, nil
+}
+
+func (s *server) GetFan(ctx context.Context, request *pb.GetFanRequest) (*pb.Fan, error) {
+	if _, ok := fans.GetFan(request.Id); !ok {
+		return nil, notFound(request.Id)
+	}
+	return toFan(request.Id), nil
+}
+
+func (s *server) ListSensors(ctx context.Context, request *pb.ListSensorsRequest) (*pb.ListSensorsResponse, error) {
+	return &pb.ListSensorsResponse{Sensors: collectSensors()}, nil
+}
+
+func (s *server) GetSensor(ctx context.Context, request *pb.GetSensorRequest) (*pb.Sensor, error) {
+	sensor, ok := sensors.GetSensor(request.Id)
+	if !ok {
+		return nil, notFound(request.Id)
+	}
+	return toSensor(sensor), nil
+}
+
+func (s *server) SetOverride(ctx context.Context, request *pb.SetOverrideRequest) (*pb.Fan, error) {
+	if s.readOnly {
+		return nil, readOnlyError()
+	}
+	fanController, ok := controller.GetFanController(request.Id)
+	if !ok {
+		return nil, notFound(request.Id)
+	}
+	if request.Pwm < fans.MinPwmValue || request.Pwm > fans.MaxPwmValue {
+		return nil, status.Errorf(codes.InvalidArgument, "pwm must be in range [%d..%d]", fans.MinPwmValue, fans.MaxPwmValue)
+	}
+
+	var duration time.Duration
+	if request.Duration != nil {
+		duration = request.Duration.AsDuration()
+		if duration < 0 {
+			return nil, status.Error(codes.InvalidArgument, "duration must not be negative")
+		}
+	}
+
+	fanController.SetOverride(int(request.Pwm), duration)
+	return toFan(request.Id), nil
+}
+
+func (s *server) ClearOverride(ctx context.Context, request *pb.ClearOverrideRequest) (*pb.Fan, error) {
+	if s.readOnly {
+		return nil, readOnlyError()
+	}
+	fanController, ok := controller.GetFanController(request.Id)
+	if !ok {
+		return nil, notFound(request.Id)
+	}
+	fanController.ClearOverride()
+	return toFan(request.Id), nil
+}
+
+func (s *server) StreamTelemetry(request *pb.StreamTelemetryRequest, stream pb.Fan2Go_StreamTelemetryServer) error {
+	interval := defaultTelemetryInterval
+	if request.Interval != nil {
+		interval = request.Interval.AsDuration()
+		if interval < minTelemetryInterval {
+			interval = minTelemetryInterval
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *pb.Telemetry
+	for {
+		telemetry := &pb.Telemetry{
+			Timestamp: timestamppb.Now(),
+			Fans:      collectFans(),
+			Sensors:   collectSensors(),
+		}
+		if last == nil || !telemetryEqual(last, telemetry) {
+			if err := stream.Send(telemetry); err != nil {
+				return err
+			}
+			last = telemetry
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// telemetryEqual returns true if both telemetry messages contain the same values, ignoring their timestamp
+func telemetryEqual(a *pb.Telemetry, b *pb.Telemetry) bool {
+	if len(a.Fans) != len(b.Fans) || len(a.Sensors) != len(b.Sensors) {
+		return false
+	}
+	for i := range a.Fans {
+		if !proto.Equal(a.Fans[i], b.Fans[i]) {
+			return false
+		}
+	}
+	for i := range a.Sensors {
+		if !proto.Equal(a.Sensors[i], b.Sensors[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func collectFans() []*pb.Fan {
+	var result []*pb.Fan
+	fanMap := fans.GetFanMap()
+	for _, id := range sortedKeys(fanMap) {
+		result = append(result, toFan(id))
+	}
+	return result
+}
+
+func collectSensors() []*pb.Sensor {
+	var result []*pb.Sensor
+	sensorMap := sensors.GetSensorMap()
+	for _, id := range sortedKeys(sensorMap) {
+		result = append(result, toSensor(sensorMap[id]))
+	}
+	return result
+}
+
+// toFan returns the fan with the given id, fans are only accessed by their controllers,
+// so the values cached by them are used
+func toFan(id string) *pb.Fan {
+	result := &pb.Fan{
+		Id: id,
+	}
+	for _, fanConfig := range configuration.GetFans() {
+		if fanConfig.ID == id {
+			result.Curve = fanConfig.Curve
+		}
+	}
+	if fanController, ok := controller.GetFanController(id); ok {
+		snapshot := fanController.GetFanSnapshot()
+		result.RpmAvg = snapshot.RpmAvg
+		if snapshot.Pwm != nil {
+			value := int32(*snapshot.Pwm)
+			result.Pwm = &value
+		}
+		if override := fanController.GetOverride(); override != nil {
+			result.Override = &pb.Override{Pwm: int32(override.Pwm)}
+			if !override.Until.IsZero() {
+				result.Override.Until = timestamppb.New(override.Until)
+			}
+		}
+	}
+	return result
+}
+
+func toSensor(sensor sensors.Sensor) *pb.Sensor {
+	return &pb.Sensor{
+		Id:        sensor.GetId(),
+		MovingAvg: sensor.GetMovingAvg(),
+	}
+}
+
+func readOnlyError() error {
+	return status.Error(codes.PermissionDenied, "this instance only serves its sensors")
+}
+
+func notFound(id string) error {
+	return status.Errorf(codes.NotFound, "no item with id '%s' found", id)
+}
+
+// sortedKeys returns the keys of the given map in alphabetical order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
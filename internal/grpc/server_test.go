@@ -0,0 +1,184 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/grpc/pb"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func createClient(t *testing.T) pb.Fan2GoClient {
+	return createClientWithConfig(t, configuration.GrpcConfig{}, false)
+}
+
+func createClientWithConfig(t *testing.T, config configuration.GrpcConfig, readOnly bool) pb.Fan2GoClient {
+	listener := bufconn.Listen(1024 * 1024)
+	s, err := NewServer(config, readOnly)
+	assert.NoError(t, err)
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpclib.Dial(
+		"bufnet",
+		grpclib.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpclib.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb.NewFan2GoClient(conn)
+}
+
+func createSensors() {
+	sensors.SetSensorMap(map[string]sensors.Sensor{
+		"cpu": &sensors.FileSensor{
+			Config:    configuration.SensorConfig{ID: "cpu"},
+			MovingAvg: 40000,
+		},
+		"gpu": &sensors.FileSensor{
+			Config:    configuration.SensorConfig{ID: "gpu"},
+			MovingAvg: 60000,
+		},
+	})
+}
+
+func TestListSensors(t *testing.T) {
+	// GIVEN
+	createSensors()
+	client := createClient(t)
+
+	// WHEN
+	response, err := client.ListSensors(context.Background(), &pb.ListSensorsRequest{})
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, response.Sensors, 2)
+	assert.Equal(t, "cpu", response.Sensors[0].Id)
+	assert.Equal(t, 40000.0, response.Sensors[0].MovingAvg)
+	assert.Equal(t, "gpu", response.Sensors[1].Id)
+}
+
+func TestGetSensor_NotFound(t *testing.T) {
+	// GIVEN
+	createSensors()
+	client := createClient(t)
+
+	// WHEN
+	_, err := client.GetSensor(context.Background(), &pb.GetSensorRequest{Id: "unknown"})
+
+	// THEN
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGetFan_UsesControllerSnapshot(t *testing.T) {
+	// GIVEN
+	pwmPath := path.Join(t.TempDir(), "pwm")
+	err := os.WriteFile(pwmPath, []byte("100"), 0644)
+	assert.NoError(t, err)
+	fanConfig := configuration.FanConfig{
+		ID:    "fan",
+		Curve: "curve",
+		File:  &configuration.FileFanConfig{Path: pwmPath},
+	}
+	configuration.SetObjects(&configuration.Configuration{Fans: []configuration.FanConfig{fanConfig}})
+	fan := &fans.FileFan{Config: fanConfig}
+	fans.SetFanMap(map[string]fans.Fan{fan.GetId(): fan})
+	fanController := controller.NewFanController(nil, fan, *util.NewPidLoop(0.03, 0.002, 0.0005), time.Second)
+	controller.SetFanControllerMap(map[string]controller.FanController{fan.GetId(): fanController})
+	defer func() {
+		configuration.SetObjects(&configuration.Configuration{})
+		fans.SetFanMap(map[string]fans.Fan{})
+		controller.SetFanControllerMap(map[string]controller.FanController{})
+	}()
+	client := createClient(t)
+
+	// WHEN
+	response, err := client.GetFan(context.Background(), &pb.GetFanRequest{Id: "fan"})
+
+	// THEN
+	// the controller didn't update the fan yet, the fan itself is not read
+	assert.NoError(t, err)
+	assert.Equal(t, "curve", response.Curve)
+	assert.Nil(t, response.Pwm)
+}
+
+func TestSetOverride_NotFound(t *testing.T) {
+	// GIVEN
+	client := createClient(t)
+
+	// WHEN
+	_, err := client.SetOverride(context.Background(), &pb.SetOverrideRequest{Id: "unknown", Pwm: 100})
+
+	// THEN
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSetOverride_ReadOnly(t *testing.T) {
+	// GIVEN
+	client := createClientWithConfig(t, configuration.GrpcConfig{}, true)
+
+	// WHEN
+	_, err := client.SetOverride(context.Background(), &pb.SetOverrideRequest{Id: "fan", Pwm: 0})
+	_, clearErr := client.ClearOverride(context.Background(), &pb.ClearOverrideRequest{Id: "fan"})
+
+	// THEN
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, codes.PermissionDenied, status.Code(clearErr))
+}
+
+func TestToken(t *testing.T) {
+	// GIVEN
+	createSensors()
+	client := createClientWithConfig(t, configuration.GrpcConfig{Token: "secret"}, false)
+	request := &pb.GetSensorRequest{Id: "cpu"}
+
+	// WHEN
+	_, missingErr := client.GetSensor(context.Background(), request)
+	_, invalidErr := client.GetSensor(metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong"), request)
+	sensor, err := client.GetSensor(metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret"), request)
+
+	// THEN
+	assert.Equal(t, codes.Unauthenticated, status.Code(missingErr))
+	assert.Equal(t, codes.Unauthenticated, status.Code(invalidErr))
+	assert.NoError(t, err)
+	assert.Equal(t, 40000.0, sensor.MovingAvg)
+}
+
+func TestStreamTelemetry(t *testing.T) {
+	// GIVEN
+	createSensors()
+	client := createClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// WHEN
+	stream, err := client.StreamTelemetry(ctx, &pb.StreamTelemetryRequest{})
+	assert.NoError(t, err)
+	telemetry, err := stream.Recv()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.NotNil(t, telemetry.Timestamp)
+	assert.Len(t, telemetry.Sensors, 2)
+	assert.Equal(t, 60000.0, telemetry.Sensors[1].MovingAvg)
+}
@@ -0,0 +1,212 @@
+package history
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/sensors"
+)
+
+// Sample contains the values of all sensors and fans at a point in time
+type Sample struct {
+	Time time.Time `json:"time"`
+	// Sensors maps from sensor id -> moving average of the sensor
+	Sensors map[string]float64 `json:"sensors"`
+	// Fans maps from fan id -> pwm and rpm of the fan
+	Fans map[string]FanSample `json:"fans"`
+}
+
+type FanSample struct {
+	Pwm *int    `json:"pwm"`
+	Rpm float64 `json:"rpm"`
+}
+
+// recorder keeps the most recent samples in a ring buffer
+type recorder struct {
+	lock    sync.RWMutex
+	samples []Sample
+	// next is the index the next sample is written to
+	next int
+	full bool
+}
+
+var (
+	// current is the recorder of the running daemon, nil if history recording is disabled
+	current     *recorder
+	currentLock sync.RWMutex
+)
+
+// IsEnabled indicates whether samples are being recorded
+func IsEnabled() bool {
+	return getRecorder() != nil
+}
+
+func getRecorder() *recorder {
+	currentLock.RLock()
+	defer currentLock.RUnlock()
+	return current
+}
+
+// Run records a sample of all sensors and fans at the configured interval, until the given context is done
+func Run(ctx context.Context, config configuration.HistoryConfig) error {
+	size := int(config.Retention / config.Interval)
+	if size < 1 {
+		size = 1
+	}
+	r := newRecorder(size)
+	currentLock.Lock()
+	current = r
+	currentLock.Unlock()
+
+	tick := time.NewTicker(config.Interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+			r.add(collectSample())
+		}
+	}
+}
+
+// GetSamples returns all recorded samples taken after the given time, oldest first
+func GetSamples(since time.Time) []Sample {
+	r := getRecorder()
+	if r == nil {
+		return []Sample{}
+	}
+	return r.get(since)
+}
+
+func newRecorder(size int) *recorder {
+	return &recorder{
+		samples: make([]Sample, size),
+	}
+}
+
+func (r *recorder) add(sample Sample) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.samples[r.next] = sample
+	r.next = (r.next + 1) % len(r.samples)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *recorder) get(since time.Time) []Sample {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var ordered []Sample
+	if r.full {
+		ordered = append(ordered, r.samples[r.next:]...)
+	}
+	ordered = append(ordered, r.samples[:r.next]...)
+
+	result := []Sample{}
+	for _, sample := range ordered {
+		if sample.Time.After(since) {
+			result = append(result, sample)
+		}
+	}
+	return result
+}
+
+// collectSample returns the current (cached) values of all sensors and fans
+func collectSample() Sample {
+	sample := Sample{
+		Time:    time.Now(),
+		Sensors: map[string]float64{},
+		Fans:    map[string]FanSample{},
+	}
+	for id, sensor := range sensors.GetSensorMap() {
+		sample.Sensors[id] = sensor.GetMovingAvg()
+	}
+	// fans are only accessed by their controllers, so the values cached by them are used
+	for id := range fans.GetFanMap() {
+		fanSample := FanSample{}
+		if fanController, exists := controller.GetFanController(id); exists {
+			snapshot := fanController.GetFanSnapshot()
+			fanSample.Pwm = snapshot.Pwm
+			fanSample.Rpm = snapshot.RpmAvg
+		}
+		sample.Fans[id] = fanSample
+	}
+	return sample
+}
+
+// WriteCsv writes the given samples as CSV, with one row per sample and one column per sensor value,
+// fan pwm and fan rpm. Values that are missing in a sample are left empty.
+func WriteCsv(w io.Writer, samples []Sample) error {
+	sensorIds := map[string]bool{}
+	fanIds := map[string]bool{}
+	for _, sample := range samples {
+		for id := range sample.Sensors {
+			sensorIds[id] = true
+		}
+		for id := range sample.Fans {
+			fanIds[id] = true
+		}
+	}
+	sortedSensorIds := sortedKeys(sensorIds)
+	sortedFanIds := sortedKeys(fanIds)
+
+	header := []string{"time"}
+	for _, id := range sortedSensorIds {
+		header = append(header, fmt.Sprintf("sensor:%s", id))
+	}
+	for _, id := range sortedFanIds {
+		header = append(header, fmt.Sprintf("fan:%s:pwm", id), fmt.Sprintf("fan:%s:rpm", id))
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, sample := range samples {
+		row := []string{sample.Time.Format(time.RFC3339Nano)}
+		for _, id := range sortedSensorIds {
+			value := ""
+			if avg, ok := sample.Sensors[id]; ok {
+				value = strconv.FormatFloat(avg, 'f', -1, 64)
+			}
+			row = append(row, value)
+		}
+		for _, id := range sortedFanIds {
+			pwm, rpm := "", ""
+			if fan, ok := sample.Fans[id]; ok {
+				if fan.Pwm != nil {
+					pwm = strconv.Itoa(*fan.Pwm)
+				}
+				rpm = strconv.FormatFloat(fan.Rpm, 'f', -1, 64)
+			}
+			row = append(row, pwm, rpm)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
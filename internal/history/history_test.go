@@ -0,0 +1,107 @@
+package history
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderKeepsMostRecentSamples(t *testing.T) {
+	// GIVEN
+	r := newRecorder(3)
+	start := time.Now()
+
+	// WHEN
+	for i := 0; i < 5; i++ {
+		r.add(Sample{Time: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	// THEN
+	samples := r.get(time.Time{})
+	assert.Len(t, samples, 3)
+	assert.Equal(t, start.Add(2*time.Second), samples[0].Time)
+	assert.Equal(t, start.Add(4*time.Second), samples[2].Time)
+}
+
+func TestRecorderGetSince(t *testing.T) {
+	// GIVEN
+	r := newRecorder(10)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		r.add(Sample{Time: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	// WHEN
+	samples := r.get(start.Add(1 * time.Second))
+
+	// THEN
+	assert.Len(t, samples, 2)
+	assert.Equal(t, start.Add(2*time.Second), samples[0].Time)
+}
+
+func TestWriteCsv(t *testing.T) {
+	// GIVEN
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	pwm := 128
+	samples := []Sample{
+		{
+			Time:    start,
+			Sensors: map[string]float64{"cpu": 45000, "gpu": 50500.5},
+			Fans:    map[string]FanSample{"cpu_fan": {Pwm: &pwm, Rpm: 1200}},
+		},
+		{
+			// a sensor that was removed in the meantime
+			Time:    start.Add(time.Second),
+			Sensors: map[string]float64{"cpu": 46000},
+			Fans:    map[string]FanSample{"cpu_fan": {Rpm: 1250}},
+		},
+	}
+	var out bytes.Buffer
+
+	// WHEN
+	err := WriteCsv(&out, samples)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "time,sensor:cpu,sensor:gpu,fan:cpu_fan:pwm,fan:cpu_fan:rpm\n"+
+		"2024-01-01T12:00:00Z,45000,50500.5,128,1200\n"+
+		"2024-01-01T12:00:01Z,46000,,,1250\n", out.String())
+}
+
+func TestCollectSample_UsesControllerSnapshot(t *testing.T) {
+	// GIVEN
+	pwmPath := path.Join(t.TempDir(), "pwm")
+	err := os.WriteFile(pwmPath, []byte("100"), 0644)
+	assert.NoError(t, err)
+	fan := &fans.FileFan{
+		Config: configuration.FanConfig{
+			ID:   "cpu",
+			File: &configuration.FileFanConfig{Path: pwmPath},
+		},
+	}
+	fans.SetFanMap(map[string]fans.Fan{fan.GetId(): fan})
+	controller.SetFanControllerMap(map[string]controller.FanController{
+		fan.GetId(): controller.NewFanController(nil, fan, *util.NewPidLoop(0.03, 0.002, 0.0005), time.Second),
+	})
+	defer func() {
+		fans.SetFanMap(map[string]fans.Fan{})
+		controller.SetFanControllerMap(map[string]controller.FanController{})
+	}()
+
+	// WHEN
+	sample := collectSample()
+
+	// THEN
+	// the controller didn't update the fan yet, the fan itself is not read
+	assert.Contains(t, sample.Fans, "cpu")
+	assert.Nil(t, sample.Fans["cpu"].Pwm)
+	assert.Equal(t, 0.0, sample.Fans["cpu"].Rpm)
+}
@@ -0,0 +1,122 @@
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadTrace reads samples from a file written by "fan2go history export",
+// files ending in ".json" are read as JSON, all others as CSV
+func ReadTrace(path string) ([]Sample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var samples []Sample
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.NewDecoder(file).Decode(&samples)
+	} else {
+		samples, err = ReadCsv(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return samples, nil
+}
+
+// ReadCsv reads samples in the format written by WriteCsv
+func ReadCsv(r io.Reader) ([]Sample, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) <= 0 || header[0] != "time" {
+		return nil, fmt.Errorf("first column must be 'time'")
+	}
+
+	var samples []Sample
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sampleTime, err := time.Parse(time.RFC3339Nano, row[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid time '%s': %v", row[0], err)
+		}
+		sample := Sample{
+			Time:    sampleTime,
+			Sensors: map[string]float64{},
+			Fans:    map[string]FanSample{},
+		}
+		for idx, column := range header[1:] {
+			value := row[idx+1]
+			if len(value) <= 0 {
+				continue
+			}
+			err = parseCsvValue(&sample, column, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// parseCsvValue stores the value of the given column ("sensor:<id>", "fan:<id>:pwm" or "fan:<id>:rpm") in the sample
+func parseCsvValue(sample *Sample, column string, value string) error {
+	if strings.HasPrefix(column, "sensor:") {
+		id := strings.TrimPrefix(column, "sensor:")
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value '%s' of %s: %v", value, column, err)
+		}
+		sample.Sensors[id] = parsed
+		return nil
+	}
+
+	if strings.HasPrefix(column, "fan:") {
+		rest := strings.TrimPrefix(column, "fan:")
+		separator := strings.LastIndex(rest, ":")
+		if separator < 0 {
+			return fmt.Errorf("invalid column '%s'", column)
+		}
+		id, field := rest[:separator], rest[separator+1:]
+		fan := sample.Fans[id]
+		switch field {
+		case "pwm":
+			pwm, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid value '%s' of %s: %v", value, column, err)
+			}
+			fan.Pwm = &pwm
+		case "rpm":
+			rpm, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value '%s' of %s: %v", value, column, err)
+			}
+			fan.Rpm = rpm
+		default:
+			return fmt.Errorf("invalid column '%s'", column)
+		}
+		sample.Fans[id] = fan
+		return nil
+	}
+
+	return fmt.Errorf("invalid column '%s'", column)
+}
@@ -0,0 +1,49 @@
+package history
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCsvReadsWrittenSamples(t *testing.T) {
+	// GIVEN
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	pwm := 128
+	samples := []Sample{
+		{
+			Time:    start,
+			Sensors: map[string]float64{"cpu": 45000, "gpu": 50500.5},
+			Fans:    map[string]FanSample{"cpu_fan": {Pwm: &pwm, Rpm: 1200}},
+		},
+		{
+			Time:    start.Add(time.Second),
+			Sensors: map[string]float64{"cpu": 46000},
+			Fans:    map[string]FanSample{"cpu_fan": {Rpm: 1250}},
+		},
+	}
+	var out bytes.Buffer
+	err := WriteCsv(&out, samples)
+	assert.NoError(t, err)
+
+	// WHEN
+	result, err := ReadCsv(&out)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, samples, result)
+}
+
+func TestReadCsvInvalidColumn(t *testing.T) {
+	// GIVEN
+	data := "time,temperature\n2024-01-01T12:00:00Z,45000\n"
+
+	// WHEN
+	_, err := ReadCsv(strings.NewReader(data))
+
+	// THEN
+	assert.EqualError(t, err, "invalid column 'temperature'")
+}
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// hotplugDelay is the time to wait for further device events before re-applying the configuration,
+	// since a single device usually produces multiple events
+	hotplugDelay = 1 * time.Second
+
+	ueventActionAdd    = "add"
+	ueventActionRemove = "remove"
+
+	// ueventKernelGroup is the netlink multicast group of uevents sent by the kernel
+	ueventKernelGroup = 1
+	ueventBufferSize  = 16 * 1024
+)
+
+// hotplugSubsystems are the subsystems of devices that may provide sensors or fans
+var hotplugSubsystems = []string{"hwmon", "hidraw", "drm", "nvme"}
+
+type uevent struct {
+	Action    string
+	DevPath   string
+	Subsystem string
+}
+
+// watchForHotplug re-applies the current configuration whenever a device providing sensors or fans
+// is added or removed, so devices that appear after startup are picked up and fan controllers of
+// devices that were removed and added again are restarted
+func watchForHotplug(ctx context.Context, objects *daemonObjects) error {
+	events, err := listenUevents(ctx)
+	if err != nil {
+		return err
+	}
+
+	delay := time.NewTimer(hotplugDelay)
+	delay.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if event.Action != ueventActionAdd && event.Action != ueventActionRemove {
+				continue
+			}
+			if !slices.Contains(hotplugSubsystems, event.Subsystem) {
+				continue
+			}
+			ui.Debug("Device event: %s %s (%s)", event.Action, event.DevPath, event.Subsystem)
+			delay.Reset(hotplugDelay)
+		case <-delay.C:
+			ui.Info("Devices changed, re-applying configuration...")
+			err := objects.apply(configuration.CopyObjects())
+			if err != nil {
+				ui.Warning("Unable to apply configuration after device change: %v", err)
+			}
+		}
+	}
+}
+
+// listenUevents subscribes to the uevents of the kernel, the returned channel is closed
+// once the given context is done
+func listenUevents(ctx context.Context) (<-chan uevent, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Bind(fd, &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: ueventKernelGroup,
+	})
+	if err != nil {
+		_ = syscall.Close(fd)
+		return nil, err
+	}
+	// a non-blocking file is handled by the runtime poller, so closing it interrupts pending reads
+	err = syscall.SetNonblock(fd, true)
+	if err != nil {
+		_ = syscall.Close(fd)
+		return nil, err
+	}
+	file := os.NewFile(uintptr(fd), "uevent")
+
+	go func() {
+		<-ctx.Done()
+		_ = file.Close()
+	}()
+
+	events := make(chan uevent, 100)
+	go func() {
+		defer close(events)
+		buffer := make([]byte, ueventBufferSize)
+		for {
+			n, err := file.Read(buffer)
+			if err != nil {
+				if ctx.Err() == nil {
+					ui.Warning("Error reading device events: %v", err)
+				}
+				return
+			}
+			if event, ok := parseUevent(buffer[:n]); ok {
+				events <- event
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// parseUevent parses a kernel uevent message of the form "action@devpath\0KEY=value\0..."
+func parseUevent(message []byte) (uevent, bool) {
+	fields := strings.Split(string(message), "\x00")
+	if len(fields) <= 0 || !strings.Contains(fields[0], "@") {
+		// not a kernel uevent, f.ex. a message of udevd
+		return uevent{}, false
+	}
+
+	event := uevent{}
+	for _, field := range fields[1:] {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "ACTION":
+			event.Action = value
+		case "DEVPATH":
+			event.DevPath = value
+		case "SUBSYSTEM":
+			event.Subsystem = value
+		}
+	}
+
+	return event, len(event.Action) > 0
+}
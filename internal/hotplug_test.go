@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUevent(t *testing.T) {
+	// GIVEN
+	message := strings.Join([]string{
+		"add@/devices/platform/nct6775.656/hwmon/hwmon3",
+		"ACTION=add",
+		"DEVPATH=/devices/platform/nct6775.656/hwmon/hwmon3",
+		"SUBSYSTEM=hwmon",
+		"SEQNUM=4711",
+	}, "\x00")
+
+	// WHEN
+	event, ok := parseUevent([]byte(message))
+
+	// THEN
+	assert.True(t, ok)
+	assert.Equal(t, "add", event.Action)
+	assert.Equal(t, "/devices/platform/nct6775.656/hwmon/hwmon3", event.DevPath)
+	assert.Equal(t, "hwmon", event.Subsystem)
+}
+
+func TestParseUeventIgnoresUdevMessages(t *testing.T) {
+	// GIVEN
+	message := "libudev\x00ACTION=add\x00SUBSYSTEM=hwmon"
+
+	// WHEN
+	_, ok := parseUevent([]byte(message))
+
+	// THEN
+	assert.False(t, ok)
+}
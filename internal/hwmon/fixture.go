@@ -0,0 +1,49 @@
+package hwmon
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// FixtureDevice describes a hwmon device of a fake sysfs tree written by WriteFixture
+type FixtureDevice struct {
+	// Name is the content of the "name" file of the device
+	Name string
+	// Fans maps from channel -> rpm, a pwm and pwm_enable file is created for each fan
+	Fans map[int]int
+	// Pwms maps from channel -> initial pwm value, defaults to 255
+	Pwms map[int]int
+	// Temps maps from channel -> temperature in milli-degrees
+	Temps map[int]int
+	// Labels maps from feature (f.ex. "fan1" or "temp2") -> label
+	Labels map[string]string
+}
+
+// WriteFixture writes a fake sysfs tree containing the given hwmon devices to root, which can be used
+// with the "sysfs" hwmon backend, f.ex. to run the controller against fixtures in tests
+func WriteFixture(root string, devices []FixtureDevice) error {
+	files := map[string]string{}
+	for idx, device := range devices {
+		devicePath := path.Join("class", "hwmon", fmt.Sprintf("hwmon%d", idx))
+		files[path.Join(devicePath, "name")] = device.Name
+		for channel, rpm := range device.Fans {
+			pwm := 255
+			if value, ok := device.Pwms[channel]; ok {
+				pwm = value
+			}
+			files[path.Join(devicePath, fmt.Sprintf("fan%d_input", channel))] = strconv.Itoa(rpm)
+			files[path.Join(devicePath, fmt.Sprintf("pwm%d", channel))] = strconv.Itoa(pwm)
+			files[path.Join(devicePath, fmt.Sprintf("pwm%d_enable", channel))] = "2"
+		}
+		for channel, temp := range device.Temps {
+			files[path.Join(devicePath, fmt.Sprintf("temp%d_input", channel))] = strconv.Itoa(temp)
+		}
+		for feature, label := range device.Labels {
+			files[path.Join(devicePath, feature+"_label")] = label
+		}
+	}
+	return util.WriteSysfsFixture(root, files)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/markusressel/fan2go/internal/ui"
 	"io/ioutil"
+	"os"
 	"path"
 	"path/filepath"
 	"regexp"
@@ -24,12 +25,17 @@ const (
 	BusTypeScsi    = 8
 )
 
+// logger is used for all messages of hwmon device detection
+var logger = ui.ForSubsystem(ui.SubsystemHwmon)
+
 type HwMonController struct {
 	Name     string
 	DType    string
 	Modalias string
-	Platform string
-	Path     string
+	// PciAddress is the address of the PCI device of the controller, f.ex. "0000:2f:00.0", if any
+	PciAddress string
+	Platform   string
+	Path       string
 
 	// Fans (can be matched either by enumeration index or channel number)
 	Fans []fans.HwMonFan
@@ -37,7 +43,15 @@ type HwMonController struct {
 	Sensors map[int]*sensors.HwmonSensor
 }
 
+// GetChips returns all hwmon devices with fans or temperature sensors, detected using the configured backend
 func GetChips() []*HwMonController {
+	if configuration.CurrentConfig.HwMonBackend == configuration.HwMonBackendSysfs {
+		return getChipsFromSysfs(configuration.CurrentConfig.SysfsRoot)
+	}
+	return getChipsFromLibsensors()
+}
+
+func getChipsFromLibsensors() []*HwMonController {
 	gosensors.Init()
 	defer gosensors.Cleanup()
 	chips := gosensors.GetDetectedChips()
@@ -63,14 +77,16 @@ func GetChips() []*HwMonController {
 		}
 
 		c := &HwMonController{
-			Name:     identifier,
-			DType:    dType,
-			Modalias: modalias,
-			Platform: platform,
-			Path:     chip.Path,
-			Fans:     fanSlice,
-			Sensors:  sensorMap,
+			Name:       identifier,
+			DType:      dType,
+			Modalias:   modalias,
+			PciAddress: getPciAddress(chip.Path),
+			Platform:   platform,
+			Path:       chip.Path,
+			Fans:       fanSlice,
+			Sensors:    sensorMap,
 		}
+		logger.Debug("Found hwmon controller %s (platform %s) at %s with %d fans and %d sensors", identifier, platform, chip.Path, len(fanSlice), len(sensorMap))
 		list = append(list, c)
 	}
 
@@ -164,7 +180,7 @@ func GetFans(chip gosensors.Chip) []fans.HwMonFan {
 			var channel int
 			_, err := fmt.Sscanf(feature.Name, "fan%d", &channel)
 			if err != nil {
-				ui.Warning("No channel found for '%s', ignoring.", feature.Name)
+				logger.Warning("No channel found for '%s', ignoring.", feature.Name)
 				continue
 			}
 
@@ -283,37 +299,139 @@ func findPlatform(devicePath string) string {
 }
 
 func UpdateFanConfigFromHwMonControllers(controllers []*HwMonController, config *configuration.FanConfig) error {
+	if config.Group != nil {
+		for idx := range config.Group.Members {
+			member := &config.Group.Members[idx]
+			if member.HwMon == nil && member.DellSmm == nil {
+				continue
+			}
+			if err := UpdateFanConfigFromHwMonControllers(controllers, member); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	hwMonConfig := config.HwMon
+	if config.DellSmm != nil {
+		hwMonConfig = &config.DellSmm.HwMonFanConfig
+		if len(hwMonConfig.Platform) <= 0 {
+			hwMonConfig.Platform = "dell_smm"
+		}
+	}
+
+	match := DeviceMatch{Platform: hwMonConfig.Platform, Name: hwMonConfig.Name, Modalias: hwMonConfig.Modalias, PciAddress: hwMonConfig.PciAddress}
 	for _, controller := range controllers {
-		matched, err := regexp.MatchString("(?i)"+config.HwMon.Platform, controller.Platform)
+		matched, err := match.Matches(controller)
 		if err != nil {
-			return fmt.Errorf("failed to match platform regex of %s (%s) against controller platform %s", config.ID, config.HwMon.Platform, controller.Platform)
+			return fmt.Errorf("fan %s: %v", config.ID, err)
 		}
 		if !matched {
 			continue
 		}
 		for _, fan := range controller.Fans {
 			controllerConfig := fan.Config.HwMon
-			if config.HwMon.Index > 0 && controllerConfig.Index != config.HwMon.Index {
+			if hwMonConfig.Index > 0 && controllerConfig.Index != hwMonConfig.Index {
 				continue
 			}
-			if config.HwMon.RpmChannel > 0 && controllerConfig.RpmChannel != config.HwMon.RpmChannel {
+			if hwMonConfig.RpmChannel > 0 && controllerConfig.RpmChannel != hwMonConfig.RpmChannel {
 				continue
 			}
-			config.HwMon.Index = controllerConfig.Index
-			config.HwMon.RpmChannel = controllerConfig.RpmChannel
-			config.HwMon.SysfsPath = controllerConfig.SysfsPath
-			if config.HwMon.PwmChannel == 0 {
-				config.HwMon.PwmChannel = controllerConfig.PwmChannel
+			hwMonConfig.Index = controllerConfig.Index
+			hwMonConfig.RpmChannel = controllerConfig.RpmChannel
+			hwMonConfig.SysfsPath = controllerConfig.SysfsPath
+			if hwMonConfig.PwmChannel == 0 {
+				hwMonConfig.PwmChannel = controllerConfig.PwmChannel
 			}
-			setFanConfigPaths(config.HwMon)
+			setFanConfigPaths(hwMonConfig)
 			return nil
 		}
 	}
 	return fmt.Errorf("no hwmon fan matched fan config: %+v", config)
 }
 
+// UpdateAmdGpuSensorConfigFromHwMonControllers resolves the temp inputs of an amdgpu sensor config
+// by matching the labels of the sensors of the first matching controller,
+// or its busy input if the sensor reads the utilization of the GPU
+func UpdateAmdGpuSensorConfigFromHwMonControllers(controllers []*HwMonController, config *configuration.SensorConfig) error {
+	platform := config.AmdGpu.Platform
+	if len(platform) <= 0 {
+		platform = "amdgpu"
+	}
+	match := DeviceMatch{Platform: platform}
+
+	if config.AmdGpu.Type == configuration.AmdGpuSensorTypeUtilization {
+		for _, controller := range controllers {
+			matched, err := match.Matches(controller)
+			if err != nil {
+				return fmt.Errorf("sensor %s: %v", config.ID, err)
+			}
+			if !matched {
+				continue
+			}
+			if input := findGpuBusyInput(controller.Path); len(input) > 0 {
+				config.AmdGpu.BusyInput = input
+				return nil
+			}
+		}
+		return fmt.Errorf("no amdgpu controller with gpu_busy_percent found for sensor: %s", config.ID)
+	}
+
+	temps := config.AmdGpu.Temps
+	if len(temps) <= 0 {
+		temps = []string{
+			configuration.AmdGpuSensorTempEdge,
+			configuration.AmdGpuSensorTempJunction,
+			configuration.AmdGpuSensorTempMem,
+		}
+	}
+
+	for _, controller := range controllers {
+		matched, err := match.Matches(controller)
+		if err != nil {
+			return fmt.Errorf("sensor %s: %v", config.ID, err)
+		}
+		if !matched {
+			continue
+		}
+
+		var inputs []string
+		for _, temp := range temps {
+			for _, sensor := range controller.Sensors {
+				if sensor.Label == temp {
+					inputs = append(inputs, sensor.Input)
+					break
+				}
+			}
+		}
+		if len(inputs) <= 0 {
+			continue
+		}
+
+		config.AmdGpu.TempInputs = inputs
+		return nil
+	}
+	return fmt.Errorf("no amdgpu controller with temps %v found for sensor: %s", temps, config.ID)
+}
+
+// findGpuBusyInput returns the gpu_busy_percent file of the PCI device of the given hwmon controller,
+// an empty string if there is none
+func findGpuBusyInput(controllerPath string) string {
+	// the file belongs to the device, not to the hwmon directory itself
+	for _, candidate := range []string{
+		path.Join(controllerPath, "device", "gpu_busy_percent"),
+		path.Join(controllerPath, "gpu_busy_percent"),
+	} {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
 func setFanConfigPaths(config *configuration.HwMonFanConfig) {
 	config.RpmInputPath = path.Join(config.SysfsPath, fmt.Sprintf("fan%d_input", config.RpmChannel))
 	config.PwmPath = path.Join(config.SysfsPath, fmt.Sprintf("pwm%d", config.PwmChannel))
 	config.PwmEnablePath = path.Join(config.SysfsPath, fmt.Sprintf("pwm%d_enable", config.PwmChannel))
+	config.PwmModePath = path.Join(config.SysfsPath, fmt.Sprintf("pwm%d_mode", config.PwmChannel))
 }
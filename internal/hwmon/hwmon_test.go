@@ -2,6 +2,8 @@ package hwmon
 
 import (
 	"fmt"
+	"os"
+	"path"
 	"testing"
 
 	"github.com/markusressel/fan2go/internal/configuration"
@@ -109,6 +111,7 @@ func TestUpdateFanConfigFromHwMonControllers(t *testing.T) {
 			RpmInputPath:  "/sys/hwmon1/fan2_input",
 			PwmPath:       "/sys/hwmon1/pwm2",
 			PwmEnablePath: "/sys/hwmon1/pwm2_enable",
+			PwmModePath:   "/sys/hwmon1/pwm2_mode",
 		},
 	}, {
 		tn: "channel config",
@@ -131,6 +134,7 @@ func TestUpdateFanConfigFromHwMonControllers(t *testing.T) {
 			RpmInputPath:  "/sys/hwmon1/fan2_input",
 			PwmPath:       "/sys/hwmon1/pwm2",
 			PwmEnablePath: "/sys/hwmon1/pwm2_enable",
+			PwmModePath:   "/sys/hwmon1/pwm2_mode",
 		},
 	}, {
 		tn: "pwm channel config",
@@ -154,6 +158,7 @@ func TestUpdateFanConfigFromHwMonControllers(t *testing.T) {
 			RpmInputPath:  "/sys/hwmon1/fan2_input",
 			PwmPath:       "/sys/hwmon1/pwm3",
 			PwmEnablePath: "/sys/hwmon1/pwm3_enable",
+			PwmModePath:   "/sys/hwmon1/pwm3_mode",
 		},
 	}, {
 		tn: "no hwmon fans",
@@ -231,3 +236,55 @@ func TestUpdateFanConfigFromHwMonControllers(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateAmdGpuSensorConfigFromHwMonControllersUtilization(t *testing.T) {
+	// GIVEN
+	controllerPath := t.TempDir()
+	_ = os.MkdirAll(path.Join(controllerPath, "device"), 0755)
+	_ = os.WriteFile(path.Join(controllerPath, "device", "gpu_busy_percent"), []byte("12\n"), 0644)
+	controllers := []*HwMonController{
+		{
+			Platform: "nct6798",
+			Path:     t.TempDir(),
+		},
+		{
+			Platform: "amdgpu",
+			Path:     controllerPath,
+		},
+	}
+	config := configuration.SensorConfig{
+		ID: "gpu",
+		AmdGpu: &configuration.AmdGpuSensorConfig{
+			Type: configuration.AmdGpuSensorTypeUtilization,
+		},
+	}
+
+	// WHEN
+	err := UpdateAmdGpuSensorConfigFromHwMonControllers(controllers, &config)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(controllerPath, "device", "gpu_busy_percent"), config.AmdGpu.BusyInput)
+}
+
+func TestUpdateAmdGpuSensorConfigFromHwMonControllersUtilizationMissing(t *testing.T) {
+	// GIVEN
+	controllers := []*HwMonController{
+		{
+			Platform: "amdgpu",
+			Path:     t.TempDir(),
+		},
+	}
+	config := configuration.SensorConfig{
+		ID: "gpu",
+		AmdGpu: &configuration.AmdGpuSensorConfig{
+			Type: configuration.AmdGpuSensorTypeUtilization,
+		},
+	}
+
+	// WHEN
+	err := UpdateAmdGpuSensorConfigFromHwMonControllers(controllers, &config)
+
+	// THEN
+	assert.EqualError(t, err, "no amdgpu controller with gpu_busy_percent found for sensor: gpu")
+}
@@ -0,0 +1,109 @@
+package hwmon
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+// DeviceMatch selects hwmon controllers, all non-empty patterns must match.
+// Patterns are case-insensitive regular expressions, which match anywhere in the value,
+// or glob patterns, which match the whole value (see matchPattern).
+type DeviceMatch struct {
+	// Platform is matched against the platform of a controller
+	Platform string
+	// Name is matched against the name of a controller, f.ex. "nct6798-isa-0290"
+	Name string
+	// Modalias is matched against the modalias of the device of a controller, f.ex. "pci:v00001002d0000731F..."
+	Modalias string
+	// PciAddress is compared to the PCI address of the device of a controller, f.ex. "pci-0000:2f:00.0",
+	// the "pci-" prefix is optional
+	PciAddress string
+}
+
+// Matches returns true if all patterns of this match apply to the given controller
+func (m DeviceMatch) Matches(controller *HwMonController) (bool, error) {
+	criteria := []struct {
+		field   string
+		pattern string
+		value   string
+	}{
+		{"platform", m.Platform, controller.Platform},
+		{"name", m.Name, controller.Name},
+		{"modalias", m.Modalias, controller.Modalias},
+	}
+	for _, c := range criteria {
+		if len(c.pattern) <= 0 {
+			continue
+		}
+		matched, err := matchPattern(c.pattern, c.value)
+		if err != nil {
+			return false, fmt.Errorf("failed to match %s pattern (%s) against controller %s %s", c.field, c.pattern, c.field, c.value)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	if len(m.PciAddress) > 0 && configuration.NormalizePciAddress(m.PciAddress) != controller.PciAddress {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (m DeviceMatch) String() string {
+	var parts []string
+	if len(m.Platform) > 0 {
+		parts = append(parts, fmt.Sprintf("platform '%s'", m.Platform))
+	}
+	if len(m.Name) > 0 {
+		parts = append(parts, fmt.Sprintf("name '%s'", m.Name))
+	}
+	if len(m.Modalias) > 0 {
+		parts = append(parts, fmt.Sprintf("modalias '%s'", m.Modalias))
+	}
+	if len(m.PciAddress) > 0 {
+		parts = append(parts, fmt.Sprintf("PCI address '%s'", m.PciAddress))
+	}
+	if len(parts) <= 0 {
+		return "any device"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// matchPattern returns true if the given value matches the pattern either as a glob, f.ex. "nct6798-*",
+// or as a regular expression. Since suffixes like "-isa-0290" may change between kernel versions,
+// globs are a simple way to match the stable part of a value, and most globs are no valid regex anyway.
+// Both are case-insensitive, an error is only returned if the pattern is neither a valid glob nor a regex.
+func matchPattern(pattern string, value string) (bool, error) {
+	globMatched, globErr := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+	if globErr == nil && globMatched {
+		return true, nil
+	}
+	regexMatched, regexErr := regexp.MatchString("(?i)"+pattern, value)
+	if regexErr == nil {
+		return regexMatched, nil
+	}
+	if globErr == nil {
+		return false, nil
+	}
+	return false, regexErr
+}
+
+// getPciAddress returns the address of the PCI device closest to the given device
+// in the device tree, or an empty string if it isn't connected via PCI
+func getPciAddress(devicePath string) string {
+	resolved, err := filepath.EvalSymlinks(devicePath)
+	if err != nil {
+		resolved = devicePath
+	}
+	for dir := resolved; dir != "/" && dir != "."; dir = filepath.Dir(dir) {
+		if name := filepath.Base(dir); configuration.IsValidPciAddress(name) {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package hwmon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceMatch_Matches(t *testing.T) {
+	controller := &HwMonController{
+		Name:     "amdgpu-pci-0300",
+		Platform: "amdgpu-pci-0300",
+		Modalias: "pci:v00001002d0000731Fsv00001DA2sd0000E409bc03sc00i00",
+	}
+
+	var tests = []struct {
+		tn    string
+		match DeviceMatch
+		want  bool
+	}{
+		{tn: "empty", match: DeviceMatch{}, want: true},
+		{tn: "platform", match: DeviceMatch{Platform: "amdgpu"}, want: true},
+		{tn: "name", match: DeviceMatch{Name: "^AMDGPU-pci"}, want: true},
+		{tn: "modalias", match: DeviceMatch{Modalias: "^pci:v00001002d0000731F"}, want: true},
+		{tn: "all", match: DeviceMatch{Platform: "amdgpu", Name: "amdgpu", Modalias: "v00001002"}, want: true},
+		{tn: "name mismatch", match: DeviceMatch{Platform: "amdgpu", Name: "nct6798"}, want: false},
+		{tn: "modalias mismatch", match: DeviceMatch{Modalias: "^usb:"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tn, func(t *testing.T) {
+			// WHEN
+			matched, err := tt.match.Matches(controller)
+
+			// THEN
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, matched)
+		})
+	}
+}
+
+func TestDeviceMatch_InvalidPattern(t *testing.T) {
+	// GIVEN
+	match := DeviceMatch{Modalias: "pci:["}
+
+	// WHEN
+	_, err := match.Matches(&HwMonController{})
+
+	// THEN
+	assert.ErrorContains(t, err, "modalias")
+}
+
+func TestDeviceMatch_PciAddress(t *testing.T) {
+	// GIVEN
+	first := &HwMonController{Name: "amdgpu-pci-2f00", PciAddress: "0000:2f:00.0"}
+	second := &HwMonController{Name: "amdgpu-pci-3000", PciAddress: "0000:30:00.0"}
+	match := DeviceMatch{Name: "amdgpu", PciAddress: "pci-0000:2F:00.0"}
+
+	// WHEN
+	firstMatched, err1 := match.Matches(first)
+	secondMatched, err2 := match.Matches(second)
+
+	// THEN
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.True(t, firstMatched)
+	assert.False(t, secondMatched)
+}
+
+func TestGetPciAddress(t *testing.T) {
+	// GIVEN
+	devicePath := "/sys/devices/pci0000:00/0000:00:01.1/0000:2d:00.0/0000:2e:00.0/0000:2f:00.0/hwmon/hwmon3"
+
+	// WHEN
+	address := getPciAddress(devicePath)
+
+	// THEN
+	assert.Equal(t, "0000:2f:00.0", address)
+}
+
+func TestGetPciAddressPlatformDevice(t *testing.T) {
+	// GIVEN
+	devicePath := "/sys/devices/platform/nct6775.656/hwmon/hwmon2"
+
+	// WHEN
+	address := getPciAddress(devicePath)
+
+	// THEN
+	assert.Equal(t, "", address)
+}
+
+func TestMatchPattern(t *testing.T) {
+	var tests = []struct {
+		tn      string
+		pattern string
+		value   string
+		want    bool
+	}{
+		{tn: "glob suffix", pattern: "nct6798-*", value: "nct6798-isa-0290", want: true},
+		{tn: "glob infix", pattern: "nct67*-isa-*", value: "nct6798-isa-0290", want: true},
+		{tn: "glob no regex", pattern: "*-isa-*", value: "NCT6798-ISA-0290", want: true},
+		{tn: "glob mismatch", pattern: "it87*-isa-*", value: "nct6798-isa-0290", want: false},
+		{tn: "regex", pattern: "^nct67\\d+-isa", value: "nct6798-isa-0290", want: true},
+		{tn: "regex partial", pattern: "coretemp", value: "coretemp-isa-0000", want: true},
+		{tn: "regex mismatch", pattern: "^it87", value: "nct6798-isa-0290", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tn, func(t *testing.T) {
+			// WHEN
+			matched, err := matchPattern(tt.pattern, tt.value)
+
+			// THEN
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, matched)
+		})
+	}
+}
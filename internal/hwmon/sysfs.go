@@ -0,0 +1,175 @@
+package hwmon
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/md14454/gosensors"
+)
+
+var (
+	sysfsFanInputPattern  = regexp.MustCompile(`^fan(\d+)_input$`)
+	sysfsTempInputPattern = regexp.MustCompile(`^temp(\d+)_input$`)
+)
+
+// getChipsFromSysfs detects hwmon devices by scanning <root>/class/hwmon directly instead of using
+// libsensors, which allows using a fake sysfs tree, f.ex. a directory with test fixtures
+func getChipsFromSysfs(root string) []*HwMonController {
+	hwmonDir := path.Join(root, "class", "hwmon")
+	entries, err := os.ReadDir(hwmonDir)
+	if err != nil {
+		logger.Warning("Unable to read hwmon devices from %s: %v", hwmonDir, err)
+		return nil
+	}
+
+	var list []*HwMonController
+	for _, entry := range entries {
+		devicePath := path.Join(hwmonDir, entry.Name())
+		// on a real sysfs, the entries are symlinks to the actual device path
+		if resolved, err := filepath.EvalSymlinks(devicePath); err == nil {
+			devicePath = resolved
+		}
+
+		identifier := computeIdentifier(gosensors.Chip{Path: devicePath})
+		platform := findPlatform(devicePath)
+		if len(platform) <= 0 {
+			platform = identifier
+		}
+
+		fanSlice := getSysfsFans(devicePath)
+		sensorMap := getSysfsTempSensors(devicePath)
+		if len(fanSlice) <= 0 && len(sensorMap) <= 0 {
+			continue
+		}
+
+		c := &HwMonController{
+			Name:       identifier,
+			DType:      getDeviceType(devicePath),
+			Modalias:   getDeviceModalias(devicePath),
+			PciAddress: getPciAddress(devicePath),
+			Platform:   platform,
+			Path:       devicePath,
+			Fans:       fanSlice,
+			Sensors:    sensorMap,
+		}
+		logger.Debug("Found hwmon controller %s (platform %s) at %s with %d fans and %d sensors", identifier, platform, devicePath, len(fanSlice), len(sensorMap))
+		list = append(list, c)
+	}
+	return list
+}
+
+// getSysfsFans returns all fans of the given device, equivalent to GetFans
+func getSysfsFans(devicePath string) []fans.HwMonFan {
+	var result = []fans.HwMonFan{}
+
+	for _, channel := range findSysfsChannels(devicePath, sysfsFanInputPattern) {
+		featureName := fmt.Sprintf("fan%d", channel)
+
+		rpmAverage, _ := readSysfsValue(devicePath, featureName+"_input")
+
+		max := fans.MaxPwmValue
+		if value, err := readSysfsValue(devicePath, featureName+"_max"); err == nil {
+			max = int(value)
+		}
+		min := fans.MinPwmValue
+		if value, err := readSysfsValue(devicePath, featureName+"_min"); err == nil {
+			min = int(value)
+		}
+
+		label := getLabel(devicePath, featureName)
+
+		fan := fans.HwMonFan{
+			Config: configuration.FanConfig{
+				ID:     label,
+				MinPwm: &min,
+				MaxPwm: &max,
+				HwMon: &configuration.HwMonFanConfig{
+					Index:      len(result) + 1,
+					RpmChannel: channel,
+					PwmChannel: channel,
+					SysfsPath:  devicePath,
+				},
+			},
+			Label:        label,
+			Index:        len(result) + 1,
+			RpmMovingAvg: rpmAverage,
+		}
+		setFanConfigPaths(fan.Config.HwMon)
+
+		result = append(result, fan)
+	}
+	return result
+}
+
+// getSysfsTempSensors returns all temperature sensors of the given device, equivalent to GetTempSensors.
+// Values are scaled from milli-degrees to degrees, the same way libsensors does.
+func getSysfsTempSensors(devicePath string) map[int]*sensors.HwmonSensor {
+	result := map[int]*sensors.HwmonSensor{}
+
+	currentOutputIndex := 0
+	for _, channel := range findSysfsChannels(devicePath, sysfsTempInputPattern) {
+		currentOutputIndex++
+		featureName := fmt.Sprintf("temp%d", channel)
+
+		value, _ := readSysfsValue(devicePath, featureName+"_input")
+
+		max := -1
+		if maxValue, err := readSysfsValue(devicePath, featureName+"_max"); err == nil {
+			max = int(maxValue / 1000)
+		}
+		min := -1
+		if minValue, err := readSysfsValue(devicePath, featureName+"_min"); err == nil {
+			min = int(minValue / 1000)
+		}
+
+		result[currentOutputIndex] = &sensors.HwmonSensor{
+			Label:     getLabel(devicePath, featureName),
+			Index:     currentOutputIndex,
+			Input:     path.Join(devicePath, featureName+"_input"),
+			Max:       max,
+			Min:       min,
+			MovingAvg: value / 1000,
+		}
+	}
+	return result
+}
+
+// findSysfsChannels returns the sorted channel numbers of all files in the given directory matching the pattern
+func findSysfsChannels(devicePath string, pattern *regexp.Regexp) []int {
+	entries, err := os.ReadDir(devicePath)
+	if err != nil {
+		return nil
+	}
+
+	var channels []int
+	for _, entry := range entries {
+		match := pattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		channel, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+	sort.Ints(channels)
+	return channels
+}
+
+func readSysfsValue(devicePath string, name string) (float64, error) {
+	content, err := os.ReadFile(path.Join(devicePath, name))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(content)), 64)
+}
@@ -0,0 +1,110 @@
+package hwmon
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChipsFromSysfsFixture(t *testing.T) {
+	// GIVEN
+	root := t.TempDir()
+	err := WriteFixture(root, []FixtureDevice{
+		{
+			Name:   "nct6798",
+			Fans:   map[int]int{1: 1200, 2: 800},
+			Temps:  map[int]int{1: 45000},
+			Labels: map[string]string{"temp1": "SYSTIN"},
+		},
+		{
+			// devices without fans and sensors are ignored
+			Name: "acpitz",
+		},
+	})
+	assert.NoError(t, err)
+
+	// WHEN
+	controllers := getChipsFromSysfs(root)
+
+	// THEN
+	assert.Len(t, controllers, 1)
+	controller := controllers[0]
+	assert.Equal(t, "nct6798", controller.Name)
+	assert.Equal(t, "nct6798", controller.Platform)
+	assert.Len(t, controller.Fans, 2)
+	assert.Equal(t, 2, controller.Fans[1].Config.HwMon.RpmChannel)
+	assert.Equal(t, 800.0, controller.Fans[1].RpmMovingAvg)
+	assert.Equal(t, "SYSTIN", controller.Sensors[1].Label)
+	assert.Equal(t, 45.0, controller.Sensors[1].MovingAvg)
+}
+
+func TestSysfsFixtureFanIsControllable(t *testing.T) {
+	// GIVEN
+	root := t.TempDir()
+	err := WriteFixture(root, []FixtureDevice{
+		{
+			Name: "nct6798",
+			Fans: map[int]int{1: 1200, 2: 800},
+			Pwms: map[int]int{2: 100},
+		},
+	})
+	assert.NoError(t, err)
+	controllers := getChipsFromSysfs(root)
+
+	config := configuration.FanConfig{
+		ID: "case_fan",
+		HwMon: &configuration.HwMonFanConfig{
+			Platform:   "nct6798",
+			RpmChannel: 2,
+		},
+	}
+
+	// WHEN
+	err = UpdateFanConfigFromHwMonControllers(controllers, &config)
+	assert.NoError(t, err)
+	fan, err := fans.NewFan(config)
+	assert.NoError(t, err)
+	initialPwm, _ := fan.GetPwm()
+	err = fan.SetPwm(150)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 100, initialPwm)
+	pwm, err := fan.GetPwm()
+	assert.NoError(t, err)
+	assert.Equal(t, 150, pwm)
+	rpm, err := fan.GetRpm()
+	assert.NoError(t, err)
+	assert.Equal(t, 800, rpm)
+}
+
+func TestGetChipsUsesConfiguredSysfsRoot(t *testing.T) {
+	// GIVEN
+	root := t.TempDir()
+	err := WriteFixture(root, []FixtureDevice{
+		{
+			Name:  "nct6798",
+			Fans:  map[int]int{1: 1200},
+			Temps: map[int]int{1: 45000},
+		},
+	})
+	assert.NoError(t, err)
+	configuration.CurrentConfig.HwMonBackend = configuration.HwMonBackendSysfs
+	configuration.CurrentConfig.SysfsRoot = root
+	defer func() {
+		configuration.CurrentConfig.HwMonBackend = ""
+		configuration.CurrentConfig.SysfsRoot = ""
+	}()
+
+	// WHEN
+	controllers := GetChips()
+
+	// THEN
+	assert.Len(t, controllers, 1)
+	assert.Equal(t, "nct6798", controllers[0].Name)
+	value, err := controllers[0].Sensors[1].GetValue()
+	assert.NoError(t, err)
+	assert.Equal(t, 45000.0, value)
+}
@@ -0,0 +1,102 @@
+package liquidctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+const (
+	DefaultExecutable = "/usr/bin/liquidctl"
+
+	timeout = 10 * time.Second
+)
+
+// Device is a device as reported by "liquidctl status --json"
+type Device struct {
+	Bus         string         `json:"bus"`
+	Address     string         `json:"address"`
+	Description string         `json:"description"`
+	Status      []StatusRecord `json:"status"`
+}
+
+// StatusRecord is a single value reported by a device, f.ex. "Liquid temperature"
+type StatusRecord struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	Unit  string      `json:"unit"`
+}
+
+// GetStatus returns the status of all devices matching the given filter (or all devices, if empty)
+func GetStatus(executable string, match string) ([]Device, error) {
+	args := []string{"--json"}
+	if len(match) > 0 {
+		args = append(args, "--match", match)
+	}
+	args = append(args, "status")
+
+	output, err := util.SafeCmdExecution(resolveExecutable(executable), args, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []Device
+	err = json.Unmarshal([]byte(output), &devices)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse liquidctl output: %v", err)
+	}
+	return devices, nil
+}
+
+// GetValue returns the value of the status record with the given key of the first device
+// matching the given filter
+func GetValue(executable string, match string, key string) (StatusRecord, error) {
+	devices, err := GetStatus(executable, match)
+	if err != nil {
+		return StatusRecord{}, err
+	}
+	for _, device := range devices {
+		for _, record := range device.Status {
+			if strings.EqualFold(record.Key, key) {
+				return record, nil
+			}
+		}
+	}
+	return StatusRecord{}, fmt.Errorf("no liquidctl device matching '%s' reports '%s'", match, key)
+}
+
+// SetSpeed sets the duty (0-100) of the given channel (f.ex. "fan1" or "pump")
+// of all devices matching the given filter
+func SetSpeed(executable string, match string, channel string, duty int) error {
+	var args []string
+	if len(match) > 0 {
+		args = append(args, "--match", match)
+	}
+	args = append(args, "set", channel, "speed", strconv.Itoa(duty))
+
+	_, err := util.SafeCmdExecution(resolveExecutable(executable), args, timeout)
+	return err
+}
+
+// Float returns the value of this record as a float
+func (record StatusRecord) Float() (float64, error) {
+	switch value := record.Value.(type) {
+	case float64:
+		return value, nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	default:
+		return 0, fmt.Errorf("value of '%s' is not a number: %v", record.Key, record.Value)
+	}
+}
+
+func resolveExecutable(executable string) string {
+	if len(executable) <= 0 {
+		return DefaultExecutable
+	}
+	return executable
+}
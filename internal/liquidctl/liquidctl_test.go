@@ -0,0 +1,27 @@
+package liquidctl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusRecord_Float(t *testing.T) {
+	// GIVEN
+	var devices []Device
+	output := `[{"bus": "hid", "address": "/dev/hidraw1", "description": "NZXT Kraken X (X53, X63 or X73)",
+		"status": [{"key": "Liquid temperature", "value": 31.4, "unit": "°C"},
+		           {"key": "Pump speed", "value": 2100, "unit": "rpm"}]}]`
+	_ = json.Unmarshal([]byte(output), &devices)
+
+	// WHEN
+	temp, err1 := devices[0].Status[0].Float()
+	rpm, err2 := devices[0].Status[1].Float()
+
+	// THEN
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, 31.4, temp)
+	assert.Equal(t, 2100.0, rpm)
+}
@@ -0,0 +1,110 @@
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"gopkg.in/yaml.v3"
+)
+
+var nonIdCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Config is the subset of the configuration written by migrations
+type Config struct {
+	Sensors []Sensor `yaml:"sensors"`
+	Curves  []Curve  `yaml:"curves"`
+	Fans    []Fan    `yaml:"fans"`
+}
+
+type Sensor struct {
+	ID     string       `yaml:"id"`
+	HwMon  *HwMonSensor `yaml:"hwmon,omitempty"`
+	File   *FileSensor  `yaml:"file,omitempty"`
+	Offset float64      `yaml:"offset,omitempty"`
+}
+
+type HwMonSensor struct {
+	Platform   string `yaml:"platform"`
+	PciAddress string `yaml:"pciAddress,omitempty"`
+	Index      int    `yaml:"index"`
+}
+
+type FileSensor struct {
+	Path string `yaml:"path"`
+}
+
+type Curve struct {
+	ID       string         `yaml:"id"`
+	Linear   *LinearCurve   `yaml:"linear,omitempty"`
+	Function *FunctionCurve `yaml:"function,omitempty"`
+}
+
+type LinearCurve struct {
+	Sensor string          `yaml:"sensor"`
+	Min    int             `yaml:"min,omitempty"`
+	Max    int             `yaml:"max,omitempty"`
+	Steps  map[int]float64 `yaml:"steps,omitempty"`
+	// Interpolation is one of: linear | step | monotone
+	Interpolation string  `yaml:"interpolation,omitempty"`
+	Hysteresis    float64 `yaml:"hysteresis,omitempty"`
+}
+
+type FunctionCurve struct {
+	Type   string   `yaml:"type"`
+	Curves []string `yaml:"curves"`
+}
+
+type Fan struct {
+	ID        string    `yaml:"id"`
+	HwMon     *HwMonFan `yaml:"hwmon,omitempty"`
+	File      *FileFan  `yaml:"file,omitempty"`
+	NeverStop bool      `yaml:"neverStop"`
+	MinPwm    *int      `yaml:"minPwm,omitempty"`
+	StartPwm  *int      `yaml:"startPwm,omitempty"`
+	MaxPwm    *int      `yaml:"maxPwm,omitempty"`
+	Curve     string    `yaml:"curve"`
+}
+
+type HwMonFan struct {
+	Platform   string `yaml:"platform"`
+	PciAddress string `yaml:"pciAddress,omitempty"`
+	RpmChannel int    `yaml:"rpmChannel"`
+	PwmChannel int    `yaml:"pwmChannel,omitempty"`
+}
+
+type FileFan struct {
+	Path string `yaml:"path"`
+}
+
+// Marshal returns the given config as yaml, preceded by the given header as a comment
+func Marshal(config Config, header string) ([]byte, error) {
+	var out bytes.Buffer
+	for _, line := range strings.Split(strings.TrimSpace(header), "\n") {
+		out.WriteString(fmt.Sprintf("# %s\n", line))
+	}
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// maximumCurve returns a curve with the given id computing the maximum of the given curves
+func maximumCurve(id string, curves []string) Curve {
+	return Curve{
+		ID: id,
+		Function: &FunctionCurve{
+			Type:   configuration.FunctionMaximum,
+			Curves: curves,
+		},
+	}
+}
+
+// toId returns the given name with all characters not allowed in ids replaced
+func toId(name string) string {
+	return strings.Trim(nonIdCharacters.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
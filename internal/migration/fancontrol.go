@@ -0,0 +1,280 @@
+package migration
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+var (
+	pwmFilePattern   = regexp.MustCompile(`^pwm(\d+)$`)
+	tempInputPattern = regexp.MustCompile(`^temp(\d+)_input$`)
+	fanInputPattern  = regexp.MustCompile(`^fan(\d+)_input$`)
+)
+
+// fancontrolIgnoredKeys are settings of fancontrol which have no equivalent in fan2go
+var fancontrolIgnoredKeys = []string{"INTERVAL", "AVERAGE"}
+
+// fancontrolConfig is a parsed fancontrol configuration file, as written by pwmconfig
+type fancontrolConfig struct {
+	// values maps from setting -> key -> value, f.ex. MINTEMP -> hwmon0/pwm1 -> 40
+	values map[string]map[string]string
+	// raw contains the unparsed value of all settings
+	raw map[string]string
+}
+
+// FromFancontrol converts the given fancontrol configuration (/etc/fancontrol) to a fan2go configuration.
+// The returned warnings describe settings which couldn't be converted.
+func FromFancontrol(r io.Reader) (Config, []string, error) {
+	result := Config{}
+	fc, err := parseFancontrol(r)
+	if err != nil {
+		return result, nil, err
+	}
+
+	var warnings []string
+	for _, key := range fancontrolIgnoredKeys {
+		if value, ok := fc.raw[key]; ok {
+			warnings = append(warnings, fmt.Sprintf("%s=%s is not supported and was ignored", key, value))
+		}
+	}
+
+	temps := fc.values["FCTEMPS"]
+	if len(temps) <= 0 {
+		return result, nil, fmt.Errorf("no FCTEMPS found, the file doesn't configure any fan")
+	}
+	pwms := make([]string, 0, len(temps))
+	for pwm := range temps {
+		pwms = append(pwms, pwm)
+	}
+	sort.Strings(pwms)
+
+	sensorIds := map[string]bool{}
+	for _, pwm := range pwms {
+		device, pwmChannel, err := parseFancontrolPath(pwm, pwmFilePattern)
+		if err != nil {
+			return result, nil, err
+		}
+		fanId := fmt.Sprintf("%s_pwm%d", fc.deviceId(device), pwmChannel)
+		platform, pciAddress, err := fc.deviceMatch(device)
+		if err != nil {
+			return result, nil, err
+		}
+
+		minTemp, err := fc.getInt("MINTEMP", pwm, nil)
+		if err != nil {
+			return result, nil, err
+		}
+		maxTemp, err := fc.getInt("MAXTEMP", pwm, nil)
+		if err != nil {
+			return result, nil, err
+		}
+
+		var curveIds []string
+		tempInputs := strings.Split(temps[pwm], "+")
+		for _, temp := range tempInputs {
+			sensorDevice, index, err := parseFancontrolPath(temp, tempInputPattern)
+			if err != nil {
+				return result, nil, err
+			}
+			sensorId := fmt.Sprintf("%s_temp%d", fc.deviceId(sensorDevice), index)
+			if !sensorIds[sensorId] {
+				sensorIds[sensorId] = true
+				sensorPlatform, sensorPciAddress, err := fc.deviceMatch(sensorDevice)
+				if err != nil {
+					return result, nil, err
+				}
+				result.Sensors = append(result.Sensors, Sensor{
+					ID: sensorId,
+					HwMon: &HwMonSensor{
+						Platform:   sensorPlatform,
+						PciAddress: sensorPciAddress,
+						Index:      index,
+					},
+				})
+			}
+
+			curveId := fanId + "_curve"
+			if len(tempInputs) > 1 {
+				curveId = fmt.Sprintf("%s_%s_curve", fanId, sensorId)
+			}
+			result.Curves = append(result.Curves, Curve{
+				ID: curveId,
+				Linear: &LinearCurve{
+					Sensor: sensorId,
+					Min:    minTemp,
+					Max:    maxTemp,
+				},
+			})
+			curveIds = append(curveIds, curveId)
+		}
+
+		// fancontrol uses the highest temperature of all sensors of a fan
+		curveId := curveIds[0]
+		if len(tempInputs) > 1 {
+			curveId = fanId + "_curve"
+			result.Curves = append(result.Curves, maximumCurve(curveId, curveIds))
+		}
+
+		fan, err := fc.createFan(pwm, fanId, platform, pciAddress, pwmChannel)
+		if err != nil {
+			return result, nil, err
+		}
+		fan.Curve = curveId
+		if len(fc.values["FCFANS"][pwm]) <= 0 {
+			warnings = append(warnings, fmt.Sprintf("no FCFANS entry for %s, assuming the RPM of fan %s is read from fan%d_input", pwm, fanId, pwmChannel))
+		}
+		result.Fans = append(result.Fans, fan)
+	}
+
+	return result, warnings, nil
+}
+
+func (fc fancontrolConfig) createFan(pwm string, fanId string, platform string, pciAddress string, pwmChannel int) (Fan, error) {
+	fan := Fan{
+		ID: fanId,
+		HwMon: &HwMonFan{
+			Platform:   platform,
+			PciAddress: pciAddress,
+			RpmChannel: pwmChannel,
+			PwmChannel: pwmChannel,
+		},
+	}
+	if fanInputs := fc.values["FCFANS"][pwm]; len(fanInputs) > 0 {
+		// fan2go reads a single RPM input per fan
+		_, rpmChannel, err := parseFancontrolPath(strings.Split(fanInputs, "+")[0], fanInputPattern)
+		if err != nil {
+			return fan, err
+		}
+		fan.HwMon.RpmChannel = rpmChannel
+	}
+
+	defaultMinPwm := 0
+	defaultMaxPwm := 255
+	minStart, err := fc.getInt("MINSTART", pwm, nil)
+	if err != nil {
+		return fan, err
+	}
+	minStop, err := fc.getInt("MINSTOP", pwm, nil)
+	if err != nil {
+		return fan, err
+	}
+	minPwm, err := fc.getInt("MINPWM", pwm, &defaultMinPwm)
+	if err != nil {
+		return fan, err
+	}
+	maxPwm, err := fc.getInt("MAXPWM", pwm, &defaultMaxPwm)
+	if err != nil {
+		return fan, err
+	}
+
+	// fancontrol stops the fan below MINTEMP, unless MINPWM is set
+	fan.NeverStop = minPwm > 0
+	fan.MinPwm = &minStop
+	fan.StartPwm = &minStart
+	fan.MaxPwm = &maxPwm
+	return fan, nil
+}
+
+func parseFancontrol(r io.Reader) (fancontrolConfig, error) {
+	fc := fancontrolConfig{
+		values: map[string]map[string]string{},
+		raw:    map[string]string{},
+	}
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) <= 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fc, fmt.Errorf("line %d: expected KEY=VALUE, got '%s'", lineNumber, line)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		fc.raw[key] = value
+
+		entries := map[string]string{}
+		for _, entry := range strings.Fields(value) {
+			entryKey, entryValue, ok := strings.Cut(entry, "=")
+			if ok {
+				entries[entryKey] = entryValue
+			}
+		}
+		fc.values[key] = entries
+	}
+	return fc, scanner.Err()
+}
+
+// getInt returns the value of the given setting for the given pwm output,
+// or defaultValue if the setting is missing and defaultValue is not nil
+func (fc fancontrolConfig) getInt(key string, pwm string, defaultValue *int) (int, error) {
+	value, ok := fc.values[key][pwm]
+	if !ok {
+		if defaultValue != nil {
+			return *defaultValue, nil
+		}
+		return 0, fmt.Errorf("missing %s for %s", key, pwm)
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s for %s: %s", key, pwm, value)
+	}
+	return result, nil
+}
+
+// deviceMatch returns the platform and PCI address to select the given hwmon device (f.ex. hwmon0) with
+func (fc fancontrolConfig) deviceMatch(device string) (platform string, pciAddress string, err error) {
+	platform = fc.values["DEVNAME"][device]
+	if len(platform) <= 0 {
+		return "", "", fmt.Errorf("missing DEVNAME for %s, the device can't be identified without it", device)
+	}
+	for _, element := range strings.Split(fc.values["DEVPATH"][device], "/") {
+		if configuration.IsValidPciAddress(element) {
+			pciAddress = "pci-" + configuration.NormalizePciAddress(element)
+		}
+	}
+	return platform, pciAddress, nil
+}
+
+// deviceId returns the name of the given hwmon device (f.ex. hwmon0) used for ids,
+// the device itself is appended if multiple devices have the same name
+func (fc fancontrolConfig) deviceId(device string) string {
+	name := fc.values["DEVNAME"][device]
+	count := 0
+	for _, other := range fc.values["DEVNAME"] {
+		if other == name {
+			count++
+		}
+	}
+	if count > 1 {
+		name = name + "_" + device
+	}
+	return toId(name)
+}
+
+// parseFancontrolPath splits a path like "hwmon0/pwm1" or "hwmon0/device/pwm1" into the hwmon device
+// and the channel of the file, which must match the given pattern
+func parseFancontrolPath(p string, pattern *regexp.Regexp) (device string, channel int, err error) {
+	p = strings.TrimPrefix(p, "/sys/class/hwmon/")
+	elements := strings.Split(p, "/")
+	match := pattern.FindStringSubmatch(elements[len(elements)-1])
+	if len(elements) < 2 || match == nil {
+		return "", 0, fmt.Errorf("unsupported path: %s", p)
+	}
+	channel, err = strconv.Atoi(match[1])
+	if err != nil {
+		return "", 0, err
+	}
+	return elements[0], channel, nil
+}
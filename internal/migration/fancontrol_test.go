@@ -0,0 +1,118 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testFancontrol = `
+# Configuration file generated by pwmconfig, changes will be lost
+INTERVAL=10
+DEVPATH=hwmon1=devices/platform/nct6775.656 hwmon2=devices/pci0000:00/0000:00:18.3
+DEVNAME=hwmon1=nct6798 hwmon2=k10temp
+FCTEMPS=hwmon1/pwm2=hwmon2/temp1_input hwmon1/pwm1=hwmon2/temp1_input+hwmon1/temp2_input
+FCFANS=hwmon1/pwm2=hwmon1/fan2_input hwmon1/pwm1=hwmon1/fan1_input
+MINTEMP=hwmon1/pwm2=40 hwmon1/pwm1=35
+MAXTEMP=hwmon1/pwm2=70 hwmon1/pwm1=80
+MINSTART=hwmon1/pwm2=150 hwmon1/pwm1=120
+MINSTOP=hwmon1/pwm2=100 hwmon1/pwm1=80
+MINPWM=hwmon1/pwm1=50
+MAXPWM=hwmon1/pwm2=200
+`
+
+func TestFromFancontrol(t *testing.T) {
+	// WHEN
+	config, warnings, err := FromFancontrol(strings.NewReader(testFancontrol))
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"INTERVAL=10 is not supported and was ignored"}, warnings)
+
+	assert.Equal(t, []Sensor{
+		{ID: "k10temp_temp1", HwMon: &HwMonSensor{Platform: "k10temp", PciAddress: "pci-0000:00:18.3", Index: 1}},
+		{ID: "nct6798_temp2", HwMon: &HwMonSensor{Platform: "nct6798", Index: 2}},
+	}, config.Sensors)
+
+	assert.Equal(t, []Curve{
+		{ID: "nct6798_pwm1_k10temp_temp1_curve", Linear: &LinearCurve{Sensor: "k10temp_temp1", Min: 35, Max: 80}},
+		{ID: "nct6798_pwm1_nct6798_temp2_curve", Linear: &LinearCurve{Sensor: "nct6798_temp2", Min: 35, Max: 80}},
+		maximumCurve("nct6798_pwm1_curve", []string{"nct6798_pwm1_k10temp_temp1_curve", "nct6798_pwm1_nct6798_temp2_curve"}),
+		{ID: "nct6798_pwm2_curve", Linear: &LinearCurve{Sensor: "k10temp_temp1", Min: 40, Max: 70}},
+	}, config.Curves)
+
+	minPwm1, startPwm1, maxPwm1 := 80, 120, 255
+	minPwm2, startPwm2, maxPwm2 := 100, 150, 200
+	assert.Equal(t, []Fan{
+		{
+			ID:        "nct6798_pwm1",
+			HwMon:     &HwMonFan{Platform: "nct6798", RpmChannel: 1, PwmChannel: 1},
+			NeverStop: true,
+			MinPwm:    &minPwm1,
+			StartPwm:  &startPwm1,
+			MaxPwm:    &maxPwm1,
+			Curve:     "nct6798_pwm1_curve",
+		},
+		{
+			ID:        "nct6798_pwm2",
+			HwMon:     &HwMonFan{Platform: "nct6798", RpmChannel: 2, PwmChannel: 2},
+			NeverStop: false,
+			MinPwm:    &minPwm2,
+			StartPwm:  &startPwm2,
+			MaxPwm:    &maxPwm2,
+			Curve:     "nct6798_pwm2_curve",
+		},
+	}, config.Fans)
+}
+
+func TestFromFancontrolMissingFanInput(t *testing.T) {
+	// GIVEN
+	content := `
+DEVNAME=hwmon0=it8728
+FCTEMPS=hwmon0/device/pwm3=hwmon0/device/temp1_input
+MINTEMP=hwmon0/device/pwm3=45
+MAXTEMP=hwmon0/device/pwm3=60
+MINSTART=hwmon0/device/pwm3=90
+MINSTOP=hwmon0/device/pwm3=60
+`
+
+	// WHEN
+	config, warnings, err := FromFancontrol(strings.NewReader(content))
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"no FCFANS entry for hwmon0/device/pwm3, assuming the RPM of fan it8728_pwm3 is read from fan3_input"}, warnings)
+	assert.Len(t, config.Fans, 1)
+	assert.Equal(t, &HwMonFan{Platform: "it8728", RpmChannel: 3, PwmChannel: 3}, config.Fans[0].HwMon)
+}
+
+func TestFromFancontrolMissingDevName(t *testing.T) {
+	// GIVEN
+	content := `
+FCTEMPS=hwmon0/pwm1=hwmon0/temp1_input
+MINTEMP=hwmon0/pwm1=45
+MAXTEMP=hwmon0/pwm1=60
+`
+
+	// WHEN
+	_, _, err := FromFancontrol(strings.NewReader(content))
+
+	// THEN
+	assert.EqualError(t, err, "missing DEVNAME for hwmon0, the device can't be identified without it")
+}
+
+func TestFromFancontrolMissingValue(t *testing.T) {
+	// GIVEN
+	content := `
+DEVNAME=hwmon0=it8728
+FCTEMPS=hwmon0/pwm1=hwmon0/temp1_input
+MINTEMP=hwmon0/pwm1=45
+`
+
+	// WHEN
+	_, _, err := FromFancontrol(strings.NewReader(content))
+
+	// THEN
+	assert.EqualError(t, err, "missing MAXTEMP for hwmon0/pwm1")
+}
@@ -0,0 +1,368 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/markusressel/fan2go/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// thinkpadPlatform is the platform of the hwmon device of the thinkpad_acpi driver
+	thinkpadPlatform = "thinkpad"
+	// thinkpadMaxLevel is the highest fan level of the thinkpad_acpi driver
+	thinkpadMaxLevel = 7
+)
+
+// thinkfanConfig is a thinkfan configuration in the YAML format used since thinkfan 1.0
+type thinkfanConfig struct {
+	Sensors []thinkfanDevice `yaml:"sensors"`
+	Fans    []thinkfanDevice `yaml:"fans"`
+	Levels  []yaml.Node      `yaml:"levels"`
+}
+
+type thinkfanDevice struct {
+	Hwmon      string    `yaml:"hwmon"`
+	Tpacpi     string    `yaml:"tpacpi"`
+	Nvml       string    `yaml:"nvml"`
+	Atasmart   string    `yaml:"atasmart"`
+	Chip       string    `yaml:"chip"`
+	Name       string    `yaml:"name"`
+	Indices    []int     `yaml:"indices"`
+	Correction []float64 `yaml:"correction"`
+}
+
+// thinkfanLevel is a single level of the levels list, in either the simple or the detailed syntax
+type thinkfanLevel struct {
+	// speeds contains the speed of each fan, or a single speed for all fans
+	speeds []string
+	// lower and upper contain the limits of each sensor, or a single limit for all sensors
+	lower []float64
+	upper []float64
+}
+
+// thinkfanFan is a converted fan, and whether its speeds are thinkpad_acpi levels instead of pwm values
+type thinkfanFan struct {
+	fan        Fan
+	usesLevels bool
+}
+
+// FromThinkfan converts the given thinkfan configuration (/etc/thinkfan.yaml) to a fan2go configuration.
+// The levels of thinkfan are converted to curves with steps, its hysteresis to the hysteresis of the curves.
+// The returned warnings describe settings which couldn't be converted.
+func FromThinkfan(r io.Reader) (Config, []string, error) {
+	result := Config{}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return result, nil, err
+	}
+	tf := thinkfanConfig{}
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return result, nil, fmt.Errorf("only the YAML format of thinkfan 1.0 and newer is supported: %v", err)
+	}
+	if len(tf.Sensors) <= 0 || len(tf.Fans) <= 0 || len(tf.Levels) <= 0 {
+		return result, nil, fmt.Errorf("sensors, fans and levels are required, only the YAML format of thinkfan 1.0 and newer is supported")
+	}
+
+	var warnings []string
+	for _, device := range tf.Sensors {
+		sensors, sensorWarnings, err := convertThinkfanSensor(device, len(result.Sensors))
+		if err != nil {
+			return result, nil, err
+		}
+		warnings = append(warnings, sensorWarnings...)
+		result.Sensors = append(result.Sensors, sensors...)
+	}
+	if len(result.Sensors) <= 0 {
+		return result, nil, fmt.Errorf("none of the sensors can be converted")
+	}
+
+	var fans []thinkfanFan
+	for _, device := range tf.Fans {
+		converted, err := convertThinkfanFan(device, len(fans))
+		if err != nil {
+			return result, nil, err
+		}
+		fans = append(fans, converted...)
+	}
+
+	levels, err := parseThinkfanLevels(tf.Levels)
+	if err != nil {
+		return result, nil, err
+	}
+
+	for fanIdx, tfFan := range fans {
+		fan := tfFan.fan
+		speeds := make([]float64, len(levels))
+		for idx, level := range levels {
+			speed := level.speeds[0]
+			if len(level.speeds) > 1 {
+				if fanIdx >= len(level.speeds) {
+					return result, nil, fmt.Errorf("level %d: no speed for fan %d", idx, fanIdx+1)
+				}
+				speed = level.speeds[fanIdx]
+			}
+			pwm, warning, err := thinkfanSpeedToPwm(speed, tfFan.usesLevels)
+			if err != nil {
+				return result, nil, fmt.Errorf("level %d: %v", idx, err)
+			}
+			if len(warning) > 0 {
+				warnings = append(warnings, fmt.Sprintf("level %d: %s", idx, warning))
+			}
+			speeds[idx] = float64(pwm)
+		}
+
+		var curveIds []string
+		for sensorIdx, sensor := range result.Sensors {
+			curveId := fan.ID + "_curve"
+			if len(result.Sensors) > 1 {
+				curveId = fmt.Sprintf("%s_%s_curve", fan.ID, sensor.ID)
+			}
+			curve, err := createThinkfanCurve(curveId, sensor.ID, sensorIdx, levels, speeds)
+			if err != nil {
+				return result, nil, err
+			}
+			result.Curves = append(result.Curves, curve)
+			curveIds = append(curveIds, curveId)
+		}
+
+		// thinkfan switches to the next level if any sensor exceeds its limit
+		fan.Curve = curveIds[0]
+		if len(curveIds) > 1 {
+			fan.Curve = fan.ID + "_curve"
+			result.Curves = append(result.Curves, maximumCurve(fan.Curve, curveIds))
+		}
+
+		// the speeds of the levels are used as they are
+		minPwm, maxPwm := 0, 255
+		fan.MinPwm = &minPwm
+		fan.MaxPwm = &maxPwm
+		fan.NeverStop = speeds[0] > 0
+		result.Fans = append(result.Fans, fan)
+	}
+
+	return result, warnings, nil
+}
+
+// convertThinkfanSensor returns the sensors for all indices of the given sensor entry,
+// count is the number of sensors converted so far
+func convertThinkfanSensor(device thinkfanDevice, count int) ([]Sensor, []string, error) {
+	var result []Sensor
+	var warnings []string
+
+	switch {
+	case len(device.Tpacpi) > 0:
+		// the temperatures of /proc/acpi/ibm/thermal are also exposed by the hwmon device of thinkpad_acpi
+		indices := device.Indices
+		if len(indices) <= 0 {
+			indices = []int{0}
+			warnings = append(warnings, fmt.Sprintf("tpacpi sensor %s has no indices, only its first temperature is used", device.Tpacpi))
+		}
+		for _, index := range indices {
+			result = append(result, Sensor{
+				ID:    fmt.Sprintf("%s_temp%d", thinkpadPlatform, index+1),
+				HwMon: &HwMonSensor{Platform: thinkpadPlatform, Index: index + 1},
+			})
+		}
+	case len(device.Hwmon) > 0 && len(device.Name) > 0:
+		if len(device.Indices) <= 0 {
+			return nil, nil, fmt.Errorf("hwmon sensor %s has no indices", device.Name)
+		}
+		for _, index := range device.Indices {
+			result = append(result, Sensor{
+				ID:    fmt.Sprintf("%s_temp%d", toId(device.Name), index),
+				HwMon: &HwMonSensor{Platform: device.Name, Index: index},
+			})
+		}
+	case len(device.Hwmon) > 0:
+		if strings.ContainsAny(device.Hwmon, "*?[") {
+			warnings = append(warnings, fmt.Sprintf("the path of sensor %s contains a glob pattern, which is not expanded by fan2go, use a hwmon sensor instead", device.Hwmon))
+		}
+		result = append(result, Sensor{
+			ID:   fmt.Sprintf("sensor%d", count+1),
+			File: &FileSensor{Path: device.Hwmon},
+		})
+	default:
+		return nil, []string{fmt.Sprintf("sensor %s is not supported and was ignored", describeThinkfanDevice(device))}, nil
+	}
+
+	for idx, correction := range device.Correction {
+		if idx < len(result) {
+			result[idx].Offset = correction
+		}
+	}
+	return result, warnings, nil
+}
+
+// convertThinkfanFan returns the fans for all indices of the given fan entry,
+// count is the number of fans converted so far
+func convertThinkfanFan(device thinkfanDevice, count int) ([]thinkfanFan, error) {
+	switch {
+	case len(device.Tpacpi) > 0:
+		// the fan of /proc/acpi/ibm/fan is also exposed by the hwmon device of thinkpad_acpi
+		return []thinkfanFan{{
+			fan: Fan{
+				ID:    thinkpadPlatform + "_fan",
+				HwMon: &HwMonFan{Platform: thinkpadPlatform, RpmChannel: 1},
+			},
+			usesLevels: true,
+		}}, nil
+	case len(device.Hwmon) > 0 && len(device.Name) > 0:
+		if len(device.Indices) <= 0 {
+			return nil, fmt.Errorf("hwmon fan %s has no indices", device.Name)
+		}
+		var result []thinkfanFan
+		for _, index := range device.Indices {
+			result = append(result, thinkfanFan{fan: Fan{
+				ID:    fmt.Sprintf("%s_pwm%d", toId(device.Name), index),
+				HwMon: &HwMonFan{Platform: device.Name, RpmChannel: index, PwmChannel: index},
+			}})
+		}
+		return result, nil
+	case len(device.Hwmon) > 0:
+		if !pwmFilePattern.MatchString(filepath.Base(device.Hwmon)) {
+			return nil, fmt.Errorf("fan %s is not a pwm file", device.Hwmon)
+		}
+		return []thinkfanFan{{fan: Fan{ID: fmt.Sprintf("fan%d", count+1), File: &FileFan{Path: device.Hwmon}}}}, nil
+	default:
+		return nil, fmt.Errorf("fan %s is not supported", describeThinkfanDevice(device))
+	}
+}
+
+func parseThinkfanLevels(nodes []yaml.Node) ([]thinkfanLevel, error) {
+	var result []thinkfanLevel
+	for idx, node := range nodes {
+		level := thinkfanLevel{}
+		switch node.Kind {
+		case yaml.SequenceNode:
+			// simple syntax: [speed, lower limit, upper limit]
+			var values []string
+			if err := node.Decode(&values); err != nil || len(values) != 3 {
+				return nil, fmt.Errorf("level %d: expected [speed, lower limit, upper limit]", idx)
+			}
+			lower, lowerErr := strconv.ParseFloat(values[1], 64)
+			upper, upperErr := strconv.ParseFloat(values[2], 64)
+			if lowerErr != nil || upperErr != nil {
+				return nil, fmt.Errorf("level %d: invalid limits", idx)
+			}
+			level.speeds = []string{values[0]}
+			level.lower = []float64{lower}
+			level.upper = []float64{upper}
+		case yaml.MappingNode:
+			detailed := struct {
+				Speed      yaml.Node `yaml:"speed"`
+				LowerLimit []float64 `yaml:"lower_limit"`
+				UpperLimit []float64 `yaml:"upper_limit"`
+			}{}
+			if err := node.Decode(&detailed); err != nil {
+				return nil, fmt.Errorf("level %d: %v", idx, err)
+			}
+			if detailed.Speed.Kind == yaml.SequenceNode {
+				if err := detailed.Speed.Decode(&level.speeds); err != nil {
+					return nil, fmt.Errorf("level %d: invalid speed: %v", idx, err)
+				}
+			} else {
+				level.speeds = []string{detailed.Speed.Value}
+			}
+			level.lower = detailed.LowerLimit
+			level.upper = detailed.UpperLimit
+		default:
+			return nil, fmt.Errorf("level %d: unsupported syntax", idx)
+		}
+		if len(level.speeds) <= 0 || len(level.speeds[0]) <= 0 {
+			return nil, fmt.Errorf("level %d: missing speed", idx)
+		}
+		if idx < len(nodes)-1 && len(level.upper) <= 0 {
+			return nil, fmt.Errorf("level %d: missing upper limit", idx)
+		}
+		result = append(result, level)
+	}
+	return result, nil
+}
+
+// createThinkfanCurve creates a step curve switching to the speed of the next level once the sensor with
+// the given index exceeds the upper limit of the current level
+func createThinkfanCurve(id string, sensorId string, sensorIdx int, levels []thinkfanLevel, speeds []float64) (Curve, error) {
+	steps := map[int]float64{0: speeds[0]}
+	hysteresis := 0.0
+	for idx := 1; idx < len(levels); idx++ {
+		upper, err := getThinkfanLimit(levels[idx-1].upper, sensorIdx)
+		if err != nil {
+			return Curve{}, fmt.Errorf("level %d: upper limit: %v", idx-1, err)
+		}
+		steps[int(upper)] = speeds[idx]
+
+		// thinkfan switches back to the previous level once the sensor drops below the lower limit
+		if len(levels[idx].lower) <= 0 {
+			continue
+		}
+		lower, err := getThinkfanLimit(levels[idx].lower, sensorIdx)
+		if err != nil {
+			return Curve{}, fmt.Errorf("level %d: lower limit: %v", idx, err)
+		}
+		// fan2go uses a single hysteresis for all steps, so the smallest one is used
+		if gap := upper - lower; gap > 0 && (hysteresis <= 0 || gap < hysteresis) {
+			hysteresis = gap
+		}
+	}
+	return Curve{
+		ID: id,
+		Linear: &LinearCurve{
+			Sensor:        sensorId,
+			Steps:         steps,
+			Interpolation: util.InterpolationTypeStep,
+			Hysteresis:    hysteresis,
+		},
+	}, nil
+}
+
+func getThinkfanLimit(limits []float64, sensorIdx int) (float64, error) {
+	if len(limits) == 1 {
+		return limits[0], nil
+	}
+	if sensorIdx >= len(limits) {
+		return 0, fmt.Errorf("no limit for sensor %d", sensorIdx+1)
+	}
+	return limits[sensorIdx], nil
+}
+
+// thinkfanSpeedToPwm returns the pwm value of the given thinkfan speed, which is a
+// thinkpad_acpi level for tpacpi fans and a pwm value otherwise
+func thinkfanSpeedToPwm(speed string, usesLevels bool) (pwm int, warning string, err error) {
+	speed = strings.TrimSpace(speed)
+	switch speed {
+	case "level full-speed", "level disengaged":
+		return 255, "", nil
+	case "level auto":
+		return 255, "\"level auto\" is not supported by fan2go, full speed is used instead", nil
+	}
+	value, err := strconv.Atoi(strings.TrimPrefix(speed, "level "))
+	if err != nil {
+		return 0, "", fmt.Errorf("unsupported speed '%s'", speed)
+	}
+	if !usesLevels {
+		return value, "", nil
+	}
+	if value < 0 || value > thinkpadMaxLevel {
+		return 0, "", fmt.Errorf("fan level %d out of range [0..%d]", value, thinkpadMaxLevel)
+	}
+	// thinkpad_acpi maps pwm values to levels by scaling them down to [0..7]
+	return (value*255 + thinkpadMaxLevel - 1) / thinkpadMaxLevel, "", nil
+}
+
+func describeThinkfanDevice(device thinkfanDevice) string {
+	switch {
+	case len(device.Nvml) > 0:
+		return "nvml: " + device.Nvml
+	case len(device.Atasmart) > 0:
+		return "atasmart: " + device.Atasmart
+	case len(device.Chip) > 0:
+		return "chip: " + device.Chip
+	default:
+		return "without a type"
+	}
+}
@@ -0,0 +1,165 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromThinkfanSimpleLevels(t *testing.T) {
+	// GIVEN
+	content := `
+sensors:
+  - tpacpi: /proc/acpi/ibm/thermal
+    indices: [0, 1]
+    correction: [0, -5]
+fans:
+  - tpacpi: /proc/acpi/ibm/fan
+levels:
+  - [0, 0, 55]
+  - [1, 48, 60]
+  - [7, 56, 32767]
+  - ["level full-speed", 70, 32767]
+`
+
+	// WHEN
+	config, warnings, err := FromThinkfan(strings.NewReader(content))
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	assert.Equal(t, []Sensor{
+		{ID: "thinkpad_temp1", HwMon: &HwMonSensor{Platform: "thinkpad", Index: 1}},
+		{ID: "thinkpad_temp2", HwMon: &HwMonSensor{Platform: "thinkpad", Index: 2}, Offset: -5},
+	}, config.Sensors)
+
+	steps := map[int]float64{0: 0, 55: 37, 60: 255, 32767: 255}
+	assert.Equal(t, []Curve{
+		{ID: "thinkpad_fan_thinkpad_temp1_curve", Linear: &LinearCurve{Sensor: "thinkpad_temp1", Steps: steps, Interpolation: "step", Hysteresis: 4}},
+		{ID: "thinkpad_fan_thinkpad_temp2_curve", Linear: &LinearCurve{Sensor: "thinkpad_temp2", Steps: steps, Interpolation: "step", Hysteresis: 4}},
+		maximumCurve("thinkpad_fan_curve", []string{"thinkpad_fan_thinkpad_temp1_curve", "thinkpad_fan_thinkpad_temp2_curve"}),
+	}, config.Curves)
+
+	minPwm, maxPwm := 0, 255
+	assert.Equal(t, []Fan{
+		{
+			ID:        "thinkpad_fan",
+			HwMon:     &HwMonFan{Platform: "thinkpad", RpmChannel: 1},
+			NeverStop: false,
+			MinPwm:    &minPwm,
+			MaxPwm:    &maxPwm,
+			Curve:     "thinkpad_fan_curve",
+		},
+	}, config.Fans)
+}
+
+func TestFromThinkfanDetailedLevels(t *testing.T) {
+	// GIVEN
+	content := `
+sensors:
+  - hwmon: /sys/class/hwmon
+    name: k10temp
+    indices: [1]
+  - nvml: 27:00.0
+fans:
+  - hwmon: /sys/class/hwmon
+    name: nct6798
+    indices: [1, 2]
+levels:
+  - speed: [60, 0]
+    upper_limit: [50]
+  - speed: [128, 100]
+    lower_limit: [45]
+    upper_limit: [65]
+  - speed: [255, 255]
+    lower_limit: [60]
+`
+
+	// WHEN
+	config, warnings, err := FromThinkfan(strings.NewReader(content))
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sensor nvml: 27:00.0 is not supported and was ignored"}, warnings)
+
+	assert.Equal(t, []Sensor{
+		{ID: "k10temp_temp1", HwMon: &HwMonSensor{Platform: "k10temp", Index: 1}},
+	}, config.Sensors)
+	assert.Equal(t, []Curve{
+		{ID: "nct6798_pwm1_curve", Linear: &LinearCurve{Sensor: "k10temp_temp1", Steps: map[int]float64{0: 60, 50: 128, 65: 255}, Interpolation: "step", Hysteresis: 5}},
+		{ID: "nct6798_pwm2_curve", Linear: &LinearCurve{Sensor: "k10temp_temp1", Steps: map[int]float64{0: 0, 50: 100, 65: 255}, Interpolation: "step", Hysteresis: 5}},
+	}, config.Curves)
+
+	assert.Len(t, config.Fans, 2)
+	assert.Equal(t, &HwMonFan{Platform: "nct6798", RpmChannel: 1, PwmChannel: 1}, config.Fans[0].HwMon)
+	assert.True(t, config.Fans[0].NeverStop)
+	assert.Equal(t, &HwMonFan{Platform: "nct6798", RpmChannel: 2, PwmChannel: 2}, config.Fans[1].HwMon)
+	assert.False(t, config.Fans[1].NeverStop)
+}
+
+func TestFromThinkfanLevelAuto(t *testing.T) {
+	// GIVEN
+	content := `
+sensors:
+  - hwmon: /sys/class/hwmon/hwmon3/temp1_input
+fans:
+  - tpacpi: /proc/acpi/ibm/fan
+levels:
+  - [0, 0, 60]
+  - ["level auto", 55, 32767]
+`
+
+	// WHEN
+	config, warnings, err := FromThinkfan(strings.NewReader(content))
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"level 1: \"level auto\" is not supported by fan2go, full speed is used instead"}, warnings)
+	assert.Equal(t, []Sensor{{ID: "sensor1", File: &FileSensor{Path: "/sys/class/hwmon/hwmon3/temp1_input"}}}, config.Sensors)
+}
+
+func TestFromThinkfanLegacyFormat(t *testing.T) {
+	// GIVEN
+	content := `
+tp_fan /proc/acpi/ibm/fan
+hwmon /sys/class/hwmon/hwmon0/temp1_input
+(0, 0, 55)
+(7, 50, 32767)
+`
+
+	// WHEN
+	_, _, err := FromThinkfan(strings.NewReader(content))
+
+	// THEN
+	assert.Error(t, err)
+}
+
+func TestThinkfanSpeedToPwm(t *testing.T) {
+	for _, tc := range []struct {
+		speed      string
+		usesLevels bool
+		expected   int
+	}{
+		{"0", true, 0},
+		{"1", true, 37},
+		{"level 4", true, 146},
+		{"7", true, 255},
+		{"level full-speed", true, 255},
+		{"128", false, 128},
+	} {
+		// WHEN
+		pwm, _, err := thinkfanSpeedToPwm(tc.speed, tc.usesLevels)
+
+		// THEN
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, pwm, tc.speed)
+	}
+
+	// WHEN
+	_, _, err := thinkfanSpeedToPwm("8", true)
+
+	// THEN
+	assert.EqualError(t, err, "fan level 8 out of range [0..7]")
+}
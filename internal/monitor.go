@@ -2,56 +2,238 @@ package internal
 
 import (
 	"context"
-	"github.com/markusressel/fan2go/internal/configuration"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/alerting"
+	"github.com/markusressel/fan2go/internal/emergency"
+	"github.com/markusressel/fan2go/internal/polling"
 	"github.com/markusressel/fan2go/internal/sensors"
 	"github.com/markusressel/fan2go/internal/ui"
-	"github.com/markusressel/fan2go/internal/util"
-	"time"
 )
 
-type SensorMonitor interface {
-	Run(ctx context.Context) error
+// number of goroutines reading sensors concurrently
+const sensorPollingWorkers = 4
+
+// sensorGroup is a set of sensors that are read in one pass by a single worker
+type sensorGroup struct {
+	sensors     []sensors.Sensor
+	pollingRate time.Duration
+	// the time the group was last read at, and the time it is read again at,
+	// only accessed by the dispatching goroutine
+	last time.Time
+	next time.Time
+	// set to 1 while a worker is reading the group, so slow sensors are not queued up
+	busy int32
+	// set to 1 once the group was replaced, its results are discarded then
+	removed int32
 }
 
-type sensorMonitor struct {
-	sensor      sensors.Sensor
+// sensorPoller polls all sensors at their configured rate. All inputs of the same hwmon device
+// with the same rate are read in one pass, and all groups are read by a shared pool of workers.
+type sensorPoller struct {
+	// the rate of all sensors which don't override it
 	pollingRate time.Duration
+
+	lock   sync.RWMutex
+	groups []*sensorGroup
 }
 
-func NewSensorMonitor(sensor sensors.Sensor, pollingRate time.Duration) SensorMonitor {
-	return sensorMonitor{
-		sensor:      sensor,
+func newSensorPoller(pollingRate time.Duration) *sensorPoller {
+	return &sensorPoller{
 		pollingRate: pollingRate,
 	}
 }
 
-func (s sensorMonitor) Run(ctx context.Context) error {
-	tick := time.NewTicker(s.pollingRate)
+// SetSensors replaces the polled sensors with the given ones
+func (p *sensorPoller) SetSensors(sensorList []sensors.Sensor) {
+	groups := groupSensors(sensorList, p.pollingRate)
+
+	current := map[string]bool{}
+	for _, sensor := range sensorList {
+		current[sensor.GetId()] = true
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, group := range p.groups {
+		atomic.StoreInt32(&group.removed, 1)
+		for _, sensor := range group.sensors {
+			if !current[sensor.GetId()] {
+				ui.WithSensor(sensor.GetId()).Info("Stopping sensor monitor for sensor %s...", sensor.GetId())
+				clearSensor(sensor.GetId())
+			}
+		}
+	}
+	p.groups = groups
+}
+
+func (p *sensorPoller) getGroups() []*sensorGroup {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.groups
+}
+
+// getTickRate returns the shortest polling rate of all groups
+func (p *sensorPoller) getTickRate() time.Duration {
+	result := p.pollingRate
+	for _, group := range p.getGroups() {
+		if group.pollingRate < result {
+			result = group.pollingRate
+		}
+	}
+	return result
+}
+
+func (p *sensorPoller) Run(ctx context.Context) {
+	jobs := make(chan *sensorGroup)
+	var wg sync.WaitGroup
+	for i := 0; i < sensorPollingWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range jobs {
+				p.poll(group)
+				atomic.StoreInt32(&group.busy, 0)
+			}
+		}()
+	}
+
+dispatch:
 	for {
+		wakeup := polling.Wakeup()
+		if !polling.Wait(ctx, p.getTickRate()) {
+			break
+		}
+		woken := false
 		select {
-		case <-ctx.Done():
-			ui.Info("Stopping sensor monitor for sensor %s...", s.sensor.GetId())
-			return nil
-		case <-tick.C:
-			err := updateSensor(s.sensor)
-			if err != nil {
-				ui.Warning("Error updating sensor: %v", err)
+		case <-wakeup:
+			woken = true
+		default:
+		}
+
+		now := time.Now()
+		for _, group := range p.getGroups() {
+			if !group.isDue(now, woken) {
+				continue
 			}
+			// a group whose previous read didn't finish yet is skipped for this pass
+			if !atomic.CompareAndSwapInt32(&group.busy, 0, 1) {
+				continue
+			}
+			group.last = now
+			group.next = now.Add(polling.Interval(group.pollingRate))
+			select {
+			case jobs <- group:
+			case <-ctx.Done():
+				break dispatch
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, group := range p.getGroups() {
+		for _, sensor := range group.sensors {
+			ui.WithSensor(sensor.GetId()).Info("Stopping sensor monitor for sensor %s...", sensor.GetId())
+			clearSensor(sensor.GetId())
+		}
+	}
+}
+
+// isDue returns true if the group should be read at the given time. All groups are read
+// on a wakeup, and the stretched interval of adaptive polling is shortened again once it was reset.
+func (group *sensorGroup) isDue(now time.Time, woken bool) bool {
+	if woken {
+		return true
+	}
+	if next := group.last.Add(polling.Interval(group.pollingRate)); next.Before(group.next) {
+		group.next = next
+	}
+	return !now.Before(group.next)
+}
+
+// poll reads all sensors of the given group, and reports the results
+// unless the group was replaced in the meantime
+func (p *sensorPoller) poll(group *sensorGroup) {
+	for _, sensor := range group.sensors {
+		value, err := updateSensor(sensor)
+
+		p.lock.RLock()
+		if atomic.LoadInt32(&group.removed) == 0 {
+			reportSensor(sensor, value, err)
+		}
+		p.lock.RUnlock()
+	}
+}
+
+func reportSensor(sensor sensors.Sensor, value float64, err error) {
+	sensors.ReportReadResult(sensor.GetId(), err)
+	if err != nil {
+		ui.WithSensor(sensor.GetId()).Warning("Error updating sensor: %v", err)
+		alerting.Fire(alerting.EventSensorFailure, sensor.GetId(), "Unable to read sensor %s: %v", sensor.GetId(), err)
+		return
+	}
+	sensors.ReportValue(sensor.GetId(), value)
+	polling.ReportSensorValue(sensor.GetId(), value)
+	if critical := sensor.GetConfig().Critical; critical != nil {
+		emergency.Check(sensor.GetId(), *critical, value)
+	}
+}
+
+func clearSensor(sensorId string) {
+	emergency.Clear(sensorId)
+	polling.Clear(sensorId)
+}
+
+// groupSensors groups hwmon sensors by their device and polling rate, all other sensors are read individually.
+// Sensors without a polling rate of their own are polled at the given default rate.
+func groupSensors(sensorList []sensors.Sensor, defaultPollingRate time.Duration) []*sensorGroup {
+	type groupKey struct {
+		device      string
+		pollingRate time.Duration
+	}
+
+	var result []*sensorGroup
+	devices := map[groupKey]*sensorGroup{}
+	for _, sensor := range sensorList {
+		pollingRate := defaultPollingRate
+		if sensor.GetConfig().PollingRate > 0 {
+			pollingRate = sensor.GetConfig().PollingRate
+		}
+
+		inner := sensor
+		if transformed, ok := sensor.(*sensors.TransformedSensor); ok {
+			inner = transformed.Unwrap()
+		}
+		hwmonSensor, ok := inner.(*sensors.HwmonSensor)
+		if !ok {
+			result = append(result, &sensorGroup{sensors: []sensors.Sensor{sensor}, pollingRate: pollingRate})
+			continue
+		}
+
+		key := groupKey{device: filepath.Dir(hwmonSensor.Input), pollingRate: pollingRate}
+		group, ok := devices[key]
+		if !ok {
+			group = &sensorGroup{pollingRate: pollingRate}
+			devices[key] = group
+			result = append(result, group)
 		}
+		group.sensors = append(group.sensors, sensor)
 	}
+	return result
 }
 
 // read the current value of a sensors and append it to the moving window
-func updateSensor(s sensors.Sensor) (err error) {
-	value, err := s.GetValue()
+func updateSensor(s sensors.Sensor) (value float64, err error) {
+	value, err = s.GetValue()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	var n = configuration.CurrentConfig.TempRollingWindowSize
-	lastAvg := s.GetMovingAvg()
-	newAvg := util.UpdateSimpleMovingAvg(lastAvg, n, value)
-	s.SetMovingAvg(newAvg)
+	sensors.UpdateMovingAvg(s, value)
 
-	return nil
+	return value, nil
 }
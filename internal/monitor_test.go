@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/polling"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupSensorsByHwmonDevice(t *testing.T) {
+	// GIVEN
+	cpu := &sensors.HwmonSensor{Input: "/sys/class/hwmon/hwmon0/temp1_input", Config: configuration.SensorConfig{ID: "cpu"}}
+	vrm := &sensors.HwmonSensor{Input: "/sys/class/hwmon/hwmon0/temp2_input", Config: configuration.SensorConfig{ID: "vrm"}}
+	gpu := &sensors.HwmonSensor{Input: "/sys/class/hwmon/hwmon1/temp1_input", Config: configuration.SensorConfig{ID: "gpu"}}
+	file := &sensors.FileSensor{Config: configuration.SensorConfig{ID: "file"}}
+
+	// WHEN
+	groups := groupSensors([]sensors.Sensor{cpu, file, gpu, vrm}, time.Second)
+
+	// THEN
+	assert.Len(t, groups, 3)
+	assert.Equal(t, []sensors.Sensor{cpu, vrm}, groups[0].sensors)
+	assert.Equal(t, []sensors.Sensor{file}, groups[1].sensors)
+	assert.Equal(t, []sensors.Sensor{gpu}, groups[2].sensors)
+}
+
+func TestGroupSensorsByPollingRate(t *testing.T) {
+	// GIVEN
+	cpu := &sensors.HwmonSensor{Input: "/sys/class/hwmon/hwmon0/temp1_input", Config: configuration.SensorConfig{ID: "cpu"}}
+	vrm := &sensors.HwmonSensor{Input: "/sys/class/hwmon/hwmon0/temp2_input", Config: configuration.SensorConfig{ID: "vrm", PollingRate: 5 * time.Second}}
+	file := &sensors.FileSensor{Config: configuration.SensorConfig{ID: "file", PollingRate: 30 * time.Second}}
+
+	// WHEN
+	groups := groupSensors([]sensors.Sensor{cpu, vrm, file}, time.Second)
+
+	// THEN
+	assert.Len(t, groups, 3)
+	assert.Equal(t, []sensors.Sensor{cpu}, groups[0].sensors)
+	assert.Equal(t, time.Second, groups[0].pollingRate)
+	assert.Equal(t, []sensors.Sensor{vrm}, groups[1].sensors)
+	assert.Equal(t, 5*time.Second, groups[1].pollingRate)
+	assert.Equal(t, []sensors.Sensor{file}, groups[2].sensors)
+	assert.Equal(t, 30*time.Second, groups[2].pollingRate)
+}
+
+func TestSensorGroupIsDue(t *testing.T) {
+	// GIVEN
+	now := time.Now()
+	group := &sensorGroup{
+		pollingRate: 100 * time.Millisecond,
+		last:        now,
+		next:        now.Add(100 * time.Millisecond),
+	}
+
+	// WHEN
+	// THEN
+	assert.False(t, group.isDue(now.Add(50*time.Millisecond), false))
+	assert.True(t, group.isDue(now.Add(50*time.Millisecond), true))
+	assert.True(t, group.isDue(now.Add(100*time.Millisecond), false))
+}
+
+func TestSensorGroupIsDueAfterAdaptivePollingReset(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.AdaptivePolling = configuration.AdaptivePollingConfig{
+		Enabled:     true,
+		MaxInterval: 10 * time.Second,
+		RampUp:      time.Minute,
+	}
+	defer func() { configuration.CurrentConfig.AdaptivePolling = configuration.AdaptivePollingConfig{} }()
+	now := time.Now()
+	// the group was read while the interval was stretched
+	group := &sensorGroup{
+		pollingRate: 100 * time.Millisecond,
+		last:        now,
+		next:        now.Add(10 * time.Second),
+	}
+
+	// WHEN
+	polling.ReportFanActivity()
+
+	// THEN
+	assert.True(t, group.isDue(now.Add(time.Second), false))
+}
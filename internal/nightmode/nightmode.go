@@ -0,0 +1,42 @@
+package nightmode
+
+import (
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// IsActive returns true if night mode is enabled and the given time is within its hours
+func IsActive(now time.Time) bool {
+	config := configuration.CurrentConfig.NightMode
+	if !config.Enabled {
+		return false
+	}
+	from, err := util.ParseTimeOfDay(config.From)
+	if err != nil {
+		return false
+	}
+	to, err := util.ParseTimeOfDay(config.To)
+	if err != nil {
+		return false
+	}
+	return util.IsTimeOfDayInRange(now, from, to)
+}
+
+// GetMaxPwm returns the pwm cap of night mode for the given fan at the given time, if any
+func GetMaxPwm(fanId string, now time.Time) (int, bool) {
+	if !IsActive(now) {
+		return 0, false
+	}
+	config := configuration.CurrentConfig.NightMode
+	for _, fanConfig := range config.Fans {
+		if fanConfig.Fan == fanId {
+			return fanConfig.MaxPwm, true
+		}
+	}
+	if config.MaxPwm == nil {
+		return 0, false
+	}
+	return *config.MaxPwm, true
+}
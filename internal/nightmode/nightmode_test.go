@@ -0,0 +1,81 @@
+package nightmode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func createNightMode(enabled bool) {
+	maxPwm := 150
+	configuration.CurrentConfig.NightMode = configuration.NightModeConfig{
+		Enabled: enabled,
+		From:    "22:00",
+		To:      "07:00",
+		MaxPwm:  &maxPwm,
+		Fans: []configuration.NightModeFanConfig{
+			{Fan: "case", MaxPwm: 100},
+		},
+	}
+}
+
+func TestNightModeDisabled(t *testing.T) {
+	// GIVEN
+	createNightMode(false)
+	now := time.Date(2024, 1, 1, 23, 0, 0, 0, time.Local)
+
+	// WHEN
+	_, capped := GetMaxPwm("cpu", now)
+
+	// THEN
+	assert.False(t, IsActive(now))
+	assert.False(t, capped)
+}
+
+func TestNightModeOutsideOfHours(t *testing.T) {
+	// GIVEN
+	createNightMode(true)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+
+	// WHEN
+	_, capped := GetMaxPwm("cpu", now)
+
+	// THEN
+	assert.False(t, IsActive(now))
+	assert.False(t, capped)
+}
+
+func TestNightModeActive(t *testing.T) {
+	// GIVEN
+	createNightMode(true)
+	now := time.Date(2024, 1, 1, 2, 30, 0, 0, time.Local)
+
+	// WHEN
+	cpuMaxPwm, cpuCapped := GetMaxPwm("cpu", now)
+	caseMaxPwm, caseCapped := GetMaxPwm("case", now)
+
+	// THEN
+	assert.True(t, IsActive(now))
+	assert.True(t, cpuCapped)
+	assert.Equal(t, 150, cpuMaxPwm)
+	assert.True(t, caseCapped)
+	assert.Equal(t, 100, caseMaxPwm)
+}
+
+func TestNightModeWithoutGlobalCap(t *testing.T) {
+	// GIVEN
+	createNightMode(true)
+	configuration.CurrentConfig.NightMode.MaxPwm = nil
+	now := time.Date(2024, 1, 1, 23, 0, 0, 0, time.Local)
+
+	// WHEN
+	_, cpuCapped := GetMaxPwm("cpu", now)
+	caseMaxPwm, caseCapped := GetMaxPwm("case", now)
+
+	// THEN
+	assert.False(t, cpuCapped)
+	assert.True(t, caseCapped)
+	assert.Equal(t, 100, caseMaxPwm)
+}
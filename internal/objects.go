@@ -0,0 +1,443 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/markusressel/fan2go/internal/alerting"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/profiles"
+	"github.com/markusressel/fan2go/internal/quarantine"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/statistics"
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// daemonObjects manages the sensors, curves and fans of the daemon, including the sensor monitors
+// and fan controllers running for them, and applies configuration changes at runtime
+type daemonObjects struct {
+	ctx  context.Context
+	pers persistence.Persistence
+
+	// lock serializes calls to apply
+	lock sync.Mutex
+	wg   sync.WaitGroup
+
+	// the (resolved) configurations of all currently active objects
+	sensorConfigs map[string]configuration.SensorConfig
+	curveConfigs  map[string]configuration.CurveConfig
+	fanConfigs    map[string]configuration.FanConfig
+
+	// sensorPoller polls all sensors, regardless of their configuration
+	sensorPoller   *sensorPoller
+	fanControllers map[string]*runningTask
+
+	collectors []prometheus.Collector
+}
+
+// runningTask is a goroutine that can be stopped individually
+type runningTask struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (t *runningTask) stop() {
+	t.cancel()
+	<-t.done
+}
+
+// isRunning indicates whether the task has not finished yet
+func (t *runningTask) isRunning() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func newDaemonObjects(ctx context.Context, pers persistence.Persistence) *daemonObjects {
+	d := &daemonObjects{
+		ctx:            ctx,
+		pers:           pers,
+		sensorConfigs:  map[string]configuration.SensorConfig{},
+		curveConfigs:   map[string]configuration.CurveConfig{},
+		fanConfigs:     map[string]configuration.FanConfig{},
+		sensorPoller:   newSensorPoller(configuration.CurrentConfig.TempSensorPollingRate),
+		fanControllers: map[string]*runningTask{},
+	}
+	d.start(d.sensorPoller.Run)
+	return d
+}
+
+// wait blocks until all sensor monitors and fan controllers have stopped
+func (d *daemonObjects) wait() {
+	d.wg.Wait()
+}
+
+// apply creates all sensors, curves and fans of the given configuration. Objects whose configuration
+// didn't change since the last call are kept as they are, so fans that are not affected by a change
+// keep being controlled without interruption. Sensors and fans whose device can't be accessed are
+// quarantined, while all other objects are applied, and retried by calling apply again.
+// If any curve can't be created, nothing is changed.
+func (d *daemonObjects) apply(config *configuration.Configuration) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	controllers := hwmon.GetChips()
+
+	// === create all objects whose configuration changed
+	sensorMap := map[string]sensors.Sensor{}
+	sensorConfigs := map[string]configuration.SensorConfig{}
+	var createdSensors []sensors.Sensor
+	var quarantinedSensors []quarantinedObject
+	for _, sensorConfig := range config.Sensors {
+		// the config given is not modified, its hwmon configs may be used by running sensors
+		sensorConfig = sensorConfig.Copy()
+		err := resolveSensorConfig(&sensorConfig, controllers)
+		if err != nil {
+			quarantinedSensors = append(quarantinedSensors, quarantinedObject{sensorConfig.ID, err})
+			continue
+		}
+
+		if old, ok := d.sensorConfigs[sensorConfig.ID]; ok && reflect.DeepEqual(old, sensorConfig) {
+			sensorConfigs[sensorConfig.ID] = sensorConfig
+			sensorMap[sensorConfig.ID] = sensors.GetSensorMap()[sensorConfig.ID]
+			continue
+		}
+
+		sensor, err := sensors.NewSensor(sensorConfig)
+		if err != nil {
+			quarantinedSensors = append(quarantinedSensors, quarantinedObject{sensorConfig.ID, fmt.Errorf("unable to process sensor configuration: %v", err)})
+			continue
+		}
+		sensorConfigs[sensorConfig.ID] = sensorConfig
+		sensorMap[sensorConfig.ID] = sensor
+		createdSensors = append(createdSensors, sensor)
+	}
+
+	curveMap := map[string]curves.SpeedCurve{}
+	curveConfigs := map[string]configuration.CurveConfig{}
+	for _, curveConfig := range config.Curves {
+		curveConfigs[curveConfig.ID] = curveConfig
+
+		if old, ok := d.curveConfigs[curveConfig.ID]; ok && reflect.DeepEqual(old, curveConfig) {
+			curveMap[curveConfig.ID] = curves.GetSpeedCurveMap()[curveConfig.ID]
+			continue
+		}
+
+		curve, err := curves.NewSpeedCurve(curveConfig)
+		if err != nil {
+			return fmt.Errorf("unable to process curve configuration: %s", curveConfig.ID)
+		}
+		curveMap[curveConfig.ID] = curve
+	}
+
+	fanMap := map[string]fans.Fan{}
+	fanConfigs := map[string]configuration.FanConfig{}
+	keptFans := map[string]bool{}
+	createdFans := map[configuration.FanConfig]fans.Fan{}
+	var quarantinedFans []quarantinedObject
+	for _, fanConfig := range config.Fans {
+		// the config given is not modified, its hwmon configs may be used by running fans
+		fanConfig = fanConfig.Copy()
+		if fanConfig.HwMon != nil || fanConfig.DellSmm != nil || fanConfig.Group != nil {
+			err := hwmon.UpdateFanConfigFromHwMonControllers(controllers, &fanConfig)
+			if err != nil {
+				quarantinedFans = append(quarantinedFans, quarantinedObject{fanConfig.ID, fmt.Errorf("couldn't update fan config from hwmon: %s", err)})
+				continue
+			}
+		}
+
+		// fan controllers stop on errors, f.ex. when their device was removed,
+		// so they are started again if their fan is still configured
+		if old, ok := d.fanConfigs[fanConfig.ID]; ok && reflect.DeepEqual(old, fanConfig) && d.fanControllers[fanConfig.ID].isRunning() {
+			fanConfigs[fanConfig.ID] = fanConfig
+			fanMap[fanConfig.ID] = fans.GetFanMap()[fanConfig.ID]
+			keptFans[fanConfig.ID] = true
+			continue
+		}
+
+		fan, err := fans.NewFan(fanConfig)
+		if err != nil {
+			quarantinedFans = append(quarantinedFans, quarantinedObject{fanConfig.ID, fmt.Errorf("unable to process fan configuration: %v", err)})
+			continue
+		}
+		fanConfigs[fanConfig.ID] = fanConfig
+		fanMap[fanConfig.ID] = fan
+		createdFans[fanConfig] = fan
+	}
+
+	if len(config.Fans) == 0 {
+		return errors.New("no valid fan configurations")
+	}
+
+	// === stop everything that was removed or replaced,
+	// fan controllers restore the original state of their fan when stopped
+	for id, task := range d.fanControllers {
+		if keptFans[id] {
+			continue
+		}
+		task.stop()
+		delete(d.fanControllers, id)
+	}
+
+	// === publish the new objects, the maps are replaced instead of modified,
+	// since they are read concurrently
+	fanControllerMap := map[string]controller.FanController{}
+	for id := range d.fanControllers {
+		fanControllerMap[id], _ = controller.GetFanController(id)
+	}
+	sensors.SetSensorMap(sensorMap)
+	curves.SetSpeedCurveMap(curveMap)
+	fans.SetFanMap(fanMap)
+	d.sensorConfigs = sensorConfigs
+	d.curveConfigs = curveConfigs
+	d.fanConfigs = fanConfigs
+	configuration.SetObjects(config)
+	if active := profiles.GetActive(); profiles.SetActive(active) != nil {
+		ui.Warning("Active profile %s was removed, activating the default profile", active)
+		_ = profiles.SetActive(config.Profile)
+	}
+
+	// === start monitors and controllers for all new objects
+	// read initial values only after all sensors have been published,
+	// since virtual sensors depend on other sensors
+	for _, sensor := range createdSensors {
+		currentValue, err := sensor.GetValue()
+		if err != nil {
+			ui.Warning("Error reading sensor %s: %v", sensor.GetId(), err)
+		}
+		sensor.SetMovingAvg(currentValue)
+	}
+	var sensorList []sensors.Sensor
+	for _, sensorConfig := range config.Sensors {
+		if sensor, ok := sensorMap[sensorConfig.ID]; ok {
+			sensorList = append(sensorList, sensor)
+		}
+	}
+	d.sensorPoller.SetSensors(sensorList)
+
+	for fanConfig, fan := range createdFans {
+		if !configuration.CurrentConfig.DryRun {
+			checkFanAccess(fan)
+		}
+		fanController := createFanController(d.pers, fanConfig, fan)
+		fanControllerMap[fan.GetId()] = fanController
+		d.fanControllers[fan.GetId()] = d.startFanController(fan, fanController)
+	}
+	controller.SetFanControllerMap(fanControllerMap)
+
+	d.updateCollectors()
+	updateQuarantine(config, quarantinedSensors, quarantinedFans)
+
+	return nil
+}
+
+// quarantinedObject is a sensor or fan that couldn't be created
+type quarantinedObject struct {
+	id  string
+	err error
+}
+
+// updateQuarantine quarantines the given sensors and fans, and releases all other objects of the given
+// configuration as well as objects which are no longer configured
+func updateQuarantine(config *configuration.Configuration, quarantinedSensors []quarantinedObject, quarantinedFans []quarantinedObject) {
+	failed := map[string]bool{}
+	for _, object := range quarantinedSensors {
+		failed[quarantine.KindSensor+"/"+object.id] = true
+		// fans depending on the sensor enter failsafe mode, if configured
+		sensors.ReportReadResult(object.id, object.err)
+		if quarantine.Add(quarantine.KindSensor, object.id, object.err) {
+			ui.WithSensor(object.id).ErrorAndNotify("Sensor Quarantined", "Sensor %s is quarantined and retried periodically: %v", object.id, object.err)
+			alerting.Fire(alerting.EventSensorFailure, object.id, "Sensor %s is quarantined: %v", object.id, object.err)
+		} else {
+			ui.WithSensor(object.id).Debug("Sensor %s is still unavailable: %v", object.id, object.err)
+		}
+	}
+	for _, object := range quarantinedFans {
+		failed[quarantine.KindFan+"/"+object.id] = true
+		if quarantine.Add(quarantine.KindFan, object.id, object.err) {
+			ui.WithFan(object.id).ErrorAndNotify("Fan Quarantined", "Fan %s is quarantined and retried periodically: %v", object.id, object.err)
+			alerting.Fire(alerting.EventFanFailure, object.id, "Fan %s is quarantined: %v", object.id, object.err)
+		} else {
+			ui.WithFan(object.id).Debug("Fan %s is still unavailable: %v", object.id, object.err)
+		}
+	}
+
+	for _, entry := range quarantine.Get() {
+		if failed[entry.Kind+"/"+entry.ID] {
+			continue
+		}
+		switch entry.Kind {
+		case quarantine.KindSensor:
+			sensors.ReportReadResult(entry.ID, nil)
+			if isSensorConfigured(config, entry.ID) {
+				ui.WithSensor(entry.ID).Info("Sensor %s is available again", entry.ID)
+			}
+		case quarantine.KindFan:
+			// fans are only released once their controller is running, see releaseRecoveredFans
+			if isFanConfigured(config, entry.ID) {
+				continue
+			}
+		}
+		quarantine.Remove(entry.Kind, entry.ID)
+	}
+}
+
+// releaseRecoveredFans releases all quarantined fans whose controller is controlling them again
+func releaseRecoveredFans() {
+	for _, entry := range quarantine.Get() {
+		if entry.Kind != quarantine.KindFan {
+			continue
+		}
+		fanController, ok := controller.GetFanController(entry.ID)
+		if !ok {
+			continue
+		}
+		if state := fanController.GetState(); state != "" && state != controller.ControllerStateStopped {
+			quarantine.Remove(entry.Kind, entry.ID)
+			ui.WithFan(entry.ID).Info("Fan %s is available again", entry.ID)
+		}
+	}
+}
+
+func isSensorConfigured(config *configuration.Configuration, id string) bool {
+	for _, sensorConfig := range config.Sensors {
+		if sensorConfig.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func isFanConfigured(config *configuration.Configuration, id string) bool {
+	for _, fanConfig := range config.Fans {
+		if fanConfig.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *daemonObjects) startFanController(fan fans.Fan, fanController controller.FanController) *runningTask {
+	return d.start(func(ctx context.Context) {
+		err := fanController.Run(ctx)
+		ui.Info("Fan controller for fan %s stopped.", fan.GetId())
+		if ctx.Err() != nil {
+			if err != nil {
+				ui.WithFan(fan.GetId()).Warning("Error stopping fan controller for fan %s: %v", fan.GetId(), err)
+			}
+			return
+		}
+		// the controller stopped on its own, all other fans keep being controlled,
+		// while this one is quarantined and restarted by the next retry
+		if err == nil {
+			err = errors.New("fan controller stopped")
+		}
+		if quarantine.Add(quarantine.KindFan, fan.GetId(), err) {
+			ui.WithFan(fan.GetId()).ErrorAndNotify(fmt.Sprintf("Fan Controller: %s", fan.GetId()), "Fan %s is quarantined and retried periodically: %v", fan.GetId(), err)
+		} else {
+			ui.WithFan(fan.GetId()).Debug("Fan controller for fan %s failed again: %v", fan.GetId(), err)
+		}
+	})
+}
+
+func (d *daemonObjects) start(run func(ctx context.Context)) *runningTask {
+	ctx, cancel := context.WithCancel(d.ctx)
+	task := &runningTask{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer close(task.done)
+		run(ctx)
+	}()
+
+	return task
+}
+
+// updateCollectors replaces the statistics collectors with ones for the current objects
+func (d *daemonObjects) updateCollectors() {
+	for _, collector := range d.collectors {
+		statistics.Unregister(collector)
+	}
+
+	var sensorList []sensors.Sensor
+	for _, sensor := range sensors.GetSensorMap() {
+		sensorList = append(sensorList, sensor)
+	}
+	var curveList []curves.SpeedCurve
+	for _, curve := range curves.GetSpeedCurveMap() {
+		curveList = append(curveList, curve)
+	}
+	var fanList []fans.Fan
+	for _, fan := range fans.GetFanMap() {
+		fanList = append(fanList, fan)
+	}
+	var fanControllers []controller.FanController
+	for _, fanController := range controller.GetFanControllerMap() {
+		fanControllers = append(fanControllers, fanController)
+	}
+
+	d.collectors = []prometheus.Collector{
+		statistics.NewSensorCollector(sensorList),
+		statistics.NewCurveCollector(curveList),
+		statistics.NewFanCollector(fanList),
+		statistics.NewControllerCollector(fanControllers),
+	}
+	for _, collector := range d.collectors {
+		statistics.Register(collector)
+	}
+}
+
+func createFanController(pers persistence.Persistence, config configuration.FanConfig, fan fans.Fan) controller.FanController {
+	updateRate := configuration.CurrentConfig.ControllerAdjustmentTickRate
+	if config.UpdateRate > 0 {
+		updateRate = config.UpdateRate
+	}
+
+	return controller.NewFanController(pers, fan, *controller.NewPidLoop(config), updateRate)
+}
+
+// resolveSensorConfig fills in the device paths of hwmon based sensors
+func resolveSensorConfig(config *configuration.SensorConfig, controllers []*hwmon.HwMonController) error {
+	if config.HwMon != nil {
+		found := false
+		match := hwmon.DeviceMatch{Platform: config.HwMon.Platform, Name: config.HwMon.Name, Modalias: config.HwMon.Modalias, PciAddress: config.HwMon.PciAddress}
+		for _, c := range controllers {
+			matched, err := match.Matches(c)
+			if err != nil {
+				return fmt.Errorf("sensor %s: %v", config.ID, err)
+			}
+			if matched {
+				found = true
+				config.HwMon.TempInput = c.Sensors[config.HwMon.Index].Input
+			}
+		}
+		if !found {
+			return fmt.Errorf("couldn't find hwmon device with %s for sensor: %s. Run 'fan2go detect' again and correct any mistake", match, config.ID)
+		}
+	}
+
+	if config.AmdGpu != nil {
+		err := hwmon.UpdateAmdGpuSensorConfigFromHwMonControllers(controllers, config)
+		if err != nil {
+			return fmt.Errorf("%v. Run 'fan2go detect' again and correct any mistake", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,321 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/quarantine"
+	"github.com/markusressel/fan2go/internal/sensors"
+	"github.com/markusressel/fan2go/internal/statistics"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTestConfig resets the global configuration and returns a config with a single sensor, curve and fan
+func createTestConfig(dir string, maxTemp int) *configuration.Configuration {
+	configuration.CurrentConfig = configuration.Configuration{
+		TempSensorPollingRate:        100 * time.Millisecond,
+		TempRollingWindowSize:        1,
+		RpmPollingRate:               100 * time.Millisecond,
+		RpmRollingWindowSize:         1,
+		ControllerAdjustmentTickRate: 100 * time.Millisecond,
+	}
+	return createObjectsConfig(dir, maxTemp)
+}
+
+func createObjectsConfig(dir string, maxTemp int) *configuration.Configuration {
+	return &configuration.Configuration{
+		Sensors: []configuration.SensorConfig{
+			{
+				ID:   "cpu",
+				File: &configuration.FileSensorConfig{Path: path.Join(dir, "temp")},
+			},
+		},
+		Curves: []configuration.CurveConfig{
+			{
+				ID: "curve",
+				Linear: &configuration.LinearCurveConfig{
+					Sensor: "cpu",
+					Min:    40,
+					Max:    maxTemp,
+				},
+			},
+		},
+		Fans: []configuration.FanConfig{
+			{
+				ID:    "fan",
+				Curve: "curve",
+				File:  &configuration.FileFanConfig{Path: path.Join(dir, "pwm")},
+			},
+		},
+	}
+}
+
+func stopObjects(cancel context.CancelFunc, objects *daemonObjects) {
+	cancel()
+	objects.wait()
+	for _, collector := range objects.collectors {
+		statistics.Unregister(collector)
+	}
+}
+
+func TestApplyKeepsUnchangedObjects(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	_ = os.WriteFile(path.Join(dir, "temp"), []byte("50000"), 0644)
+	_ = os.WriteFile(path.Join(dir, "pwm"), []byte("100"), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	objects := newDaemonObjects(ctx, persistence.NewPersistence(path.Join(dir, "fan2go.db")))
+	defer stopObjects(cancel, objects)
+
+	err := objects.apply(createTestConfig(dir, 80))
+	assert.NoError(t, err)
+	sensor := sensors.GetSensorMap()["cpu"]
+	curve := curves.GetSpeedCurveMap()["curve"]
+	fan := fans.GetFanMap()["fan"]
+	fanController := controller.GetFanControllerMap()["fan"]
+
+	// WHEN
+	err = objects.apply(createObjectsConfig(dir, 70))
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Same(t, sensor, sensors.GetSensorMap()["cpu"])
+	assert.NotSame(t, curve, curves.GetSpeedCurveMap()["curve"])
+	assert.Equal(t, 70, configuration.CurrentConfig.Curves[0].Linear.Max)
+	assert.Same(t, fan, fans.GetFanMap()["fan"])
+	assert.Same(t, fanController, controller.GetFanControllerMap()["fan"])
+}
+
+func TestApplyInvalidConfigChangesNothing(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	_ = os.WriteFile(path.Join(dir, "temp"), []byte("50000"), 0644)
+	_ = os.WriteFile(path.Join(dir, "pwm"), []byte("100"), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	objects := newDaemonObjects(ctx, persistence.NewPersistence(path.Join(dir, "fan2go.db")))
+	defer stopObjects(cancel, objects)
+
+	err := objects.apply(createTestConfig(dir, 80))
+	assert.NoError(t, err)
+	fan := fans.GetFanMap()["fan"]
+
+	config := createObjectsConfig(dir, 70)
+	config.Fans = nil
+
+	// WHEN
+	err = objects.apply(config)
+
+	// THEN
+	assert.Error(t, err)
+	assert.Same(t, fan, fans.GetFanMap()["fan"])
+	assert.Equal(t, 80, configuration.CurrentConfig.Curves[0].Linear.Max)
+}
+
+func TestApplyQuarantinesMissingDevices(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	_ = os.WriteFile(path.Join(dir, "temp"), []byte("50000"), 0644)
+	_ = os.WriteFile(path.Join(dir, "pwm"), []byte("100"), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	objects := newDaemonObjects(ctx, persistence.NewPersistence(path.Join(dir, "fan2go.db")))
+	defer stopObjects(cancel, objects)
+	defer quarantine.Remove(quarantine.KindSensor, "missing_sensor")
+	defer quarantine.Remove(quarantine.KindFan, "missing_fan")
+
+	config := createTestConfig(dir, 80)
+	config.Sensors = append(config.Sensors, configuration.SensorConfig{
+		ID:    "missing_sensor",
+		HwMon: &configuration.HwMonSensorConfig{Platform: "fan2go-missing-platform", Index: 1},
+	})
+	config.Fans = append(config.Fans, configuration.FanConfig{
+		ID:    "missing_fan",
+		Curve: "curve",
+		HwMon: &configuration.HwMonFanConfig{Platform: "fan2go-missing-platform", Index: 1},
+	})
+
+	// WHEN
+	err := objects.apply(config)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Contains(t, sensors.GetSensorMap(), "cpu")
+	assert.NotContains(t, sensors.GetSensorMap(), "missing_sensor")
+	assert.Contains(t, fans.GetFanMap(), "fan")
+	assert.NotContains(t, fans.GetFanMap(), "missing_fan")
+	assert.Contains(t, controller.GetFanControllerMap(), "fan")
+	assert.True(t, quarantine.Contains(quarantine.KindSensor, "missing_sensor"))
+	assert.True(t, quarantine.Contains(quarantine.KindFan, "missing_fan"))
+}
+
+func TestApplyReleasesRemovedDevicesFromQuarantine(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	_ = os.WriteFile(path.Join(dir, "temp"), []byte("50000"), 0644)
+	_ = os.WriteFile(path.Join(dir, "pwm"), []byte("100"), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	objects := newDaemonObjects(ctx, persistence.NewPersistence(path.Join(dir, "fan2go.db")))
+	defer stopObjects(cancel, objects)
+
+	config := createTestConfig(dir, 80)
+	config.Sensors = append(config.Sensors, configuration.SensorConfig{
+		ID:    "missing_sensor",
+		HwMon: &configuration.HwMonSensorConfig{Platform: "fan2go-missing-platform", Index: 1},
+	})
+	err := objects.apply(config)
+	assert.NoError(t, err)
+	assert.True(t, quarantine.Contains(quarantine.KindSensor, "missing_sensor"))
+
+	// WHEN
+	err = objects.apply(createObjectsConfig(dir, 80))
+
+	// THEN
+	assert.NoError(t, err)
+	assert.False(t, quarantine.Contains(quarantine.KindSensor, "missing_sensor"))
+}
+
+func TestApplyDoesNotModifyConfig(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	root := path.Join(dir, "sys")
+	err := hwmon.WriteFixture(root, []hwmon.FixtureDevice{
+		{
+			Name:  "nct6798",
+			Fans:  map[int]int{1: 1200, 2: 800},
+			Temps: map[int]int{1: 45000},
+		},
+	})
+	assert.NoError(t, err)
+
+	config := createTestConfig(dir, 80)
+	configuration.CurrentConfig.DryRun = true
+	configuration.CurrentConfig.HwMonBackend = configuration.HwMonBackendSysfs
+	configuration.CurrentConfig.SysfsRoot = root
+	defer func() {
+		configuration.CurrentConfig.DryRun = false
+		configuration.CurrentConfig.HwMonBackend = ""
+		configuration.CurrentConfig.SysfsRoot = ""
+	}()
+	config.Sensors = []configuration.SensorConfig{
+		{
+			ID:    "cpu",
+			HwMon: &configuration.HwMonSensorConfig{Platform: "nct6798", Index: 1},
+		},
+	}
+	config.Fans = []configuration.FanConfig{
+		{
+			ID:    "fan",
+			Curve: "curve",
+			HwMon: &configuration.HwMonFanConfig{Platform: "nct6798", RpmChannel: 2},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	objects := newDaemonObjects(ctx, persistence.NewPersistence(path.Join(dir, "fan2go.db")))
+	defer stopObjects(cancel, objects)
+
+	// WHEN
+	err = objects.apply(config)
+	assert.NoError(t, err)
+	fan := fans.GetFanMap()["fan"]
+	err = objects.apply(config)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, config.Sensors[0].HwMon.TempInput)
+	assert.Empty(t, config.Fans[0].HwMon.PwmPath)
+	assert.Equal(t, 0, config.Fans[0].HwMon.Index)
+	assert.Equal(t, path.Join(root, "class", "hwmon", "hwmon0", "pwm2"), fan.GetConfig().HwMon.PwmPath)
+	assert.Same(t, fan, fans.GetFanMap()["fan"])
+}
+
+func TestApplyControlsSysfsFixtureFan(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	root := path.Join(dir, "sys")
+	err := hwmon.WriteFixture(root, []hwmon.FixtureDevice{
+		{
+			Name:  "nct6798",
+			Fans:  map[int]int{1: 1200},
+			Pwms:  map[int]int{1: 100},
+			Temps: map[int]int{1: 80000},
+		},
+	})
+	assert.NoError(t, err)
+
+	config := createTestConfig(dir, 80)
+	configuration.CurrentConfig.HwMonBackend = configuration.HwMonBackendSysfs
+	configuration.CurrentConfig.SysfsRoot = root
+	defer func() {
+		configuration.CurrentConfig.HwMonBackend = ""
+		configuration.CurrentConfig.SysfsRoot = ""
+	}()
+	config.Sensors = []configuration.SensorConfig{
+		{
+			ID:    "cpu",
+			HwMon: &configuration.HwMonSensorConfig{Platform: "nct6798", Index: 1},
+		},
+	}
+	config.Fans = []configuration.FanConfig{
+		{
+			ID:    "fan",
+			Curve: "curve",
+			HwMon: &configuration.HwMonFanConfig{Platform: "nct6798", RpmChannel: 1},
+		},
+	}
+
+	// the fan has been analyzed before, so the controller doesn't run the initialization sequence
+	pers := persistence.NewPersistence(path.Join(dir, "fan2go.db"))
+	pwmMap := map[int]int{}
+	for pwm := fans.MinPwmValue; pwm <= fans.MaxPwmValue; pwm++ {
+		pwmMap[pwm] = pwm
+	}
+	assert.NoError(t, pers.SaveFanPwmMap("fan", pwmMap))
+	curveData := util.InterpolateLinearly(&map[int]float64{0: 0, 255: 2000}, 0, 255)
+	assert.NoError(t, pers.SaveFanPwmData(&fans.HwMonFan{Config: configuration.FanConfig{ID: "fan"}, FanCurveData: &curveData}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	objects := newDaemonObjects(ctx, pers)
+	defer stopObjects(cancel, objects)
+
+	// WHEN
+	err = objects.apply(config)
+
+	// THEN
+	assert.NoError(t, err)
+	pwmPath := path.Join(root, "class", "hwmon", "hwmon0", "pwm1")
+	assert.Eventually(t, func() bool {
+		pwm, err := util.ReadIntFromFile(pwmPath)
+		return err == nil && pwm == fans.MaxPwmValue
+	}, 15*time.Second, 100*time.Millisecond)
+	pwmEnabled, err := util.ReadIntFromFile(pwmPath + "_enable")
+	assert.NoError(t, err)
+	assert.Equal(t, int(fans.ControlModePWM), pwmEnabled)
+}
+
+func TestNextRetryDelay(t *testing.T) {
+	// GIVEN
+	delay := quarantineRetryMinDelay
+
+	// WHEN
+	for i := 0; i < 10; i++ {
+		delay = nextRetryDelay(delay)
+	}
+
+	// THEN
+	assert.Equal(t, quarantineRetryMaxDelay, delay)
+	assert.Equal(t, 2*quarantineRetryMinDelay, nextRetryDelay(quarantineRetryMinDelay))
+}
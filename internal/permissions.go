@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"os"
+
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+// getControlFiles returns the files written to control the given fan,
+// fans which are not controlled via files return none
+func getControlFiles(fan fans.Fan) []string {
+	switch f := fan.(type) {
+	case *fans.HwMonFan:
+		return getHwMonControlFiles(f)
+	case *fans.DellSmmFan:
+		return getHwMonControlFiles(&f.HwMonFan)
+	case *fans.FileFan:
+		return []string{f.Config.File.Path}
+	case *fans.GroupFan:
+		var result []string
+		for _, member := range f.Members {
+			result = append(result, getControlFiles(member)...)
+		}
+		return result
+	}
+	return nil
+}
+
+func getHwMonControlFiles(fan *fans.HwMonFan) []string {
+	result := []string{fan.Config.HwMon.PwmPath}
+	if fan.Supports(fans.FeatureControlMode) {
+		result = append(result, fan.Config.HwMon.PwmEnablePath)
+	}
+	if _, ok := fans.GetConfiguredPwmMode(*fan.Config.HwMon); ok {
+		result = append(result, fan.Config.HwMon.PwmModePath)
+	}
+	return result
+}
+
+// checkWritable opens the given file for writing without actually writing to it
+func checkWritable(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// checkFanAccess verifies that all control files of the given fan are writable by the current user,
+// and logs a warning for each file that isn't
+func checkFanAccess(fan fans.Fan) {
+	for _, path := range getControlFiles(fan) {
+		err := checkWritable(path)
+		if err != nil {
+			ui.WithFan(fan.GetId()).Warning("Fan %s: unable to write %s: %v. Run fan2go as root, or grant write access f.ex. using a udev rule.", fan.GetId(), path, err)
+		}
+	}
+}
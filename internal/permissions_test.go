@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetControlFiles(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	err := os.WriteFile(path.Join(dir, "pwm1_enable"), []byte("1"), 0644)
+	assert.NoError(t, err)
+
+	hwmonFan := &fans.HwMonFan{
+		Config: configuration.FanConfig{
+			ID: "cpu",
+			HwMon: &configuration.HwMonFanConfig{
+				PwmPath:       path.Join(dir, "pwm1"),
+				PwmEnablePath: path.Join(dir, "pwm1_enable"),
+			},
+		},
+	}
+	fileFan := &fans.FileFan{
+		Config: configuration.FanConfig{
+			ID:   "case",
+			File: &configuration.FileFanConfig{Path: path.Join(dir, "case")},
+		},
+	}
+	group := &fans.GroupFan{
+		Members: []fans.Fan{hwmonFan, fileFan},
+	}
+
+	// WHEN
+	result := getControlFiles(group)
+
+	// THEN
+	assert.Equal(t, []string{path.Join(dir, "pwm1"), path.Join(dir, "pwm1_enable"), path.Join(dir, "case")}, result)
+}
+
+func TestCheckWritable(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	file := path.Join(dir, "pwm1")
+	err := os.WriteFile(file, []byte("100"), 0644)
+	assert.NoError(t, err)
+
+	// WHEN
+	existing := checkWritable(file)
+	missing := checkWritable(path.Join(dir, "pwm2"))
+
+	// THEN
+	assert.NoError(t, existing)
+	assert.Error(t, missing)
+	// the file must not be changed by the probe
+	content, _ := os.ReadFile(file)
+	assert.Equal(t, "100", string(content))
+}
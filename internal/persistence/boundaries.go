@@ -0,0 +1,87 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// FanPwmBoundaries are the pwm values of a fan measured during the initialization sequence
+type FanPwmBoundaries struct {
+	// StartPwm is the lowest pwm value which reliably starts the fan from standstill
+	StartPwm int `json:"startPwm"`
+	// MinPwm is the lowest pwm value at which the fan keeps spinning once it is running
+	MinPwm int `json:"minPwm"`
+}
+
+// SaveFanPwmBoundaries saves the measured pwm boundaries of the given fan to persistence
+func (p persistence) SaveFanPwmBoundaries(fanId string, boundaries FanPwmBoundaries) (err error) {
+	db, err := p.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	logger.WithFan(fanId).Debug("Saving pwm boundaries of fan %s to %s", fanId, p.dbPath)
+
+	data, err := json.Marshal(boundaries)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(BucketFanPwmBoundaries))
+		if err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		return b.Put([]byte(fanId), data)
+	})
+}
+
+// LoadFanPwmBoundaries loads the measured pwm boundaries of the given fan,
+// returns os.ErrNotExist if they haven't been measured yet
+func (p persistence) LoadFanPwmBoundaries(fanId string) (FanPwmBoundaries, error) {
+	var boundaries FanPwmBoundaries
+
+	db, err := p.openPersistence()
+	if err != nil {
+		return boundaries, err
+	}
+	defer db.Close()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketFanPwmBoundaries))
+		if b == nil {
+			return os.ErrNotExist
+		}
+		v := b.Get([]byte(fanId))
+		if v == nil {
+			return os.ErrNotExist
+		}
+		err := json.Unmarshal(v, &boundaries)
+		if err != nil {
+			logger.Warning("Unable to unmarshal saved pwm boundaries of fan %s: %v", fanId, err)
+			return os.ErrNotExist
+		}
+		return nil
+	})
+	return boundaries, err
+}
+
+func (p persistence) DeleteFanPwmBoundaries(fanId string) error {
+	db, err := p.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketFanPwmBoundaries))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(fanId))
+	})
+}
@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistence_SaveAndLoadFanPwmBoundaries(t *testing.T) {
+	// GIVEN
+	p := NewPersistence(createDbPath(t))
+	_ = p.DeleteFanPwmBoundaries("cpu")
+
+	// WHEN
+	err := p.SaveFanPwmBoundaries("cpu", FanPwmBoundaries{StartPwm: 60, MinPwm: 40})
+	assert.NoError(t, err)
+
+	// THEN
+	boundaries, err := p.LoadFanPwmBoundaries("cpu")
+	assert.NoError(t, err)
+	assert.Equal(t, FanPwmBoundaries{StartPwm: 60, MinPwm: 40}, boundaries)
+}
+
+func TestPersistence_DeleteFanPwmBoundaries(t *testing.T) {
+	// GIVEN
+	p := NewPersistence(createDbPath(t))
+	_ = p.SaveFanPwmBoundaries("cpu", FanPwmBoundaries{StartPwm: 60, MinPwm: 40})
+
+	// WHEN
+	err := p.DeleteFanPwmBoundaries("cpu")
+
+	// THEN
+	assert.NoError(t, err)
+	_, err = p.LoadFanPwmBoundaries("cpu")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMemoryPersistence_SaveAndLoadFanPwmBoundaries(t *testing.T) {
+	// GIVEN
+	p, _ := NewMemoryPersistence(nil)
+
+	// WHEN
+	err := p.SaveFanPwmBoundaries("cpu", FanPwmBoundaries{StartPwm: 60, MinPwm: 40})
+	assert.NoError(t, err)
+
+	// THEN
+	boundaries, err := p.LoadFanPwmBoundaries("cpu")
+	assert.NoError(t, err)
+	assert.Equal(t, FanPwmBoundaries{StartPwm: 60, MinPwm: 40}, boundaries)
+	_, err = p.LoadFanPwmBoundaries("case")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,197 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/markusressel/fan2go/internal/fans"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ExportVersion is the version of the format written by ExportData
+const ExportVersion = 1
+
+// Export contains the calibration data of all fans in a database
+type Export struct {
+	Version int `json:"version"`
+	// Fans maps from fan id -> calibration data of the fan
+	Fans map[string]FanExport `json:"fans"`
+}
+
+// FanExport contains the calibration data of a single fan
+type FanExport struct {
+	// CurveData maps from pwm -> measured rpm
+	CurveData map[int]float64 `json:"curveData,omitempty"`
+	// PwmMap maps from requested pwm -> pwm actually applied by the fan
+	PwmMap map[int]int `json:"pwmMap,omitempty"`
+	// PwmBoundaries are the startPwm and minPwm measured during the initialization sequence
+	PwmBoundaries *FanPwmBoundaries `json:"pwmBoundaries,omitempty"`
+
+	// StartPwm and MaxPwm are derived from CurveData and only exported for reference,
+	// they are ignored on import
+	StartPwm *int `json:"startPwm,omitempty"`
+	MaxPwm   *int `json:"maxPwm,omitempty"`
+}
+
+// ExportData reads the calibration data of all fans from the database at the given path
+func ExportData(dbPath string) (*Export, error) {
+	db, err := persistence{dbPath: dbPath}.openPersistence()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	result := &Export{
+		Version: ExportVersion,
+		Fans:    map[string]FanExport{},
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket([]byte(BucketFans)); b != nil {
+			err := b.ForEach(func(k, v []byte) error {
+				fan := result.Fans[string(k)]
+				if err := json.Unmarshal(v, &fan.CurveData); err != nil {
+					return fmt.Errorf("unable to read fan curve data of %s: %v", string(k), err)
+				}
+				if len(fan.CurveData) > 0 {
+					startPwm, maxPwm := fans.ComputeCurveBoundaries(fan.CurveData)
+					fan.StartPwm = &startPwm
+					fan.MaxPwm = &maxPwm
+				}
+				result.Fans[string(k)] = fan
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if b := tx.Bucket([]byte(BucketFanPwmMap)); b != nil {
+			err := b.ForEach(func(k, v []byte) error {
+				fan := result.Fans[string(k)]
+				if err := json.Unmarshal(v, &fan.PwmMap); err != nil {
+					return fmt.Errorf("unable to read pwm map of %s: %v", string(k), err)
+				}
+				result.Fans[string(k)] = fan
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if b := tx.Bucket([]byte(BucketFanPwmBoundaries)); b != nil {
+			err := b.ForEach(func(k, v []byte) error {
+				fan := result.Fans[string(k)]
+				if err := json.Unmarshal(v, &fan.PwmBoundaries); err != nil {
+					return fmt.Errorf("unable to read pwm boundaries of %s: %v", string(k), err)
+				}
+				result.Fans[string(k)] = fan
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReadExport reads calibration data from a file written by "fan2go db export"
+func ReadExport(path string) (*Export, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var data Export
+	err = json.Unmarshal(content, &data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return &data, nil
+}
+
+// ImportData writes the calibration data of all fans in the given export to the database at the given path,
+// existing data of these fans is replaced
+func ImportData(dbPath string, data Export) error {
+	if err := ValidateExport(data); err != nil {
+		return err
+	}
+
+	db, err := persistence{dbPath: dbPath}.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		fansBucket, err := tx.CreateBucketIfNotExists([]byte(BucketFans))
+		if err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		pwmMapBucket, err := tx.CreateBucketIfNotExists([]byte(BucketFanPwmMap))
+		if err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		boundariesBucket, err := tx.CreateBucketIfNotExists([]byte(BucketFanPwmBoundaries))
+		if err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+
+		for fanId, fan := range data.Fans {
+			if err := putJson(fansBucket, fanId, fan.CurveData, len(fan.CurveData) <= 0); err != nil {
+				return err
+			}
+			if err := putJson(pwmMapBucket, fanId, fan.PwmMap, len(fan.PwmMap) <= 0); err != nil {
+				return err
+			}
+			if err := putJson(boundariesBucket, fanId, fan.PwmBoundaries, fan.PwmBoundaries == nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// putJson stores the given value as JSON, or deletes the key if the value is empty
+func putJson(b *bolt.Bucket, key string, value interface{}, empty bool) error {
+	if empty {
+		return b.Delete([]byte(key))
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), data)
+}
+
+// ValidateExport checks that the given export can be imported
+func ValidateExport(data Export) error {
+	if data.Version != ExportVersion {
+		return fmt.Errorf("unsupported export version %d, expected %d", data.Version, ExportVersion)
+	}
+	for fanId, fan := range data.Fans {
+		for pwm, rpm := range fan.CurveData {
+			if pwm < fans.MinPwmValue || pwm > fans.MaxPwmValue {
+				return fmt.Errorf("fan %s: curveData: pwm %d out of range [%d..%d]", fanId, pwm, fans.MinPwmValue, fans.MaxPwmValue)
+			}
+			if rpm < 0 {
+				return fmt.Errorf("fan %s: curveData: negative rpm %.0f at pwm %d", fanId, rpm, pwm)
+			}
+		}
+		for requested, actual := range fan.PwmMap {
+			if requested < fans.MinPwmValue || requested > fans.MaxPwmValue || actual < fans.MinPwmValue || actual > fans.MaxPwmValue {
+				return fmt.Errorf("fan %s: pwmMap: entry %d -> %d out of range [%d..%d]", fanId, requested, actual, fans.MinPwmValue, fans.MaxPwmValue)
+			}
+		}
+		if b := fan.PwmBoundaries; b != nil {
+			if b.MinPwm < fans.MinPwmValue || b.StartPwm > fans.MaxPwmValue || b.MinPwm > b.StartPwm {
+				return fmt.Errorf("fan %s: pwmBoundaries: invalid minPwm %d and startPwm %d, must be in range [%d..%d] with minPwm <= startPwm", fanId, b.MinPwm, b.StartPwm, fans.MinPwmValue, fans.MaxPwmValue)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	// GIVEN
+	source := NewPersistence(filepath.Join(t.TempDir(), "source.db"))
+	fan, _ := createFan(false, NeverStoppingFan)
+	assert.NoError(t, source.SaveFanPwmData(fan))
+	assert.NoError(t, source.SaveFanPwmMap(fan.GetId(), map[int]int{0: 0, 128: 130, 255: 255}))
+	assert.NoError(t, source.SaveFanPwmBoundaries(fan.GetId(), FanPwmBoundaries{StartPwm: 60, MinPwm: 40}))
+
+	targetPath := filepath.Join(t.TempDir(), "target.db")
+
+	// WHEN
+	data, err := ExportData(source.(*persistence).dbPath)
+	assert.NoError(t, err)
+	err = ImportData(targetPath, *data)
+	assert.NoError(t, err)
+
+	// THEN
+	exported := data.Fans[fan.GetId()]
+	assert.Equal(t, ExportVersion, data.Version)
+	assert.Equal(t, NeverStoppingFan, exported.CurveData)
+	assert.Equal(t, 0, *exported.StartPwm)
+	assert.Equal(t, 255, *exported.MaxPwm)
+
+	target := NewPersistence(targetPath)
+	curveData, err := target.LoadFanPwmData(fan)
+	assert.NoError(t, err)
+	assert.Equal(t, NeverStoppingFan, curveData)
+	pwmMap, err := target.LoadFanPwmMap(fan.GetId())
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{0: 0, 128: 130, 255: 255}, pwmMap)
+	boundaries, err := target.LoadFanPwmBoundaries(fan.GetId())
+	assert.NoError(t, err)
+	assert.Equal(t, FanPwmBoundaries{StartPwm: 60, MinPwm: 40}, boundaries)
+}
+
+func TestImportRejectsInvalidPwm(t *testing.T) {
+	// GIVEN
+	data := Export{
+		Version: ExportVersion,
+		Fans: map[string]FanExport{
+			"cpu": {CurveData: map[int]float64{300: 1000}},
+		},
+	}
+
+	// WHEN
+	err := ImportData(filepath.Join(t.TempDir(), "fan2go.db"), data)
+
+	// THEN
+	assert.EqualError(t, err, "fan cpu: curveData: pwm 300 out of range [0..255]")
+}
+
+func TestImportRejectsInvalidPwmBoundaries(t *testing.T) {
+	// GIVEN
+	data := Export{
+		Version: ExportVersion,
+		Fans: map[string]FanExport{
+			"cpu": {PwmBoundaries: &FanPwmBoundaries{StartPwm: 40, MinPwm: 60}},
+		},
+	}
+
+	// WHEN
+	err := ImportData(filepath.Join(t.TempDir(), "fan2go.db"), data)
+
+	// THEN
+	assert.EqualError(t, err, "fan cpu: pwmBoundaries: invalid minPwm 60 and startPwm 40, must be in range [0..255] with minPwm <= startPwm")
+}
+
+func TestImportRejectsUnknownVersion(t *testing.T) {
+	// WHEN
+	err := ImportData(filepath.Join(t.TempDir(), "fan2go.db"), Export{Version: 2})
+
+	// THEN
+	assert.EqualError(t, err, "unsupported export version 2, expected 1")
+}
@@ -0,0 +1,159 @@
+package persistence
+
+import (
+	"os"
+	"sync"
+
+	"github.com/markusressel/fan2go/internal/fans"
+)
+
+// memoryPersistence keeps all data in memory only, f.ex. for read-only file systems
+type memoryPersistence struct {
+	lock       sync.Mutex
+	curveData  map[string]map[int]float64
+	pwmMaps    map[string]map[int]int
+	boundaries map[string]FanPwmBoundaries
+	states     map[string]FanState
+}
+
+// NewMemoryPersistence creates a persistence which keeps all data in memory only, so it is lost
+// when fan2go stops. If initial is not nil, its calibration data is used as initial state.
+func NewMemoryPersistence(initial *Export) (Persistence, error) {
+	p := &memoryPersistence{
+		curveData:  map[string]map[int]float64{},
+		pwmMaps:    map[string]map[int]int{},
+		boundaries: map[string]FanPwmBoundaries{},
+		states:     map[string]FanState{},
+	}
+	if initial == nil {
+		return p, nil
+	}
+
+	if err := ValidateExport(*initial); err != nil {
+		return nil, err
+	}
+	for fanId, fan := range initial.Fans {
+		if len(fan.CurveData) > 0 {
+			p.curveData[fanId] = copyCurveData(fan.CurveData)
+		}
+		if len(fan.PwmMap) > 0 {
+			p.pwmMaps[fanId] = copyPwmMap(fan.PwmMap)
+		}
+		if fan.PwmBoundaries != nil {
+			p.boundaries[fanId] = *fan.PwmBoundaries
+		}
+	}
+	return p, nil
+}
+
+func (p *memoryPersistence) SaveFanPwmData(fan fans.Fan) (err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.curveData[fan.GetId()] = copyCurveData(*fan.GetFanCurveData())
+	return nil
+}
+
+func (p *memoryPersistence) LoadFanPwmData(fan fans.Fan) (map[int]float64, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	data, ok := p.curveData[fan.GetId()]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return copyCurveData(data), nil
+}
+
+func (p *memoryPersistence) DeleteFanPwmData(fan fans.Fan) (err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.curveData, fan.GetId())
+	return nil
+}
+
+func (p *memoryPersistence) SaveFanPwmMap(fanId string, pwmMap map[int]int) (err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.pwmMaps[fanId] = copyPwmMap(pwmMap)
+	return nil
+}
+
+func (p *memoryPersistence) LoadFanPwmMap(fanId string) (map[int]int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	pwmMap, ok := p.pwmMaps[fanId]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return copyPwmMap(pwmMap), nil
+}
+
+func (p *memoryPersistence) DeleteFanPwmMap(fanId string) (err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.pwmMaps, fanId)
+	return nil
+}
+
+func (p *memoryPersistence) SaveFanPwmBoundaries(fanId string, boundaries FanPwmBoundaries) (err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.boundaries[fanId] = boundaries
+	return nil
+}
+
+func (p *memoryPersistence) LoadFanPwmBoundaries(fanId string) (FanPwmBoundaries, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	boundaries, ok := p.boundaries[fanId]
+	if !ok {
+		return FanPwmBoundaries{}, os.ErrNotExist
+	}
+	return boundaries, nil
+}
+
+func (p *memoryPersistence) DeleteFanPwmBoundaries(fanId string) (err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.boundaries, fanId)
+	return nil
+}
+
+func (p *memoryPersistence) SaveFanState(fanId string, state FanState) (err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.states[fanId] = state
+	return nil
+}
+
+func (p *memoryPersistence) LoadFanStates() (map[string]FanState, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	result := make(map[string]FanState, len(p.states))
+	for fanId, state := range p.states {
+		result[fanId] = state
+	}
+	return result, nil
+}
+
+func (p *memoryPersistence) DeleteFanState(fanId string) (err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.states, fanId)
+	return nil
+}
+
+func copyCurveData(data map[int]float64) map[int]float64 {
+	result := make(map[int]float64, len(data))
+	for pwm, rpm := range data {
+		result[pwm] = rpm
+	}
+	return result
+}
+
+func copyPwmMap(pwmMap map[int]int) map[int]int {
+	result := make(map[int]int, len(pwmMap))
+	for requested, actual := range pwmMap {
+		result[requested] = actual
+	}
+	return result
+}
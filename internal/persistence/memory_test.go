@@ -0,0 +1,66 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryPersistence_SaveAndLoadFanPwmData(t *testing.T) {
+	// GIVEN
+	p, err := NewMemoryPersistence(nil)
+	assert.NoError(t, err)
+	fan, _ := createFan(false, map[int]float64{0: 0, 255: 255})
+
+	// WHEN
+	err = p.SaveFanPwmData(fan)
+	assert.NoError(t, err)
+	// changes to the fan after saving must not affect the saved data
+	(*fan.GetFanCurveData())[128] = 1000
+
+	// THEN
+	data, err := p.LoadFanPwmData(fan)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]float64{0: 0, 255: 255}, data)
+}
+
+func TestMemoryPersistence_DeleteFanPwmMap(t *testing.T) {
+	// GIVEN
+	p, _ := NewMemoryPersistence(nil)
+	_ = p.SaveFanPwmMap("cpu", map[int]int{0: 0, 255: 255})
+
+	// WHEN
+	err := p.DeleteFanPwmMap("cpu")
+
+	// THEN
+	assert.NoError(t, err)
+	pwmMap, err := p.LoadFanPwmMap("cpu")
+	assert.Nil(t, pwmMap)
+	assert.Error(t, err)
+}
+
+func TestMemoryPersistence_InitialData(t *testing.T) {
+	// GIVEN
+	initial := &Export{
+		Version: ExportVersion,
+		Fans: map[string]FanExport{
+			"fan1": {
+				CurveData: NeverStoppingFan,
+				PwmMap:    map[int]int{0: 0, 255: 255},
+			},
+		},
+	}
+	fan, _ := createFan(false, LinearFan)
+
+	// WHEN
+	p, err := NewMemoryPersistence(initial)
+
+	// THEN
+	assert.NoError(t, err)
+	data, err := p.LoadFanPwmData(fan)
+	assert.NoError(t, err)
+	assert.Equal(t, NeverStoppingFan, data)
+	pwmMap, err := p.LoadFanPwmMap("fan1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{0: 0, 255: 255}, pwmMap)
+}
@@ -13,8 +13,15 @@ import (
 const (
 	BucketFans      = "fans"
 	BucketFanPwmMap = "fanPwmMap"
+	// BucketFanState contains the state of all fans at the time fan2go took control of them
+	BucketFanState = "fanState"
+	// BucketFanPwmBoundaries contains the startPwm and minPwm of all fans measured during the initialization sequence
+	BucketFanPwmBoundaries = "fanPwmBoundaries"
 )
 
+// logger is used for all messages of the persistence
+var logger = ui.ForSubsystem(ui.SubsystemPersistence)
+
 type Persistence interface {
 	LoadFanPwmData(fan fans.Fan) (map[int]float64, error)
 	SaveFanPwmData(fan fans.Fan) (err error)
@@ -23,6 +30,14 @@ type Persistence interface {
 	LoadFanPwmMap(fanId string) (map[int]int, error)
 	SaveFanPwmMap(fanId string, pwmMap map[int]int) (err error)
 	DeleteFanPwmMap(fanId string) (err error)
+
+	LoadFanPwmBoundaries(fanId string) (FanPwmBoundaries, error)
+	SaveFanPwmBoundaries(fanId string, boundaries FanPwmBoundaries) (err error)
+	DeleteFanPwmBoundaries(fanId string) (err error)
+
+	LoadFanStates() (map[string]FanState, error)
+	SaveFanState(fanId string, state FanState) (err error)
+	DeleteFanState(fanId string) (err error)
 }
 
 type persistence struct {
@@ -53,6 +68,7 @@ func (p persistence) SaveFanPwmData(fan fans.Fan) (err error) {
 	defer db.Close()
 
 	key := fan.GetId()
+	logger.WithFan(key).Debug("Saving fan curve data of fan %s to %s", key, p.dbPath)
 
 	// convert the curve data moving window to a map to arrays, so we can persist them
 	fanCurveDataMap := map[int]float64{}
@@ -99,10 +115,10 @@ func (p persistence) LoadFanPwmData(fan fans.Fan) (map[int]float64, error) {
 		err := json.Unmarshal(v, &fanCurveDataMap)
 		if err != nil {
 			// if we cannot read the saved data, delete it
-			ui.Warning("Unable to unmarshal saved fan data for %s: %v", key, err)
+			logger.Warning("Unable to unmarshal saved fan data for %s: %v", key, err)
 			err := b.Delete([]byte(key))
 			if err != nil {
-				ui.Error("Unable to delete corrupt data key %s: %v", key, err)
+				logger.Error("Unable to delete corrupt data key %s: %v", key, err)
 			}
 			return nil
 		}
@@ -147,6 +163,7 @@ func (p persistence) SaveFanPwmMap(fanId string, pwmMap map[int]int) (err error)
 	defer db.Close()
 
 	key := fanId
+	logger.WithFan(key).Debug("Saving pwm map of fan %s to %s", key, p.dbPath)
 
 	// convert the curve data moving window to a map to arrays, so we can persist them
 	for key, value := range pwmMap {
@@ -192,10 +209,10 @@ func (p persistence) LoadFanPwmMap(fanId string) (map[int]int, error) {
 		err := json.Unmarshal(v, &pwmMap)
 		if err != nil {
 			// if we cannot read the saved data, delete it
-			ui.Warning("Unable to unmarshal saved pwmMap data for %s: %v", key, err)
+			logger.Warning("Unable to unmarshal saved pwmMap data for %s: %v", key, err)
 			err := b.Delete([]byte(key))
 			if err != nil {
-				ui.Error("Unable to delete corrupt data key %s: %v", key, err)
+				logger.Error("Unable to delete corrupt data key %s: %v", key, err)
 			}
 			return nil
 		}
@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/markusressel/fan2go/internal/configuration"
@@ -9,10 +10,6 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-const (
-	dbTestingPath = "./test.db"
-)
-
 var (
 	LinearFan = map[int]float64{
 		0:   0.0,
@@ -28,7 +25,7 @@ var (
 
 func TestPersistence_DeleteFanPwmData(t *testing.T) {
 	// GIVEN
-	p := NewPersistence(dbTestingPath)
+	p := NewPersistence(createDbPath(t))
 	fan, _ := createFan(false, LinearFan)
 	_ = p.SaveFanPwmData(fan)
 
@@ -44,7 +41,7 @@ func TestPersistence_DeleteFanPwmData(t *testing.T) {
 
 func TestPersistence_SaveFanPwmData_LinearFanInterpolated(t *testing.T) {
 	// GIVEN
-	p := NewPersistence(dbTestingPath)
+	p := NewPersistence(createDbPath(t))
 
 	expected := util.InterpolateLinearly(&LinearFan, 0, 255)
 	fan, _ := createFan(false, expected)
@@ -58,7 +55,7 @@ func TestPersistence_SaveFanPwmData_LinearFanInterpolated(t *testing.T) {
 
 func TestPersistence_LoadFanPwmData_LinearFanInterpolated(t *testing.T) {
 	// GIVEN
-	persistence := NewPersistence(dbTestingPath)
+	persistence := NewPersistence(createDbPath(t))
 
 	expected := util.InterpolateLinearly(&LinearFan, 0, 255)
 	fan, _ := createFan(false, expected)
@@ -77,7 +74,7 @@ func TestPersistence_LoadFanPwmData_LinearFanInterpolated(t *testing.T) {
 
 func TestPersistence_SaveFanPwmData_SamplesNotInterpolated(t *testing.T) {
 	// GIVEN
-	p := NewPersistence(dbTestingPath)
+	p := NewPersistence(createDbPath(t))
 
 	expected := NeverStoppingFan
 	fan, _ := createFan(false, expected)
@@ -91,7 +88,7 @@ func TestPersistence_SaveFanPwmData_SamplesNotInterpolated(t *testing.T) {
 
 func TestPersistence_LoadFanPwmData_SamplesNotInterpolated(t *testing.T) {
 	// GIVEN
-	persistence := NewPersistence(dbTestingPath)
+	persistence := NewPersistence(createDbPath(t))
 
 	expected := NeverStoppingFan
 	fan, _ := createFan(false, expected)
@@ -108,6 +105,11 @@ func TestPersistence_LoadFanPwmData_SamplesNotInterpolated(t *testing.T) {
 	assert.Equal(t, expected, fanData)
 }
 
+// createDbPath returns the path of a database in a temporary directory, which is removed after the test
+func createDbPath(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "fan2go.db")
+}
+
 func createFan(neverStop bool, curveData map[int]float64) (fan fans.Fan, err error) {
 	configuration.CurrentConfig.RpmRollingWindowSize = 10
 
@@ -122,7 +124,7 @@ func createFan(neverStop bool, curveData map[int]float64) (fan fans.Fan, err err
 			Curve:     "curve",
 		},
 	}
-	fans.FanMap[fan.GetId()] = fan
+	fans.SetFan(fan.GetId(), fan)
 
 	err = fan.AttachFanCurveData(&curveData)
 
@@ -0,0 +1,87 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// FanState is the state of a fan at the time fan2go took control of it. It is stored while fan2go
+// controls the fan and deleted once the state was restored, so an entry that is still present on
+// the next start means that fan2go didn't exit cleanly.
+type FanState struct {
+	Pwm int `json:"pwm"`
+	// PwmEnabled is the original pwm_enable value, nil if the fan doesn't support it
+	PwmEnabled *int `json:"pwmEnabled,omitempty"`
+	// PwmMode is the original pwmN_mode value, nil if fan2go doesn't change it
+	PwmMode *int `json:"pwmMode,omitempty"`
+}
+
+// SaveFanState saves the original state of the given fan to persistence
+func (p persistence) SaveFanState(fanId string, state FanState) (err error) {
+	db, err := p.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	logger.WithFan(fanId).Debug("Saving original state of fan %s to %s", fanId, p.dbPath)
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(BucketFanState))
+		if err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		return b.Put([]byte(fanId), data)
+	})
+}
+
+// LoadFanStates loads the original states of all fans that haven't been restored yet
+func (p persistence) LoadFanStates() (map[string]FanState, error) {
+	db, err := p.openPersistence()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	result := map[string]FanState{}
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketFanState))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var state FanState
+			err := json.Unmarshal(v, &state)
+			if err != nil {
+				logger.Warning("Unable to unmarshal saved state of fan %s: %v", string(k), err)
+				return nil
+			}
+			result[string(k)] = state
+			return nil
+		})
+	})
+	return result, err
+}
+
+func (p persistence) DeleteFanState(fanId string) error {
+	db, err := p.openPersistence()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(BucketFanState))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(fanId))
+	})
+}
@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistence_SaveAndLoadFanStates(t *testing.T) {
+	// GIVEN
+	p := NewPersistence(createDbPath(t))
+	pwmEnabled := 2
+	_ = p.DeleteFanState("cpu")
+	_ = p.DeleteFanState("case")
+
+	// WHEN
+	err := p.SaveFanState("cpu", FanState{Pwm: 100, PwmEnabled: &pwmEnabled})
+	assert.NoError(t, err)
+	err = p.SaveFanState("case", FanState{Pwm: 50})
+	assert.NoError(t, err)
+
+	// THEN
+	states, err := p.LoadFanStates()
+	assert.NoError(t, err)
+	assert.Equal(t, FanState{Pwm: 100, PwmEnabled: &pwmEnabled}, states["cpu"])
+	assert.Equal(t, FanState{Pwm: 50}, states["case"])
+}
+
+func TestPersistence_DeleteFanState(t *testing.T) {
+	// GIVEN
+	p := NewPersistence(createDbPath(t))
+	_ = p.SaveFanState("cpu", FanState{Pwm: 100})
+
+	// WHEN
+	err := p.DeleteFanState("cpu")
+
+	// THEN
+	assert.NoError(t, err)
+	states, err := p.LoadFanStates()
+	assert.NoError(t, err)
+	assert.NotContains(t, states, "cpu")
+}
+
+func TestMemoryPersistence_DeleteFanState(t *testing.T) {
+	// GIVEN
+	p, _ := NewMemoryPersistence(nil)
+	_ = p.SaveFanState("cpu", FanState{Pwm: 100})
+	_ = p.SaveFanState("case", FanState{Pwm: 50})
+
+	// WHEN
+	err := p.DeleteFanState("cpu")
+
+	// THEN
+	assert.NoError(t, err)
+	states, err := p.LoadFanStates()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]FanState{"case": {Pwm: 50}}, states)
+}
@@ -0,0 +1,183 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+const (
+	// ExecutablePrefix is the prefix of the file name of all plugin executables,
+	// f.ex. fan2go-plugin-corsair for the plugin "corsair"
+	ExecutablePrefix = "fan2go-plugin-"
+
+	// DefaultTimeout is the time a plugin is allowed to run if no timeout is configured
+	DefaultTimeout = 5 * time.Second
+
+	ActionDescribe = "describe"
+	ActionGetValue = "getValue"
+	ActionGetRpm   = "getRpm"
+	ActionGetPwm   = "getPwm"
+	ActionSetPwm   = "setPwm"
+)
+
+// Plugin is an executable providing sensors and/or fans
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// Request is passed to the plugin as a JSON encoded argument following the action
+type Request struct {
+	// Options are the options of the sensor or fan as configured by the user
+	Options map[string]interface{} `json:"options"`
+	// Pwm is the value to set, only used by the setPwm action
+	Pwm *int `json:"pwm,omitempty"`
+}
+
+// Response is printed by the plugin as JSON to stdout
+type Response struct {
+	// Value is the result of the getValue, getRpm and getPwm actions
+	Value *float64 `json:"value,omitempty"`
+	// Error is a message describing why the action failed, if it did
+	Error string `json:"error,omitempty"`
+}
+
+// Description is printed by the plugin as JSON to stdout for the describe action
+type Description struct {
+	// Description is a short, human-readable description of the plugin
+	Description string `json:"description"`
+	// Sensor is true if the plugin supports the getValue action
+	Sensor bool `json:"sensor"`
+	// Fan is true if the plugin supports the setPwm action
+	Fan bool `json:"fan"`
+	// Rpm is true if the plugin supports the getRpm action
+	Rpm bool `json:"rpm"`
+	// Pwm is true if the plugin supports the getPwm action
+	Pwm bool `json:"pwm"`
+}
+
+// Find returns the plugin with the given name in the given directory
+func Find(dir string, name string) (Plugin, error) {
+	if len(name) <= 0 || strings.Contains(name, "/") {
+		return Plugin{}, fmt.Errorf("invalid plugin name '%s'", name)
+	}
+	file := path.Join(dir, ExecutablePrefix+name)
+	info, err := os.Stat(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Plugin{}, fmt.Errorf("plugin %s not found in %s", name, dir)
+		}
+		return Plugin{}, err
+	}
+	if info.IsDir() {
+		return Plugin{}, fmt.Errorf("plugin %s: %s is a directory", name, file)
+	}
+	return Plugin{Name: name, Path: file}, nil
+}
+
+// List returns all plugins in the given directory, sorted by name
+func List(dir string) ([]Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var result []Plugin
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), ExecutablePrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(entry.Name(), ExecutablePrefix)
+		if len(name) <= 0 {
+			continue
+		}
+		result = append(result, Plugin{Name: name, Path: path.Join(dir, entry.Name())})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// Describe returns the capabilities of the plugin
+func (plugin Plugin) Describe(timeout time.Duration) (Description, error) {
+	output, err := plugin.run(ActionDescribe, Request{}, timeout)
+	if err != nil {
+		return Description{}, err
+	}
+
+	var description Description
+	err = json.Unmarshal([]byte(output), &description)
+	if err != nil {
+		return Description{}, fmt.Errorf("plugin %s: invalid response: %v", plugin.Name, err)
+	}
+	return description, nil
+}
+
+// GetValue runs the given action (getValue, getRpm or getPwm) and returns the value it reported
+func (plugin Plugin) GetValue(action string, options map[string]interface{}, timeout time.Duration) (float64, error) {
+	response, err := plugin.call(action, Request{Options: options}, timeout)
+	if err != nil {
+		return 0, err
+	}
+	if response.Value == nil {
+		return 0, fmt.Errorf("plugin %s: %s: response contains no value", plugin.Name, action)
+	}
+	return *response.Value, nil
+}
+
+// SetPwm runs the setPwm action with the given value
+func (plugin Plugin) SetPwm(options map[string]interface{}, pwm int, timeout time.Duration) error {
+	_, err := plugin.call(ActionSetPwm, Request{Options: options, Pwm: &pwm}, timeout)
+	return err
+}
+
+func (plugin Plugin) call(action string, request Request, timeout time.Duration) (Response, error) {
+	output, err := plugin.run(action, request, timeout)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var response Response
+	if len(strings.TrimSpace(output)) > 0 {
+		err = json.Unmarshal([]byte(output), &response)
+		if err != nil {
+			return Response{}, fmt.Errorf("plugin %s: %s: invalid response: %v", plugin.Name, action, err)
+		}
+	}
+	if len(response.Error) > 0 {
+		return Response{}, fmt.Errorf("plugin %s: %s: %s", plugin.Name, action, response.Error)
+	}
+	return response, nil
+}
+
+// run executes the plugin as "<executable> <action> <request>" and returns its output
+func (plugin Plugin) run(action string, request Request, timeout time.Duration) (string, error) {
+	if _, err := os.Stat(plugin.Path); err != nil {
+		// checked here, since the permission check can't handle missing files
+		return "", fmt.Errorf("plugin %s: %v", plugin.Name, err)
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	output, err := util.SafeCmdExecution(plugin.Path, []string{action, string(data)}, timeout)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s: %s: %v", plugin.Name, action, err)
+	}
+	return output, nil
+}
@@ -0,0 +1,116 @@
+package plugins
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPlugin = `#!/bin/sh
+case "$1" in
+  describe) echo '{"description": "test plugin", "sensor": true, "fan": true, "rpm": true}' ;;
+  getValue) echo '{"value": 42000}' ;;
+  getRpm) echo '{"error": "no rpm sensor"}' ;;
+  setPwm) echo "$2" > "$(dirname "$0")/last_request" ;;
+  *) exit 1 ;;
+esac
+`
+
+func createTestPlugin(t *testing.T, name string, content string) string {
+	dir := t.TempDir()
+	err := os.WriteFile(path.Join(dir, ExecutablePrefix+name), []byte(content), 0755)
+	assert.NoError(t, err)
+	return dir
+}
+
+func TestFind(t *testing.T) {
+	// GIVEN
+	dir := createTestPlugin(t, "test", testPlugin)
+
+	// WHEN
+	plugin, err := Find(dir, "test")
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, Plugin{Name: "test", Path: path.Join(dir, "fan2go-plugin-test")}, plugin)
+
+	// WHEN
+	_, err = Find(dir, "missing")
+
+	// THEN
+	assert.EqualError(t, err, "plugin missing not found in "+dir)
+}
+
+func TestList(t *testing.T) {
+	// GIVEN
+	dir := createTestPlugin(t, "b", testPlugin)
+	err := os.WriteFile(path.Join(dir, ExecutablePrefix+"a"), []byte(testPlugin), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(path.Join(dir, "README"), []byte{}, 0644)
+	assert.NoError(t, err)
+
+	// WHEN
+	result, err := List(dir)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []Plugin{
+		{Name: "a", Path: path.Join(dir, "fan2go-plugin-a")},
+		{Name: "b", Path: path.Join(dir, "fan2go-plugin-b")},
+	}, result)
+}
+
+func TestDescribe(t *testing.T) {
+	// GIVEN
+	dir := createTestPlugin(t, "test", testPlugin)
+	plugin, _ := Find(dir, "test")
+
+	// WHEN
+	description, err := plugin.Describe(0)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, Description{Description: "test plugin", Sensor: true, Fan: true, Rpm: true}, description)
+}
+
+func TestGetValue(t *testing.T) {
+	// GIVEN
+	dir := createTestPlugin(t, "test", testPlugin)
+	plugin, _ := Find(dir, "test")
+
+	// WHEN
+	value, err := plugin.GetValue(ActionGetValue, map[string]interface{}{"channel": 1}, 0)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 42000.0, value)
+}
+
+func TestGetValueError(t *testing.T) {
+	// GIVEN
+	dir := createTestPlugin(t, "test", testPlugin)
+	plugin, _ := Find(dir, "test")
+
+	// WHEN
+	_, err := plugin.GetValue(ActionGetRpm, map[string]interface{}{"channel": 1}, 0)
+
+	// THEN
+	assert.EqualError(t, err, "plugin test: getRpm: no rpm sensor")
+}
+
+func TestSetPwm(t *testing.T) {
+	// GIVEN
+	dir := createTestPlugin(t, "test", testPlugin)
+	plugin, _ := Find(dir, "test")
+
+	// WHEN
+	err := plugin.SetPwm(map[string]interface{}{"channel": 1}, 128, 0)
+
+	// THEN
+	assert.NoError(t, err)
+	request, err := os.ReadFile(path.Join(dir, "last_request"))
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"options\":{\"channel\":1},\"pwm\":128}\n", string(request))
+}
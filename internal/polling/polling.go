@@ -0,0 +1,110 @@
+package polling
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+var (
+	lock sync.Mutex
+	// calmSince is the time since which all sensors are stable and no fan was adjusted
+	calmSince = time.Now()
+	// lastValues maps from sensor id -> last reported value of the sensor
+	lastValues = map[string]float64{}
+	// wakeup is closed and replaced whenever a rapid temperature change is detected
+	wakeup = make(chan struct{})
+
+	// now returns the current time, replaced in tests
+	now = time.Now
+)
+
+// Interval returns the interval to wait for before the next poll or update of a loop with the
+// given configured interval. While temperatures are stable and fans idle, the interval is
+// lengthened linearly up to adaptivePolling.maxInterval, it is never shorter than base.
+func Interval(base time.Duration) time.Duration {
+	config := configuration.CurrentConfig.AdaptivePolling
+	if !config.Enabled || config.MaxInterval <= base || config.RampUp <= 0 {
+		return base
+	}
+
+	lock.Lock()
+	calm := now().Sub(calmSince)
+	lock.Unlock()
+
+	if calm >= config.RampUp {
+		return config.MaxInterval
+	}
+	progress := float64(calm) / float64(config.RampUp)
+	return base + time.Duration(progress*float64(config.MaxInterval-base))
+}
+
+// Wakeup returns a channel that is closed on the next rapid temperature change,
+// loops waiting for their next interval should poll or update again immediately then
+func Wakeup() <-chan struct{} {
+	lock.Lock()
+	defer lock.Unlock()
+	return wakeup
+}
+
+// Wait blocks until the interval returned by Interval for the given configured interval has passed,
+// or a rapid temperature change was detected. It returns false if ctx is done.
+func Wait(ctx context.Context, base time.Duration) bool {
+	timer := time.NewTimer(Interval(base))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	case <-Wakeup():
+		return true
+	}
+}
+
+// ReportSensorValue compares the given value (in milli-degrees) of a sensor with its last value,
+// any change larger than adaptivePolling.stableDelta resets all intervals to their configured rate
+func ReportSensorValue(sensorId string, value float64) {
+	config := configuration.CurrentConfig.AdaptivePolling
+	if !config.Enabled {
+		return
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	last, ok := lastValues[sensorId]
+	lastValues[sensorId] = value
+	if !ok {
+		return
+	}
+
+	delta := math.Abs(value-last) / 1000
+	if delta > config.StableDelta {
+		calmSince = now()
+	}
+	if delta >= config.RapidDelta {
+		close(wakeup)
+		wakeup = make(chan struct{})
+	}
+}
+
+// ReportFanActivity resets all intervals to their configured rate, since a fan was adjusted
+func ReportFanActivity() {
+	if !configuration.CurrentConfig.AdaptivePolling.Enabled {
+		return
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	calmSince = now()
+}
+
+// Clear removes the last value of the given sensor, f.ex. when it is removed
+func Clear(sensorId string) {
+	lock.Lock()
+	defer lock.Unlock()
+	delete(lastValues, sensorId)
+}
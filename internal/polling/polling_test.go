@@ -0,0 +1,109 @@
+package polling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+// useClock enables adaptive polling and replaces the clock with one returning the given time
+func useClock(t *testing.T, current *time.Time) {
+	configuration.CurrentConfig.AdaptivePolling = configuration.AdaptivePollingConfig{
+		Enabled:     true,
+		MaxInterval: 2 * time.Second,
+		RampUp:      1 * time.Minute,
+		StableDelta: 0.5,
+		RapidDelta:  2,
+	}
+	now = func() time.Time {
+		return *current
+	}
+	calmSince = *current
+	t.Cleanup(func() {
+		configuration.CurrentConfig.AdaptivePolling = configuration.AdaptivePollingConfig{}
+		now = time.Now
+		lastValues = map[string]float64{}
+	})
+}
+
+func TestIntervalDisabled(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.AdaptivePolling = configuration.AdaptivePollingConfig{}
+
+	// WHEN
+	result := Interval(200 * time.Millisecond)
+
+	// THEN
+	assert.Equal(t, 200*time.Millisecond, result)
+}
+
+func TestIntervalLengthensWhileStable(t *testing.T) {
+	// GIVEN
+	current := time.Unix(1000, 0)
+	useClock(t, &current)
+	ReportSensorValue("cpu", 50000)
+
+	// WHEN
+	current = current.Add(30 * time.Second)
+	ReportSensorValue("cpu", 50300)
+	halfway := Interval(200 * time.Millisecond)
+	current = current.Add(1 * time.Minute)
+	rampedUp := Interval(200 * time.Millisecond)
+	longBase := Interval(5 * time.Second)
+
+	// THEN
+	assert.Equal(t, 1100*time.Millisecond, halfway)
+	assert.Equal(t, 2*time.Second, rampedUp)
+	assert.Equal(t, 5*time.Second, longBase)
+}
+
+func TestIntervalResetOnTemperatureChange(t *testing.T) {
+	// GIVEN
+	current := time.Unix(1000, 0)
+	useClock(t, &current)
+	ReportSensorValue("cpu", 50000)
+	current = current.Add(2 * time.Minute)
+
+	// WHEN
+	ReportSensorValue("cpu", 51000)
+
+	// THEN
+	assert.Equal(t, 200*time.Millisecond, Interval(200*time.Millisecond))
+}
+
+func TestIntervalResetOnFanActivity(t *testing.T) {
+	// GIVEN
+	current := time.Unix(1000, 0)
+	useClock(t, &current)
+	current = current.Add(2 * time.Minute)
+
+	// WHEN
+	ReportFanActivity()
+
+	// THEN
+	assert.Equal(t, 200*time.Millisecond, Interval(200*time.Millisecond))
+}
+
+func TestWakeupOnRapidTemperatureChange(t *testing.T) {
+	// GIVEN
+	current := time.Unix(1000, 0)
+	useClock(t, &current)
+	ReportSensorValue("cpu", 50000)
+	moderate := Wakeup()
+	ReportSensorValue("cpu", 51000)
+	rapid := Wakeup()
+
+	// WHEN
+	ReportSensorValue("cpu", 55000)
+
+	// THEN
+	assert.Equal(t, moderate, rapid)
+	select {
+	case <-rapid:
+	default:
+		assert.Fail(t, "expected wakeup channel to be closed")
+	}
+	assert.NotEqual(t, rapid, Wakeup())
+}
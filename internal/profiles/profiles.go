@@ -0,0 +1,77 @@
+package profiles
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+var (
+	// active is the id of the currently active profile, empty if none is active
+	active     string
+	activeLock sync.RWMutex
+)
+
+// GetActive returns the id of the currently active profile, empty if none is active
+func GetActive() string {
+	activeLock.RLock()
+	defer activeLock.RUnlock()
+	return active
+}
+
+// SetActive activates the profile with the given id, an empty id deactivates the current profile
+func SetActive(id string) error {
+	if len(id) > 0 && findProfile(id) == nil {
+		return fmt.Errorf("no profile with id '%s' found", id)
+	}
+	activeLock.Lock()
+	defer activeLock.Unlock()
+	active = id
+	return nil
+}
+
+// GetCurveId returns the id of the curve the given fan should currently use
+func GetCurveId(fanId string, defaultCurveId string) string {
+	settings := getFanSettings(fanId)
+	if settings == nil || len(settings.Curve) <= 0 {
+		return defaultCurveId
+	}
+	return settings.Curve
+}
+
+// GetMaxPwm returns the pwm cap of the active profile for the given fan, if any
+func GetMaxPwm(fanId string) (int, bool) {
+	settings := getFanSettings(fanId)
+	if settings == nil || settings.MaxPwm == nil {
+		return 0, false
+	}
+	return *settings.MaxPwm, true
+}
+
+// getFanSettings returns the settings of the active profile for the given fan, if any
+func getFanSettings(fanId string) *configuration.ProfileFanConfig {
+	id := GetActive()
+	if len(id) <= 0 {
+		return nil
+	}
+	profile := findProfile(id)
+	if profile == nil {
+		return nil
+	}
+	for _, settings := range profile.Fans {
+		if settings.Fan == fanId {
+			return &settings
+		}
+	}
+	return nil
+}
+
+func findProfile(id string) *configuration.ProfileConfig {
+	for _, profile := range configuration.GetProfiles() {
+		if profile.ID == id {
+			return &profile
+		}
+	}
+	return nil
+}
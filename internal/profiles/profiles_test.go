@@ -0,0 +1,67 @@
+package profiles
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func createProfiles() {
+	maxPwm := 120
+	configuration.CurrentConfig.Profiles = []configuration.ProfileConfig{
+		{
+			ID: "silent",
+			Fans: []configuration.ProfileFanConfig{
+				{Fan: "cpu", Curve: "cpu_silent"},
+				{Fan: "case", MaxPwm: &maxPwm},
+			},
+		},
+	}
+}
+
+func TestNoActiveProfile(t *testing.T) {
+	// GIVEN
+	createProfiles()
+	_ = SetActive("")
+
+	// WHEN
+	curveId := GetCurveId("cpu", "cpu_curve")
+	_, capped := GetMaxPwm("case")
+
+	// THEN
+	assert.Equal(t, "cpu_curve", curveId)
+	assert.False(t, capped)
+}
+
+func TestActiveProfile(t *testing.T) {
+	// GIVEN
+	createProfiles()
+
+	// WHEN
+	err := SetActive("silent")
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "silent", GetActive())
+	assert.Equal(t, "cpu_silent", GetCurveId("cpu", "cpu_curve"))
+	assert.Equal(t, "case_curve", GetCurveId("case", "case_curve"))
+	maxPwm, capped := GetMaxPwm("case")
+	assert.True(t, capped)
+	assert.Equal(t, 120, maxPwm)
+	_, capped = GetMaxPwm("cpu")
+	assert.False(t, capped)
+}
+
+func TestSetUnknownProfile(t *testing.T) {
+	// GIVEN
+	createProfiles()
+	_ = SetActive("silent")
+
+	// WHEN
+	err := SetActive("unknown")
+
+	// THEN
+	assert.Error(t, err)
+	assert.Equal(t, "silent", GetActive())
+}
@@ -0,0 +1,87 @@
+package quarantine
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	KindSensor = "sensor"
+	KindFan    = "fan"
+)
+
+// Entry is a sensor or fan which couldn't be created or whose controller failed,
+// it is left out while all other devices keep being controlled, and retried periodically
+type Entry struct {
+	// Kind of the object, one of: sensor | fan
+	Kind  string    `json:"kind"`
+	ID    string    `json:"id"`
+	Error string    `json:"error"`
+	Since time.Time `json:"since"`
+	// Retries is the number of failed attempts since the object was quarantined
+	Retries int `json:"retries"`
+}
+
+var (
+	// entries maps from kind/id -> quarantine entry
+	entries     = map[string]Entry{}
+	entriesLock sync.Mutex
+)
+
+func key(kind string, id string) string {
+	return kind + "/" + id
+}
+
+// Add quarantines the given object due to the given error,
+// returns true if it wasn't quarantined already
+func Add(kind string, id string, err error) bool {
+	entriesLock.Lock()
+	defer entriesLock.Unlock()
+	k := key(kind, id)
+	if entry, ok := entries[k]; ok {
+		entry.Error = err.Error()
+		entry.Retries++
+		entries[k] = entry
+		return false
+	}
+	entries[k] = Entry{
+		Kind:  kind,
+		ID:    id,
+		Error: err.Error(),
+		Since: time.Now(),
+	}
+	return true
+}
+
+// Remove releases the given object from quarantine, returns true if it was quarantined
+func Remove(kind string, id string) bool {
+	entriesLock.Lock()
+	defer entriesLock.Unlock()
+	k := key(kind, id)
+	_, ok := entries[k]
+	delete(entries, k)
+	return ok
+}
+
+// Contains indicates whether the given object is currently quarantined
+func Contains(kind string, id string) bool {
+	entriesLock.Lock()
+	defer entriesLock.Unlock()
+	_, ok := entries[key(kind, id)]
+	return ok
+}
+
+// Get returns all quarantined objects, ordered by kind and id
+func Get() []Entry {
+	entriesLock.Lock()
+	defer entriesLock.Unlock()
+	result := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return key(result[i].Kind, result[i].ID) < key(result[j].Kind, result[j].ID)
+	})
+	return result
+}
@@ -0,0 +1,64 @@
+package quarantine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdd(t *testing.T) {
+	// GIVEN
+	id := "add_sensor"
+	defer Remove(KindSensor, id)
+
+	// WHEN
+	added := Add(KindSensor, id, errors.New("first error"))
+	addedAgain := Add(KindSensor, id, errors.New("second error"))
+
+	// THEN
+	assert.True(t, added)
+	assert.False(t, addedAgain)
+	assert.True(t, Contains(KindSensor, id))
+	assert.False(t, Contains(KindFan, id))
+	entries := Get()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "second error", entries[0].Error)
+	assert.Equal(t, 1, entries[0].Retries)
+}
+
+func TestRemove(t *testing.T) {
+	// GIVEN
+	id := "remove_fan"
+	Add(KindFan, id, errors.New("error"))
+
+	// WHEN
+	removed := Remove(KindFan, id)
+	removedAgain := Remove(KindFan, id)
+
+	// THEN
+	assert.True(t, removed)
+	assert.False(t, removedAgain)
+	assert.False(t, Contains(KindFan, id))
+}
+
+func TestGetIsSorted(t *testing.T) {
+	// GIVEN
+	Add(KindSensor, "b", errors.New("error"))
+	Add(KindSensor, "a", errors.New("error"))
+	Add(KindFan, "c", errors.New("error"))
+	defer func() {
+		Remove(KindSensor, "a")
+		Remove(KindSensor, "b")
+		Remove(KindFan, "c")
+	}()
+
+	// WHEN
+	entries := Get()
+
+	// THEN
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "c", entries[0].ID)
+	assert.Equal(t, "a", entries[1].ID)
+	assert.Equal(t, "b", entries[2].ID)
+}
@@ -0,0 +1,230 @@
+package redfish
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+const (
+	chassisCollectionPath = "/redfish/v1/Chassis"
+
+	defaultTimeout = 5 * time.Second
+
+	// ControlModeAutomatic lets the BMC control the fan
+	ControlModeAutomatic = "Automatic"
+	// ControlModeManual uses the SetPoint of the Control resource
+	ControlModeManual = "Manual"
+)
+
+// Client reads and writes the resources of a BMC with a Redfish API
+type Client struct {
+	config configuration.RedfishConfig
+	http   *http.Client
+
+	// thermalPath is the path of the Thermal resource of the chassis, resolved on first use
+	thermalPath string
+	lock        sync.Mutex
+}
+
+// Thermal is the Thermal resource of a chassis, only the properties used by fan2go are included
+type Thermal struct {
+	Temperatures []Temperature `json:"Temperatures"`
+	Fans         []Fan         `json:"Fans"`
+}
+
+type Temperature struct {
+	MemberId       string   `json:"MemberId"`
+	Name           string   `json:"Name"`
+	ReadingCelsius *float64 `json:"ReadingCelsius"`
+}
+
+type Fan struct {
+	MemberId string `json:"MemberId"`
+	Name     string `json:"Name"`
+	// FanName is used instead of Name by older versions of the schema
+	FanName      string   `json:"FanName"`
+	Reading      *float64 `json:"Reading"`
+	ReadingUnits string   `json:"ReadingUnits"`
+}
+
+// Control is a Control resource, which sets the speed of a fan (or a fan zone) in percent
+type Control struct {
+	SetPoint    *float64 `json:"SetPoint,omitempty"`
+	ControlMode string   `json:"ControlMode,omitempty"`
+}
+
+type collection struct {
+	Members []struct {
+		Id string `json:"@odata.id"`
+	} `json:"Members"`
+}
+
+func NewClient(config configuration.RedfishConfig) *Client {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &Client{
+		config: config,
+		http:   &http.Client{Timeout: timeout, Transport: transport},
+	}
+}
+
+// GetThermal reads the Thermal resource of the configured chassis
+func (c *Client) GetThermal() (Thermal, error) {
+	var result Thermal
+	path, err := c.getThermalPath()
+	if err != nil {
+		return result, err
+	}
+	err = c.Get(path, &result)
+	return result, err
+}
+
+// GetTemperature returns the temperature (in degrees) with the given name or member id
+func (c *Client) GetTemperature(name string) (float64, error) {
+	thermal, err := c.GetThermal()
+	if err != nil {
+		return 0, err
+	}
+	for _, temperature := range thermal.Temperatures {
+		if temperature.MemberId != name && temperature.Name != name {
+			continue
+		}
+		if temperature.ReadingCelsius == nil {
+			return 0, fmt.Errorf("temperature '%s' has no reading", name)
+		}
+		return *temperature.ReadingCelsius, nil
+	}
+	return 0, fmt.Errorf("no temperature '%s' found", name)
+}
+
+// GetFanRpm returns the speed (in RPM) of the fan with the given name or member id
+func (c *Client) GetFanRpm(name string) (float64, error) {
+	thermal, err := c.GetThermal()
+	if err != nil {
+		return 0, err
+	}
+	for _, fan := range thermal.Fans {
+		if fan.MemberId != name && fan.Name != name && fan.FanName != name {
+			continue
+		}
+		if fan.Reading == nil {
+			return 0, fmt.Errorf("fan '%s' has no reading", name)
+		}
+		if len(fan.ReadingUnits) > 0 && !strings.EqualFold(fan.ReadingUnits, "RPM") {
+			return 0, fmt.Errorf("fan '%s' reports its speed in %s instead of RPM", name, fan.ReadingUnits)
+		}
+		return *fan.Reading, nil
+	}
+	return 0, fmt.Errorf("no fan '%s' found", name)
+}
+
+// GetControl reads the Control resource at the given path
+func (c *Client) GetControl(path string) (Control, error) {
+	var result Control
+	err := c.Get(path, &result)
+	return result, err
+}
+
+// SetControl changes the Control resource at the given path
+func (c *Client) SetControl(path string, control Control) error {
+	return c.Patch(path, control)
+}
+
+// Get reads the resource at the given path into result
+func (c *Client) Get(path string, result interface{}) error {
+	body, err := c.request(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return nil
+}
+
+// Patch changes the given properties of the resource at the given path
+func (c *Client) Patch(path string, properties interface{}) error {
+	data, err := json.Marshal(properties)
+	if err != nil {
+		return err
+	}
+	_, err = c.request(http.MethodPatch, path, data)
+	return err
+}
+
+func (c *Client) getThermalPath() (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.thermalPath) > 0 {
+		return c.thermalPath, nil
+	}
+
+	chassis := c.config.Chassis
+	if len(chassis) > 0 {
+		chassis = chassisCollectionPath + "/" + chassis
+	} else {
+		var chassisCollection collection
+		err := c.Get(chassisCollectionPath, &chassisCollection)
+		if err != nil {
+			return "", err
+		}
+		if len(chassisCollection.Members) <= 0 {
+			return "", fmt.Errorf("no chassis found")
+		}
+		chassis = chassisCollection.Members[0].Id
+	}
+
+	c.thermalPath = strings.TrimSuffix(chassis, "/") + "/Thermal"
+	return c.thermalPath, nil
+}
+
+func (c *Client) request(method string, path string, data []byte) ([]byte, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	request, err := http.NewRequest(method, strings.TrimSuffix(c.config.Url, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if data != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if len(c.config.Token) > 0 {
+		request.Header.Set("X-Auth-Token", c.config.Token)
+	} else if len(c.config.Username) > 0 {
+		request.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	result, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: unexpected status code %d", method, path, response.StatusCode)
+	}
+	return result, nil
+}
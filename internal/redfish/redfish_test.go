@@ -0,0 +1,125 @@
+package redfish
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+const thermal = `{
+	"Temperatures": [
+		{"MemberId": "0", "Name": "CPU1 Temp", "ReadingCelsius": 45},
+		{"MemberId": "1", "Name": "Inlet Temp", "ReadingCelsius": null}
+	],
+	"Fans": [
+		{"MemberId": "0", "Name": "FAN1", "Reading": 3200, "ReadingUnits": "RPM"},
+		{"MemberId": "1", "FanName": "FAN2", "Reading": 40, "ReadingUnits": "Percent"}
+	]
+}`
+
+// createBmc creates a BMC with a single chassis, the body of the last PATCH request is stored in patched
+func createBmc(t *testing.T, patched *map[string]interface{}) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/redfish/v1/Chassis":
+			_, _ = w.Write([]byte(`{"Members": [{"@odata.id": "/redfish/v1/Chassis/Self"}]}`))
+		case r.URL.Path == "/redfish/v1/Chassis/Self/Thermal":
+			_, _ = w.Write([]byte(thermal))
+		case r.URL.Path == "/redfish/v1/Chassis/Self/Controls/Fan1" && r.Method == http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, patched)
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/redfish/v1/Chassis/Self/Controls/Fan1":
+			_, _ = w.Write([]byte(`{"SetPoint": 40, "ControlMode": "Manual"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func createClient(server *httptest.Server) *Client {
+	return NewClient(configuration.RedfishConfig{
+		Url:      server.URL,
+		Token:    "secret",
+		Insecure: true,
+	})
+}
+
+func TestClient_GetTemperature(t *testing.T) {
+	// GIVEN
+	client := createClient(createBmc(t, nil))
+
+	// WHEN
+	byName, err1 := client.GetTemperature("CPU1 Temp")
+	byId, err2 := client.GetTemperature("0")
+	_, noReading := client.GetTemperature("Inlet Temp")
+	_, missing := client.GetTemperature("GPU Temp")
+
+	// THEN
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, 45.0, byName)
+	assert.Equal(t, 45.0, byId)
+	assert.EqualError(t, noReading, "temperature 'Inlet Temp' has no reading")
+	assert.EqualError(t, missing, "no temperature 'GPU Temp' found")
+}
+
+func TestClient_GetFanRpm(t *testing.T) {
+	// GIVEN
+	client := createClient(createBmc(t, nil))
+
+	// WHEN
+	rpm, err := client.GetFanRpm("FAN1")
+	_, percent := client.GetFanRpm("FAN2")
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 3200.0, rpm)
+	assert.EqualError(t, percent, "fan 'FAN2' reports its speed in Percent instead of RPM")
+}
+
+func TestClient_SetControl(t *testing.T) {
+	// GIVEN
+	patched := map[string]interface{}{}
+	client := createClient(createBmc(t, &patched))
+	setPoint := 60.0
+
+	// WHEN
+	err := client.SetControl("/redfish/v1/Chassis/Self/Controls/Fan1", Control{SetPoint: &setPoint})
+	control, err2 := client.GetControl("/redfish/v1/Chassis/Self/Controls/Fan1")
+
+	// THEN
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Equal(t, map[string]interface{}{"SetPoint": 60.0}, patched)
+	assert.Equal(t, 40.0, *control.SetPoint)
+	assert.Equal(t, ControlModeManual, control.ControlMode)
+}
+
+func TestClient_Unauthorized(t *testing.T) {
+	// GIVEN
+	server := createBmc(t, nil)
+	client := NewClient(configuration.RedfishConfig{
+		Url:      server.URL,
+		Username: "admin",
+		Password: "admin",
+		Insecure: true,
+	})
+
+	// WHEN
+	_, err := client.GetTemperature("CPU1 Temp")
+
+	// THEN
+	assert.EqualError(t, err, "GET /redfish/v1/Chassis: unexpected status code 401")
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+// configChangeDelay is the time to wait for further changes of the config file before reloading it,
+// since editors tend to write a file in multiple steps
+const configChangeDelay = 500 * time.Millisecond
+
+// watchForReload reloads the configuration on SIGHUP, and whenever the config file changes
+// if autoReload is enabled, until the given context is done
+func watchForReload(ctx context.Context, objects *daemonObjects, autoReload bool) error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	defer signal.Stop(sig)
+
+	var fileChanged <-chan fsnotify.Event
+	var watchErrors <-chan error
+	configPath := configuration.GetFilePath()
+	if autoReload {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+
+		// watch the directory instead of the file, since editors may replace the file instead of writing to it
+		err = watcher.Add(filepath.Dir(configPath))
+		if err != nil {
+			return err
+		}
+		for _, dir := range configuration.GetIncludeDirs() {
+			if err := watcher.Add(dir); err != nil {
+				ui.Warning("Unable to watch include directory %s: %v", dir, err)
+			}
+		}
+		fileChanged = watcher.Events
+		watchErrors = watcher.Errors
+	}
+
+	delay := time.NewTimer(configChangeDelay)
+	delay.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sig:
+			ui.Info("Received SIGHUP signal, reloading configuration...")
+			reloadConfig(objects)
+		case event := <-fileChanged:
+			isConfigFile := filepath.Clean(event.Name) == filepath.Clean(configPath) || configuration.IsIncludedFile(event.Name)
+			if isConfigFile && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+				delay.Reset(configChangeDelay)
+			}
+		case <-delay.C:
+			ui.Info("Config file changed, reloading configuration...")
+			reloadConfig(objects)
+		case err := <-watchErrors:
+			ui.Warning("Error watching config file: %v", err)
+		}
+	}
+}
+
+// reloadConfig reads the config file and applies the changes to all sensors, curves and fans.
+// Other settings are only applied on restart.
+func reloadConfig(objects *daemonObjects) {
+	config, err := configuration.ReloadConfig()
+	if err != nil {
+		ui.ErrorAndNotify("Reload Error", "Invalid configuration, keeping the current one: %v", err)
+		return
+	}
+
+	err = objects.apply(config)
+	if err != nil {
+		ui.ErrorAndNotify("Reload Error", "Unable to apply configuration, keeping the current one: %v", err)
+		return
+	}
+
+	ui.Info("Configuration reloaded")
+}
@@ -0,0 +1,189 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/curves"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/history"
+	"github.com/markusressel/fan2go/internal/sensors"
+)
+
+// Step contains the pwm decisions for all fans at a single sample of the trace
+type Step struct {
+	Time time.Time
+	// Fans maps from fan id -> decision for the fan
+	Fans map[string]FanDecision
+}
+
+type FanDecision struct {
+	// CurveValue is the value [0..255] of the curve of the fan
+	CurveValue int
+	// Pwm is the curve value mapped to the configured pwm range of the fan
+	Pwm int
+	// RecordedPwm is the pwm of the fan at the time the trace was recorded, if any
+	RecordedPwm *int
+}
+
+// Run feeds the sensor values of the given samples to the curves of the given configuration and passes
+// the resulting decisions to handle. The time between two samples is divided by speed, samples are
+// replayed as fast as possible if speed is <= 0.
+func Run(ctx context.Context, config *configuration.Configuration, samples []history.Sample, speed float64, handle func(step Step) error) error {
+	replayed, derived, err := createSensors(config, samples)
+	if err != nil {
+		return err
+	}
+
+	curves.SetSpeedCurveMap(map[string]curves.SpeedCurve{})
+	for _, curveConfig := range config.Curves {
+		curve, err := curves.NewSpeedCurve(curveConfig)
+		if err != nil {
+			return fmt.Errorf("unable to process curve configuration: %s", curveConfig.ID)
+		}
+		curves.SetSpeedCurve(curveConfig.ID, curve)
+	}
+	for _, fanConfig := range config.Fans {
+		if _, ok := curves.GetSpeedCurve(fanConfig.Curve); !ok {
+			return fmt.Errorf("curve %s of fan %s doesn't exist", fanConfig.Curve, fanConfig.ID)
+		}
+	}
+
+	for idx, sample := range samples {
+		if idx > 0 && speed > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Duration(float64(sample.Time.Sub(samples[idx-1].Time)) / speed)):
+			}
+		}
+
+		for id, sensor := range replayed {
+			if value, ok := sample.Sensors[id]; ok {
+				sensor.SetMovingAvg(value)
+			}
+		}
+		for _, sensor := range derived {
+			if value, err := sensor.GetValue(); err == nil {
+				sensor.SetMovingAvg(value)
+			}
+		}
+
+		step, err := evaluate(config.Fans, sample)
+		if err != nil {
+			return err
+		}
+		err = handle(step)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createSensors replaces all configured sensors with sensors replaying the values of the given samples.
+// Aggregate sensors whose values are not part of the trace are derived from the replayed sensors.
+func createSensors(config *configuration.Configuration, samples []history.Sample) (replayed map[string]sensors.Sensor, derived []sensors.Sensor, err error) {
+	recorded := map[string]bool{}
+	for _, sample := range samples {
+		for id := range sample.Sensors {
+			recorded[id] = true
+		}
+	}
+
+	replayed = map[string]sensors.Sensor{}
+	sensors.SetSensorMap(map[string]sensors.Sensor{})
+	for _, sensorConfig := range config.Sensors {
+		var sensor sensors.Sensor
+		switch {
+		case recorded[sensorConfig.ID]:
+			sensor = &sensors.VirtualSensor{Name: sensorConfig.ID}
+			replayed[sensorConfig.ID] = sensor
+		case sensorConfig.Virtual != nil || sensorConfig.Expression != nil:
+			sensor, err = sensors.NewSensor(sensorConfig)
+			if err != nil {
+				return nil, nil, err
+			}
+			derived = append(derived, sensor)
+		default:
+			return nil, nil, fmt.Errorf("the trace contains no values of sensor %s", sensorConfig.ID)
+		}
+		sensors.SetSensor(sensorConfig.ID, sensor)
+	}
+	return replayed, derived, nil
+}
+
+// evaluate returns the decisions for all given fans, based on the current sensor values
+func evaluate(fanConfigs []configuration.FanConfig, sample history.Sample) (Step, error) {
+	step := Step{
+		Time: sample.Time,
+		Fans: map[string]FanDecision{},
+	}
+	for _, fanConfig := range fanConfigs {
+		value, err := curves.GetSpeedCurveMap()[fanConfig.Curve].Evaluate()
+		if err != nil {
+			return step, fmt.Errorf("unable to evaluate curve %s of fan %s: %v", fanConfig.Curve, fanConfig.ID, err)
+		}
+
+		decision := FanDecision{
+			CurveValue: value,
+			Pwm:        mapToPwmRange(fanConfig, fans.ApplyCurveAdjustment(fanConfig, value)),
+		}
+		if recorded, ok := sample.Fans[fanConfig.ID]; ok {
+			decision.RecordedPwm = recorded.Pwm
+		}
+		step.Fans[fanConfig.ID] = decision
+	}
+	return step, nil
+}
+
+// mapToPwmRange maps the given curve value to the configured pwm range of the fan,
+// the same way the fan controller does
+func mapToPwmRange(config configuration.FanConfig, value int) int {
+	minPwm := fans.MinPwmValue
+	if config.MinPwm != nil {
+		minPwm = *config.MinPwm
+	}
+	maxPwm := fans.MaxPwmValue
+	if config.MaxPwm != nil {
+		maxPwm = *config.MaxPwm
+	}
+	return minPwm + int((float64(value)/fans.MaxPwmValue)*(float64(maxPwm)-float64(minPwm)))
+}
+
+// CsvHeader returns the header of the CSV written for the decisions of the given fans
+func CsvHeader(fanIds []string) []string {
+	header := []string{"time"}
+	for _, id := range fanIds {
+		header = append(header, fmt.Sprintf("fan:%s:curve", id), fmt.Sprintf("fan:%s:pwm", id), fmt.Sprintf("fan:%s:recordedPwm", id))
+	}
+	return header
+}
+
+// CsvRow returns the CSV row of the given step, matching CsvHeader
+func CsvRow(fanIds []string, step Step) []string {
+	row := []string{step.Time.Format(time.RFC3339Nano)}
+	for _, id := range fanIds {
+		decision := step.Fans[id]
+		recordedPwm := ""
+		if decision.RecordedPwm != nil {
+			recordedPwm = strconv.Itoa(*decision.RecordedPwm)
+		}
+		row = append(row, strconv.Itoa(decision.CurveValue), strconv.Itoa(decision.Pwm), recordedPwm)
+	}
+	return row
+}
+
+// FanIds returns the sorted ids of all fans of the given configuration
+func FanIds(config *configuration.Configuration) []string {
+	var ids []string
+	for _, fanConfig := range config.Fans {
+		ids = append(ids, fanConfig.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
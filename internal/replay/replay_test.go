@@ -0,0 +1,72 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/history"
+	"github.com/stretchr/testify/assert"
+)
+
+func createReplayTestConfig() *configuration.Configuration {
+	minPwm := 50
+	return &configuration.Configuration{
+		Sensors: []configuration.SensorConfig{
+			{ID: "cpu", HwMon: &configuration.HwMonSensorConfig{Platform: "coretemp", Index: 1}},
+			{ID: "gpu", HwMon: &configuration.HwMonSensorConfig{Platform: "amdgpu", Index: 1}},
+			{ID: "max", Virtual: &configuration.VirtualSensorConfig{Function: configuration.AggregationMax, Sensors: []string{"cpu", "gpu"}}},
+		},
+		Curves: []configuration.CurveConfig{
+			{ID: "curve", Linear: &configuration.LinearCurveConfig{Sensor: "max", Min: 40, Max: 80}},
+		},
+		Fans: []configuration.FanConfig{
+			{ID: "fan", Curve: "curve", MinPwm: &minPwm},
+		},
+	}
+}
+
+func TestRunEvaluatesCurvesOfSamples(t *testing.T) {
+	// GIVEN
+	config := createReplayTestConfig()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	recordedPwm := 120
+	samples := []history.Sample{
+		{Time: start, Sensors: map[string]float64{"cpu": 30000, "gpu": 40000}},
+		{Time: start.Add(time.Second), Sensors: map[string]float64{"cpu": 60000, "gpu": 50000},
+			Fans: map[string]history.FanSample{"fan": {Pwm: &recordedPwm}}},
+		{Time: start.Add(2 * time.Second), Sensors: map[string]float64{"cpu": 45000, "gpu": 90000}},
+	}
+
+	// WHEN
+	var steps []Step
+	err := Run(context.Background(), config, samples, 0, func(step Step) error {
+		steps = append(steps, step)
+		return nil
+	})
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, steps, 3)
+	assert.Equal(t, FanDecision{CurveValue: 0, Pwm: 50}, steps[0].Fans["fan"])
+	assert.Equal(t, FanDecision{CurveValue: 127, Pwm: 152, RecordedPwm: &recordedPwm}, steps[1].Fans["fan"])
+	assert.Equal(t, FanDecision{CurveValue: 255, Pwm: 255}, steps[2].Fans["fan"])
+	assert.Equal(t, []string{"2024-01-01T12:00:01Z", "127", "152", "120"}, CsvRow(FanIds(config), steps[1]))
+}
+
+func TestRunMissingSensor(t *testing.T) {
+	// GIVEN
+	config := createReplayTestConfig()
+	samples := []history.Sample{
+		{Time: time.Now(), Sensors: map[string]float64{"cpu": 30000}},
+	}
+
+	// WHEN
+	err := Run(context.Background(), config, samples, 0, func(step Step) error {
+		return nil
+	})
+
+	// THEN
+	assert.EqualError(t, err, "the trace contains no values of sensor gpu")
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/fans"
+	"github.com/markusressel/fan2go/internal/hwmon"
+	"github.com/markusressel/fan2go/internal/persistence"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+// RestoreFans writes the original state stored in the given persistence back to all configured fans.
+// A stored state is only left over if fan2go didn't exit cleanly, f.ex. because it crashed, which
+// would otherwise leave the fans in manual mode. Returns the number of restored fans.
+func RestoreFans(pers persistence.Persistence) (int, error) {
+	states, err := pers.LoadFanStates()
+	if err != nil {
+		return 0, err
+	}
+	if len(states) <= 0 {
+		return 0, nil
+	}
+
+	controllers := hwmon.GetChips()
+
+	restored := 0
+	for _, fanConfig := range configuration.GetFans() {
+		state, ok := states[fanConfig.ID]
+		if !ok {
+			continue
+		}
+		delete(states, fanConfig.ID)
+
+		if fanConfig.HwMon != nil || fanConfig.DellSmm != nil || fanConfig.Group != nil {
+			err := hwmon.UpdateFanConfigFromHwMonControllers(controllers, &fanConfig)
+			if err != nil {
+				ui.Warning("Unable to restore fan %s: %v", fanConfig.ID, err)
+				continue
+			}
+		}
+		fan, err := fans.NewFan(fanConfig)
+		if err != nil {
+			ui.Warning("Unable to restore fan %s: %v", fanConfig.ID, err)
+			continue
+		}
+
+		ui.Info("Restoring original state of fan %s (pwm: %d)", fanConfig.ID, state.Pwm)
+		err = controller.RestoreFanState(fan, state)
+		if err != nil {
+			ui.Warning("Unable to restore fan %s, make sure it is running!", fanConfig.ID)
+		}
+		restored++
+
+		err = pers.DeleteFanState(fanConfig.ID)
+		if err != nil {
+			return restored, err
+		}
+	}
+
+	// fans which are no longer configured can't be restored, their state is discarded
+	for fanId := range states {
+		ui.Warning("Discarding original state of fan %s, since it is no longer configured", fanId)
+		err = pers.DeleteFanState(fanId)
+		if err != nil {
+			return restored, err
+		}
+	}
+	return restored, nil
+}
@@ -0,0 +1,136 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/markusressel/fan2go/internal/controller"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+const (
+	logindInterface      = "org.freedesktop.login1.Manager"
+	logindPath           = godbus.ObjectPath("/org/freedesktop/login1")
+	prepareForSleepEvent = "PrepareForSleep"
+
+	// clockGapCheckRate is the rate at which the wall clock is compared with the monotonic clock
+	clockGapCheckRate = 5 * time.Second
+	// minClockGap is the minimum difference between wall clock and monotonic clock that is
+	// considered a suspend, since the monotonic clock doesn't advance while the system is suspended
+	minClockGap = 5 * time.Second
+)
+
+// watchForResume re-initializes all fans whenever the system resumed from suspend, since the
+// firmware may reset the control mode and pwm values of the fans. Resumes are detected using
+// the logind PrepareForSleep signal, or by detecting gaps in the monotonic clock if logind is
+// not available.
+func watchForResume(ctx context.Context) error {
+	resumed, err := watchLogindResume(ctx)
+	if err != nil {
+		ui.Warning("Unable to subscribe to logind sleep signals, detecting resume from clock gaps instead: %v", err)
+		resumed = watchClockGapResume(ctx)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-resumed:
+			ui.Info("System resumed from suspend, re-initializing fans...")
+			reinitializeFans()
+		}
+	}
+}
+
+// watchLogindResume returns a channel receiving a value whenever logind signals a resume
+func watchLogindResume(ctx context.Context) (<-chan struct{}, error) {
+	conn, err := godbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+
+	err = conn.AddMatchSignal(
+		godbus.WithMatchObjectPath(logindPath),
+		godbus.WithMatchInterface(logindInterface),
+		godbus.WithMatchMember(prepareForSleepEvent),
+	)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	signals := make(chan *godbus.Signal, 10)
+	conn.Signal(signals)
+
+	resumed := make(chan struct{}, 1)
+	go func() {
+		defer conn.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case signal, ok := <-signals:
+				if !ok {
+					return
+				}
+				if signal.Name != logindInterface+"."+prepareForSleepEvent || len(signal.Body) <= 0 {
+					continue
+				}
+				// the signal is sent with "true" before suspending and "false" after resuming
+				if sleeping, ok := signal.Body[0].(bool); ok && !sleeping {
+					notifyResume(resumed)
+				}
+			}
+		}
+	}()
+
+	return resumed, nil
+}
+
+// watchClockGapResume returns a channel receiving a value whenever the wall clock advanced
+// significantly more than the monotonic clock, which indicates that the system was suspended
+func watchClockGapResume(ctx context.Context) <-chan struct{} {
+	resumed := make(chan struct{}, 1)
+	go func() {
+		tick := time.NewTicker(clockGapCheckRate)
+		defer tick.Stop()
+
+		last := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				now := time.Now()
+				monotonic := now.Sub(last)
+				// Round(0) strips the monotonic clock reading, so only the wall clock is compared
+				wall := now.Round(0).Sub(last.Round(0))
+				if hasClockGap(monotonic, wall) {
+					notifyResume(resumed)
+				}
+				last = now
+			}
+		}
+	}()
+	return resumed
+}
+
+// hasClockGap indicates whether the wall clock advanced significantly more than the monotonic clock
+func hasClockGap(monotonic time.Duration, wall time.Duration) bool {
+	return wall-monotonic > minClockGap
+}
+
+func notifyResume(resumed chan struct{}) {
+	select {
+	case resumed <- struct{}{}:
+	default:
+		// a re-initialization is already pending
+	}
+}
+
+func reinitializeFans() {
+	for _, fanController := range controller.GetFanControllerMap() {
+		fanController.Reinitialize()
+	}
+}
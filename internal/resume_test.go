@@ -0,0 +1,16 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasClockGap(t *testing.T) {
+	// THEN
+	assert.False(t, hasClockGap(clockGapCheckRate, clockGapCheckRate))
+	// small wall clock adjustments, f.ex. by ntp, are not considered a suspend
+	assert.False(t, hasClockGap(clockGapCheckRate, clockGapCheckRate+time.Second))
+	assert.True(t, hasClockGap(clockGapCheckRate, clockGapCheckRate+time.Minute))
+}
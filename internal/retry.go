@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/quarantine"
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+const (
+	// quarantineRetryMinDelay is the time after which quarantined sensors and fans are retried first
+	quarantineRetryMinDelay = 10 * time.Second
+	// quarantineRetryMaxDelay is the longest time between two retries, the delay doubles after each retry
+	quarantineRetryMaxDelay = 5 * time.Minute
+)
+
+// watchQuarantine periodically re-applies the current configuration while any sensor or fan is
+// quarantined, so devices that become available again are picked up and failed fan controllers
+// are restarted, until the given context is done
+func watchQuarantine(ctx context.Context, objects *daemonObjects) error {
+	delay := quarantineRetryMinDelay
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		releaseRecoveredFans()
+		entries := quarantine.Get()
+		if len(entries) <= 0 {
+			delay = quarantineRetryMinDelay
+			timer.Reset(delay)
+			continue
+		}
+
+		ui.Debug("Retrying %d quarantined sensors and fans...", len(entries))
+		err := objects.apply(configuration.CopyObjects())
+		if err != nil {
+			ui.Warning("Unable to apply configuration while retrying quarantined sensors and fans: %v", err)
+		}
+
+		delay = nextRetryDelay(delay)
+		timer.Reset(delay)
+	}
+}
+
+// nextRetryDelay doubles the given delay, up to quarantineRetryMaxDelay
+func nextRetryDelay(delay time.Duration) time.Duration {
+	delay *= 2
+	if delay > quarantineRetryMaxDelay {
+		return quarantineRetryMaxDelay
+	}
+	return delay
+}
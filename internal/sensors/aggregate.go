@@ -0,0 +1,67 @@
+package sensors
+
+import (
+	"fmt"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+// AggregateSensor is a virtual sensor combining the values of other sensors
+type AggregateSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+}
+
+func (sensor *AggregateSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *AggregateSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *AggregateSensor) GetValue() (float64, error) {
+	config := sensor.Config.Virtual
+
+	var values []float64
+	for _, sensorId := range config.Sensors {
+		s, ok := GetSensor(sensorId)
+		if !ok {
+			return 0, fmt.Errorf("sensor %s: sensor '%s' not found", sensor.GetId(), sensorId)
+		}
+		// like expression sensors, the moving average is used, reading the sensor again would bypass its smoothing
+		values = append(values, s.GetMovingAvg())
+	}
+
+	if len(values) <= 0 {
+		return 0, fmt.Errorf("sensor %s: no sensors configured", sensor.GetId())
+	}
+
+	switch config.Function {
+	case configuration.AggregationWeighted:
+		sum := 0.0
+		for i, value := range values {
+			sum += value * config.Weights[i]
+		}
+		return sum, nil
+	case configuration.AggregationDifference:
+		if len(values) != 2 {
+			return 0, fmt.Errorf("sensor %s: difference requires exactly two sensors", sensor.GetId())
+		}
+		return values[0] - values[1], nil
+	default:
+		return aggregate(config.Function, values), nil
+	}
+}
+
+func (sensor *AggregateSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *AggregateSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
@@ -0,0 +1,116 @@
+package sensors
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func createAggregateSensor(function string, weights []float64) AggregateSensor {
+	SetSensor("cpu", &VirtualSensor{Name: "cpu", Value: 60000})
+	SetSensor("vrm", &VirtualSensor{Name: "vrm", Value: 40000})
+
+	return AggregateSensor{
+		Config: configuration.SensorConfig{
+			ID: "aggregate",
+			Virtual: &configuration.VirtualSensorConfig{
+				Function: function,
+				Sensors:  []string{"cpu", "vrm"},
+				Weights:  weights,
+			},
+		},
+	}
+}
+
+func TestAggregateSensor_Max(t *testing.T) {
+	// GIVEN
+	sensor := createAggregateSensor(configuration.AggregationMax, nil)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 60000.0, value)
+}
+
+func TestAggregateSensor_Min(t *testing.T) {
+	// GIVEN
+	sensor := createAggregateSensor(configuration.AggregationMin, nil)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 40000.0, value)
+}
+
+func TestAggregateSensor_Average(t *testing.T) {
+	// GIVEN
+	sensor := createAggregateSensor(configuration.AggregationAverage, nil)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 50000.0, value)
+}
+
+func TestAggregateSensor_Weighted(t *testing.T) {
+	// GIVEN
+	sensor := createAggregateSensor(configuration.AggregationWeighted, []float64{0.75, 0.25})
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 55000.0, value)
+}
+
+func TestAggregateSensor_Difference(t *testing.T) {
+	// GIVEN
+	sensor := createAggregateSensor(configuration.AggregationDifference, nil)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 20000.0, value)
+}
+
+func TestAggregateSensor_UsesMovingAvg(t *testing.T) {
+	// GIVEN
+	sensor := createAggregateSensor(configuration.AggregationMax, nil)
+	// reading this sensor would fail, since its file doesn't exist
+	SetSensor("cpu", &FileSensor{
+		Config: configuration.SensorConfig{
+			ID:   "cpu",
+			File: &configuration.FileSensorConfig{Path: "/nonexistent"},
+		},
+		MovingAvg: 70000,
+	})
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 70000.0, value)
+}
+
+func TestAggregateSensor_MissingSensor(t *testing.T) {
+	// GIVEN
+	sensor := createAggregateSensor(configuration.AggregationMax, nil)
+	RemoveSensor("vrm")
+
+	// WHEN
+	_, err := sensor.GetValue()
+
+	// THEN
+	assert.EqualError(t, err, "sensor aggregate: sensor 'vrm' not found")
+}
@@ -0,0 +1,72 @@
+package sensors
+
+import (
+	"fmt"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+type AmdGpuSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+}
+
+func (sensor *AmdGpuSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *AmdGpuSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *AmdGpuSensor) GetValue() (float64, error) {
+	if sensor.Config.AmdGpu.Type == configuration.AmdGpuSensorTypeUtilization {
+		percent, err := util.ReadIntFromFile(sensor.Config.AmdGpu.BusyInput)
+		if err != nil {
+			return 0, fmt.Errorf("sensor %s: unable to read GPU utilization: %v", sensor.GetId(), err)
+		}
+		// scaled like temperatures, so curves see the percentage
+		return float64(percent) * 1000, nil
+	}
+
+	inputs := sensor.Config.AmdGpu.TempInputs
+	if len(inputs) <= 0 {
+		return 0, fmt.Errorf("sensor %s: no temp inputs found", sensor.GetId())
+	}
+
+	var values []float64
+	for _, input := range inputs {
+		integer, err := util.ReadIntFromFile(input)
+		if err != nil {
+			return 0, err
+		}
+		values = append(values, float64(integer))
+	}
+
+	return aggregate(sensor.Config.AmdGpu.Aggregation, values), nil
+}
+
+func (sensor *AmdGpuSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *AmdGpuSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
+
+// aggregate combines the given values using the given aggregation, defaults to max
+func aggregate(aggregation string, values []float64) float64 {
+	switch aggregation {
+	case configuration.AggregationMin:
+		return util.Min(values)
+	case configuration.AggregationAverage:
+		return util.Avg(values)
+	default:
+		return util.Max(values)
+	}
+}
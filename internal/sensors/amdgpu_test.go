@@ -0,0 +1,83 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func createAmdGpuTempInputs(t *testing.T, values ...string) []string {
+	root := t.TempDir()
+	var result []string
+	for i, value := range values {
+		input := path.Join(root, fmt.Sprintf("temp%d_input", i+1))
+		_ = os.WriteFile(input, []byte(value+"\n"), 0644)
+		result = append(result, input)
+	}
+	return result
+}
+
+func TestAmdGpuSensor_GetValueDefaultsToMax(t *testing.T) {
+	// GIVEN
+	sensor := AmdGpuSensor{
+		Config: configuration.SensorConfig{
+			ID: "gpu",
+			AmdGpu: &configuration.AmdGpuSensorConfig{
+				TempInputs: createAmdGpuTempInputs(t, "58000", "71000", "56000"),
+			},
+		},
+	}
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 71000.0, value)
+}
+
+func TestAmdGpuSensor_GetValueAverage(t *testing.T) {
+	// GIVEN
+	sensor := AmdGpuSensor{
+		Config: configuration.SensorConfig{
+			ID: "gpu",
+			AmdGpu: &configuration.AmdGpuSensorConfig{
+				Aggregation: configuration.AggregationAverage,
+				TempInputs:  createAmdGpuTempInputs(t, "58000", "62000"),
+			},
+		},
+	}
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 60000.0, value)
+}
+
+func TestAmdGpuSensor_GetValueUtilization(t *testing.T) {
+	// GIVEN
+	busyInput := path.Join(t.TempDir(), "gpu_busy_percent")
+	_ = os.WriteFile(busyInput, []byte("37\n"), 0644)
+	sensor := AmdGpuSensor{
+		Config: configuration.SensorConfig{
+			ID: "gpu",
+			AmdGpu: &configuration.AmdGpuSensorConfig{
+				Type:      configuration.AmdGpuSensorTypeUtilization,
+				BusyInput: busyInput,
+			},
+		},
+	}
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 37000.0, value)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/markusressel/fan2go/internal/ui"
 	"github.com/markusressel/fan2go/internal/util"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,16 +16,19 @@ type CmdSensor struct {
 	MovingAvg float64                    `json:"movingAvg"`
 }
 
-func (sensor CmdSensor) GetId() string {
+func (sensor *CmdSensor) GetId() string {
 	return sensor.Config.ID
 }
 
-func (sensor CmdSensor) GetConfig() configuration.SensorConfig {
+func (sensor *CmdSensor) GetConfig() configuration.SensorConfig {
 	return sensor.Config
 }
 
-func (sensor CmdSensor) GetValue() (float64, error) {
-	timeout := 2 * time.Second
+func (sensor *CmdSensor) GetValue() (float64, error) {
+	timeout := sensor.Config.Cmd.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
 	exec := sensor.Config.Cmd.Exec
 	args := sensor.Config.Cmd.Args
 	result, err := util.SafeCmdExecution(exec, args, timeout)
@@ -32,7 +36,7 @@ func (sensor CmdSensor) GetValue() (float64, error) {
 		return 0, fmt.Errorf("sensor %s: %s", sensor.GetId(), err.Error())
 	}
 
-	temp, err := strconv.ParseFloat(result, 64)
+	temp, err := strconv.ParseFloat(strings.TrimSpace(result), 64)
 	if err != nil {
 		ui.Warning("sensor %s: Unable to read int from command output: %s", sensor.GetId(), exec)
 		return 0, err
@@ -41,10 +45,14 @@ func (sensor CmdSensor) GetValue() (float64, error) {
 	return temp, nil
 }
 
-func (sensor CmdSensor) GetMovingAvg() (avg float64) {
+func (sensor *CmdSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
 	return sensor.MovingAvg
 }
 
 func (sensor *CmdSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
 	sensor.MovingAvg = avg
 }
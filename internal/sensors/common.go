@@ -1,14 +1,70 @@
 package sensors
 
 import (
+	"sync"
+
 	"fmt"
 	"github.com/markusressel/fan2go/internal/configuration"
 )
 
 var (
-	SensorMap = map[string]Sensor{}
+	// sensorMap contains all active sensors. It is read concurrently,
+	// so it must only be accessed using the functions below.
+	sensorMap     = map[string]Sensor{}
+	sensorMapLock sync.RWMutex
 )
 
+// GetSensorMap returns all sensors by id, the result must not be modified
+func GetSensorMap() map[string]Sensor {
+	sensorMapLock.RLock()
+	defer sensorMapLock.RUnlock()
+	return sensorMap
+}
+
+// GetSensor returns the sensor with the given id
+func GetSensor(id string) (Sensor, bool) {
+	sensorMapLock.RLock()
+	defer sensorMapLock.RUnlock()
+	value, ok := sensorMap[id]
+	return value, ok
+}
+
+// SetSensorMap replaces all sensors, the given map must not be modified afterwards
+func SetSensorMap(values map[string]Sensor) {
+	sensorMapLock.Lock()
+	defer sensorMapLock.Unlock()
+	sensorMap = values
+}
+
+// SetSensor adds or replaces the sensor with the given id
+func SetSensor(id string, value Sensor) {
+	sensorMapLock.Lock()
+	defer sensorMapLock.Unlock()
+	values := make(map[string]Sensor, len(sensorMap)+1)
+	for key, v := range sensorMap {
+		values[key] = v
+	}
+	values[id] = value
+	sensorMap = values
+}
+
+// RemoveSensor removes the sensor with the given id
+func RemoveSensor(id string) {
+	sensorMapLock.Lock()
+	defer sensorMapLock.Unlock()
+	values := make(map[string]Sensor, len(sensorMap))
+	for key, v := range sensorMap {
+		if key != id {
+			values[key] = v
+		}
+	}
+	sensorMap = values
+}
+
+// movingAvgLock guards the moving averages of all sensors, which are updated by the sensor monitors,
+// while curves and the apis read them
+var movingAvgLock sync.RWMutex
+
 type Sensor interface {
 	GetId() string
 
@@ -22,7 +78,20 @@ type Sensor interface {
 	SetMovingAvg(avg float64)
 }
 
+// NewSensor creates the sensor of the given config, whose values are transformed
+// using the factor and offset of the config, if any
 func NewSensor(config configuration.SensorConfig) (Sensor, error) {
+	sensor, err := newSensor(config)
+	if err != nil {
+		return nil, err
+	}
+	if config.Factor != 0 || config.Offset != 0 {
+		return &TransformedSensor{Sensor: sensor}, nil
+	}
+	return sensor, nil
+}
+
+func newSensor(config configuration.SensorConfig) (Sensor, error) {
 	if config.HwMon != nil {
 		return &HwmonSensor{
 			Index:  config.HwMon.Index,
@@ -43,5 +112,77 @@ func NewSensor(config configuration.SensorConfig) (Sensor, error) {
 		}, nil
 	}
 
+	if config.Nvme != nil {
+		return NewNvmeSensor(config)
+	}
+
+	if config.Nvidia != nil {
+		return &NvidiaSensor{
+			Config: config,
+		}, nil
+	}
+
+	if config.AmdGpu != nil {
+		return &AmdGpuSensor{
+			Config: config,
+		}, nil
+	}
+
+	if config.Http != nil {
+		return &HttpSensor{
+			Config: config,
+		}, nil
+	}
+
+	if config.Snmp != nil {
+		return &SnmpSensor{
+			Config: config,
+		}, nil
+	}
+
+	if config.Redfish != nil {
+		return NewRedfishSensor(config), nil
+	}
+
+	if config.Remote != nil {
+		return NewRemoteSensor(config)
+	}
+
+	if config.Smart != nil {
+		return NewSmartSensor(config)
+	}
+
+	if config.Thermal != nil {
+		return NewThermalZoneSensor(config)
+	}
+
+	if config.Rapl != nil {
+		return NewRaplSensor(config)
+	}
+
+	if config.Liquidctl != nil {
+		return &LiquidctlSensor{
+			Config: config,
+		}, nil
+	}
+
+	if config.UsbHid != nil {
+		return NewUsbHidSensor(config)
+	}
+
+	if config.Virtual != nil {
+		return &AggregateSensor{
+			Config: config,
+		}, nil
+	}
+
+	if config.Plugin != nil {
+		return NewPluginSensor(config)
+	}
+
+	if config.Expression != nil {
+		return NewExpressionSensor(config)
+	}
+
 	return nil, fmt.Errorf("no matching sensor type for sensor: %s", config.ID)
 }
@@ -0,0 +1,26 @@
+package sensors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetSensor_KeepsPreviousMap(t *testing.T) {
+	// GIVEN
+	SetSensorMap(map[string]Sensor{"cpu": &VirtualSensor{Name: "cpu"}})
+	defer SetSensorMap(map[string]Sensor{})
+	previous := GetSensorMap()
+
+	// WHEN
+	SetSensor("gpu", &VirtualSensor{Name: "gpu"})
+	RemoveSensor("cpu")
+
+	// THEN
+	assert.Len(t, previous, 1)
+	assert.Contains(t, previous, "cpu")
+	_, ok := GetSensor("gpu")
+	assert.True(t, ok)
+	_, ok = GetSensor("cpu")
+	assert.False(t, ok)
+}
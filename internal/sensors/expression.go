@@ -0,0 +1,66 @@
+package sensors
+
+import (
+	"fmt"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/expression"
+)
+
+// ExpressionSensor is a virtual sensor computing its value using a formula of other sensor values
+type ExpressionSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+
+	expression *expression.Expression
+}
+
+func NewExpressionSensor(config configuration.SensorConfig) (*ExpressionSensor, error) {
+	parsed, err := expression.Parse(config.Expression.Formula)
+	if err != nil {
+		return nil, fmt.Errorf("sensor %s: invalid formula: %v", config.ID, err)
+	}
+	return &ExpressionSensor{
+		Config:     config,
+		expression: parsed,
+	}, nil
+}
+
+func (sensor *ExpressionSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *ExpressionSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *ExpressionSensor) GetValue() (float64, error) {
+	variables := map[string]float64{}
+	for _, sensorId := range sensor.expression.Variables() {
+		s, ok := GetSensor(sensorId)
+		if !ok {
+			return 0, fmt.Errorf("sensor %s: sensor '%s' not found", sensor.GetId(), sensorId)
+		}
+		// like expression curves, the moving average is used, reading the sensor again would bypass its smoothing
+		// milli-degree to degree
+		variables[sensorId] = s.GetMovingAvg() / 1000
+	}
+
+	result, err := sensor.expression.Evaluate(variables)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+	return result * 1000, nil
+}
+
+func (sensor *ExpressionSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *ExpressionSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
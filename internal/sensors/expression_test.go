@@ -0,0 +1,86 @@
+package sensors
+
+import (
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func createExpressionSensor(t *testing.T, formula string) *ExpressionSensor {
+	SetSensor("cpu", &VirtualSensor{Name: "cpu", Value: 60000})
+	SetSensor("gpu", &VirtualSensor{Name: "gpu", Value: 45000})
+
+	sensor, err := NewExpressionSensor(configuration.SensorConfig{
+		ID: "expression",
+		Expression: &configuration.ExpressionSensorConfig{
+			Formula: formula,
+		},
+	})
+	assert.NoError(t, err)
+	return sensor
+}
+
+func TestExpressionSensor(t *testing.T) {
+	for _, tc := range []struct {
+		formula  string
+		expected float64
+	}{
+		{"(cpu + gpu) / 2", 52500},
+		{"max(cpu, gpu)", 60000},
+		{"cpu - gpu", 15000},
+		{"cpu > 55 ? cpu + 10 : gpu", 70000},
+	} {
+		// GIVEN
+		sensor := createExpressionSensor(t, tc.formula)
+
+		// WHEN
+		value, err := sensor.GetValue()
+
+		// THEN
+		assert.NoError(t, err, tc.formula)
+		assert.Equal(t, tc.expected, value, tc.formula)
+	}
+}
+
+func TestExpressionSensor_UsesMovingAvg(t *testing.T) {
+	// GIVEN
+	sensor := createExpressionSensor(t, "cpu + 1")
+	// reading this sensor would fail, since its file doesn't exist
+	SetSensor("cpu", &FileSensor{
+		Config: configuration.SensorConfig{
+			ID:   "cpu",
+			File: &configuration.FileSensorConfig{Path: "/nonexistent"},
+		},
+		MovingAvg: 50000,
+	})
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 51000.0, value)
+}
+
+func TestExpressionSensor_MissingSensor(t *testing.T) {
+	// GIVEN
+	sensor := createExpressionSensor(t, "cpu + ambient")
+
+	// WHEN
+	_, err := sensor.GetValue()
+
+	// THEN
+	assert.EqualError(t, err, "sensor expression: sensor 'ambient' not found")
+}
+
+func TestExpressionSensor_InvalidFormula(t *testing.T) {
+	// WHEN
+	_, err := NewExpressionSensor(configuration.SensorConfig{
+		ID:         "expression",
+		Expression: &configuration.ExpressionSensorConfig{Formula: "cpu +"},
+	})
+
+	// THEN
+	assert.EqualError(t, err, "sensor expression: invalid formula: unexpected end of expression")
+}
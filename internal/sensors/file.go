@@ -14,15 +14,15 @@ type FileSensor struct {
 	MovingAvg float64                    `json:"movingAvg"`
 }
 
-func (sensor FileSensor) GetId() string {
+func (sensor *FileSensor) GetId() string {
 	return sensor.Config.ID
 }
 
-func (sensor FileSensor) GetConfig() configuration.SensorConfig {
+func (sensor *FileSensor) GetConfig() configuration.SensorConfig {
 	return sensor.Config
 }
 
-func (sensor FileSensor) GetValue() (float64, error) {
+func (sensor *FileSensor) GetValue() (float64, error) {
 	filePath := sensor.Config.File.Path
 	// resolve home dir path
 	if strings.HasPrefix(filePath, "~") {
@@ -34,20 +34,29 @@ func (sensor FileSensor) GetValue() (float64, error) {
 		filePath = filepath.Join(currentUser.HomeDir, filePath[1:])
 	}
 
-	integer, err := util.ReadIntFromFile(filePath)
+	value, err := util.ReadFloatFromFile(filePath)
 	if err != nil {
-		ui.Warning("Unable to read int from file sensor: %s", filePath)
+		ui.Warning("Unable to read number from file sensor: %s", filePath)
 		return 0, nil
 	}
 
-	result := float64(integer)
+	scale := sensor.Config.File.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	result := value * scale
 	return result, nil
 }
 
-func (sensor FileSensor) GetMovingAvg() (avg float64) {
+func (sensor *FileSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
 	return sensor.MovingAvg
 }
 
 func (sensor *FileSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
 	sensor.MovingAvg = avg
 }
@@ -0,0 +1,53 @@
+package sensors
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSensor_GetValueWithScale(t *testing.T) {
+	// GIVEN
+	filePath := path.Join(t.TempDir(), "temp")
+	_ = os.WriteFile(filePath, []byte("42.5\n"), 0644)
+	sensor := FileSensor{
+		Config: configuration.SensorConfig{
+			ID: "file",
+			File: &configuration.FileSensorConfig{
+				Path:  filePath,
+				Scale: 1000,
+			},
+		},
+	}
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 42500.0, value)
+}
+
+func TestFileSensor_GetValueWithoutScale(t *testing.T) {
+	// GIVEN
+	filePath := path.Join(t.TempDir(), "temp")
+	_ = os.WriteFile(filePath, []byte("42500\n"), 0644)
+	sensor := FileSensor{
+		Config: configuration.SensorConfig{
+			ID: "file",
+			File: &configuration.FileSensorConfig{
+				Path: filePath,
+			},
+		},
+	}
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 42500.0, value)
+}
@@ -0,0 +1,174 @@
+package sensors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+var (
+	jsonPathIndexRegex = regexp.MustCompile(`^([^\[]*)\[(\d+)]$`)
+)
+
+type HttpSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+}
+
+func (sensor *HttpSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *HttpSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *HttpSensor) GetValue() (float64, error) {
+	config := sensor.Config.Http
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	client := http.Client{Timeout: timeout}
+
+	request, err := http.NewRequest(http.MethodGet, config.Url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+	for key, value := range config.Headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return 0, fmt.Errorf("sensor %s: unexpected status code %d", sensor.GetId(), response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+
+	value, err := extractHttpValue(*config, body)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+
+	scale := config.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	return value * scale, nil
+}
+
+func (sensor *HttpSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *HttpSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
+
+// extractHttpValue extracts the sensor value from the given response body,
+// using either the json path or the regex of the given config
+func extractHttpValue(config configuration.HttpSensorConfig, body []byte) (float64, error) {
+	text := strings.TrimSpace(string(body))
+
+	if len(config.JsonPath) > 0 {
+		var data interface{}
+		err := json.Unmarshal(body, &data)
+		if err != nil {
+			return 0, err
+		}
+		value, err := resolveJsonPath(data, config.JsonPath)
+		if err != nil {
+			return 0, err
+		}
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			text = strings.TrimSpace(v)
+		default:
+			return 0, fmt.Errorf("value at json path '%s' is not a number: %v", config.JsonPath, value)
+		}
+	}
+
+	if len(config.Regex) > 0 {
+		expr, err := regexp.Compile(config.Regex)
+		if err != nil {
+			return 0, err
+		}
+		match := expr.FindStringSubmatch(text)
+		if match == nil {
+			return 0, fmt.Errorf("regex '%s' didn't match response", config.Regex)
+		}
+		text = match[0]
+		if len(match) > 1 {
+			text = match[1]
+		}
+	}
+
+	return strconv.ParseFloat(text, 64)
+}
+
+// resolveJsonPath resolves a simple JSONPath expression like "$.sensors[0].value"
+// against the given unmarshalled json data
+func resolveJsonPath(data interface{}, path string) (interface{}, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if len(path) <= 0 {
+		return data, nil
+	}
+
+	current := data
+	for _, element := range strings.Split(path, ".") {
+		key := element
+		index := -1
+		if match := jsonPathIndexRegex.FindStringSubmatch(element); match != nil {
+			key = match[1]
+			index, _ = strconv.Atoi(match[2])
+		}
+
+		if len(key) > 0 {
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot resolve '%s' of json path '%s', not an object", key, path)
+			}
+			current, ok = object[key]
+			if !ok {
+				return nil, fmt.Errorf("cannot resolve '%s' of json path '%s', key not found", key, path)
+			}
+		}
+
+		if index >= 0 {
+			array, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("cannot resolve '%s' of json path '%s', not an array", element, path)
+			}
+			if index >= len(array) {
+				return nil, fmt.Errorf("cannot resolve '%s' of json path '%s', index out of range", element, path)
+			}
+			current = array[index]
+		}
+	}
+
+	return current, nil
+}
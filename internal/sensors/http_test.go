@@ -0,0 +1,61 @@
+package sensors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpSensor_GetValueJsonPath(t *testing.T) {
+	// GIVEN
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"sensors": [{"name": "ambient", "value": 21.5}]}`))
+	}))
+	defer server.Close()
+
+	sensor := HttpSensor{
+		Config: configuration.SensorConfig{
+			ID: "ambient",
+			Http: &configuration.HttpSensorConfig{
+				Url:      server.URL,
+				JsonPath: "$.sensors[0].value",
+				Scale:    1000,
+			},
+		},
+	}
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 21500.0, value)
+}
+
+func TestHttpSensor_GetValueRegex(t *testing.T) {
+	// GIVEN
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("temperature: 23.1 °C"))
+	}))
+	defer server.Close()
+
+	sensor := HttpSensor{
+		Config: configuration.SensorConfig{
+			ID: "ambient",
+			Http: &configuration.HttpSensorConfig{
+				Url:   server.URL,
+				Regex: `temperature: ([\d.]+)`,
+			},
+		},
+	}
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 23.1, value)
+}
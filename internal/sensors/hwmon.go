@@ -15,16 +15,16 @@ type HwmonSensor struct {
 	MovingAvg float64                    `json:"movingAvg"`
 }
 
-func (sensor HwmonSensor) GetId() string {
+func (sensor *HwmonSensor) GetId() string {
 	return sensor.Config.ID
 }
 
-func (sensor HwmonSensor) GetConfig() configuration.SensorConfig {
+func (sensor *HwmonSensor) GetConfig() configuration.SensorConfig {
 	return sensor.Config
 }
 
-func (sensor HwmonSensor) GetValue() (result float64, err error) {
-	integer, err := util.ReadIntFromFile(sensor.Input)
+func (sensor *HwmonSensor) GetValue() (result float64, err error) {
+	integer, err := util.ReadIntFromSysfs(sensor.Input)
 	if err != nil {
 		return 0, err
 	}
@@ -32,10 +32,14 @@ func (sensor HwmonSensor) GetValue() (result float64, err error) {
 	return result, err
 }
 
-func (sensor HwmonSensor) GetMovingAvg() (avg float64) {
+func (sensor *HwmonSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
 	return sensor.MovingAvg
 }
 
 func (sensor *HwmonSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
 	sensor.MovingAvg = avg
 }
@@ -0,0 +1,53 @@
+package sensors
+
+import (
+	"fmt"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/liquidctl"
+)
+
+type LiquidctlSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+}
+
+func (sensor *LiquidctlSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *LiquidctlSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *LiquidctlSensor) GetValue() (float64, error) {
+	config := sensor.Config.Liquidctl
+	record, err := liquidctl.GetValue(config.Liquidctl, config.Match, config.Key)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+
+	value, err := record.Float()
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+
+	// temperatures are reported in degrees
+	if record.Unit == "°C" {
+		value *= 1000
+	}
+
+	return value, nil
+}
+
+func (sensor *LiquidctlSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *LiquidctlSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
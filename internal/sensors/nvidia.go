@@ -0,0 +1,119 @@
+package sensors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+var (
+	nvmlInitOnce   sync.Once
+	nvmlInitResult nvml.Return
+)
+
+type NvidiaSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+}
+
+// initNvml loads and initializes the NVML library, this is only done once
+// for the lifetime of the process
+func initNvml() error {
+	nvmlInitOnce.Do(func() {
+		nvmlInitResult = nvml.Init()
+	})
+	if nvmlInitResult != nvml.SUCCESS {
+		return fmt.Errorf("unable to initialize NVML: %s", nvml.ErrorString(nvmlInitResult))
+	}
+	return nil
+}
+
+func (sensor *NvidiaSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *NvidiaSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *NvidiaSensor) getDevice() (nvml.Device, error) {
+	if err := initNvml(); err != nil {
+		return nvml.Device{}, err
+	}
+
+	var device nvml.Device
+	var ret nvml.Return
+	config := sensor.Config.Nvidia
+	if len(config.UUID) > 0 {
+		device, ret = nvml.DeviceGetHandleByUUID(config.UUID)
+	} else {
+		device, ret = nvml.DeviceGetHandleByIndex(config.Index)
+	}
+	if ret != nvml.SUCCESS {
+		return device, fmt.Errorf("sensor %s: unable to find GPU: %s", sensor.GetId(), nvml.ErrorString(ret))
+	}
+	return device, nil
+}
+
+func (sensor *NvidiaSensor) GetValue() (float64, error) {
+	device, err := sensor.getDevice()
+	if err != nil {
+		return 0, err
+	}
+
+	switch sensor.Config.Nvidia.Type {
+	case configuration.NvidiaSensorTypeMemory:
+		values := []nvml.FieldValue{{FieldId: nvml.FI_DEV_MEMORY_TEMP}}
+		ret := device.GetFieldValues(values)
+		if ret == nvml.SUCCESS && values[0].NvmlReturn != uint32(nvml.SUCCESS) {
+			ret = nvml.Return(values[0].NvmlReturn)
+		}
+		if ret != nvml.SUCCESS {
+			return 0, fmt.Errorf("sensor %s: unable to read memory temperature: %s", sensor.GetId(), nvml.ErrorString(ret))
+		}
+		return fieldValueToFloat(values[0]) * 1000, nil
+	case configuration.NvidiaSensorTypeUtilization:
+		utilization, ret := device.GetUtilizationRates()
+		if ret != nvml.SUCCESS {
+			return 0, fmt.Errorf("sensor %s: unable to read utilization: %s", sensor.GetId(), nvml.ErrorString(ret))
+		}
+		// scaled like temperatures, so curves see the percentage
+		return float64(utilization.Gpu) * 1000, nil
+	default:
+		temp, ret := device.GetTemperature(nvml.TEMPERATURE_GPU)
+		if ret != nvml.SUCCESS {
+			return 0, fmt.Errorf("sensor %s: unable to read core temperature: %s", sensor.GetId(), nvml.ErrorString(ret))
+		}
+		return float64(temp) * 1000, nil
+	}
+}
+
+func (sensor *NvidiaSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *NvidiaSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
+
+// fieldValueToFloat converts the raw value of an NVML field to a float, based on its value type
+func fieldValueToFloat(value nvml.FieldValue) float64 {
+	switch nvml.ValueType(value.ValueType) {
+	case nvml.VALUE_TYPE_DOUBLE:
+		return math.Float64frombits(binary.LittleEndian.Uint64(value.Value[:]))
+	case nvml.VALUE_TYPE_UNSIGNED_INT:
+		return float64(binary.LittleEndian.Uint32(value.Value[:]))
+	case nvml.VALUE_TYPE_SIGNED_LONG_LONG:
+		return float64(int64(binary.LittleEndian.Uint64(value.Value[:])))
+	default:
+		return float64(binary.LittleEndian.Uint64(value.Value[:]))
+	}
+}
@@ -0,0 +1,204 @@
+package sensors
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+const (
+	// nvmeAdminGetLogPage is the opcode of the "Get Log Page" admin command
+	nvmeAdminGetLogPage = 0x02
+	// nvmeLogSmartHealth is the id of the "SMART / Health Information" log page
+	nvmeLogSmartHealth = 0x02
+	// nvmeSmartLogSize is the size of the "SMART / Health Information" log page in bytes
+	nvmeSmartLogSize = 512
+	// nvmeIoctlAdminCmd is _IOWR('N', 0x41, struct nvme_admin_cmd)
+	nvmeIoctlAdminCmd = 0xC0484E41
+)
+
+var (
+	nvmeDevPath = "/dev"
+
+	nvmeControllerRegex = regexp.MustCompile(`^nvme\d+`)
+)
+
+type NvmeSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+
+	// TempInput is the hwmon temperature input of the drive, if the kernel exposes one
+	TempInput string `json:"tempInput"`
+	// DevicePath is the character device used to query the drive directly,
+	// if the kernel doesn't expose a hwmon temperature input for it
+	DevicePath string `json:"devicePath"`
+}
+
+// nvmeAdminCmd mirrors struct nvme_admin_cmd from linux/nvme_ioctl.h
+type nvmeAdminCmd struct {
+	opcode      uint8
+	flags       uint8
+	rsvd1       uint16
+	nsid        uint32
+	cdw2        uint32
+	cdw3        uint32
+	metadata    uint64
+	addr        uint64
+	metadataLen uint32
+	dataLen     uint32
+	cdw10       uint32
+	cdw11       uint32
+	cdw12       uint32
+	cdw13       uint32
+	cdw14       uint32
+	cdw15       uint32
+	timeoutMs   uint32
+	result      uint32
+}
+
+func NewNvmeSensor(config configuration.SensorConfig) (*NvmeSensor, error) {
+	controller, err := findNvmeController(*config.Nvme)
+	if err != nil {
+		return nil, fmt.Errorf("sensor %s: %v", config.ID, err)
+	}
+
+	index := config.Nvme.Index
+	if index <= 0 {
+		index = 1
+	}
+
+	sensor := &NvmeSensor{
+		Config:     config,
+		DevicePath: path.Join(nvmeDevPath, controller),
+	}
+
+	// the hwmon device is registered either on the nvme controller itself or,
+	// on older kernels, on the underlying pci device
+	for _, pattern := range []string{
+		configuration.GetSysfsPath("class", "nvme", controller, "hwmon*", fmt.Sprintf("temp%d_input", index)),
+		configuration.GetSysfsPath("class", "nvme", controller, "device", "hwmon", "hwmon*", fmt.Sprintf("temp%d_input", index)),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		if len(matches) > 0 {
+			sensor.TempInput = matches[0]
+			break
+		}
+	}
+
+	return sensor, nil
+}
+
+// findNvmeController returns the name (f.ex. "nvme0") of the controller matching the given config
+func findNvmeController(config configuration.NvmeSensorConfig) (string, error) {
+	if len(config.Device) > 0 {
+		name := nvmeControllerRegex.FindString(filepath.Base(config.Device))
+		if len(name) <= 0 {
+			return "", fmt.Errorf("invalid nvme device: %s", config.Device)
+		}
+		return name, nil
+	}
+
+	entries, err := os.ReadDir(configuration.GetSysfsPath("class", "nvme"))
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		content, err := os.ReadFile(configuration.GetSysfsPath("class", "nvme", entry.Name(), "serial"))
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(content)) == config.Serial {
+			return entry.Name(), nil
+		}
+	}
+
+	return "", fmt.Errorf("no nvme device with serial '%s' found", config.Serial)
+}
+
+func (sensor *NvmeSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *NvmeSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *NvmeSensor) GetValue() (float64, error) {
+	if len(sensor.TempInput) > 0 {
+		integer, err := util.ReadIntFromFile(sensor.TempInput)
+		if err != nil {
+			return 0, err
+		}
+		return float64(integer), nil
+	}
+
+	index := sensor.Config.Nvme.Index
+	if index <= 0 {
+		index = 1
+	}
+	return readNvmeTemperature(sensor.DevicePath, index)
+}
+
+func (sensor *NvmeSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *NvmeSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
+
+// readNvmeTemperature reads the SMART log page of the given nvme device
+// and returns the temperature of the sensor with the given index in milli-degrees celsius.
+// Index 1 is the composite temperature, 2..9 are "Temperature Sensor 1..8".
+func readNvmeTemperature(devicePath string, index int) (float64, error) {
+	if index > 9 {
+		return 0, fmt.Errorf("invalid nvme temperature sensor index: %d", index)
+	}
+
+	file, err := os.Open(devicePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	data := make([]byte, nvmeSmartLogSize)
+	cmd := nvmeAdminCmd{
+		opcode:  nvmeAdminGetLogPage,
+		nsid:    0xFFFFFFFF,
+		addr:    uint64(uintptr(unsafe.Pointer(&data[0]))),
+		dataLen: uint32(len(data)),
+		// number of dwords to read (0 based) and log page id
+		cdw10: uint32(len(data)/4-1)<<16 | nvmeLogSmartHealth,
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), nvmeIoctlAdminCmd, uintptr(unsafe.Pointer(&cmd)))
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return 0, fmt.Errorf("unable to read smart log of %s: %v", devicePath, errno)
+	}
+
+	offset := 1
+	if index > 1 {
+		offset = 200 + (index-2)*2
+	}
+	kelvin := binary.LittleEndian.Uint16(data[offset : offset+2])
+	if kelvin == 0 {
+		return 0, fmt.Errorf("temperature sensor %d of %s is not implemented", index, devicePath)
+	}
+
+	return (float64(kelvin) - 273.15) * 1000, nil
+}
@@ -0,0 +1,56 @@
+package sensors
+
+import (
+	"path"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func createNvmeSysfs(t *testing.T, controller string, serial string, temp string) string {
+	root := t.TempDir()
+	controllerPath := path.Join("class", "nvme", controller)
+	err := util.WriteSysfsFixture(root, map[string]string{
+		path.Join(controllerPath, "serial"):                serial,
+		path.Join(controllerPath, "hwmon3", "temp1_input"): temp,
+	})
+	assert.NoError(t, err)
+	return root
+}
+
+func TestNvmeSensor_FindControllerByDevicePath(t *testing.T) {
+	// GIVEN
+	config := configuration.NvmeSensorConfig{
+		Device: "/dev/nvme1n1",
+	}
+
+	// WHEN
+	controller, err := findNvmeController(config)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "nvme1", controller)
+}
+
+func TestNvmeSensor_GetValueBySerial(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.SysfsRoot = createNvmeSysfs(t, "nvme0", "S4EWNX0R123456", "38850")
+	config := configuration.SensorConfig{
+		ID: "ssd",
+		Nvme: &configuration.NvmeSensorConfig{
+			Serial: "S4EWNX0R123456",
+		},
+	}
+
+	// WHEN
+	sensor, err := NewNvmeSensor(config)
+	assert.NoError(t, err)
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, "/dev/nvme0", sensor.DevicePath)
+	assert.Equal(t, 38850.0, value)
+}
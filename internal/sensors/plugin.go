@@ -0,0 +1,57 @@
+package sensors
+
+import (
+	"fmt"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/plugins"
+)
+
+type PluginSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+
+	Plugin plugins.Plugin `json:"-"`
+}
+
+// NewPluginSensor returns a sensor whose value is read by the plugin of the given config
+func NewPluginSensor(config configuration.SensorConfig) (*PluginSensor, error) {
+	plugin, err := plugins.Find(configuration.GetPluginDir(), config.Plugin.Name)
+	if err != nil {
+		return nil, fmt.Errorf("sensor %s: %v", config.ID, err)
+	}
+
+	return &PluginSensor{
+		Config: config,
+		Plugin: plugin,
+	}, nil
+}
+
+func (sensor *PluginSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *PluginSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *PluginSensor) GetValue() (float64, error) {
+	config := sensor.Config.Plugin
+	value, err := sensor.Plugin.GetValue(plugins.ActionGetValue, config.Options, config.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+	return value, nil
+}
+
+func (sensor *PluginSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *PluginSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
@@ -0,0 +1,189 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+const (
+	defaultRaplZone = "package-0"
+	// minRaplInterval is the minimum time between two readings of the energy counter,
+	// shorter intervals result in imprecise power values
+	minRaplInterval = 100 * time.Millisecond
+)
+
+var raplPackagePattern = regexp.MustCompile(`^package-(\d+)$`)
+
+// RaplSensor reports the power drawn by a RAPL (Running Average Power Limit) domain in milli-watts,
+// computed from the energy counter of the domain
+type RaplSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+
+	// EnergyInput is the file containing the energy counter of the domain in micro-joules
+	EnergyInput string `json:"energyInput"`
+
+	// maxEnergy is the value at which the energy counter wraps around, 0 if unknown
+	maxEnergy  float64
+	lastEnergy float64
+	lastTime   time.Time
+	lastValue  *float64
+	lock       sync.Mutex
+}
+
+func NewRaplSensor(config configuration.SensorConfig) (*RaplSensor, error) {
+	zone := config.Rapl.Zone
+	if len(zone) <= 0 {
+		zone = defaultRaplZone
+	}
+
+	sensor, err := findPowercapZone(zone)
+	if err == nil && sensor == nil {
+		sensor, err = findAmdEnergyCounter(zone)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sensor %s: %v", config.ID, err)
+	}
+	if sensor == nil {
+		return nil, fmt.Errorf("sensor %s: no RAPL zone '%s' found", config.ID, zone)
+	}
+	sensor.Config = config
+
+	// the power is computed from the difference to the previous reading
+	sensor.lastEnergy, err = util.ReadFloatFromFile(sensor.EnergyInput)
+	if err != nil {
+		return nil, fmt.Errorf("sensor %s: unable to read energy counter: %v", config.ID, err)
+	}
+	sensor.lastTime = time.Now()
+
+	return sensor, nil
+}
+
+// findPowercapZone returns a sensor reading the powercap zone with the given name or directory,
+// nil if there is none
+func findPowercapZone(zone string) (*RaplSensor, error) {
+	zones, err := filepath.Glob(configuration.GetSysfsPath("class", "powercap", "*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, zonePath := range zones {
+		name, err := os.ReadFile(path.Join(zonePath, "name"))
+		if err != nil {
+			continue
+		}
+		if filepath.Base(zonePath) != zone && strings.TrimSpace(string(name)) != zone {
+			continue
+		}
+
+		maxEnergy, err := util.ReadFloatFromFile(path.Join(zonePath, "max_energy_range_uj"))
+		if err != nil {
+			maxEnergy = 0
+		}
+		return &RaplSensor{
+			EnergyInput: path.Join(zonePath, "energy_uj"),
+			maxEnergy:   maxEnergy,
+		}, nil
+	}
+	return nil, nil
+}
+
+// findAmdEnergyCounter returns a sensor reading the counter of the amd_energy driver with the given label,
+// "package-N" is an alias for "EsocketN", returns nil if there is none
+func findAmdEnergyCounter(zone string) (*RaplSensor, error) {
+	label := zone
+	if match := raplPackagePattern.FindStringSubmatch(zone); match != nil {
+		label = "Esocket" + match[1]
+	}
+
+	devices, err := filepath.Glob(configuration.GetSysfsPath("class", "hwmon", "hwmon*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, device := range devices {
+		name, err := os.ReadFile(path.Join(device, "name"))
+		if err != nil || strings.TrimSpace(string(name)) != "amd_energy" {
+			continue
+		}
+		labels, err := filepath.Glob(path.Join(device, "energy*_label"))
+		if err != nil {
+			return nil, err
+		}
+		for _, labelPath := range labels {
+			content, err := os.ReadFile(labelPath)
+			if err != nil || strings.TrimSpace(string(content)) != label {
+				continue
+			}
+			// the driver accumulates the counter in 64 bit, it doesn't wrap around
+			return &RaplSensor{
+				EnergyInput: strings.TrimSuffix(labelPath, "_label") + "_input",
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (sensor *RaplSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *RaplSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+// GetValue returns the average power (in milli-watts) drawn since the previous call
+func (sensor *RaplSensor) GetValue() (float64, error) {
+	sensor.lock.Lock()
+	defer sensor.lock.Unlock()
+
+	elapsed := time.Since(sensor.lastTime)
+	if elapsed < minRaplInterval {
+		if sensor.lastValue != nil {
+			return *sensor.lastValue, nil
+		}
+		// first reading right after creating the sensor
+		time.Sleep(minRaplInterval - elapsed)
+	}
+
+	energy, err := util.ReadFloatFromFile(sensor.EnergyInput)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: unable to read energy counter: %v", sensor.GetId(), err)
+	}
+	now := time.Now()
+
+	delta := energy - sensor.lastEnergy
+	if delta < 0 {
+		// the counter wrapped around
+		delta = energy
+		if sensor.maxEnergy > 0 {
+			delta += sensor.maxEnergy - sensor.lastEnergy
+		}
+	}
+
+	// micro-joules per second = micro-watts
+	value := delta / now.Sub(sensor.lastTime).Seconds() / 1000
+	sensor.lastEnergy = energy
+	sensor.lastTime = now
+	sensor.lastValue = &value
+	return value, nil
+}
+
+func (sensor *RaplSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *RaplSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
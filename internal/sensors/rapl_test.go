@@ -0,0 +1,150 @@
+package sensors
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func createPowercapSysfs(t *testing.T) string {
+	root := t.TempDir()
+	files := map[string]string{}
+	for zone, name := range map[string]string{
+		"intel-rapl:0":   "package-0",
+		"intel-rapl:0:0": "core",
+	} {
+		zonePath := path.Join("class", "powercap", zone)
+		files[path.Join(zonePath, "name")] = name
+		files[path.Join(zonePath, "energy_uj")] = "1000000"
+		files[path.Join(zonePath, "max_energy_range_uj")] = "262143328850"
+	}
+	err := util.WriteSysfsFixture(root, files)
+	assert.NoError(t, err)
+	return root
+}
+
+func createRaplSensor(t *testing.T, zone string) *RaplSensor {
+	sensor, err := NewRaplSensor(configuration.SensorConfig{
+		ID:   "power",
+		Rapl: &configuration.RaplSensorConfig{Zone: zone},
+	})
+	assert.NoError(t, err)
+	return sensor
+}
+
+func TestRaplSensor_FindZone(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.SysfsRoot = createPowercapSysfs(t)
+	defer func() {
+		configuration.CurrentConfig.SysfsRoot = ""
+	}()
+
+	for zone, expected := range map[string]string{
+		"":               "intel-rapl:0",
+		"core":           "intel-rapl:0:0",
+		"intel-rapl:0:0": "intel-rapl:0:0",
+	} {
+		// WHEN
+		sensor := createRaplSensor(t, zone)
+
+		// THEN
+		assert.Equal(t, path.Join(configuration.CurrentConfig.SysfsRoot, "class", "powercap", expected, "energy_uj"), sensor.EnergyInput, zone)
+		assert.Equal(t, 262143328850.0, sensor.maxEnergy)
+		assert.Equal(t, 1000000.0, sensor.lastEnergy)
+	}
+}
+
+func TestRaplSensor_FindAmdEnergy(t *testing.T) {
+	// GIVEN
+	root := t.TempDir()
+	err := util.WriteSysfsFixture(root, map[string]string{
+		"class/hwmon/hwmon3/name":           "amd_energy",
+		"class/hwmon/hwmon3/energy1_label":  "Ecore000",
+		"class/hwmon/hwmon3/energy1_input":  "5000",
+		"class/hwmon/hwmon3/energy17_label": "Esocket0",
+		"class/hwmon/hwmon3/energy17_input": "9000",
+	})
+	assert.NoError(t, err)
+	devicePath := path.Join(root, "class", "hwmon", "hwmon3")
+	configuration.CurrentConfig.SysfsRoot = root
+	defer func() {
+		configuration.CurrentConfig.SysfsRoot = ""
+	}()
+
+	// WHEN
+	sensor := createRaplSensor(t, "package-0")
+
+	// THEN
+	assert.Equal(t, path.Join(devicePath, "energy17_input"), sensor.EnergyInput)
+	assert.Equal(t, 9000.0, sensor.lastEnergy)
+}
+
+func TestRaplSensor_ZoneNotFound(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.SysfsRoot = createPowercapSysfs(t)
+	defer func() {
+		configuration.CurrentConfig.SysfsRoot = ""
+	}()
+
+	// WHEN
+	_, err := NewRaplSensor(configuration.SensorConfig{
+		ID:   "power",
+		Rapl: &configuration.RaplSensorConfig{Zone: "dram"},
+	})
+
+	// THEN
+	assert.EqualError(t, err, "sensor power: no RAPL zone 'dram' found")
+}
+
+func TestRaplSensor_GetValue(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.SysfsRoot = createPowercapSysfs(t)
+	defer func() {
+		configuration.CurrentConfig.SysfsRoot = ""
+	}()
+	sensor := createRaplSensor(t, "package-0")
+	sensor.lastTime = time.Now().Add(-2 * time.Second)
+	// 90 J in 2 s
+	_ = os.WriteFile(sensor.EnergyInput, []byte("91000000\n"), 0644)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.InDelta(t, 45000.0, value, 100)
+
+	// WHEN
+	// read again immediately
+	again, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, value, again)
+}
+
+func TestRaplSensor_GetValueWrapAround(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.SysfsRoot = createPowercapSysfs(t)
+	defer func() {
+		configuration.CurrentConfig.SysfsRoot = ""
+	}()
+	sensor := createRaplSensor(t, "package-0")
+	sensor.maxEnergy = 100000000
+	sensor.lastEnergy = 90000000
+	sensor.lastTime = time.Now().Add(-time.Second)
+	// 10 J until the wrap around + 10 J
+	_ = os.WriteFile(sensor.EnergyInput, []byte("10000000\n"), 0644)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.InDelta(t, 20000.0, value, 100)
+}
@@ -0,0 +1,52 @@
+package sensors
+
+import (
+	"fmt"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/redfish"
+)
+
+// RedfishSensor reads a temperature from the Thermal resource of the Redfish API of a BMC
+type RedfishSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+
+	client *redfish.Client
+}
+
+func NewRedfishSensor(config configuration.SensorConfig) *RedfishSensor {
+	return &RedfishSensor{
+		Config: config,
+		client: redfish.NewClient(config.Redfish.RedfishConfig),
+	}
+}
+
+func (sensor *RedfishSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *RedfishSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *RedfishSensor) GetValue() (float64, error) {
+	value, err := sensor.client.GetTemperature(sensor.Config.Redfish.Sensor)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+	// temperatures are reported in degrees
+	return value * 1000, nil
+}
+
+func (sensor *RedfishSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *RedfishSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
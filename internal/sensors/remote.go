@@ -0,0 +1,118 @@
+package sensors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/grpc/pb"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const defaultRemoteTimeout = 2 * time.Second
+
+// RemoteSensor reads a sensor of another fan2go instance, f.ex. a `fan2go agent`, using its gRPC api
+type RemoteSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+
+	client pb.Fan2GoClient
+}
+
+func NewRemoteSensor(config configuration.SensorConfig) (*RemoteSensor, error) {
+	options, err := remoteDialOptions(*config.Remote)
+	if err != nil {
+		return nil, fmt.Errorf("sensor %s: %v", config.ID, err)
+	}
+	// the connection is established lazily and re-established after errors by grpc itself
+	conn, err := grpclib.Dial(config.Remote.Address, options...)
+	if err != nil {
+		return nil, fmt.Errorf("sensor %s: %v", config.ID, err)
+	}
+	return &RemoteSensor{
+		Config: config,
+		client: pb.NewFan2GoClient(conn),
+	}, nil
+}
+
+// remoteDialOptions returns the transport credentials and the token of the given config
+func remoteDialOptions(config configuration.RemoteSensorConfig) ([]grpclib.DialOption, error) {
+	if config.Tls == nil {
+		return []grpclib.DialOption{grpclib.WithTransportCredentials(insecure.NewCredentials())}, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: config.Tls.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if len(config.Tls.CaFile) > 0 {
+		data, err := os.ReadFile(config.Tls.CaFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificate found in %s", config.Tls.CaFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	options := []grpclib.DialOption{grpclib.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+	if len(config.Token) > 0 {
+		options = append(options, grpclib.WithPerRPCCredentials(tokenCredentials(config.Token)))
+	}
+	return options, nil
+}
+
+// tokenCredentials sends a token as "authorization: Bearer <token>" metadata with each call
+type tokenCredentials string
+
+func (token tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(token)}, nil
+}
+
+func (token tokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+func (sensor *RemoteSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *RemoteSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+// GetValue returns the moving average of the remote sensor, the remote instance polls and smooths it
+func (sensor *RemoteSensor) GetValue() (float64, error) {
+	timeout := sensor.Config.Remote.Timeout
+	if timeout <= 0 {
+		timeout = defaultRemoteTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := sensor.client.GetSensor(ctx, &pb.GetSensorRequest{Id: sensor.Config.Remote.Sensor})
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+	return result.MovingAvg, nil
+}
+
+func (sensor *RemoteSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *RemoteSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
@@ -0,0 +1,165 @@
+package sensors
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/grpc/pb"
+	"github.com/stretchr/testify/assert"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type agentServer struct {
+	pb.UnimplementedFan2GoServer
+}
+
+func (s *agentServer) GetSensor(ctx context.Context, request *pb.GetSensorRequest) (*pb.Sensor, error) {
+	if request.Id != "cpu" {
+		return nil, status.Errorf(codes.NotFound, "no item with id '%s' found", request.Id)
+	}
+	return &pb.Sensor{Id: request.Id, MovingAvg: 42000}, nil
+}
+
+// createAgent serves a sensor "cpu" on a random local port, returns its address
+func createAgent(t *testing.T, options ...grpclib.ServerOption) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpclib.NewServer(options...)
+	pb.RegisterFan2GoServer(server, &agentServer{})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+// createCertificate writes a self-signed certificate for 127.0.0.1 and its key to a temporary directory
+func createCertificate(t *testing.T) (certFile string, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "agent"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyData, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "agent.crt")
+	keyFile = filepath.Join(dir, "agent.key")
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData}), 0600)
+	assert.NoError(t, err)
+	return certFile, keyFile
+}
+
+// requireToken rejects requests without the given bearer token
+func requireToken(token string) grpclib.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get("authorization"); len(values) != 1 || values[0] != "Bearer "+token {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+func TestRemoteSensor_GetValue(t *testing.T) {
+	// GIVEN
+	sensor, err := NewRemoteSensor(configuration.SensorConfig{
+		ID: "node_cpu",
+		Remote: &configuration.RemoteSensorConfig{
+			Address: createAgent(t),
+			Sensor:  "cpu",
+		},
+	})
+	assert.NoError(t, err)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 42000.0, value)
+}
+
+func TestRemoteSensor_NotFound(t *testing.T) {
+	// GIVEN
+	sensor, err := NewRemoteSensor(configuration.SensorConfig{
+		ID: "node_gpu",
+		Remote: &configuration.RemoteSensorConfig{
+			Address: createAgent(t),
+			Sensor:  "gpu",
+		},
+	})
+	assert.NoError(t, err)
+
+	// WHEN
+	_, err = sensor.GetValue()
+
+	// THEN
+	assert.EqualError(t, err, "sensor node_gpu: rpc error: code = NotFound desc = no item with id 'gpu' found")
+}
+
+func TestRemoteSensor_TlsAndToken(t *testing.T) {
+	// GIVEN
+	certFile, keyFile := createCertificate(t)
+	creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+	assert.NoError(t, err)
+	address := createAgent(t, grpclib.Creds(creds), grpclib.UnaryInterceptor(requireToken("secret")))
+
+	sensor, err := NewRemoteSensor(configuration.SensorConfig{
+		ID: "node_cpu",
+		Remote: &configuration.RemoteSensorConfig{
+			Address: address,
+			Sensor:  "cpu",
+			Tls:     &configuration.RemoteTlsConfig{CaFile: certFile},
+			Token:   "secret",
+		},
+	})
+	assert.NoError(t, err)
+	unauthenticated, err := NewRemoteSensor(configuration.SensorConfig{
+		ID: "node_cpu",
+		Remote: &configuration.RemoteSensorConfig{
+			Address: address,
+			Sensor:  "cpu",
+			Tls:     &configuration.RemoteTlsConfig{CaFile: certFile},
+		},
+	})
+	assert.NoError(t, err)
+
+	// WHEN
+	value, err := sensor.GetValue()
+	_, unauthenticatedErr := unauthenticated.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 42000.0, value)
+	assert.EqualError(t, unauthenticatedErr, "sensor node_cpu: rpc error: code = Unauthenticated desc = missing or invalid token")
+}
@@ -0,0 +1,154 @@
+package sensors
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+const (
+	defaultSmartctlPath = "/usr/sbin/smartctl"
+	smartctlTimeout     = 10 * time.Second
+	// smartctlExitStandby is the exit status bit set by smartctl when the device
+	// is in standby mode and "-n standby" was used
+	smartctlExitStandby = 1 << 1
+)
+
+type SmartSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+
+	// Device is the device path of the drive
+	Device string `json:"device"`
+
+	lastValue  float64
+	lastUpdate time.Time
+	lock       sync.Mutex
+}
+
+// smartctlOutput is the subset of the json output of smartctl used by fan2go
+type smartctlOutput struct {
+	Devices []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"devices"`
+	SerialNumber string `json:"serial_number"`
+	Temperature  *struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+}
+
+func NewSmartSensor(config configuration.SensorConfig) (*SmartSensor, error) {
+	sensor := &SmartSensor{
+		Config: config,
+		Device: config.Smart.Device,
+	}
+
+	if len(sensor.Device) <= 0 {
+		device, err := sensor.findDeviceBySerial(config.Smart.Serial)
+		if err != nil {
+			return nil, fmt.Errorf("sensor %s: %v", config.ID, err)
+		}
+		sensor.Device = device
+	}
+
+	return sensor, nil
+}
+
+func (sensor *SmartSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *SmartSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+// GetValue returns the temperature of the drive in milli-degrees. SMART is only queried
+// once per configured interval, and never while the drive is in standby.
+func (sensor *SmartSensor) GetValue() (float64, error) {
+	sensor.lock.Lock()
+	defer sensor.lock.Unlock()
+
+	interval := sensor.Config.Smart.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if !sensor.lastUpdate.IsZero() && time.Since(sensor.lastUpdate) < interval {
+		return sensor.lastValue, nil
+	}
+
+	output, exitCode, err := sensor.smartctl("-j", "-A", "-n", "standby", sensor.Device)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+	sensor.lastUpdate = time.Now()
+
+	if output.Temperature == nil {
+		if exitCode&smartctlExitStandby != 0 {
+			// the drive is sleeping, don't wake it up just to read its temperature
+			return sensor.lastValue, nil
+		}
+		return 0, fmt.Errorf("sensor %s: no temperature reported for %s", sensor.GetId(), sensor.Device)
+	}
+
+	sensor.lastValue = float64(output.Temperature.Current) * 1000
+	return sensor.lastValue, nil
+}
+
+func (sensor *SmartSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *SmartSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
+
+// findDeviceBySerial scans all devices known to smartctl for one with the given serial number
+func (sensor *SmartSensor) findDeviceBySerial(serial string) (string, error) {
+	scan, _, err := sensor.smartctl("-j", "--scan")
+	if err != nil {
+		return "", err
+	}
+
+	for _, device := range scan.Devices {
+		info, _, err := sensor.smartctl("-j", "-i", "-n", "standby", "-d", device.Type, device.Name)
+		if err != nil {
+			continue
+		}
+		if info.SerialNumber == serial {
+			return device.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("no drive with serial '%s' found", serial)
+}
+
+// smartctl runs smartctl with the given arguments and parses its json output.
+// The exit code is returned as well, since smartctl uses it as a bitmask
+// for the drive status, even if the output is valid.
+func (sensor *SmartSensor) smartctl(args ...string) (*smartctlOutput, int, error) {
+	executable := sensor.Config.Smart.Smartctl
+	if len(executable) <= 0 {
+		executable = defaultSmartctlPath
+	}
+
+	out, exitCode, err := util.SafeCmdExecutionWithExitCode(executable, args, smartctlTimeout)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := &smartctlOutput{}
+	if err := json.Unmarshal([]byte(out), result); err != nil {
+		return nil, exitCode, fmt.Errorf("unable to parse output of %s: %v", executable, err)
+	}
+
+	return result, exitCode, nil
+}
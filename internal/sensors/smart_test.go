@@ -0,0 +1,81 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmartSensor_GetValueUsesLastValueWithinInterval(t *testing.T) {
+	// GIVEN
+	sensor := &SmartSensor{
+		Config: configuration.SensorConfig{
+			ID: "hdd",
+			Smart: &configuration.SmartSensorConfig{
+				Device:   "/dev/sda",
+				Smartctl: "/does/not/exist",
+				Interval: time.Hour,
+			},
+		},
+		Device:     "/dev/sda",
+		lastValue:  35000,
+		lastUpdate: time.Now(),
+	}
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 35000.0, value)
+}
+
+// createSmartSensor returns a sensor using a fake smartctl, which prints the given output
+// and exits with the given code
+func createSmartSensor(t *testing.T, output string, exitCode int) *SmartSensor {
+	smartctl := path.Join(t.TempDir(), "smartctl")
+	script := fmt.Sprintf("#!/bin/sh\necho '%s'\nexit %d\n", output, exitCode)
+	err := os.WriteFile(smartctl, []byte(script), 0755)
+	assert.NoError(t, err)
+
+	return &SmartSensor{
+		Config: configuration.SensorConfig{
+			ID: "hdd",
+			Smart: &configuration.SmartSensorConfig{
+				Device:   "/dev/sda",
+				Smartctl: smartctl,
+			},
+		},
+		Device: "/dev/sda",
+	}
+}
+
+func TestSmartSensor_GetValue(t *testing.T) {
+	// GIVEN
+	sensor := createSmartSensor(t, `{"temperature":{"current":38}}`, 0)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 38000.0, value)
+}
+
+func TestSmartSensor_GetValueKeepsLastValueInStandby(t *testing.T) {
+	// GIVEN
+	sensor := createSmartSensor(t, `{}`, smartctlExitStandby)
+	sensor.lastValue = 35000
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 35000.0, value)
+}
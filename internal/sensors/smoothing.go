@@ -0,0 +1,49 @@
+package sensors
+
+import (
+	"math"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+// GetRollingWindowSize returns the number of values the moving average of a sensor with the given config
+// is computed over
+func GetRollingWindowSize(config configuration.SensorConfig) int {
+	n := configuration.CurrentConfig.TempRollingWindowSize
+	if config.Smoothing == nil {
+		return n
+	}
+
+	if config.Smoothing.Window > 0 {
+		pollingRate := configuration.CurrentConfig.TempSensorPollingRate
+		if config.PollingRate > 0 {
+			pollingRate = config.PollingRate
+		}
+		if pollingRate > 0 {
+			return int(math.Max(1, math.Round(float64(config.Smoothing.Window)/float64(pollingRate))))
+		}
+	}
+	if config.Smoothing.WindowSize > 0 {
+		return config.Smoothing.WindowSize
+	}
+	return n
+}
+
+// UpdateMovingAvg adds the given value to the moving average of the given sensor,
+// using the smoothing algorithm configured for the sensor
+func UpdateMovingAvg(s Sensor, value float64) {
+	config := s.GetConfig()
+	lastAvg := s.GetMovingAvg()
+	if config.Smoothing != nil && config.Smoothing.Type == configuration.SmoothingTypeEwma {
+		alpha := config.Smoothing.Alpha
+		if value > lastAvg && config.Smoothing.AlphaRising > 0 {
+			alpha = config.Smoothing.AlphaRising
+		}
+		s.SetMovingAvg(util.UpdateExponentialMovingAvg(lastAvg, alpha, value))
+		return
+	}
+
+	n := GetRollingWindowSize(config)
+	s.SetMovingAvg(util.UpdateSimpleMovingAvg(lastAvg, n, value))
+}
@@ -0,0 +1,56 @@
+package sensors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRollingWindowSize(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.TempRollingWindowSize = 10
+	configuration.CurrentConfig.TempSensorPollingRate = 200 * time.Millisecond
+
+	// WHEN
+	defaultSize := GetRollingWindowSize(configuration.SensorConfig{})
+	fixedSize := GetRollingWindowSize(configuration.SensorConfig{
+		Smoothing: &configuration.SmoothingConfig{WindowSize: 3},
+	})
+	timeWindow := GetRollingWindowSize(configuration.SensorConfig{
+		Smoothing: &configuration.SmoothingConfig{Window: 5 * time.Second},
+	})
+	timeWindowWithPollingRate := GetRollingWindowSize(configuration.SensorConfig{
+		PollingRate: 30 * time.Second,
+		Smoothing:   &configuration.SmoothingConfig{WindowSize: 3, Window: 5 * time.Second},
+	})
+
+	// THEN
+	assert.Equal(t, 10, defaultSize)
+	assert.Equal(t, 3, fixedSize)
+	assert.Equal(t, 25, timeWindow)
+	assert.Equal(t, 1, timeWindowWithPollingRate)
+}
+
+func TestUpdateMovingAvgEwmaReactsFasterToRisingValues(t *testing.T) {
+	// GIVEN
+	config := configuration.SensorConfig{
+		ID: "ewma",
+		Smoothing: &configuration.SmoothingConfig{
+			Type:        configuration.SmoothingTypeEwma,
+			Alpha:       0.25,
+			AlphaRising: 0.5,
+		},
+	}
+	rising := &FileSensor{Config: config, MovingAvg: 40000}
+	falling := &FileSensor{Config: config, MovingAvg: 40000}
+
+	// WHEN
+	UpdateMovingAvg(rising, 60000)
+	UpdateMovingAvg(falling, 20000)
+
+	// THEN
+	assert.Equal(t, 50000.0, rising.GetMovingAvg())
+	assert.Equal(t, 35000.0, falling.GetMovingAvg())
+}
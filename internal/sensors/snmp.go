@@ -0,0 +1,173 @@
+package sensors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+var (
+	snmpVersions = map[string]gosnmp.SnmpVersion{
+		configuration.SnmpVersion1:  gosnmp.Version1,
+		configuration.SnmpVersion2c: gosnmp.Version2c,
+		configuration.SnmpVersion3:  gosnmp.Version3,
+	}
+	snmpAuthProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+		"":       gosnmp.NoAuth,
+		"MD5":    gosnmp.MD5,
+		"SHA":    gosnmp.SHA,
+		"SHA224": gosnmp.SHA224,
+		"SHA256": gosnmp.SHA256,
+		"SHA384": gosnmp.SHA384,
+		"SHA512": gosnmp.SHA512,
+	}
+	snmpPrivProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+		"":       gosnmp.NoPriv,
+		"DES":    gosnmp.DES,
+		"AES":    gosnmp.AES,
+		"AES192": gosnmp.AES192,
+		"AES256": gosnmp.AES256,
+	}
+)
+
+type SnmpSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+}
+
+func (sensor *SnmpSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *SnmpSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *SnmpSensor) GetValue() (float64, error) {
+	config := sensor.Config.Snmp
+
+	client, err := newSnmpClient(*config)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+
+	err = client.Connect()
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: unable to connect to %s: %v", sensor.GetId(), config.Host, err)
+	}
+	defer client.Conn.Close()
+
+	result, err := client.Get([]string{config.Oid})
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: unable to get %s: %v", sensor.GetId(), config.Oid, err)
+	}
+	if len(result.Variables) <= 0 {
+		return 0, fmt.Errorf("sensor %s: no value returned for %s", sensor.GetId(), config.Oid)
+	}
+
+	value, err := snmpPduToFloat(result.Variables[0])
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+
+	scale := config.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	return value * scale, nil
+}
+
+func (sensor *SnmpSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *SnmpSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
+
+func newSnmpClient(config configuration.SnmpSensorConfig) (*gosnmp.GoSNMP, error) {
+	port := config.Port
+	if port <= 0 {
+		port = 161
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	version, ok := snmpVersions[config.Version]
+	if !ok {
+		version = gosnmp.Version2c
+	}
+
+	community := config.Community
+	if len(community) <= 0 {
+		community = "public"
+	}
+
+	client := &gosnmp.GoSNMP{
+		Target:    config.Host,
+		Port:      uint16(port),
+		Version:   version,
+		Community: community,
+		Timeout:   timeout,
+		Retries:   1,
+	}
+
+	if version == gosnmp.Version3 {
+		authProtocol, ok := snmpAuthProtocols[strings.ToUpper(config.AuthProtocol)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported auth protocol: %s", config.AuthProtocol)
+		}
+		privProtocol, ok := snmpPrivProtocols[strings.ToUpper(config.PrivProtocol)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported priv protocol: %s", config.PrivProtocol)
+		}
+
+		client.SecurityModel = gosnmp.UserSecurityModel
+		client.MsgFlags = gosnmp.NoAuthNoPriv
+		if authProtocol != gosnmp.NoAuth {
+			client.MsgFlags = gosnmp.AuthNoPriv
+			if privProtocol != gosnmp.NoPriv {
+				client.MsgFlags = gosnmp.AuthPriv
+			}
+		}
+		client.SecurityParameters = &gosnmp.UsmSecurityParameters{
+			UserName:                 config.Username,
+			AuthenticationProtocol:   authProtocol,
+			AuthenticationPassphrase: config.AuthPassword,
+			PrivacyProtocol:          privProtocol,
+			PrivacyPassphrase:        config.PrivPassword,
+		}
+	}
+
+	return client, nil
+}
+
+// snmpPduToFloat converts the value of the given pdu to a float
+func snmpPduToFloat(pdu gosnmp.SnmpPDU) (float64, error) {
+	switch pdu.Type {
+	case gosnmp.OctetString:
+		text := strings.TrimSpace(string(pdu.Value.([]byte)))
+		return strconv.ParseFloat(text, 64)
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64, gosnmp.Uinteger32:
+		value, _ := gosnmp.ToBigInt(pdu.Value).Float64()
+		return value, nil
+	case gosnmp.OpaqueFloat:
+		return float64(pdu.Value.(float32)), nil
+	case gosnmp.OpaqueDouble:
+		return pdu.Value.(float64), nil
+	default:
+		return 0, fmt.Errorf("unsupported value type %v of %s", pdu.Type, pdu.Name)
+	}
+}
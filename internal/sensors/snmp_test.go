@@ -0,0 +1,28 @@
+package sensors
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnmpSensor_PduToFloat(t *testing.T) {
+	// GIVEN
+	pdus := []gosnmp.SnmpPDU{
+		{Name: "integer", Type: gosnmp.Integer, Value: 42},
+		{Name: "gauge", Type: gosnmp.Gauge32, Value: uint(43)},
+		{Name: "string", Type: gosnmp.OctetString, Value: []byte("44.5 ")},
+	}
+
+	// WHEN
+	var values []float64
+	for _, pdu := range pdus {
+		value, err := snmpPduToFloat(pdu)
+		assert.NoError(t, err)
+		values = append(values, value)
+	}
+
+	// THEN
+	assert.Equal(t, []float64{42, 43, 44.5}, values)
+}
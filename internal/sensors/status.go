@@ -0,0 +1,58 @@
+package sensors
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	// failingSince maps from sensor id -> time of the first failed read since the last successful one
+	failingSince     = map[string]time.Time{}
+	failingSinceLock sync.Mutex
+
+	// lastValues maps from sensor id -> the last value that was read successfully
+	lastValues     = map[string]LastValue{}
+	lastValuesLock sync.Mutex
+)
+
+// LastValue is a value read from a sensor
+type LastValue struct {
+	Value float64   `json:"value"`
+	Time  time.Time `json:"time"`
+}
+
+// ReportReadResult records whether the last read of the given sensor was successful
+func ReportReadResult(id string, err error) {
+	failingSinceLock.Lock()
+	defer failingSinceLock.Unlock()
+	if err == nil {
+		delete(failingSince, id)
+		return
+	}
+	if _, ok := failingSince[id]; !ok {
+		failingSince[id] = time.Now()
+	}
+}
+
+// GetFailingSince returns the time since which the given sensor cannot be read, if it is currently failing
+func GetFailingSince(id string) (time.Time, bool) {
+	failingSinceLock.Lock()
+	defer failingSinceLock.Unlock()
+	since, ok := failingSince[id]
+	return since, ok
+}
+
+// ReportValue records the given value as the last value read from the given sensor
+func ReportValue(id string, value float64) {
+	lastValuesLock.Lock()
+	defer lastValuesLock.Unlock()
+	lastValues[id] = LastValue{Value: value, Time: time.Now()}
+}
+
+// GetLastValue returns the last value read from the given sensor, if it was read at least once
+func GetLastValue(id string) (LastValue, bool) {
+	lastValuesLock.Lock()
+	defer lastValuesLock.Unlock()
+	value, ok := lastValues[id]
+	return value, ok
+}
@@ -0,0 +1,45 @@
+package sensors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportReadResult(t *testing.T) {
+	// GIVEN
+	id := "status_sensor"
+
+	// WHEN
+	ReportReadResult(id, errors.New("unreadable"))
+	firstSince, firstFailing := GetFailingSince(id)
+	ReportReadResult(id, errors.New("still unreadable"))
+	secondSince, secondFailing := GetFailingSince(id)
+	ReportReadResult(id, nil)
+	_, recovered := GetFailingSince(id)
+
+	// THEN
+	assert.True(t, firstFailing)
+	assert.True(t, secondFailing)
+	// the first failure is kept until the sensor is readable again
+	assert.Equal(t, firstSince, secondSince)
+	assert.False(t, recovered)
+}
+
+func TestReportValue(t *testing.T) {
+	// GIVEN
+	id := "value_sensor"
+	_, initial := GetLastValue(id)
+
+	// WHEN
+	ReportValue(id, 40000)
+	ReportValue(id, 42000)
+	last, ok := GetLastValue(id)
+
+	// THEN
+	assert.False(t, initial)
+	assert.True(t, ok)
+	assert.Equal(t, 42000.0, last.Value)
+	assert.False(t, last.Time.IsZero())
+}
@@ -0,0 +1,83 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+)
+
+type ThermalZoneSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+
+	// TempInput is the "temp" file of the thermal zone
+	TempInput string `json:"tempInput"`
+}
+
+func NewThermalZoneSensor(config configuration.SensorConfig) (*ThermalZoneSensor, error) {
+	zone, err := findThermalZone(*config.Thermal)
+	if err != nil {
+		return nil, fmt.Errorf("sensor %s: %v", config.ID, err)
+	}
+
+	return &ThermalZoneSensor{
+		Config:    config,
+		TempInput: configuration.GetSysfsPath("class", "thermal", zone, "temp"),
+	}, nil
+}
+
+// findThermalZone returns the name (f.ex. "thermal_zone0") of the thermal zone matching the given config
+func findThermalZone(config configuration.ThermalSensorConfig) (string, error) {
+	if len(config.Type) <= 0 {
+		return fmt.Sprintf("thermal_zone%d", config.Zone), nil
+	}
+
+	zones, err := filepath.Glob(configuration.GetSysfsPath("class", "thermal", "thermal_zone*"))
+	if err != nil {
+		return "", err
+	}
+	for _, zone := range zones {
+		content, err := os.ReadFile(path.Join(zone, "type"))
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(content)) == config.Type {
+			return filepath.Base(zone), nil
+		}
+	}
+
+	return "", fmt.Errorf("no thermal zone with type '%s' found", config.Type)
+}
+
+func (sensor *ThermalZoneSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *ThermalZoneSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *ThermalZoneSensor) GetValue() (float64, error) {
+	integer, err := util.ReadIntFromFile(sensor.TempInput)
+	if err != nil {
+		return 0, err
+	}
+	return float64(integer), nil
+}
+
+func (sensor *ThermalZoneSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *ThermalZoneSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
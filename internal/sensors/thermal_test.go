@@ -0,0 +1,47 @@
+package sensors
+
+import (
+	"path"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func createThermalSysfs(t *testing.T, zones map[string]string) string {
+	root := t.TempDir()
+	files := map[string]string{}
+	for zone, zoneType := range zones {
+		zonePath := path.Join("class", "thermal", zone)
+		files[path.Join(zonePath, "type")] = zoneType
+		files[path.Join(zonePath, "temp")] = "45000"
+	}
+	err := util.WriteSysfsFixture(root, files)
+	assert.NoError(t, err)
+	return root
+}
+
+func TestThermalZoneSensor_GetValueByType(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.SysfsRoot = createThermalSysfs(t, map[string]string{
+		"thermal_zone0": "acpitz",
+		"thermal_zone1": "x86_pkg_temp",
+	})
+	config := configuration.SensorConfig{
+		ID: "cpu",
+		Thermal: &configuration.ThermalSensorConfig{
+			Type: "x86_pkg_temp",
+		},
+	}
+
+	// WHEN
+	sensor, err := NewThermalZoneSensor(config)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(configuration.CurrentConfig.SysfsRoot, "class", "thermal", "thermal_zone1", "temp"), sensor.TempInput)
+	value, err := sensor.GetValue()
+	assert.NoError(t, err)
+	assert.Equal(t, 45000.0, value)
+}
@@ -0,0 +1,38 @@
+package sensors
+
+import (
+	"encoding/json"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+// TransformedSensor applies the factor and offset of its config to the values of another sensor
+type TransformedSensor struct {
+	Sensor
+}
+
+func (sensor TransformedSensor) GetValue() (float64, error) {
+	value, err := sensor.Sensor.GetValue()
+	if err != nil {
+		return 0, err
+	}
+	return Transform(sensor.GetConfig(), value), nil
+}
+
+// Unwrap returns the sensor whose values are transformed
+func (sensor TransformedSensor) Unwrap() Sensor {
+	return sensor.Sensor
+}
+
+// MarshalJSON serializes the transformed sensor, so the api output doesn't depend on the transform
+func (sensor TransformedSensor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sensor.Sensor)
+}
+
+// Transform applies the factor and offset of the given config to a raw value (in milli-degrees)
+func Transform(config configuration.SensorConfig, value float64) float64 {
+	if config.Factor != 0 {
+		value *= config.Factor
+	}
+	return value + config.Offset*1000
+}
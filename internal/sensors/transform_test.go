@@ -0,0 +1,48 @@
+package sensors
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSensorAppliesFactorAndOffset(t *testing.T) {
+	// GIVEN
+	filePath := path.Join(t.TempDir(), "temp")
+	err := os.WriteFile(filePath, []byte("455"), 0644)
+	assert.NoError(t, err)
+
+	sensor, err := NewSensor(configuration.SensorConfig{
+		ID:     "tenths",
+		File:   &configuration.FileSensorConfig{Path: filePath},
+		Factor: 100,
+		Offset: -2.5,
+	})
+	assert.NoError(t, err)
+
+	// WHEN
+	value, err := sensor.GetValue()
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 43000.0, value)
+	assert.IsType(t, &FileSensor{}, sensor.(*TransformedSensor).Unwrap())
+}
+
+func TestNewSensorWithoutTransform(t *testing.T) {
+	// GIVEN
+	config := configuration.SensorConfig{
+		ID:   "plain",
+		File: &configuration.FileSensorConfig{Path: "/tmp/temp"},
+	}
+
+	// WHEN
+	sensor, err := NewSensor(config)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.IsType(t, &FileSensor{}, sensor)
+}
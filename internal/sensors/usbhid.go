@@ -0,0 +1,60 @@
+package sensors
+
+import (
+	"fmt"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/usbhid"
+)
+
+// UsbHidSensor reads a sensor of a USB HID controller, f.ex. the coolant temperature or flow of an Aquacomputer device
+type UsbHidSensor struct {
+	Config    configuration.SensorConfig `json:"configuration"`
+	MovingAvg float64                    `json:"movingAvg"`
+
+	Controller usbhid.SensorController `json:"-"`
+}
+
+func NewUsbHidSensor(config configuration.SensorConfig) (*UsbHidSensor, error) {
+	controller, err := usbhid.Find(config.UsbHid.Model, config.UsbHid.Index, config.UsbHid.Path)
+	if err != nil {
+		return nil, fmt.Errorf("sensor %s: %v", config.ID, err)
+	}
+	sensorController, ok := controller.(usbhid.SensorController)
+	if !ok {
+		return nil, fmt.Errorf("sensor %s: usb hid controller of model '%s' has no sensors", config.ID, controller.GetModel())
+	}
+
+	return &UsbHidSensor{
+		Config:     config,
+		Controller: sensorController,
+	}, nil
+}
+
+func (sensor *UsbHidSensor) GetId() string {
+	return sensor.Config.ID
+}
+
+func (sensor *UsbHidSensor) GetConfig() configuration.SensorConfig {
+	return sensor.Config
+}
+
+func (sensor *UsbHidSensor) GetValue() (float64, error) {
+	value, err := sensor.Controller.GetSensorValue(sensor.Config.UsbHid.Sensor)
+	if err != nil {
+		return 0, fmt.Errorf("sensor %s: %v", sensor.GetId(), err)
+	}
+	return value, nil
+}
+
+func (sensor *UsbHidSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
+	return sensor.MovingAvg
+}
+
+func (sensor *UsbHidSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
+	sensor.MovingAvg = avg
+}
@@ -9,22 +9,28 @@ type VirtualSensor struct {
 	Value float64 `json:"value"`
 }
 
-func (sensor VirtualSensor) GetId() string {
+func (sensor *VirtualSensor) GetId() string {
 	return sensor.Name
 }
 
-func (sensor VirtualSensor) GetConfig() configuration.SensorConfig {
+func (sensor *VirtualSensor) GetConfig() configuration.SensorConfig {
 	return configuration.SensorConfig{}
 }
 
-func (sensor VirtualSensor) GetValue() (float64, error) {
+func (sensor *VirtualSensor) GetValue() (float64, error) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
 	return sensor.Value, nil
 }
 
-func (sensor VirtualSensor) GetMovingAvg() (avg float64) {
+func (sensor *VirtualSensor) GetMovingAvg() (avg float64) {
+	movingAvgLock.RLock()
+	defer movingAvgLock.RUnlock()
 	return sensor.Value
 }
 
 func (sensor *VirtualSensor) SetMovingAvg(avg float64) {
+	movingAvgLock.Lock()
+	defer movingAvgLock.Unlock()
 	sensor.Value = avg
 }
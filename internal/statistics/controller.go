@@ -13,6 +13,7 @@ type ControllerCollector struct {
 	unexpectedPwmValueCount *prometheus.Desc
 	increasedMinPwmCount    *prometheus.Desc
 	minPwmOffset            *prometheus.Desc
+	stallCount              *prometheus.Desc
 }
 
 func NewControllerCollector(controllers []controller.FanController) *ControllerCollector {
@@ -30,12 +31,17 @@ func NewControllerCollector(controllers []controller.FanController) *ControllerC
 			"Offset applied to the original minPwm of the fan due to a stalling fan",
 			[]string{"id"}, nil,
 		),
+		stallCount: prometheus.NewDesc(prometheus.BuildFQName(namespace, controllerSubsystem, "stall_count"),
+			"Counter for number of times the fan was restarted by the stall detection",
+			[]string{"id"}, nil,
+		),
 	}
 }
 
 func (collector *ControllerCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- collector.unexpectedPwmValueCount
 	ch <- collector.increasedMinPwmCount
+	ch <- collector.stallCount
 }
 
 // Collect implements required collect function for all prometheus collectors
@@ -47,6 +53,7 @@ func (collector *ControllerCollector) Collect(ch chan<- prometheus.Metric) {
 			ch <- prometheus.MustNewConstMetric(collector.unexpectedPwmValueCount, prometheus.CounterValue, float64(contr.GetStatistics().UnexpectedPwmValueCount), fanId)
 			ch <- prometheus.MustNewConstMetric(collector.increasedMinPwmCount, prometheus.CounterValue, float64(contr.GetStatistics().IncreasedMinPwmCount), fanId)
 			ch <- prometheus.MustNewConstMetric(collector.minPwmOffset, prometheus.GaugeValue, float64(contr.GetStatistics().MinPwmOffset), fanId)
+			ch <- prometheus.MustNewConstMetric(collector.stallCount, prometheus.CounterValue, float64(contr.GetStatistics().StallCount), fanId)
 		}
 	}
 }
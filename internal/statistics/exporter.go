@@ -15,6 +15,10 @@ func Register(collector prometheus.Collector) {
 	prometheus.MustRegister(collector)
 }
 
+func Unregister(collector prometheus.Collector) {
+	prometheus.Unregister(collector)
+}
+
 func CreateStatisticsService() *echo.Echo {
 	parentServer := api.CreateWebserver()
 
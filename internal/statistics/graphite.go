@@ -0,0 +1,131 @@
+package statistics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+const (
+	graphiteWriteTimeout = 10 * time.Second
+	// statsdMaxPacketSize is the maximum size of a single statsd packet, which avoids
+	// fragmentation on networks with the common MTU of 1500 bytes
+	statsdMaxPacketSize = 1432
+)
+
+// metricPathInvalidChars matches all characters that are not allowed in a single
+// component of a graphite or statsd metric path
+var metricPathInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_\-]`)
+
+// RunGraphite sends the current value of all metrics to the configured Graphite server
+// using the plaintext protocol on every interval, until the given context is done
+func RunGraphite(ctx context.Context, config configuration.PushConfig) error {
+	return runPush(ctx, "Graphite", config.Interval, func(metrics []Metric, timestamp time.Time) error {
+		dialer := net.Dialer{Timeout: graphiteWriteTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", config.Address)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		err = conn.SetWriteDeadline(time.Now().Add(graphiteWriteTimeout))
+		if err != nil {
+			return err
+		}
+		_, err = conn.Write(formatGraphite(config.Prefix, metrics, timestamp))
+		return err
+	})
+}
+
+// RunStatsd sends the current value of all metrics as gauges to the configured StatsD server
+// on every interval, until the given context is done
+func RunStatsd(ctx context.Context, config configuration.PushConfig) error {
+	conn, err := net.Dial("udp", config.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return runPush(ctx, "StatsD", config.Interval, func(metrics []Metric, timestamp time.Time) error {
+		for _, packet := range formatStatsd(config.Prefix, metrics) {
+			if _, err := conn.Write(packet); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// formatGraphite formats the given metrics using the graphite plaintext protocol, f.ex.:
+//
+//	fan2go.fan.cpu_fan.pwm 102 1704106800
+func formatGraphite(prefix string, metrics []Metric, timestamp time.Time) []byte {
+	var buffer bytes.Buffer
+	for _, line := range formatMetricLines(prefix, metrics, " ") {
+		buffer.WriteString(fmt.Sprintf("%s %d\n", line, timestamp.Unix()))
+	}
+	return buffer.Bytes()
+}
+
+// formatStatsd formats the given metrics as statsd gauges, f.ex. "fan2go.fan.cpu_fan.pwm:102|g",
+// split into packets of at most statsdMaxPacketSize bytes
+func formatStatsd(prefix string, metrics []Metric) [][]byte {
+	var result [][]byte
+	var packet bytes.Buffer
+	for _, line := range formatMetricLines(prefix, metrics, ":") {
+		line += "|g"
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdMaxPacketSize {
+			result = append(result, packet.Bytes())
+			packet = bytes.Buffer{}
+		}
+		if packet.Len() > 0 {
+			packet.WriteString("\n")
+		}
+		packet.WriteString(line)
+	}
+	if packet.Len() > 0 {
+		result = append(result, packet.Bytes())
+	}
+	return result
+}
+
+// formatMetricLines returns a sorted list of "path<separator>value" for all given metrics
+func formatMetricLines(prefix string, metrics []Metric, separator string) []string {
+	var result []string
+	for _, metric := range metrics {
+		value := strconv.FormatFloat(metric.Value, 'f', -1, 64)
+		result = append(result, metricPath(prefix, metric)+separator+value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// metricPath returns the dot separated path of the given metric, which contains the values of all
+// of its labels, f.ex. "fan2go.fan.cpu_fan.pwm"
+func metricPath(prefix string, metric Metric) string {
+	var components []string
+	if len(prefix) > 0 {
+		components = append(components, prefix)
+	}
+	components = append(components, metric.Subsystem)
+
+	names := make([]string, 0, len(metric.Labels))
+	for name := range metric.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		components = append(components, metricPathInvalidChars.ReplaceAllString(metric.Labels[name], "_"))
+	}
+
+	components = append(components, metric.Name)
+	return strings.Join(components, ".")
+}
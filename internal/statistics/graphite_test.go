@@ -0,0 +1,63 @@
+package statistics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatGraphite(t *testing.T) {
+	// GIVEN
+	metrics := []Metric{
+		{Subsystem: "fan", Name: "rpm", Labels: map[string]string{"id": "cpu"}, Value: 1108},
+		{Subsystem: "sensor", Name: "value", Labels: map[string]string{"id": "cpu package"}, Value: 45500.5},
+	}
+	timestamp := time.Unix(1704106800, 0)
+
+	// WHEN
+	result := formatGraphite("fan2go", metrics, timestamp)
+
+	// THEN
+	assert.Equal(t,
+		"fan2go.fan.cpu.rpm 1108 1704106800\n"+
+			"fan2go.sensor.cpu_package.value 45500.5 1704106800\n",
+		string(result),
+	)
+}
+
+func TestFormatStatsdWithoutPrefix(t *testing.T) {
+	// GIVEN
+	metrics := []Metric{
+		{Subsystem: "fan", Name: "pwm", Labels: map[string]string{"id": "cpu"}, Value: 102},
+		{Subsystem: "curve", Name: "value", Labels: map[string]string{"id": "cpu.curve"}, Value: 128},
+	}
+
+	// WHEN
+	result := formatStatsd("", metrics)
+
+	// THEN
+	assert.Len(t, result, 1)
+	assert.Equal(t, "curve.cpu_curve.value:128|g\nfan.cpu.pwm:102|g", string(result[0]))
+}
+
+func TestFormatStatsdSplitsPackets(t *testing.T) {
+	// GIVEN
+	var metrics []Metric
+	for i := 0; i < 100; i++ {
+		metrics = append(metrics, Metric{Subsystem: "sensor", Name: "value", Labels: map[string]string{"id": strings.Repeat("s", i)}, Value: 1})
+	}
+
+	// WHEN
+	result := formatStatsd("fan2go", metrics)
+
+	// THEN
+	assert.Greater(t, len(result), 1)
+	lines := 0
+	for _, packet := range result {
+		assert.LessOrEqual(t, len(packet), statsdMaxPacketSize)
+		lines += len(strings.Split(string(packet), "\n"))
+	}
+	assert.Equal(t, 100, lines)
+}
@@ -0,0 +1,113 @@
+package statistics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+const influxDbWriteTimeout = 10 * time.Second
+
+var (
+	influxDbMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxDbTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+// RunInfluxDb writes the current value of all metrics to the configured InfluxDB server
+// on every interval, until the given context is done
+func RunInfluxDb(ctx context.Context, config configuration.InfluxDbConfig) error {
+	return runPush(ctx, "InfluxDB", config.Interval, func(metrics []Metric, timestamp time.Time) error {
+		return writeInfluxDb(ctx, config, formatLineProtocol(metrics, timestamp))
+	})
+}
+
+// writeInfluxDb posts the given line protocol to the v2 write endpoint of the configured server
+func writeInfluxDb(ctx context.Context, config configuration.InfluxDbConfig, body []byte) error {
+	if len(body) <= 0 {
+		return nil
+	}
+
+	query := url.Values{}
+	query.Set("org", config.Org)
+	query.Set("bucket", config.Bucket)
+	query.Set("precision", "ns")
+	endpoint := strings.TrimSuffix(config.Url, "/") + "/api/v2/write?" + query.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, influxDbWriteTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if len(config.Token) > 0 {
+		request.Header.Set("Authorization", "Token "+config.Token)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from %s", response.StatusCode, config.Url)
+	}
+	return nil
+}
+
+// formatLineProtocol formats the given metrics as InfluxDB line protocol. Metrics of the same
+// subsystem with the same labels are written as fields of a single point, f.ex.:
+//
+//	fan2go_fan,id=cpu_fan pwm=102,rpm=1108 1704106800000000000
+func formatLineProtocol(metrics []Metric, timestamp time.Time) []byte {
+	// maps from measurement and tags -> fields
+	points := map[string][]string{}
+	for _, metric := range metrics {
+		key := influxDbMeasurementEscaper.Replace(namespace+"_"+metric.Subsystem) + formatTags(metric.Labels)
+		field := influxDbTagEscaper.Replace(metric.Name) + "=" + strconv.FormatFloat(metric.Value, 'f', -1, 64)
+		points[key] = append(points[key], field)
+	}
+
+	keys := make([]string, 0, len(points))
+	for key := range points {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buffer bytes.Buffer
+	for _, key := range keys {
+		fields := points[key]
+		sort.Strings(fields)
+		buffer.WriteString(fmt.Sprintf("%s %s %d\n", key, strings.Join(fields, ","), timestamp.UnixNano()))
+	}
+	return buffer.Bytes()
+}
+
+// formatTags formats the given labels as sorted tag set, including the leading comma
+func formatTags(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result strings.Builder
+	for _, name := range names {
+		value := labels[name]
+		if len(value) <= 0 {
+			// empty tag values are not allowed
+			continue
+		}
+		result.WriteString("," + influxDbTagEscaper.Replace(name) + "=" + influxDbTagEscaper.Replace(value))
+	}
+	return result.String()
+}
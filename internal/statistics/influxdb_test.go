@@ -0,0 +1,50 @@
+package statistics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatLineProtocolGroupsFields(t *testing.T) {
+	// GIVEN
+	metrics := []Metric{
+		{Subsystem: "fan", Name: "rpm", Labels: map[string]string{"id": "cpu"}, Value: 1108},
+		{Subsystem: "fan", Name: "pwm", Labels: map[string]string{"id": "cpu"}, Value: 102},
+		{Subsystem: "sensor", Name: "value", Labels: map[string]string{"id": "cpu_package"}, Value: 45500.5},
+	}
+	timestamp := time.Unix(1704106800, 0)
+
+	// WHEN
+	result := formatLineProtocol(metrics, timestamp)
+
+	// THEN
+	assert.Equal(t,
+		"fan2go_fan,id=cpu pwm=102,rpm=1108 1704106800000000000\n"+
+			"fan2go_sensor,id=cpu_package value=45500.5 1704106800000000000\n",
+		string(result),
+	)
+}
+
+func TestFormatLineProtocolEscapesTags(t *testing.T) {
+	// GIVEN
+	metrics := []Metric{
+		{Subsystem: "curve", Name: "value", Labels: map[string]string{"id": "cpu curve,a=b", "empty": ""}, Value: 128},
+	}
+	timestamp := time.Unix(0, 1)
+
+	// WHEN
+	result := formatLineProtocol(metrics, timestamp)
+
+	// THEN
+	assert.Equal(t, "fan2go_curve,id=cpu\\ curve\\,a\\=b value=128 1\n", string(result))
+}
+
+func TestFormatLineProtocolEmpty(t *testing.T) {
+	// WHEN
+	result := formatLineProtocol(nil, time.Now())
+
+	// THEN
+	assert.Empty(t, result)
+}
@@ -0,0 +1,97 @@
+package statistics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/ui"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Metric is the current value of a single metric exposed by the prometheus exporter
+type Metric struct {
+	// Subsystem is the part of the metric name following the namespace, f.ex. "fan"
+	Subsystem string
+	// Name is the remaining part of the metric name, f.ex. "pwm"
+	Name string
+	// Labels maps from label name -> value, f.ex. "id" -> "cpu_fan"
+	Labels map[string]string
+	Value  float64
+}
+
+// Gather returns the current values of all fan2go metrics, which are the same values
+// that are scraped from the prometheus exporter, so they can be pushed to other systems
+func Gather() ([]Metric, error) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+	return convertMetricFamilies(families), nil
+}
+
+func convertMetricFamilies(families []*dto.MetricFamily) []Metric {
+	var result []Metric
+	for _, family := range families {
+		prefix := namespace + "_"
+		if !strings.HasPrefix(family.GetName(), prefix) {
+			// f.ex. metrics of the go runtime or the webserver
+			continue
+		}
+		subsystem, name, found := strings.Cut(strings.TrimPrefix(family.GetName(), prefix), "_")
+		if !found {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			var value float64
+			switch {
+			case metric.Gauge != nil:
+				value = metric.GetGauge().GetValue()
+			case metric.Counter != nil:
+				value = metric.GetCounter().GetValue()
+			case metric.Untyped != nil:
+				value = metric.GetUntyped().GetValue()
+			default:
+				continue
+			}
+
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			result = append(result, Metric{
+				Subsystem: subsystem,
+				Name:      name,
+				Labels:    labels,
+				Value:     value,
+			})
+		}
+	}
+	return result
+}
+
+// runPush gathers all metrics and passes them to the given write function on every interval,
+// until the given context is done
+func runPush(ctx context.Context, target string, interval time.Duration, write func(metrics []Metric, timestamp time.Time) error) error {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+			metrics, err := Gather()
+			if err != nil {
+				ui.Warning("Error gathering metrics for %s: %v", target, err)
+				continue
+			}
+			err = write(metrics, time.Now())
+			if err != nil {
+				ui.Warning("Error writing metrics to %s: %v", target, err)
+			}
+		}
+	}
+}
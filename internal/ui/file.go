@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const megabyte = 1024 * 1024
+
+// fileSink appends log messages to a file, which is rotated once it exceeds its maximum size
+type fileSink struct {
+	path string
+	// maxSize is the size in bytes after which the file is rotated, 0 disables rotation
+	maxSize int64
+	// maxBackups is the number of rotated files that are kept
+	maxBackups int
+
+	lock sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink creates a sink appending log messages to the file at the given path. Once the file
+// is larger than maxSizeMb megabytes it is renamed to "<path>.1", while up to maxBackups previously
+// rotated files are kept.
+func NewFileSink(path string, maxSizeMb int, maxBackups int) (Sink, error) {
+	sink := &fileSink{
+		path:       path,
+		maxSize:    int64(maxSizeMb) * megabyte,
+		maxBackups: maxBackups,
+	}
+	err := sink.open()
+	if err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (s *fileSink) Write(level Level, message string, fields Fields) error {
+	line := formatFileLine(time.Now(), level, message, fields)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.maxSize > 0 && s.size+int64(len(line)) > s.maxSize && s.size > 0 {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.WriteString(line)
+	s.size += int64(n)
+	return err
+}
+
+func (s *fileSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.file.Close()
+}
+
+func (s *fileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate shifts all rotated files by one, dropping the oldest one, and starts a new file
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	if s.maxBackups <= 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return s.open()
+	}
+
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(backupPath(s.path, i), backupPath(s.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(s.path, backupPath(s.path, 1)); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func backupPath(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
+
+func formatFileLine(t time.Time, level Level, message string, fields Fields) string {
+	return fmt.Sprintf("%s %-7s %s\n", t.Format(time.RFC3339), level, formatSyslogMessage(message, fields))
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSinkRotatesWhenFull(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "fan2go.log")
+	sink, err := NewFileSink(path, 1, 2)
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	message := strings.Repeat("x", 400*1024)
+
+	// WHEN
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, sink.Write(LevelInfo, message, nil))
+	}
+
+	// THEN
+	assert.FileExists(t, path)
+	assert.FileExists(t, path+".1")
+	assert.NoFileExists(t, path+".2")
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Less(t, info.Size(), int64(megabyte))
+}
+
+func TestFileSinkKeepsMaxBackups(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "fan2go.log")
+	sink, err := NewFileSink(path, 1, 2)
+	assert.NoError(t, err)
+	defer sink.Close()
+
+	message := strings.Repeat("x", 800*1024)
+
+	// WHEN
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, sink.Write(LevelInfo, message, nil))
+	}
+
+	// THEN
+	assert.FileExists(t, path+".1")
+	assert.FileExists(t, path+".2")
+	assert.NoFileExists(t, path+".3")
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	journaldSocket   = "/run/systemd/journal/socket"
+	syslogIdentifier = "fan2go"
+)
+
+// journaldSink sends log messages to the systemd journal using its native protocol,
+// so the fields of a message can be used for filtering, f.ex. "journalctl FAN_ID=cpu"
+type journaldSink struct {
+	conn *net.UnixConn
+}
+
+// NewJournaldSink creates a sink sending log messages to the systemd journal
+func NewJournaldSink() (Sink, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: journaldSocket, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journaldSink{conn: conn}, nil
+}
+
+func (s *journaldSink) Write(level Level, message string, fields Fields) error {
+	_, err := s.conn.Write(formatJournaldEntry(level, message, fields))
+	return err
+}
+
+func (s *journaldSink) Close() error {
+	return s.conn.Close()
+}
+
+// formatJournaldEntry serializes a log message to the native journal protocol,
+// see https://systemd.io/JOURNAL_NATIVE_PROTOCOL/
+func formatJournaldEntry(level Level, message string, fields Fields) []byte {
+	var entry bytes.Buffer
+	writeJournaldField(&entry, "MESSAGE", message)
+	writeJournaldField(&entry, "PRIORITY", strconv.Itoa(syslogPriority(level)))
+	writeJournaldField(&entry, "SYSLOG_IDENTIFIER", syslogIdentifier)
+	for key, value := range fields {
+		writeJournaldField(&entry, key, value)
+	}
+	return entry.Bytes()
+}
+
+func writeJournaldField(entry *bytes.Buffer, key string, value string) {
+	if !strings.Contains(value, "\n") {
+		entry.WriteString(key + "=" + value + "\n")
+		return
+	}
+	// values containing newlines are prefixed with their length instead
+	entry.WriteString(key + "\n")
+	_ = binary.Write(entry, binary.LittleEndian, uint64(len(value)))
+	entry.WriteString(value + "\n")
+}
+
+// syslogPriority maps the given level to the corresponding syslog priority
+func syslogPriority(level Level) int {
+	switch level {
+	case LevelDebug:
+		return 7
+	case LevelInfo:
+		return 6
+	case LevelWarning:
+		return 4
+	case LevelError:
+		return 3
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatJournaldEntry(t *testing.T) {
+	// GIVEN
+	fields := Fields{FieldFan: "cpu"}
+
+	// WHEN
+	entry := string(formatJournaldEntry(LevelWarning, "Fan cpu stalled", fields))
+
+	// THEN
+	assert.Equal(t, "MESSAGE=Fan cpu stalled\nPRIORITY=4\nSYSLOG_IDENTIFIER=fan2go\nFAN_ID=cpu\n", entry)
+}
+
+func TestFormatJournaldEntryMultilineMessage(t *testing.T) {
+	// GIVEN
+	message := "first\nsecond"
+
+	// WHEN
+	entry := string(formatJournaldEntry(LevelInfo, message, nil))
+
+	// THEN
+	length := make([]byte, 8)
+	binary.LittleEndian.PutUint64(length, uint64(len(message)))
+	assert.True(t, strings.HasPrefix(entry, "MESSAGE\n"+string(length)+message+"\n"))
+}
+
+func TestFormatSyslogMessage(t *testing.T) {
+	// GIVEN
+	fields := Fields{FieldSensor: "cpu_package", FieldFan: "cpu"}
+
+	// WHEN
+	message := formatSyslogMessage("Fan cpu enters failsafe mode", fields)
+
+	// THEN
+	assert.Equal(t, "Fan cpu enters failsafe mode [FAN_ID=cpu SENSOR_ID=cpu_package]", message)
+}
@@ -2,11 +2,184 @@ package ui
 
 import (
 	"fmt"
+	"os"
+	"sync"
+
 	"github.com/pterm/pterm"
 )
 
+// Fields are structured key/value pairs attached to a log message,
+// keys must consist of upper case letters, digits and underscores
+type Fields map[string]string
+
+const (
+	// FieldFan is the id of the fan a log message is about
+	FieldFan = "FAN_ID"
+	// FieldSensor is the id of the sensor a log message is about
+	FieldSensor = "SENSOR_ID"
+	// FieldCurve is the id of the curve a log message is about
+	FieldCurve = "CURVE_ID"
+	// FieldSubsystem is the subsystem a log message originates from
+	FieldSubsystem = "SUBSYSTEM"
+)
+
+const (
+	SubsystemController  = "controller"
+	SubsystemHwmon       = "hwmon"
+	SubsystemPersistence = "persistence"
+)
+
+// Subsystems are all subsystems whose debug messages can be enabled individually
+var Subsystems = []string{SubsystemController, SubsystemHwmon, SubsystemPersistence}
+
+var (
+	// level is the minimum level of messages that are logged
+	level     = LevelInfo
+	levelLock sync.RWMutex
+
+	// debugSubsystems contains all subsystems whose debug messages are logged regardless of the level
+	debugSubsystems = map[string]bool{}
+)
+
+// SetDebugEnabled logs debug messages of all subsystems, same as SetLevel(LevelDebug)
 func SetDebugEnabled(enabled bool) {
-	pterm.PrintDebugMessages = enabled
+	if enabled {
+		SetLevel(LevelDebug)
+	} else {
+		SetLevel(LevelInfo)
+	}
+}
+
+// SetLevel sets the minimum level of messages that are logged
+func SetLevel(l Level) {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	level = l
+	pterm.PrintDebugMessages = l <= LevelDebug
+}
+
+// SetDebugSubsystems logs debug messages of the given subsystems, regardless of the level
+func SetDebugSubsystems(subsystems []string) {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	debugSubsystems = map[string]bool{}
+	for _, subsystem := range subsystems {
+		debugSubsystems[subsystem] = true
+	}
+}
+
+// isEnabled indicates whether messages of the given level and subsystem are logged
+func isEnabled(l Level, subsystem string) bool {
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+	if l == LevelDebug && debugSubsystems[subsystem] {
+		return true
+	}
+	return l >= level
+}
+
+// Logger writes log messages with a set of structured fields to the current sink
+type Logger struct {
+	fields    Fields
+	subsystem string
+}
+
+// ForSubsystem returns a logger for messages of the given subsystem
+func ForSubsystem(subsystem string) Logger {
+	return Logger{subsystem: subsystem}.WithFields(Fields{FieldSubsystem: subsystem})
+}
+
+// WithFields returns a logger attaching the given fields to all of its messages
+func WithFields(fields Fields) Logger {
+	return Logger{}.WithFields(fields)
+}
+
+// WithFan returns a logger attaching the id of the given fan to all of its messages
+func WithFan(id string) Logger {
+	return Logger{}.WithFan(id)
+}
+
+// WithSensor returns a logger attaching the id of the given sensor to all of its messages
+func WithSensor(id string) Logger {
+	return Logger{}.WithSensor(id)
+}
+
+// WithCurve returns a logger attaching the id of the given curve to all of its messages
+func WithCurve(id string) Logger {
+	return Logger{}.WithCurve(id)
+}
+
+// WithFields returns a copy of this logger, which additionally attaches the given fields
+func (l Logger) WithFields(fields Fields) Logger {
+	merged := Fields{}
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return Logger{fields: merged, subsystem: l.subsystem}
+}
+
+func (l Logger) WithFan(id string) Logger {
+	return l.WithFields(Fields{FieldFan: id})
+}
+
+func (l Logger) WithSensor(id string) Logger {
+	return l.WithFields(Fields{FieldSensor: id})
+}
+
+func (l Logger) WithCurve(id string) Logger {
+	return l.WithFields(Fields{FieldCurve: id})
+}
+
+func (l Logger) Debug(format string, a ...interface{}) {
+	l.log(LevelDebug, format, a...)
+}
+
+func (l Logger) Info(format string, a ...interface{}) {
+	l.log(LevelInfo, format, a...)
+}
+
+func (l Logger) Warning(format string, a ...interface{}) {
+	l.log(LevelWarning, format, a...)
+}
+
+func (l Logger) WarningAndNotify(title string, format string, a ...interface{}) {
+	l.Error(format, a...)
+	NotifyError(title, fmt.Sprintf(format, a...))
+}
+
+func (l Logger) Error(format string, a ...interface{}) {
+	l.log(LevelError, format, a...)
+}
+
+func (l Logger) ErrorAndNotify(title string, format string, a ...interface{}) {
+	l.Error(format, a...)
+	NotifyError(title, fmt.Sprintf(format, a...))
+}
+
+func (l Logger) Fatal(format string, a ...interface{}) {
+	NotifyError("Fatal Error", fmt.Sprintf(format, a...))
+	l.log(LevelFatal, format, a...)
+	// the console sink already exits on its own
+	os.Exit(1)
+}
+
+func (l Logger) log(level Level, format string, a ...interface{}) {
+	if level < LevelWarning && !isEnabled(level, l.subsystem) {
+		return
+	}
+	message := fmt.Sprintf(format, a...)
+	recordError(level, message, l.fields)
+	if !isEnabled(level, l.subsystem) {
+		return
+	}
+	err := getSink().Write(level, message, l.fields)
+	if err != nil {
+		// fall back to the console, so the message isn't lost
+		_ = consoleSink{}.Write(level, message, l.fields)
+	}
 }
 
 func Printf(format string, a ...interface{}) {
@@ -17,37 +190,34 @@ func Printfln(format string, a ...interface{}) {
 	pterm.Printfln(format, a...)
 }
 
-func Debug(format string, a ...interface{}) {
-	pterm.Debug.Printfln(format, a...)
-}
-
 func Success(format string, a ...interface{}) {
 	pterm.Success.Printfln(format, a...)
 }
 
+func Debug(format string, a ...interface{}) {
+	Logger{}.Debug(format, a...)
+}
+
 func Info(format string, a ...interface{}) {
-	pterm.Info.Printfln(format, a...)
+	Logger{}.Info(format, a...)
 }
 
 func Warning(format string, a ...interface{}) {
-	pterm.Warning.Printfln(format, a...)
+	Logger{}.Warning(format, a...)
 }
 
 func WarningAndNotify(title string, format string, a ...interface{}) {
-	Error(format, a...)
-	NotifyError(title, fmt.Sprintf(format, a...))
+	Logger{}.WarningAndNotify(title, format, a...)
 }
 
 func Error(format string, a ...interface{}) {
-	pterm.Error.Printfln(format, a...)
+	Logger{}.Error(format, a...)
 }
 
 func ErrorAndNotify(title string, format string, a ...interface{}) {
-	Error(format, a...)
-	NotifyError(title, fmt.Sprintf(format, a...))
+	Logger{}.ErrorAndNotify(title, format, a...)
 }
 
 func Fatal(format string, a ...interface{}) {
-	NotifyError("Fatal Error", fmt.Sprintf(format, a...))
-	pterm.Fatal.Printfln(format, a...)
+	Logger{}.Fatal(format, a...)
 }
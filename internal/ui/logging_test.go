@@ -1,8 +1,11 @@
 package ui
 
 import (
-	"github.com/pterm/pterm"
 	"os"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
 )
 
 func ExamplePrintln() {
@@ -19,7 +22,7 @@ func ExamplePrintln() {
 func ExampleDebug() {
 	pterm.SetDefaultOutput(os.Stdout)
 	pterm.DisableStyling()
-	pterm.PrintDebugMessages = true
+	SetDebugEnabled(true)
 
 	msg := "This is a test: %d"
 	a := 5
@@ -60,3 +63,18 @@ func ExampleError() {
 	// Output:
 	// ERROR: This is a test: file already closed
 }
+
+func TestIsEnabledForDebugSubsystem(t *testing.T) {
+	// GIVEN
+	SetLevel(LevelWarning)
+	SetDebugSubsystems([]string{SubsystemHwmon})
+	defer SetLevel(LevelInfo)
+	defer SetDebugSubsystems(nil)
+
+	// THEN
+	assert.True(t, isEnabled(LevelDebug, SubsystemHwmon))
+	assert.False(t, isEnabled(LevelInfo, SubsystemHwmon))
+	assert.False(t, isEnabled(LevelDebug, SubsystemController))
+	assert.False(t, isEnabled(LevelInfo, ""))
+	assert.True(t, isEnabled(LevelError, ""))
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRecentErrors is the number of warnings and errors kept in memory
+const maxRecentErrors = 50
+
+// LogEntry is a logged warning or error
+type LogEntry struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Fields  Fields    `json:"fields,omitempty"`
+}
+
+var (
+	// recentErrors contains the last warnings and errors, oldest first
+	recentErrors     []LogEntry
+	recentErrorsLock sync.Mutex
+)
+
+// RecentErrors returns the last warnings and errors that were logged, oldest first
+func RecentErrors() []LogEntry {
+	recentErrorsLock.Lock()
+	defer recentErrorsLock.Unlock()
+	result := make([]LogEntry, len(recentErrors))
+	copy(result, recentErrors)
+	return result
+}
+
+// recordError keeps the given message in memory, if it is a warning or an error,
+// so it can be inspected using the api of the running daemon
+func recordError(level Level, message string, fields Fields) {
+	if level < LevelWarning {
+		return
+	}
+	recentErrorsLock.Lock()
+	defer recentErrorsLock.Unlock()
+	recentErrors = append(recentErrors, LogEntry{
+		Time:    time.Now(),
+		Level:   level.String(),
+		Message: message,
+		Fields:  fields,
+	})
+	if len(recentErrors) > maxRecentErrors {
+		recentErrors = recentErrors[len(recentErrors)-maxRecentErrors:]
+	}
+}
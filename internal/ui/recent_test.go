@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentErrors(t *testing.T) {
+	// GIVEN
+	recentErrors = nil
+	defer func() { recentErrors = nil }()
+
+	// WHEN
+	WithFan("cpu").Info("not recorded")
+	WithFan("cpu").Warning("fan %s is slow", "cpu")
+	Error("something failed")
+
+	// THEN
+	entries := RecentErrors()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "WARNING", entries[0].Level)
+	assert.Equal(t, "fan cpu is slow", entries[0].Message)
+	assert.Equal(t, "cpu", entries[0].Fields[FieldFan])
+	assert.Equal(t, "ERROR", entries[1].Level)
+	assert.Equal(t, "something failed", entries[1].Message)
+}
+
+func TestRecentErrors_Limit(t *testing.T) {
+	// GIVEN
+	recentErrors = nil
+	defer func() { recentErrors = nil }()
+
+	// WHEN
+	for i := 0; i < maxRecentErrors+5; i++ {
+		recordError(LevelError, fmt.Sprintf("error %d", i), nil)
+	}
+
+	// THEN
+	entries := RecentErrors()
+	assert.Len(t, entries, maxRecentErrors)
+	assert.Equal(t, "error 5", entries[0].Message)
+	assert.Equal(t, fmt.Sprintf("error %d", maxRecentErrors+4), entries[len(entries)-1].Message)
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pterm/pterm"
+)
+
+// Level is the severity of a log message
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+	LevelFatal
+)
+
+// levelNames maps from the name of a level, as used in the configuration, to the level
+var levelNames = map[string]Level{
+	"debug": LevelDebug,
+	"info":  LevelInfo,
+	"warn":  LevelWarning,
+	"error": LevelError,
+}
+
+// ParseLevel returns the level with the given name, one of: debug | info | warn | error
+func ParseLevel(name string) (Level, error) {
+	level, ok := levelNames[name]
+	if !ok {
+		return LevelInfo, fmt.Errorf("unknown log level: %s", name)
+	}
+	return level, nil
+}
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarning:
+		return "WARNING"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "FATAL"
+	}
+}
+
+// Sink receives all log messages
+type Sink interface {
+	// Write logs the given message with its structured fields
+	Write(level Level, message string, fields Fields) error
+	// Close releases all resources of the sink
+	Close() error
+}
+
+var (
+	currentSink Sink = consoleSink{}
+	sinkLock    sync.RWMutex
+)
+
+// SetSink replaces the sink receiving all log messages, the previous sink is closed
+func SetSink(sink Sink) {
+	sinkLock.Lock()
+	previous := currentSink
+	currentSink = sink
+	sinkLock.Unlock()
+
+	_ = previous.Close()
+}
+
+func getSink() Sink {
+	sinkLock.RLock()
+	defer sinkLock.RUnlock()
+	return currentSink
+}
+
+// multiSink writes log messages to multiple sinks
+type multiSink []Sink
+
+// NewMultiSink creates a sink writing log messages to all of the given sinks
+func NewMultiSink(sinks ...Sink) Sink {
+	return multiSink(sinks)
+}
+
+func (s multiSink) Write(level Level, message string, fields Fields) error {
+	var result error
+	for _, sink := range s {
+		if err := sink.Write(level, message, fields); err != nil {
+			result = err
+		}
+	}
+	return result
+}
+
+func (s multiSink) Close() error {
+	var result error
+	for _, sink := range s {
+		if err := sink.Close(); err != nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// consoleSink prints log messages to the terminal, fields are omitted since
+// the messages themselves already mention the objects they are about
+type consoleSink struct{}
+
+// NewConsoleSink creates a sink printing log messages to the terminal
+func NewConsoleSink() Sink {
+	return consoleSink{}
+}
+
+func (s consoleSink) Write(level Level, message string, fields Fields) error {
+	switch level {
+	case LevelDebug:
+		// the level is already checked by the logger, f.ex. for subsystems with debug messages enabled
+		pterm.Debug.WithDebugger(false).Println(message)
+	case LevelInfo:
+		pterm.Info.Println(message)
+	case LevelWarning:
+		pterm.Warning.Println(message)
+	case LevelError:
+		pterm.Error.Println(message)
+	case LevelFatal:
+		pterm.Fatal.Println(message)
+	}
+	return nil
+}
+
+func (s consoleSink) Close() error {
+	return nil
+}
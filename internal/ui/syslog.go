@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"fmt"
+	"log/syslog"
+	"sort"
+	"strings"
+)
+
+// syslogSink sends log messages to the local syslog daemon,
+// the fields of a message are appended to its text
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink creates a sink sending log messages to the local syslog daemon
+func NewSyslogSink() (Sink, error) {
+	writer, err := syslog.New(syslog.LOG_DAEMON|syslog.LOG_INFO, syslogIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) Write(level Level, message string, fields Fields) error {
+	message = formatSyslogMessage(message, fields)
+	switch level {
+	case LevelDebug:
+		return s.writer.Debug(message)
+	case LevelInfo:
+		return s.writer.Info(message)
+	case LevelWarning:
+		return s.writer.Warning(message)
+	case LevelError:
+		return s.writer.Err(message)
+	default:
+		return s.writer.Crit(message)
+	}
+}
+
+func (s *syslogSink) Close() error {
+	return s.writer.Close()
+}
+
+func formatSyslogMessage(message string, fields Fields) string {
+	if len(fields) <= 0 {
+		return message
+	}
+
+	var keys []string
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, fields[key]))
+	}
+	return fmt.Sprintf("%s [%s]", message, strings.Join(pairs, " "))
+}
@@ -0,0 +1,239 @@
+package usbhid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	aquacomputerStatusReportId = 0x01
+	aquacomputerCtrlReportId   = 0x03
+	// aquacomputerReadLength is large enough for the status report of all supported devices
+	aquacomputerReadLength = 512
+
+	// aquacomputerSensorDisconnected is reported by temperature sensors which are not connected
+	aquacomputerSensorDisconnected = 0x7fff
+	// aquacomputerFanSpeedOffset is the offset of the RPM within the status of a fan
+	aquacomputerFanSpeedOffset = 0x08
+	// aquacomputerFanCtrlPwmOffset is the offset of the duty (in centi-percent) within the settings of a fan
+	aquacomputerFanCtrlPwmOffset = 0x01
+
+	// aquacomputerStatusMaxAge is the time a status report is reused for, the devices send one report per second
+	aquacomputerStatusMaxAge = 500 * time.Millisecond
+)
+
+// aquacomputerSecondaryCtrlReport is sent after the control report to apply it, like aquasuite does
+var aquacomputerSecondaryCtrlReport = []byte{0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x34, 0xc6}
+
+// aquacomputerLayout describes where a device reports its sensors and fans, the offsets are
+// taken from the aquacomputer_d5next kernel driver
+type aquacomputerLayout struct {
+	// sensorStart is the offset of the first temperature sensor in the status report
+	sensorStart int
+	sensorCount int
+	// virtualSensorStart is the offset of the first virtual (software) temperature sensor in the status report
+	virtualSensorStart int
+	virtualSensorCount int
+	// flowOffset is the offset of the flow sensor (in dl/h) in the status report, 0 if there is none
+	flowOffset int
+	// fanOffsets are the offsets of the status of each fan in the status report
+	fanOffsets []int
+	// ctrlFanOffsets are the offsets of the settings of each fan in the control report
+	ctrlFanOffsets []int
+	// ctrlReportLength is the length of the control report, including its report id
+	ctrlReportLength int
+}
+
+var (
+	// aquacomputerD5NextLayout is the layout of the D5 Next pump, channel 1 is the pump, channel 2 the fan header
+	aquacomputerD5NextLayout = aquacomputerLayout{
+		sensorStart:        0x57,
+		sensorCount:        1,
+		virtualSensorStart: 0x3f,
+		virtualSensorCount: 8,
+		fanOffsets:         []int{0x6c, 0x5f},
+		ctrlFanOffsets:     []int{0x97, 0x42},
+		ctrlReportLength:   0x329,
+	}
+	aquacomputerOctoLayout = aquacomputerLayout{
+		sensorStart:        0x3d,
+		sensorCount:        4,
+		virtualSensorStart: 0x45,
+		virtualSensorCount: 16,
+		flowOffset:         0x7b,
+		fanOffsets:         []int{0x7d, 0x8a, 0x97, 0xa4, 0xb1, 0xbe, 0xcb, 0xd8},
+		ctrlFanOffsets:     []int{0x5b, 0xb0, 0x105, 0x15a, 0x1af, 0x204, 0x259, 0x2ae},
+		ctrlReportLength:   0x65f,
+	}
+	aquacomputerQuadroLayout = aquacomputerLayout{
+		sensorStart:        0x34,
+		sensorCount:        4,
+		virtualSensorStart: 0x3c,
+		virtualSensorCount: 16,
+		flowOffset:         0x6e,
+		fanOffsets:         []int{0x70, 0x7d, 0x8a, 0x97},
+		ctrlFanOffsets:     []int{0x37, 0x8c, 0xe1, 0x136},
+		ctrlReportLength:   0x3c1,
+	}
+)
+
+// aquacomputerDevice is an Aquacomputer D5 Next, Octo or Quadro.
+// The device sends a status report with all sensor values once per second, its settings are
+// changed by reading, modifying and writing back the whole control report.
+type aquacomputerDevice struct {
+	device
+	layout aquacomputerLayout
+
+	status     []byte
+	statusTime time.Time
+}
+
+func (d *aquacomputerDevice) GetChannelCount() int {
+	return len(d.layout.fanOffsets)
+}
+
+func (d *aquacomputerDevice) SetDuty(channel int, duty int) error {
+	if err := validateChannel(channel, d.GetChannelCount()); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	report, err := d.getFeatureReport(aquacomputerCtrlReportId, d.layout.ctrlReportLength)
+	if err != nil {
+		return err
+	}
+	if len(report) != d.layout.ctrlReportLength {
+		return fmt.Errorf("invalid control report length: %d", len(report))
+	}
+	d.layout.setDuty(report, channel, duty)
+	err = d.setFeatureReport(report)
+	if err != nil {
+		return err
+	}
+	return d.setFeatureReport(aquacomputerSecondaryCtrlReport)
+}
+
+func (d *aquacomputerDevice) GetRpm(channel int) (int, error) {
+	if err := validateChannel(channel, d.GetChannelCount()); err != nil {
+		return 0, err
+	}
+	status, err := d.readStatus()
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint16(status[d.layout.fanOffsets[channel-1]+aquacomputerFanSpeedOffset:])), nil
+}
+
+func (d *aquacomputerDevice) GetSensorValue(name string) (float64, error) {
+	status, err := d.readStatus()
+	if err != nil {
+		return 0, err
+	}
+	return d.layout.getSensorValue(status, name)
+}
+
+// readStatus returns the latest status report of the device
+func (d *aquacomputerDevice) readStatus() ([]byte, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.status != nil && time.Since(d.statusTime) < aquacomputerStatusMaxAge {
+		return d.status, nil
+	}
+	for i := 0; i < maxStatusReports; i++ {
+		report, err := d.read(aquacomputerReadLength)
+		if err != nil {
+			return nil, err
+		}
+		if len(report) < d.layout.statusLength() || report[0] != aquacomputerStatusReportId {
+			continue
+		}
+		d.status = report
+		d.statusTime = time.Now()
+		return report, nil
+	}
+	return nil, errNoStatus
+}
+
+// statusLength returns the minimum length of a status report containing all values of the layout
+func (l aquacomputerLayout) statusLength() int {
+	ends := []int{l.sensorStart + l.sensorCount*2, l.virtualSensorStart + l.virtualSensorCount*2, l.flowOffset + 2}
+	for _, offset := range l.fanOffsets {
+		ends = append(ends, offset+aquacomputerFanSpeedOffset+2)
+	}
+	result := 0
+	for _, end := range ends {
+		if end > result {
+			result = end
+		}
+	}
+	return result
+}
+
+// getSensorValue returns the value of the sensor with the given name from the given status report,
+// temperatures ("tempN" and "virtualN") are returned in milli-degrees, the flow ("flow") in milli-liters per hour
+func (l aquacomputerLayout) getSensorValue(status []byte, name string) (float64, error) {
+	if name == "flow" {
+		if l.flowOffset <= 0 {
+			return 0, fmt.Errorf("device has no flow sensor")
+		}
+		// dl/h
+		return float64(binary.BigEndian.Uint16(status[l.flowOffset:])) * 100 * 1000, nil
+	}
+
+	start, count := l.sensorStart, l.sensorCount
+	index, err := parseSensorIndex(name, "temp")
+	if err != nil {
+		start, count = l.virtualSensorStart, l.virtualSensorCount
+		index, err = parseSensorIndex(name, "virtual")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unknown sensor '%s', use one of: temp1..temp%d | virtual1..virtual%d | flow", name, l.sensorCount, l.virtualSensorCount)
+	}
+	if index < 1 || index > count {
+		return 0, fmt.Errorf("invalid sensor '%s', must be in range 1..%d", name, count)
+	}
+
+	value := binary.BigEndian.Uint16(status[start+(index-1)*2:])
+	if value == aquacomputerSensorDisconnected {
+		return 0, fmt.Errorf("sensor '%s' is not connected", name)
+	}
+	// centi-degrees
+	return float64(value) * 10, nil
+}
+
+// setDuty writes the given duty (0-100) of the given channel to the control report and updates its checksum
+func (l aquacomputerLayout) setDuty(report []byte, channel int, duty int) {
+	binary.BigEndian.PutUint16(report[l.ctrlFanOffsets[channel-1]+aquacomputerFanCtrlPwmOffset:], uint16(duty*100))
+	// the checksum covers everything except the report id and the checksum itself
+	checksum := crc16Usb(report[1 : len(report)-2])
+	binary.BigEndian.PutUint16(report[len(report)-2:], checksum)
+}
+
+// parseSensorIndex parses the index of a sensor name like "temp1" with the given prefix
+func parseSensorIndex(name string, prefix string) (int, error) {
+	if !strings.HasPrefix(name, prefix) {
+		return 0, fmt.Errorf("sensor '%s' doesn't start with %s", name, prefix)
+	}
+	return strconv.Atoi(strings.TrimPrefix(name, prefix))
+}
+
+// crc16Usb computes the CRC-16/USB checksum of the given data
+func crc16Usb(data []byte) uint16 {
+	crc := uint16(0xffff)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xa001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc ^ 0xffff
+}
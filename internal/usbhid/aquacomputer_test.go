@@ -0,0 +1,92 @@
+package usbhid
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createQuadroStatus() []byte {
+	status := make([]byte, aquacomputerQuadroLayout.statusLength())
+	status[0] = aquacomputerStatusReportId
+	// temp1: 28.5 °C, temp2: not connected
+	binary.BigEndian.PutUint16(status[0x34:], 2850)
+	binary.BigEndian.PutUint16(status[0x36:], aquacomputerSensorDisconnected)
+	// virtual1: 40 °C
+	binary.BigEndian.PutUint16(status[0x3c:], 4000)
+	// 123.4 l/h
+	binary.BigEndian.PutUint16(status[0x6e:], 1234)
+	// fan 2: 1200 RPM
+	binary.BigEndian.PutUint16(status[0x7d+aquacomputerFanSpeedOffset:], 1200)
+	return status
+}
+
+func TestAquacomputerLayout_GetSensorValue(t *testing.T) {
+	// GIVEN
+	status := createQuadroStatus()
+
+	// WHEN
+	temp, err1 := aquacomputerQuadroLayout.getSensorValue(status, "temp1")
+	virtual, err2 := aquacomputerQuadroLayout.getSensorValue(status, "virtual1")
+	flow, err3 := aquacomputerQuadroLayout.getSensorValue(status, "flow")
+	_, disconnected := aquacomputerQuadroLayout.getSensorValue(status, "temp2")
+	_, outOfRange := aquacomputerQuadroLayout.getSensorValue(status, "temp5")
+	_, unknown := aquacomputerQuadroLayout.getSensorValue(status, "pump")
+
+	// THEN
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Equal(t, 28500.0, temp)
+	assert.Equal(t, 40000.0, virtual)
+	assert.Equal(t, 123400000.0, flow)
+	assert.EqualError(t, disconnected, "sensor 'temp2' is not connected")
+	assert.EqualError(t, outOfRange, "invalid sensor 'temp5', must be in range 1..4")
+	assert.EqualError(t, unknown, "unknown sensor 'pump', use one of: temp1..temp4 | virtual1..virtual16 | flow")
+}
+
+func TestAquacomputerLayout_NoFlowSensor(t *testing.T) {
+	// GIVEN
+	status := make([]byte, aquacomputerD5NextLayout.statusLength())
+
+	// WHEN
+	_, err := aquacomputerD5NextLayout.getSensorValue(status, "flow")
+
+	// THEN
+	assert.EqualError(t, err, "device has no flow sensor")
+}
+
+func TestAquacomputerDevice_GetRpm(t *testing.T) {
+	// GIVEN
+	device := &aquacomputerDevice{layout: aquacomputerQuadroLayout}
+	device.status = createQuadroStatus()
+	device.statusTime = time.Now()
+
+	// WHEN
+	rpm, err := device.GetRpm(2)
+	_, invalid := device.GetRpm(5)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 1200, rpm)
+	assert.EqualError(t, invalid, "invalid channel 5, must be in range 1..4")
+}
+
+func TestAquacomputerLayout_SetDuty(t *testing.T) {
+	// GIVEN
+	report := make([]byte, aquacomputerQuadroLayout.ctrlReportLength)
+	report[0] = aquacomputerCtrlReportId
+
+	// WHEN
+	aquacomputerQuadroLayout.setDuty(report, 3, 42)
+
+	// THEN
+	assert.Equal(t, uint16(4200), binary.BigEndian.Uint16(report[0xe1+aquacomputerFanCtrlPwmOffset:]))
+	assert.Equal(t, crc16Usb(report[1:len(report)-2]), binary.BigEndian.Uint16(report[len(report)-2:]))
+}
+
+func TestCrc16Usb(t *testing.T) {
+	assert.Equal(t, uint16(0xb4c8), crc16Usb([]byte("123456789")))
+}
@@ -0,0 +1,150 @@
+package usbhid
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	nzxtWriteLength    = 65
+	nzxtReadLength     = 21
+	nzxtV2ReadLength   = 64
+	corsairWriteLength = 64
+	corsairReadLength  = 16
+
+	// maxStatusReports is the number of reports read while waiting for a specific status report
+	maxStatusReports = 16
+)
+
+var (
+	errNoStatus = errors.New("device didn't report the requested status")
+)
+
+// nzxtSmartDevice is the (first generation) NZXT Smart Device with 3 fan channels.
+// The device reports the status of one fan channel per report.
+type nzxtSmartDevice struct {
+	device
+}
+
+func (d *nzxtSmartDevice) GetChannelCount() int {
+	return 3
+}
+
+func (d *nzxtSmartDevice) SetDuty(channel int, duty int) error {
+	if err := validateChannel(channel, d.GetChannelCount()); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.write(nzxtWriteLength, 0x02, 0x4d, byte(channel-1), 0x00, byte(duty))
+}
+
+func (d *nzxtSmartDevice) GetRpm(channel int) (int, error) {
+	if err := validateChannel(channel, d.GetChannelCount()); err != nil {
+		return 0, err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for i := 0; i < maxStatusReports; i++ {
+		report, err := d.read(nzxtReadLength)
+		if err != nil {
+			return 0, err
+		}
+		if len(report) < nzxtReadLength || int(report[15]>>4) != channel-1 {
+			continue
+		}
+		return int(report[3])<<8 | int(report[4]), nil
+	}
+	return 0, errNoStatus
+}
+
+// nzxtSmartDeviceV2 is the NZXT Smart Device V2 and the NZXT RGB & Fan Controller with 3 fan channels
+type nzxtSmartDeviceV2 struct {
+	device
+}
+
+func (d *nzxtSmartDeviceV2) GetChannelCount() int {
+	return 3
+}
+
+func (d *nzxtSmartDeviceV2) SetDuty(channel int, duty int) error {
+	if err := validateChannel(channel, d.GetChannelCount()); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	// the channel is passed as a bitmask, the duties of fan 1-3 follow
+	report := []byte{0x62, 0x01, 0x01 << (channel - 1), 0x00, 0x00, 0x00}
+	report[channel+2] = byte(duty)
+	return d.write(nzxtWriteLength, report...)
+}
+
+func (d *nzxtSmartDeviceV2) GetRpm(channel int) (int, error) {
+	if err := validateChannel(channel, d.GetChannelCount()); err != nil {
+		return 0, err
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for i := 0; i < maxStatusReports; i++ {
+		report, err := d.read(nzxtV2ReadLength)
+		if err != nil {
+			return 0, err
+		}
+		// fan status report, the rpm of each channel is a little endian uint16 starting at offset 24
+		if len(report) < 30 || report[0] != 0x67 || report[1] != 0x02 {
+			continue
+		}
+		offset := 24 + (channel-1)*2
+		return int(report[offset+1])<<8 | int(report[offset]), nil
+	}
+	return 0, errNoStatus
+}
+
+// corsairCommanderPro is the Corsair Commander Pro with 6 fan channels
+type corsairCommanderPro struct {
+	device
+}
+
+func (d *corsairCommanderPro) GetChannelCount() int {
+	return 6
+}
+
+func (d *corsairCommanderPro) SetDuty(channel int, duty int) error {
+	if err := validateChannel(channel, d.GetChannelCount()); err != nil {
+		return err
+	}
+	_, err := d.command(0x23, byte(channel-1), byte(duty))
+	return err
+}
+
+func (d *corsairCommanderPro) GetRpm(channel int) (int, error) {
+	if err := validateChannel(channel, d.GetChannelCount()); err != nil {
+		return 0, err
+	}
+	response, err := d.command(0x21, byte(channel-1))
+	if err != nil {
+		return 0, err
+	}
+	return int(response[1])<<8 | int(response[2]), nil
+}
+
+// command sends the given command to the device and returns its response
+func (d *corsairCommanderPro) command(data ...byte) ([]byte, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	err := d.write(corsairWriteLength, data...)
+	if err != nil {
+		return nil, err
+	}
+	response, err := d.read(corsairReadLength)
+	if err != nil {
+		return nil, err
+	}
+	if len(response) < 3 {
+		return nil, fmt.Errorf("invalid response length: %d", len(response))
+	}
+	if response[0] != 0x00 {
+		return nil, fmt.Errorf("command 0x%02x failed with status 0x%02x", data[0], response[0])
+	}
+	return response, nil
+}
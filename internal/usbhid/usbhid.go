@@ -0,0 +1,266 @@
+package usbhid
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+)
+
+const (
+	readTimeout = 2 * time.Second
+
+	// request numbers of the hidraw feature report ioctls, see linux/hidraw.h
+	hidIocSFeature = 0x06
+	hidIocGFeature = 0x07
+)
+
+var (
+	devPath = "/dev"
+
+	// models maps from the HID_ID (bus:vendor:product) of a device to its model
+	models = map[string]string{
+		"0003:00001E71:00001714": configuration.UsbHidModelNzxtSmartDevice,
+		"0003:00001E71:00002006": configuration.UsbHidModelNzxtSmartDeviceV2,
+		"0003:00001E71:00002007": configuration.UsbHidModelNzxtSmartDeviceV2,
+		"0003:00001E71:0000200D": configuration.UsbHidModelNzxtSmartDeviceV2,
+		"0003:00001B1C:00000C10": configuration.UsbHidModelCorsairCommanderPro,
+		"0003:00000C70:0000F00E": configuration.UsbHidModelAquacomputerD5Next,
+		"0003:00000C70:0000F011": configuration.UsbHidModelAquacomputerOcto,
+		"0003:00000C70:0000F00D": configuration.UsbHidModelAquacomputerQuadro,
+	}
+
+	// controllers caches all detected controllers by their path, since multiple fans
+	// on the same controller have to share the same device handle
+	controllers     = map[string]Controller{}
+	controllersLock sync.Mutex
+)
+
+// Controller is a USB HID fan controller with multiple fan channels
+type Controller interface {
+	// GetPath returns the hidraw device path of this controller
+	GetPath() string
+	// GetModel returns the model of this controller
+	GetModel() string
+	// GetName returns the name of this controller as reported by the device
+	GetName() string
+	// GetChannelCount returns the number of fan channels of this controller
+	GetChannelCount() int
+
+	// SetDuty sets the duty (0-100) of the given channel (1 based)
+	SetDuty(channel int, duty int) error
+	// GetRpm returns the current RPM of the given channel (1 based)
+	GetRpm(channel int) (int, error)
+}
+
+// SensorController is a Controller which also reports the values of its sensors
+type SensorController interface {
+	Controller
+
+	// GetSensorValue returns the value (in milli-units) of the sensor with the given name
+	GetSensorValue(name string) (float64, error)
+}
+
+// device is the base of all controllers, handling the communication with the hidraw device
+type device struct {
+	Path  string
+	Model string
+	Name  string
+
+	file *os.File
+	lock sync.Mutex
+}
+
+func (d *device) GetPath() string {
+	return d.Path
+}
+
+func (d *device) GetModel() string {
+	return d.Model
+}
+
+func (d *device) GetName() string {
+	return d.Name
+}
+
+func (d *device) open() error {
+	if d.file != nil {
+		return nil
+	}
+	file, err := os.OpenFile(d.Path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	d.file = file
+	return nil
+}
+
+// write sends the given report to the device, padded with zeros to the given length
+func (d *device) write(length int, data ...byte) error {
+	if err := d.open(); err != nil {
+		return err
+	}
+	report := make([]byte, length)
+	copy(report, data)
+	_, err := d.file.Write(report)
+	return err
+}
+
+// read reads a single report of the given length from the device
+func (d *device) read(length int) ([]byte, error) {
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+	_ = d.file.SetReadDeadline(time.Now().Add(readTimeout))
+	report := make([]byte, length)
+	n, err := d.file.Read(report)
+	if err != nil {
+		return nil, err
+	}
+	return report[:n], nil
+}
+
+// getFeatureReport reads the feature report with the given id and length (including the report id) from the device
+func (d *device) getFeatureReport(reportId byte, length int) ([]byte, error) {
+	if err := d.open(); err != nil {
+		return nil, err
+	}
+	report := make([]byte, length)
+	report[0] = reportId
+	n, err := d.ioctl(hidIocGFeature, report)
+	if err != nil {
+		return nil, err
+	}
+	return report[:n], nil
+}
+
+// setFeatureReport sends the given feature report, starting with its report id, to the device
+func (d *device) setFeatureReport(report []byte) error {
+	if err := d.open(); err != nil {
+		return err
+	}
+	_, err := d.ioctl(hidIocSFeature, report)
+	return err
+}
+
+// ioctl issues the HIDIOCGFEATURE or HIDIOCSFEATURE request with the given buffer,
+// returns the number of bytes transferred
+func (d *device) ioctl(request uintptr, buffer []byte) (int, error) {
+	// _IOC(_IOC_WRITE|_IOC_READ, 'H', request, len)
+	code := uintptr(3)<<30 | uintptr(len(buffer))<<16 | uintptr('H')<<8 | request
+	n, _, errno := syscall.Syscall(syscall.SYS_IOCTL, d.file.Fd(), code, uintptr(unsafe.Pointer(&buffer[0])))
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(n), nil
+}
+
+// Detect returns all supported controllers connected to this system
+func Detect() []Controller {
+	controllersLock.Lock()
+	defer controllersLock.Unlock()
+
+	var result []Controller
+
+	entries, err := filepath.Glob(configuration.GetSysfsPath("class", "hidraw", "hidraw*"))
+	if err != nil {
+		return result
+	}
+	sort.Strings(entries)
+
+	for _, entry := range entries {
+		hidId, name := readUevent(path.Join(entry, "device", "uevent"))
+		model, ok := models[strings.ToUpper(hidId)]
+		if !ok {
+			continue
+		}
+		devicePath := path.Join(devPath, filepath.Base(entry))
+		controller, ok := controllers[devicePath]
+		if !ok || controller.GetModel() != model {
+			controller = NewController(devicePath, model, name)
+			if controller == nil {
+				continue
+			}
+			controllers[devicePath] = controller
+		}
+		result = append(result, controller)
+	}
+
+	return result
+}
+
+// Find returns the controller of the given model with the given index
+// (in order of detection), or the one with the given hidraw path, if set
+func Find(model string, index int, devicePath string) (Controller, error) {
+	count := 0
+	for _, controller := range Detect() {
+		if len(devicePath) > 0 {
+			if controller.GetPath() == devicePath {
+				return controller, nil
+			}
+			continue
+		}
+		if controller.GetModel() != model {
+			continue
+		}
+		if count == index {
+			return controller, nil
+		}
+		count++
+	}
+	return nil, fmt.Errorf("no usb hid controller of model '%s' with index %d found", model, index)
+}
+
+func NewController(devicePath string, model string, name string) Controller {
+	switch model {
+	case configuration.UsbHidModelNzxtSmartDevice:
+		return &nzxtSmartDevice{device: device{Path: devicePath, Model: model, Name: name}}
+	case configuration.UsbHidModelNzxtSmartDeviceV2:
+		return &nzxtSmartDeviceV2{device: device{Path: devicePath, Model: model, Name: name}}
+	case configuration.UsbHidModelCorsairCommanderPro:
+		return &corsairCommanderPro{device: device{Path: devicePath, Model: model, Name: name}}
+	case configuration.UsbHidModelAquacomputerD5Next:
+		return &aquacomputerDevice{device: device{Path: devicePath, Model: model, Name: name}, layout: aquacomputerD5NextLayout}
+	case configuration.UsbHidModelAquacomputerOcto:
+		return &aquacomputerDevice{device: device{Path: devicePath, Model: model, Name: name}, layout: aquacomputerOctoLayout}
+	case configuration.UsbHidModelAquacomputerQuadro:
+		return &aquacomputerDevice{device: device{Path: devicePath, Model: model, Name: name}, layout: aquacomputerQuadroLayout}
+	}
+	return nil
+}
+
+// readUevent returns the HID_ID and HID_NAME of the given uevent file
+func readUevent(ueventPath string) (hidId string, name string) {
+	content, err := os.ReadFile(ueventPath)
+	if err != nil {
+		return "", ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "HID_ID":
+			hidId = value
+		case "HID_NAME":
+			name = value
+		}
+	}
+	return hidId, name
+}
+
+func validateChannel(channel int, count int) error {
+	if channel < 1 || channel > count {
+		return fmt.Errorf("invalid channel %d, must be in range 1..%d", channel, count)
+	}
+	return nil
+}
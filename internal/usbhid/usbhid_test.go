@@ -0,0 +1,60 @@
+package usbhid
+
+import (
+	"path"
+	"testing"
+
+	"github.com/markusressel/fan2go/internal/configuration"
+	"github.com/markusressel/fan2go/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func createHidrawSysfs(t *testing.T, devices map[string]string) string {
+	root := t.TempDir()
+	files := map[string]string{}
+	for name, hidId := range devices {
+		files[path.Join("class", "hidraw", name, "device", "uevent")] = "DRIVER=hid-generic\nHID_ID=" + hidId + "\nHID_NAME=Some Device"
+	}
+	err := util.WriteSysfsFixture(root, files)
+	assert.NoError(t, err)
+	return root
+}
+
+func TestDetect(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.SysfsRoot = createHidrawSysfs(t, map[string]string{
+		"hidraw0": "0003:0000046D:0000C52B",
+		"hidraw1": "0003:00001B1C:00000C10",
+		"hidraw2": "0003:00001E71:00002006",
+		"hidraw3": "0003:00000C70:0000F011",
+	})
+
+	// WHEN
+	result := Detect()
+
+	// THEN
+	assert.Len(t, result, 3)
+	assert.Equal(t, "/dev/hidraw1", result[0].GetPath())
+	assert.Equal(t, configuration.UsbHidModelCorsairCommanderPro, result[0].GetModel())
+	assert.Equal(t, 6, result[0].GetChannelCount())
+	assert.Equal(t, configuration.UsbHidModelNzxtSmartDeviceV2, result[1].GetModel())
+	assert.Equal(t, configuration.UsbHidModelAquacomputerOcto, result[2].GetModel())
+	assert.Equal(t, 8, result[2].GetChannelCount())
+	assert.Implements(t, (*SensorController)(nil), result[2])
+}
+
+func TestFindReturnsSameController(t *testing.T) {
+	// GIVEN
+	configuration.CurrentConfig.SysfsRoot = createHidrawSysfs(t, map[string]string{
+		"hidraw3": "0003:00001E71:00001714",
+	})
+
+	// WHEN
+	first, err1 := Find(configuration.UsbHidModelNzxtSmartDevice, 0, "")
+	second, err2 := Find("", 0, "/dev/hidraw3")
+
+	// THEN
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Same(t, first, second)
+}
@@ -2,37 +2,76 @@ package util
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/markusressel/fan2go/internal/ui"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
 )
 
 func SafeCmdExecution(executable string, args []string, timeout time.Duration) (string, error) {
+	return SafeCmdExecutionWithEnv(executable, args, nil, timeout)
+}
+
+// SafeCmdExecutionWithEnv behaves like SafeCmdExecution, but adds the given "KEY=value" pairs
+// to the environment of the command, f.ex. to pass secrets, which must not show up in its arguments
+func SafeCmdExecutionWithEnv(executable string, args []string, env []string, timeout time.Duration) (string, error) {
+	out, err := runCmd(executable, args, env, timeout)
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			ui.Warning("Command failed to execute: %s: %s", executable, string(exitError.Stderr))
+		}
+		return "", err
+	}
+
+	strout := string(out)
+	strout = strings.Trim(strout, "\n")
+
+	return strout, nil
+}
+
+// SafeCmdExecutionWithExitCode behaves like SafeCmdExecution, but returns the output and the exit code
+// of a command exiting with a non-zero code instead of failing, f.ex. for commands using the exit code
+// as a status bitmask
+func SafeCmdExecutionWithExitCode(executable string, args []string, timeout time.Duration) (string, int, error) {
+	exitCode := 0
+	out, err := runCmd(executable, args, nil, timeout)
+	if err != nil {
+		var exitError *exec.ExitError
+		if !errors.As(err, &exitError) {
+			return "", 0, err
+		}
+		exitCode = exitError.ExitCode()
+	}
+
+	strout := string(out)
+	strout = strings.Trim(strout, "\n")
+
+	return strout, exitCode, nil
+}
+
+// runCmd runs the given executable, if its file permissions are safe, and returns its output
+func runCmd(executable string, args []string, env []string, timeout time.Duration) ([]byte, error) {
 	if _, err := CheckFilePermissionsForExecution(executable); err != nil {
-		return "", fmt.Errorf("cannot execute %s: %s", executable, err)
+		return nil, fmt.Errorf("cannot execute %s: %s", executable, err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, executable, args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	out, err := cmd.Output()
 
 	if ctx.Err() == context.DeadlineExceeded {
 		ui.Warning("Command timed out: %s", executable)
-		return "", err
+		return nil, fmt.Errorf("command timed out: %s", executable)
 	}
 
-	if err != nil {
-		exitError := err.(*exec.ExitError)
-		ui.Warning("Command failed to execute: %s: %s", executable, string(exitError.Stderr))
-		return "", err
-	}
-
-	strout := string(out)
-	strout = strings.Trim(strout, "\n")
-
-	return strout, nil
+	return out, err
 }
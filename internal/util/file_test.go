@@ -113,3 +113,17 @@ func TestFileHasPermissionsOtherHasWritePermission(t *testing.T) {
 	assert.Equal(t, false, result)
 	assert.Error(t, err)
 }
+
+func TestReadFloatFromFile(t *testing.T) {
+	// GIVEN
+	filePath := t.TempDir() + "/value"
+	err := os.WriteFile(filePath, []byte(" 42.5\n"), 0644)
+	assert.NoError(t, err)
+
+	// WHEN
+	result, err := ReadFloatFromFile(filePath)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 42.5, result)
+}
@@ -3,12 +3,18 @@ package util
 import (
 	"fmt"
 	"github.com/markusressel/fan2go/internal/ui"
+	"math"
 	"sort"
 	"strconv"
 )
 
 const (
 	InterpolationTypeLinear = "linear"
+	// InterpolationTypeStep holds the value of a step until the next step is reached
+	InterpolationTypeStep = "step"
+	// InterpolationTypeMonotone uses a monotone cubic spline, which results in a smooth
+	// curve without overshooting the given steps
+	InterpolationTypeMonotone = "monotone"
 )
 
 // Coerce returns a value that is at least min and at most max, otherwise value
@@ -55,6 +61,11 @@ func UpdateSimpleMovingAvg(oldAvg float64, n int, newValue float64) float64 {
 	return oldAvg + (1/float64(n))*(newValue-oldAvg)
 }
 
+// UpdateExponentialMovingAvg weights the new value with alpha and the old average with 1 - alpha
+func UpdateExponentialMovingAvg(oldAvg float64, alpha float64, newValue float64) float64 {
+	return alpha*newValue + (1-alpha)*oldAvg
+}
+
 // InterpolateLinearly takes the given mapping and adds interpolated values in [start;stop].
 func InterpolateLinearly(data *map[int]float64, start int, stop int) map[int]float64 {
 	interpolated := map[int]float64{}
@@ -75,25 +86,32 @@ func CalculateInterpolatedCurveValue(steps map[int]float64, interpolationType st
 	// sort them increasing
 	sort.Ints(xValues)
 
-	// find value closest to input
+	if input <= float64(xValues[0]) {
+		// input is below the smallest given step, so
+		// we fall back to the value of the smallest step
+		return steps[xValues[0]]
+	}
+
+	// find the section containing the input
 	for i := 0; i < len(xValues)-1; i++ {
 		currentX := xValues[i]
 		nextX := xValues[i+1]
 
-		if input <= float64(currentX) && i == 0 {
-			// input is below the smallest given step, so
-			// we fall back to the value of the smallest step
-			return steps[currentX]
-		}
-
 		if input >= float64(nextX) {
 			continue
 		}
 
 		if input == float64(currentX) {
 			return steps[currentX]
-		} else {
-			// input is somewhere in between currentX and nextX
+		}
+
+		// input is somewhere in between currentX and nextX
+		switch interpolationType {
+		case InterpolationTypeStep:
+			return steps[currentX]
+		case InterpolationTypeMonotone:
+			return interpolateMonotone(steps, xValues, i, input)
+		default:
 			currentY := steps[currentX]
 			nextY := steps[nextX]
 
@@ -108,6 +126,58 @@ func CalculateInterpolatedCurveValue(steps map[int]float64, interpolationType st
 	return steps[xValues[len(xValues)-1]]
 }
 
+// interpolateMonotone evaluates the monotone cubic spline (Fritsch-Carlson) through the given steps
+// at the given input, which has to be within the section starting at xValues[section]
+func interpolateMonotone(steps map[int]float64, xValues []int, section int, input float64) float64 {
+	n := len(xValues)
+
+	// secants between all steps
+	secants := make([]float64, n-1)
+	for i := 0; i < n-1; i++ {
+		secants[i] = (steps[xValues[i+1]] - steps[xValues[i]]) / float64(xValues[i+1]-xValues[i])
+	}
+
+	// tangents at all steps
+	tangents := make([]float64, n)
+	tangents[0] = secants[0]
+	tangents[n-1] = secants[n-2]
+	for i := 1; i < n-1; i++ {
+		if secants[i-1]*secants[i] <= 0 {
+			tangents[i] = 0
+		} else {
+			tangents[i] = (secants[i-1] + secants[i]) / 2
+		}
+	}
+
+	// limit the tangents to preserve monotonicity
+	for i := 0; i < n-1; i++ {
+		if secants[i] == 0 {
+			tangents[i] = 0
+			tangents[i+1] = 0
+			continue
+		}
+		a := tangents[i] / secants[i]
+		b := tangents[i+1] / secants[i]
+		if a*a+b*b > 9 {
+			t := 3 / math.Sqrt(a*a+b*b)
+			tangents[i] = t * a * secants[i]
+			tangents[i+1] = t * b * secants[i]
+		}
+	}
+
+	x0 := float64(xValues[section])
+	x1 := float64(xValues[section+1])
+	h := x1 - x0
+	t := (input - x0) / h
+	t2 := t * t
+	t3 := t2 * t
+
+	return (2*t3-3*t2+1)*steps[xValues[section]] +
+		(t3-2*t2+t)*h*tangents[section] +
+		(-2*t3+3*t2)*steps[xValues[section+1]] +
+		(t3-t2)*h*tangents[section+1]
+}
+
 // FindClosest finds the closest value to target in options.
 func FindClosest(target int, arr []int) int {
 	n := len(arr)
@@ -31,6 +31,60 @@ func TestCalculateInterpolatedCurveValue(t *testing.T) {
 	}
 }
 
+func TestCalculateInterpolatedCurveValueStep(t *testing.T) {
+	// GIVEN
+	expectedInputOutput := map[float64]float64{
+		0:      0.0,
+		99.0:   0.0,
+		100.0:  100.0,
+		500.0:  100.0,
+		1000.0: 1000.0,
+		2000.0: 1000.0,
+	}
+	steps := map[int]float64{
+		0:    0,
+		100:  100,
+		1000: 1000,
+	}
+	interpolationType := InterpolationTypeStep
+
+	for input, output := range expectedInputOutput {
+		// WHEN
+		result := CalculateInterpolatedCurveValue(steps, interpolationType, input)
+
+		// THEN
+		assert.Equal(t, output, result)
+	}
+}
+
+func TestCalculateInterpolatedCurveValueMonotone(t *testing.T) {
+	// GIVEN
+	steps := map[int]float64{
+		40: 0,
+		50: 50,
+		60: 50,
+		80: 255,
+	}
+	interpolationType := InterpolationTypeMonotone
+
+	// WHEN
+	var results []float64
+	for input := 30.0; input <= 90.0; input += 0.5 {
+		results = append(results, CalculateInterpolatedCurveValue(steps, interpolationType, input))
+	}
+
+	// THEN
+	for x, y := range steps {
+		assert.InDelta(t, y, CalculateInterpolatedCurveValue(steps, interpolationType, float64(x)), 0.0001)
+	}
+	for i := 1; i < len(results); i++ {
+		// the curve never decreases and never overshoots the steps
+		assert.GreaterOrEqual(t, results[i]+0.0001, results[i-1])
+		assert.LessOrEqual(t, results[i], 255.0)
+	}
+	assert.InDelta(t, 50.0, CalculateInterpolatedCurveValue(steps, interpolationType, 55), 0.0001)
+}
+
 func TestRatio(t *testing.T) {
 	// GIVEN
 	a := 0.0
@@ -18,6 +18,11 @@ type PidLoop struct {
 	//differentialError float64
 	// last execution time of the loop
 	lastTime time.Time
+
+	// limits of the output, used to prevent integral windup
+	limited   bool
+	outputMin float64
+	outputMax float64
 }
 
 func NewPidLoop(p float64, i float64, d float64) *PidLoop {
@@ -28,6 +33,22 @@ func NewPidLoop(p float64, i float64, d float64) *PidLoop {
 	}
 }
 
+// SetOutputLimits limits the output of the loop to the given range.
+// While the output is saturated, the integral is not accumulated any
+// further in the direction of the saturation (anti-windup).
+func (p *PidLoop) SetOutputLimits(min float64, max float64) {
+	p.limited = true
+	p.outputMin = min
+	p.outputMax = max
+}
+
+// Reset discards the accumulated state of the loop, so the next call to Loop starts over
+func (p *PidLoop) Reset() {
+	p.error = 0
+	p.integral = 0
+	p.lastTime = time.Time{}
+}
+
 // Loop advances the pid loop
 func (p *PidLoop) Loop(target float64, measured float64) float64 {
 	output := 0.0
@@ -40,9 +61,22 @@ func (p *PidLoop) Loop(target float64, measured float64) float64 {
 		dt := loopTime.Sub(p.lastTime).Seconds()
 
 		proportional := err
-		p.integral = p.integral + err*dt
+		integral := p.integral + err*dt
 		derivative := (err - p.error) / dt
-		output = p.p*proportional + p.i*p.integral + p.d*derivative
+		output = p.p*proportional + p.i*integral + p.d*derivative
+
+		if p.limited && output > p.outputMax {
+			output = p.outputMax
+			if p.i*err > 0 {
+				integral = p.integral
+			}
+		} else if p.limited && output < p.outputMin {
+			output = p.outputMin
+			if p.i*err < 0 {
+				integral = p.integral
+			}
+		}
+		p.integral = integral
 	}
 
 	p.error = err
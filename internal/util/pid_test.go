@@ -0,0 +1,56 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPidLoop_OutputLimitsPreventWindup(t *testing.T) {
+	// GIVEN
+	pid := NewPidLoop(0, 100, 0)
+	pid.SetOutputLimits(0, 1)
+	pid.Loop(10, 0)
+
+	// WHEN
+	var outputs []float64
+	for i := 0; i < 3; i++ {
+		time.Sleep(10 * time.Millisecond)
+		outputs = append(outputs, pid.Loop(10, 0))
+	}
+
+	// THEN
+	assert.Equal(t, []float64{1, 1, 1}, outputs)
+	assert.Equal(t, 0.0, pid.integral)
+}
+
+func TestPidLoop_NoLimits(t *testing.T) {
+	// GIVEN
+	pid := NewPidLoop(0, 100, 0)
+	pid.Loop(10, 0)
+
+	// WHEN
+	time.Sleep(10 * time.Millisecond)
+	output := pid.Loop(10, 0)
+
+	// THEN
+	assert.Greater(t, output, 1.0)
+	assert.Greater(t, pid.integral, 0.0)
+}
+
+func TestPidLoop_Reset(t *testing.T) {
+	// GIVEN
+	pid := NewPidLoop(0, 100, 0)
+	pid.Loop(10, 0)
+	time.Sleep(10 * time.Millisecond)
+	pid.Loop(10, 0)
+
+	// WHEN
+	pid.Reset()
+	output := pid.Loop(10, 0)
+
+	// THEN
+	assert.Equal(t, 0.0, output)
+	assert.Equal(t, 0.0, pid.integral)
+}
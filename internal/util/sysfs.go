@@ -0,0 +1,73 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/markusressel/fan2go/internal/ui"
+)
+
+const (
+	// sysfsRetryAttempts is the number of times a sysfs attribute is accessed before a transient error is returned
+	sysfsRetryAttempts = 4
+	// sysfsRetryDelay is the delay before the first retry, it doubles after each retry
+	sysfsRetryDelay = 10 * time.Millisecond
+)
+
+// IsTransientSysfsError indicates whether the given error returned when accessing a sysfs attribute
+// is likely to go away when retried, some drivers (f.ex. it87 or usb devices) occasionally return
+// EAGAIN or ENXIO while the device is busy
+func IsTransientSysfsError(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.ENXIO)
+}
+
+// ReadIntFromSysfs reads a single integer from a sysfs attribute, like ReadIntFromFile,
+// retrying with backoff on transient errors
+func ReadIntFromSysfs(path string) (value int, err error) {
+	err = retryTransient(path, sysfsRetryAttempts, sysfsRetryDelay, func() error {
+		value, err = ReadIntFromFile(path)
+		return err
+	})
+	return value, err
+}
+
+// WriteIntToSysfs writes a single integer to a sysfs attribute, like WriteIntToFile,
+// retrying with backoff on transient errors
+func WriteIntToSysfs(value int, path string) error {
+	return retryTransient(path, sysfsRetryAttempts, sysfsRetryDelay, func() error {
+		return WriteIntToFile(value, path)
+	})
+}
+
+// retryTransient calls f up to the given number of attempts while it returns a transient error,
+// doubling the given delay after each retry
+func retryTransient(path string, attempts int, delay time.Duration, f func() error) (err error) {
+	for attempt := 1; ; attempt++ {
+		err = f()
+		if err == nil || attempt >= attempts || !IsTransientSysfsError(err) {
+			return err
+		}
+		ui.ForSubsystem(ui.SubsystemHwmon).Debug("Transient error accessing %s, retrying in %s: %v", path, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// WriteSysfsFixture writes a fake sysfs tree to root, f.ex. for tests or to reproduce bugs using
+// the configured sysfs root. files maps from the path of an attribute relative to root -> its content,
+// a trailing newline is added like the kernel does.
+func WriteSysfsFixture(root string, files map[string]string) error {
+	for name, content := range files {
+		filePath := path.Join(root, name)
+		if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filePath, []byte(content+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransientSysfsError(t *testing.T) {
+	// GIVEN
+	eagain := &fs.PathError{Op: "read", Path: "pwm1", Err: syscall.EAGAIN}
+	enxio := &fs.PathError{Op: "read", Path: "pwm1", Err: syscall.ENXIO}
+	enoent := &fs.PathError{Op: "open", Path: "pwm1", Err: syscall.ENOENT}
+
+	// WHEN
+	// THEN
+	assert.True(t, IsTransientSysfsError(eagain))
+	assert.True(t, IsTransientSysfsError(enxio))
+	assert.False(t, IsTransientSysfsError(enoent))
+	assert.False(t, IsTransientSysfsError(errors.New("other")))
+}
+
+func TestRetryTransientSucceedsAfterTransientErrors(t *testing.T) {
+	// GIVEN
+	calls := 0
+	f := func() error {
+		calls++
+		if calls < 3 {
+			return &fs.PathError{Op: "read", Path: "pwm1", Err: syscall.EAGAIN}
+		}
+		return nil
+	}
+
+	// WHEN
+	err := retryTransient("pwm1", 4, 0, f)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryTransientGivesUp(t *testing.T) {
+	// GIVEN
+	calls := 0
+	f := func() error {
+		calls++
+		return &fs.PathError{Op: "read", Path: "pwm1", Err: syscall.ENXIO}
+	}
+
+	// WHEN
+	err := retryTransient("pwm1", 4, 0, f)
+
+	// THEN
+	assert.ErrorIs(t, err, syscall.ENXIO)
+	assert.Equal(t, 4, calls)
+}
+
+func TestRetryTransientDoesNotRetryOtherErrors(t *testing.T) {
+	// GIVEN
+	calls := 0
+	f := func() error {
+		calls++
+		return &fs.PathError{Op: "open", Path: "pwm1", Err: syscall.ENOENT}
+	}
+
+	// WHEN
+	err := retryTransient("pwm1", 4, 0, f)
+
+	// THEN
+	assert.ErrorIs(t, err, syscall.ENOENT)
+	assert.Equal(t, 1, calls)
+}
+
+func TestReadIntFromSysfs(t *testing.T) {
+	// GIVEN
+	filePath := path.Join(t.TempDir(), "pwm1")
+	_ = os.WriteFile(filePath, []byte("128\n"), 0644)
+
+	// WHEN
+	value, err := ReadIntFromSysfs(filePath)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 128, value)
+}
+
+func TestWriteSysfsFixture(t *testing.T) {
+	// GIVEN
+	root := t.TempDir()
+
+	// WHEN
+	err := WriteSysfsFixture(root, map[string]string{
+		"class/thermal/thermal_zone0/type": "acpitz",
+		"class/thermal/thermal_zone0/temp": "45000",
+	})
+
+	// THEN
+	assert.NoError(t, err)
+	zoneType, err := os.ReadFile(path.Join(root, "class", "thermal", "thermal_zone0", "type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "acpitz\n", string(zoneType))
+	temp, err := ReadIntFromSysfs(path.Join(root, "class", "thermal", "thermal_zone0", "temp"))
+	assert.NoError(t, err)
+	assert.Equal(t, 45000, temp)
+}
@@ -0,0 +1,26 @@
+package util
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseTimeOfDay parses the given time of day in the format HH:MM and
+// returns the duration since midnight
+func ParseTimeOfDay(text string) (time.Duration, error) {
+	t, err := time.Parse("15:04", text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day '%s', expected HH:MM", text)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// IsTimeOfDayInRange returns true if the time of day of now is in [from;to).
+// If to is before from, the range spans midnight.
+func IsTimeOfDayInRange(now time.Time, from time.Duration, to time.Duration) bool {
+	timeOfDay := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute + time.Duration(now.Second())*time.Second
+	if from <= to {
+		return timeOfDay >= from && timeOfDay < to
+	}
+	return timeOfDay >= from || timeOfDay < to
+}
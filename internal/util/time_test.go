@@ -0,0 +1,52 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeOfDay(t *testing.T) {
+	// WHEN
+	result, err := ParseTimeOfDay("22:30")
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 22*time.Hour+30*time.Minute, result)
+}
+
+func TestParseTimeOfDayInvalid(t *testing.T) {
+	// WHEN
+	_, err := ParseTimeOfDay("25:00")
+
+	// THEN
+	assert.EqualError(t, err, "invalid time of day '25:00', expected HH:MM")
+}
+
+func TestIsTimeOfDayInRange(t *testing.T) {
+	// GIVEN
+	from := 22 * time.Hour
+	to := 7 * time.Hour
+
+	expected := map[int]bool{
+		21: false,
+		22: true,
+		23: true,
+		0:  true,
+		6:  true,
+		7:  false,
+		12: false,
+	}
+
+	for hour, inRange := range expected {
+		now := time.Date(2022, 1, 1, hour, 0, 0, 0, time.Local)
+
+		// WHEN
+		result := IsTimeOfDayInRange(now, from, to)
+
+		// THEN
+		assert.Equal(t, inRange, result, "hour %d", hour)
+		assert.Equal(t, !inRange, IsTimeOfDayInRange(now, to, from), "hour %d", hour)
+	}
+}